@@ -26,6 +26,19 @@ pub const tb_completion_t = *const fn (
     result_len: u32,
 ) callconv(.C) void;
 
+pub const tb_log_level_t = enum(u8) {
+    err = 0,
+    warn = 1,
+    info = 2,
+    debug = 3,
+};
+
+pub const tb_log_callback_t = *const fn (
+    level: tb_log_level_t,
+    message_ptr: [*]const u8,
+    message_len: u32,
+) callconv(.C) void;
+
 const constants = @import("../../constants.zig");
 const Storage = @import("../../storage.zig").Storage;
 const MessageBus = @import("../../message_bus.zig").MessageBusClient;
@@ -140,3 +153,38 @@ pub fn deinit(
     const context = client_to_context(client);
     (context.deinit_fn)(context);
 }
+
+/// The callback that receives the logs of every client in the process, or zero to write them to
+/// stderr.
+var log_callback = std.atomic.Atomic(usize).init(0);
+
+pub fn register_log_callback(
+    callback: ?tb_log_callback_t,
+) callconv(.C) void {
+    log_callback.store(if (callback) |f| @intFromPtr(f) else 0, .Release);
+}
+
+/// The log function used by clients, to be set as `std_options.logFn` by whoever exports them.
+pub fn log(
+    comptime level: std.log.Level,
+    comptime scope: @TypeOf(.EnumLiteral),
+    comptime format: []const u8,
+    args: anytype,
+) void {
+    const callback_address = log_callback.load(.Acquire);
+    if (callback_address == 0) return constants.log(level, scope, format, args);
+
+    const callback: tb_log_callback_t = @ptrFromInt(callback_address);
+    const prefix = if (scope == .default) "" else "(" ++ @tagName(scope) ++ ") ";
+
+    // Long messages are truncated rather than dropped.
+    var buffer: [1024]u8 = undefined;
+    const message = std.fmt.bufPrint(&buffer, prefix ++ format, args) catch &buffer;
+
+    callback(switch (level) {
+        .err => .err,
+        .warn => .warn,
+        .info => .info,
+        .debug => .debug,
+    }, message.ptr, @intCast(message.len));
+}
@@ -2,6 +2,10 @@ const std = @import("std");
 const builtin = @import("builtin");
 const tb = @import("tb_client.zig");
 
+pub const std_options = struct {
+    pub const logFn = tb.log;
+};
+
 comptime {
     if (!builtin.link_libc) {
         @compileError("Must be built with libc to export tb_client symbols");
@@ -14,6 +18,7 @@ comptime {
     @export(tb.release_packet, .{ .name = "tb_client_release_packet", .linkage = .Strong });
     @export(tb.submit, .{ .name = "tb_client_submit", .linkage = .Strong });
     @export(tb.deinit, .{ .name = "tb_client_deinit", .linkage = .Strong });
+    @export(tb.register_log_callback, .{ .name = "tb_client_register_log_callback", .linkage = .Strong });
 }
 
 fn init(
@@ -20,6 +20,7 @@ const type_mappings = .{
     .{ tb_client.tb_packet_t, "tb_packet_t" },
     .{ tb_client.tb_client_t, "tb_client_t" },
     .{ tb_client.tb_status_t, "TB_STATUS" },
+    .{ tb_client.tb_log_level_t, "TB_LOG_LEVEL" },
 };
 
 fn resolve_c_type(comptime Type: type) []const u8 {
@@ -234,6 +235,10 @@ pub fn main() !void {
         \\    tb_client_t client
         \\);
         \\
+        \\void tb_client_register_log_callback(
+        \\    void (*callback)(TB_LOG_LEVEL, const uint8_t*, uint32_t)
+        \\);
+        \\
         \\
     , .{});
 
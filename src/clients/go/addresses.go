@@ -0,0 +1,61 @@
+package tigerbeetle_go
+
+import (
+	"strings"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+)
+
+// UpdateAddresses switches the client to a new list of replica addresses, for example while a
+// replica is being replaced. Duplicate addresses are dropped.
+//
+// tb_client has no way to change the addresses of a session, so a new session is registered
+// with the cluster for the new addresses, and new requests are submitted to it. The requests
+// in flight complete on the previous session, which is closed once they have. If the new
+// addresses are invalid, the client keeps using the previous ones.
+//
+// Clients created with WithTransport return ErrUpdateAddressesUnsupported.
+func (c *c_client) UpdateAddresses(addresses []string) error {
+	if c.native == nil {
+		return errors.ErrUpdateAddressesUnsupported{}
+	}
+
+	addresses, err := normalizeAddresses(addresses)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if c.closing {
+		return errors.ErrClientClosed{}
+	}
+	if err := c.native.updateAddresses(addresses); err != nil {
+		return err
+	}
+	if c.hedgeNative != nil {
+		return c.hedgeNative.updateAddresses(addresses)
+	}
+	return nil
+}
+
+// normalizeAddresses trims the addresses and drops duplicates, keeping their order.
+func normalizeAddresses(addresses []string) ([]string, error) {
+	normalized := make([]string, 0, len(addresses))
+	seen := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if address == "" || strings.Contains(address, ",") {
+			return nil, errors.ErrInvalidAddress{}
+		}
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		seen[address] = struct{}{}
+		normalized = append(normalized, address)
+	}
+	if len(normalized) == 0 {
+		return nil, errors.ErrInvalidAddress{}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,37 @@
+package tigerbeetle_go
+
+import (
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// AuditLog records the batches a client submits and the results it receives, such as the
+// append-only log of package audit.
+type AuditLog interface {
+	// Submitted records the events of a batch about to be submitted, in their wire layout, and
+	// returns the ID of the record, which Received is given.
+	Submitted(op types.Operation, events []byte) uint64
+	// Received records the reply to the batch of the record submitted, in its wire layout, or the
+	// error that the batch failed with.
+	Received(submitted uint64, op types.Operation, reply []byte, err error)
+}
+
+// WithAuditLog records every batch the client submits, and the reply or error it receives, to
+// log. A batch that is retried is recorded on every attempt. Batches are recorded on the
+// goroutine that submits them, so log must be safe for concurrent use, and should be quick.
+func WithAuditLog(log AuditLog) ClientOption {
+	return func(options *clientOptions) {
+		options.audit = log
+	}
+}
+
+type auditTransport struct {
+	Transport
+	log AuditLog
+}
+
+func (t *auditTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	submitted := t.log.Submitted(op, events)
+	wrote, err := t.Transport.Submit(op, events, reply)
+	t.log.Received(submitted, op, reply[:wrote], err)
+	return wrote, err
+}
@@ -0,0 +1,52 @@
+package tigerbeetle_go
+
+import (
+	"context"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Authorizer decides whether a request may be submitted, such as to cap the amounts of a code or
+// to prohibit transfers between some accounts, centrally for every caller of a client. The
+// events are typed by operation as for an Interceptor, and belong to the caller.
+//
+// It returns nil to allow the request, and errors.ErrUnauthorized, naming the event and the
+// reason, to veto it. Other errors, such as from a policy service that can't be reached, fail
+// the request as they are.
+type Authorizer func(ctx context.Context, op types.Operation, events any) error
+
+// WithAuthorizer makes the client submit only the requests that authorizer allows, failing the
+// others with its error. It is an interceptor, run in the order it was added among those of
+// WithInterceptor, with the context of ClientWithContext to tell whom requests are made on
+// behalf of.
+func WithAuthorizer(authorizer Authorizer) ClientOption {
+	return WithInterceptor(func(ctx context.Context, op types.Operation, events any, next Invoker) (any, error) {
+		if err := authorizer(ctx, op, events); err != nil {
+			return nil, err
+		}
+		return next(ctx, op, events)
+	})
+}
+
+// AuthorizeTransfers returns an Authorizer that checks every transfer created, and vetoes the
+// request with the reason that check returns for the first transfer it refuses, as a non-empty
+// string. Other requests are allowed, except those of SubmitRaw creating transfers, whose
+// transfers it can't check.
+func AuthorizeTransfers(check func(ctx context.Context, transfer types.Transfer) string) Authorizer {
+	return func(ctx context.Context, op types.Operation, events any) error {
+		if op != types.OperationCreateTransfers {
+			return nil
+		}
+		transfers, ok := events.([]types.Transfer)
+		if !ok {
+			return errors.ErrUnauthorized{Operation: op, Index: -1, Reason: "raw transfers can't be checked"}
+		}
+		for i, transfer := range transfers {
+			if reason := check(ctx, transfer); reason != "" {
+				return errors.ErrUnauthorized{Operation: op, Index: i, Reason: reason}
+			}
+		}
+		return nil
+	}
+}
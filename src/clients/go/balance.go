@@ -0,0 +1,139 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// balanceWatchPollIntervalDefault is how often WatchBalance looks the account up by default.
+const balanceWatchPollIntervalDefault = time.Second
+
+// BalanceThreshold is a condition on the balances of an account, whose crossings WatchBalance
+// reports.
+type BalanceThreshold struct {
+	// Name identifies the threshold in the snapshots.
+	Name string
+	// Reached reports whether the account is past the threshold.
+	Reached func(account types.Account) bool
+}
+
+// CreditBalanceBelow returns a threshold reached while the credits posted of an account exceed
+// its debits posted and pending by less than amount, such as for the liquidity of an account
+// that holds funds, or when it is overdrawn.
+func CreditBalanceBelow(name string, amount types.Uint128) BalanceThreshold {
+	return BalanceThreshold{Name: name, Reached: func(account types.Account) bool {
+		available := account.CreditsPosted.
+			SubSaturating(account.DebitsPosted).
+			SubSaturating(account.DebitsPending)
+		_, above := available.SubChecked(amount)
+		return !above
+	}}
+}
+
+// DebitBalanceBelow is CreditBalanceBelow for an account whose balance is on the debit side.
+func DebitBalanceBelow(name string, amount types.Uint128) BalanceThreshold {
+	return BalanceThreshold{Name: name, Reached: func(account types.Account) bool {
+		available := account.DebitsPosted.
+			SubSaturating(account.CreditsPosted).
+			SubSaturating(account.CreditsPending)
+		_, above := available.SubChecked(amount)
+		return !above
+	}}
+}
+
+// WatchBalanceOptions configures WatchBalance.
+type WatchBalanceOptions struct {
+	// Thresholds are the conditions to report crossings of.
+	Thresholds []BalanceThreshold
+	// PollInterval is how often the account is looked up. Defaults to 1s.
+	PollInterval time.Duration
+}
+
+// BalanceSnapshot reports that an account crossed a threshold of WatchBalance, with its
+// balances as they were looked up. A watch ends with a snapshot holding a non-nil Err if a
+// lookup fails.
+type BalanceSnapshot struct {
+	Account types.Account
+	// Threshold is the name of the threshold that was crossed.
+	Threshold string
+	// Reached is set when the account went past the threshold, and unset when it came back.
+	Reached bool
+	Err     error
+}
+
+// WatchBalance looks up accountID every PollInterval and reports on the returned channel every
+// time its balances cross one of the thresholds, until ctx is done. The thresholds that the
+// account is already past when first looked up are reported as reached. Until the account
+// exists, nothing is reported.
+//
+// Crossings back and forth between two lookups go unnoticed, so the interval bounds how
+// quickly an alert fires rather than guaranteeing it does.
+func (c *c_client) WatchBalance(
+	ctx context.Context,
+	accountID types.Uint128,
+	options WatchBalanceOptions,
+) <-chan BalanceSnapshot {
+	return watchBalance(ctx, c, accountID, options)
+}
+
+func watchBalance(
+	ctx context.Context,
+	client Client,
+	accountID types.Uint128,
+	options WatchBalanceOptions,
+) <-chan BalanceSnapshot {
+	if options.PollInterval <= 0 {
+		options.PollInterval = balanceWatchPollIntervalDefault
+	}
+	thresholds := append([]BalanceThreshold(nil), options.Thresholds...)
+
+	snapshots := make(chan BalanceSnapshot)
+	go func() {
+		defer close(snapshots)
+
+		emit := func(snapshot BalanceSnapshot) bool {
+			select {
+			case snapshots <- snapshot:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// reached holds whether each threshold was reached at the last lookup, once the account
+		// was found, as if none were before.
+		var reached []bool
+		for {
+			account, found, err := lookupAccount(client, accountID)
+			if err != nil {
+				emit(BalanceSnapshot{Err: err})
+				return
+			}
+			if found {
+				if reached == nil {
+					reached = make([]bool, len(thresholds))
+				}
+				for i, threshold := range thresholds {
+					now := threshold.Reached(account)
+					if now == reached[i] {
+						continue
+					}
+					reached[i] = now
+					if !emit(BalanceSnapshot{Account: account, Threshold: threshold.Name, Reached: now}) {
+						return
+					}
+				}
+			}
+
+			select {
+			case <-time.After(options.PollInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return snapshots
+}
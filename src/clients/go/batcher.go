@@ -0,0 +1,249 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const (
+	// batcherDelayDefault is how long a batcher waits for more transfers to join a batch.
+	batcherDelayDefault = time.Millisecond
+)
+
+// BatcherOptions configures a TransferBatcher.
+type BatcherOptions struct {
+	// Delay is how long a queued transfer waits for others to join its batch. A transfer with a
+	// deadline sooner than Delay is flushed right away. Defaults to 1ms.
+	Delay time.Duration
+	// BatchSizeMax caps the transfers per batch. Defaults to the most that fit in a request.
+	BatchSizeMax int
+}
+
+// TransferBatcher coalesces the transfers submitted by concurrent goroutines into batches,
+// so that many small requests share the round trips of a few large ones.
+//
+// The deadline of the context passed to Submit annotates each transfer: when more transfers
+// are queued than fit in a batch, those with the soonest deadlines go first, and transfers
+// whose deadline passes while queued fail locally without being submitted.
+type TransferBatcher struct {
+	client  Client
+	options BatcherOptions
+
+	mutex   sync.Mutex
+	queue   []*batchedTransfer
+	closing bool
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+type batchedTransfer struct {
+	ctx      context.Context
+	transfer types.Transfer
+	deadline time.Time
+	queued   time.Time
+	result   chan error
+}
+
+// NewTransferBatcher returns a batcher submitting to client until closed.
+func NewTransferBatcher(client Client, options BatcherOptions) *TransferBatcher {
+	if options.Delay <= 0 {
+		options.Delay = batcherDelayDefault
+	}
+	if batchMax := types.MaxBatchSize(types.OperationCreateTransfers); options.BatchSizeMax <= 0 ||
+		options.BatchSizeMax > batchMax {
+		options.BatchSizeMax = batchMax
+	}
+
+	b := &TransferBatcher{
+		client:  client,
+		options: options,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go b.flusher()
+	return b
+}
+
+// Submit queues transfer for the next batch and waits for its result, returning nil once it
+// was created, or ErrCreateTransfer with the reason it was not.
+//
+// If ctx is done before the transfer was submitted, it is dropped and ctx.Err() is returned.
+// If ctx is done later, ctx.Err() is returned but the transfer may still be created.
+// Transfers are reordered, so linked transfers fail with TransferLinkedEventChainOpen.
+func (b *TransferBatcher) Submit(ctx context.Context, transfer types.Transfer) error {
+	if transfer.TransferFlags().Linked {
+		return errors.ErrCreateTransfer{Result: types.TransferLinkedEventChainOpen}
+	}
+
+	item := &batchedTransfer{
+		ctx:      ctx,
+		transfer: transfer,
+		queued:   time.Now(),
+		result:   make(chan error, 1),
+	}
+	item.deadline, _ = ctx.Deadline()
+
+	b.mutex.Lock()
+	if b.closing {
+		b.mutex.Unlock()
+		return errors.ErrClientClosed{}
+	}
+	b.queue = append(b.queue, item)
+	b.mutex.Unlock()
+	b.notify()
+
+	select {
+	case err := <-item.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close submits the queued transfers and waits for their results. It does not close the client.
+func (b *TransferBatcher) Close() {
+	b.mutex.Lock()
+	b.closing = true
+	b.mutex.Unlock()
+	b.notify()
+	<-b.done
+}
+
+func (b *TransferBatcher) notify() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (b *TransferBatcher) flusher() {
+	defer close(b.done)
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		b.mutex.Lock()
+		flushAt, ok := b.flushAt()
+		closing := b.closing
+		b.mutex.Unlock()
+
+		if !ok {
+			if closing {
+				return
+			}
+			<-b.wake
+			continue
+		}
+
+		if wait := time.Until(flushAt); wait > 0 && !closing {
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-b.wake:
+				timer.Stop()
+				continue
+			}
+		}
+
+		b.flush(b.take())
+	}
+}
+
+// flushAt returns when the queued transfers must be flushed, which is right away once they fill
+// a batch, or false if none are queued.
+func (b *TransferBatcher) flushAt() (time.Time, bool) {
+	if len(b.queue) == 0 {
+		return time.Time{}, false
+	}
+	if len(b.queue) >= b.options.BatchSizeMax {
+		return time.Time{}, true
+	}
+
+	flushAt := b.queue[0].queued.Add(b.options.Delay)
+	for _, item := range b.queue {
+		flushAt = minTime(flushAt, item.queued.Add(b.options.Delay))
+		if !item.deadline.IsZero() {
+			flushAt = minTime(flushAt, item.deadline.Add(-b.options.Delay))
+		}
+	}
+	return flushAt, true
+}
+
+// take removes the next batch from the queue, ordered by deadline, with the transfers without a
+// deadline last and otherwise in the order they were queued. Transfers that are past their
+// deadline, or no longer waited for, fail first so as not to take up room in the batch.
+func (b *TransferBatcher) take() []*batchedTransfer {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	b.queue = slices.DeleteFunc(b.queue, func(item *batchedTransfer) bool {
+		if err := item.ctx.Err(); err != nil {
+			item.result <- err
+			return true
+		}
+		if !item.deadline.IsZero() && !now.Before(item.deadline) {
+			item.result <- context.DeadlineExceeded
+			return true
+		}
+		return false
+	})
+
+	slices.SortStableFunc(b.queue, func(x, y *batchedTransfer) int {
+		switch {
+		case x.deadline.IsZero() && y.deadline.IsZero():
+			return 0
+		case x.deadline.IsZero():
+			return 1
+		case y.deadline.IsZero():
+			return -1
+		default:
+			return x.deadline.Compare(y.deadline)
+		}
+	})
+
+	count := min(len(b.queue), b.options.BatchSizeMax)
+	batch := slices.Clone(b.queue[:count])
+	b.queue = slices.Delete(b.queue, 0, count)
+	return batch
+}
+
+func (b *TransferBatcher) flush(batch []*batchedTransfer) {
+	if len(batch) == 0 {
+		return
+	}
+
+	transfers := make([]types.Transfer, len(batch))
+	for i, item := range batch {
+		transfers[i] = item.transfer
+	}
+
+	results, err := b.client.CreateTransfers(transfers)
+	if err != nil {
+		for _, item := range batch {
+			item.result <- err
+		}
+		return
+	}
+
+	failed := make([]error, len(batch))
+	for _, result := range results {
+		failed[result.Index] = errors.ErrCreateTransfer{Result: result.Result}
+	}
+	for i, item := range batch {
+		item.result <- failed[i]
+	}
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,252 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// AccountCache caches the accounts that LookupAccounts returns, for up to a TTL, so that lookups
+// of hot accounts are answered without a request to the cluster.
+//
+// The cache is read-through: the accounts of a lookup that aren't cached are looked up, then
+// cached, and accounts that aren't found aren't cached. Creating transfers or accounts through a
+// client of the cache invalidates the accounts they touch, once the request is answered. Post and
+// void transfers, whose accounts are those of their pending transfer, invalidate the whole
+// cache. A lookup that overlaps such a request caches nothing, so that it can't cache balances
+// from before the request.
+//
+// Consistency: through a client of the cache, a lookup sees the transfers created through that
+// client once they are answered, as without the cache. Transfers created by other clients, and
+// pending transfers that expire, show up once the cached account expires, so a cached account
+// may be up to TTL behind the cluster. Read accounts for decisions that must see every transfer,
+// such as checking a balance before a withdrawal, without the cache, or let the cluster enforce
+// them with account flags.
+type AccountCache struct {
+	ttl      time.Duration
+	capacity int
+	now      func() time.Time
+
+	mutex   sync.Mutex
+	entries map[types.Uint128]cachedAccount
+	// epoch advances at the start and the end of every request that invalidates the cache, which
+	// writing counts while in flight.
+	epoch   uint64
+	writing int
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+type cachedAccount struct {
+	account types.Account
+	expires time.Time
+}
+
+// AccountCacheStats counts the accounts looked up through a cache, and invalidated.
+type AccountCacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64
+	Len           int
+}
+
+// NewAccountCache returns an empty cache of up to capacity accounts, each cached for ttl. A full
+// cache makes room by evicting the expired accounts, or else an arbitrary one.
+func NewAccountCache(ttl time.Duration, capacity int) *AccountCache {
+	return &AccountCache{
+		ttl:      ttl,
+		capacity: max(capacity, 1),
+		now:      time.Now,
+		entries:  make(map[types.Uint128]cachedAccount),
+	}
+}
+
+// WithAccountCache makes the client look accounts up through cache. A cache may be shared by
+// several clients of the same cluster, which then invalidate it for each other.
+//
+// The cache is an interceptor: it runs in the order it was added among those of WithInterceptor,
+// and answers lookups from the cache without calling the interceptors added after it, so add it
+// after those that check or authorize requests.
+func WithAccountCache(cache *AccountCache) ClientOption {
+	return WithInterceptor(cache.intercept)
+}
+
+// Invalidate evicts accounts from the cache, such as after they were changed by another client.
+func (cache *AccountCache) Invalidate(accountIDs ...types.Uint128) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.epoch++
+	for _, id := range accountIDs {
+		delete(cache.entries, id)
+	}
+	cache.invalidations.Add(uint64(len(accountIDs)))
+}
+
+// Purge evicts every account from the cache.
+func (cache *AccountCache) Purge() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.epoch++
+	cache.invalidations.Add(uint64(len(cache.entries)))
+	clear(cache.entries)
+}
+
+// Stats returns the counts of the cache since it was created.
+func (cache *AccountCache) Stats() AccountCacheStats {
+	cache.mutex.Lock()
+	length := len(cache.entries)
+	cache.mutex.Unlock()
+	return AccountCacheStats{
+		Hits:          cache.hits.Load(),
+		Misses:        cache.misses.Load(),
+		Invalidations: cache.invalidations.Load(),
+		Len:           length,
+	}
+}
+
+func (cache *AccountCache) intercept(ctx context.Context, op types.Operation, events any, next Invoker) (any, error) {
+	switch op {
+	case types.OperationLookupAccounts:
+		if accountIDs, ok := events.([]types.Uint128); ok {
+			return cache.lookup(ctx, accountIDs, next)
+		}
+	case types.OperationCreateAccounts, types.OperationCreateTransfers:
+		touched, all := touchedAccounts(events)
+		cache.beginWrite()
+		defer cache.endWrite(touched, all)
+	}
+	return next(ctx, op, events)
+}
+
+// touchedAccounts returns the accounts whose balances a request to create events may change, or
+// all if they can't be told from the events.
+func touchedAccounts(events any) (touched []types.Uint128, all bool) {
+	switch events := events.(type) {
+	case []types.Account:
+		for _, account := range events {
+			touched = append(touched, account.ID)
+		}
+	case []types.Transfer:
+		for _, transfer := range events {
+			if transfer.DebitAccountID == (types.Uint128{}) || transfer.CreditAccountID == (types.Uint128{}) {
+				return nil, true
+			}
+			touched = append(touched, transfer.DebitAccountID, transfer.CreditAccountID)
+		}
+	default:
+		return nil, true
+	}
+	return touched, false
+}
+
+func (cache *AccountCache) beginWrite() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.epoch++
+	cache.writing++
+}
+
+func (cache *AccountCache) endWrite(touched []types.Uint128, all bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.epoch++
+	cache.writing--
+	if all {
+		cache.invalidations.Add(uint64(len(cache.entries)))
+		clear(cache.entries)
+		return
+	}
+	for _, id := range touched {
+		if _, ok := cache.entries[id]; ok {
+			delete(cache.entries, id)
+			cache.invalidations.Add(1)
+		}
+	}
+}
+
+// lookup answers the lookup of accountIDs from the cache, looking up those that aren't cached
+// with next.
+func (cache *AccountCache) lookup(ctx context.Context, accountIDs []types.Uint128, next Invoker) (any, error) {
+	now := cache.now()
+	requested := make(map[types.Uint128]bool, len(accountIDs))
+	found := make(map[types.Uint128]types.Account, len(accountIDs))
+	var missing []types.Uint128
+
+	cache.mutex.Lock()
+	for _, id := range accountIDs {
+		if requested[id] {
+			continue
+		}
+		requested[id] = true
+		if entry, ok := cache.entries[id]; ok && now.Before(entry.expires) {
+			found[id] = entry.account
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	epoch, writing := cache.epoch, cache.writing
+	cache.mutex.Unlock()
+
+	cache.hits.Add(uint64(len(found)))
+	cache.misses.Add(uint64(len(missing)))
+	if len(missing) > 0 {
+		result, err := next(ctx, types.OperationLookupAccounts, missing)
+		if err != nil {
+			return nil, err
+		}
+		accounts, ok := result.([]types.Account)
+		if !ok {
+			return result, nil
+		}
+		for _, account := range accounts {
+			found[account.ID] = account
+		}
+		if writing == 0 {
+			cache.store(accounts, epoch, now)
+		}
+	}
+
+	accounts := make([]types.Account, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		if account, ok := found[id]; ok {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+// store caches accounts looked up at now, unless the cache was invalidated since epoch.
+func (cache *AccountCache) store(accounts []types.Account, epoch uint64, now time.Time) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if cache.epoch != epoch {
+		return
+	}
+	for _, account := range accounts {
+		if _, ok := cache.entries[account.ID]; !ok && len(cache.entries) >= cache.capacity {
+			cache.evict(now)
+		}
+		cache.entries[account.ID] = cachedAccount{account: account, expires: now.Add(cache.ttl)}
+	}
+}
+
+// evict makes room for an account, by evicting the expired ones, or else an arbitrary one.
+func (cache *AccountCache) evict(now time.Time) {
+	for id, entry := range cache.entries {
+		if !now.Before(entry.expires) {
+			delete(cache.entries, id)
+		}
+	}
+	if len(cache.entries) < cache.capacity {
+		return
+	}
+	for id := range cache.entries {
+		delete(cache.entries, id)
+		return
+	}
+}
@@ -0,0 +1,298 @@
+package tigerbeetle_go
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const (
+	// changesPollInterval is how long a change stream waits before polling again once it has
+	// caught up with the accounts it tails.
+	changesPollInterval = 100 * time.Millisecond
+
+	// changesExpiryGrace is how far the local clock must be past a pending transfer's timeout
+	// before it is reported as expired, to tolerate clock skew with the cluster.
+	changesExpiryGrace = time.Second
+)
+
+// ChangeType is the kind of change reported by a change stream.
+type ChangeType uint8
+
+const (
+	ChangeTransferCreated ChangeType = iota + 1
+	ChangePendingPosted
+	ChangePendingVoided
+	ChangePendingExpired
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeTransferCreated:
+		return "transfer_created"
+	case ChangePendingPosted:
+		return "pending_posted"
+	case ChangePendingVoided:
+		return "pending_voided"
+	case ChangePendingExpired:
+		return "pending_expired"
+	default:
+		return "unknown"
+	}
+}
+
+// ChangeEvent is a single change reported by a change stream.
+//
+// Transfer is the transfer that caused the change, or for ChangePendingExpired the pending
+// transfer that expired. A stream ends with an event holding a non-nil Err if polling fails.
+type ChangeEvent struct {
+	Type      ChangeType
+	Transfer  types.Transfer
+	Timestamp uint64
+	Err       error
+}
+
+// StreamChanges tails the transfers of accountIDs with a timestamp after fromTimestamp and
+// reports them on the returned channel in timestamp order, until ctx is done.
+//
+// The cluster has no change feed, so the stream polls GetAccountTransfers for each account, a
+// page at a time. A transfer is only reported once every account has been read past its
+// timestamp, so that a transfer of one account read late is never reported after a later one
+// of another.
+// Expiry is not recorded as a transfer, so pending transfers are reported as expired once their
+// timeout has passed without them being posted or voided, as seen by the cluster timestamps
+// observed or the local clock.
+func (c *c_client) StreamChanges(
+	ctx context.Context,
+	fromTimestamp uint64,
+	accountIDs []types.Uint128,
+) <-chan ChangeEvent {
+	return streamChanges(ctx, c, fromTimestamp, accountIDs)
+}
+
+func streamChanges(
+	ctx context.Context,
+	client Client,
+	fromTimestamp uint64,
+	accountIDs []types.Uint128,
+) <-chan ChangeEvent {
+	events := make(chan ChangeEvent)
+	tailer := &changeTailer{
+		client:    client,
+		accounts:  slices.Clone(accountIDs),
+		cursors:   make(map[types.Uint128]uint64, len(accountIDs)),
+		bounds:    make(map[types.Uint128]uint64, len(accountIDs)),
+		seen:      make(map[types.Uint128]types.Transfer),
+		pending:   make(map[types.Uint128]types.Transfer),
+		watermark: fromTimestamp,
+	}
+	for _, accountID := range accountIDs {
+		tailer.cursors[accountID] = fromTimestamp
+		tailer.bounds[accountID] = fromTimestamp
+	}
+
+	go func() {
+		defer close(events)
+
+		emit := func(event ChangeEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			changes, more, err := tailer.poll()
+			if err != nil {
+				emit(ChangeEvent{Err: err})
+				return
+			}
+			for _, change := range changes {
+				if !emit(change) {
+					return
+				}
+			}
+
+			// Keep reading a backlog without waiting.
+			if more {
+				if ctx.Err() != nil {
+					return
+				}
+				continue
+			}
+
+			select {
+			case <-time.After(changesPollInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
+
+type changeTailer struct {
+	client Client
+
+	// accounts holds the tailed accounts, in the order they are polled.
+	accounts []types.Uint128
+
+	// cursors holds the timestamp of the last transfer read for each account.
+	cursors map[types.Uint128]uint64
+
+	// bounds holds the timestamp up to which every transfer of each account has been read.
+	bounds map[types.Uint128]uint64
+
+	// watermark is the lowest of bounds: every transfer up to it has been read.
+	watermark uint64
+
+	// held holds the transfers read but not reported yet, as they are past the watermark, in
+	// timestamp order.
+	held []types.Transfer
+
+	// seen holds the transfers already read that may still be read through another tailed
+	// account, until every cursor has passed them.
+	seen map[types.Uint128]types.Transfer
+
+	// pending holds the pending transfers with a timeout that have not been resolved yet.
+	pending map[types.Uint128]types.Transfer
+
+	// clusterTime is the latest cluster timestamp observed.
+	clusterTime uint64
+}
+
+// poll reads the next page of transfers of the accounts that are not past the watermark, and
+// returns the changes up to the new watermark. It reports more if a page was full, so that
+// there may be more to read right away.
+func (t *changeTailer) poll() (changes []ChangeEvent, more bool, err error) {
+	if len(t.accounts) == 0 {
+		return nil, false, nil
+	}
+
+	limit := types.MaxBatchSize(types.OperationGetAccountTransfers)
+	for _, accountID := range t.accounts {
+		cursor := t.cursors[accountID]
+		// An account read past the watermark waits for the others to catch up, so that at
+		// most a page of each account is held.
+		if cursor > t.watermark {
+			continue
+		}
+
+		// Cluster timestamps only grow, so every transfer up to the latest one observed had
+		// been created before this read.
+		observed := t.clusterTime
+		page, err := t.client.GetAccountTransfers(types.AccountFilter{
+			AccountID:    accountID,
+			TimestampMin: cursor + 1,
+			Limit:        uint32(limit),
+			Flags: types.AccountFilterFlags{
+				Debits:  true,
+				Credits: true,
+			}.ToUint32(),
+		})
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, transfer := range page {
+			cursor = transfer.Timestamp
+			t.clusterTime = max(t.clusterTime, transfer.Timestamp)
+			if _, ok := t.seen[transfer.ID]; !ok {
+				t.seen[transfer.ID] = transfer
+				t.held = append(t.held, transfer)
+			}
+		}
+		t.cursors[accountID] = cursor
+
+		if len(page) < limit {
+			t.bounds[accountID] = max(t.bounds[accountID], cursor, observed)
+		} else {
+			t.bounds[accountID] = max(t.bounds[accountID], cursor)
+			more = true
+		}
+	}
+
+	t.watermark = t.bounds[t.accounts[0]]
+	for _, bound := range t.bounds {
+		t.watermark = min(t.watermark, bound)
+	}
+
+	for id, transfer := range t.seen {
+		if t.passed(transfer.DebitAccountID, transfer) && t.passed(transfer.CreditAccountID, transfer) {
+			delete(t.seen, id)
+		}
+	}
+
+	slices.SortFunc(t.held, func(a, b types.Transfer) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+	ready, _ := slices.BinarySearchFunc(t.held, t.watermark+1, func(transfer types.Transfer, timestamp uint64) int {
+		return cmp.Compare(transfer.Timestamp, timestamp)
+	})
+
+	for _, transfer := range t.held[:ready] {
+		// Report pending transfers that expired before this transfer was created first.
+		changes = t.expire(changes, transfer.Timestamp)
+
+		flags := transfer.TransferFlags()
+		change := ChangeEvent{
+			Type:      ChangeTransferCreated,
+			Transfer:  transfer,
+			Timestamp: transfer.Timestamp,
+		}
+		switch {
+		case flags.PostPendingTransfer:
+			change.Type = ChangePendingPosted
+			delete(t.pending, transfer.PendingID)
+		case flags.VoidPendingTransfer:
+			change.Type = ChangePendingVoided
+			delete(t.pending, transfer.PendingID)
+		case flags.Pending && transfer.Timeout > 0:
+			t.pending[transfer.ID] = transfer
+		}
+		changes = append(changes, change)
+	}
+	t.held = slices.Delete(t.held, 0, ready)
+
+	// Expiry is reported by the local clock too, but never past a transfer still held, which
+	// may resolve the pending transfer first.
+	now := uint64(time.Now().Add(-changesExpiryGrace).UnixNano())
+	expiry := max(t.watermark, now)
+	if len(t.held) > 0 {
+		expiry = min(expiry, t.held[0].Timestamp-1)
+	}
+	return t.expire(changes, expiry), more, nil
+}
+
+// passed reports whether transfer can no longer be read through accountID.
+func (t *changeTailer) passed(accountID types.Uint128, transfer types.Transfer) bool {
+	cursor, tailed := t.cursors[accountID]
+	return !tailed || cursor >= transfer.Timestamp
+}
+
+// expire appends the pending transfers whose timeout has passed at timestamp.
+func (t *changeTailer) expire(changes []ChangeEvent, timestamp uint64) []ChangeEvent {
+	start := len(changes)
+	for id, pending := range t.pending {
+		if expiresAt := pending.ExpiresAt(); expiresAt <= timestamp {
+			delete(t.pending, id)
+			changes = append(changes, ChangeEvent{
+				Type:      ChangePendingExpired,
+				Transfer:  pending,
+				Timestamp: expiresAt,
+			})
+		}
+	}
+
+	expired := changes[start:]
+	slices.SortFunc(expired, func(a, b ChangeEvent) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+	return changes
+}
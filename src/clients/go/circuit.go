@@ -0,0 +1,172 @@
+package tigerbeetle_go
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+)
+
+// CircuitState is the state of the circuit breaker of WithCircuitBreaker.
+type CircuitState uint8
+
+const (
+	// CircuitClosed lets requests through, as long as they don't keep timing out.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests with ErrCircuitOpen, without submitting them.
+	CircuitOpen
+	// CircuitHalfOpen lets a single request through as a probe, whose reply closes the circuit
+	// again, and fails the others with ErrCircuitOpen.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Defaults of CircuitBreaker.
+const (
+	circuitThresholdDefault     = 5
+	circuitTimeoutDefault       = 5 * time.Second
+	circuitProbeIntervalDefault = time.Second
+)
+
+// CircuitBreaker configures the circuit breaker of WithCircuitBreaker.
+type CircuitBreaker struct {
+	// Threshold is how many requests in a row must time out for the circuit to open. Defaults
+	// to 5.
+	Threshold int
+	// Timeout is how long a request may wait for a reply before it counts as timed out. It
+	// keeps waiting all the same, as tb_client can't cancel a request. Defaults to 5s.
+	Timeout time.Duration
+	// ProbeInterval is how long the circuit stays open before letting a probe through, and
+	// again after every probe that timed out. Defaults to 1s.
+	ProbeInterval time.Duration
+	// OnStateChange is called on every change of state, in order. It must not block, nor submit
+	// requests through the client.
+	OnStateChange func(change CircuitStateChange)
+}
+
+// CircuitStateChange describes a change of CircuitState, as passed to
+// CircuitBreaker.OnStateChange.
+type CircuitStateChange struct {
+	From CircuitState
+	To   CircuitState
+	At   time.Time
+}
+
+// WithCircuitBreaker makes the client fail fast while the cluster is unavailable, rather than
+// pile requests up to concurrencyMax: once Threshold requests in a row have gone without a reply
+// for Timeout, the circuit opens and requests fail with ErrCircuitOpen. Every ProbeInterval, a
+// single request goes through as a probe, and the circuit closes as soon as one is answered in
+// time. Stats reports the state of the circuit.
+func WithCircuitBreaker(breaker CircuitBreaker) ClientOption {
+	return func(options *clientOptions) {
+		options.circuitBreaker = &breaker
+	}
+}
+
+type circuitBreaker struct {
+	config CircuitBreaker
+
+	// notifying serializes the calls to OnStateChange, which are made outside of mutex.
+	notifying sync.Mutex
+
+	mutex    sync.Mutex
+	state    CircuitState
+	timeouts int
+	// probeAt is when the open circuit lets the next probe through, and when the half-open
+	// circuit gives up on a probe that was never submitted and lets another one through.
+	probeAt time.Time
+}
+
+func newCircuitBreaker(config CircuitBreaker) *circuitBreaker {
+	if config.Threshold <= 0 {
+		config.Threshold = circuitThresholdDefault
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = circuitTimeoutDefault
+	}
+	if config.ProbeInterval <= 0 {
+		config.ProbeInterval = circuitProbeIntervalDefault
+	}
+	return &circuitBreaker{config: config}
+}
+
+func (b *circuitBreaker) State() CircuitState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state
+}
+
+// allow fails with ErrCircuitOpen unless the circuit lets a request through, as a probe if it
+// is open.
+func (b *circuitBreaker) allow() error {
+	b.notifying.Lock()
+	defer b.notifying.Unlock()
+	b.mutex.Lock()
+	from := b.state
+	if b.state != CircuitClosed {
+		now := time.Now()
+		if now.Before(b.probeAt) {
+			b.mutex.Unlock()
+			return errors.ErrCircuitOpen{}
+		}
+		b.state = CircuitHalfOpen
+		b.probeAt = now.Add(b.config.Timeout)
+	}
+	to := b.state
+	b.mutex.Unlock()
+	b.notify(from, to)
+	return nil
+}
+
+// watch starts watching a request let through by allow, and returns the function to call once
+// it is answered.
+func (b *circuitBreaker) watch() func() {
+	var once sync.Once
+	timer := time.AfterFunc(b.config.Timeout, func() {
+		once.Do(func() { b.completed(false) })
+	})
+	return func() {
+		timer.Stop()
+		once.Do(func() { b.completed(true) })
+	}
+}
+
+// completed counts a request that was answered in time, or that timed out.
+func (b *circuitBreaker) completed(answered bool) {
+	b.notifying.Lock()
+	defer b.notifying.Unlock()
+	b.mutex.Lock()
+	from := b.state
+	if answered {
+		b.timeouts = 0
+		b.state = CircuitClosed
+	} else {
+		b.timeouts++
+		if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.timeouts >= b.config.Threshold) {
+			b.state = CircuitOpen
+			b.probeAt = time.Now().Add(b.config.ProbeInterval)
+		}
+	}
+	to := b.state
+	b.mutex.Unlock()
+	b.notify(from, to)
+}
+
+// notify calls OnStateChange if the state changed, with notifying held.
+func (b *circuitBreaker) notify(from CircuitState, to CircuitState) {
+	if from == to || b.config.OnStateChange == nil {
+		return
+	}
+	b.config.OnStateChange(CircuitStateChange{From: from, To: to, At: time.Now()})
+}
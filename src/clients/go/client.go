@@ -0,0 +1,109 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Client is a connection to a cluster. It is the union of the operations and the lifecycle, so
+// that code needing only one of them can depend on the narrower interface.
+type Client interface {
+	ClientOperations
+	ClientLifecycle
+}
+
+// ClientOperations are the requests a client submits to the cluster.
+type ClientOperations interface {
+	CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error)
+	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+	LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error)
+	LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error)
+	GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error)
+	GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error)
+
+	// TryCreateAccounts and TryCreateTransfers fail with ErrConcurrencyExceeded rather than
+	// wait for a free request slot, even with ConcurrencyBlock.
+	TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error)
+	TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+
+	// CreateAccountsAsync and CreateTransfersAsync return once the request is submitted, with
+	// the future of its results, so that a single goroutine can keep many requests in flight.
+	CreateAccountsAsync(accounts []types.Account) (*Future[[]types.AccountEventResult], error)
+	CreateTransfersAsync(transfers []types.Transfer) (*Future[[]types.TransferEventResult], error)
+
+	CreateAccount(account types.Account) error
+	CreateTransfer(transfer types.Transfer) error
+	LookupAccount(accountID types.Uint128) (types.Account, bool, error)
+	LookupAccountsStream(accountIDs iter.Seq[types.Uint128]) iter.Seq2[types.Account, error]
+	StreamChanges(
+		ctx context.Context,
+		fromTimestamp uint64,
+		accountIDs []types.Uint128,
+	) <-chan ChangeEvent
+	TailTransfers(
+		ctx context.Context,
+		accountID types.Uint128,
+		sinceTimestamp uint64,
+	) <-chan TailEvent
+	WatchBalance(
+		ctx context.Context,
+		accountID types.Uint128,
+		options WatchBalanceOptions,
+	) <-chan BalanceSnapshot
+
+	// LookupAccountsInto, LookupTransfersInto, GetAccountTransfersInto and GetAccountHistoryInto
+	// decode the results straight into buf rather than into a new slice, returning the part of
+	// buf that was written. The capacity of buf must have room for a result per ID, or for as
+	// many as the filter's limit, and reusing buf across requests avoids allocating at all.
+	LookupAccountsInto(accountIDs []types.Uint128, buf []types.Account) ([]types.Account, error)
+	LookupTransfersInto(transferIDs []types.Uint128, buf []types.Transfer) ([]types.Transfer, error)
+	GetAccountTransfersInto(filter types.AccountFilter, buf []types.Transfer) ([]types.Transfer, error)
+	GetAccountHistoryInto(
+		filter types.AccountFilter,
+		buf []types.AccountBalance,
+	) ([]types.AccountBalance, error)
+
+	PostPending(pendingID types.Uint128, amount types.Uint128) error
+	VoidPending(pendingID types.Uint128) error
+
+	// SubmitRaw submits the events of op encoded in their wire layout and returns the encoded
+	// results, for operations without a typed method.
+	SubmitRaw(op types.Operation, body []byte) ([]byte, error)
+}
+
+// ClientLifecycle manages the connection of a client rather than submitting operations.
+type ClientLifecycle interface {
+	// MessageSizeMax returns the size of the largest message exchanged with the cluster, which
+	// bounds the events and results of every request.
+	MessageSizeMax() int
+
+	// UpdateAddresses switches the client to a new list of replica addresses.
+	UpdateAddresses(addresses []string) error
+
+	// Pause holds back new requests for a maintenance window, and Resume lets them through.
+	Pause(ctx context.Context, mode PauseMode) error
+	Resume()
+
+	// State returns the state of the client's session with the cluster, as described by
+	// ConnectionState.
+	State() ConnectionState
+
+	// Stats returns a snapshot of the requests in flight and queued, and of the counters of the
+	// requests completed.
+	Stats() Stats
+
+	// Ping submits a request to the cluster and returns how long the reply took, for health
+	// checks. It fails with ctx.Err() if ctx is done first, as when the cluster is unreachable,
+	// though the request stays in flight until the cluster replies.
+	Ping(ctx context.Context) (time.Duration, error)
+
+	// Nop submits a request that tb_client rejects without sending it, which measures the
+	// overhead of the client alone. Use Ping to reach the cluster.
+	Nop() error
+	Close()
+	CloseContext(ctx context.Context) error
+	Done() <-chan struct{}
+}
@@ -0,0 +1,77 @@
+// Command tb-archive-verify checks that a transfer archive written by pkg/archive is intact.
+//
+//	tb-archive-verify [-public-key <hex>] [-format table|json|csv] <archive>...
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/archive"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/output"
+)
+
+func main() {
+	publicKeyHex := flag.String("public-key", "", "hex-encoded ed25519 public key that signed every batch")
+	var format output.Format
+	flag.Var(&format, "format", "output format: table, json or csv")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		log.Fatalf("Usage: tb-archive-verify [-public-key <hex>] [-format table|json|csv] <archive>...")
+	}
+
+	var publicKey ed25519.PublicKey
+	if *publicKeyHex != "" {
+		key, err := hex.DecodeString(*publicKeyHex)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			log.Fatalf("Invalid public key: %s", *publicKeyHex)
+		}
+		publicKey = key
+	}
+
+	writer, err := output.NewWriter(os.Stdout, format, "archive", "status", "batches", "transfers", "head", "error")
+	if err != nil {
+		log.Fatalf("Error writing output: %s", err)
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Error opening archive: %s", err)
+		}
+
+		summary, err := archive.Verify(file, publicKey)
+		file.Close()
+
+		status, reason := "OK", ""
+		if err != nil {
+			status, reason = "FAIL", err.Error()
+			failed = true
+		}
+		err = writer.Row(
+			path,
+			status,
+			strconv.FormatUint(summary.Batches, 10),
+			strconv.FormatUint(summary.Transfers, 10),
+			hex.EncodeToString(summary.Head[:]),
+			reason,
+		)
+		if err != nil {
+			log.Fatalf("Error writing output: %s", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		log.Fatalf("Error writing output: %s", err)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,80 @@
+// Command tb-benchmark load-tests a cluster through the Go client, and reports the
+// throughput and batch latency percentiles it observed.
+//
+//	tb-benchmark [-addresses 3000] [-accounts 10000] [-transfers 10000000] [-batch-size 8190]
+//	    [-zipf 0] [-two-phase-ratio 0] [-rate 0] [-format table|json|csv]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/bench"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/output"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+var percentiles = []float64{1, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99, 100}
+
+func main() {
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	var config bench.Config
+	flag.IntVar(&config.AccountCount, "accounts", 10_000, "number of accounts to create")
+	flag.IntVar(&config.TransferCount, "transfers", 10_000_000, "number of transfers to create")
+	flag.IntVar(&config.BatchSize, "batch-size", 0, "transfers per request, 0 for the most that fit")
+	flag.Float64Var(&config.ZipfSkew, "zipf", 0, "Zipf exponent above 1 for hot accounts, 0 for uniform")
+	flag.Float64Var(&config.TwoPhaseRatio, "two-phase-ratio", 0, "fraction of transfers created pending and posted")
+	flag.Float64Var(&config.TransfersPerSecond, "rate", 0, "transfers per second to offer, 0 for unlimited")
+	flag.Int64Var(&config.Seed, "seed", 0, "seed for choosing accounts")
+	var format output.Format
+	flag.Var(&format, "format", "output format: table, json or csv")
+	flag.Parse()
+
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(*clusterID), strings.Split(*addresses, ","), 1)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := bench.Run(ctx, client, config)
+	if err != nil && ctx.Err() == nil {
+		log.Fatalf("Error running benchmark: %s", err)
+	}
+
+	writer, err := output.NewWriter(os.Stdout, format, "metric", "value")
+	if err != nil {
+		log.Fatalf("Error writing output: %s", err)
+	}
+	rows := [][]string{
+		{"batches", strconv.Itoa(report.Batches)},
+		{"transfers", strconv.Itoa(report.Transfers)},
+		{"rejected", strconv.Itoa(report.Rejected)},
+		{"duration_s", strconv.FormatFloat(report.Duration.Seconds(), 'f', 2, 64)},
+		{"throughput_tps", strconv.FormatFloat(report.Throughput(), 'f', 0, 64)},
+	}
+	for _, p := range percentiles {
+		latency := report.Percentile(p)
+		rows = append(rows, []string{
+			"latency_p" + strconv.FormatFloat(p, 'f', -1, 64) + "_ms",
+			strconv.FormatFloat(float64(latency.Microseconds())/1000, 'f', 3, 64),
+		})
+	}
+	for _, row := range rows {
+		if err := writer.Row(row...); err != nil {
+			log.Fatalf("Error writing output: %s", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		log.Fatalf("Error writing output: %s", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Command tb-cross runs go build for another platform with zig cc as the C compiler and linker,
+// as described by pkg/crossbuild, so that a program using the client cross-compiles from any
+// host. It runs from the directory of the program, and checks first that the client module has
+// the static library of the target.
+//
+//	GOOS=linux GOARCH=arm64 tb-cross [-zig zig] [go build flags and packages]
+//	tb-cross -goos linux -goarch arm64 -- -o app-arm64 .
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/crossbuild"
+)
+
+const clientModule = "github.com/tigerbeetle/tigerbeetle-go"
+
+func main() {
+	goos := flag.String("goos", env("GOOS", runtime.GOOS), "target operating system")
+	goarch := flag.String("goarch", env("GOARCH", runtime.GOARCH), "target architecture")
+	zig := flag.String("zig", "zig", "path of the zig executable")
+	flag.Parse()
+
+	target, err := crossbuild.Lookup(*goos, *goarch)
+	if err != nil {
+		log.Fatalf("Error selecting target: %s", err)
+	}
+
+	moduleDir, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", clientModule).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			log.Fatalf("Error locating %s: %s", clientModule, exitErr.Stderr)
+		}
+		log.Fatalf("Error locating %s: %s", clientModule, err)
+	}
+	if err := target.Check(strings.TrimSpace(string(moduleDir))); err != nil {
+		log.Fatalf("Error checking target: %s", err)
+	}
+	if _, err := exec.LookPath(*zig); err != nil {
+		log.Fatalf("Error locating zig: %s", err)
+	}
+
+	build := exec.Command("go", append([]string{"build"}, flag.Args()...)...)
+	build.Env = append(os.Environ(), target.Env(*zig)...)
+	build.Stdout = os.Stdout
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		log.Fatalf("Error running go build: %s", err)
+	}
+}
+
+func env(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// decimalPrecision is the number of digits of the 128-bit decimals, the most that Arrow holds.
+const decimalPrecision = 38
+
+var (
+	// decimalType is the type of amounts and balances.
+	decimalType = &arrow.Decimal128Type{Precision: decimalPrecision, Scale: 0}
+	// idType is the type of IDs and user_data_128, in big-endian order.
+	idType = &arrow.FixedSizeBinaryType{ByteWidth: 16}
+)
+
+var transferSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "id", Type: idType},
+	{Name: "debit_account_id", Type: idType},
+	{Name: "credit_account_id", Type: idType},
+	{Name: "amount", Type: decimalType},
+	{Name: "pending_id", Type: idType},
+	{Name: "user_data_128", Type: idType},
+	{Name: "user_data_64", Type: arrow.PrimitiveTypes.Uint64},
+	{Name: "user_data_32", Type: arrow.PrimitiveTypes.Uint32},
+	{Name: "timeout", Type: arrow.PrimitiveTypes.Uint32},
+	{Name: "ledger", Type: arrow.PrimitiveTypes.Uint32},
+	{Name: "code", Type: arrow.PrimitiveTypes.Uint16},
+	{Name: "flags", Type: arrow.PrimitiveTypes.Uint16},
+	{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ns},
+}, nil)
+
+var accountSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "id", Type: idType},
+	{Name: "debits_pending", Type: decimalType},
+	{Name: "debits_posted", Type: decimalType},
+	{Name: "credits_pending", Type: decimalType},
+	{Name: "credits_posted", Type: decimalType},
+	{Name: "user_data_128", Type: idType},
+	{Name: "user_data_64", Type: arrow.PrimitiveTypes.Uint64},
+	{Name: "user_data_32", Type: arrow.PrimitiveTypes.Uint32},
+	{Name: "ledger", Type: arrow.PrimitiveTypes.Uint32},
+	{Name: "code", Type: arrow.PrimitiveTypes.Uint16},
+	{Name: "flags", Type: arrow.PrimitiveTypes.Uint16},
+	{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ns},
+}, nil)
+
+// batch builds record batches of the rows appended, writing each once it holds rowsMax rows.
+type batch struct {
+	builder *array.RecordBuilder
+	rows    int
+	rowsMax int
+	writer  recordWriter
+}
+
+func newBatch(schema *arrow.Schema, rowsMax int, writer recordWriter) *batch {
+	return &batch{
+		builder: array.NewRecordBuilder(memory.DefaultAllocator, schema),
+		rowsMax: rowsMax,
+		writer:  writer,
+	}
+}
+
+func (b *batch) appendTransfer(transfer types.Transfer) error {
+	amount, err := toDecimal("amount", transfer.ID, transfer.Amount)
+	if err != nil {
+		return err
+	}
+	b.id(0, transfer.ID)
+	b.id(1, transfer.DebitAccountID)
+	b.id(2, transfer.CreditAccountID)
+	b.builder.Field(3).(*array.Decimal128Builder).Append(amount)
+	b.id(4, transfer.PendingID)
+	b.id(5, transfer.UserData128)
+	b.builder.Field(6).(*array.Uint64Builder).Append(transfer.UserData64)
+	b.builder.Field(7).(*array.Uint32Builder).Append(transfer.UserData32)
+	b.builder.Field(8).(*array.Uint32Builder).Append(transfer.Timeout)
+	b.builder.Field(9).(*array.Uint32Builder).Append(transfer.Ledger)
+	b.builder.Field(10).(*array.Uint16Builder).Append(transfer.Code)
+	b.builder.Field(11).(*array.Uint16Builder).Append(transfer.Flags)
+	b.builder.Field(12).(*array.TimestampBuilder).Append(arrow.Timestamp(transfer.Timestamp))
+	return b.appended()
+}
+
+func (b *batch) appendAccount(account types.Account) error {
+	balances := make([]decimal128.Num, 4)
+	for i, balance := range []struct {
+		name  string
+		value types.Uint128
+	}{
+		{"debits_pending", account.DebitsPending},
+		{"debits_posted", account.DebitsPosted},
+		{"credits_pending", account.CreditsPending},
+		{"credits_posted", account.CreditsPosted},
+	} {
+		var err error
+		if balances[i], err = toDecimal(balance.name, account.ID, balance.value); err != nil {
+			return err
+		}
+	}
+	b.id(0, account.ID)
+	for i, balance := range balances {
+		b.builder.Field(1 + i).(*array.Decimal128Builder).Append(balance)
+	}
+	b.id(5, account.UserData128)
+	b.builder.Field(6).(*array.Uint64Builder).Append(account.UserData64)
+	b.builder.Field(7).(*array.Uint32Builder).Append(account.UserData32)
+	b.builder.Field(8).(*array.Uint32Builder).Append(account.Ledger)
+	b.builder.Field(9).(*array.Uint16Builder).Append(account.Code)
+	b.builder.Field(10).(*array.Uint16Builder).Append(account.Flags)
+	b.builder.Field(11).(*array.TimestampBuilder).Append(arrow.Timestamp(account.Timestamp))
+	return b.appended()
+}
+
+// id appends value to field as 16 bytes in big-endian order.
+func (b *batch) id(field int, value types.Uint128) {
+	bytes := value.Bytes()
+	for i, j := 0, len(bytes)-1; i < j; i, j = i+1, j-1 {
+		bytes[i], bytes[j] = bytes[j], bytes[i]
+	}
+	b.builder.Field(field).(*array.FixedSizeBinaryBuilder).Append(bytes[:])
+}
+
+func (b *batch) appended() error {
+	b.rows++
+	if b.rows < b.rowsMax {
+		return nil
+	}
+	return b.flush()
+}
+
+// flush writes the rows appended since the last flush as a record batch, if any.
+func (b *batch) flush() error {
+	if b.rows == 0 {
+		return nil
+	}
+	record := b.builder.NewRecord()
+	defer record.Release()
+	b.rows = 0
+	return b.writer.Write(record)
+}
+
+func (b *batch) release() {
+	b.builder.Release()
+}
+
+// toDecimal returns value as a decimal, or an error naming the field and the ID of the row if
+// it has more digits than the decimals hold.
+func toDecimal(field string, id types.Uint128, value types.Uint128) (decimal128.Num, error) {
+	bytes := value.Bytes()
+	var low, high uint64
+	for i := 7; i >= 0; i-- {
+		low = low<<8 | uint64(bytes[i])
+		high = high<<8 | uint64(bytes[8+i])
+	}
+	// A high bit set would read as negative.
+	num := decimal128.New(int64(high), low)
+	if high>>63 != 0 || !num.FitsInPrecision(decimalPrecision) {
+		return decimal128.Num{}, fmt.Errorf("%s %s of %s exceeds %d digits", field, value, id, decimalPrecision)
+	}
+	return num, nil
+}
@@ -0,0 +1,200 @@
+// Command tb-export exports the transfers of a set of accounts, or the accounts themselves, to a
+// Parquet or Arrow IPC file, for loading into DuckDB, Spark or a warehouse:
+//
+//	tb-export -accounts 1,2,3 -output transfers.parquet
+//	duckdb -c "SELECT ledger, sum(amount) FROM 'transfers.parquet' GROUP BY ledger"
+//
+// The transfers are paged with GetAccountTransfers and written in record batches of -batch-rows
+// rows as they arrive, so an export of any size takes little memory. A transfer between two of
+// the accounts is written once.
+//
+// Amounts and balances are 128-bit decimal columns, of 38 digits, the most that Arrow and the
+// engines reading Parquet support; a larger value fails the export. IDs and user_data_128 are
+// 16-byte binary columns in big-endian order, so they sort as the integers do, since random IDs
+// take all 128 bits. Timestamps are nanosecond timestamps in UTC.
+//
+//	tb-export -accounts <id>[,<id>...] [-table transfers|accounts] [-format parquet|arrow]
+//	    [-output path] [-timestamp-min 0] [-timestamp-max 0] [-batch-rows 65536]
+//	    [-addresses 3000] [-cluster 0]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/reconcile"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// recordWriter writes record batches to a file, as the Parquet and Arrow IPC writers do.
+type recordWriter interface {
+	Write(record arrow.Record) error
+	Close() error
+}
+
+func main() {
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	accounts := flag.String("accounts", "", "comma-separated decimal IDs of the accounts to export")
+	table := flag.String("table", "transfers", "what to export: transfers or accounts")
+	format := flag.String("format", "parquet", "file format: parquet or arrow")
+	outputPath := flag.String("output", "-", "file to write, or - for stdout")
+	timestampMin := flag.Uint64("timestamp-min", 0, "export transfers from this timestamp, inclusive")
+	timestampMax := flag.Uint64("timestamp-max", 0, "export transfers up to this timestamp, inclusive")
+	batchRows := flag.Int("batch-rows", 64*1024, "rows per record batch")
+	flag.Parse()
+
+	if *accounts == "" || flag.NArg() != 0 || *batchRows <= 0 {
+		log.Fatalf("Usage: tb-export -accounts <id>[,<id>...] [-table transfers|accounts] [-format parquet|arrow] [-output path]")
+	}
+	var accountIDs []types.Uint128
+	for _, account := range strings.Split(*accounts, ",") {
+		id, err := types.DecStringToUint128(strings.TrimSpace(account))
+		if err != nil {
+			log.Fatalf("Error parsing account ID %q: %s", account, err)
+		}
+		accountIDs = append(accountIDs, id)
+	}
+
+	var schema *arrow.Schema
+	switch *table {
+	case "transfers":
+		schema = transferSchema
+	case "accounts":
+		schema = accountSchema
+	default:
+		log.Fatalf("Unknown table %q, expected transfers or accounts", *table)
+	}
+
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(*clusterID),
+		strings.Split(*addresses, ","),
+		1,
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	var output io.WriteCloser = os.Stdout
+	if *outputPath != "-" {
+		if output, err = os.Create(*outputPath); err != nil {
+			log.Fatalf("Error creating output: %s", err)
+		}
+	}
+	writer, err := newRecordWriter(*format, schema, output)
+	if err != nil {
+		log.Fatalf("Error creating writer: %s", err)
+	}
+
+	batch := newBatch(schema, *batchRows, writer)
+	defer batch.release()
+	var rows uint64
+	switch *table {
+	case "transfers":
+		rows, err = exportTransfers(client, accountIDs, *timestampMin, *timestampMax, batch)
+	case "accounts":
+		rows, err = exportAccounts(client, accountIDs, batch)
+	}
+	if err == nil {
+		err = batch.flush()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil && output != os.Stdout {
+		err = output.Close()
+	}
+	if err != nil {
+		log.Fatalf("Error exporting: %s", err)
+	}
+	log.Printf("Exported %d %s", rows, *table)
+}
+
+func newRecordWriter(format string, schema *arrow.Schema, output io.Writer) (recordWriter, error) {
+	switch format {
+	case "parquet":
+		return pqarrow.NewFileWriter(
+			schema,
+			output,
+			parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Zstd)),
+			// Storing the Arrow schema keeps the unsigned integers and the time zone when the
+			// file is read back into Arrow.
+			pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()),
+		)
+	case "arrow":
+		return ipc.NewFileWriter(output, ipc.WithSchema(schema), ipc.WithAllocator(memory.DefaultAllocator))
+	}
+	return nil, fmt.Errorf("unknown format %q, expected parquet or arrow", format)
+}
+
+// exportTransfers writes the transfers of the accounts within the timestamps, a zero maximum
+// being none, in timestamp order per account.
+func exportTransfers(
+	client tigerbeetle_go.Client,
+	accountIDs []types.Uint128,
+	timestampMin uint64,
+	timestampMax uint64,
+	batch *batch,
+) (uint64, error) {
+	// A transfer between two of the accounts is paged from both.
+	seen := make(map[types.Uint128]struct{})
+	var rows uint64
+	for _, accountID := range accountIDs {
+		filter := types.AccountFilter{
+			AccountID:    accountID,
+			TimestampMin: timestampMin,
+			TimestampMax: timestampMax,
+			Limit:        uint32(types.MaxBatchSize(types.OperationGetAccountTransfers)),
+			Flags:        types.AccountFilterFlags{Debits: true, Credits: true}.ToUint32(),
+		}
+		for transfer, err := range reconcile.AccountTransfers(client, filter) {
+			if err != nil {
+				return rows, err
+			}
+			if _, ok := seen[transfer.ID]; ok {
+				continue
+			}
+			if slices.Contains(accountIDs, transfer.DebitAccountID) &&
+				slices.Contains(accountIDs, transfer.CreditAccountID) {
+				seen[transfer.ID] = struct{}{}
+			}
+			if err := batch.appendTransfer(transfer); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+	}
+	return rows, nil
+}
+
+// exportAccounts writes the accounts that exist, in the order of their IDs.
+func exportAccounts(client tigerbeetle_go.Client, accountIDs []types.Uint128, batch *batch) (uint64, error) {
+	var rows uint64
+	for chunk := range slices.Chunk(accountIDs, types.MaxBatchSize(types.OperationLookupAccounts)) {
+		accounts, err := client.LookupAccounts(chunk)
+		if err != nil {
+			return rows, err
+		}
+		for _, account := range accounts {
+			if err := batch.appendAccount(account); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,10 @@
+module github.com/tigerbeetle/tigerbeetle-go/cmd/tb-graphql
+
+go 1.23.0
+
+require (
+	github.com/graph-gophers/graphql-go v1.5.0
+	github.com/tigerbeetle/tigerbeetle-go v0.0.0
+)
+
+replace github.com/tigerbeetle/tigerbeetle-go => ../../
@@ -0,0 +1,87 @@
+// Command tb-graphql serves a read-only GraphQL API over the Go client, for back-office UIs:
+//
+//	POST /graphql          a query, as {"query": ..., "variables": ...}
+//	GET  /schema.graphql   the schema
+//
+// The accounts and transfers are looked up by ID, and the transfers and balances of an account
+// are Relay connections, paged forward with first and after. Their cursors are the timestamps of
+// the events, so a page continues where the previous one ended even as new transfers arrive.
+// The accounts of the transfers of a query are looked up together, as the first is resolved.
+//
+// 128-bit and 64-bit integers are decimal strings, the UInt128 and UInt64 scalars, since the
+// numbers of JSON and JavaScript don't hold them.
+//
+//	tb-graphql [-listen :8080] [-addresses 3000] [-cluster 0] [-concurrency 1024]
+package main
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// queryDepthMax bounds the nesting of a query, as every level of transfers and their accounts
+// costs requests to the cluster.
+const queryDepthMax = 8
+
+func main() {
+	listen := flag.String("listen", ":8080", "address to serve HTTP on")
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	concurrency := flag.Uint("concurrency", 1024, "requests in flight to the cluster, more wait")
+	flag.Parse()
+
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(*clusterID),
+		strings.Split(*addresses, ","),
+		*concurrency,
+		tigerbeetle_go.WithConcurrencyMode(tigerbeetle_go.ConcurrencyBlock),
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	parsed, err := graphql.ParseSchema(schema, &resolver{client: client},
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(queryDepthMax),
+	)
+	if err != nil {
+		log.Fatalf("Error parsing schema: %s", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /graphql", withLoader(client, &relay.Handler{Schema: parsed}))
+	mux.HandleFunc("GET /schema.graphql", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(schema))
+	})
+	httpServer := &http.Server{Addr: *listen, Handler: mux}
+
+	go func() {
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		<-interrupt
+		_ = httpServer.Shutdown(context.Background())
+	}()
+
+	log.Printf("Serving on %s", *listen)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Error serving: %s", err)
+	}
+}
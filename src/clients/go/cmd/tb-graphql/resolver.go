@@ -0,0 +1,399 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// resolver resolves the Query type.
+type resolver struct {
+	client tigerbeetle_go.Client
+}
+
+func (r *resolver) Account(ctx context.Context, args struct{ ID uint128Scalar }) (*accountResolver, error) {
+	account, ok, err := loaderOf(ctx, r.client).account(types.Uint128(args.ID))
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &accountResolver{account: account, client: r.client}, nil
+}
+
+func (r *resolver) Accounts(args struct{ IDs []uint128Scalar }) ([]*accountResolver, error) {
+	accounts, err := lookup(r.client.LookupAccounts, types.OperationLookupAccounts, args.IDs)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*accountResolver, len(accounts))
+	for i, account := range accounts {
+		resolvers[i] = &accountResolver{account: account, client: r.client}
+	}
+	return resolvers, nil
+}
+
+func (r *resolver) Transfer(ctx context.Context, args struct{ ID uint128Scalar }) (*transferResolver, error) {
+	transfers, err := r.client.LookupTransfers([]types.Uint128{types.Uint128(args.ID)})
+	if err != nil || len(transfers) == 0 {
+		return nil, err
+	}
+	loaderOf(ctx, r.client).want(transfers...)
+	return &transferResolver{transfer: transfers[0], client: r.client}, nil
+}
+
+func (r *resolver) Transfers(ctx context.Context, args struct{ IDs []uint128Scalar }) ([]*transferResolver, error) {
+	transfers, err := lookup(r.client.LookupTransfers, types.OperationLookupTransfers, args.IDs)
+	if err != nil {
+		return nil, err
+	}
+	loaderOf(ctx, r.client).want(transfers...)
+	resolvers := make([]*transferResolver, len(transfers))
+	for i, transfer := range transfers {
+		resolvers[i] = &transferResolver{transfer: transfer, client: r.client}
+	}
+	return resolvers, nil
+}
+
+// lookup returns the events of the IDs that exist, in the order of the IDs, looked up in as many
+// requests as it takes.
+func lookup[T any](request func([]types.Uint128) ([]T, error), op types.Operation, ids []uint128Scalar) ([]T, error) {
+	var found []T
+	for chunk := range slices.Chunk(ids, types.MaxBatchSize(op)) {
+		events, err := request(toUint128s(chunk))
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, events...)
+	}
+	return found, nil
+}
+
+func toUint128s(ids []uint128Scalar) []types.Uint128 {
+	converted := make([]types.Uint128, len(ids))
+	for i, id := range ids {
+		converted[i] = types.Uint128(id)
+	}
+	return converted
+}
+
+type accountResolver struct {
+	account types.Account
+	client  tigerbeetle_go.Client
+}
+
+func (r *accountResolver) ID() uint128Scalar { return uint128Scalar(r.account.ID) }
+func (r *accountResolver) DebitsPending() uint128Scalar {
+	return uint128Scalar(r.account.DebitsPending)
+}
+func (r *accountResolver) DebitsPosted() uint128Scalar { return uint128Scalar(r.account.DebitsPosted) }
+func (r *accountResolver) CreditsPending() uint128Scalar {
+	return uint128Scalar(r.account.CreditsPending)
+}
+func (r *accountResolver) CreditsPosted() uint128Scalar {
+	return uint128Scalar(r.account.CreditsPosted)
+}
+func (r *accountResolver) UserData128() uint128Scalar { return uint128Scalar(r.account.UserData128) }
+func (r *accountResolver) UserData64() uint64Scalar   { return uint64Scalar(r.account.UserData64) }
+func (r *accountResolver) UserData32() uint32Scalar   { return uint32Scalar(r.account.UserData32) }
+func (r *accountResolver) Ledger() uint32Scalar       { return uint32Scalar(r.account.Ledger) }
+func (r *accountResolver) Code() int32                { return int32(r.account.Code) }
+func (r *accountResolver) Flags() types.AccountFlags  { return r.account.AccountFlags() }
+func (r *accountResolver) Timestamp() uint64Scalar    { return uint64Scalar(r.account.Timestamp) }
+
+// pageArgs are the arguments of a connection.
+type pageArgs struct {
+	First        int32
+	After        *string
+	Reversed     bool
+	TimestampMin *uint64Scalar
+	TimestampMax *uint64Scalar
+}
+
+// filter returns the filter of the page after the cursor, of one more than the first events, so
+// that a full page tells whether there is a next one.
+func (args pageArgs) filter(accountID types.Uint128, flags types.AccountFilterFlags) (types.AccountFilter, error) {
+	if limitMax := types.MaxBatchSize(types.OperationGetAccountTransfers) - 1; args.First < 1 || int(args.First) > limitMax {
+		return types.AccountFilter{}, fmt.Errorf("first %d is not between 1 and %d", args.First, limitMax)
+	}
+	filter := types.AccountFilter{AccountID: accountID, Limit: uint32(args.First) + 1}
+	if args.TimestampMin != nil {
+		filter.TimestampMin = uint64(*args.TimestampMin)
+	}
+	if args.TimestampMax != nil {
+		filter.TimestampMax = uint64(*args.TimestampMax)
+	}
+	if args.After != nil {
+		timestamp, err := decodeCursor(*args.After)
+		if err != nil {
+			return types.AccountFilter{}, err
+		}
+		// Continue past the cursor, in the direction of the page.
+		if args.Reversed {
+			filter.TimestampMax = timestamp - 1
+		} else {
+			filter.TimestampMin = timestamp + 1
+		}
+	}
+	flags.Reversed = args.Reversed
+	filter.Flags = flags.ToUint32()
+	return filter, nil
+}
+
+type transfersArgs struct {
+	pageArgs
+	Debits  *bool
+	Credits *bool
+}
+
+func (r *accountResolver) Transfers(ctx context.Context, args transfersArgs) (*connection[*transferResolver], error) {
+	var flags types.AccountFilterFlags
+	if args.Debits != nil {
+		flags.Debits = *args.Debits
+	}
+	if args.Credits != nil {
+		flags.Credits = *args.Credits
+	}
+	if args.Debits == nil && args.Credits == nil {
+		flags.Debits, flags.Credits = true, true
+	}
+	filter, err := args.filter(r.account.ID, flags)
+	if err != nil {
+		return nil, err
+	}
+	transfers, err := r.client.GetAccountTransfers(filter)
+	if err != nil {
+		return nil, err
+	}
+	loaderOf(ctx, r.client).want(transfers...)
+	return newConnection(transfers, int(args.First),
+		func(transfer types.Transfer) uint64 { return transfer.Timestamp },
+		func(transfer types.Transfer) *transferResolver {
+			return &transferResolver{transfer: transfer, client: r.client}
+		},
+	), nil
+}
+
+func (r *accountResolver) Balances(args pageArgs) (*connection[*balanceResolver], error) {
+	filter, err := args.filter(r.account.ID, types.AccountFilterFlags{Debits: true, Credits: true})
+	if err != nil {
+		return nil, err
+	}
+	balances, err := r.client.GetAccountHistory(filter)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection(balances, int(args.First),
+		func(balance types.AccountBalance) uint64 { return balance.Timestamp },
+		func(balance types.AccountBalance) *balanceResolver { return &balanceResolver{balance: balance} },
+	), nil
+}
+
+type transferResolver struct {
+	transfer types.Transfer
+	client   tigerbeetle_go.Client
+}
+
+func (r *transferResolver) ID() uint128Scalar { return uint128Scalar(r.transfer.ID) }
+func (r *transferResolver) DebitAccountID() uint128Scalar {
+	return uint128Scalar(r.transfer.DebitAccountID)
+}
+func (r *transferResolver) CreditAccountID() uint128Scalar {
+	return uint128Scalar(r.transfer.CreditAccountID)
+}
+func (r *transferResolver) Amount() uint128Scalar      { return uint128Scalar(r.transfer.Amount) }
+func (r *transferResolver) PendingID() uint128Scalar   { return uint128Scalar(r.transfer.PendingID) }
+func (r *transferResolver) UserData128() uint128Scalar { return uint128Scalar(r.transfer.UserData128) }
+func (r *transferResolver) UserData64() uint64Scalar   { return uint64Scalar(r.transfer.UserData64) }
+func (r *transferResolver) UserData32() uint32Scalar   { return uint32Scalar(r.transfer.UserData32) }
+func (r *transferResolver) Timeout() uint32Scalar      { return uint32Scalar(r.transfer.Timeout) }
+func (r *transferResolver) Ledger() uint32Scalar       { return uint32Scalar(r.transfer.Ledger) }
+func (r *transferResolver) Code() int32                { return int32(r.transfer.Code) }
+func (r *transferResolver) Flags() types.TransferFlags { return r.transfer.TransferFlags() }
+func (r *transferResolver) Timestamp() uint64Scalar    { return uint64Scalar(r.transfer.Timestamp) }
+
+func (r *transferResolver) DebitAccount(ctx context.Context) (*accountResolver, error) {
+	return r.account(ctx, r.transfer.DebitAccountID)
+}
+
+func (r *transferResolver) CreditAccount(ctx context.Context) (*accountResolver, error) {
+	return r.account(ctx, r.transfer.CreditAccountID)
+}
+
+func (r *transferResolver) account(ctx context.Context, id types.Uint128) (*accountResolver, error) {
+	account, ok, err := loaderOf(ctx, r.client).account(id)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &accountResolver{account: account, client: r.client}, nil
+}
+
+func (r *transferResolver) PendingTransfer(ctx context.Context) (*transferResolver, error) {
+	if r.transfer.PendingID == types.ToUint128(0) {
+		return nil, nil
+	}
+	resolver := resolver{client: r.client}
+	return resolver.Transfer(ctx, struct{ ID uint128Scalar }{uint128Scalar(r.transfer.PendingID)})
+}
+
+type balanceResolver struct {
+	balance types.AccountBalance
+}
+
+func (r *balanceResolver) DebitsPending() uint128Scalar {
+	return uint128Scalar(r.balance.DebitsPending)
+}
+func (r *balanceResolver) DebitsPosted() uint128Scalar { return uint128Scalar(r.balance.DebitsPosted) }
+func (r *balanceResolver) CreditsPending() uint128Scalar {
+	return uint128Scalar(r.balance.CreditsPending)
+}
+func (r *balanceResolver) CreditsPosted() uint128Scalar {
+	return uint128Scalar(r.balance.CreditsPosted)
+}
+func (r *balanceResolver) Timestamp() uint64Scalar { return uint64Scalar(r.balance.Timestamp) }
+
+// connection is a Relay connection, of the nodes of one page of events.
+type connection[N any] struct {
+	edges       []*edge[N]
+	hasNextPage bool
+}
+
+type edge[N any] struct {
+	cursor string
+	node   N
+}
+
+// newConnection returns the connection of the first events, the one past them only telling
+// that there is a next page.
+func newConnection[T, N any](events []T, first int, timestamp func(T) uint64, node func(T) N) *connection[N] {
+	c := &connection[N]{edges: []*edge[N]{}}
+	if len(events) > first {
+		events, c.hasNextPage = events[:first], true
+	}
+	for _, event := range events {
+		c.edges = append(c.edges, &edge[N]{cursor: encodeCursor(timestamp(event)), node: node(event)})
+	}
+	return c
+}
+
+func (c *connection[N]) Edges() []*edge[N] { return c.edges }
+
+func (c *connection[N]) PageInfo() *pageInfo {
+	info := &pageInfo{hasNextPage: c.hasNextPage}
+	if len(c.edges) > 0 {
+		info.startCursor = &c.edges[0].cursor
+		info.endCursor = &c.edges[len(c.edges)-1].cursor
+	}
+	return info
+}
+
+func (e *edge[N]) Cursor() string { return e.cursor }
+func (e *edge[N]) Node() N        { return e.node }
+
+type pageInfo struct {
+	hasNextPage bool
+	startCursor *string
+	endCursor   *string
+}
+
+func (p *pageInfo) HasNextPage() bool     { return p.hasNextPage }
+func (p *pageInfo) HasPreviousPage() bool { return false }
+func (p *pageInfo) StartCursor() *string  { return p.startCursor }
+func (p *pageInfo) EndCursor() *string    { return p.endCursor }
+
+// Cursors are the timestamps of the events, which are unique, kept opaque as Relay asks.
+func encodeCursor(timestamp uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(timestamp, 10)))
+}
+
+func decodeCursor(cursor string) (uint64, error) {
+	text, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		var timestamp uint64
+		if timestamp, err = strconv.ParseUint(string(text), 10, 64); err == nil && timestamp != 0 {
+			return timestamp, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q", cursor)
+}
+
+// loader looks up the accounts that the transfers of a request refer to, all of them in one
+// lookup as the first of them is resolved, rather than one lookup per transfer.
+type loader struct {
+	client tigerbeetle_go.Client
+
+	mutex    sync.Mutex
+	wanted   []types.Uint128
+	accounts map[types.Uint128]*types.Account
+}
+
+type loaderKey struct{}
+
+// withLoader gives every request a loader of its own, so that accounts are never stale across
+// requests.
+func withLoader(client tigerbeetle_go.Client, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loader := &loader{client: client, accounts: make(map[types.Uint128]*types.Account)}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loaderKey{}, loader)))
+	})
+}
+
+func loaderOf(ctx context.Context, client tigerbeetle_go.Client) *loader {
+	if loader, ok := ctx.Value(loaderKey{}).(*loader); ok {
+		return loader
+	}
+	return &loader{client: client, accounts: make(map[types.Uint128]*types.Account)}
+}
+
+// want notes the accounts of the transfers, to be looked up with the next account.
+func (l *loader) want(transfers ...types.Transfer) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, transfer := range transfers {
+		l.wanted = append(l.wanted, transfer.DebitAccountID, transfer.CreditAccountID)
+	}
+}
+
+// account returns the account of id, and whether it exists.
+func (l *loader) account(id types.Uint128) (types.Account, bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if account, ok := l.accounts[id]; ok {
+		if account == nil {
+			return types.Account{}, false, nil
+		}
+		return *account, true, nil
+	}
+
+	ids := []types.Uint128{id}
+	queued := map[types.Uint128]bool{id: true}
+	for _, wanted := range l.wanted {
+		if _, ok := l.accounts[wanted]; !ok && !queued[wanted] {
+			queued[wanted] = true
+			ids = append(ids, wanted)
+		}
+	}
+	l.wanted = nil
+	for chunk := range slices.Chunk(ids, types.MaxBatchSize(types.OperationLookupAccounts)) {
+		accounts, err := l.client.LookupAccounts(chunk)
+		if err != nil {
+			return types.Account{}, false, err
+		}
+		for _, id := range chunk {
+			l.accounts[id] = nil
+		}
+		for _, account := range accounts {
+			l.accounts[account.ID] = &account
+		}
+	}
+	account := l.accounts[id]
+	if account == nil {
+		return types.Account{}, false, nil
+	}
+	return *account, true, nil
+}
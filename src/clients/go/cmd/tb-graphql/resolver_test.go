@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbtest"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// testServer serves queries over a client of an in-memory ledger, counting the lookups of
+// accounts.
+type testServer struct {
+	handler        http.Handler
+	accountLookups atomic.Int32
+}
+
+func newTestServer(t *testing.T) *testServer {
+	s := &testServer{}
+	ledger := tbtest.NewLedger(tbtest.NewClock(time.Time{}))
+	transport := tigerbeetle_go.NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if op == types.OperationLookupAccounts {
+			s.accountLookups.Add(1)
+		}
+		reply := make([]byte, types.MessageSizeMax)
+		size, err := ledger.Submit(op, events, reply)
+		return reply[:size], err
+	})
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 4, tigerbeetle_go.WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+
+	// Transfers 1 to 5 move between accounts 1 and 2, of which 2 keeps its history.
+	_, err = client.CreateAccounts([]types.Account{
+		{ID: types.ToUint128(1), Ledger: 1, Code: 1},
+		{ID: types.ToUint128(2), Ledger: 1, Code: 1, Flags: types.AccountFlags{History: true}.ToUint16()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 5 {
+		transfer := types.Transfer{
+			ID:              types.ToUint128(uint64(i + 1)),
+			DebitAccountID:  types.ToUint128(1),
+			CreditAccountID: types.ToUint128(2),
+			Amount:          types.ToUint128(uint64(i + 1)),
+			Ledger:          1,
+			Code:            1,
+		}
+		if err := client.CreateTransfer(transfer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parsed, err := graphql.ParseSchema(schema, &resolver{client: client},
+		graphql.UseFieldResolvers(),
+		graphql.MaxDepth(queryDepthMax),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.handler = withLoader(client, &relay.Handler{Schema: parsed})
+	return s
+}
+
+// query runs query with variables, decoding its data into data, and returns the messages of its
+// errors.
+func (s *testServer) query(t *testing.T, query string, variables map[string]any, data any) []string {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body))))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	messages := make([]string, len(response.Errors))
+	for i, e := range response.Errors {
+		messages[i] = e.Message
+	}
+	if len(messages) == 0 {
+		if err := json.Unmarshal(response.Data, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return messages
+}
+
+type transfersData struct {
+	Account struct {
+		Transfers struct {
+			Edges []struct {
+				Cursor string
+				Node   struct {
+					ID            string
+					Amount        string
+					DebitAccount  struct{ ID string }
+					CreditAccount struct{ CreditsPosted string }
+				}
+			}
+			PageInfo struct {
+				HasNextPage bool
+				EndCursor   *string
+			}
+		}
+	}
+}
+
+const transfersQuery = `query($first: Int = 20, $after: String, $reversed: Boolean = false) {
+	account(id: "1") {
+		transfers(first: $first, after: $after, reversed: $reversed) {
+			edges { cursor node { id amount debitAccount { id } creditAccount { creditsPosted } } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}
+}`
+
+// ids returns the IDs of the transfers of a page.
+func (d transfersData) ids() []string {
+	var ids []string
+	for _, edge := range d.Account.Transfers.Edges {
+		ids = append(ids, edge.Node.ID)
+	}
+	return ids
+}
+
+func TestLookup(t *testing.T) {
+	s := newTestServer(t)
+
+	var data struct {
+		Account  *struct{ ID, DebitsPosted string }
+		Missing  *struct{ ID string }
+		Accounts []struct{ ID string }
+		Transfer struct {
+			Amount string
+			Flags  struct{ Pending bool }
+		}
+		Transfers []struct{ ID string }
+	}
+	errors := s.query(t, `{
+		account(id: "1") { id debitsPosted }
+		missing: account(id: 3) { id }
+		accounts(ids: ["2", "3", "1"]) { id }
+		transfer(id: "4") { amount flags { pending } }
+		transfers(ids: ["5", "6", "2"]) { id }
+	}`, nil, &data)
+	assert.Empty(t, errors)
+	assert.Equal(t, "1", data.Account.ID)
+	assert.Equal(t, "15", data.Account.DebitsPosted)
+	assert.True(t, data.Missing == nil)
+	assert.Equal(t, []struct{ ID string }{{"2"}, {"1"}}, data.Accounts)
+	assert.Equal(t, "4", data.Transfer.Amount)
+	assert.Equal(t, false, data.Transfer.Flags.Pending)
+	assert.Equal(t, []struct{ ID string }{{"5"}, {"2"}}, data.Transfers)
+
+	errors = s.query(t, `{ account(id: "-1") { id } }`, nil, &data)
+	assert.Len(t, errors, 1)
+}
+
+func TestTransfersPages(t *testing.T) {
+	s := newTestServer(t)
+
+	// The cursor of a page continues where it ended, and the accounts of all its transfers are
+	// looked up together.
+	var page transfersData
+	lookups := s.accountLookups.Load()
+	errors := s.query(t, transfersQuery, map[string]any{"first": 2}, &page)
+	assert.Empty(t, errors)
+	assert.Equal(t, []string{"1", "2"}, page.ids())
+	assert.Equal(t, "1", page.Account.Transfers.Edges[0].Node.DebitAccount.ID)
+	assert.Equal(t, "15", page.Account.Transfers.Edges[1].Node.CreditAccount.CreditsPosted)
+	assert.Equal(t, lookups+2, s.accountLookups.Load())
+	assert.True(t, page.Account.Transfers.PageInfo.HasNextPage)
+	assert.Equal(t, page.Account.Transfers.Edges[1].Cursor, *page.Account.Transfers.PageInfo.EndCursor)
+
+	var pages [][]string
+	for {
+		pages = append(pages, page.ids())
+		if !page.Account.Transfers.PageInfo.HasNextPage {
+			break
+		}
+		after := *page.Account.Transfers.PageInfo.EndCursor
+		page = transfersData{}
+		errors := s.query(t, transfersQuery, map[string]any{"first": 2, "after": after}, &page)
+		assert.Empty(t, errors)
+	}
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, pages)
+
+	// A reversed page continues backwards from its cursor.
+	page = transfersData{}
+	s.query(t, transfersQuery, map[string]any{"first": 3, "reversed": true}, &page)
+	assert.Equal(t, []string{"5", "4", "3"}, page.ids())
+	after := *page.Account.Transfers.PageInfo.EndCursor
+	page = transfersData{}
+	s.query(t, transfersQuery, map[string]any{"first": 3, "reversed": true, "after": after}, &page)
+	assert.Equal(t, []string{"2", "1"}, page.ids())
+	assert.Equal(t, false, page.Account.Transfers.PageInfo.HasNextPage)
+
+	// An empty page has no cursors.
+	page = transfersData{}
+	s.query(t, transfersQuery, map[string]any{"first": 3, "after": encodeCursor(math.MaxUint64 - 1)}, &page)
+	assert.Empty(t, page.Account.Transfers.Edges)
+	assert.True(t, page.Account.Transfers.PageInfo.EndCursor == nil)
+}
+
+func TestBalancesPages(t *testing.T) {
+	s := newTestServer(t)
+
+	var data struct {
+		Account struct {
+			Balances struct {
+				Edges []struct {
+					Cursor string
+					Node   struct{ CreditsPosted string }
+				}
+				PageInfo struct{ HasNextPage bool }
+			}
+		}
+	}
+	query := `query($after: String) {
+		account(id: "2") {
+			balances(first: 3, after: $after) {
+				edges { cursor node { creditsPosted } }
+				pageInfo { hasNextPage }
+			}
+		}
+	}`
+	errors := s.query(t, query, nil, &data)
+	assert.Empty(t, errors)
+	assert.Len(t, data.Account.Balances.Edges, 3)
+	assert.Equal(t, "6", data.Account.Balances.Edges[2].Node.CreditsPosted)
+	assert.True(t, data.Account.Balances.PageInfo.HasNextPage)
+
+	after := data.Account.Balances.Edges[2].Cursor
+	errors = s.query(t, query, map[string]any{"after": after}, &data)
+	assert.Empty(t, errors)
+	assert.Len(t, data.Account.Balances.Edges, 2)
+	assert.Equal(t, "15", data.Account.Balances.Edges[1].Node.CreditsPosted)
+	assert.Equal(t, false, data.Account.Balances.PageInfo.HasNextPage)
+}
+
+func TestTransfersInvalid(t *testing.T) {
+	s := newTestServer(t)
+
+	encode := func(text string) string { return base64.RawURLEncoding.EncodeToString([]byte(text)) }
+	for _, variables := range []map[string]any{
+		{"first": 0},
+		{"first": types.MaxBatchSize(types.OperationGetAccountTransfers)},
+		{"first": 2, "after": "not a cursor!"},
+		{"first": 2, "after": encode("0")},
+		{"first": 2, "after": encode("-1")},
+		{"first": 2, "after": encode("18446744073709551616")},
+		{"first": 2, "after": base64.StdEncoding.EncodeToString([]byte("12"))},
+	} {
+		var page transfersData
+		errors := s.query(t, transfersQuery, variables, &page)
+		assert.Len(t, errors, 1)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	for _, timestamp := range []uint64{1, 1_700_000_000_000_000_000, math.MaxUint64} {
+		cursor := encodeCursor(timestamp)
+		assert.True(t, !strings.ContainsAny(cursor, "+/="))
+		decoded, err := decodeCursor(cursor)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, timestamp, decoded)
+	}
+
+	for _, cursor := range []string{"", "!", "MTI=", encodeCursor(0) + "A"} {
+		_, err := decodeCursor(cursor)
+		assert.Equal(t, `invalid cursor "`+cursor+`"`, err.Error())
+	}
+}
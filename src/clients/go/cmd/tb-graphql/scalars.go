@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// uint128Scalar is the UInt128 scalar, a decimal string.
+type uint128Scalar types.Uint128
+
+func (uint128Scalar) ImplementsGraphQLType(name string) bool { return name == "UInt128" }
+
+// UnmarshalGraphQL reads a decimal string, or an Int literal.
+func (value *uint128Scalar) UnmarshalGraphQL(input any) error {
+	switch input := input.(type) {
+	case string:
+		parsed, err := types.DecStringToUint128(input)
+		if err != nil {
+			return fmt.Errorf("UInt128 %q: %w", input, err)
+		}
+		*value = uint128Scalar(parsed)
+		return nil
+	case int32:
+		if input < 0 {
+			return fmt.Errorf("UInt128 %d is negative", input)
+		}
+		*value = uint128Scalar(types.ToUint128(uint64(input)))
+		return nil
+	}
+	return fmt.Errorf("UInt128 can't be %T", input)
+}
+
+func (value uint128Scalar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(types.Uint128(value).String())
+}
+
+// uint64Scalar is the UInt64 scalar, a decimal string.
+type uint64Scalar uint64
+
+func (uint64Scalar) ImplementsGraphQLType(name string) bool { return name == "UInt64" }
+
+// UnmarshalGraphQL reads a decimal string, or an Int literal.
+func (value *uint64Scalar) UnmarshalGraphQL(input any) error {
+	switch input := input.(type) {
+	case string:
+		parsed, err := strconv.ParseUint(input, 10, 64)
+		if err != nil {
+			return fmt.Errorf("UInt64 %q: %w", input, err)
+		}
+		*value = uint64Scalar(parsed)
+		return nil
+	case int32:
+		if input < 0 {
+			return fmt.Errorf("UInt64 %d is negative", input)
+		}
+		*value = uint64Scalar(input)
+		return nil
+	}
+	return fmt.Errorf("UInt64 can't be %T", input)
+}
+
+func (value uint64Scalar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(value), 10))
+}
+
+// uint32Scalar is the UInt32 scalar, a number.
+type uint32Scalar uint32
+
+func (uint32Scalar) ImplementsGraphQLType(name string) bool { return name == "UInt32" }
+
+// UnmarshalGraphQL reads a number, which variables decode to float64 and literals to int32.
+func (value *uint32Scalar) UnmarshalGraphQL(input any) error {
+	switch input := input.(type) {
+	case int32:
+		if input >= 0 {
+			*value = uint32Scalar(input)
+			return nil
+		}
+	case float64:
+		if input >= 0 && input <= math.MaxUint32 && input == math.Trunc(input) {
+			*value = uint32Scalar(input)
+			return nil
+		}
+	default:
+		return fmt.Errorf("UInt32 can't be %T", input)
+	}
+	return fmt.Errorf("UInt32 %v is out of range", input)
+}
+
+func (value uint32Scalar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(uint32(value))
+}
@@ -0,0 +1,22 @@
+package main
+
+// mapSlice converts every element of values with convert.
+func mapSlice[T, U any](values []T, convert func(T) U) []U {
+	converted := make([]U, len(values))
+	for i, value := range values {
+		converted[i] = convert(value)
+	}
+	return converted
+}
+
+// tryMapSlice converts every element of values with convert, stopping at the first error.
+func tryMapSlice[T, U any](values []T, convert func(T) (U, error)) ([]U, error) {
+	converted := make([]U, len(values))
+	for i, value := range values {
+		var err error
+		if converted[i], err = convert(value); err != nil {
+			return nil, err
+		}
+	}
+	return converted, nil
+}
@@ -0,0 +1,62 @@
+// Command tb-grpc-gateway serves the TigerBeetle operations over gRPC, for languages without
+// a native client, by forwarding every call through one Go client. The service is defined in
+// proto/tigerbeetle.proto, on the messages of the tbproto package.
+//
+//	tb-grpc-gateway [-listen :50051] [-addresses 3000] [-cluster 0] [-concurrency 1024]
+package main
+
+//go:generate protoc -I proto -I ../../pkg/tbproto/proto --go_out=tigerbeetlepb --go_opt=paths=source_relative --go-grpc_out=tigerbeetlepb --go-grpc_opt=paths=source_relative tigerbeetle.proto
+
+import (
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/cmd/tb-grpc-gateway/tigerbeetlepb"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func main() {
+	listen := flag.String("listen", ":50051", "address to serve gRPC on")
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	concurrency := flag.Uint("concurrency", 1024, "requests in flight to the cluster, more wait")
+	flag.Parse()
+
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(*clusterID),
+		strings.Split(*addresses, ","),
+		*concurrency,
+		tigerbeetle_go.WithConcurrencyMode(tigerbeetle_go.ConcurrencyBlock),
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalf("Error listening: %s", err)
+	}
+
+	grpcServer := grpc.NewServer()
+	tigerbeetlepb.RegisterTigerBeetleServer(grpcServer, &server{client: client})
+
+	go func() {
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		<-interrupt
+		grpcServer.GracefulStop()
+	}()
+
+	log.Printf("Serving on %s", listener.Addr())
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Fatalf("Error serving: %s", err)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	e "errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/cmd/tb-grpc-gateway/tigerbeetlepb"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbproto"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// server serves the TigerBeetle service by forwarding every call to one client, so that the
+// calls of all gRPC clients share its session and batching.
+type server struct {
+	tigerbeetlepb.UnimplementedTigerBeetleServer
+	client tigerbeetle_go.Client
+}
+
+func (s *server) CreateAccounts(
+	ctx context.Context,
+	request *tigerbeetlepb.CreateAccountsRequest,
+) (*tigerbeetlepb.CreateAccountsResponse, error) {
+	if len(request.GetAccounts()) == 0 {
+		return nil, grpcError(errors.ErrEmptyBatch{})
+	}
+
+	accounts, err := tryMapSlice(request.GetAccounts(), tbproto.ToAccount)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	results, err := s.client.CreateAccounts(accounts)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &tigerbeetlepb.CreateAccountsResponse{
+		Results: mapSlice(results, func(result types.AccountEventResult) *tigerbeetlepb.EventResult {
+			return &tigerbeetlepb.EventResult{
+				Index:      result.Index,
+				Result:     uint32(result.Result),
+				ResultName: result.Result.String(),
+			}
+		}),
+	}, nil
+}
+
+func (s *server) CreateTransfers(
+	ctx context.Context,
+	request *tigerbeetlepb.CreateTransfersRequest,
+) (*tigerbeetlepb.CreateTransfersResponse, error) {
+	if len(request.GetTransfers()) == 0 {
+		return nil, grpcError(errors.ErrEmptyBatch{})
+	}
+
+	transfers, err := tryMapSlice(request.GetTransfers(), tbproto.ToTransfer)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	results, err := s.client.CreateTransfers(transfers)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &tigerbeetlepb.CreateTransfersResponse{
+		Results: mapSlice(results, func(result types.TransferEventResult) *tigerbeetlepb.EventResult {
+			return &tigerbeetlepb.EventResult{
+				Index:      result.Index,
+				Result:     uint32(result.Result),
+				ResultName: result.Result.String(),
+			}
+		}),
+	}, nil
+}
+
+func (s *server) LookupAccounts(
+	ctx context.Context,
+	request *tigerbeetlepb.LookupRequest,
+) (*tigerbeetlepb.LookupAccountsResponse, error) {
+	if len(request.GetIds()) == 0 {
+		return nil, grpcError(errors.ErrEmptyBatch{})
+	}
+
+	accounts, err := s.client.LookupAccounts(mapSlice(request.GetIds(), tbproto.ToUint128))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &tigerbeetlepb.LookupAccountsResponse{Accounts: mapSlice(accounts, tbproto.FromAccount)}, nil
+}
+
+func (s *server) LookupTransfers(
+	ctx context.Context,
+	request *tigerbeetlepb.LookupRequest,
+) (*tigerbeetlepb.LookupTransfersResponse, error) {
+	if len(request.GetIds()) == 0 {
+		return nil, grpcError(errors.ErrEmptyBatch{})
+	}
+
+	transfers, err := s.client.LookupTransfers(mapSlice(request.GetIds(), tbproto.ToUint128))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &tigerbeetlepb.LookupTransfersResponse{Transfers: mapSlice(transfers, tbproto.FromTransfer)}, nil
+}
+
+func (s *server) GetAccountTransfers(
+	ctx context.Context,
+	filter *tbproto.AccountFilter,
+) (*tigerbeetlepb.GetAccountTransfersResponse, error) {
+	transfers, err := s.client.GetAccountTransfers(tbproto.ToAccountFilter(filter))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &tigerbeetlepb.GetAccountTransfersResponse{Transfers: mapSlice(transfers, tbproto.FromTransfer)}, nil
+}
+
+func (s *server) GetAccountHistory(
+	ctx context.Context,
+	filter *tbproto.AccountFilter,
+) (*tigerbeetlepb.GetAccountHistoryResponse, error) {
+	balances, err := s.client.GetAccountHistory(tbproto.ToAccountFilter(filter))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &tigerbeetlepb.GetAccountHistoryResponse{Balances: mapSlice(balances, tbproto.FromAccountBalance)}, nil
+}
+
+// grpcError maps a client error to the gRPC status that tells the caller whether to fix the
+// request, back off, or retry elsewhere.
+func grpcError(err error) error {
+	code := codes.Internal
+	switch {
+	case e.Is(err, errors.ErrEmptyBatch{}),
+		e.Is(err, errors.ErrMaximumBatchSizeExceeded{}),
+		e.As(err, new(errors.ErrInvalidAccount)),
+		e.As(err, new(errors.ErrInvalidTransfer)),
+		e.As(err, new(tbproto.ErrOverflow)):
+		code = codes.InvalidArgument
+	case e.Is(err, errors.ErrConcurrencyExceeded{}):
+		code = codes.ResourceExhausted
+	case e.Is(err, errors.ErrClientClosed{}), e.Is(err, errors.ErrMaintenance{}):
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
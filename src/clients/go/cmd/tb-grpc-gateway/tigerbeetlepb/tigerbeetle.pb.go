@@ -0,0 +1,605 @@
+// The TigerBeetle operations, served by tb-grpc-gateway for languages without a native client.
+//
+// The accounts, transfers and filters are the messages of tigerbeetle/v1/types.proto, shared
+// with the other services. Result codes are the numeric values of the CreateAccountResult and
+// CreateTransferResult enums, with their names for readability.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: tigerbeetle.proto
+
+package tigerbeetlepb
+
+import (
+	tbproto "github.com/tigerbeetle/tigerbeetle-go/pkg/tbproto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// EventResult reports an event that failed. Events without a result were created.
+type EventResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Result        uint32                 `protobuf:"varint,2,opt,name=result,proto3" json:"result,omitempty"`
+	ResultName    string                 `protobuf:"bytes,3,opt,name=result_name,json=resultName,proto3" json:"result_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventResult) Reset() {
+	*x = EventResult{}
+	mi := &file_tigerbeetle_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventResult) ProtoMessage() {}
+
+func (x *EventResult) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventResult.ProtoReflect.Descriptor instead.
+func (*EventResult) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EventResult) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *EventResult) GetResult() uint32 {
+	if x != nil {
+		return x.Result
+	}
+	return 0
+}
+
+func (x *EventResult) GetResultName() string {
+	if x != nil {
+		return x.ResultName
+	}
+	return ""
+}
+
+type CreateAccountsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accounts      []*tbproto.Account     `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccountsRequest) Reset() {
+	*x = CreateAccountsRequest{}
+	mi := &file_tigerbeetle_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountsRequest) ProtoMessage() {}
+
+func (x *CreateAccountsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountsRequest.ProtoReflect.Descriptor instead.
+func (*CreateAccountsRequest) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateAccountsRequest) GetAccounts() []*tbproto.Account {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+type CreateAccountsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*EventResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccountsResponse) Reset() {
+	*x = CreateAccountsResponse{}
+	mi := &file_tigerbeetle_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccountsResponse) ProtoMessage() {}
+
+func (x *CreateAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccountsResponse.ProtoReflect.Descriptor instead.
+func (*CreateAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateAccountsResponse) GetResults() []*EventResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type CreateTransfersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transfers     []*tbproto.Transfer    `protobuf:"bytes,1,rep,name=transfers,proto3" json:"transfers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransfersRequest) Reset() {
+	*x = CreateTransfersRequest{}
+	mi := &file_tigerbeetle_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransfersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransfersRequest) ProtoMessage() {}
+
+func (x *CreateTransfersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransfersRequest.ProtoReflect.Descriptor instead.
+func (*CreateTransfersRequest) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateTransfersRequest) GetTransfers() []*tbproto.Transfer {
+	if x != nil {
+		return x.Transfers
+	}
+	return nil
+}
+
+type CreateTransfersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*EventResult         `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTransfersResponse) Reset() {
+	*x = CreateTransfersResponse{}
+	mi := &file_tigerbeetle_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTransfersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTransfersResponse) ProtoMessage() {}
+
+func (x *CreateTransfersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTransfersResponse.ProtoReflect.Descriptor instead.
+func (*CreateTransfersResponse) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateTransfersResponse) GetResults() []*EventResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type LookupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []*tbproto.Uint128     `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupRequest) Reset() {
+	*x = LookupRequest{}
+	mi := &file_tigerbeetle_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupRequest) ProtoMessage() {}
+
+func (x *LookupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupRequest.ProtoReflect.Descriptor instead.
+func (*LookupRequest) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LookupRequest) GetIds() []*tbproto.Uint128 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type LookupAccountsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accounts      []*tbproto.Account     `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupAccountsResponse) Reset() {
+	*x = LookupAccountsResponse{}
+	mi := &file_tigerbeetle_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupAccountsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupAccountsResponse) ProtoMessage() {}
+
+func (x *LookupAccountsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupAccountsResponse.ProtoReflect.Descriptor instead.
+func (*LookupAccountsResponse) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LookupAccountsResponse) GetAccounts() []*tbproto.Account {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+type LookupTransfersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transfers     []*tbproto.Transfer    `protobuf:"bytes,1,rep,name=transfers,proto3" json:"transfers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupTransfersResponse) Reset() {
+	*x = LookupTransfersResponse{}
+	mi := &file_tigerbeetle_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupTransfersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupTransfersResponse) ProtoMessage() {}
+
+func (x *LookupTransfersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupTransfersResponse.ProtoReflect.Descriptor instead.
+func (*LookupTransfersResponse) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LookupTransfersResponse) GetTransfers() []*tbproto.Transfer {
+	if x != nil {
+		return x.Transfers
+	}
+	return nil
+}
+
+type GetAccountTransfersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transfers     []*tbproto.Transfer    `protobuf:"bytes,1,rep,name=transfers,proto3" json:"transfers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountTransfersResponse) Reset() {
+	*x = GetAccountTransfersResponse{}
+	mi := &file_tigerbeetle_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountTransfersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountTransfersResponse) ProtoMessage() {}
+
+func (x *GetAccountTransfersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountTransfersResponse.ProtoReflect.Descriptor instead.
+func (*GetAccountTransfersResponse) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetAccountTransfersResponse) GetTransfers() []*tbproto.Transfer {
+	if x != nil {
+		return x.Transfers
+	}
+	return nil
+}
+
+type GetAccountHistoryResponse struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Balances      []*tbproto.AccountBalance `protobuf:"bytes,1,rep,name=balances,proto3" json:"balances,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAccountHistoryResponse) Reset() {
+	*x = GetAccountHistoryResponse{}
+	mi := &file_tigerbeetle_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAccountHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAccountHistoryResponse) ProtoMessage() {}
+
+func (x *GetAccountHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAccountHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetAccountHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetAccountHistoryResponse) GetBalances() []*tbproto.AccountBalance {
+	if x != nil {
+		return x.Balances
+	}
+	return nil
+}
+
+var File_tigerbeetle_proto protoreflect.FileDescriptor
+
+const file_tigerbeetle_proto_rawDesc = "" +
+	"\n" +
+	"\x11tigerbeetle.proto\x12\x0etigerbeetle.v1\x1a\x1atigerbeetle/v1/types.proto\"\\\n" +
+	"\vEventResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x16\n" +
+	"\x06result\x18\x02 \x01(\rR\x06result\x12\x1f\n" +
+	"\vresult_name\x18\x03 \x01(\tR\n" +
+	"resultName\"L\n" +
+	"\x15CreateAccountsRequest\x123\n" +
+	"\baccounts\x18\x01 \x03(\v2\x17.tigerbeetle.v1.AccountR\baccounts\"O\n" +
+	"\x16CreateAccountsResponse\x125\n" +
+	"\aresults\x18\x01 \x03(\v2\x1b.tigerbeetle.v1.EventResultR\aresults\"P\n" +
+	"\x16CreateTransfersRequest\x126\n" +
+	"\ttransfers\x18\x01 \x03(\v2\x18.tigerbeetle.v1.TransferR\ttransfers\"P\n" +
+	"\x17CreateTransfersResponse\x125\n" +
+	"\aresults\x18\x01 \x03(\v2\x1b.tigerbeetle.v1.EventResultR\aresults\":\n" +
+	"\rLookupRequest\x12)\n" +
+	"\x03ids\x18\x01 \x03(\v2\x17.tigerbeetle.v1.Uint128R\x03ids\"M\n" +
+	"\x16LookupAccountsResponse\x123\n" +
+	"\baccounts\x18\x01 \x03(\v2\x17.tigerbeetle.v1.AccountR\baccounts\"Q\n" +
+	"\x17LookupTransfersResponse\x126\n" +
+	"\ttransfers\x18\x01 \x03(\v2\x18.tigerbeetle.v1.TransferR\ttransfers\"U\n" +
+	"\x1bGetAccountTransfersResponse\x126\n" +
+	"\ttransfers\x18\x01 \x03(\v2\x18.tigerbeetle.v1.TransferR\ttransfers\"W\n" +
+	"\x19GetAccountHistoryResponse\x12:\n" +
+	"\bbalances\x18\x01 \x03(\v2\x1e.tigerbeetle.v1.AccountBalanceR\bbalances2\xc8\x04\n" +
+	"\vTigerBeetle\x12_\n" +
+	"\x0eCreateAccounts\x12%.tigerbeetle.v1.CreateAccountsRequest\x1a&.tigerbeetle.v1.CreateAccountsResponse\x12b\n" +
+	"\x0fCreateTransfers\x12&.tigerbeetle.v1.CreateTransfersRequest\x1a'.tigerbeetle.v1.CreateTransfersResponse\x12W\n" +
+	"\x0eLookupAccounts\x12\x1d.tigerbeetle.v1.LookupRequest\x1a&.tigerbeetle.v1.LookupAccountsResponse\x12Y\n" +
+	"\x0fLookupTransfers\x12\x1d.tigerbeetle.v1.LookupRequest\x1a'.tigerbeetle.v1.LookupTransfersResponse\x12a\n" +
+	"\x13GetAccountTransfers\x12\x1d.tigerbeetle.v1.AccountFilter\x1a+.tigerbeetle.v1.GetAccountTransfersResponse\x12]\n" +
+	"\x11GetAccountHistory\x12\x1d.tigerbeetle.v1.AccountFilter\x1a).tigerbeetle.v1.GetAccountHistoryResponseBIZGgithub.com/tigerbeetle/tigerbeetle-go/cmd/tb-grpc-gateway/tigerbeetlepbb\x06proto3"
+
+var (
+	file_tigerbeetle_proto_rawDescOnce sync.Once
+	file_tigerbeetle_proto_rawDescData []byte
+)
+
+func file_tigerbeetle_proto_rawDescGZIP() []byte {
+	file_tigerbeetle_proto_rawDescOnce.Do(func() {
+		file_tigerbeetle_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tigerbeetle_proto_rawDesc), len(file_tigerbeetle_proto_rawDesc)))
+	})
+	return file_tigerbeetle_proto_rawDescData
+}
+
+var file_tigerbeetle_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_tigerbeetle_proto_goTypes = []any{
+	(*EventResult)(nil),                 // 0: tigerbeetle.v1.EventResult
+	(*CreateAccountsRequest)(nil),       // 1: tigerbeetle.v1.CreateAccountsRequest
+	(*CreateAccountsResponse)(nil),      // 2: tigerbeetle.v1.CreateAccountsResponse
+	(*CreateTransfersRequest)(nil),      // 3: tigerbeetle.v1.CreateTransfersRequest
+	(*CreateTransfersResponse)(nil),     // 4: tigerbeetle.v1.CreateTransfersResponse
+	(*LookupRequest)(nil),               // 5: tigerbeetle.v1.LookupRequest
+	(*LookupAccountsResponse)(nil),      // 6: tigerbeetle.v1.LookupAccountsResponse
+	(*LookupTransfersResponse)(nil),     // 7: tigerbeetle.v1.LookupTransfersResponse
+	(*GetAccountTransfersResponse)(nil), // 8: tigerbeetle.v1.GetAccountTransfersResponse
+	(*GetAccountHistoryResponse)(nil),   // 9: tigerbeetle.v1.GetAccountHistoryResponse
+	(*tbproto.Account)(nil),             // 10: tigerbeetle.v1.Account
+	(*tbproto.Transfer)(nil),            // 11: tigerbeetle.v1.Transfer
+	(*tbproto.Uint128)(nil),             // 12: tigerbeetle.v1.Uint128
+	(*tbproto.AccountBalance)(nil),      // 13: tigerbeetle.v1.AccountBalance
+	(*tbproto.AccountFilter)(nil),       // 14: tigerbeetle.v1.AccountFilter
+}
+var file_tigerbeetle_proto_depIdxs = []int32{
+	10, // 0: tigerbeetle.v1.CreateAccountsRequest.accounts:type_name -> tigerbeetle.v1.Account
+	0,  // 1: tigerbeetle.v1.CreateAccountsResponse.results:type_name -> tigerbeetle.v1.EventResult
+	11, // 2: tigerbeetle.v1.CreateTransfersRequest.transfers:type_name -> tigerbeetle.v1.Transfer
+	0,  // 3: tigerbeetle.v1.CreateTransfersResponse.results:type_name -> tigerbeetle.v1.EventResult
+	12, // 4: tigerbeetle.v1.LookupRequest.ids:type_name -> tigerbeetle.v1.Uint128
+	10, // 5: tigerbeetle.v1.LookupAccountsResponse.accounts:type_name -> tigerbeetle.v1.Account
+	11, // 6: tigerbeetle.v1.LookupTransfersResponse.transfers:type_name -> tigerbeetle.v1.Transfer
+	11, // 7: tigerbeetle.v1.GetAccountTransfersResponse.transfers:type_name -> tigerbeetle.v1.Transfer
+	13, // 8: tigerbeetle.v1.GetAccountHistoryResponse.balances:type_name -> tigerbeetle.v1.AccountBalance
+	1,  // 9: tigerbeetle.v1.TigerBeetle.CreateAccounts:input_type -> tigerbeetle.v1.CreateAccountsRequest
+	3,  // 10: tigerbeetle.v1.TigerBeetle.CreateTransfers:input_type -> tigerbeetle.v1.CreateTransfersRequest
+	5,  // 11: tigerbeetle.v1.TigerBeetle.LookupAccounts:input_type -> tigerbeetle.v1.LookupRequest
+	5,  // 12: tigerbeetle.v1.TigerBeetle.LookupTransfers:input_type -> tigerbeetle.v1.LookupRequest
+	14, // 13: tigerbeetle.v1.TigerBeetle.GetAccountTransfers:input_type -> tigerbeetle.v1.AccountFilter
+	14, // 14: tigerbeetle.v1.TigerBeetle.GetAccountHistory:input_type -> tigerbeetle.v1.AccountFilter
+	2,  // 15: tigerbeetle.v1.TigerBeetle.CreateAccounts:output_type -> tigerbeetle.v1.CreateAccountsResponse
+	4,  // 16: tigerbeetle.v1.TigerBeetle.CreateTransfers:output_type -> tigerbeetle.v1.CreateTransfersResponse
+	6,  // 17: tigerbeetle.v1.TigerBeetle.LookupAccounts:output_type -> tigerbeetle.v1.LookupAccountsResponse
+	7,  // 18: tigerbeetle.v1.TigerBeetle.LookupTransfers:output_type -> tigerbeetle.v1.LookupTransfersResponse
+	8,  // 19: tigerbeetle.v1.TigerBeetle.GetAccountTransfers:output_type -> tigerbeetle.v1.GetAccountTransfersResponse
+	9,  // 20: tigerbeetle.v1.TigerBeetle.GetAccountHistory:output_type -> tigerbeetle.v1.GetAccountHistoryResponse
+	15, // [15:21] is the sub-list for method output_type
+	9,  // [9:15] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_tigerbeetle_proto_init() }
+func file_tigerbeetle_proto_init() {
+	if File_tigerbeetle_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tigerbeetle_proto_rawDesc), len(file_tigerbeetle_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tigerbeetle_proto_goTypes,
+		DependencyIndexes: file_tigerbeetle_proto_depIdxs,
+		MessageInfos:      file_tigerbeetle_proto_msgTypes,
+	}.Build()
+	File_tigerbeetle_proto = out.File
+	file_tigerbeetle_proto_goTypes = nil
+	file_tigerbeetle_proto_depIdxs = nil
+}
@@ -0,0 +1,318 @@
+// The TigerBeetle operations, served by tb-grpc-gateway for languages without a native client.
+//
+// The accounts, transfers and filters are the messages of tigerbeetle/v1/types.proto, shared
+// with the other services. Result codes are the numeric values of the CreateAccountResult and
+// CreateTransferResult enums, with their names for readability.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: tigerbeetle.proto
+
+package tigerbeetlepb
+
+import (
+	context "context"
+	tbproto "github.com/tigerbeetle/tigerbeetle-go/pkg/tbproto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TigerBeetle_CreateAccounts_FullMethodName      = "/tigerbeetle.v1.TigerBeetle/CreateAccounts"
+	TigerBeetle_CreateTransfers_FullMethodName     = "/tigerbeetle.v1.TigerBeetle/CreateTransfers"
+	TigerBeetle_LookupAccounts_FullMethodName      = "/tigerbeetle.v1.TigerBeetle/LookupAccounts"
+	TigerBeetle_LookupTransfers_FullMethodName     = "/tigerbeetle.v1.TigerBeetle/LookupTransfers"
+	TigerBeetle_GetAccountTransfers_FullMethodName = "/tigerbeetle.v1.TigerBeetle/GetAccountTransfers"
+	TigerBeetle_GetAccountHistory_FullMethodName   = "/tigerbeetle.v1.TigerBeetle/GetAccountHistory"
+)
+
+// TigerBeetleClient is the client API for TigerBeetle service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TigerBeetleClient interface {
+	CreateAccounts(ctx context.Context, in *CreateAccountsRequest, opts ...grpc.CallOption) (*CreateAccountsResponse, error)
+	CreateTransfers(ctx context.Context, in *CreateTransfersRequest, opts ...grpc.CallOption) (*CreateTransfersResponse, error)
+	LookupAccounts(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupAccountsResponse, error)
+	LookupTransfers(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupTransfersResponse, error)
+	GetAccountTransfers(ctx context.Context, in *tbproto.AccountFilter, opts ...grpc.CallOption) (*GetAccountTransfersResponse, error)
+	GetAccountHistory(ctx context.Context, in *tbproto.AccountFilter, opts ...grpc.CallOption) (*GetAccountHistoryResponse, error)
+}
+
+type tigerBeetleClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTigerBeetleClient(cc grpc.ClientConnInterface) TigerBeetleClient {
+	return &tigerBeetleClient{cc}
+}
+
+func (c *tigerBeetleClient) CreateAccounts(ctx context.Context, in *CreateAccountsRequest, opts ...grpc.CallOption) (*CreateAccountsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAccountsResponse)
+	err := c.cc.Invoke(ctx, TigerBeetle_CreateAccounts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tigerBeetleClient) CreateTransfers(ctx context.Context, in *CreateTransfersRequest, opts ...grpc.CallOption) (*CreateTransfersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateTransfersResponse)
+	err := c.cc.Invoke(ctx, TigerBeetle_CreateTransfers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tigerBeetleClient) LookupAccounts(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupAccountsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupAccountsResponse)
+	err := c.cc.Invoke(ctx, TigerBeetle_LookupAccounts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tigerBeetleClient) LookupTransfers(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupTransfersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupTransfersResponse)
+	err := c.cc.Invoke(ctx, TigerBeetle_LookupTransfers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tigerBeetleClient) GetAccountTransfers(ctx context.Context, in *tbproto.AccountFilter, opts ...grpc.CallOption) (*GetAccountTransfersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAccountTransfersResponse)
+	err := c.cc.Invoke(ctx, TigerBeetle_GetAccountTransfers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tigerBeetleClient) GetAccountHistory(ctx context.Context, in *tbproto.AccountFilter, opts ...grpc.CallOption) (*GetAccountHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAccountHistoryResponse)
+	err := c.cc.Invoke(ctx, TigerBeetle_GetAccountHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TigerBeetleServer is the server API for TigerBeetle service.
+// All implementations must embed UnimplementedTigerBeetleServer
+// for forward compatibility.
+type TigerBeetleServer interface {
+	CreateAccounts(context.Context, *CreateAccountsRequest) (*CreateAccountsResponse, error)
+	CreateTransfers(context.Context, *CreateTransfersRequest) (*CreateTransfersResponse, error)
+	LookupAccounts(context.Context, *LookupRequest) (*LookupAccountsResponse, error)
+	LookupTransfers(context.Context, *LookupRequest) (*LookupTransfersResponse, error)
+	GetAccountTransfers(context.Context, *tbproto.AccountFilter) (*GetAccountTransfersResponse, error)
+	GetAccountHistory(context.Context, *tbproto.AccountFilter) (*GetAccountHistoryResponse, error)
+	mustEmbedUnimplementedTigerBeetleServer()
+}
+
+// UnimplementedTigerBeetleServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTigerBeetleServer struct{}
+
+func (UnimplementedTigerBeetleServer) CreateAccounts(context.Context, *CreateAccountsRequest) (*CreateAccountsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateAccounts not implemented")
+}
+func (UnimplementedTigerBeetleServer) CreateTransfers(context.Context, *CreateTransfersRequest) (*CreateTransfersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateTransfers not implemented")
+}
+func (UnimplementedTigerBeetleServer) LookupAccounts(context.Context, *LookupRequest) (*LookupAccountsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method LookupAccounts not implemented")
+}
+func (UnimplementedTigerBeetleServer) LookupTransfers(context.Context, *LookupRequest) (*LookupTransfersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method LookupTransfers not implemented")
+}
+func (UnimplementedTigerBeetleServer) GetAccountTransfers(context.Context, *tbproto.AccountFilter) (*GetAccountTransfersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAccountTransfers not implemented")
+}
+func (UnimplementedTigerBeetleServer) GetAccountHistory(context.Context, *tbproto.AccountFilter) (*GetAccountHistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAccountHistory not implemented")
+}
+func (UnimplementedTigerBeetleServer) mustEmbedUnimplementedTigerBeetleServer() {}
+func (UnimplementedTigerBeetleServer) testEmbeddedByValue()                     {}
+
+// UnsafeTigerBeetleServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TigerBeetleServer will
+// result in compilation errors.
+type UnsafeTigerBeetleServer interface {
+	mustEmbedUnimplementedTigerBeetleServer()
+}
+
+func RegisterTigerBeetleServer(s grpc.ServiceRegistrar, srv TigerBeetleServer) {
+	// If the following call panics, it indicates UnimplementedTigerBeetleServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TigerBeetle_ServiceDesc, srv)
+}
+
+func _TigerBeetle_CreateAccounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAccountsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TigerBeetleServer).CreateAccounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TigerBeetle_CreateAccounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TigerBeetleServer).CreateAccounts(ctx, req.(*CreateAccountsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TigerBeetle_CreateTransfers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTransfersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TigerBeetleServer).CreateTransfers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TigerBeetle_CreateTransfers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TigerBeetleServer).CreateTransfers(ctx, req.(*CreateTransfersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TigerBeetle_LookupAccounts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TigerBeetleServer).LookupAccounts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TigerBeetle_LookupAccounts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TigerBeetleServer).LookupAccounts(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TigerBeetle_LookupTransfers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TigerBeetleServer).LookupTransfers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TigerBeetle_LookupTransfers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TigerBeetleServer).LookupTransfers(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TigerBeetle_GetAccountTransfers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(tbproto.AccountFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TigerBeetleServer).GetAccountTransfers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TigerBeetle_GetAccountTransfers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TigerBeetleServer).GetAccountTransfers(ctx, req.(*tbproto.AccountFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TigerBeetle_GetAccountHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(tbproto.AccountFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TigerBeetleServer).GetAccountHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TigerBeetle_GetAccountHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TigerBeetleServer).GetAccountHistory(ctx, req.(*tbproto.AccountFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TigerBeetle_ServiceDesc is the grpc.ServiceDesc for TigerBeetle service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TigerBeetle_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tigerbeetle.v1.TigerBeetle",
+	HandlerType: (*TigerBeetleServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateAccounts",
+			Handler:    _TigerBeetle_CreateAccounts_Handler,
+		},
+		{
+			MethodName: "CreateTransfers",
+			Handler:    _TigerBeetle_CreateTransfers_Handler,
+		},
+		{
+			MethodName: "LookupAccounts",
+			Handler:    _TigerBeetle_LookupAccounts_Handler,
+		},
+		{
+			MethodName: "LookupTransfers",
+			Handler:    _TigerBeetle_LookupTransfers_Handler,
+		},
+		{
+			MethodName: "GetAccountTransfers",
+			Handler:    _TigerBeetle_GetAccountTransfers_Handler,
+		},
+		{
+			MethodName: "GetAccountHistory",
+			Handler:    _TigerBeetle_GetAccountHistory_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tigerbeetle.proto",
+}
@@ -0,0 +1,77 @@
+// Command tb-http serves the client operations over HTTP and JSON, for quick integrations and
+// debugging, by forwarding every request through one Go client:
+//
+//	POST /accounts                 create a batch of accounts
+//	POST /transfers                create a batch of transfers
+//	GET  /accounts/{id}/transfers  page through the transfers of an account
+//	GET  /openapi.json             the OpenAPI document of the API
+//
+// A batch is a JSON array of events, or a single event, and is submitted as one request. It
+// returns 201 once every event was created. Otherwise it returns a problem details object listing
+// the events that were not created, with a status for each: 409 when the event already exists,
+// 404 when an account or pending transfer it refers to does not, and 422 for the other results.
+// The other events of the batch were created.
+//
+// The transfers of an account are filtered by the query parameters limit, timestamp_min,
+// timestamp_max, debits, credits and reversed, and a full page links to the next one.
+//
+// The OpenAPI document is derived from the Go types, and openapi.json, a copy of it for
+// generating clients, is updated by go generate, or with -openapi, which writes it and exits.
+//
+//	tb-http [-listen :8080] [-addresses 3000] [-cluster 0] [-concurrency 1024] [-openapi path]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func main() {
+	listen := flag.String("listen", ":8080", "address to serve HTTP on")
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	concurrency := flag.Uint("concurrency", 1024, "requests in flight to the cluster, more wait")
+	openAPIPath := flag.String("openapi", "", "write the OpenAPI document to this path and exit")
+	flag.Parse()
+
+	if *openAPIPath != "" {
+		if err := writeOpenAPI(*openAPIPath); err != nil {
+			log.Fatalf("Error writing OpenAPI document: %s", err)
+		}
+		return
+	}
+
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(*clusterID),
+		strings.Split(*addresses, ","),
+		*concurrency,
+		tigerbeetle_go.WithConcurrencyMode(tigerbeetle_go.ConcurrencyBlock),
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	httpServer := &http.Server{Addr: *listen, Handler: (&server{client: client}).handler()}
+
+	go func() {
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		<-interrupt
+		_ = httpServer.Shutdown(context.Background())
+	}()
+
+	log.Printf("Serving on %s", *listen)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Error serving: %s", err)
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+//go:generate go run . -openapi openapi.json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// apiVersion is the version of the API in the OpenAPI document, to bump when the API changes in a
+// way that breaks generated clients.
+const apiVersion = "1"
+
+// schema is a JSON Schema object, or any other object of the OpenAPI document.
+type schema = map[string]any
+
+// components names the schemas of the Go types that the document refers to, rather than inlines.
+var components = map[reflect.Type]string{
+	reflect.TypeFor[types.Uint128]():  "Uint128",
+	reflect.TypeFor[types.Account]():  "Account",
+	reflect.TypeFor[types.Transfer](): "Transfer",
+}
+
+// openAPI returns the OpenAPI 3.1 document of the API that the server serves. The schemas are
+// derived from the Go types and their JSON encoding, so that the document follows them; openapi.json
+// is a copy of it for generating clients, kept in sync by go generate.
+func openAPI() schema {
+	problemResponse := func(description string) schema {
+		return schema{
+			"description": description,
+			"content":     schema{"application/problem+json": schema{"schema": ref("Problem")}},
+		}
+	}
+	rejectedResponse := func(description, rejected string) schema {
+		return schema{
+			"description": description,
+			"content": schema{"application/problem+json": schema{"schema": schema{
+				"anyOf": []any{ref(rejected), ref("Problem")},
+			}}},
+		}
+	}
+	batchOperation := func(id, summary, event, rejected string) schema {
+		return schema{
+			"operationId": id,
+			"summary":     summary,
+			"description": "The batch is a JSON array of events, or a single event, and is submitted as one " +
+				"request. When some events are not created, the others are, and the problem lists the " +
+				"events that were not created. Its status is that of their results if they share one, " +
+				"and otherwise 422.",
+			"requestBody": schema{
+				"required": true,
+				"content": schema{"application/json": schema{"schema": schema{
+					"oneOf": []any{ref(event), schema{"type": "array", "items": ref(event), "minItems": 1}},
+				}}},
+			},
+			"responses": schema{
+				"201": schema{
+					"description": "Every event was created.",
+					"content":     schema{"application/json": schema{"schema": ref("BatchResponse")}},
+				},
+				"400": problemResponse("The batch is malformed, empty or invalid."),
+				"404": rejectedResponse("Events refer to accounts or pending transfers that don't exist.", rejected),
+				"409": rejectedResponse("Events already exist.", rejected),
+				"413": problemResponse("The batch is too large."),
+				"422": rejectedResponse("Events were not created.", rejected),
+				"429": problemResponse("Too many requests are in flight."),
+				"500": problemResponse("The request failed."),
+				"503": problemResponse("The client is closed or the cluster in maintenance."),
+			},
+		}
+	}
+
+	// The transfers of an account are filtered by the fields of the filter other than the account
+	// and the flags, and by a boolean for every flag.
+	parameters := []any{schema{
+		"name":        "id",
+		"in":          "path",
+		"required":    true,
+		"description": "The ID of the account, in decimal or in hex with a 0x prefix.",
+		"schema":      ref("Uint128"),
+	}}
+	filterType := reflect.TypeFor[types.AccountFilter]()
+	for i := range filterType.NumField() {
+		name, _, ok := jsonField(filterType.Field(i))
+		if !ok || name == "account_id" || name == "flags" {
+			continue
+		}
+		parameter := schema{"name": name, "in": "query", "schema": typeSchema(filterType.Field(i).Type)}
+		if name == "limit" {
+			parameter["schema"] = schema{
+				"type":    "integer",
+				"minimum": 1,
+				"maximum": types.MaxBatchSize(types.OperationGetAccountTransfers),
+				"default": transfersLimitDefault,
+			}
+		}
+		parameters = append(parameters, parameter)
+	}
+	for _, flag := range flagNames(types.AccountFilterFlags{}) {
+		parameters = append(parameters, schema{
+			"name":        flag,
+			"in":          "query",
+			"description": "Without debits or credits, both are included.",
+			"schema":      schema{"type": "boolean"},
+		})
+	}
+
+	// Accounts don't refer to others.
+	createAccounts := batchOperation("createAccounts", "Create a batch of accounts", "Account", "AccountsRejected")
+	delete(createAccounts["responses"].(schema), "404")
+
+	return schema{
+		"openapi": "3.1.0",
+		"info": schema{
+			"title":   "TigerBeetle HTTP proxy",
+			"version": apiVersion,
+			"description": "Serves the TigerBeetle client operations over HTTP and JSON. IDs and amounts " +
+				"are 128-bit integers, encoded as decimal strings, and accepted in hex with a 0x prefix too.",
+		},
+		"paths": schema{
+			"/accounts": schema{
+				"post": createAccounts,
+			},
+			"/transfers": schema{
+				"post": batchOperation("createTransfers", "Create a batch of transfers", "Transfer", "TransfersRejected"),
+			},
+			"/accounts/{id}/transfers": schema{
+				"get": schema{
+					"operationId": "getAccountTransfers",
+					"summary":     "Page through the transfers of an account",
+					"description": "A full page links to the next one.",
+					"parameters":  parameters,
+					"responses": schema{
+						"200": schema{
+							"description": "A page of the transfers of the account.",
+							"content":     schema{"application/json": schema{"schema": ref("TransfersPage")}},
+						},
+						"400": problemResponse("The account ID or the query is invalid."),
+						"429": problemResponse("Too many requests are in flight."),
+						"500": problemResponse("The request failed."),
+						"503": problemResponse("The client is closed or the cluster in maintenance."),
+					},
+				},
+			},
+		},
+		"components": schema{"schemas": schema{
+			"Uint128": schema{
+				"type":        "string",
+				"pattern":     "^([0-9]{1,39}|0[xX][0-9a-fA-F]{1,32})$",
+				"description": "A 128-bit unsigned integer, in decimal, or in hex with a 0x prefix.",
+			},
+			"Account": structSchema[types.Account](
+				"An account. Fields left out of a request are zero.", false,
+				schema{"flags": flagsSchema(types.AccountFlags{})},
+			),
+			"Transfer": structSchema[types.Transfer](
+				"A transfer. Fields left out of a request are zero.", false,
+				schema{"flags": flagsSchema(types.TransferFlags{})},
+			),
+			"AccountResult": structSchema[eventResult](
+				"Why the account at index of the batch was not created.", true,
+				schema{"result": resultSchema[types.CreateAccountResult]()},
+			),
+			"TransferResult": structSchema[eventResult](
+				"Why the transfer at index of the batch was not created.", true,
+				schema{"result": resultSchema[types.CreateTransferResult]()},
+			),
+			"Problem": structSchema[problem](
+				"An RFC 9457 problem details object.", true,
+				schema{"results": nil},
+			),
+			"AccountsRejected":  rejectedSchema(problemAccountsRejected, "AccountResult"),
+			"TransfersRejected": rejectedSchema(problemTransfersRejected, "TransferResult"),
+			"BatchResponse": structSchema[batchResponse](
+				"The response of a batch of which every event was created.", true,
+				schema{"results": schema{"type": "array", "maxItems": 0}},
+			),
+			"TransfersPage": structSchema[transfersPage](
+				"A page of transfers. Next is the path of the following page, and is left out of the last one.", true,
+				nil,
+			),
+		}},
+	}
+}
+
+func ref(component string) schema {
+	return schema{"$ref": "#/components/schemas/" + component}
+}
+
+// structSchema returns the schema of the JSON object of S, with the fields that overrides has in
+// place of those of their type, and without the fields it sets to nil. With required, the fields
+// that are always encoded are required.
+func structSchema[S any](description string, required bool, overrides schema) schema {
+	t := reflect.TypeFor[S]()
+	properties := schema{}
+	var names []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, omitEmpty, ok := jsonField(field)
+		if !ok {
+			continue
+		}
+		property, overridden := overrides[name]
+		if !overridden {
+			property = typeSchema(field.Type)
+		} else if property == nil {
+			continue
+		}
+		properties[name] = property
+		if required && !omitEmpty {
+			names = append(names, name)
+		}
+	}
+	object := schema{"type": "object", "description": description, "properties": properties}
+	if len(names) > 0 {
+		object["required"] = names
+	}
+	return object
+}
+
+// typeSchema returns the schema of the JSON encoding of t.
+func typeSchema(t reflect.Type) schema {
+	if component, ok := components[t]; ok {
+		return ref(component)
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int:
+		return schema{"type": "integer"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema{"type": "integer", "minimum": 0, "maximum": uint64(math.MaxUint64) >> (64 - t.Bits())}
+	case reflect.Slice:
+		return schema{"type": "array", "items": typeSchema(t.Elem())}
+	}
+	panic(fmt.Sprintf("tb-http: no schema for %s", t))
+}
+
+// jsonField returns the name that encoding/json encodes field under, and whether it is left out
+// when empty, unless it is not encoded.
+func jsonField(field reflect.StructField) (name string, omitEmpty bool, ok bool) {
+	if !field.IsExported() {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, slices.Contains(strings.Split(options, ","), "omitempty"), true
+}
+
+// flagNames returns the names of the flags of flags, a zero value of a flags type, in the order of
+// their bits.
+func flagNames(flags fmt.Stringer) []string {
+	t := reflect.TypeOf(flags)
+	names := make([]string, t.NumField())
+	for i := range names {
+		value := reflect.New(t).Elem()
+		value.Field(i).SetBool(true)
+		names[i] = value.Interface().(fmt.Stringer).String()
+	}
+	return names
+}
+
+// flagsSchema returns the schema of the bit set of the flags of flags, a zero value of a flags
+// type, which lists them by bit.
+func flagsSchema(flags fmt.Stringer) schema {
+	names := flagNames(flags)
+	bits := make([]string, len(names))
+	for i, name := range names {
+		bits[i] = fmt.Sprintf("%s (%d)", name, 1<<i)
+	}
+	return schema{
+		"type":        "integer",
+		"minimum":     0,
+		"maximum":     1<<len(names) - 1,
+		"description": "A bit set of the flags " + strings.Join(bits, ", ") + ".",
+	}
+}
+
+// resultSchema returns the schema of the names of the results of R, other than OK.
+func resultSchema[R interface {
+	~uint32
+	fmt.Stringer
+}]() schema {
+	var names []string
+	for code := R(1); code <= math.MaxUint8; code++ {
+		// Codes without a name are formatted as Type(code).
+		if name := code.String(); !strings.HasSuffix(name, ")") {
+			names = append(names, name)
+		}
+	}
+	return schema{
+		"type":        "string",
+		"enum":        names,
+		"description": "The name of the result, whose number is code.",
+	}
+}
+
+// rejectedSchema returns the schema of the problem of a batch of which some events were not
+// created, of type kind, listing their results.
+func rejectedSchema(kind, result string) schema {
+	return schema{
+		"allOf": []any{
+			ref("Problem"),
+			schema{
+				"type": "object",
+				"properties": schema{
+					"type":    schema{"const": kind},
+					"results": schema{"type": "array", "items": ref(result), "minItems": 1},
+				},
+				"required": []string{"type", "results"},
+			},
+		},
+	}
+}
+
+func (s *server) getOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, openAPI())
+}
+
+// writeOpenAPI writes the OpenAPI document to path.
+func writeOpenAPI(path string) error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(openAPI()); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data.Bytes(), 0o644)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	e "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// problem is an RFC 9457 problem details object. Without a type, the problem is described by
+// its status alone.
+type problem struct {
+	Type   string `json:"type,omitempty"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Results lists the events of a batch that were not created.
+	Results []eventResult `json:"results,omitempty"`
+}
+
+// eventResult is why the event at Index of a batch was not created.
+type eventResult struct {
+	Index  uint32 `json:"index"`
+	Result string `json:"result"`
+	Code   uint32 `json:"code"`
+	Status int    `json:"status"`
+}
+
+const (
+	problemAccountsRejected  = "urn:tigerbeetle:problem:accounts-rejected"
+	problemTransfersRejected = "urn:tigerbeetle:problem:transfers-rejected"
+)
+
+func writeProblem(w http.ResponseWriter, p problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// writeError writes the problem of a request that failed as a whole.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case e.Is(err, errors.ErrMaximumBatchSizeExceeded{}):
+		status = http.StatusRequestEntityTooLarge
+	case e.Is(err, errors.ErrEmptyBatch{}),
+		e.As(err, new(errors.ErrInvalidAccount)),
+		e.As(err, new(errors.ErrInvalidTransfer)):
+		status = http.StatusBadRequest
+	case e.Is(err, errors.ErrConcurrencyExceeded{}):
+		status = http.StatusTooManyRequests
+	case e.Is(err, errors.ErrClientClosed{}), e.Is(err, errors.ErrMaintenance{}):
+		status = http.StatusServiceUnavailable
+	}
+	writeProblem(w, problem{Title: http.StatusText(status), Status: status, Detail: err.Error()})
+}
+
+// rejected returns the problem of a batch of which some events were not created. Its status is
+// that of the results if they all share one, and otherwise 422.
+func rejected(kind, title string, count int, results []eventResult) problem {
+	status := results[0].Status
+	for _, result := range results[1:] {
+		if result.Status != status {
+			status = http.StatusUnprocessableEntity
+			break
+		}
+	}
+	return problem{
+		Type:    kind,
+		Title:   title,
+		Status:  status,
+		Detail:  fmt.Sprintf("%d of %d events were not created; the others were.", len(results), count),
+		Results: results,
+	}
+}
+
+func accountResultStatus(result types.CreateAccountResult) int {
+	switch {
+	case result >= types.AccountExistsWithDifferentFlags && result <= types.AccountExists:
+		return http.StatusConflict
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+func transferResultStatus(result types.CreateTransferResult) int {
+	switch {
+	case result >= types.TransferExistsWithDifferentFlags && result <= types.TransferExists:
+		return http.StatusConflict
+	case result == types.TransferDebitAccountNotFound,
+		result == types.TransferCreditAccountNotFound,
+		result == types.TransferPendingTransferNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
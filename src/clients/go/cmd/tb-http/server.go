@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const (
+	// bodySizeMax bounds a request body, generously above a full batch of events in JSON.
+	bodySizeMax = 16 * 1024 * 1024
+
+	// transfersLimitDefault is the page size of account transfers when no limit is given.
+	transfersLimitDefault = 100
+)
+
+// server serves the client operations over HTTP, forwarding every request to one client.
+type server struct {
+	client tigerbeetle_go.Client
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /accounts", s.createAccounts)
+	mux.HandleFunc("POST /transfers", s.createTransfers)
+	mux.HandleFunc("GET /accounts/{id}/transfers", s.getAccountTransfers)
+	mux.HandleFunc("GET /openapi.json", s.getOpenAPI)
+	return mux
+}
+
+// batchResponse is the response of a batch of which every event was created.
+type batchResponse struct {
+	Results []eventResult `json:"results"`
+}
+
+func (s *server) createAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, ok := decodeBatch[types.Account](w, r)
+	if !ok {
+		return
+	}
+
+	results, err := s.client.CreateAccounts(accounts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(results) > 0 {
+		rejections := make([]eventResult, len(results))
+		for i, result := range results {
+			rejections[i] = eventResult{
+				Index:  result.Index,
+				Result: result.Result.String(),
+				Code:   uint32(result.Result),
+				Status: accountResultStatus(result.Result),
+			}
+		}
+		writeProblem(w, rejected(problemAccountsRejected, "Accounts not created", len(accounts), rejections))
+		return
+	}
+	writeJSON(w, http.StatusCreated, batchResponse{Results: []eventResult{}})
+}
+
+func (s *server) createTransfers(w http.ResponseWriter, r *http.Request) {
+	transfers, ok := decodeBatch[types.Transfer](w, r)
+	if !ok {
+		return
+	}
+
+	results, err := s.client.CreateTransfers(transfers)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(results) > 0 {
+		rejections := make([]eventResult, len(results))
+		for i, result := range results {
+			rejections[i] = eventResult{
+				Index:  result.Index,
+				Result: result.Result.String(),
+				Code:   uint32(result.Result),
+				Status: transferResultStatus(result.Result),
+			}
+		}
+		writeProblem(w, rejected(problemTransfersRejected, "Transfers not created", len(transfers), rejections))
+		return
+	}
+	writeJSON(w, http.StatusCreated, batchResponse{Results: []eventResult{}})
+}
+
+// transfersPage is a page of the transfers of an account. Next is the path of the following page,
+// and is omitted on the last one.
+type transfersPage struct {
+	Transfers []types.Transfer `json:"transfers"`
+	Next      string           `json:"next,omitempty"`
+}
+
+func (s *server) getAccountTransfers(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAccountFilter(r.PathValue("id"), r.URL.Query())
+	if err != nil {
+		writeProblem(w, problem{Title: "Invalid query", Status: http.StatusBadRequest, Detail: err.Error()})
+		return
+	}
+
+	transfers, err := s.client.GetAccountTransfers(filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page := transfersPage{Transfers: transfers}
+	if len(transfers) > 0 && len(transfers) == int(filter.Limit) {
+		// Continue past the last transfer, in the direction of the page.
+		query := r.URL.Query()
+		last := transfers[len(transfers)-1].Timestamp
+		if filter.Flags&(types.AccountFilterFlags{Reversed: true}).ToUint32() != 0 {
+			query.Set("timestamp_max", strconv.FormatUint(last-1, 10))
+		} else {
+			query.Set("timestamp_min", strconv.FormatUint(last+1, 10))
+		}
+		page.Next = r.URL.Path + "?" + query.Encode()
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// parseAccountFilter reads the filter of the transfers of account id from the query parameters
+// limit, timestamp_min, timestamp_max, debits, credits and reversed. Without debits or credits,
+// both are included.
+func parseAccountFilter(id string, query url.Values) (types.AccountFilter, error) {
+	accountID, err := parseID(id)
+	if err != nil {
+		return types.AccountFilter{}, fmt.Errorf("account id %q: %w", id, err)
+	}
+	filter := types.AccountFilter{AccountID: accountID, Limit: transfersLimitDefault}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.ParseUint(value, 10, 32)
+		limitMax := types.MaxBatchSize(types.OperationGetAccountTransfers)
+		if err != nil || limit == 0 || limit > uint64(limitMax) {
+			return types.AccountFilter{}, fmt.Errorf("limit %q is not between 1 and %d", value, limitMax)
+		}
+		filter.Limit = uint32(limit)
+	}
+	for _, param := range []struct {
+		name  string
+		value *uint64
+	}{
+		{"timestamp_min", &filter.TimestampMin},
+		{"timestamp_max", &filter.TimestampMax},
+	} {
+		if value := query.Get(param.name); value != "" {
+			if *param.value, err = strconv.ParseUint(value, 10, 64); err != nil {
+				return types.AccountFilter{}, fmt.Errorf("%s %q is not a timestamp", param.name, value)
+			}
+		}
+	}
+
+	var flags types.AccountFilterFlags
+	for _, param := range []struct {
+		name  string
+		value *bool
+	}{
+		{"debits", &flags.Debits},
+		{"credits", &flags.Credits},
+		{"reversed", &flags.Reversed},
+	} {
+		if value := query.Get(param.name); value != "" {
+			if *param.value, err = strconv.ParseBool(value); err != nil {
+				return types.AccountFilter{}, fmt.Errorf("%s %q is not a boolean", param.name, value)
+			}
+		}
+	}
+	if !query.Has("debits") && !query.Has("credits") {
+		flags.Debits, flags.Credits = true, true
+	}
+	filter.Flags = flags.ToUint32()
+	return filter, nil
+}
+
+// parseID reads an ID in decimal, or in hex with a "0x" prefix.
+func parseID(text string) (types.Uint128, error) {
+	if hex, ok := strings.CutPrefix(strings.ToLower(text), "0x"); ok {
+		return types.HexStringToUint128(hex)
+	}
+	return types.DecStringToUint128(text)
+}
+
+// decodeBatch reads the events of a request, given as a JSON array or as a single object, and
+// writes the problem if they are malformed. Unknown fields are rejected so that misspelled ones
+// are not silently zero.
+func decodeBatch[E any](w http.ResponseWriter, r *http.Request) ([]E, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, bodySizeMax))
+	if err != nil {
+		status := http.StatusBadRequest
+		if _, ok := err.(*http.MaxBytesError); ok {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeProblem(w, problem{Title: http.StatusText(status), Status: status, Detail: err.Error()})
+		return nil, false
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '{' {
+		body = append(append([]byte{'['}, body...), ']')
+	}
+
+	var events []E
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&events); err != nil {
+		writeProblem(w, problem{Title: "Invalid JSON", Status: http.StatusBadRequest, Detail: err.Error()})
+		return nil, false
+	}
+	if len(events) == 0 {
+		writeError(w, errors.ErrEmptyBatch{})
+		return nil, false
+	}
+	return events, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+}
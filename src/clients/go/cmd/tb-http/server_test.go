@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbtest"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// newHandler returns the handler of a server over a client of an in-memory ledger.
+func newHandler(t *testing.T) http.Handler {
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 4,
+		tigerbeetle_go.WithTransport(tbtest.NewLedger(tbtest.NewClock(time.Time{}))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return (&server{client: client}).handler()
+}
+
+// serve sends a request to handler and decodes the JSON of its response into response.
+func serve(t *testing.T, handler http.Handler, method, path, body string, response any) *http.Response {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	if response != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+			t.Fatalf("Error decoding %q: %s", recorder.Body.String(), err)
+		}
+	}
+	return recorder.Result()
+}
+
+func TestCreate(t *testing.T) {
+	handler := newHandler(t)
+
+	var created batchResponse
+	response := serve(t, handler, "POST", "/accounts",
+		`[{"id": 1, "ledger": 1, "code": 1}, {"id": "2", "ledger": 1, "code": 1}]`, &created)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	assert.Empty(t, created.Results)
+
+	// A single event is a batch of one, and an existing one conflicts.
+	var rejection problem
+	response = serve(t, handler, "POST", "/accounts", `{"id": 1, "ledger": 1, "code": 1}`, &rejection)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, "application/problem+json", response.Header.Get("Content-Type"))
+	assert.Equal(t, problemAccountsRejected, rejection.Type)
+	assert.Equal(t, []eventResult{{
+		Index:  0,
+		Result: types.AccountExists.String(),
+		Code:   uint32(types.AccountExists),
+		Status: http.StatusConflict,
+	}}, rejection.Results)
+
+	response = serve(t, handler, "POST", "/transfers", `[
+		{"id": 1, "debit_account_id": 1, "credit_account_id": 2, "amount": 5, "ledger": 1, "code": 1},
+		{"id": 2, "debit_account_id": 1, "credit_account_id": 3, "amount": 5, "ledger": 1, "code": 1}
+	]`, &rejection)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	assert.Equal(t, problemTransfersRejected, rejection.Type)
+	assert.Equal(t, "1 of 2 events were not created; the others were.", rejection.Detail)
+	assert.Len(t, rejection.Results, 1)
+	assert.Equal(t, uint32(1), rejection.Results[0].Index)
+	assert.Equal(t, uint32(types.TransferCreditAccountNotFound), rejection.Results[0].Code)
+
+	// Results of different statuses make the batch unprocessable.
+	response = serve(t, handler, "POST", "/transfers", `[
+		{"id": 1, "debit_account_id": 1, "credit_account_id": 2, "amount": 5, "ledger": 1, "code": 1},
+		{"id": 3, "debit_account_id": 1, "credit_account_id": 2, "amount": 0, "ledger": 2, "code": 1}
+	]`, &rejection)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	assert.Len(t, rejection.Results, 2)
+	assert.Equal(t, http.StatusConflict, rejection.Results[0].Status)
+	assert.Equal(t, http.StatusUnprocessableEntity, rejection.Results[1].Status)
+}
+
+func TestCreateInvalid(t *testing.T) {
+	handler := newHandler(t)
+
+	accounts := make([]string, types.MaxBatchSize(types.OperationCreateAccounts)+1)
+	for i := range accounts {
+		accounts[i] = fmt.Sprintf(`{"id": %d, "ledger": 1, "code": 1}`, i+1)
+	}
+
+	for _, test := range []struct {
+		body   string
+		status int
+		title  string
+	}{
+		{"[]", http.StatusBadRequest, "Bad Request"},
+		{"", http.StatusBadRequest, "Invalid JSON"},
+		{`{"id": 1`, http.StatusBadRequest, "Invalid JSON"},
+		{`{"id": 1.5}`, http.StatusBadRequest, "Invalid JSON"},
+		{`{"idd": 1}`, http.StatusBadRequest, "Invalid JSON"},
+		{"[" + strings.Join(accounts, ",") + "]", http.StatusRequestEntityTooLarge, "Request Entity Too Large"},
+		{strings.Repeat(" ", bodySizeMax+1), http.StatusRequestEntityTooLarge, "Request Entity Too Large"},
+	} {
+		var rejection problem
+		response := serve(t, handler, "POST", "/accounts", test.body, &rejection)
+		assert.Equal(t, test.status, response.StatusCode)
+		assert.Equal(t, test.status, rejection.Status)
+		assert.Equal(t, test.title, rejection.Title)
+		assert.Empty(t, rejection.Results)
+	}
+
+	// The size limit leaves room for a full batch.
+	var created batchResponse
+	response := serve(t, handler, "POST", "/accounts", "["+strings.Join(accounts[1:], ",")+"]", &created)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+}
+
+func TestWriteError(t *testing.T) {
+	for _, test := range []struct {
+		err    error
+		status int
+	}{
+		{errors.ErrMaximumBatchSizeExceeded{}, http.StatusRequestEntityTooLarge},
+		{errors.ErrEmptyBatch{}, http.StatusBadRequest},
+		{errors.ErrInvalidAccount{}, http.StatusBadRequest},
+		{errors.ErrInvalidTransfer{}, http.StatusBadRequest},
+		{errors.ErrConcurrencyExceeded{}, http.StatusTooManyRequests},
+		{errors.ErrClientClosed{}, http.StatusServiceUnavailable},
+		{errors.ErrMaintenance{}, http.StatusServiceUnavailable},
+		{fmt.Errorf("submitting: %w", errors.ErrConcurrencyExceeded{}), http.StatusTooManyRequests},
+		{errors.ErrUnexpected{}, http.StatusInternalServerError},
+	} {
+		recorder := httptest.NewRecorder()
+		writeError(recorder, test.err)
+		assert.Equal(t, test.status, recorder.Code)
+
+		var rejection problem
+		if err := json.Unmarshal(recorder.Body.Bytes(), &rejection); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, problem{
+			Title:  http.StatusText(test.status),
+			Status: test.status,
+			Detail: test.err.Error(),
+		}, rejection)
+	}
+}
+
+func TestGetAccountTransfers(t *testing.T) {
+	handler := newHandler(t)
+
+	var created batchResponse
+	serve(t, handler, "POST", "/accounts",
+		`[{"id": 1, "ledger": 1, "code": 1}, {"id": 2, "ledger": 1, "code": 1}]`, &created)
+	response := serve(t, handler, "POST", "/transfers", `[
+		{"id": 1, "debit_account_id": 1, "credit_account_id": 2, "amount": 1, "ledger": 1, "code": 1},
+		{"id": 2, "debit_account_id": 2, "credit_account_id": 1, "amount": 2, "ledger": 1, "code": 1},
+		{"id": 3, "debit_account_id": 1, "credit_account_id": 2, "amount": 3, "ledger": 1, "code": 1}
+	]`, &created)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+
+	// A full page links to the next one, in the direction of the page.
+	ids := func(transfers []types.Transfer) []types.Uint128 {
+		ids := make([]types.Uint128, len(transfers))
+		for i, transfer := range transfers {
+			ids[i] = transfer.ID
+		}
+		return ids
+	}
+	var page transfersPage
+	response = serve(t, handler, "GET", "/accounts/1/transfers?limit=2", "", &page)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []types.Uint128{types.ToUint128(1), types.ToUint128(2)}, ids(page.Transfers))
+	next := page.Next
+	assert.True(t, strings.HasPrefix(next, "/accounts/1/transfers?"))
+
+	page = transfersPage{}
+	serve(t, handler, "GET", next, "", &page)
+	assert.Equal(t, []types.Uint128{types.ToUint128(3)}, ids(page.Transfers))
+	assert.Equal(t, "", page.Next)
+
+	page = transfersPage{}
+	serve(t, handler, "GET", "/accounts/0x1/transfers?limit=2&reversed=true", "", &page)
+	assert.Equal(t, []types.Uint128{types.ToUint128(3), types.ToUint128(2)}, ids(page.Transfers))
+	page = transfersPage{}
+	serve(t, handler, "GET", "/accounts/0x1/transfers?limit=2&reversed=true&debits=true", "", &page)
+	assert.Equal(t, []types.Uint128{types.ToUint128(3), types.ToUint128(1)}, ids(page.Transfers))
+
+	for _, query := range []string{
+		"/accounts/one/transfers",
+		"/accounts/1/transfers?limit=0",
+		fmt.Sprintf("/accounts/1/transfers?limit=%d", types.MaxBatchSize(types.OperationGetAccountTransfers)+1),
+		"/accounts/1/transfers?timestamp_min=-1",
+		"/accounts/1/transfers?credits=maybe",
+	} {
+		var rejection problem
+		response := serve(t, handler, "GET", query, "", &rejection)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Equal(t, "Invalid query", rejection.Title)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	handler := newHandler(t)
+
+	var document map[string]any
+	response := serve(t, handler, "GET", "/openapi.json", "", &document)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+
+	// The served document documents every route, and openapi.json is up to date with it.
+	paths := document["paths"].(map[string]any)
+	assert.Len(t, paths, 3)
+	for _, path := range []string{"/accounts", "/transfers", "/accounts/{id}/transfers"} {
+		_, ok := paths[path]
+		assert.True(t, ok)
+	}
+
+	data, err := os.ReadFile("openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generated map[string]any
+	if err := json.Unmarshal(data, &generated); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, generated, document)
+}
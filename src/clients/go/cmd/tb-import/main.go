@@ -0,0 +1,146 @@
+// Command tb-import bulk-loads accounts or transfers from a CSV or JSON lines file, in the format
+// described by pkg/importer, and reports the result code of every row.
+//
+// Progress is checkpointed to a file after every batch, and a rerun with the same checkpoint
+// resumes after the last batch that was imported. The client resubmits a batch until the cluster
+// replies, and batches it fails for transient reasons are retried up to -attempts times.
+//
+//	tb-import -kind accounts|transfers [-format csv|jsonl] [-checkpoint <path>] [-addresses 3000]
+//	    [-cluster 0] [-batch-size 0] [-attempts 5] [-report-format table|json|csv] <file>
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/importer"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/output"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func main() {
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	kind := flag.String("kind", "", "what the file holds: accounts or transfers")
+	checkpointPath := flag.String("checkpoint", "", "checkpoint file, defaults to <file>.checkpoint")
+	attempts := flag.Int("attempts", 5, "attempts per batch before giving up on transient failures")
+	var config importer.Config
+	flag.Var(&config.Format, "format", "input format: csv or jsonl, defaults to the file extension")
+	flag.IntVar(&config.BatchSize, "batch-size", 0, "rows per request, 0 for the most that fit")
+	reportFormat := output.FormatCSV
+	flag.Var(&reportFormat, "report-format", "report format: table, json or csv")
+	flag.Parse()
+
+	if flag.NArg() != 1 || (*kind != "accounts" && *kind != "transfers") {
+		log.Fatalf("Usage: tb-import -kind accounts|transfers [-format csv|jsonl] [-checkpoint <path>] <file>")
+	}
+	path := flag.Arg(0)
+
+	formatSet := false
+	flag.Visit(func(f *flag.Flag) { formatSet = formatSet || f.Name == "format" })
+	if !formatSet {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".jsonl", ".ndjson":
+			config.Format = importer.FormatJSONL
+		}
+	}
+
+	if *checkpointPath == "" {
+		*checkpointPath = path + ".checkpoint"
+	}
+	resume, err := readCheckpoint(*checkpointPath)
+	if err != nil {
+		log.Fatalf("Error reading checkpoint: %s", err)
+	}
+	config.Resume = resume
+	config.Checkpoint = func(rows int) error { return writeCheckpoint(*checkpointPath, rows) }
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Error opening file: %s", err)
+	}
+	defer file.Close()
+
+	policy := tigerbeetle_go.DefaultRetryPolicy()
+	policy.MaxAttempts = *attempts
+	policy.BudgetRatio = 0
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(*clusterID),
+		strings.Split(*addresses, ","),
+		1,
+		tigerbeetle_go.WithRetryPolicy(policy),
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	writer, err := output.NewWriter(os.Stdout, reportFormat, "row", "id", "result", "code")
+	if err != nil {
+		log.Fatalf("Error writing output: %s", err)
+	}
+	config.Report = func(result importer.RowResult) error {
+		return writer.Row(
+			strconv.Itoa(result.Row),
+			result.ID.String(),
+			result.Result,
+			strconv.FormatUint(uint64(result.Code), 10),
+		)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if resume > 0 {
+		log.Printf("Resuming after row %d", resume)
+	}
+	var summary importer.Summary
+	if *kind == "accounts" {
+		summary, err = importer.ImportAccounts(ctx, client, file, config)
+	} else {
+		summary, err = importer.ImportTransfers(ctx, client, file, config)
+	}
+	if flushErr := writer.Flush(); flushErr != nil {
+		log.Fatalf("Error writing output: %s", flushErr)
+	}
+	log.Printf("Imported %d rows in %d batches: %d already existed, %d rejected",
+		summary.Rows, summary.Batches, summary.Exists, summary.Rejected)
+	if err != nil {
+		log.Fatalf("Error importing: %s", err)
+	}
+	if summary.Rejected > 0 {
+		os.Exit(1)
+	}
+}
+
+// readCheckpoint returns the number of rows imported according to the checkpoint at path, or 0
+// if there is none yet.
+func readCheckpoint(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// writeCheckpoint replaces the checkpoint at path, atomically so that a crash leaves either the
+// previous checkpoint or the new one.
+func writeCheckpoint(path string, rows int) error {
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, []byte(strconv.Itoa(rows)+"\n"), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
@@ -0,0 +1,213 @@
+// Command tb-kafka-cdc tails the transfers and balances of a set of accounts and publishes them to
+// Kafka, as described by pkg/cdc: a record per transfer to -transfers-topic, keyed by the transfer
+// ID, and a record per balance to -balances-topic, keyed by the account ID.
+//
+// Every batch of records is produced in a Kafka transaction together with the checkpoint after
+// it, which goes to the compacted -checkpoint-topic under the key -transactional-id. A restart
+// resumes from the last committed checkpoint, and since starting a producer with the same
+// transactional ID fences and aborts the transaction of the previous one, every record is
+// committed exactly once. Consumers must read with the read_committed isolation level.
+//
+//	tb-kafka-cdc -accounts <id>[,<id>...] [-brokers localhost:9092] [-format json|avro]
+//	    [-transfers-topic tigerbeetle.transfers] [-balances-topic tigerbeetle.balances]
+//	    [-checkpoint-topic tigerbeetle.cdc.checkpoints] [-transactional-id tb-kafka-cdc]
+//	    [-poll-interval 1s] [-addresses 3000] [-cluster 0]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/cdc"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// checkpointReadIdle is how long reading the checkpoint topic waits for more records before
+// taking the last one read.
+const checkpointReadIdle = 2 * time.Second
+
+func main() {
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	brokers := flag.String("brokers", "localhost:9092", "comma-separated Kafka brokers")
+	accounts := flag.String("accounts", "", "comma-separated decimal IDs of the accounts to tail")
+	checkpointTopic := flag.String("checkpoint-topic", "tigerbeetle.cdc.checkpoints", "compacted topic of the checkpoints")
+	transactionalID := flag.String("transactional-id", "tb-kafka-cdc", "Kafka transactional ID, unique per capture")
+	var config cdc.Config
+	flag.StringVar(&config.TransfersTopic, "transfers-topic", "tigerbeetle.transfers", "topic of the transfers")
+	flag.StringVar(&config.BalancesTopic, "balances-topic", "tigerbeetle.balances", "topic of the balances")
+	flag.Var(&config.Format, "format", "record format: json or avro")
+	flag.DurationVar(&config.PollInterval, "poll-interval", time.Second, "wait between polls once caught up")
+	flag.Parse()
+
+	if *accounts == "" || flag.NArg() != 0 {
+		log.Fatalf("Usage: tb-kafka-cdc -accounts <id>[,<id>...] [-brokers localhost:9092] [-format json|avro]")
+	}
+	for _, account := range strings.Split(*accounts, ",") {
+		id, err := types.DecStringToUint128(strings.TrimSpace(account))
+		if err != nil {
+			log.Fatalf("Error parsing account ID %q: %s", account, err)
+		}
+		config.Accounts = append(config.Accounts, id)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	producer, err := kgo.NewClient(
+		kgo.SeedBrokers(strings.Split(*brokers, ",")...),
+		kgo.TransactionalID(*transactionalID),
+	)
+	if err != nil {
+		log.Fatalf("Error creating Kafka producer: %s", err)
+	}
+	defer producer.Close()
+	// Fence any previous producer with the same transactional ID, which aborts its open
+	// transaction, before reading the checkpoint it may have been committing.
+	if _, _, err := producer.ProducerID(ctx); err != nil {
+		log.Fatalf("Error initializing Kafka producer: %s", err)
+	}
+
+	config.Resume, err = readCheckpoint(ctx, *brokers, *checkpointTopic, *transactionalID)
+	if err != nil {
+		log.Fatalf("Error reading checkpoint: %s", err)
+	}
+
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(*clusterID),
+		strings.Split(*addresses, ","),
+		1,
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	log.Printf("Publishing the changes of %d accounts to %s", len(config.Accounts), *brokers)
+	publisher := &publisher{producer: producer, topic: *checkpointTopic, key: []byte(*transactionalID)}
+	err = cdc.New(client, config).Run(ctx, publisher)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("Error publishing: %s", err)
+	}
+}
+
+// publisher produces every batch of records with its checkpoint in one transaction.
+type publisher struct {
+	producer *kgo.Client
+	topic    string
+	key      []byte
+}
+
+func (p *publisher) Publish(ctx context.Context, records []cdc.Record, checkpoint cdc.Checkpoint) error {
+	value, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	batch := make([]*kgo.Record, 0, len(records)+1)
+	for _, record := range records {
+		batch = append(batch, &kgo.Record{Topic: record.Topic, Key: record.Key, Value: record.Value})
+	}
+	batch = append(batch, &kgo.Record{Topic: p.topic, Key: p.key, Value: value})
+
+	if err := p.producer.BeginTransaction(); err != nil {
+		return err
+	}
+	if err := p.producer.ProduceSync(ctx, batch...).FirstErr(); err != nil {
+		// Abort with a fresh context, since ctx may be why producing failed.
+		abortCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if p.producer.AbortBufferedRecords(abortCtx) == nil {
+			p.producer.EndTransaction(abortCtx, kgo.TryAbort)
+		}
+		return err
+	}
+	return p.producer.EndTransaction(ctx, kgo.TryCommit)
+}
+
+// readCheckpoint returns the last checkpoint committed to topic under key, or an empty one if
+// there is none yet.
+func readCheckpoint(ctx context.Context, brokers string, topic string, key string) (cdc.Checkpoint, error) {
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(strings.Split(brokers, ",")...),
+		kgo.FetchIsolationLevel(kgo.ReadCommitted()),
+	)
+	if err != nil {
+		return cdc.Checkpoint{}, err
+	}
+	defer consumer.Close()
+
+	// Read every partition up to its last stable offset, which is past every committed
+	// checkpoint.
+	ends, err := kadm.NewClient(consumer).ListCommittedOffsets(ctx, topic)
+	if err != nil {
+		return cdc.Checkpoint{}, err
+	}
+	if err := ends.Error(); err != nil {
+		return cdc.Checkpoint{}, err
+	}
+	partitions := make(map[int32]kgo.Offset)
+	remaining := make(map[int32]int64)
+	ends.Each(func(end kadm.ListedOffset) {
+		if end.Offset > 0 {
+			partitions[end.Partition] = kgo.NewOffset().AtStart()
+			remaining[end.Partition] = end.Offset
+		}
+	})
+
+	var checkpoint cdc.Checkpoint
+	if len(partitions) == 0 {
+		return checkpoint, nil
+	}
+	consumer.AddConsumePartitions(map[string]map[int32]kgo.Offset{topic: partitions})
+
+	// The offsets of the transaction markers are never fetched, so a partition is only known to
+	// be read once its last record is, or once no more records arrive.
+	for len(remaining) > 0 {
+		pollCtx, cancel := context.WithTimeout(ctx, checkpointReadIdle)
+		fetches := consumer.PollFetches(pollCtx)
+		cancel()
+		if err := ctx.Err(); err != nil {
+			return cdc.Checkpoint{}, err
+		}
+		if fetches.Empty() {
+			break
+		}
+		for _, fetchErr := range fetches.Errors() {
+			if !errors.Is(fetchErr.Err, context.DeadlineExceeded) {
+				return cdc.Checkpoint{}, fetchErr.Err
+			}
+		}
+		var recordErr error
+		fetches.EachRecord(func(record *kgo.Record) {
+			end, ok := remaining[record.Partition]
+			if !ok || record.Offset >= end {
+				return
+			}
+			if record.Offset >= end-1 {
+				delete(remaining, record.Partition)
+			}
+			if string(record.Key) != key {
+				return
+			}
+			checkpoint = cdc.Checkpoint{}
+			if err := json.Unmarshal(record.Value, &checkpoint); err != nil && recordErr == nil {
+				recordErr = err
+			}
+		})
+		if recordErr != nil {
+			return cdc.Checkpoint{}, recordErr
+		}
+	}
+	return checkpoint, nil
+}
@@ -0,0 +1,19 @@
+module github.com/tigerbeetle/tigerbeetle-go/cmd/tb-nats-cdc
+
+go 1.23.0
+
+require (
+	github.com/nats-io/nats.go v1.38.0
+	github.com/tigerbeetle/tigerbeetle-go v0.0.0
+)
+
+require (
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/nats-io/nkeys v0.4.9 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+)
+
+replace github.com/tigerbeetle/tigerbeetle-go => ../../
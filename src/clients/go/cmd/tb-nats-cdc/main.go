@@ -0,0 +1,178 @@
+// Command tb-nats-cdc tails the transfers and balances of a set of accounts and publishes them to
+// NATS JetStream, as described by pkg/cdc: a message per transfer to -transfers-subject, and a
+// message per balance to -balances-subject. It is a lighter alternative to tb-kafka-cdc, for
+// deployments that run NATS already.
+//
+// JetStream has no transactions, so every message carries a Nats-Msg-Id header, the transfer ID,
+// or the account ID and the timestamp of the balance as "<account>/<timestamp>", and the stream
+// drops a message whose ID it has seen within its duplicate window. Every batch of messages is
+// acknowledged before the checkpoint after it is stored in the key-value bucket
+// -checkpoint-bucket under -name, from which a restart resumes, republishing at most the batch
+// that was in flight. Consumers must deduplicate by Nats-Msg-Id themselves if the capture may be
+// down for longer than the duplicate window of the stream.
+//
+// The stream of the subjects must exist. The checkpoint is updated only from the revision read,
+// so a second process with the same name fails rather than interleaving.
+//
+//	tb-nats-cdc -accounts <id>[,<id>...] [-server nats://localhost:4222] [-format json|avro]
+//	    [-transfers-subject tigerbeetle.transfers] [-balances-subject tigerbeetle.balances]
+//	    [-checkpoint-bucket tigerbeetle-cdc] [-name tb-nats-cdc]
+//	    [-poll-interval 1s] [-addresses 3000] [-cluster 0]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/cdc"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// publishWindow is the most messages awaiting their acknowledgement at once.
+const publishWindow = 1024
+
+func main() {
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	server := flag.String("server", nats.DefaultURL, "comma-separated NATS server URLs")
+	accounts := flag.String("accounts", "", "comma-separated decimal IDs of the accounts to tail")
+	checkpointBucket := flag.String("checkpoint-bucket", "tigerbeetle-cdc", "key-value bucket of the checkpoints")
+	name := flag.String("name", "tb-nats-cdc", "key of the checkpoint, unique per capture")
+	var config cdc.Config
+	flag.StringVar(&config.TransfersTopic, "transfers-subject", "tigerbeetle.transfers", "subject of the transfers")
+	flag.StringVar(&config.BalancesTopic, "balances-subject", "tigerbeetle.balances", "subject of the balances")
+	flag.Var(&config.Format, "format", "message format: json or avro")
+	flag.DurationVar(&config.PollInterval, "poll-interval", time.Second, "wait between polls once caught up")
+	flag.Parse()
+
+	if *accounts == "" || flag.NArg() != 0 {
+		log.Fatalf("Usage: tb-nats-cdc -accounts <id>[,<id>...] [-server nats://localhost:4222] [-format json|avro]")
+	}
+	for _, account := range strings.Split(*accounts, ",") {
+		id, err := types.DecStringToUint128(strings.TrimSpace(account))
+		if err != nil {
+			log.Fatalf("Error parsing account ID %q: %s", account, err)
+		}
+		config.Accounts = append(config.Accounts, id)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	connection, err := nats.Connect(*server, nats.Name(*name))
+	if err != nil {
+		log.Fatalf("Error connecting to NATS: %s", err)
+	}
+	defer connection.Close()
+	js, err := jetstream.New(connection)
+	if err != nil {
+		log.Fatalf("Error creating JetStream context: %s", err)
+	}
+
+	kv, err := js.KeyValue(ctx, *checkpointBucket)
+	if errors.Is(err, jetstream.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(ctx, jetstream.KeyValueConfig{Bucket: *checkpointBucket})
+	}
+	if err != nil {
+		log.Fatalf("Error opening checkpoint bucket: %s", err)
+	}
+	publisher := &publisher{js: js, kv: kv, key: *name}
+	if config.Resume, err = publisher.readCheckpoint(ctx); err != nil {
+		log.Fatalf("Error reading checkpoint: %s", err)
+	}
+
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(*clusterID),
+		strings.Split(*addresses, ","),
+		1,
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	log.Printf("Publishing the changes of %d accounts to %s", len(config.Accounts), *server)
+	err = cdc.New(client, config).Run(ctx, publisher)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("Error publishing: %s", err)
+	}
+}
+
+// publisher publishes every batch of messages, then stores the checkpoint after it.
+type publisher struct {
+	js  jetstream.JetStream
+	kv  jetstream.KeyValue
+	key string
+	// revision is the revision of the checkpoint last read or stored, zero if there is none.
+	revision uint64
+}
+
+// readCheckpoint returns the checkpoint stored, or an empty one if there is none yet.
+func (p *publisher) readCheckpoint(ctx context.Context) (cdc.Checkpoint, error) {
+	entry, err := p.kv.Get(ctx, p.key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return cdc.Checkpoint{}, nil
+	}
+	if err != nil {
+		return cdc.Checkpoint{}, err
+	}
+	var checkpoint cdc.Checkpoint
+	if err := json.Unmarshal(entry.Value(), &checkpoint); err != nil {
+		return cdc.Checkpoint{}, err
+	}
+	p.revision = entry.Revision()
+	return checkpoint, nil
+}
+
+func (p *publisher) Publish(ctx context.Context, records []cdc.Record, checkpoint cdc.Checkpoint) error {
+	for window := range slices.Chunk(records, publishWindow) {
+		futures := make([]jetstream.PubAckFuture, len(window))
+		for i, record := range window {
+			message := nats.NewMsg(record.Topic)
+			message.Data = record.Value
+			var err error
+			if futures[i], err = p.js.PublishMsgAsync(message, jetstream.WithMsgID(record.ID)); err != nil {
+				return err
+			}
+		}
+		// A message that is not acknowledged fails the batch, which is republished from the
+		// previous checkpoint, the messages that made it being dropped as duplicates.
+		for _, future := range futures {
+			select {
+			case <-future.Ok():
+			case err := <-future.Err():
+				return err
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	value, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	var revision uint64
+	if p.revision == 0 {
+		revision, err = p.kv.Create(ctx, p.key, value)
+	} else {
+		revision, err = p.kv.Update(ctx, p.key, value, p.revision)
+	}
+	if err != nil {
+		return err
+	}
+	p.revision = revision
+	return nil
+}
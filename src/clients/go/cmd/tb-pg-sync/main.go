@@ -0,0 +1,97 @@
+// Command tb-pg-sync mirrors the accounts, transfers and balances of a set of accounts into
+// Postgres tables, continuously, so that ledger data can be queried with ad-hoc SQL. It tails
+// the accounts as pkg/cdc does, and writes every batch of changes in one transaction together
+// with the checkpoint after it:
+//
+//   - tb_transfers holds every transfer of the accounts, keyed by ID, and tb_balances every
+//     balance of the accounts with the history flag, keyed by account ID and timestamp. Both are
+//     only ever inserted into, as transfers and balances don't change once created.
+//   - tb_accounts holds the accounts and the other accounts of their transfers, keyed by ID, with
+//     their balances as looked up after the last batch that touched them.
+//   - tb_sync_checkpoints holds the checkpoint of the mirror under -name, from which a restart
+//     resumes. Inserts are idempotent, so a batch written again after a crash changes nothing.
+//
+// 128-bit integers are NUMERIC(39, 0) columns, 64-bit ones NUMERIC(20, 0), except timestamps,
+// which are BIGINT nanoseconds. The tables are created if they don't exist.
+//
+//	tb-pg-sync -accounts <id>[,<id>...] -database <url> [-name tb-pg-sync] [-table-prefix tb_]
+//	    [-poll-interval 1s] [-addresses 3000] [-cluster 0]
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/cdc"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func main() {
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	accounts := flag.String("accounts", "", "comma-separated decimal IDs of the accounts to mirror")
+	database := flag.String("database", "", "Postgres connection URL")
+	name := flag.String("name", "tb-pg-sync", "name of the checkpoint of the mirror, unique per mirror")
+	tablePrefix := flag.String("table-prefix", "tb_", "prefix of the table names")
+	config := cdc.Config{TransfersTopic: transfersTopic, BalancesTopic: balancesTopic}
+	flag.DurationVar(&config.PollInterval, "poll-interval", time.Second, "wait between polls once caught up")
+	flag.Parse()
+
+	if *accounts == "" || *database == "" || flag.NArg() != 0 {
+		log.Fatalf("Usage: tb-pg-sync -accounts <id>[,<id>...] -database <url> [-name tb-pg-sync]")
+	}
+	for _, account := range strings.Split(*accounts, ",") {
+		id, err := types.DecStringToUint128(strings.TrimSpace(account))
+		if err != nil {
+			log.Fatalf("Error parsing account ID %q: %s", account, err)
+		}
+		config.Accounts = append(config.Accounts, id)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	db, err := sql.Open("pgx", *database)
+	if err != nil {
+		log.Fatalf("Error opening database: %s", err)
+	}
+	defer db.Close()
+
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(*clusterID),
+		strings.Split(*addresses, ","),
+		1,
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	mirror := &mirror{db: db, client: client, name: *name, prefix: *tablePrefix}
+	if err := mirror.createTables(ctx); err != nil {
+		log.Fatalf("Error creating tables: %s", err)
+	}
+	if config.Resume, err = mirror.readCheckpoint(ctx); err != nil {
+		log.Fatalf("Error reading checkpoint: %s", err)
+	}
+	// Mirror the accounts before their first change, which may be a while.
+	if err := mirror.updateAccounts(ctx, config.Accounts); err != nil {
+		log.Fatalf("Error mirroring accounts: %s", err)
+	}
+
+	log.Printf("Mirroring %d accounts to Postgres", len(config.Accounts))
+	err = cdc.New(client, config).Run(ctx, mirror)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("Error mirroring: %s", err)
+	}
+}
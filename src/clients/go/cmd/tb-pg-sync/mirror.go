@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/cdc"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// The topics of the records of the capture, which only tell transfers from balances here.
+const (
+	transfersTopic = "tigerbeetle.transfers"
+	balancesTopic  = "tigerbeetle.balances"
+)
+
+var (
+	accountColumns = []string{
+		"id", "debits_pending", "debits_posted", "credits_pending", "credits_posted",
+		"user_data_128", "user_data_64", "user_data_32", "ledger", "code", "flags", "timestamp",
+	}
+	transferColumns = []string{
+		"id", "debit_account_id", "credit_account_id", "amount", "pending_id",
+		"user_data_128", "user_data_64", "user_data_32", "timeout", "ledger", "code", "flags", "timestamp",
+	}
+	balanceColumns = []string{
+		"account_id", "debits_pending", "debits_posted", "credits_pending", "credits_posted", "timestamp",
+	}
+)
+
+// mirror writes the changes of a capture to Postgres, as a cdc.Publisher.
+type mirror struct {
+	db     *sql.DB
+	client tigerbeetle_go.Client
+	name   string
+	prefix string
+}
+
+func (m *mirror) createTables(ctx context.Context) error {
+	for _, statement := range []string{
+		`CREATE TABLE IF NOT EXISTS ` + m.prefix + `accounts (
+			id NUMERIC(39, 0) PRIMARY KEY,
+			debits_pending NUMERIC(39, 0) NOT NULL,
+			debits_posted NUMERIC(39, 0) NOT NULL,
+			credits_pending NUMERIC(39, 0) NOT NULL,
+			credits_posted NUMERIC(39, 0) NOT NULL,
+			user_data_128 NUMERIC(39, 0) NOT NULL,
+			user_data_64 NUMERIC(20, 0) NOT NULL,
+			user_data_32 BIGINT NOT NULL,
+			ledger BIGINT NOT NULL,
+			code INTEGER NOT NULL,
+			flags INTEGER NOT NULL,
+			timestamp BIGINT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS ` + m.prefix + `transfers (
+			id NUMERIC(39, 0) PRIMARY KEY,
+			debit_account_id NUMERIC(39, 0) NOT NULL,
+			credit_account_id NUMERIC(39, 0) NOT NULL,
+			amount NUMERIC(39, 0) NOT NULL,
+			pending_id NUMERIC(39, 0) NOT NULL,
+			user_data_128 NUMERIC(39, 0) NOT NULL,
+			user_data_64 NUMERIC(20, 0) NOT NULL,
+			user_data_32 BIGINT NOT NULL,
+			timeout BIGINT NOT NULL,
+			ledger BIGINT NOT NULL,
+			code INTEGER NOT NULL,
+			flags INTEGER NOT NULL,
+			timestamp BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS ` + m.prefix + `transfers_debit_account_id
+			ON ` + m.prefix + `transfers (debit_account_id, timestamp)`,
+		`CREATE INDEX IF NOT EXISTS ` + m.prefix + `transfers_credit_account_id
+			ON ` + m.prefix + `transfers (credit_account_id, timestamp)`,
+		`CREATE TABLE IF NOT EXISTS ` + m.prefix + `balances (
+			account_id NUMERIC(39, 0) NOT NULL,
+			debits_pending NUMERIC(39, 0) NOT NULL,
+			debits_posted NUMERIC(39, 0) NOT NULL,
+			credits_pending NUMERIC(39, 0) NOT NULL,
+			credits_posted NUMERIC(39, 0) NOT NULL,
+			timestamp BIGINT NOT NULL,
+			PRIMARY KEY (account_id, timestamp)
+		)`,
+		`CREATE TABLE IF NOT EXISTS ` + m.prefix + `sync_checkpoints (
+			name TEXT PRIMARY KEY,
+			checkpoint JSONB NOT NULL
+		)`,
+	} {
+		if _, err := m.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCheckpoint returns the checkpoint of the mirror, or an empty one if there is none yet.
+func (m *mirror) readCheckpoint(ctx context.Context) (cdc.Checkpoint, error) {
+	var data []byte
+	err := m.db.QueryRowContext(ctx,
+		"SELECT checkpoint FROM "+m.prefix+"sync_checkpoints WHERE name = $1", m.name,
+	).Scan(&data)
+	if err == sql.ErrNoRows {
+		return cdc.Checkpoint{}, nil
+	}
+	if err != nil {
+		return cdc.Checkpoint{}, err
+	}
+	var checkpoint cdc.Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return cdc.Checkpoint{}, fmt.Errorf("checkpoint %q: %w", m.name, err)
+	}
+	return checkpoint, nil
+}
+
+// Publish writes the transfers and balances of records, the accounts they touch, and checkpoint,
+// in one transaction.
+func (m *mirror) Publish(ctx context.Context, records []cdc.Record, checkpoint cdc.Checkpoint) error {
+	var transfers []types.Transfer
+	var balances []cdc.BalanceEvent
+	var accountIDs []types.Uint128
+	touched := make(map[types.Uint128]bool)
+	touch := func(id types.Uint128) {
+		if !touched[id] {
+			touched[id] = true
+			accountIDs = append(accountIDs, id)
+		}
+	}
+	for _, record := range records {
+		switch record.Topic {
+		case transfersTopic:
+			var transfer types.Transfer
+			if err := json.Unmarshal(record.Value, &transfer); err != nil {
+				return err
+			}
+			transfers = append(transfers, transfer)
+			touch(transfer.DebitAccountID)
+			touch(transfer.CreditAccountID)
+		case balancesTopic:
+			var balance cdc.BalanceEvent
+			if err := json.Unmarshal(record.Value, &balance); err != nil {
+				return err
+			}
+			balances = append(balances, balance)
+			touch(balance.AccountID)
+		}
+	}
+	accounts, err := m.lookupAccounts(accountIDs)
+	if err != nil {
+		return err
+	}
+	state, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = exec(ctx, tx, m.insert("transfers", transferColumns, "ON CONFLICT (id) DO NOTHING"),
+		transfers, transferRow)
+	if err != nil {
+		return err
+	}
+	err = exec(ctx, tx, m.insert("balances", balanceColumns, "ON CONFLICT (account_id, timestamp) DO NOTHING"),
+		balances, balanceRow)
+	if err != nil {
+		return err
+	}
+	if err := exec(ctx, tx, m.upsertAccount(), accounts, accountRow); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		m.insert("sync_checkpoints", []string{"name", "checkpoint"},
+			"ON CONFLICT (name) DO UPDATE SET checkpoint = excluded.checkpoint"),
+		m.name, string(state),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateAccounts writes the accounts of accountIDs that exist, as they are now.
+func (m *mirror) updateAccounts(ctx context.Context, accountIDs []types.Uint128) error {
+	accounts, err := m.lookupAccounts(accountIDs)
+	if err != nil {
+		return err
+	}
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := exec(ctx, tx, m.upsertAccount(), accounts, accountRow); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *mirror) lookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	var accounts []types.Account
+	for chunk := range slices.Chunk(accountIDs, types.MaxBatchSize(types.OperationLookupAccounts)) {
+		found, err := m.client.LookupAccounts(chunk)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, found...)
+	}
+	return accounts, nil
+}
+
+// insert returns the statement inserting a row into table, followed by conflict.
+func (m *mirror) insert(table string, columns []string, conflict string) string {
+	placeholders := make([]string, len(columns))
+	for i := range placeholders {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+	}
+	return "INSERT INTO " + m.prefix + table + " (" + strings.Join(columns, ", ") + ") VALUES (" +
+		strings.Join(placeholders, ", ") + ") " + conflict
+}
+
+// upsertAccount returns the statement inserting an account, or updating its balances, the only
+// fields of an account that change.
+func (m *mirror) upsertAccount() string {
+	return m.insert("accounts", accountColumns, "ON CONFLICT (id) DO UPDATE SET "+
+		"debits_pending = excluded.debits_pending, debits_posted = excluded.debits_posted, "+
+		"credits_pending = excluded.credits_pending, credits_posted = excluded.credits_posted")
+}
+
+// exec runs query on the row of every value.
+func exec[T any](ctx context.Context, tx *sql.Tx, query string, values []T, row func(T) []any) error {
+	if len(values) == 0 {
+		return nil
+	}
+	statement, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer statement.Close()
+	for _, value := range values {
+		if _, err := statement.ExecContext(ctx, row(value)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// numeric returns value for a NUMERIC(20, 0) column, since database/sql can't pass a uint64 with
+// its high bit set.
+func numeric(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
+
+func accountRow(account types.Account) []any {
+	return []any{
+		account.ID, account.DebitsPending, account.DebitsPosted, account.CreditsPending,
+		account.CreditsPosted, account.UserData128, numeric(account.UserData64),
+		int64(account.UserData32), int64(account.Ledger), int64(account.Code), int64(account.Flags),
+		int64(account.Timestamp),
+	}
+}
+
+func transferRow(transfer types.Transfer) []any {
+	return []any{
+		transfer.ID, transfer.DebitAccountID, transfer.CreditAccountID, transfer.Amount,
+		transfer.PendingID, transfer.UserData128, numeric(transfer.UserData64),
+		int64(transfer.UserData32), int64(transfer.Timeout), int64(transfer.Ledger),
+		int64(transfer.Code), int64(transfer.Flags), int64(transfer.Timestamp),
+	}
+}
+
+func balanceRow(balance cdc.BalanceEvent) []any {
+	return []any{
+		balance.AccountID, balance.DebitsPending, balance.DebitsPosted, balance.CreditsPending,
+		balance.CreditsPosted, int64(balance.Timestamp),
+	}
+}
@@ -0,0 +1,143 @@
+// Command tb-verify checks the trial balance of a ledger, as described by pkg/trialbalance, and
+// reports the totals and every account whose balance exceeds the limit set by its flags.
+//
+// This cluster version has no query_accounts operation to list the accounts of a ledger, so
+// the IDs of the accounts to check are read one per line, in decimal or as "0x"-prefixed hex,
+// from the given file or from stdin. Accounts of other ledgers are skipped, and IDs that don't
+// exist are ignored. The exit status is 1 if the ledger fails a check.
+//
+//	tb-verify -ledger <ledger> [-addresses 3000] [-cluster 0] [-format table|json|csv] [<ids>]
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/output"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/trialbalance"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func main() {
+	addresses := flag.String("addresses", "3000", "comma-separated replica addresses")
+	clusterID := flag.Uint64("cluster", 0, "cluster ID")
+	ledger := flag.Uint("ledger", 0, "ledger to check")
+	var format output.Format
+	flag.Var(&format, "format", "output format: table, json or csv")
+	flag.Parse()
+
+	if *ledger == 0 || *ledger > 1<<32-1 || flag.NArg() > 1 {
+		log.Fatalf("Usage: tb-verify -ledger <ledger> [-format table|json|csv] [<ids>]")
+	}
+
+	input := io.Reader(os.Stdin)
+	if flag.NArg() == 1 && flag.Arg(0) != "-" {
+		file, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatalf("Error opening IDs: %s", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(*clusterID),
+		strings.Split(*addresses, ","),
+		1,
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	var idsErr error
+	ids := readIDs(input, &idsErr)
+	report, err := trialbalance.Verify(uint32(*ledger), client.LookupAccountsStream(ids))
+	if err != nil {
+		log.Fatalf("Error looking up accounts: %s", err)
+	}
+	if idsErr != nil {
+		log.Fatalf("Error reading IDs: %s", idsErr)
+	}
+
+	writer, err := output.NewWriter(os.Stdout, format, "check", "account_id", "debits", "credits", "status")
+	if err != nil {
+		log.Fatalf("Error writing output: %s", err)
+	}
+	status := func(ok bool) string {
+		if ok {
+			return "OK"
+		}
+		return "FAIL"
+	}
+	rows := [][]string{
+		{
+			string(trialbalance.CheckPostedBalance),
+			"",
+			report.DebitsPosted.String(),
+			report.CreditsPosted.String(),
+			status(report.PostedBalanced()),
+		},
+		{
+			string(trialbalance.CheckPendingBalance),
+			"",
+			report.DebitsPending.String(),
+			report.CreditsPending.String(),
+			status(report.PendingBalanced()),
+		},
+	}
+	for _, violation := range report.Violations {
+		rows = append(rows, []string{
+			string(violation.Check),
+			violation.AccountID.String(),
+			violation.Debits.String(),
+			violation.Credits.String(),
+			status(false),
+		})
+	}
+	for _, row := range rows {
+		if err := writer.Row(row...); err != nil {
+			log.Fatalf("Error writing output: %s", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		log.Fatalf("Error writing output: %s", err)
+	}
+
+	log.Printf("Checked %d accounts of ledger %d, skipped %d of other ledgers: %d violations",
+		report.Accounts, report.Ledger, report.Skipped, len(report.Violations))
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
+
+// readIDs yields the IDs read from r, one per line, skipping blank lines. It stops at the first
+// line that isn't an ID, setting *err.
+func readIDs(r io.Reader, err *error) iter.Seq[types.Uint128] {
+	return func(yield func(types.Uint128) bool) {
+		scanner := bufio.NewScanner(r)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var id types.Uint128
+			if parseErr := id.UnmarshalJSON([]byte(strconv.Quote(text))); parseErr != nil {
+				*err = fmt.Errorf("line %d: %w", line, parseErr)
+				return
+			}
+			if !yield(id) {
+				return
+			}
+		}
+		*err = scanner.Err()
+	}
+}
@@ -0,0 +1,60 @@
+package tigerbeetle_go
+
+import (
+	"context"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+)
+
+// ConcurrencyMode decides what a request does when concurrencyMax requests are in flight.
+type ConcurrencyMode uint8
+
+const (
+	// ConcurrencyFailFast fails the request with ErrConcurrencyExceeded.
+	ConcurrencyFailFast ConcurrencyMode = iota
+	// ConcurrencyBlock waits for one of the requests in flight to complete.
+	ConcurrencyBlock
+)
+
+// WithConcurrencyMode sets what requests do once concurrencyMax requests are in flight, instead
+// of failing fast. It saves callers from limiting their own concurrency with a semaphore.
+// With WithTransport and a concurrencyMax of zero, requests are not limited.
+func WithConcurrencyMode(mode ConcurrencyMode) ClientOption {
+	return func(options *clientOptions) {
+		options.concurrencyMode = mode
+	}
+}
+
+// WithConcurrencyContext bounds the wait of ConcurrencyBlock: once ctx is done, requests
+// waiting for a free slot fail with ctx.Err(). Typically ctx is cancelled on shutdown.
+func WithConcurrencyContext(ctx context.Context) ClientOption {
+	return func(options *clientOptions) {
+		options.concurrencyCtx = ctx
+	}
+}
+
+func (c *c_client) acquireSlot(wait bool) error {
+	if !wait {
+		select {
+		case c.slots <- struct{}{}:
+			return nil
+		default:
+			return errors.ErrConcurrencyExceeded{}
+		}
+	}
+
+	var done <-chan struct{}
+	if c.slotsCtx != nil {
+		done = c.slotsCtx.Done()
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	case <-done:
+		return c.slotsCtx.Err()
+	}
+}
+
+func (c *c_client) releaseSlot() {
+	<-c.slots
+}
@@ -0,0 +1,309 @@
+package tigerbeetle_go
+
+import (
+	e "errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// ConnectionState is the state of a client's session with the cluster, as returned by
+// Client.State.
+//
+// tb_client reconnects and resubmits requests on its own without reporting it, so the state is
+// derived from the progress of the client's requests: the client is connected while replies
+// arrive, and reconnecting, then finding the cluster unreachable, while a request has waited
+// past ConnectionMonitor.ReconnectingAfter and UnreachableAfter without any reply. A client
+// with no requests in flight keeps the state its last request left it in.
+type ConnectionState uint8
+
+const (
+	// ConnectionConnecting is the state of a client that has yet to receive a reply.
+	ConnectionConnecting ConnectionState = iota
+	ConnectionConnected
+	ConnectionReconnecting
+	ConnectionUnreachable
+	// ConnectionEvicted is the state of a client whose session was evicted by the cluster, as
+	// when more clients connect than it has sessions for, and whose requests then fail with
+	// errors.ErrSessionEvicted. tb_client logs the eviction and then panics, so the state is
+	// mostly seen by OnStateChange, right before the process exits. The log does not say which
+	// client was evicted, so every client of the process passes through the state.
+	ConnectionEvicted
+	ConnectionClosed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case ConnectionConnecting:
+		return "connecting"
+	case ConnectionConnected:
+		return "connected"
+	case ConnectionReconnecting:
+		return "reconnecting"
+	case ConnectionUnreachable:
+		return "cluster-unreachable"
+	case ConnectionEvicted:
+		return "session-evicted"
+	case ConnectionClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// Default thresholds of ConnectionMonitor.
+const (
+	connectionReconnectingAfterDefault = 5 * time.Second
+	connectionUnreachableAfterDefault  = 30 * time.Second
+)
+
+// ConnectionMonitor configures how a client derives its ConnectionState and whom it tells.
+type ConnectionMonitor struct {
+	// ReconnectingAfter is how long a request may wait for a reply before the client is
+	// considered to be reconnecting. Defaults to 5s.
+	ReconnectingAfter time.Duration
+	// UnreachableAfter is how long a request may wait for a reply before the cluster is
+	// considered unreachable. Defaults to 30s.
+	UnreachableAfter time.Duration
+	// OnStateChange is called on every change of state, in order. It must not block, nor submit
+	// requests through the client.
+	OnStateChange func(change ConnectionStateChange)
+}
+
+// ConnectionStateChange describes a change of ConnectionState, as passed to
+// ConnectionMonitor.OnStateChange.
+type ConnectionStateChange struct {
+	From ConnectionState
+	To   ConnectionState
+	At   time.Time
+	// Err is the errors.ErrSessionEvicted with the reason of the eviction when To is
+	// ConnectionEvicted, and nil otherwise.
+	Err error
+}
+
+// WithConnectionMonitor makes the client derive its ConnectionState according to monitor,
+// rather than with the default thresholds, and call monitor.OnStateChange as it changes.
+//
+// Evictions are read from the logs of tb_client, which then go to the logger of WithLogger, or
+// to stderr, and reported with their reason in ConnectionStateChange.Err so that the application
+// can decide to rebuild the client.
+func WithConnectionMonitor(monitor ConnectionMonitor) ClientOption {
+	return func(options *clientOptions) {
+		options.connectionMonitor = &monitor
+	}
+}
+
+// connectionTransport tracks the ConnectionState of a client from the requests submitted
+// through it.
+type connectionTransport struct {
+	Transport
+	monitor ConnectionMonitor
+
+	// notifying serializes the calls to OnStateChange, which are made outside of mutex so that
+	// they may call State.
+	notifying sync.Mutex
+
+	mutex   sync.Mutex
+	state   ConnectionState
+	evicted errors.ErrSessionEvicted
+	// inflight counts the requests waiting for a reply, and progress is when the last reply
+	// arrived or, if later, when the oldest of them was submitted.
+	inflight int
+	progress time.Time
+	timer    *time.Timer
+}
+
+func newConnectionTransport(transport Transport, monitor ConnectionMonitor) *connectionTransport {
+	if monitor.ReconnectingAfter <= 0 {
+		monitor.ReconnectingAfter = connectionReconnectingAfterDefault
+	}
+	if monitor.UnreachableAfter <= 0 {
+		monitor.UnreachableAfter = connectionUnreachableAfterDefault
+	}
+	t := &connectionTransport{Transport: transport, monitor: monitor}
+	t.timer = time.AfterFunc(time.Hour, t.check)
+	t.timer.Stop()
+	return t
+}
+
+func (t *connectionTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	if err := t.started(); err != nil {
+		return 0, err
+	}
+	size, err := t.Transport.Submit(op, events, reply)
+	t.completed(err)
+	return size, err
+}
+
+// SubmitAsync is Submit through a transport that completes requests itself, as tb_client does.
+func (t *connectionTransport) SubmitAsync(
+	op types.Operation,
+	events []byte,
+	reply []byte,
+	done func(wrote int, err error),
+) {
+	if err := t.started(); err != nil {
+		done(0, err)
+		return
+	}
+	t.Transport.(asyncTransport).SubmitAsync(op, events, reply, func(size int, err error) {
+		t.completed(err)
+		done(size, err)
+	})
+}
+
+// started counts a request submitted, unless the client was evicted.
+func (t *connectionTransport) started() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.state == ConnectionEvicted {
+		return t.evicted
+	}
+	if t.inflight == 0 {
+		t.progress = time.Now()
+		t.timer.Reset(t.monitor.ReconnectingAfter)
+	}
+	t.inflight++
+	return nil
+}
+
+// completed counts a request completed with err.
+func (t *connectionTransport) completed(err error) {
+	// Requests that never reached the cluster say nothing about the connection.
+	replied := !e.Is(err, errors.ErrClientClosed{}) && !e.Is(err, errors.ErrConcurrencyExceeded{})
+
+	t.notifying.Lock()
+	defer t.notifying.Unlock()
+	t.mutex.Lock()
+	t.inflight--
+	from := t.state
+	if replied {
+		t.progress = time.Now()
+		if t.state != ConnectionEvicted && t.state != ConnectionClosed {
+			t.state = ConnectionConnected
+		}
+	}
+	if t.inflight == 0 {
+		t.timer.Stop()
+	} else if replied {
+		t.timer.Reset(t.monitor.ReconnectingAfter)
+	}
+	to := t.state
+	t.mutex.Unlock()
+	t.notify(from, to, nil)
+}
+
+// check moves the client to reconnecting or unreachable if a request has waited too long.
+func (t *connectionTransport) check() {
+	t.notifying.Lock()
+	defer t.notifying.Unlock()
+	t.mutex.Lock()
+	from := t.state
+	if t.inflight > 0 && t.state != ConnectionEvicted && t.state != ConnectionClosed {
+		waited := time.Since(t.progress)
+		if waited >= t.monitor.UnreachableAfter {
+			t.state = ConnectionUnreachable
+		} else if waited >= t.monitor.ReconnectingAfter {
+			t.state = ConnectionReconnecting
+			t.timer.Reset(t.monitor.UnreachableAfter - waited)
+		} else {
+			t.timer.Reset(t.monitor.ReconnectingAfter - waited)
+		}
+	}
+	to := t.state
+	t.mutex.Unlock()
+	t.notify(from, to, nil)
+}
+
+// evict moves the client to ConnectionEvicted, unless it is closed, so that its requests fail
+// with evicted.
+func (t *connectionTransport) evict(evicted errors.ErrSessionEvicted) {
+	t.notifying.Lock()
+	defer t.notifying.Unlock()
+	t.mutex.Lock()
+	from := t.state
+	if t.state != ConnectionClosed && t.state != ConnectionEvicted {
+		t.state = ConnectionEvicted
+		t.evicted = evicted
+	}
+	to := t.state
+	t.mutex.Unlock()
+	t.notify(from, to, evicted)
+}
+
+// close moves the client to ConnectionClosed.
+func (t *connectionTransport) close() {
+	t.notifying.Lock()
+	defer t.notifying.Unlock()
+	t.mutex.Lock()
+	from := t.state
+	t.state = ConnectionClosed
+	t.mutex.Unlock()
+	t.notify(from, ConnectionClosed, nil)
+}
+
+// notify calls OnStateChange if the state changed, with notifying held. err is passed on for
+// changes to ConnectionEvicted.
+func (t *connectionTransport) notify(from ConnectionState, to ConnectionState, err error) {
+	if from == to || t.monitor.OnStateChange == nil {
+		return
+	}
+	change := ConnectionStateChange{From: from, To: to, At: time.Now()}
+	if to == ConnectionEvicted {
+		change.Err = err
+	}
+	t.monitor.OnStateChange(change)
+}
+
+func (t *connectionTransport) State() ConnectionState {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.state
+}
+
+func (t *connectionTransport) Close() {
+	nativeConnections.Delete(t)
+	t.Transport.Close()
+	t.timer.Stop()
+	t.close()
+}
+
+// nativeConnections holds the connectionTransport of every client on tb_client, to be told of
+// evictions, which tb_client logs for the whole process.
+var nativeConnections sync.Map
+
+// noteNativeLog moves every client on tb_client to ConnectionEvicted if message logs an
+// eviction.
+func noteNativeLog(level int, message string) {
+	if level != nativeLogErr {
+		return
+	}
+	evicted, ok := parseEviction(message)
+	if !ok {
+		return
+	}
+	nativeConnections.Range(func(key, _ any) bool {
+		key.(*connectionTransport).evict(evicted)
+		return true
+	})
+}
+
+// parseEviction reads the reason of an eviction from the log of tb_client, which is of the form
+// "<client>: session evicted: reason=<reason> (cluster_release=<release>)".
+func parseEviction(message string) (errors.ErrSessionEvicted, bool) {
+	_, details, ok := strings.Cut(message, "session evicted: ")
+	if !ok {
+		return errors.ErrSessionEvicted{}, false
+	}
+	reason := "unknown"
+	if _, after, ok := strings.Cut(details, "reason="); ok {
+		reason, _, _ = strings.Cut(after, " ")
+	}
+	if _, after, ok := strings.Cut(details, "cluster_release="); ok {
+		release, _, _ := strings.Cut(after, ")")
+		reason += ", cluster release " + release
+	}
+	return errors.ErrSessionEvicted{Reason: reason}, true
+}
@@ -0,0 +1,144 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// The single-item convenience methods are implemented on top of the batch methods of any
+// Client, so that clients wrapping another Client apply their batch behavior to them too.
+
+func createAccount(client Client, account types.Account) error {
+	results, err := client.CreateAccounts([]types.Account{account})
+	if err != nil {
+		return err
+	}
+
+	// Only failed events have a result, so an empty result means success.
+	if len(results) > 0 {
+		return errors.ErrCreateAccount{Result: results[0].Result}
+	}
+	return nil
+}
+
+func createTransfer(client Client, transfer types.Transfer) error {
+	results, err := client.CreateTransfers([]types.Transfer{transfer})
+	if err != nil {
+		return err
+	}
+
+	// Only failed events have a result, so an empty result means success.
+	if len(results) > 0 {
+		return errors.ErrCreateTransfer{Result: results[0].Result}
+	}
+	return nil
+}
+
+func lookupAccount(client Client, accountID types.Uint128) (types.Account, bool, error) {
+	results, err := client.LookupAccounts([]types.Uint128{accountID})
+	if err != nil {
+		return types.Account{}, false, err
+	}
+
+	if len(results) == 0 {
+		return types.Account{}, false, nil
+	}
+	return results[0], true, nil
+}
+
+// ping looks up an account that cannot exist, since IDs are never zero, which goes to the
+// cluster like any other request unlike Nop, until the reply or until ctx is done.
+func ping(ctx context.Context, client Client) (time.Duration, error) {
+	start := time.Now()
+	replied := make(chan error, 1)
+	go func() {
+		_, err := client.LookupAccounts([]types.Uint128{{}})
+		replied <- err
+	}()
+
+	select {
+	case err := <-replied:
+		return time.Since(start), err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// LookupTransferState looks up a transfer and, if it is pending, the transfer that posted or
+// voided it, returning its status as TransferState does. The bool is false if the transfer
+// doesn't exist.
+//
+// The post or void is searched for among the later debits of the debit account, a page at a
+// time. With a timeout, only those before the pending transfer expired are searched, but without
+// one every later debit of the account may be, and the search fails with ctx.Err() once ctx is
+// done.
+func LookupTransferState(
+	ctx context.Context,
+	client Client,
+	transferID types.Uint128,
+) (types.TransferStatus, bool, error) {
+	transfers, err := client.LookupTransfers([]types.Uint128{transferID})
+	if err != nil || len(transfers) == 0 {
+		return 0, false, err
+	}
+	transfer := transfers[0]
+	if !transfer.TransferFlags().Pending {
+		return types.TransferStatusPosted, true, nil
+	}
+
+	// Posts and voids share the debit account of the pending transfer, and come after it, and
+	// before it expires.
+	limit := types.MaxBatchSize(types.OperationGetAccountTransfers)
+	filter := types.AccountFilter{
+		AccountID:    transfer.DebitAccountID,
+		TimestampMin: transfer.Timestamp + 1,
+		Limit:        uint32(limit),
+		Flags:        types.AccountFilterFlags{Debits: true}.ToUint32(),
+	}
+	expiresAt := transfer.ExpiresAt()
+	if expiresAt != 0 {
+		filter.TimestampMax = expiresAt - 1
+	}
+
+	var related []types.Transfer
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, false, err
+		}
+		page, err := client.GetAccountTransfers(filter)
+		if err != nil {
+			return 0, false, err
+		}
+		for _, other := range page {
+			if other.PendingID == transferID {
+				return types.TransferState(transfer, []types.Transfer{other}), true, nil
+			}
+		}
+		if len(page) > 0 {
+			// The latest transfer tells how far the cluster clock has moved past the timeout.
+			related = page[len(page)-1:]
+			filter.TimestampMin = page[len(page)-1].Timestamp + 1
+		}
+		if len(page) < limit {
+			break
+		}
+	}
+
+	if expiresAt != 0 {
+		// Any debit since the timeout tells that the cluster clock has moved past it.
+		later, err := client.GetAccountTransfers(types.AccountFilter{
+			AccountID:    transfer.DebitAccountID,
+			TimestampMin: expiresAt,
+			Limit:        1,
+			Flags:        types.AccountFilterFlags{Debits: true}.ToUint32(),
+		})
+		if err != nil {
+			return 0, false, err
+		}
+		related = append(related, later...)
+	}
+	return types.TransferState(transfer, related), true, nil
+}
@@ -0,0 +1,162 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	e "errors"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+)
+
+const (
+	// dnsDiscoveryIntervalDefault is how often addresses are re-resolved.
+	dnsDiscoveryIntervalDefault = 30 * time.Second
+	// dnsResolveTimeout bounds every resolution of the addresses.
+	dnsResolveTimeout = 10 * time.Second
+)
+
+// Resolver looks up DNS records, as net.Resolver does.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// DNSDiscovery configures how a client finds the addresses of the replicas with DNS.
+//
+// tb_client only connects to IP addresses, and resolves none at all, so the addresses are
+// resolved by the client. The order of the addresses must be that of the replicas, so every
+// name must resolve to a single replica: with more than one IP address, the first is taken.
+type DNSDiscovery struct {
+	// SRV is the name of the SRV records of the replicas, such as
+	// "_tigerbeetle._tcp.tigerbeetle.default.svc.cluster.local" for the headless service of a
+	// Kubernetes StatefulSet. The targets are taken in order of priority and then of name, which
+	// must be the order of the replicas, and the addresses passed to NewClient are not used.
+	// When empty, the host names among the addresses passed to NewClient are resolved instead.
+	SRV string
+	// Interval is how often the addresses are resolved again. Defaults to 30s.
+	Interval time.Duration
+	// Resolver defaults to net.DefaultResolver.
+	Resolver Resolver
+	// OnChange is called with the addresses whenever the client switches to new ones, or with
+	// the error of a resolution that failed, in which case the client keeps its addresses.
+	OnChange func(addresses []string, err error)
+}
+
+// WithDNSDiscovery makes the client resolve the addresses of the replicas as described by
+// discovery when it is created, and then every discovery.Interval, switching to the new
+// addresses with UpdateAddresses whenever they change. Redeployments that move the replicas to
+// new IP addresses then don't require the client to be recreated.
+//
+// The addresses also change on a call to UpdateAddresses, until the next resolution. Clients
+// created with WithTransport ignore discovery.
+func WithDNSDiscovery(discovery DNSDiscovery) ClientOption {
+	return func(options *clientOptions) {
+		options.dnsDiscovery = &discovery
+	}
+}
+
+func (d DNSDiscovery) withDefaults() DNSDiscovery {
+	if d.Interval <= 0 {
+		d.Interval = dnsDiscoveryIntervalDefault
+	}
+	if d.Resolver == nil {
+		d.Resolver = net.DefaultResolver
+	}
+	return d
+}
+
+// resolve returns the IP addresses of the replicas, with the port of each address kept as is.
+func (d DNSDiscovery) resolve(ctx context.Context, addresses []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dnsResolveTimeout)
+	defer cancel()
+
+	if d.SRV != "" {
+		_, records, err := d.Resolver.LookupSRV(ctx, "", "", d.SRV)
+		if err != nil {
+			return nil, errors.ErrAddressResolution{Address: d.SRV, Err: err}
+		}
+		slices.SortStableFunc(records, func(a, b *net.SRV) int {
+			if a.Priority != b.Priority {
+				return int(a.Priority) - int(b.Priority)
+			}
+			return strings.Compare(a.Target, b.Target)
+		})
+		addresses = make([]string, 0, len(records))
+		for _, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			addresses = append(addresses, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+		}
+	}
+
+	resolved := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			// A port alone, or a host without one.
+			host, port = address, ""
+			if _, err := strconv.Atoi(address); err == nil {
+				resolved = append(resolved, address)
+				continue
+			}
+		}
+		if net.ParseIP(host) == nil {
+			ips, err := d.Resolver.LookupHost(ctx, host)
+			if err != nil {
+				return nil, errors.ErrAddressResolution{Address: address, Err: err}
+			}
+			if len(ips) == 0 {
+				return nil, errors.ErrAddressResolution{Address: address, Err: errors.ErrInvalidAddress{}}
+			}
+			host = ips[0]
+		}
+		if port == "" {
+			resolved = append(resolved, host)
+		} else {
+			resolved = append(resolved, net.JoinHostPort(host, port))
+		}
+	}
+	return normalizeAddresses(resolved)
+}
+
+// watch resolves addresses every Interval until done, and passes them to update whenever they
+// differ from current.
+func (d DNSDiscovery) watch(
+	addresses []string,
+	current []string,
+	update func(addresses []string) error,
+	done <-chan struct{},
+) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		resolved, err := d.resolve(context.Background(), addresses)
+		if err == nil && slices.Equal(resolved, current) {
+			continue
+		}
+		if err == nil {
+			err = update(resolved)
+		}
+		if e.Is(err, errors.ErrClientClosed{}) {
+			return
+		}
+		if err == nil {
+			current = resolved
+		} else {
+			resolved = nil
+		}
+		if d.OnChange != nil {
+			d.OnChange(resolved, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package tigerbeetle_go
+
+import (
+	"unsafe"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// WithDryRun makes the client answer every request locally instead of submitting it, for CI and
+// sandboxes without a cluster. It replaces the transport, so nothing touches the network.
+//
+// Creating accounts and transfers replies with the results that the cluster would reply with
+// for the errors that can be detected without its state: the invariants checked by Validate,
+// events of the batch with the ID of an earlier one, and linked chains that fail or are left
+// open. The other events are answered as created, though nothing is: the batches don't see
+// each other, and lookups and queries find nothing.
+func WithDryRun() ClientOption {
+	return func(options *clientOptions) {
+		options.transport = NewInMemoryTransport(dryRun)
+	}
+}
+
+func dryRun(op types.Operation, events []byte) ([]byte, error) {
+	switch op {
+	case types.OperationCreateAccounts:
+		accounts := unsafe.Slice((*types.Account)(unsafe.Pointer(unsafe.SliceData(events))), len(events)/types.AccountSize)
+		results := dryRunBatch(
+			accounts,
+			func(account types.Account) types.Uint128 { return account.ID },
+			func(account types.Account) bool { return account.AccountFlags().Linked },
+			func(account types.Account, existing *types.Account) uint32 {
+				if result := account.Validate(); result != types.AccountOK {
+					return uint32(result)
+				}
+				if existing != nil {
+					return uint32(account.Exists(*existing))
+				}
+				return 0
+			},
+		)
+		return encodeDryRunResults(results, func(index uint32, code uint32) types.AccountEventResult {
+			return types.AccountEventResult{Index: index, Result: types.CreateAccountResult(code)}
+		}), nil
+	case types.OperationCreateTransfers:
+		transfers := unsafe.Slice((*types.Transfer)(unsafe.Pointer(unsafe.SliceData(events))), len(events)/types.TransferSize)
+		results := dryRunBatch(
+			transfers,
+			func(transfer types.Transfer) types.Uint128 { return transfer.ID },
+			func(transfer types.Transfer) bool { return transfer.TransferFlags().Linked },
+			func(transfer types.Transfer, existing *types.Transfer) uint32 {
+				if result := transfer.Validate(); result != types.TransferOK {
+					return uint32(result)
+				}
+				if existing != nil {
+					return uint32(transfer.Exists(*existing))
+				}
+				return 0
+			},
+		)
+		return encodeDryRunResults(results, func(index uint32, code uint32) types.TransferEventResult {
+			return types.TransferEventResult{Index: index, Result: types.CreateTransferResult(code)}
+		}), nil
+	}
+	// Nothing was ever created, so lookups and queries find nothing.
+	return nil, nil
+}
+
+// dryRunResult is the result of an event of a batch, with the code of the CreateAccountResult or
+// CreateTransferResult.
+type dryRunResult struct {
+	index uint32
+	code  uint32
+}
+
+// Codes shared by CreateAccountResult and CreateTransferResult.
+const (
+	dryRunLinkedEventFailed    = 1
+	dryRunLinkedEventChainOpen = 2
+)
+
+// dryRunBatch checks the events of a batch with check, passing it the earlier event of the batch
+// with the same ID if there is one, and fails the linked chains as the cluster does.
+func dryRunBatch[E any](
+	events []E,
+	id func(event E) types.Uint128,
+	linked func(event E) bool,
+	check func(event E, existing *E) uint32,
+) []dryRunResult {
+	var results []dryRunResult
+	created := make(map[types.Uint128]int, len(events))
+	for start := 0; start < len(events); {
+		end := start
+		for end < len(events)-1 && linked(events[end]) {
+			end++
+		}
+
+		failed, code := -1, uint32(0)
+		if linked(events[end]) {
+			// The last event of the batch leaves the chain open.
+			failed, code = end, dryRunLinkedEventChainOpen
+		} else {
+			for i := start; i <= end; i++ {
+				var existing *E
+				if index, ok := created[id(events[i])]; ok {
+					existing = &events[index]
+				}
+				if code = check(events[i], existing); code != 0 {
+					failed = i
+					break
+				}
+				created[id(events[i])] = i
+			}
+		}
+
+		if failed >= 0 {
+			for i := start; i <= end; i++ {
+				if index, ok := created[id(events[i])]; ok && index >= start {
+					delete(created, id(events[i]))
+				}
+				switch {
+				case i == failed:
+					results = append(results, dryRunResult{index: uint32(i), code: code})
+				case start != end:
+					// Every other event of a linked chain fails along with it.
+					results = append(results, dryRunResult{index: uint32(i), code: dryRunLinkedEventFailed})
+				}
+			}
+		}
+		start = end + 1
+	}
+	return results
+}
+
+func encodeDryRunResults[R any](results []dryRunResult, result func(index uint32, code uint32) R) []byte {
+	if len(results) == 0 {
+		return nil
+	}
+	encoded := make([]R, len(results))
+	for i, r := range results {
+		encoded[i] = result(r.index, r.code)
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&encoded[0])), len(encoded)*int(unsafe.Sizeof(encoded[0])))
+}
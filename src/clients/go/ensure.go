@@ -0,0 +1,133 @@
+package tigerbeetle_go
+
+import (
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+type AccountReceiptStatus uint8
+
+const (
+	// AccountCreated means the account did not exist and was created.
+	AccountCreated AccountReceiptStatus = iota
+	// AccountExisted means an identical account already existed.
+	AccountExisted
+	// AccountMismatch means an account with the same ID already existed but differs from the one
+	// requested; Drift lists the fields that differ.
+	AccountMismatch
+	// AccountFailed means the account could not be created; Result holds the reason.
+	AccountFailed
+)
+
+func (s AccountReceiptStatus) String() string {
+	switch s {
+	case AccountCreated:
+		return "created"
+	case AccountExisted:
+		return "existed"
+	case AccountMismatch:
+		return "mismatch"
+	case AccountFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// AccountReceipt records what EnsureAccounts did for a single requested account.
+type AccountReceipt struct {
+	ID     types.Uint128
+	Status AccountReceiptStatus
+	// Result is the result code of the create, AccountOK when the account was created.
+	Result types.CreateAccountResult
+	// Existing is the account found in the cluster, set for AccountExisted and AccountMismatch.
+	Existing types.Account
+	// Drift names the fields where Existing differs from the requested account.
+	Drift []string
+}
+
+// EnsureAccounts creates the accounts that don't exist yet and checks that the ones that do
+// exist match what was requested, returning one receipt per account in the same order.
+// It is safe to re-run, which is what provisioning jobs need. All accounts are submitted in a
+// single batch, so they must fit within the maximum batch size.
+func EnsureAccounts(client Client, accounts []types.Account) ([]AccountReceipt, error) {
+	results, err := client.CreateAccounts(accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make([]AccountReceipt, len(accounts))
+	for i, account := range accounts {
+		receipts[i] = AccountReceipt{ID: account.ID, Status: AccountCreated, Result: types.AccountOK}
+	}
+
+	var existingIDs []types.Uint128
+	for _, result := range results {
+		receipt := &receipts[result.Index]
+		receipt.Result = result.Result
+
+		switch result.Result {
+		case types.AccountExists:
+			receipt.Status = AccountExisted
+			existingIDs = append(existingIDs, receipt.ID)
+		case types.AccountExistsWithDifferentFlags,
+			types.AccountExistsWithDifferentUserData128,
+			types.AccountExistsWithDifferentUserData64,
+			types.AccountExistsWithDifferentUserData32,
+			types.AccountExistsWithDifferentLedger,
+			types.AccountExistsWithDifferentCode:
+			receipt.Status = AccountMismatch
+			existingIDs = append(existingIDs, receipt.ID)
+		default:
+			receipt.Status = AccountFailed
+		}
+	}
+
+	if len(existingIDs) == 0 {
+		return receipts, nil
+	}
+
+	// The result code only names the first field that differs, so compare all of them.
+	existing, err := client.LookupAccounts(existingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[types.Uint128]types.Account, len(existing))
+	for _, account := range existing {
+		found[account.ID] = account
+	}
+
+	for i := range receipts {
+		receipt := &receipts[i]
+		if receipt.Status != AccountExisted && receipt.Status != AccountMismatch {
+			continue
+		}
+
+		receipt.Existing = found[receipt.ID]
+		receipt.Drift = accountDrift(accounts[i], receipt.Existing)
+	}
+
+	return receipts, nil
+}
+
+func accountDrift(requested types.Account, existing types.Account) []string {
+	var drift []string
+	if requested.Flags != existing.Flags {
+		drift = append(drift, "flags")
+	}
+	if requested.Ledger != existing.Ledger {
+		drift = append(drift, "ledger")
+	}
+	if requested.Code != existing.Code {
+		drift = append(drift, "code")
+	}
+	if requested.UserData128 != existing.UserData128 {
+		drift = append(drift, "user_data_128")
+	}
+	if requested.UserData64 != existing.UserData64 {
+		drift = append(drift, "user_data_64")
+	}
+	if requested.UserData32 != existing.UserData32 {
+		drift = append(drift, "user_data_32")
+	}
+	return drift
+}
@@ -0,0 +1,474 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// FailoverCluster is a cluster of a FailoverClient.
+type FailoverCluster uint8
+
+const (
+	FailoverPrimary FailoverCluster = iota
+	FailoverSecondary
+)
+
+func (c FailoverCluster) String() string {
+	switch c {
+	case FailoverPrimary:
+		return "primary"
+	case FailoverSecondary:
+		return "secondary"
+	}
+	return "unknown"
+}
+
+// Defaults of FailoverPolicy.
+const (
+	failoverCheckIntervalDefault = time.Second
+	failoverQueueMaxDefault      = 1024
+)
+
+// FailoverPolicy configures when a FailoverClient fails over, and what happens to writes then.
+type FailoverPolicy struct {
+	// After is how long the primary must stay ConnectionUnreachable, or ConnectionEvicted,
+	// before the reads fail over to the secondary, on top of the
+	// ConnectionMonitor.UnreachableAfter it took to become so.
+	After time.Duration
+	// Manual leaves failing over to Switchover, rather than doing it after After.
+	Manual bool
+	// CheckInterval is how often the state of the primary is checked. Defaults to 1s.
+	CheckInterval time.Duration
+	// QueueWrites holds the writes submitted while failed over until the switchback, and then
+	// submits them to the primary, in no particular order, rather than fail them with
+	// ErrFailedOver. TryCreateAccounts and TryCreateTransfers still fail with ErrFailedOver.
+	QueueWrites bool
+	// QueueMax is how many writes may be held at once, with more failing with
+	// ErrFailoverQueueFull. Defaults to 1024.
+	QueueMax int
+	// OnEvent is called on every switch between the clusters, in order. It must not block, nor
+	// submit requests through the client.
+	OnEvent func(event FailoverEvent)
+}
+
+// FailoverEvent describes a switch between the clusters of a FailoverClient, as passed to
+// FailoverPolicy.OnEvent.
+type FailoverEvent struct {
+	From FailoverCluster
+	To   FailoverCluster
+	At   time.Time
+	// Automatic is whether the client failed over on its own, as the primary was unreachable,
+	// rather than with Switchover or Switchback.
+	Automatic bool
+	// State is the state of the primary at the switch.
+	State ConnectionState
+	// Queued counts the writes held when switching back, which are then submitted to the
+	// primary.
+	Queued int
+}
+
+// FailoverClient sends its requests to a primary cluster, and its reads to a warm standby, the
+// secondary cluster, while the primary is unavailable: once it has been unreachable for
+// FailoverPolicy.After, or after Switchover. Writes keep going to the primary alone, since the
+// clusters can't agree on them, and fail with ErrFailedOver while failed over, or are held until
+// Switchback with FailoverPolicy.QueueWrites.
+//
+// The secondary is kept in sync by the application, as with pkg/cdc, so it may lag behind the
+// primary, and the timestamps of its objects differ from those of the primary. Streams and tails
+// follow the reads from one cluster to the other, and may thus skip or repeat events across a
+// switch.
+//
+// Requests in flight to the primary when it fails over stay in flight until it replies.
+// Switching back is left to Switchback, once the primary is known to be healthy again.
+type FailoverClient struct {
+	primary   Client
+	secondary Client
+	policy    FailoverPolicy
+
+	mutex  sync.Mutex
+	active FailoverCluster
+	// switchedBack is closed on the switchback, for the writes held while failed over.
+	switchedBack chan struct{}
+	queued       int
+
+	closing   chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewFailoverClient returns a client over primary and secondary, sending its requests to
+// primary until it fails over. The client owns both clients, and closes them on Close.
+func NewFailoverClient(primary Client, secondary Client, policy FailoverPolicy) *FailoverClient {
+	if policy.CheckInterval <= 0 {
+		policy.CheckInterval = failoverCheckIntervalDefault
+	}
+	if policy.QueueMax <= 0 {
+		policy.QueueMax = failoverQueueMaxDefault
+	}
+	c := &FailoverClient{
+		primary:   primary,
+		secondary: secondary,
+		policy:    policy,
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if !policy.Manual {
+		go c.watch()
+	}
+	go func() {
+		<-primary.Done()
+		<-secondary.Done()
+		close(c.done)
+	}()
+	return c
+}
+
+// watch fails over once the primary has been unavailable for After, until the client closes.
+func (c *FailoverClient) watch() {
+	ticker := time.NewTicker(c.policy.CheckInterval)
+	defer ticker.Stop()
+	var since time.Time
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.closing:
+			return
+		}
+		state := c.primary.State()
+		if state != ConnectionUnreachable && state != ConnectionEvicted {
+			since = time.Time{}
+			continue
+		}
+		now := time.Now()
+		if since.IsZero() {
+			since = now
+		}
+		if now.Sub(since) >= c.policy.After {
+			c.switchTo(FailoverSecondary, true)
+		}
+	}
+}
+
+// Switchover fails the reads over to the secondary, once it has replied to a ping, or fails with
+// the error of the ping.
+func (c *FailoverClient) Switchover(ctx context.Context) error {
+	if _, err := c.secondary.Ping(ctx); err != nil {
+		return err
+	}
+	c.switchTo(FailoverSecondary, false)
+	return nil
+}
+
+// Switchback sends the reads to the primary again, once it has replied to a ping, and submits the
+// writes held meanwhile to it, or fails with the error of the ping.
+func (c *FailoverClient) Switchback(ctx context.Context) error {
+	if _, err := c.primary.Ping(ctx); err != nil {
+		return err
+	}
+	c.switchTo(FailoverPrimary, false)
+	return nil
+}
+
+func (c *FailoverClient) switchTo(to FailoverCluster, automatic bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.active == to {
+		return
+	}
+	event := FailoverEvent{
+		From:      c.active,
+		To:        to,
+		At:        time.Now(),
+		Automatic: automatic,
+		State:     c.primary.State(),
+	}
+	c.active = to
+	switch to {
+	case FailoverSecondary:
+		c.switchedBack = make(chan struct{})
+	case FailoverPrimary:
+		event.Queued = c.queued
+		close(c.switchedBack)
+	}
+	if c.policy.OnEvent != nil {
+		c.policy.OnEvent(event)
+	}
+}
+
+// Active returns the cluster that reads are currently sent to.
+func (c *FailoverClient) Active() FailoverCluster {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.active
+}
+
+// reader returns the client of the cluster that reads are sent to.
+func (c *FailoverClient) reader() Client {
+	if c.Active() == FailoverSecondary {
+		return c.secondary
+	}
+	return c.primary
+}
+
+// writer returns the primary, once the client is not failed over, holding the write meanwhile
+// with QueueWrites if it may wait.
+func (c *FailoverClient) writer(wait bool) (Client, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for c.active == FailoverSecondary {
+		if !c.policy.QueueWrites || !wait {
+			return nil, errors.ErrFailedOver{}
+		}
+		if c.queued >= c.policy.QueueMax {
+			return nil, errors.ErrFailoverQueueFull{}
+		}
+		c.queued++
+		switchedBack := c.switchedBack
+		c.mutex.Unlock()
+		var closed bool
+		select {
+		case <-switchedBack:
+		case <-c.closing:
+			closed = true
+		}
+		c.mutex.Lock()
+		c.queued--
+		if closed {
+			return nil, errors.ErrClientClosed{}
+		}
+	}
+	return c.primary, nil
+}
+
+// failoverWrite calls f with the primary, as a write, which fails rather than wait for the
+// switchback unless it may wait.
+func failoverWrite[T any](c *FailoverClient, wait bool, f func(client Client) (T, error)) (T, error) {
+	client, err := c.writer(wait)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return f(client)
+}
+
+func (c *FailoverClient) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return failoverWrite(c, true, func(client Client) ([]types.AccountEventResult, error) {
+		return client.CreateAccounts(accounts)
+	})
+}
+
+func (c *FailoverClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return failoverWrite(c, true, func(client Client) ([]types.TransferEventResult, error) {
+		return client.CreateTransfers(transfers)
+	})
+}
+
+// TryCreateAccounts fails with ErrFailedOver while failed over, rather than wait for the
+// switchback with QueueWrites.
+func (c *FailoverClient) TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return failoverWrite(c, false, func(client Client) ([]types.AccountEventResult, error) {
+		return client.TryCreateAccounts(accounts)
+	})
+}
+
+// TryCreateTransfers fails with ErrFailedOver while failed over, rather than wait for the
+// switchback with QueueWrites.
+func (c *FailoverClient) TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return failoverWrite(c, false, func(client Client) ([]types.TransferEventResult, error) {
+		return client.TryCreateTransfers(transfers)
+	})
+}
+
+// CreateAccountsAsync returns once the accounts are submitted, which waits for the switchback
+// for writes held while failed over.
+func (c *FailoverClient) CreateAccountsAsync(
+	accounts []types.Account,
+) (*Future[[]types.AccountEventResult], error) {
+	return failoverWrite(c, true, func(client Client) (*Future[[]types.AccountEventResult], error) {
+		return client.CreateAccountsAsync(accounts)
+	})
+}
+
+// CreateTransfersAsync returns once the transfers are submitted, which waits for the switchback
+// for writes held while failed over.
+func (c *FailoverClient) CreateTransfersAsync(
+	transfers []types.Transfer,
+) (*Future[[]types.TransferEventResult], error) {
+	return failoverWrite(c, true, func(client Client) (*Future[[]types.TransferEventResult], error) {
+		return client.CreateTransfersAsync(transfers)
+	})
+}
+
+func (c *FailoverClient) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	return c.reader().LookupAccounts(accountIDs)
+}
+
+func (c *FailoverClient) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	return c.reader().LookupTransfers(transferIDs)
+}
+
+func (c *FailoverClient) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	return c.reader().GetAccountTransfers(filter)
+}
+
+func (c *FailoverClient) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	return c.reader().GetAccountHistory(filter)
+}
+
+func (c *FailoverClient) LookupAccountsInto(
+	accountIDs []types.Uint128,
+	buf []types.Account,
+) ([]types.Account, error) {
+	return c.reader().LookupAccountsInto(accountIDs, buf)
+}
+
+func (c *FailoverClient) LookupTransfersInto(
+	transferIDs []types.Uint128,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return c.reader().LookupTransfersInto(transferIDs, buf)
+}
+
+func (c *FailoverClient) GetAccountTransfersInto(
+	filter types.AccountFilter,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return c.reader().GetAccountTransfersInto(filter, buf)
+}
+
+func (c *FailoverClient) GetAccountHistoryInto(
+	filter types.AccountFilter,
+	buf []types.AccountBalance,
+) ([]types.AccountBalance, error) {
+	return c.reader().GetAccountHistoryInto(filter, buf)
+}
+
+func (c *FailoverClient) CreateAccount(account types.Account) error {
+	return createAccount(c, account)
+}
+
+func (c *FailoverClient) CreateTransfer(transfer types.Transfer) error {
+	return createTransfer(c, transfer)
+}
+
+func (c *FailoverClient) LookupAccount(accountID types.Uint128) (types.Account, bool, error) {
+	return lookupAccount(c, accountID)
+}
+
+func (c *FailoverClient) LookupAccountsStream(
+	accountIDs iter.Seq[types.Uint128],
+) iter.Seq2[types.Account, error] {
+	return lookupAccountsStream(c, accountIDs)
+}
+
+func (c *FailoverClient) StreamChanges(
+	ctx context.Context,
+	fromTimestamp uint64,
+	accountIDs []types.Uint128,
+) <-chan ChangeEvent {
+	return streamChanges(ctx, c, fromTimestamp, accountIDs)
+}
+
+func (c *FailoverClient) TailTransfers(
+	ctx context.Context,
+	accountID types.Uint128,
+	sinceTimestamp uint64,
+) <-chan TailEvent {
+	return tailTransfers(ctx, c, accountID, sinceTimestamp)
+}
+
+func (c *FailoverClient) WatchBalance(
+	ctx context.Context,
+	accountID types.Uint128,
+	options WatchBalanceOptions,
+) <-chan BalanceSnapshot {
+	return watchBalance(ctx, c, accountID, options)
+}
+
+func (c *FailoverClient) PostPending(pendingID types.Uint128, amount types.Uint128) error {
+	return createTransfer(c, types.PostPendingTransfer(pendingID, amount))
+}
+
+func (c *FailoverClient) VoidPending(pendingID types.Uint128) error {
+	return createTransfer(c, types.VoidPendingTransfer(pendingID))
+}
+
+// SubmitRaw submits the creation of accounts and transfers as a write, and the other operations
+// as reads.
+func (c *FailoverClient) SubmitRaw(op types.Operation, body []byte) ([]byte, error) {
+	if op == types.OperationCreateAccounts || op == types.OperationCreateTransfers {
+		return failoverWrite(c, true, func(client Client) ([]byte, error) {
+			return client.SubmitRaw(op, body)
+		})
+	}
+	return c.reader().SubmitRaw(op, body)
+}
+
+func (c *FailoverClient) MessageSizeMax() int {
+	return c.reader().MessageSizeMax()
+}
+
+// UpdateAddresses updates the addresses of the primary.
+func (c *FailoverClient) UpdateAddresses(addresses []string) error {
+	return c.primary.UpdateAddresses(addresses)
+}
+
+// Pause pauses both clients.
+func (c *FailoverClient) Pause(ctx context.Context, mode PauseMode) error {
+	if err := c.primary.Pause(ctx, mode); err != nil {
+		return err
+	}
+	return c.secondary.Pause(ctx, mode)
+}
+
+// Resume resumes both clients.
+func (c *FailoverClient) Resume() {
+	c.primary.Resume()
+	c.secondary.Resume()
+}
+
+// Ping pings the cluster that reads are sent to.
+func (c *FailoverClient) Ping(ctx context.Context) (time.Duration, error) {
+	return c.reader().Ping(ctx)
+}
+
+func (c *FailoverClient) Nop() error {
+	return c.reader().Nop()
+}
+
+// State returns the connection state of the primary.
+func (c *FailoverClient) State() ConnectionState {
+	return c.primary.State()
+}
+
+// Stats returns the stats of the client of the cluster that reads are sent to.
+func (c *FailoverClient) Stats() Stats {
+	return c.reader().Stats()
+}
+
+// Close fails the writes held with ErrClientClosed, and closes both clients.
+func (c *FailoverClient) Close() {
+	c.closeOnce.Do(func() { close(c.closing) })
+	c.primary.Close()
+	c.secondary.Close()
+}
+
+// CloseContext fails the writes held with ErrClientClosed, and closes both clients.
+func (c *FailoverClient) CloseContext(ctx context.Context) error {
+	c.closeOnce.Do(func() { close(c.closing) })
+	err := c.primary.CloseContext(ctx)
+	if secondaryErr := c.secondary.CloseContext(ctx); err == nil {
+		err = secondaryErr
+	}
+	return err
+}
+
+// Done returns the channel closed once both clients have shut down.
+func (c *FailoverClient) Done() <-chan struct{} {
+	return c.done
+}
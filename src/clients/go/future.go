@@ -0,0 +1,159 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"sync"
+	"unsafe"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Future is the eventual reply to a request submitted with CreateAccountsAsync or
+// CreateTransfersAsync, which lets a single goroutine keep many requests in flight.
+type Future[T any] struct {
+	done chan struct{}
+
+	mutex     sync.Mutex
+	resolved  bool
+	value     T
+	err       error
+	callbacks []func()
+}
+
+func newFuture[T any]() *Future[T] {
+	return &Future[T]{done: make(chan struct{})}
+}
+
+// ResolvedFuture returns a future that is already complete with value and err, as for a mock
+// of CreateTransfersAsync.
+func ResolvedFuture[T any](value T, err error) *Future[T] {
+	future := newFuture[T]()
+	future.resolve(value, err)
+	return future
+}
+
+// resolve completes the future, unless it already was, and runs its callbacks.
+func (f *Future[T]) resolve(value T, err error) {
+	f.mutex.Lock()
+	if f.resolved {
+		f.mutex.Unlock()
+		return
+	}
+	f.resolved = true
+	f.value, f.err = value, err
+	callbacks := f.callbacks
+	f.callbacks = nil
+	close(f.done)
+	f.mutex.Unlock()
+
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+// onDone calls callback once the future is complete, right away if it already is.
+func (f *Future[T]) onDone(callback func()) {
+	f.mutex.Lock()
+	if !f.resolved {
+		f.callbacks = append(f.callbacks, callback)
+		f.mutex.Unlock()
+		return
+	}
+	f.mutex.Unlock()
+	callback()
+}
+
+// Done returns a channel that is closed once the reply has arrived, for use in a select.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait returns the reply once it has arrived, or ctx.Err() if ctx is done first, in which case
+// the request stays in flight and the future still completes.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Then returns a future of the result of calling next with the reply of f, once it has arrived
+// without an error, or that fails with the error of f. next is called where f completes, which
+// may be the thread of tb_client, without a goroutine of its own, so it must not block.
+func Then[T any, U any](f *Future[T], next func(T) (U, error)) *Future[U] {
+	chained := newFuture[U]()
+	f.onDone(func() {
+		if f.err != nil {
+			var zero U
+			chained.resolve(zero, f.err)
+			return
+		}
+		chained.resolve(next(f.value))
+	})
+	return chained
+}
+
+// CreateAccountsAsync submits the accounts and returns right away with the future of the
+// results, once the request was accepted. Errors that reject the request outright, such as
+// ErrEmptyBatch or ErrClientClosed, are returned immediately instead. With ConcurrencyBlock, it
+// waits for a free request slot first, which paces the submissions.
+//
+// The accounts must not be modified until the future is done.
+func (c *c_client) CreateAccountsAsync(
+	accounts []types.Account,
+) (*Future[[]types.AccountEventResult], error) {
+	if err := c.checkAccounts(accounts); err != nil {
+		return nil, err
+	}
+	return requestAsync[types.Account, types.AccountEventResult](
+		c, types.OperationCreateAccounts, accounts)
+}
+
+// CreateTransfersAsync is CreateAccountsAsync for transfers.
+func (c *c_client) CreateTransfersAsync(
+	transfers []types.Transfer,
+) (*Future[[]types.TransferEventResult], error) {
+	if err := c.checkTransfers(transfers); err != nil {
+		return nil, err
+	}
+	return requestAsync[types.Transfer, types.TransferEventResult](
+		c, types.OperationCreateTransfers, transfers)
+}
+
+// requestAsync admits a create request right away, with room for a result per event, and
+// resolves its future from the completion of the transport. Transports that only submit
+// synchronously, as those of WithTransport or wrapped for retries or hedging, take a goroutine
+// per request instead.
+func requestAsync[E any, R any](c *c_client, op types.Operation, events []E) (*Future[[]R], error) {
+	release, err := c.begin(op, len(events), true)
+	if err != nil {
+		return nil, err
+	}
+
+	future := newFuture[[]R]()
+	results := make([]R, len(events))
+	complete := func(wrote int, err error) {
+		// Release the request slot before completing, for the next request to take it.
+		release()
+		if err != nil {
+			future.resolve(nil, err)
+			return
+		}
+		var result R
+		future.resolve(results[:wrote/int(unsafe.Sizeof(result))], nil)
+	}
+
+	encoded := unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(events))), len(events)*types.EventSize(op))
+	reply := unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(results))), len(results)*types.ResultSize(op))
+	if c.async != nil {
+		c.sendAsync(c.async, op, encoded, reply, complete)
+		return future, nil
+	}
+	go func() {
+		complete(c.send(op, encoded, reply))
+	}()
+	return future, nil
+}
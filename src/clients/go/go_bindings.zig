@@ -84,6 +84,30 @@ fn calculate_min_len(comptime type_info: anytype) comptime_int {
     }
 }
 
+fn go_field_type(comptime Type: type) []const u8 {
+    return comptime switch (@typeInfo(Type)) {
+        .Array => |array| std.fmt.comptimePrint("[{d}]{s}", .{ array.len, go_type(array.child) }),
+        else => go_type(Type),
+    };
+}
+
+fn calculate_type_min_len(comptime type_info: anytype) comptime_int {
+    comptime {
+        comptime var min_len: comptime_int = 0;
+        inline for (type_info.fields) |field| {
+            const type_len = go_field_type(field.type).len;
+            if (type_len > min_len) {
+                min_len = type_len;
+            }
+        }
+        return min_len;
+    }
+}
+
+fn pad_right(comptime input: []const u8, comptime min_len: usize) []const u8 {
+    return comptime input ++ (" " ** (min_len - input.len));
+}
+
 fn is_upper_case(comptime word: []const u8) bool {
     // https://github.com/golang/go/wiki/CodeReviewComments#initialisms
     const initialisms = .{ "id", "ok" };
@@ -226,30 +250,24 @@ fn emit_struct(
     });
 
     const min_len = calculate_min_len(type_info);
+    const type_min_len = calculate_type_min_len(type_info);
     comptime var flagsField = false;
     inline for (type_info.fields) |field| {
-        switch (@typeInfo(field.type)) {
-            .Array => |array| {
-                try buffer.writer().print("\t{s} [{d}]{s}\n", .{
-                    to_pascal_case(field.name, min_len),
-                    array.len,
-                    go_type(array.child),
-                });
-            },
-            else => {
-                if (comptime std.mem.eql(u8, field.name, "flags")) {
-                    flagsField = true;
-                }
-
-                try buffer.writer().print(
-                    "\t{s} {s}\n",
-                    .{
-                        to_pascal_case(field.name, min_len),
-                        go_type(field.type),
-                    },
-                );
-            },
+        if (comptime std.mem.eql(u8, field.name, "flags")) {
+            flagsField = true;
         }
+
+        // Reserved fields must be zero, so they are left out of JSON.
+        const json_name = if (comptime std.mem.startsWith(u8, field.name, "reserved"))
+            "-"
+        else
+            field.name;
+
+        try buffer.writer().print("\t{s} {s} `json:\"{s}\"`\n", .{
+            to_pascal_case(field.name, min_len),
+            comptime pad_right(go_field_type(field.type), type_min_len),
+            json_name,
+        });
     }
 
     try buffer.writer().print("}}\n\n", .{});
@@ -306,10 +324,6 @@ pub fn generate_bindings(buffer: *std.ArrayList(u8)) !void {
         \\
         \\package types
         \\
-        \\/*
-        \\#include "../native/tb_client.h"
-        \\*/
-        \\import "C"
         \\import "strconv"
         \\
         \\
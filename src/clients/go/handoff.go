@@ -0,0 +1,277 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	e "errors"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// HandoffClient is a client whose traffic can be switched to a warm standby client, for
+// example one created with a new address list, without dropping submissions.
+type HandoffClient struct {
+	mutex  sync.RWMutex
+	active Client
+}
+
+// NewHandoffClient returns a client that sends its traffic to active until handed off.
+func NewHandoffClient(active Client) *HandoffClient {
+	return &HandoffClient{active: active}
+}
+
+// Handoff checks that standby can reach its cluster with a Ping, switches all new requests to
+// it, and closes the previously active client once its in-flight requests have drained. If the
+// Ping fails, or ctx is done first, the active client is left in place and the error returned.
+//
+// Requests that raced with the switch and were refused by the closing client are resubmitted
+// to standby, which is safe as refused requests never reached the cluster.
+func (c *HandoffClient) Handoff(ctx context.Context, standby Client) error {
+	if _, err := standby.Ping(ctx); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	previous := c.active
+	c.active = standby
+	c.mutex.Unlock()
+
+	return previous.CloseContext(ctx)
+}
+
+// Active returns the client that new requests are currently sent to.
+func (c *HandoffClient) Active() Client {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.active
+}
+
+// handoffDo calls f with the active client, calling it again with the new active client if the
+// previous one refused it because it was handed off in the meantime.
+func handoffDo[T any](c *HandoffClient, f func(client Client) (T, error)) (T, error) {
+	for {
+		client := c.Active()
+		result, err := f(client)
+		if e.Is(err, errors.ErrClientClosed{}) && c.Active() != client {
+			continue
+		}
+		return result, err
+	}
+}
+
+func (c *HandoffClient) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return handoffDo(c, func(client Client) ([]types.AccountEventResult, error) {
+		return client.CreateAccounts(accounts)
+	})
+}
+
+func (c *HandoffClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return handoffDo(c, func(client Client) ([]types.TransferEventResult, error) {
+		return client.CreateTransfers(transfers)
+	})
+}
+
+func (c *HandoffClient) TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return handoffDo(c, func(client Client) ([]types.AccountEventResult, error) {
+		return client.TryCreateAccounts(accounts)
+	})
+}
+
+func (c *HandoffClient) TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return handoffDo(c, func(client Client) ([]types.TransferEventResult, error) {
+		return client.TryCreateTransfers(transfers)
+	})
+}
+
+func (c *HandoffClient) CreateAccountsAsync(
+	accounts []types.Account,
+) (*Future[[]types.AccountEventResult], error) {
+	return handoffDo(c, func(client Client) (*Future[[]types.AccountEventResult], error) {
+		return client.CreateAccountsAsync(accounts)
+	})
+}
+
+func (c *HandoffClient) CreateTransfersAsync(
+	transfers []types.Transfer,
+) (*Future[[]types.TransferEventResult], error) {
+	return handoffDo(c, func(client Client) (*Future[[]types.TransferEventResult], error) {
+		return client.CreateTransfersAsync(transfers)
+	})
+}
+
+func (c *HandoffClient) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	return handoffDo(c, func(client Client) ([]types.Account, error) {
+		return client.LookupAccounts(accountIDs)
+	})
+}
+
+func (c *HandoffClient) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	return handoffDo(c, func(client Client) ([]types.Transfer, error) {
+		return client.LookupTransfers(transferIDs)
+	})
+}
+
+func (c *HandoffClient) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	return handoffDo(c, func(client Client) ([]types.Transfer, error) {
+		return client.GetAccountTransfers(filter)
+	})
+}
+
+func (c *HandoffClient) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	return handoffDo(c, func(client Client) ([]types.AccountBalance, error) {
+		return client.GetAccountHistory(filter)
+	})
+}
+
+func (c *HandoffClient) LookupAccountsInto(
+	accountIDs []types.Uint128,
+	buf []types.Account,
+) ([]types.Account, error) {
+	return handoffDo(c, func(client Client) ([]types.Account, error) {
+		return client.LookupAccountsInto(accountIDs, buf)
+	})
+}
+
+func (c *HandoffClient) LookupTransfersInto(
+	transferIDs []types.Uint128,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return handoffDo(c, func(client Client) ([]types.Transfer, error) {
+		return client.LookupTransfersInto(transferIDs, buf)
+	})
+}
+
+func (c *HandoffClient) GetAccountTransfersInto(
+	filter types.AccountFilter,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return handoffDo(c, func(client Client) ([]types.Transfer, error) {
+		return client.GetAccountTransfersInto(filter, buf)
+	})
+}
+
+func (c *HandoffClient) GetAccountHistoryInto(
+	filter types.AccountFilter,
+	buf []types.AccountBalance,
+) ([]types.AccountBalance, error) {
+	return handoffDo(c, func(client Client) ([]types.AccountBalance, error) {
+		return client.GetAccountHistoryInto(filter, buf)
+	})
+}
+
+func (c *HandoffClient) CreateAccount(account types.Account) error {
+	return createAccount(c, account)
+}
+
+func (c *HandoffClient) CreateTransfer(transfer types.Transfer) error {
+	return createTransfer(c, transfer)
+}
+
+func (c *HandoffClient) LookupAccount(accountID types.Uint128) (types.Account, bool, error) {
+	return lookupAccount(c, accountID)
+}
+
+func (c *HandoffClient) LookupAccountsStream(
+	accountIDs iter.Seq[types.Uint128],
+) iter.Seq2[types.Account, error] {
+	return lookupAccountsStream(c, accountIDs)
+}
+
+func (c *HandoffClient) StreamChanges(
+	ctx context.Context,
+	fromTimestamp uint64,
+	accountIDs []types.Uint128,
+) <-chan ChangeEvent {
+	return streamChanges(ctx, c, fromTimestamp, accountIDs)
+}
+
+func (c *HandoffClient) TailTransfers(
+	ctx context.Context,
+	accountID types.Uint128,
+	sinceTimestamp uint64,
+) <-chan TailEvent {
+	return tailTransfers(ctx, c, accountID, sinceTimestamp)
+}
+
+func (c *HandoffClient) WatchBalance(
+	ctx context.Context,
+	accountID types.Uint128,
+	options WatchBalanceOptions,
+) <-chan BalanceSnapshot {
+	return watchBalance(ctx, c, accountID, options)
+}
+
+func (c *HandoffClient) PostPending(pendingID types.Uint128, amount types.Uint128) error {
+	return createTransfer(c, types.PostPendingTransfer(pendingID, amount))
+}
+
+func (c *HandoffClient) VoidPending(pendingID types.Uint128) error {
+	return createTransfer(c, types.VoidPendingTransfer(pendingID))
+}
+
+func (c *HandoffClient) SubmitRaw(op types.Operation, body []byte) ([]byte, error) {
+	return handoffDo(c, func(client Client) ([]byte, error) {
+		return client.SubmitRaw(op, body)
+	})
+}
+
+func (c *HandoffClient) MessageSizeMax() int {
+	return c.Active().MessageSizeMax()
+}
+
+// UpdateAddresses updates the addresses of the active client.
+func (c *HandoffClient) UpdateAddresses(addresses []string) error {
+	return c.Active().UpdateAddresses(addresses)
+}
+
+// Pause pauses the active client. A standby handed off to meanwhile is not paused.
+func (c *HandoffClient) Pause(ctx context.Context, mode PauseMode) error {
+	return c.Active().Pause(ctx, mode)
+}
+
+// Resume resumes the active client.
+func (c *HandoffClient) Resume() {
+	c.Active().Resume()
+}
+
+// Ping pings the cluster through the active client.
+func (c *HandoffClient) Ping(ctx context.Context) (time.Duration, error) {
+	return handoffDo(c, func(client Client) (time.Duration, error) {
+		return client.Ping(ctx)
+	})
+}
+
+func (c *HandoffClient) Nop() error {
+	_, err := handoffDo(c, func(client Client) (struct{}, error) {
+		return struct{}{}, client.Nop()
+	})
+	return err
+}
+
+// State returns the connection state of the active client.
+func (c *HandoffClient) State() ConnectionState {
+	return c.Active().State()
+}
+
+// Stats returns the stats of the active client.
+func (c *HandoffClient) Stats() Stats {
+	return c.Active().Stats()
+}
+
+// Close closes the active client.
+func (c *HandoffClient) Close() {
+	c.Active().Close()
+}
+
+// CloseContext closes the active client.
+func (c *HandoffClient) CloseContext(ctx context.Context) error {
+	return c.Active().CloseContext(ctx)
+}
+
+// Done returns the channel closed once the active client has shut down.
+func (c *HandoffClient) Done() <-chan struct{} {
+	return c.Active().Done()
+}
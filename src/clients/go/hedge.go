@@ -0,0 +1,194 @@
+package tigerbeetle_go
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// hedgeDelayDefault is how long a lookup waits for a reply before it is hedged.
+const hedgeDelayDefault = 10 * time.Millisecond
+
+// HedgePolicy configures how a client hedges lookups: a lookup that has no reply after Delay is
+// submitted a second time, through a second session, and the first reply is taken. This bounds
+// the latency of a lookup stuck behind a slow connection or session, at the cost of the
+// duplicate requests.
+//
+// Only LookupAccounts, LookupTransfers, GetAccountTransfers and GetAccountHistory are hedged,
+// since they don't change the state of the cluster. Every session submits to the primary
+// replica, so hedging does not help when the primary itself is slow.
+type HedgePolicy struct {
+	// Delay is how long a lookup waits before it is hedged. Defaults to 10ms.
+	Delay time.Duration
+	// Transport carries the hedged requests. Defaults to a second tb_client session with the
+	// addresses passed to NewClient, or to the client's own transport with WithTransport.
+	Transport Transport
+	// OnResult is called after every lookup that could be hedged, whether or not it was.
+	OnResult func(result HedgeResult)
+}
+
+// HedgeResult describes a lookup, as passed to HedgePolicy.OnResult.
+type HedgeResult struct {
+	Operation types.Operation
+	// Hedged is whether the lookup was submitted a second time, and HedgeWon whether the reply
+	// taken was that of the second submission.
+	Hedged   bool
+	HedgeWon bool
+	Latency  time.Duration
+}
+
+// WithHedging makes the client hedge lookups according to policy.
+func WithHedging(policy HedgePolicy) ClientOption {
+	return func(options *clientOptions) {
+		options.hedgePolicy = &policy
+	}
+}
+
+// HedgeStats counts the hedged lookups of a client, as a HedgePolicy.OnResult:
+//
+//	stats := &tigerbeetle_go.HedgeStats{}
+//	client, err := tigerbeetle_go.NewClient(clusterID, addresses, concurrencyMax,
+//		tigerbeetle_go.WithHedging(tigerbeetle_go.HedgePolicy{OnResult: stats.Record}))
+type HedgeStats struct {
+	lookups atomic.Uint64
+	hedged  atomic.Uint64
+	won     atomic.Uint64
+}
+
+// Record counts result.
+func (s *HedgeStats) Record(result HedgeResult) {
+	s.lookups.Add(1)
+	if result.Hedged {
+		s.hedged.Add(1)
+	}
+	if result.HedgeWon {
+		s.won.Add(1)
+	}
+}
+
+// Lookups returns the number of lookups that could be hedged, Hedged the number that were, and
+// Won the number that took the reply of the hedge.
+func (s *HedgeStats) Lookups() uint64 { return s.lookups.Load() }
+func (s *HedgeStats) Hedged() uint64  { return s.hedged.Load() }
+func (s *HedgeStats) Won() uint64     { return s.won.Load() }
+
+// HedgeRate returns the fraction of lookups that were hedged.
+func (s *HedgeStats) HedgeRate() float64 {
+	lookups := s.lookups.Load()
+	if lookups == 0 {
+		return 0
+	}
+	return float64(s.hedged.Load()) / float64(lookups)
+}
+
+type hedgeTransport struct {
+	Transport
+	hedge  Transport
+	policy HedgePolicy
+	// replies holds the buffers that the submissions of a hedged lookup write into, since the
+	// losing one may still write after the lookup has returned.
+	replies sync.Pool
+}
+
+func newHedgeTransport(transport Transport, hedge Transport, policy HedgePolicy) *hedgeTransport {
+	if policy.Delay <= 0 {
+		policy.Delay = hedgeDelayDefault
+	}
+	return &hedgeTransport{
+		Transport: transport,
+		hedge:     hedge,
+		policy:    policy,
+		replies: sync.Pool{New: func() any {
+			reply := make([]byte, types.MessageSizeMax)
+			return &reply
+		}},
+	}
+}
+
+type hedgeReply struct {
+	buffer *[]byte
+	size   int
+	err    error
+	hedge  bool
+}
+
+func (t *hedgeTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	switch op {
+	case types.OperationLookupAccounts, types.OperationLookupTransfers,
+		types.OperationGetAccountTransfers, types.OperationGetAccountHistory:
+	default:
+		return t.Transport.Submit(op, events, reply)
+	}
+
+	// Both submissions outlive the lookup if it returns with the first reply, so they get their
+	// own copy of the events and their own reply buffer.
+	start := time.Now()
+	events = slices.Clone(events)
+	replies := make(chan hedgeReply, 2)
+	submit := func(transport Transport, hedge bool) {
+		buffer := t.replies.Get().(*[]byte)
+		if len(*buffer) < len(reply) {
+			// A reply longer than a message, as for an Into lookup with a large buffer, gets a
+			// buffer of its own.
+			t.replies.Put(buffer)
+			fresh := make([]byte, len(reply))
+			buffer = &fresh
+		}
+		size, err := transport.Submit(op, events, (*buffer)[:len(reply)])
+		replies <- hedgeReply{buffer: buffer, size: size, err: err, hedge: hedge}
+	}
+
+	go submit(t.Transport, false)
+	pending := 1
+	hedged := false
+
+	timer := time.NewTimer(t.policy.Delay)
+	defer timer.Stop()
+
+	var first hedgeReply
+	select {
+	case first = <-replies:
+		pending--
+	case <-timer.C:
+		go submit(t.hedge, true)
+		pending++
+		hedged = true
+		first = <-replies
+		pending--
+		if first.err != nil {
+			// Take the other reply instead, and its error if it fails too.
+			t.replies.Put(first.buffer)
+			first = <-replies
+			pending--
+		}
+	}
+
+	if pending > 0 {
+		go func() {
+			t.replies.Put((<-replies).buffer)
+		}()
+	}
+
+	size := copy(reply, (*first.buffer)[:first.size])
+	t.replies.Put(first.buffer)
+
+	if t.policy.OnResult != nil {
+		t.policy.OnResult(HedgeResult{
+			Operation: op,
+			Hedged:    hedged,
+			HedgeWon:  first.hedge,
+			Latency:   time.Since(start),
+		})
+	}
+	return size, first.err
+}
+
+func (t *hedgeTransport) Close() {
+	t.Transport.Close()
+	if t.hedge != t.Transport {
+		t.hedge.Close()
+	}
+}
@@ -0,0 +1,74 @@
+package tigerbeetle_go
+
+import (
+	"syscall/js"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// NewJSTransport returns a Transport for a client compiled to js/wasm, which submits requests
+// through submit, a JavaScript function of the host, for use with WithTransport:
+//
+//	submit(operation: number, events: Uint8Array, replySizeMax: number): Promise<Uint8Array>
+//
+// It submits the events in their wire layout to the cluster, as through a native client or a
+// relay, and resolves with the results. It rejects with an object whose numeric status is as
+// for NewHostTransport, or with any other reason, which fails the request with ErrHost.
+//
+// Requests wait for the promise to settle, which needs the JavaScript event loop to run: they
+// must not be submitted from the callback of a JavaScript function, but from a goroutine.
+func NewJSTransport(submit js.Value) Transport {
+	return &jsTransport{submit: submit}
+}
+
+type jsTransport struct {
+	hostClosing
+	submit js.Value
+}
+
+func (t *jsTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	if t.closed {
+		return 0, errors.ErrClientClosed{}
+	}
+
+	array := js.Global().Get("Uint8Array").New(len(events))
+	js.CopyBytesToJS(array, events)
+
+	type settled struct {
+		results js.Value
+		err     error
+	}
+	done := make(chan settled, 1)
+	resolve := js.FuncOf(func(this js.Value, args []js.Value) any {
+		done <- settled{results: args[0]}
+		return nil
+	})
+	defer resolve.Release()
+	reject := js.FuncOf(func(this js.Value, args []js.Value) any {
+		done <- settled{err: jsError(args[0])}
+		return nil
+	})
+	defer reject.Release()
+
+	t.submit.Invoke(int(op), array, len(reply)).Call("then", resolve, reject)
+	result := <-done
+	if result.err != nil {
+		return 0, result.err
+	}
+	if result.results.Get("length").Int() > len(reply) {
+		return 0, errors.ErrUnexpected{}
+	}
+	return js.CopyBytesToGo(reply, result.results), nil
+}
+
+func jsError(reason js.Value) error {
+	if reason.Type() == js.TypeObject {
+		if status := reason.Get("status"); status.Type() == js.TypeNumber {
+			return hostStatusError(int64(status.Int()))
+		}
+	}
+	return errors.ErrHost{Message: js.Global().Get("String").Invoke(reason).String()}
+}
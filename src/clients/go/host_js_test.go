@@ -0,0 +1,47 @@
+package tigerbeetle_go
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func TestJSTransport(t *testing.T) {
+	account := types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1}
+	encoded := types.EncodeAccounts([]types.Account{account})
+
+	submit := js.FuncOf(func(this js.Value, args []js.Value) any {
+		promise := js.Global().Get("Promise")
+		switch types.Operation(args[0].Int()) {
+		case types.OperationLookupAccounts:
+			results := js.Global().Get("Uint8Array").New(len(encoded))
+			js.CopyBytesToJS(results, encoded)
+			return promise.Call("resolve", results)
+		case types.OperationLookupTransfers:
+			return promise.Call("reject", map[string]any{"status": hostStatusClosed})
+		}
+		return promise.Call("reject", "unavailable")
+	})
+	defer submit.Release()
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(NewJSTransport(submit.Value)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	accounts, err := client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []types.Account{account}, accounts)
+
+	_, err = client.LookupTransfers([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, errors.ErrClientClosed{}, err)
+	_, err = client.CreateAccounts([]types.Account{account})
+	assert.Equal(t, errors.ErrHost{Message: "unavailable"}, err)
+
+	_, err = NewClient(types.ToUint128(0), []string{"3000"}, 1)
+	assert.Equal(t, errors.ErrNativeUnavailable{}, err)
+}
@@ -0,0 +1,66 @@
+package tigerbeetle_go
+
+import (
+	"unsafe"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// hostSubmit is the submit function that the host imports into the "tigerbeetle" module. It
+// submits the events of op, and writes the results into reply once the cluster has replied,
+// returning their size, or a negative hostStatus.
+//
+//go:wasmimport tigerbeetle submit
+func hostSubmit(
+	op uint32,
+	events unsafe.Pointer,
+	eventsLen uint32,
+	reply unsafe.Pointer,
+	replyLen uint32,
+) int64
+
+// NewHostTransport returns a Transport for a client compiled to wasip1, which submits requests
+// through the host of the module rather than tb_client, for use with WithTransport. The host
+// provides the function
+//
+//	(import "tigerbeetle" "submit"
+//	    (func (param $op i32) (param $events i32) (param $events_len i32)
+//	          (param $reply i32) (param $reply_len i32) (result i64)))
+//
+// which submits the events in their wire layout to the cluster, typically with a native
+// client, and writes the results into reply, returning their size, or the negated status of
+// the failure: 1 for too much data, 2 for an invalid operation, 3 for too many requests in
+// flight, or 4 once the host's client is closed.
+//
+// Calling the host blocks the module, so requests are submitted one at a time.
+func NewHostTransport() Transport {
+	return &hostTransport{}
+}
+
+type hostTransport struct {
+	hostClosing
+}
+
+func (t *hostTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	if t.closed {
+		return 0, errors.ErrClientClosed{}
+	}
+
+	wrote := hostSubmit(
+		uint32(op),
+		unsafe.Pointer(unsafe.SliceData(events)),
+		uint32(len(events)),
+		unsafe.Pointer(unsafe.SliceData(reply)),
+		uint32(len(reply)),
+	)
+	if wrote < 0 {
+		return 0, hostStatusError(-wrote)
+	}
+	if wrote > int64(len(reply)) {
+		return 0, errors.ErrUnexpected{}
+	}
+	return int(wrote), nil
+}
@@ -0,0 +1,43 @@
+package tigerbeetle_go
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+)
+
+// The statuses that the host of a WebAssembly client fails a request with, instead of replying
+// with the results.
+const (
+	hostStatusTooMuchData         = 1
+	hostStatusInvalidOperation    = 2
+	hostStatusConcurrencyExceeded = 3
+	hostStatusClosed              = 4
+)
+
+func hostStatusError(status int64) error {
+	switch status {
+	case hostStatusTooMuchData:
+		return errors.ErrMaximumBatchSizeExceeded{}
+	case hostStatusInvalidOperation:
+		return errors.ErrInvalidOperation{}
+	case hostStatusConcurrencyExceeded:
+		return errors.ErrConcurrencyExceeded{}
+	case hostStatusClosed:
+		return errors.ErrClientClosed{}
+	}
+	return errors.ErrHost{Message: "status " + strconv.FormatInt(status, 10)}
+}
+
+// hostClosing fails the requests submitted to a host transport once it is closed.
+type hostClosing struct {
+	mutex  sync.RWMutex
+	closed bool
+}
+
+func (c *hostClosing) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.closed = true
+}
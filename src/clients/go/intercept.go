@@ -0,0 +1,309 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Invoker carries on a request past an interceptor, through the next interceptors and then to
+// the cluster.
+type Invoker func(ctx context.Context, op types.Operation, events any) (any, error)
+
+// Interceptor sees every request of a client, to log it, measure it, check it, change its
+// events or answer it on its own. It calls next to carry on the request, or returns without
+// calling it to short-circuit it.
+//
+// The events and the results are typed by operation:
+//   - OperationCreateAccounts: []types.Account, answered by []types.AccountEventResult.
+//   - OperationCreateTransfers: []types.Transfer, answered by []types.TransferEventResult.
+//   - OperationLookupAccounts: []types.Uint128, answered by []types.Account.
+//   - OperationLookupTransfers: []types.Uint128, answered by []types.Transfer.
+//   - OperationGetAccountTransfers: types.AccountFilter, answered by []types.Transfer.
+//   - OperationGetAccountHistory: types.AccountFilter, answered by []types.AccountBalance.
+//   - Any operation of SubmitRaw: its encoded body, answered by the encoded results, as []byte.
+//
+// The events belong to the caller: to change them, pass a changed copy to next. Results of the
+// wrong type fail the request with ErrInterceptorResult, and events of the wrong type with
+// ErrInterceptorEvents. Ping goes through the interceptors as a lookup, unlike Nop.
+type Interceptor func(ctx context.Context, op types.Operation, events any, next Invoker) (any, error)
+
+// WithInterceptor adds interceptor to the requests of the client. Interceptors run in the order
+// they were added, the first one seeing the request first and the reply last. They run on the
+// goroutine of the request, before it is admitted: a request held back by Pause or waiting for
+// a request slot has already been through them.
+//
+// The context of the requests is context.Background(), unless they are made through a view of
+// the client returned by ClientWithContext.
+func WithInterceptor(interceptor Interceptor) ClientOption {
+	return func(options *clientOptions) {
+		options.interceptors = append(options.interceptors, interceptor)
+	}
+}
+
+// ClientWithContext returns a view of client whose requests pass ctx to its interceptors, such
+// as to tell them whom the requests are made on behalf of. The view shares the client, and
+// closing it closes the client. Without interceptors, client itself is returned.
+func ClientWithContext(client Client, ctx context.Context) Client {
+	switch view := client.(type) {
+	case *timeoutClient:
+		return &timeoutClient{client: ClientWithContext(view.client, ctx), timeout: view.timeout}
+	case *interceptClient:
+		return &interceptClient{client: view.client, invoke: view.invoke, ctx: ctx}
+	}
+	return client
+}
+
+type interceptClient struct {
+	client Client
+	invoke func(ctx context.Context, op types.Operation, events any, submit Invoker) (any, error)
+	ctx    context.Context
+}
+
+// newInterceptClient returns a client that runs the requests of client through interceptors.
+func newInterceptClient(client Client, interceptors []Interceptor) *interceptClient {
+	invoke := func(ctx context.Context, op types.Operation, events any, submit Invoker) (any, error) {
+		return submit(ctx, op, events)
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoke
+		invoke = func(ctx context.Context, op types.Operation, events any, submit Invoker) (any, error) {
+			return interceptor(ctx, op, events, func(ctx context.Context, op types.Operation, events any) (any, error) {
+				return next(ctx, op, events, submit)
+			})
+		}
+	}
+	return &interceptClient{client: client, invoke: invoke, ctx: context.Background()}
+}
+
+// intercept runs a request with events of type E through the interceptors, and submits it with
+// submit.
+func intercept[E, R any](c *interceptClient, op types.Operation, events E, submit func(events E) (R, error)) (R, error) {
+	var zero R
+	result, err := c.invoke(c.ctx, op, events, func(ctx context.Context, op types.Operation, events any) (any, error) {
+		typed, ok := events.(E)
+		if !ok {
+			return nil, errors.ErrInterceptorEvents{Operation: op, Events: events}
+		}
+		return submit(typed)
+	})
+	if err != nil {
+		return zero, err
+	}
+	if result == nil {
+		return zero, nil
+	}
+	typed, ok := result.(R)
+	if !ok {
+		return zero, errors.ErrInterceptorResult{Operation: op, Result: result}
+	}
+	return typed, nil
+}
+
+// interceptAsync runs a request through the interceptors on a goroutine of its own, since they
+// may wait for the reply, and returns the future of its reply.
+func interceptAsync[E, R any](
+	c *interceptClient,
+	op types.Operation,
+	events E,
+	submit func(events E) (*Future[R], error),
+) *Future[R] {
+	future := newFuture[R]()
+	go func() {
+		result, err := intercept(c, op, events, func(events E) (R, error) {
+			submitted, err := submit(events)
+			if err != nil {
+				var zero R
+				return zero, err
+			}
+			return submitted.Wait(context.Background())
+		})
+		future.resolve(result, err)
+	}()
+	return future
+}
+
+func (c *interceptClient) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return intercept(c, types.OperationCreateAccounts, accounts, c.client.CreateAccounts)
+}
+
+func (c *interceptClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return intercept(c, types.OperationCreateTransfers, transfers, c.client.CreateTransfers)
+}
+
+func (c *interceptClient) TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return intercept(c, types.OperationCreateAccounts, accounts, c.client.TryCreateAccounts)
+}
+
+func (c *interceptClient) TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return intercept(c, types.OperationCreateTransfers, transfers, c.client.TryCreateTransfers)
+}
+
+// CreateAccountsAsync and CreateTransfersAsync return before the request is submitted, as the
+// interceptors run first, so that a request that can't be submitted fails through the future.
+func (c *interceptClient) CreateAccountsAsync(
+	accounts []types.Account,
+) (*Future[[]types.AccountEventResult], error) {
+	return interceptAsync(c, types.OperationCreateAccounts, accounts, c.client.CreateAccountsAsync), nil
+}
+
+func (c *interceptClient) CreateTransfersAsync(
+	transfers []types.Transfer,
+) (*Future[[]types.TransferEventResult], error) {
+	return interceptAsync(c, types.OperationCreateTransfers, transfers, c.client.CreateTransfersAsync), nil
+}
+
+func (c *interceptClient) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	return intercept(c, types.OperationLookupAccounts, accountIDs, c.client.LookupAccounts)
+}
+
+func (c *interceptClient) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	return intercept(c, types.OperationLookupTransfers, transferIDs, c.client.LookupTransfers)
+}
+
+func (c *interceptClient) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	return intercept(c, types.OperationGetAccountTransfers, filter, c.client.GetAccountTransfers)
+}
+
+func (c *interceptClient) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	return intercept(c, types.OperationGetAccountHistory, filter, c.client.GetAccountHistory)
+}
+
+func (c *interceptClient) LookupAccountsInto(
+	accountIDs []types.Uint128,
+	buf []types.Account,
+) ([]types.Account, error) {
+	return intercept(c, types.OperationLookupAccounts, accountIDs, func(accountIDs []types.Uint128) ([]types.Account, error) {
+		return c.client.LookupAccountsInto(accountIDs, buf)
+	})
+}
+
+func (c *interceptClient) LookupTransfersInto(
+	transferIDs []types.Uint128,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return intercept(c, types.OperationLookupTransfers, transferIDs, func(transferIDs []types.Uint128) ([]types.Transfer, error) {
+		return c.client.LookupTransfersInto(transferIDs, buf)
+	})
+}
+
+func (c *interceptClient) GetAccountTransfersInto(
+	filter types.AccountFilter,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return intercept(c, types.OperationGetAccountTransfers, filter, func(filter types.AccountFilter) ([]types.Transfer, error) {
+		return c.client.GetAccountTransfersInto(filter, buf)
+	})
+}
+
+func (c *interceptClient) GetAccountHistoryInto(
+	filter types.AccountFilter,
+	buf []types.AccountBalance,
+) ([]types.AccountBalance, error) {
+	return intercept(c, types.OperationGetAccountHistory, filter, func(filter types.AccountFilter) ([]types.AccountBalance, error) {
+		return c.client.GetAccountHistoryInto(filter, buf)
+	})
+}
+
+func (c *interceptClient) CreateAccount(account types.Account) error {
+	return createAccount(c, account)
+}
+
+func (c *interceptClient) CreateTransfer(transfer types.Transfer) error {
+	return createTransfer(c, transfer)
+}
+
+func (c *interceptClient) LookupAccount(accountID types.Uint128) (types.Account, bool, error) {
+	return lookupAccount(c, accountID)
+}
+
+func (c *interceptClient) LookupAccountsStream(
+	accountIDs iter.Seq[types.Uint128],
+) iter.Seq2[types.Account, error] {
+	return lookupAccountsStream(c, accountIDs)
+}
+
+func (c *interceptClient) StreamChanges(
+	ctx context.Context,
+	fromTimestamp uint64,
+	accountIDs []types.Uint128,
+) <-chan ChangeEvent {
+	return streamChanges(ctx, c, fromTimestamp, accountIDs)
+}
+
+func (c *interceptClient) TailTransfers(
+	ctx context.Context,
+	accountID types.Uint128,
+	sinceTimestamp uint64,
+) <-chan TailEvent {
+	return tailTransfers(ctx, c, accountID, sinceTimestamp)
+}
+
+func (c *interceptClient) WatchBalance(
+	ctx context.Context,
+	accountID types.Uint128,
+	options WatchBalanceOptions,
+) <-chan BalanceSnapshot {
+	return watchBalance(ctx, c, accountID, options)
+}
+
+func (c *interceptClient) PostPending(pendingID types.Uint128, amount types.Uint128) error {
+	return createTransfer(c, types.PostPendingTransfer(pendingID, amount))
+}
+
+func (c *interceptClient) VoidPending(pendingID types.Uint128) error {
+	return createTransfer(c, types.VoidPendingTransfer(pendingID))
+}
+
+func (c *interceptClient) SubmitRaw(op types.Operation, body []byte) ([]byte, error) {
+	return intercept(c, op, body, func(body []byte) ([]byte, error) {
+		return c.client.SubmitRaw(op, body)
+	})
+}
+
+func (c *interceptClient) MessageSizeMax() int {
+	return c.client.MessageSizeMax()
+}
+
+func (c *interceptClient) UpdateAddresses(addresses []string) error {
+	return c.client.UpdateAddresses(addresses)
+}
+
+func (c *interceptClient) Pause(ctx context.Context, mode PauseMode) error {
+	return c.client.Pause(ctx, mode)
+}
+
+func (c *interceptClient) Resume() {
+	c.client.Resume()
+}
+
+func (c *interceptClient) State() ConnectionState {
+	return c.client.State()
+}
+
+func (c *interceptClient) Stats() Stats {
+	return c.client.Stats()
+}
+
+func (c *interceptClient) Ping(ctx context.Context) (time.Duration, error) {
+	return ping(ctx, c)
+}
+
+func (c *interceptClient) Nop() error {
+	return c.client.Nop()
+}
+
+func (c *interceptClient) Close() {
+	c.client.Close()
+}
+
+func (c *interceptClient) CloseContext(ctx context.Context) error {
+	return c.client.CloseContext(ctx)
+}
+
+func (c *interceptClient) Done() <-chan struct{} {
+	return c.client.Done()
+}
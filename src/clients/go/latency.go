@@ -0,0 +1,206 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"log/slog"
+	"math/bits"
+	"sync/atomic"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// latencyBucketsMax is the number of buckets of a LatencyHistogram: bucket i counts the
+// requests that took less than 2^i microseconds, with the last one counting all the slower ones
+// from about 17 minutes up.
+const latencyBucketsMax = 31
+
+// LatencyMonitor measures how long the requests of a client take, from their submission to
+// their completion, including any retries, into a histogram per operation, and logs the
+// requests slower than a threshold. Measuring and logging can be turned on and off at any time,
+// and one monitor may be shared by several clients to aggregate them.
+type LatencyMonitor struct {
+	disabled      atomic.Bool
+	slowThreshold atomic.Int64
+	histograms    [256]latencyHistogram
+}
+
+type latencyHistogram struct {
+	count   atomic.Uint64
+	sum     atomic.Int64
+	max     atomic.Int64
+	buckets [latencyBucketsMax]atomic.Uint64
+}
+
+// NewLatencyMonitor returns an enabled monitor that logs the requests that take slowThreshold
+// or longer, or none if slowThreshold is zero.
+func NewLatencyMonitor(slowThreshold time.Duration) *LatencyMonitor {
+	monitor := &LatencyMonitor{}
+	monitor.slowThreshold.Store(int64(slowThreshold))
+	return monitor
+}
+
+// WithLatencyMonitor makes the client report the latency of its requests to monitor. Slow
+// requests are logged as a warning to the logger of WithLogger, or to slog.Default().
+func WithLatencyMonitor(monitor *LatencyMonitor) ClientOption {
+	return func(options *clientOptions) {
+		options.latencyMonitor = monitor
+	}
+}
+
+// SetEnabled turns the measurement of requests, and the logging of slow ones, on or off.
+func (m *LatencyMonitor) SetEnabled(enabled bool) {
+	m.disabled.Store(!enabled)
+}
+
+// Enabled reports whether requests are being measured.
+func (m *LatencyMonitor) Enabled() bool {
+	return !m.disabled.Load()
+}
+
+// SetSlowThreshold changes the latency from which requests are logged, with zero logging none.
+func (m *LatencyMonitor) SetSlowThreshold(threshold time.Duration) {
+	m.slowThreshold.Store(int64(threshold))
+}
+
+// SlowThreshold returns the latency from which requests are logged.
+func (m *LatencyMonitor) SlowThreshold() time.Duration {
+	return time.Duration(m.slowThreshold.Load())
+}
+
+// Histogram returns a snapshot of the latencies measured for op since the monitor was created
+// or last reset. Requests that complete while the snapshot is taken may be partly included.
+func (m *LatencyMonitor) Histogram(op types.Operation) LatencyHistogram {
+	histogram := &m.histograms[op]
+	snapshot := LatencyHistogram{
+		Operation: op,
+		Count:     histogram.count.Load(),
+		Sum:       time.Duration(histogram.sum.Load()),
+		Max:       time.Duration(histogram.max.Load()),
+	}
+	for i := range histogram.buckets {
+		snapshot.Buckets[i] = histogram.buckets[i].Load()
+	}
+	return snapshot
+}
+
+// Reset clears the histograms of every operation.
+func (m *LatencyMonitor) Reset() {
+	for i := range m.histograms {
+		histogram := &m.histograms[i]
+		histogram.count.Store(0)
+		histogram.sum.Store(0)
+		histogram.max.Store(0)
+		for j := range histogram.buckets {
+			histogram.buckets[j].Store(0)
+		}
+	}
+}
+
+func (m *LatencyMonitor) observe(op types.Operation, latency time.Duration) {
+	histogram := &m.histograms[op]
+	histogram.count.Add(1)
+	histogram.sum.Add(int64(latency))
+	for {
+		latest := histogram.max.Load()
+		if int64(latency) <= latest || histogram.max.CompareAndSwap(latest, int64(latency)) {
+			break
+		}
+	}
+	histogram.buckets[latencyBucket(latency)].Add(1)
+}
+
+func latencyBucket(latency time.Duration) int {
+	return min(bits.Len64(uint64(latency.Microseconds())), latencyBucketsMax-1)
+}
+
+// LatencyHistogram is a snapshot of the latencies of the requests for an operation.
+type LatencyHistogram struct {
+	Operation types.Operation
+	Count     uint64
+	Sum       time.Duration
+	Max       time.Duration
+	// Buckets counts the requests by latency: Buckets[i] those that took less than
+	// LatencyBucketBound(i), and at least the bound of the previous bucket.
+	Buckets [latencyBucketsMax]uint64
+}
+
+// LatencyBucketBound returns the exclusive upper bound of the latencies counted by the bucket i
+// of a LatencyHistogram, with the last bucket unbounded.
+func LatencyBucketBound(i int) time.Duration {
+	if i >= latencyBucketsMax-1 {
+		return time.Duration(1<<63 - 1)
+	}
+	return time.Duration(1<<i) * time.Microsecond
+}
+
+// Mean returns the average latency, or zero if no request was measured.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile returns an upper bound of the latency of the fraction q of the requests, between 0
+// and 1, with the precision of the buckets: the bound of the bucket holding the quantile, or
+// Max if that is lower.
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(h.Count))
+	rank = max(rank, 1)
+	var seen uint64
+	for i, count := range h.Buckets {
+		seen += count
+		if seen >= rank {
+			return min(LatencyBucketBound(i), h.Max)
+		}
+	}
+	return h.Max
+}
+
+type latencyTransport struct {
+	Transport
+	monitor *LatencyMonitor
+	logger  *slog.Logger
+}
+
+func newLatencyTransport(inner Transport, monitor *LatencyMonitor, logger *slog.Logger) *latencyTransport {
+	return &latencyTransport{Transport: inner, monitor: monitor, logger: logger}
+}
+
+func (t *latencyTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	if !t.monitor.Enabled() {
+		return t.Transport.Submit(op, events, reply)
+	}
+
+	start := time.Now()
+	wrote, err := t.Transport.Submit(op, events, reply)
+	latency := time.Since(start)
+	t.monitor.observe(op, latency)
+
+	if threshold := t.monitor.SlowThreshold(); threshold > 0 && latency >= threshold {
+		logger := t.logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		attrs := []slog.Attr{
+			slog.String("operation", op.String()),
+			slog.Duration("duration", latency),
+		}
+		if size := types.EventSize(op); size > 0 {
+			attrs = append(attrs, slog.Int("batch_size", len(events)/size))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		} else if size := types.ResultSize(op); size > 0 {
+			// Creates only reply with the events that failed, and lookups and queries with what
+			// they found.
+			attrs = append(attrs, slog.Int("results", wrote/size))
+		}
+		logger.LogAttrs(context.Background(), slog.LevelWarn, "slow request", attrs...)
+	}
+	return wrote, err
+}
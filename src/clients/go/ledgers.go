@@ -0,0 +1,82 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"slices"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// TenantLedgers returns an interceptor that confines the requests of every tenant to the ledgers
+// that allowed returns for it, for platforms that give each tenant ledgers of its own on a shared
+// cluster. The tenant of a request is set in its context with ContextWithTenant, through a view
+// of the client returned by ClientWithContext. allowed may be called concurrently; a static
+// allowlist is func(tenant types.Uint128) []uint32 { return ledgers[tenant] }.
+//
+//   - Accounts and transfers created must be on an allowed ledger, otherwise the request fails
+//     with ErrLedgerNotAllowed before anything is submitted. The cluster checks that the
+//     accounts of a transfer are on its ledger, so transfers can't reach other ledgers either.
+//     Post and void transfers must carry the ledger of their pending transfer, rather than zero.
+//   - Creating without a tenant fails with ErrTenantMissing.
+//   - Lookups and GetAccountTransfers leave out the accounts and transfers on other ledgers, as
+//     if they didn't exist, and a request without a tenant sees none. Ping still reaches the
+//     cluster.
+//   - GetAccountHistory, whose balances don't tell their ledger, and SubmitRaw fail with
+//     ErrTenantOperation.
+func TenantLedgers(allowed func(tenant types.Uint128) []uint32) Interceptor {
+	return func(ctx context.Context, op types.Operation, events any, next Invoker) (any, error) {
+		tenant, ok := TenantFromContext(ctx)
+		var ledgers []uint32
+		if ok {
+			ledgers = allowed(tenant)
+		}
+		denied := func(ledger uint32) bool { return !slices.Contains(ledgers, ledger) }
+
+		switch events := events.(type) {
+		case []types.Account:
+			if !ok {
+				return nil, errors.ErrTenantMissing{}
+			}
+			for i, account := range events {
+				if denied(account.Ledger) {
+					return nil, errors.ErrLedgerNotAllowed{Index: i, Ledger: account.Ledger}
+				}
+			}
+			return next(ctx, op, events)
+		case []types.Transfer:
+			if !ok {
+				return nil, errors.ErrTenantMissing{}
+			}
+			for i, transfer := range events {
+				if denied(transfer.Ledger) {
+					return nil, errors.ErrLedgerNotAllowed{Index: i, Ledger: transfer.Ledger}
+				}
+			}
+			return next(ctx, op, events)
+		case []types.Uint128:
+		case types.AccountFilter:
+			if op != types.OperationGetAccountTransfers {
+				return nil, errors.ErrTenantOperation{Operation: op}
+			}
+		default:
+			return nil, errors.ErrTenantOperation{Operation: op}
+		}
+
+		result, err := next(ctx, op, events)
+		if err != nil {
+			return nil, err
+		}
+		switch result := result.(type) {
+		case []types.Account:
+			return slices.DeleteFunc(result, func(account types.Account) bool {
+				return denied(account.Ledger)
+			}), nil
+		case []types.Transfer:
+			return slices.DeleteFunc(result, func(transfer types.Transfer) bool {
+				return denied(transfer.Ledger)
+			}), nil
+		}
+		return result, nil
+	}
+}
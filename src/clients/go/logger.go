@@ -0,0 +1,71 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+// nativeLogger receives the logs of tb_client, which are shared by every client in the process.
+var nativeLogger atomic.Pointer[slog.Logger]
+
+// WithLogger sends the client's logs to logger, including those of tb_client about reconnects,
+// evictions and protocol errors, which are otherwise written to stderr.
+//
+// tb_client logs for the whole process rather than per client, so they go to the logger of the
+// most recently created client that has one.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(options *clientOptions) {
+		options.logger = logger
+	}
+}
+
+// Levels of tb_client logs, as in TB_LOG_LEVEL.
+const (
+	nativeLogErr = iota
+	nativeLogWarn
+	nativeLogInfo
+	nativeLogDebug
+)
+
+func logNative(level int, message string) {
+	noteNativeLog(level, message)
+
+	logger := nativeLogger.Load()
+	if logger == nil {
+		// The callback is registered for a ConnectionMonitor alone, so keep tb_client's logs on
+		// stderr as they would otherwise be.
+		fmt.Fprintln(os.Stderr, message)
+		return
+	}
+
+	slogLevel := slog.LevelDebug
+	switch level {
+	case nativeLogErr:
+		slogLevel = slog.LevelError
+	case nativeLogWarn:
+		slogLevel = slog.LevelWarn
+	case nativeLogInfo:
+		slogLevel = slog.LevelInfo
+	}
+	logger.Log(context.Background(), slogLevel, message, "source", "tb_client")
+}
+
+// logRetries makes policy also log every failed attempt to logger.
+func logRetries(policy RetryPolicy, logger *slog.Logger) RetryPolicy {
+	onAttempt := policy.OnAttempt
+	policy.OnAttempt = func(attempt RetryAttempt) {
+		logger.Warn("request failed",
+			"operation", attempt.Operation.String(),
+			"attempt", attempt.Attempt,
+			"error", attempt.Err,
+			"backoff", attempt.Backoff,
+		)
+		if onAttempt != nil {
+			onAttempt(attempt)
+		}
+	}
+	return policy
+}
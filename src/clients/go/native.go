@@ -0,0 +1,330 @@
+package tigerbeetle_go
+
+/*
+#cgo CFLAGS: -g -Wall
+#cgo darwin,arm64 LDFLAGS: ${SRCDIR}/pkg/native/aarch64-macos/libtb_client.a -ldl -lm
+#cgo darwin,amd64 LDFLAGS: ${SRCDIR}/pkg/native/x86_64-macos/libtb_client.a -ldl -lm
+#cgo linux,arm64 LDFLAGS: ${SRCDIR}/pkg/native/aarch64-linux/libtb_client.a -ldl -lm
+#cgo linux,amd64 LDFLAGS: ${SRCDIR}/pkg/native/x86_64-linux/libtb_client.a -ldl -lm
+#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/pkg/native/x86_64-windows -ltb_client -lws2_32 -lntdll
+
+#include <stdlib.h>
+#include <string.h>
+#include "./pkg/native/tb_client.h"
+
+#ifndef __declspec
+	#define __declspec(x)
+#endif
+
+typedef const uint8_t* tb_result_bytes_t;
+
+extern __declspec(dllexport) void onGoPacketCompletion(
+	uintptr_t ctx,
+	tb_client_t client,
+	tb_packet_t* packet,
+	tb_result_bytes_t result_ptr,
+	uint32_t result_len
+);
+
+extern __declspec(dllexport) void onGoLog(
+	TB_LOG_LEVEL level,
+	tb_result_bytes_t message_ptr,
+	uint32_t message_len
+);
+*/
+import "C"
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// request is a packet in flight, completed by onGoPacketCompletion.
+type request struct {
+	packet  *C.tb_packet_t
+	session *nativeSession
+	events  []byte
+	reply   []byte
+	done    func(wrote int, err error)
+
+	// pinner keeps the request and its events in place while tb_client refers to them.
+	pinner runtime.Pinner
+}
+
+var registerNativeLogCallback sync.Once
+
+// registerNativeLog sends the logs of tb_client to logNative.
+func registerNativeLog() {
+	registerNativeLogCallback.Do(func() {
+		C.tb_client_register_log_callback((*[0]byte)(C.onGoLog))
+	})
+}
+
+// nativeTransport submits requests through tb_client, which owns the TCP connections to the
+// replicas and the session with the cluster.
+type nativeTransport struct {
+	clusterID      types.Uint128
+	concurrencyMax uint
+
+	// session is replaced by updateAddresses. Requests hold on to the session they were
+	// submitted to, which is only deinitialized once they have completed.
+	mutex   sync.RWMutex
+	session *nativeSession
+}
+
+type nativeSession struct {
+	tb_client C.tb_client_t
+	inflight  sync.WaitGroup
+}
+
+func newNativeTransport(
+	clusterID types.Uint128,
+	addresses []string,
+	concurrencyMax uint,
+) (*nativeTransport, error) {
+	session, err := newNativeSession(clusterID, addresses, concurrencyMax)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nativeTransport{
+		clusterID:      clusterID,
+		concurrencyMax: concurrencyMax,
+		session:        session,
+	}, nil
+}
+
+func newNativeSession(
+	clusterID types.Uint128,
+	addresses []string,
+	concurrencyMax uint,
+) (*nativeSession, error) {
+	// Allocate a cstring of the addresses joined with ",".
+	addresses_raw := strings.Join(addresses[:], ",")
+	c_addresses := C.CString(addresses_raw)
+	defer C.free(unsafe.Pointer(c_addresses))
+
+	var tb_client C.tb_client_t
+
+	// Create the tb_client.
+	status := C.tb_client_init(
+		&tb_client,
+		C.tb_uint128_t(clusterID),
+		c_addresses,
+		C.uint32_t(len(addresses_raw)),
+		C.uint32_t(concurrencyMax),
+		C.uintptr_t(0), // on_completion_ctx
+		(*[0]byte)(C.onGoPacketCompletion),
+	)
+
+	if status != C.TB_STATUS_SUCCESS {
+		switch status {
+		case C.TB_STATUS_UNEXPECTED:
+			return nil, errors.ErrUnexpected{}
+		case C.TB_STATUS_OUT_OF_MEMORY:
+			return nil, errors.ErrOutOfMemory{}
+		case C.TB_STATUS_ADDRESS_INVALID:
+			return nil, errors.ErrInvalidAddress{}
+		case C.TB_STATUS_ADDRESS_LIMIT_EXCEEDED:
+			return nil, errors.ErrAddressLimitExceeded{}
+		case C.TB_STATUS_CONCURRENCY_MAX_INVALID:
+			return nil, errors.ErrInvalidConcurrencyMax{}
+		case C.TB_STATUS_SYSTEM_RESOURCES:
+			return nil, errors.ErrSystemResources{}
+		case C.TB_STATUS_NETWORK_SUBSYSTEM:
+			return nil, errors.ErrNetworkSubsystem{}
+		default:
+			panic("tb_client_init(): invalid error code")
+		}
+	}
+
+	return &nativeSession{tb_client: tb_client}, nil
+}
+
+func (t *nativeTransport) Close() {
+	t.mutex.Lock()
+	session := t.session
+	t.session = nil
+	t.mutex.Unlock()
+
+	if session != nil {
+		session.inflight.Wait()
+		C.tb_client_deinit(session.tb_client)
+	}
+}
+
+// updateAddresses submits new requests to a new session with addresses, and closes the
+// previous session in the background once its requests have completed.
+func (t *nativeTransport) updateAddresses(addresses []string) error {
+	session, err := newNativeSession(t.clusterID, addresses, t.concurrencyMax)
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	previous := t.session
+	t.session = session
+	t.mutex.Unlock()
+
+	if previous != nil {
+		go func() {
+			previous.inflight.Wait()
+			C.tb_client_deinit(previous.tb_client)
+		}()
+	}
+	return nil
+}
+
+func (t *nativeTransport) Submit(
+	op types.Operation,
+	events []byte,
+	reply []byte,
+) (int, error) {
+	var wrote int
+	var err error
+	ready := make(chan struct{})
+	t.SubmitAsync(op, events, reply, func(w int, e error) {
+		wrote, err = w, e
+		close(ready)
+	})
+	<-ready
+	return wrote, err
+}
+
+// SubmitAsync submits the request and returns once tb_client has taken it, calling done from
+// the completion of its packet, on the thread of tb_client, so that no goroutine waits on it.
+func (t *nativeTransport) SubmitAsync(
+	op types.Operation,
+	events []byte,
+	reply []byte,
+	done func(wrote int, err error),
+) {
+	t.mutex.RLock()
+	session := t.session
+	if session == nil {
+		t.mutex.RUnlock()
+		done(0, errors.ErrClientClosed{})
+		return
+	}
+	session.inflight.Add(1)
+	t.mutex.RUnlock()
+
+	req := &request{session: session, events: events, reply: reply, done: done}
+	switch acquire_status := C.tb_client_acquire_packet(session.tb_client, &req.packet); acquire_status {
+	case C.TB_PACKET_ACQUIRE_CONCURRENCY_MAX_EXCEEDED:
+		session.inflight.Done()
+		done(0, errors.ErrConcurrencyExceeded{})
+		return
+	case C.TB_PACKET_ACQUIRE_SHUTDOWN:
+		session.inflight.Done()
+		done(0, errors.ErrClientClosed{})
+		return
+	default:
+		if req.packet == nil {
+			panic("tb_client_acquire_packet(): returned null packet")
+		}
+	}
+
+	req.pinner.Pin(req)
+	req.packet.user_data = unsafe.Pointer(req)
+	req.packet.operation = C.uint8_t(op)
+	req.packet.status = C.TB_PACKET_OK
+	req.packet.data_size = C.uint32_t(len(events))
+	req.packet.data = nil
+	if len(events) > 0 {
+		req.pinner.Pin(&events[0])
+		req.packet.data = unsafe.Pointer(&events[0])
+	}
+
+	// Submit the request, to be completed by onGoPacketCompletion.
+	C.tb_client_submit(session.tb_client, req.packet)
+}
+
+// complete releases the packet of the request, with the status and size tb_client completed it
+// with, and calls its done.
+func (req *request) complete() {
+	status := C.TB_PACKET_STATUS(req.packet.status)
+	wrote := int(req.packet.data_size)
+
+	// Release the packet for other requests to use.
+	C.tb_client_release_packet(req.session.tb_client, req.packet)
+	req.pinner.Unpin()
+	req.session.inflight.Done()
+
+	// Handle packet error
+	var err error
+	if status != C.TB_PACKET_OK {
+		wrote = 0
+		switch status {
+		case C.TB_PACKET_TOO_MUCH_DATA:
+			err = errors.ErrMaximumBatchSizeExceeded{}
+		case C.TB_PACKET_INVALID_OPERATION:
+			// we control what C.TB_OPERATION is given
+			// but allow an invalid opcode to be passed to emulate a client nop
+			err = errors.ErrInvalidOperation{}
+		case C.TB_PACKET_INVALID_DATA_SIZE:
+			panic("unreachable") // we control what type of data is given
+		default:
+			panic("tb_client_submit(): returned packet with invalid status")
+		}
+	}
+	req.done(wrote, err)
+}
+
+//export onGoLog
+func onGoLog(
+	level C.TB_LOG_LEVEL,
+	message_ptr C.tb_result_bytes_t,
+	message_len C.uint32_t,
+) {
+	message := C.GoStringN((*C.char)(unsafe.Pointer(message_ptr)), C.int(message_len))
+	logNative(int(level), message)
+}
+
+//export onGoPacketCompletion
+func onGoPacketCompletion(
+	_context C.uintptr_t,
+	client C.tb_client_t,
+	packet *C.tb_packet_t,
+	result_ptr C.tb_result_bytes_t,
+	result_len C.uint32_t,
+) {
+	// Get the request from the packet user data.
+	req := (*request)(unsafe.Pointer(packet.user_data))
+	if req.packet != packet {
+		panic("invalid packet: request packet mismatch")
+	}
+
+	var wrote C.uint32_t
+	if result_len > 0 && result_ptr != nil {
+		op := types.Operation(packet.operation)
+
+		// Make sure the completion handler is giving us valid data.
+		resultSize := C.uint32_t(types.ResultSize(op))
+		if result_len%resultSize != 0 {
+			panic("invalid result_len:  misaligned for the event")
+		}
+
+		//TODO(batiati): Refine the way we handle events with asymmetric results.
+		if op != types.OperationGetAccountTransfers && op != types.OperationGetAccountHistory {
+			// Make sure the amount of results at least matches the amount of requests.
+			count := packet.data_size / C.uint32_t(types.EventSize(op))
+			if count*resultSize < result_len {
+				panic("invalid result_len: implied multiple results per event")
+			}
+		}
+
+		// Write the result data into the request's reply.
+		if len(req.reply) > 0 {
+			wrote = result_len
+			C.memcpy(unsafe.Pointer(&req.reply[0]), unsafe.Pointer(result_ptr), C.size_t(result_len))
+		}
+	}
+
+	req.packet.data_size = wrote
+	req.complete()
+}
@@ -0,0 +1,33 @@
+//go:build !cgo
+
+package tigerbeetle_go
+
+import (
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Without cgo, as for WebAssembly, there is no tb_client to link, and clients must be created
+// with WithTransport.
+
+func registerNativeLog() {}
+
+type nativeTransport struct{}
+
+func newNativeTransport(
+	clusterID types.Uint128,
+	addresses []string,
+	concurrencyMax uint,
+) (*nativeTransport, error) {
+	return nil, errors.ErrNativeUnavailable{}
+}
+
+func (t *nativeTransport) Close() {}
+
+func (t *nativeTransport) updateAddresses(addresses []string) error {
+	return errors.ErrNativeUnavailable{}
+}
+
+func (t *nativeTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	return 0, errors.ErrNativeUnavailable{}
+}
@@ -0,0 +1,48 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// ClientOption configures optional behavior of a Client created with NewClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	transport         Transport
+	retryPolicy       *RetryPolicy
+	hedgePolicy       *HedgePolicy
+	connectionMonitor *ConnectionMonitor
+	dnsDiscovery      *DNSDiscovery
+	latencyMonitor    *LatencyMonitor
+	logger            *slog.Logger
+	recording         io.Writer
+	audit             AuditLog
+	preflight         bool
+	duplicates        bool
+	requestTimeout    time.Duration
+	rateLimit         *rateLimiter
+	circuitBreaker    *CircuitBreaker
+	interceptors      []Interceptor
+
+	concurrencyMode ConcurrencyMode
+	concurrencyCtx  context.Context
+}
+
+func newClientOptions(opts []ClientOption) clientOptions {
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithTransport makes the client submit requests through transport instead of tb_client.
+// The addresses and concurrencyMax passed to NewClient are then not used.
+func WithTransport(transport Transport) ClientOption {
+	return func(options *clientOptions) {
+		options.transport = transport
+	}
+}
@@ -0,0 +1,93 @@
+package tigerbeetle_go
+
+import (
+	"context"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+)
+
+// PauseMode decides what happens to requests submitted while a client is paused.
+type PauseMode uint8
+
+const (
+	// PauseQueue blocks new requests until the client is resumed.
+	PauseQueue PauseMode = iota
+	// PauseReject fails new requests with ErrMaintenance.
+	PauseReject
+)
+
+// Pause stops submitting new requests to the cluster, for a planned maintenance window, and
+// waits for the requests in flight to complete, or for ctx to be done, in which case the
+// client stays paused. Pausing a paused client only changes its mode.
+//
+// Closing a paused client fails the queued requests with ErrClientClosed.
+func (c *c_client) Pause(ctx context.Context, mode PauseMode) error {
+	c.mutex.Lock()
+	if c.closing {
+		c.mutex.Unlock()
+		return errors.ErrClientClosed{}
+	}
+	if !c.paused {
+		c.paused = true
+		c.resumed = make(chan struct{})
+		c.drained = make(chan struct{})
+		go func(drained chan struct{}) {
+			c.inflight.Wait()
+			close(drained)
+		}(c.drained)
+	}
+	c.pauseMode = mode
+	drained := c.drained
+	c.mutex.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Resume submits the queued requests and accepts new ones again. It first waits for the
+// requests that were in flight when the client was paused, if any are left.
+func (c *c_client) Resume() {
+	c.mutex.Lock()
+	if !c.paused {
+		c.mutex.Unlock()
+		return
+	}
+	drained := c.drained
+	c.mutex.Unlock()
+
+	// New requests must not be counted as in flight before the pause stopped waiting for them.
+	<-drained
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.paused && c.drained == drained {
+		c.paused = false
+		close(c.resumed)
+	}
+}
+
+// admit counts a request as in flight, once the client is not paused, unless it is closing.
+func (c *c_client) admit() error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for c.paused && !c.closing {
+		if c.pauseMode == PauseReject {
+			return errors.ErrMaintenance{}
+		}
+
+		resumed := c.resumed
+		c.mutex.RUnlock()
+		<-resumed
+		c.mutex.RLock()
+	}
+	if c.closing {
+		return errors.ErrClientClosed{}
+	}
+	c.inflight.Add(1)
+	return nil
+}
@@ -0,0 +1,204 @@
+// Package alias maps human names of accounts, such as "fees:USD" or "customer:42:wallet", to
+// their IDs, in a Store of the application's choosing: in memory, in an SQL table, or in a
+// key-value store such as Redis.
+//
+// A name is registered once, and keeps its ID for good, so a Registry caches the names it has
+// resolved. Names are resolved in batches, one request to the store per batch:
+//
+//	registry := alias.New(alias.NewSQLStore(db, alias.SQLConfig{Dialect: alias.Postgres}))
+//	transfers, err := registry.Transfers(ctx,
+//		alias.Transfer{Debit: "customer:42:wallet", Credit: "merchant:7", Transfer: payment},
+//		alias.Transfer{Debit: "customer:42:wallet", Credit: "fees:USD", Transfer: fee},
+//	)
+//
+// or, for the builders of package types, resolved up front:
+//
+//	ids, err := registry.Resolve(ctx, "customer:42:wallet", "merchant:7", "fees:USD")
+//	transfers, err := types.NewFeeSplit(types.Transfer{
+//		DebitAccountID:  ids["customer:42:wallet"],
+//		CreditAccountID: ids["merchant:7"],
+//		...
+//	}).Fee(ids["fees:USD"], rate, fixed).Build()
+package alias
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// ErrUnknownAlias is returned when resolving names that aren't registered.
+type ErrUnknownAlias struct {
+	Names []string
+}
+
+func (e ErrUnknownAlias) Error() string {
+	return "alias: unknown " + strings.Join(e.Names, ", ")
+}
+
+// ErrAliasTaken is returned when registering a name that is registered to another ID.
+type ErrAliasTaken struct {
+	Name string
+	ID   types.Uint128
+}
+
+func (e ErrAliasTaken) Error() string {
+	return fmt.Sprintf("alias: %s is registered to %s", e.Name, e.ID)
+}
+
+// Store keeps the names of accounts. It must be safe for concurrent use.
+type Store interface {
+	// Lookup returns the IDs of the names that are registered, leaving the others out.
+	Lookup(ctx context.Context, names []string) (map[string]types.Uint128, error)
+	// Insert registers name to id, unless name is registered already, and returns the ID that
+	// name is registered to.
+	Insert(ctx context.Context, name string, id types.Uint128) (types.Uint128, error)
+}
+
+// Registry resolves names to IDs through a Store, caching those it resolved. It is safe for
+// concurrent use.
+type Registry struct {
+	store Store
+
+	mutex    sync.RWMutex
+	resolved map[string]types.Uint128
+}
+
+// New returns a registry of the names in store.
+func New(store Store) *Registry {
+	return &Registry{store: store, resolved: make(map[string]types.Uint128)}
+}
+
+// Register registers name to id. Registering a name to the ID it is registered to succeeds,
+// and registering it to another ID fails with ErrAliasTaken.
+func (r *Registry) Register(ctx context.Context, name string, id types.Uint128) error {
+	registered, err := r.store.Insert(ctx, name, id)
+	if err != nil {
+		return fmt.Errorf("alias: registering %s: %w", name, err)
+	}
+	r.mutex.Lock()
+	r.resolved[name] = registered
+	r.mutex.Unlock()
+	if registered != id {
+		return ErrAliasTaken{Name: name, ID: registered}
+	}
+	return nil
+}
+
+// Resolve returns the IDs of names, looking up in one request those that aren't cached. It
+// fails with ErrUnknownAlias, naming them in the order given, if any aren't registered.
+func (r *Registry) Resolve(ctx context.Context, names ...string) (map[string]types.Uint128, error) {
+	ids := make(map[string]types.Uint128, len(names))
+	var missing []string
+	r.mutex.RLock()
+	for _, name := range names {
+		if id, ok := r.resolved[name]; ok {
+			ids[name] = id
+		} else if _, ok := ids[name]; !ok {
+			ids[name] = types.Uint128{}
+			missing = append(missing, name)
+		}
+	}
+	r.mutex.RUnlock()
+	if len(missing) == 0 {
+		return ids, nil
+	}
+
+	found, err := r.store.Lookup(ctx, missing)
+	if err != nil {
+		return nil, fmt.Errorf("alias: resolving: %w", err)
+	}
+	r.mutex.Lock()
+	for name, id := range found {
+		r.resolved[name] = id
+	}
+	r.mutex.Unlock()
+
+	var unknown []string
+	for _, name := range missing {
+		id, ok := found[name]
+		if !ok {
+			unknown = append(unknown, name)
+		}
+		ids[name] = id
+	}
+	if len(unknown) > 0 {
+		return nil, ErrUnknownAlias{Names: unknown}
+	}
+	return ids, nil
+}
+
+// Transfer is a transfer between accounts named by their aliases.
+type Transfer struct {
+	// Debit and Credit name the accounts of the transfer, whose IDs replace DebitAccountID and
+	// CreditAccountID. An empty name leaves the ID of Transfer as it is.
+	Debit  string
+	Credit string
+	types.Transfer
+}
+
+// Transfers resolves the names of transfers in one batch, and returns the transfers with the
+// IDs of their accounts. It fails with ErrUnknownAlias if any name isn't registered.
+func (r *Registry) Transfers(ctx context.Context, transfers ...Transfer) ([]types.Transfer, error) {
+	var names []string
+	for _, transfer := range transfers {
+		for _, name := range []string{transfer.Debit, transfer.Credit} {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	ids, err := r.Resolve(ctx, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make([]types.Transfer, len(transfers))
+	for i, transfer := range transfers {
+		resolved[i] = transfer.Transfer
+		if transfer.Debit != "" {
+			resolved[i].DebitAccountID = ids[transfer.Debit]
+		}
+		if transfer.Credit != "" {
+			resolved[i].CreditAccountID = ids[transfer.Credit]
+		}
+	}
+	return resolved, nil
+}
+
+// MemoryStore keeps names in memory, for tests and for names that are registered by the
+// application on every start.
+type MemoryStore struct {
+	mutex sync.Mutex
+	ids   map[string]types.Uint128
+}
+
+// NewMemoryStore returns an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{ids: make(map[string]types.Uint128)}
+}
+
+func (s *MemoryStore) Lookup(_ context.Context, names []string) (map[string]types.Uint128, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	found := make(map[string]types.Uint128, len(names))
+	for _, name := range names {
+		if id, ok := s.ids[name]; ok {
+			found[name] = id
+		}
+	}
+	return found, nil
+}
+
+func (s *MemoryStore) Insert(_ context.Context, name string, id types.Uint128) (types.Uint128, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if registered, ok := s.ids[name]; ok {
+		return registered, nil
+	}
+	s.ids[name] = id
+	return id, nil
+}
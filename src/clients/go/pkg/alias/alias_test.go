@@ -0,0 +1,86 @@
+package alias
+
+import (
+	"context"
+	e "errors"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// mapKeyValue is a KeyValue in a map, counting the requests to get keys.
+type mapKeyValue struct {
+	values map[string]string
+	gets   int
+}
+
+func (kv *mapKeyValue) Get(_ context.Context, keys []string) ([]*string, error) {
+	kv.gets++
+	values := make([]*string, len(keys))
+	for i, key := range keys {
+		if value, ok := kv.values[key]; ok {
+			values[i] = &value
+		}
+	}
+	return values, nil
+}
+
+func (kv *mapKeyValue) SetNX(_ context.Context, key string, value string) (bool, error) {
+	if _, ok := kv.values[key]; ok {
+		return false, nil
+	}
+	kv.values[key] = value
+	return true, nil
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	kv := &mapKeyValue{values: make(map[string]string)}
+	stores := map[string]Store{
+		"memory":    NewMemoryStore(),
+		"key-value": NewKeyValueStore(kv, "tb:alias:"),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			registry := New(store)
+			fees, alice := types.ToUint128(1), types.ToUint128(2)
+			assert.Equal(t, nil, registry.Register(ctx, "fees:USD", fees))
+			assert.Equal(t, nil, registry.Register(ctx, "fees:USD", fees))
+			assert.Equal(t, ErrAliasTaken{Name: "fees:USD", ID: fees}, registry.Register(ctx, "fees:USD", alice))
+
+			// Another registry of the same store sees the names registered.
+			registry = New(store)
+			assert.Equal(t, nil, registry.Register(ctx, "alice", alice))
+			ids, err := registry.Resolve(ctx, "alice", "fees:USD", "alice")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, map[string]types.Uint128{"alice": alice, "fees:USD": fees}, ids)
+
+			_, err = registry.Resolve(ctx, "bob", "alice", "carol")
+			var unknown ErrUnknownAlias
+			assert.True(t, e.As(err, &unknown))
+			assert.Equal(t, []string{"bob", "carol"}, unknown.Names)
+
+			transfers, err := registry.Transfers(ctx,
+				Transfer{Debit: "alice", Credit: "fees:USD", Transfer: types.Transfer{Amount: types.ToUint128(5)}},
+				Transfer{Credit: "alice", Transfer: types.Transfer{DebitAccountID: types.ToUint128(3)}},
+			)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []types.Transfer{
+				{DebitAccountID: alice, CreditAccountID: fees, Amount: types.ToUint128(5)},
+				{DebitAccountID: types.ToUint128(3), CreditAccountID: alice},
+			}, transfers)
+		})
+	}
+
+	// Resolved names are cached, and unknown names are looked up again.
+	gets := kv.gets
+	registry := New(stores["key-value"])
+	_, err := registry.Resolve(ctx, "alice", "fees:USD")
+	assert.Equal(t, nil, err)
+	_, err = registry.Resolve(ctx, "fees:USD", "alice")
+	assert.Equal(t, nil, err)
+	_, err = registry.Resolve(ctx, "alice", "bob")
+	assert.True(t, err != nil)
+	assert.Equal(t, gets+2, kv.gets)
+}
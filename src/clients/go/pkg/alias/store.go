@@ -0,0 +1,196 @@
+package alias
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Dialect adapts an SQLStore to a database.
+type Dialect uint8
+
+const (
+	SQLite Dialect = iota
+	Postgres
+	MySQL
+)
+
+// placeholder returns the placeholder of parameter n, counting from 1.
+func (d Dialect) placeholder(n int) string {
+	if d == Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// SQLConfig describes the table of an SQLStore. Zero fields take their defaults.
+type SQLConfig struct {
+	Dialect Dialect
+	// Table is the name of the table. Defaults to "tb_aliases".
+	Table string
+}
+
+// SQLStore keeps names in an SQL table, with a row per name.
+type SQLStore struct {
+	db     *sql.DB
+	config SQLConfig
+}
+
+// NewSQLStore returns a store of the names in the table of config in db.
+func NewSQLStore(db *sql.DB, config SQLConfig) *SQLStore {
+	if config.Table == "" {
+		config.Table = "tb_aliases"
+	}
+	return &SQLStore{db: db, config: config}
+}
+
+// CreateTable creates the table if it doesn't exist yet.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	var columns string
+	switch s.config.Dialect {
+	case Postgres:
+		columns = "name TEXT PRIMARY KEY, id NUMERIC(39, 0) NOT NULL"
+	case MySQL:
+		columns = "name VARCHAR(255) PRIMARY KEY, id DECIMAL(39, 0) NOT NULL"
+	default:
+		columns = "name TEXT PRIMARY KEY, id TEXT NOT NULL"
+	}
+	_, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+s.config.Table+" ("+columns+")")
+	return err
+}
+
+func (s *SQLStore) Lookup(ctx context.Context, names []string) (map[string]types.Uint128, error) {
+	found := make(map[string]types.Uint128, len(names))
+	if len(names) == 0 {
+		return found, nil
+	}
+	placeholders := make([]string, len(names))
+	args := make([]any, len(names))
+	for i, name := range names {
+		placeholders[i] = s.config.Dialect.placeholder(i + 1)
+		args[i] = name
+	}
+	query := fmt.Sprintf("SELECT name, id FROM %s WHERE name IN (%s)",
+		s.config.Table, strings.Join(placeholders, ", "))
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var id types.Uint128
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, err
+		}
+		found[name] = id
+	}
+	return found, rows.Err()
+}
+
+// Insert inserts the row of name, and reads the row back if the insert fails, since it fails
+// for a name that is registered, in a way that differs between databases.
+func (s *SQLStore) Insert(ctx context.Context, name string, id types.Uint128) (types.Uint128, error) {
+	query := fmt.Sprintf("INSERT INTO %s (name, id) VALUES (%s, %s)",
+		s.config.Table, s.config.Dialect.placeholder(1), s.config.Dialect.placeholder(2))
+	_, insertErr := s.db.ExecContext(ctx, query, name, id)
+	if insertErr == nil {
+		return id, nil
+	}
+	found, err := s.Lookup(ctx, []string{name})
+	if err != nil {
+		return types.Uint128{}, err
+	}
+	registered, ok := found[name]
+	if !ok {
+		return types.Uint128{}, insertErr
+	}
+	return registered, nil
+}
+
+// KeyValue is the part of a key-value store, such as Redis, that a KeyValueStore needs. With
+// github.com/redis/go-redis, it is:
+//
+//	type redisKeyValue struct{ client *redis.Client }
+//
+//	func (kv redisKeyValue) Get(ctx context.Context, keys []string) ([]*string, error) {
+//		values, err := kv.client.MGet(ctx, keys...).Result()
+//		found := make([]*string, len(values))
+//		for i, value := range values {
+//			if text, ok := value.(string); ok {
+//				found[i] = &text
+//			}
+//		}
+//		return found, err
+//	}
+//
+//	func (kv redisKeyValue) SetNX(ctx context.Context, key string, value string) (bool, error) {
+//		return kv.client.SetNX(ctx, key, value, 0).Result()
+//	}
+type KeyValue interface {
+	// Get returns the values of keys, in order, with nil for the keys that aren't set.
+	Get(ctx context.Context, keys []string) ([]*string, error)
+	// SetNX sets key to value unless key is set, and reports whether it did.
+	SetNX(ctx context.Context, key string, value string) (bool, error)
+}
+
+// KeyValueStore keeps names in a key-value store, as keys of prefix followed by the name, whose
+// values are the IDs in decimal.
+type KeyValueStore struct {
+	kv     KeyValue
+	prefix string
+}
+
+// NewKeyValueStore returns a store of the names in kv under prefix, such as "tb:alias:".
+func NewKeyValueStore(kv KeyValue, prefix string) *KeyValueStore {
+	return &KeyValueStore{kv: kv, prefix: prefix}
+}
+
+func (s *KeyValueStore) Lookup(ctx context.Context, names []string) (map[string]types.Uint128, error) {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = s.prefix + name
+	}
+	values, err := s.kv.Get(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(names) {
+		return nil, fmt.Errorf("got %d values for %d keys", len(values), len(names))
+	}
+
+	found := make(map[string]types.Uint128, len(names))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		id, err := types.DecStringToUint128(*value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", keys[i], err)
+		}
+		found[names[i]] = id
+	}
+	return found, nil
+}
+
+func (s *KeyValueStore) Insert(ctx context.Context, name string, id types.Uint128) (types.Uint128, error) {
+	set, err := s.kv.SetNX(ctx, s.prefix+name, id.String())
+	if err != nil {
+		return types.Uint128{}, err
+	}
+	if set {
+		return id, nil
+	}
+	found, err := s.Lookup(ctx, []string{name})
+	if err != nil {
+		return types.Uint128{}, err
+	}
+	registered, ok := found[name]
+	if !ok {
+		return types.Uint128{}, fmt.Errorf("key %s%s was set then deleted", s.prefix, name)
+	}
+	return registered, nil
+}
@@ -0,0 +1,310 @@
+// Package archive writes and verifies tamper-evident archives of transfer batches.
+//
+// Every batch is hashed together with the hash of the batch before it, so that modifying,
+// removing or reordering any batch breaks the chain for every batch that follows. The archive
+// ends with a trailer chained to the last batch, so that removing the last batches breaks it
+// too. Batches and the trailer may additionally be signed with an ed25519 key, allowing a
+// regulator holding only the public key to verify that the archive was produced by the key
+// owner.
+//
+// The archive layout is:
+//
+//	magic     [8]byte "TBARCHV1"
+//	batches   (header | transfers | signature?)*
+//	trailer   header | signature?
+//
+// where each batch header is:
+//
+//	sequence  uint64     (little-endian, starting at 0)
+//	count     uint32     (number of transfers, at most a batch of create_transfers)
+//	flags     uint32     (bit 0: signed, bit 1: trailer)
+//	prev      [32]byte   (hash of the previous batch, zero for the first batch)
+//	hash      [32]byte   (SHA-256 of sequence, count, flags, prev and the transfers)
+//
+// followed by count transfers in the 128-byte wire layout and, if signed, a 64-byte ed25519
+// signature of hash. The trailer is a header with the trailer flag, the number of batches as
+// its sequence and no transfers.
+package archive
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const magic = "TBARCHV1"
+
+const (
+	headerSize   = 8 + 4 + 4 + sha256.Size + sha256.Size
+	transferSize = int(unsafe.Sizeof(types.Transfer{}))
+	flagSigned   = uint32(1 << 0)
+	flagTrailer  = uint32(1 << 1)
+)
+
+type ErrInvalidArchive struct{}
+
+func (s ErrInvalidArchive) Error() string { return "Not a transfer archive." }
+
+type ErrChainBroken struct {
+	Sequence uint64
+	Reason   string
+}
+
+func (s ErrChainBroken) Error() string {
+	return fmt.Sprintf("Archive chain broken at batch %d: %s.", s.Sequence, s.Reason)
+}
+
+type ErrWriterClosed struct{}
+
+func (s ErrWriterClosed) Error() string { return "Archive writer already closed." }
+
+// Batch is a single archived batch of transfers.
+type Batch struct {
+	Sequence  uint64
+	Prev      [sha256.Size]byte
+	Hash      [sha256.Size]byte
+	Transfers []types.Transfer
+	Signature []byte
+}
+
+// Writer appends hash-chained batches to an archive.
+type Writer struct {
+	w        io.Writer
+	key      ed25519.PrivateKey
+	sequence uint64
+	prev     [sha256.Size]byte
+	closed   bool
+}
+
+// NewWriter starts a new archive on w. If key is not nil every batch is also signed with it.
+// The archive is only complete once Close has written its trailer.
+func NewWriter(w io.Writer, key ed25519.PrivateKey) (*Writer, error) {
+	if _, err := io.WriteString(w, magic); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, key: key}, nil
+}
+
+// WriteBatch appends the transfers as the next batch in the chain and returns its hash.
+func (w *Writer) WriteBatch(transfers []types.Transfer) ([sha256.Size]byte, error) {
+	hash, err := w.write(transfers, 0)
+	if err != nil {
+		return hash, err
+	}
+	w.sequence++
+	w.prev = hash
+	return hash, nil
+}
+
+// Close ends the archive with its trailer, without closing the underlying writer.
+func (w *Writer) Close() error {
+	if _, err := w.write(nil, flagTrailer); err != nil {
+		return err
+	}
+	w.closed = true
+	return nil
+}
+
+// write appends a record of the transfers with flags, chained to the last batch.
+func (w *Writer) write(transfers []types.Transfer, flags uint32) ([sha256.Size]byte, error) {
+	if w.closed {
+		return [sha256.Size]byte{}, ErrWriterClosed{}
+	}
+	if w.key != nil {
+		flags |= flagSigned
+	}
+
+	header := encodeHeader(w.sequence, uint32(len(transfers)), flags, w.prev)
+	body := transfersBytes(transfers)
+	hash := hashBatch(header, body)
+	copy(header[headerSize-sha256.Size:], hash[:])
+
+	if _, err := w.w.Write(header); err != nil {
+		return hash, err
+	}
+	if _, err := w.w.Write(body); err != nil {
+		return hash, err
+	}
+	if w.key != nil {
+		if _, err := w.w.Write(ed25519.Sign(w.key, hash[:])); err != nil {
+			return hash, err
+		}
+	}
+	return hash, nil
+}
+
+// Reader reads batches back from an archive without verifying them.
+type Reader struct {
+	r        io.Reader
+	sequence uint64
+	// trailer is the trailer of the archive, once read.
+	trailer *Batch
+}
+
+// NewReader checks the archive magic and returns a Reader positioned at the first batch.
+func NewReader(r io.Reader) (*Reader, error) {
+	var buffer [len(magic)]byte
+	if _, err := io.ReadFull(r, buffer[:]); err != nil || string(buffer[:]) != magic {
+		return nil, ErrInvalidArchive{}
+	}
+	return &Reader{r: r}, nil
+}
+
+// Next returns the next batch, or io.EOF once the archive is exhausted, whether or not it ended
+// with its trailer.
+func (r *Reader) Next() (Batch, error) {
+	if r.trailer != nil {
+		return Batch{}, io.EOF
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return Batch{}, ErrChainBroken{Sequence: r.sequence, Reason: "truncated header"}
+		}
+		return Batch{}, err
+	}
+
+	var batch Batch
+	batch.Sequence = binary.LittleEndian.Uint64(header[0:8])
+	count := binary.LittleEndian.Uint32(header[8:12])
+	flags := binary.LittleEndian.Uint32(header[12:16])
+	copy(batch.Prev[:], header[16:16+sha256.Size])
+	copy(batch.Hash[:], header[16+sha256.Size:])
+
+	// The count is read before the hash can be checked, so it is bounded before it sizes an
+	// allocation.
+	if uint64(count) > uint64(types.MaxBatchSize(types.OperationCreateTransfers)) {
+		return Batch{}, ErrChainBroken{Sequence: r.sequence, Reason: "batch too large"}
+	}
+	batch.Transfers = make([]types.Transfer, count)
+	if count > 0 {
+		if _, err := io.ReadFull(r.r, transfersBytes(batch.Transfers)); err != nil {
+			return Batch{}, ErrChainBroken{Sequence: r.sequence, Reason: "truncated transfers"}
+		}
+	}
+
+	if flags&flagSigned != 0 {
+		batch.Signature = make([]byte, ed25519.SignatureSize)
+		if _, err := io.ReadFull(r.r, batch.Signature); err != nil {
+			return Batch{}, ErrChainBroken{Sequence: r.sequence, Reason: "truncated signature"}
+		}
+	}
+
+	if flags&flagTrailer != 0 {
+		if count != 0 {
+			return Batch{}, ErrChainBroken{Sequence: r.sequence, Reason: "trailer with transfers"}
+		}
+		var extra [1]byte
+		if n, _ := r.r.Read(extra[:]); n > 0 {
+			return Batch{}, ErrChainBroken{Sequence: r.sequence, Reason: "data after trailer"}
+		}
+		r.trailer = &batch
+		return Batch{}, io.EOF
+	}
+
+	r.sequence++
+	return batch, nil
+}
+
+// Summary describes a successfully verified archive.
+type Summary struct {
+	Batches   uint64
+	Transfers uint64
+	Head      [sha256.Size]byte
+}
+
+// Verify reads the whole archive and checks that every batch hash is correct and chained to the
+// batch before it, and that the trailer is chained to the last one. If key is not nil, every
+// batch and the trailer must also carry a valid signature from it.
+func Verify(r io.Reader, key ed25519.PublicKey) (Summary, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	var summary Summary
+	for {
+		batch, err := reader.Next()
+		if err == io.EOF {
+			if reader.trailer == nil {
+				return summary, ErrChainBroken{Sequence: summary.Batches, Reason: "missing trailer"}
+			}
+			return summary, verifyBatch(*reader.trailer, flagTrailer, summary, key)
+		}
+		if err != nil {
+			return summary, err
+		}
+		if err := verifyBatch(batch, 0, summary, key); err != nil {
+			return summary, err
+		}
+
+		summary.Batches++
+		summary.Transfers += uint64(len(batch.Transfers))
+		summary.Head = batch.Hash
+	}
+}
+
+// verifyBatch checks that batch, with flags, follows the batches of summary.
+func verifyBatch(batch Batch, flags uint32, summary Summary, key ed25519.PublicKey) error {
+	if batch.Sequence != summary.Batches {
+		return ErrChainBroken{Sequence: summary.Batches, Reason: "unexpected sequence"}
+	}
+	if batch.Prev != summary.Head {
+		return ErrChainBroken{Sequence: batch.Sequence, Reason: "previous hash mismatch"}
+	}
+
+	if batch.Signature != nil {
+		flags |= flagSigned
+	}
+	header := encodeHeader(batch.Sequence, uint32(len(batch.Transfers)), flags, batch.Prev)
+	hash := hashBatch(header, transfersBytes(batch.Transfers))
+	if !bytes.Equal(hash[:], batch.Hash[:]) {
+		return ErrChainBroken{Sequence: batch.Sequence, Reason: "hash mismatch"}
+	}
+
+	if key != nil {
+		if batch.Signature == nil {
+			return ErrChainBroken{Sequence: batch.Sequence, Reason: "missing signature"}
+		}
+		if !ed25519.Verify(key, batch.Hash[:], batch.Signature) {
+			return ErrChainBroken{Sequence: batch.Sequence, Reason: "invalid signature"}
+		}
+	}
+	return nil
+}
+
+// encodeHeader returns a batch header with the hash left zeroed.
+func encodeHeader(sequence uint64, count uint32, flags uint32, prev [sha256.Size]byte) []byte {
+	header := make([]byte, headerSize)
+	binary.LittleEndian.PutUint64(header[0:8], sequence)
+	binary.LittleEndian.PutUint32(header[8:12], count)
+	binary.LittleEndian.PutUint32(header[12:16], flags)
+	copy(header[16:16+sha256.Size], prev[:])
+	return header
+}
+
+func hashBatch(header []byte, body []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write(header[:headerSize-sha256.Size])
+	h.Write(body)
+
+	var hash [sha256.Size]byte
+	copy(hash[:], h.Sum(nil))
+	return hash
+}
+
+// transfersBytes reinterprets the transfers as their in-memory wire layout, which is the same
+// little-endian layout the cluster stores.
+func transfersBytes(transfers []types.Transfer) []byte {
+	if len(transfers) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&transfers[0])), len(transfers)*transferSize)
+}
@@ -0,0 +1,164 @@
+package archive
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func writeArchive(t *testing.T, key ed25519.PrivateKey, batches int) []byte {
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < batches; i++ {
+		transfers := make([]types.Transfer, i+1)
+		for j := range transfers {
+			transfers[j] = types.Transfer{
+				ID:              types.ToUint128(uint64(i*100 + j + 1)),
+				DebitAccountID:  types.ToUint128(1),
+				CreditAccountID: types.ToUint128(2),
+				Amount:          types.ToUint128(uint64(j + 1)),
+				Ledger:          1,
+				Code:            1,
+			}
+		}
+		if _, err := writer.WriteBatch(transfers); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func Test_Verify(t *testing.T) {
+	archive := writeArchive(t, nil, 3)
+
+	summary, err := Verify(bytes.NewReader(archive), nil)
+	if err != nil {
+		t.Fatalf("Expected archive to verify, got: %s", err)
+	}
+	if summary.Batches != 3 || summary.Transfers != 6 {
+		t.Fatalf("Expected 3 batches and 6 transfers, got %d and %d", summary.Batches, summary.Transfers)
+	}
+}
+
+func Test_Verify_Tampered(t *testing.T) {
+	archive := writeArchive(t, nil, 3)
+
+	// Flip a byte inside the amount of the last transfer, before the trailer.
+	archive[len(archive)-headerSize-80] ^= 0xff
+
+	_, err := Verify(bytes.NewReader(archive), nil)
+	var broken ErrChainBroken
+	if !errors.As(err, &broken) {
+		t.Fatalf("Expected ErrChainBroken, got: %v", err)
+	}
+	if broken.Sequence != 2 {
+		t.Fatalf("Expected tampering to be detected at batch 2, got batch %d", broken.Sequence)
+	}
+}
+
+func Test_Verify_Signed(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive := writeArchive(t, private, 2)
+	if _, err := Verify(bytes.NewReader(archive), public); err != nil {
+		t.Fatalf("Expected signed archive to verify, got: %s", err)
+	}
+
+	other, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(bytes.NewReader(archive), other); err == nil {
+		t.Fatalf("Expected signature verification with the wrong key to fail")
+	}
+
+	unsigned := writeArchive(t, nil, 2)
+	if _, err := Verify(bytes.NewReader(unsigned), public); err == nil {
+		t.Fatalf("Expected unsigned archive to fail verification with a key")
+	}
+}
+
+func Test_Verify_Oversized(t *testing.T) {
+	archive := writeArchive(t, nil, 2)
+
+	// Corrupt the count of the second batch to the largest count a header can carry: it must
+	// be rejected before it sizes an allocation.
+	second := len(magic) + headerSize + transferSize
+	binary.LittleEndian.PutUint32(archive[second+8:second+12], math.MaxUint32)
+
+	_, err := Verify(bytes.NewReader(archive), nil)
+	var broken ErrChainBroken
+	if !errors.As(err, &broken) {
+		t.Fatalf("Expected ErrChainBroken, got: %v", err)
+	}
+	if broken.Sequence != 1 || broken.Reason != "batch too large" {
+		t.Fatalf("Expected an oversized batch at batch 1, got: %s", broken)
+	}
+}
+
+func Test_Verify_Truncated(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive := writeArchive(t, private, 3)
+	trailerSize := headerSize + ed25519.SignatureSize
+	lastSize := headerSize + 3*transferSize + ed25519.SignatureSize
+
+	// Cutting the archive at a batch boundary, with or without the last batch, loses the
+	// trailer that counts the batches.
+	for _, test := range []struct {
+		size     int
+		sequence uint64
+	}{
+		{len(archive) - trailerSize, 3},
+		{len(archive) - trailerSize - lastSize, 2},
+	} {
+		_, err := Verify(bytes.NewReader(archive[:test.size]), public)
+		var broken ErrChainBroken
+		if !errors.As(err, &broken) {
+			t.Fatalf("Expected ErrChainBroken, got: %v", err)
+		}
+		if broken.Sequence != test.sequence || broken.Reason != "missing trailer" {
+			t.Fatalf("Expected a missing trailer after batch %d, got: %s", test.sequence, broken)
+		}
+	}
+
+	// Nothing may follow the trailer.
+	extended := append(bytes.Clone(archive), 0)
+	if _, err := Verify(bytes.NewReader(extended), public); err == nil {
+		t.Fatalf("Expected data after the trailer to fail verification")
+	}
+
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.WriteBatch(nil); !errors.Is(err, ErrWriterClosed{}) {
+		t.Fatalf("Expected ErrWriterClosed, got: %v", err)
+	}
+	summary, err := Verify(bytes.NewReader(buffer.Bytes()), public)
+	if err != nil || summary.Batches != 0 {
+		t.Fatalf("Expected an empty archive to verify, got %d batches and: %v", summary.Batches, err)
+	}
+}
@@ -0,0 +1,373 @@
+// Package audit keeps a local, append-only log of the batches a client submits and the results
+// it receives, for compliance review and post-incident forensics:
+//
+//	log, err := audit.Open("/var/lib/payments/audit", audit.Config{})
+//	client, err := tigerbeetle_go.NewClient(clusterID, addresses, 1, tigerbeetle_go.WithAuditLog(log))
+//	...
+//	for record, err := range audit.Records("/var/lib/payments/audit") {
+//		...
+//	}
+//
+// The log is a directory of segment files, named after the sequence number of their first
+// record, each starting with segmentMagic. Every record is framed as:
+//
+//	length    u32, of the payload
+//	checksum  u32, the CRC-32C of the payload
+//	payload:
+//	  sequence   u64
+//	  time       i64, in Unix nanoseconds
+//	  kind       u8
+//	  operation  u8
+//	  request    u64, the sequence of the KindSubmitted record that a KindReceived answers
+//	  data_len   u32, then the events or the reply in their wire layout
+//	  error_len  u32, then the error message, empty unless the batch failed
+//
+// Integers are little-endian. Segments are only ever appended to, and a segment is not written
+// to again once the log moves on to the next one.
+package audit
+
+import (
+	"bytes"
+	"encoding/binary"
+	e "errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const (
+	// segmentSizeDefault is the size past which a log moves on to a new segment by default.
+	segmentSizeDefault = 64 << 20
+	// segmentSuffix ends the names of segment files.
+	segmentSuffix = ".audit"
+	// frameSize is the size of the length and checksum that frame a record.
+	frameSize = 8
+	// payloadSizeMin is the size of the payload of a record without data or error.
+	payloadSizeMin = 8 + 8 + 1 + 1 + 8 + 4 + 4
+)
+
+var segmentMagic = [8]byte{'T', 'B', 'A', 'U', 'D', 'I', 'T', '1'}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrClosed is returned by Err once the log is closed.
+var ErrClosed = e.New("audit: log closed")
+
+// ErrCorrupt is returned when reading a record whose checksum or framing is wrong.
+type ErrCorrupt struct {
+	Segment string
+	Offset  int64
+}
+
+func (s ErrCorrupt) Error() string {
+	return "audit: corrupt record in " + s.Segment + " at offset " + strconv.FormatInt(s.Offset, 10)
+}
+
+// Kind tells what a record records.
+type Kind uint8
+
+const (
+	// KindSubmitted records a batch about to be submitted.
+	KindSubmitted Kind = iota + 1
+	// KindReceived records the reply to a batch, or the error it failed with.
+	KindReceived
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindSubmitted:
+		return "Submitted"
+	case KindReceived:
+		return "Received"
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// Record is an entry of the log.
+type Record struct {
+	Sequence  uint64
+	Time      time.Time
+	Kind      Kind
+	Operation types.Operation
+	// Request is the Sequence of the KindSubmitted record that a KindReceived record answers,
+	// and the Sequence of a KindSubmitted record itself.
+	Request uint64
+	// Data is the events of a KindSubmitted record, and the reply of a KindReceived one, in their
+	// wire layout, as types.DecodeTransfers and the like decode them.
+	Data []byte
+	// Err is the message of the error that the batch failed with, or empty.
+	Err string
+}
+
+// Config configures a Log. Zero fields take their defaults.
+type Config struct {
+	// SegmentSize is the size past which the log moves on to a new segment. Defaults to 64 MiB.
+	SegmentSize int64
+	// Sync makes every record durable before the batch is submitted or its reply returned, at
+	// the cost of a sync per record. Otherwise, records are left to the operating system to
+	// write out, and the latest may be lost if the machine crashes.
+	Sync bool
+	// Redaction redacts the user data of the accounts and transfers in the records, created or
+	// looked up, before they are written. The data of batches that aren't whole accounts or
+	// transfers, such as by a failed request, is written as it is.
+	Redaction types.Redaction
+}
+
+// Log is an append-only audit log in a directory, which implements the AuditLog of
+// tigerbeetle_go.WithAuditLog. It is safe for concurrent use.
+//
+// The log can't fail the requests it records, so the first error writing it stops the log,
+// and is returned by Err, which should be monitored.
+type Log struct {
+	dir    string
+	config Config
+
+	mutex    sync.Mutex
+	segment  *os.File
+	size     int64
+	sequence uint64
+	err      error
+}
+
+// Open opens the log in dir, creating dir if needed, to append to it. A record that was only
+// partly written when the process stopped, at the end of the last segment, is cut off, while a
+// corrupt record before it fails the open with ErrCorrupt.
+func Open(dir string, config Config) (*Log, error) {
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = segmentSizeDefault
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	segments, err := segmentNames(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	log := &Log{dir: dir, config: config}
+	if len(segments) == 0 {
+		return log, nil
+	}
+
+	// Recover the next sequence from the last segment, cutting off a torn record at its end.
+	name := segments[len(segments)-1]
+	path := filepath.Join(dir, name)
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	log.sequence, _ = strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+	valid, err := scanSegment(file, path, true, func(record Record) bool {
+		log.sequence = record.Sequence + 1
+		return true
+	})
+	if err == nil {
+		err = file.Truncate(valid)
+	}
+	if err == nil && valid == 0 {
+		// The segment was created, but its magic not written in full.
+		_, err = file.Write(segmentMagic[:])
+		valid = int64(len(segmentMagic))
+	}
+	if err == nil {
+		_, err = file.Seek(valid, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	log.segment, log.size = file, valid
+	return log, nil
+}
+
+// Submitted records a batch about to be submitted.
+func (l *Log) Submitted(op types.Operation, events []byte) uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	sequence := l.sequence
+	l.append(Record{Kind: KindSubmitted, Operation: op, Request: sequence, Data: l.redact(op, events, false)})
+	return sequence
+}
+
+// Received records the reply to the batch of the record submitted, or its error.
+func (l *Log) Received(submitted uint64, op types.Operation, reply []byte, err error) {
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.append(Record{Kind: KindReceived, Operation: op, Request: submitted, Data: l.redact(op, reply, true), Err: message})
+}
+
+// redact returns the events, or the reply, of a batch of op with their user data redacted as
+// configured, copying them rather than redacting them in place, where they are still in use.
+func (l *Log) redact(op types.Operation, data []byte, reply bool) []byte {
+	if l.config.Redaction.UserData == types.RedactNone {
+		return data
+	}
+	switch {
+	case op == types.OperationCreateAccounts && !reply,
+		op == types.OperationLookupAccounts && reply:
+		accounts, err := types.DecodeAccounts(data)
+		if err != nil {
+			return data
+		}
+		for i := range accounts {
+			accounts[i] = l.config.Redaction.Account(accounts[i])
+		}
+		return types.EncodeAccounts(accounts)
+	case op == types.OperationCreateTransfers && !reply,
+		op == types.OperationLookupTransfers && reply,
+		op == types.OperationGetAccountTransfers && reply:
+		transfers, err := types.DecodeTransfers(data)
+		if err != nil {
+			return data
+		}
+		for i := range transfers {
+			transfers[i] = l.config.Redaction.Transfer(transfers[i])
+		}
+		return types.EncodeTransfers(transfers)
+	}
+	return data
+}
+
+// Err returns the error that stopped the log, if any.
+func (l *Log) Err() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.err
+}
+
+// Close syncs and closes the log, which records nothing more.
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.err == ErrClosed {
+		return nil
+	}
+	var err error
+	if l.segment != nil {
+		err = e.Join(l.segment.Sync(), l.segment.Close())
+	}
+	l.err = ErrClosed
+	return err
+}
+
+// append writes record as the next one, with the lock held.
+func (l *Log) append(record Record) {
+	if l.err != nil {
+		return
+	}
+	record.Sequence = l.sequence
+	record.Time = time.Now()
+	frame := encodeRecord(record)
+
+	if l.segment == nil || l.size+int64(len(frame)) > l.config.SegmentSize && l.size > int64(len(segmentMagic)) {
+		if l.err = l.rotate(); l.err != nil {
+			return
+		}
+	}
+	if _, l.err = l.segment.Write(frame); l.err != nil {
+		return
+	}
+	if l.config.Sync {
+		if l.err = l.segment.Sync(); l.err != nil {
+			return
+		}
+	}
+	l.size += int64(len(frame))
+	l.sequence++
+}
+
+// rotate moves on to a new segment, starting at the next sequence.
+func (l *Log) rotate() error {
+	if l.segment != nil {
+		if err := e.Join(l.segment.Sync(), l.segment.Close()); err != nil {
+			return err
+		}
+		l.segment = nil
+	}
+	name := fmt.Sprintf("%020d%s", l.sequence, segmentSuffix)
+	file, err := os.OpenFile(filepath.Join(l.dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(segmentMagic[:]); err != nil {
+		file.Close()
+		return err
+	}
+	l.segment, l.size = file, int64(len(segmentMagic))
+	return nil
+}
+
+func encodeRecord(record Record) []byte {
+	var payload bytes.Buffer
+	payload.Grow(payloadSizeMin + len(record.Data) + len(record.Err))
+	payload.Write(binary.LittleEndian.AppendUint64(nil, record.Sequence))
+	payload.Write(binary.LittleEndian.AppendUint64(nil, uint64(record.Time.UnixNano())))
+	payload.WriteByte(byte(record.Kind))
+	payload.WriteByte(byte(record.Operation))
+	payload.Write(binary.LittleEndian.AppendUint64(nil, record.Request))
+	payload.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(record.Data))))
+	payload.Write(record.Data)
+	payload.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(record.Err))))
+	payload.WriteString(record.Err)
+
+	frame := make([]byte, frameSize, frameSize+payload.Len())
+	binary.LittleEndian.PutUint32(frame[0:4], uint32(payload.Len()))
+	binary.LittleEndian.PutUint32(frame[4:8], crc32.Checksum(payload.Bytes(), castagnoli))
+	return append(frame, payload.Bytes()...)
+}
+
+func decodeRecord(payload []byte) (Record, bool) {
+	if len(payload) < payloadSizeMin {
+		return Record{}, false
+	}
+	record := Record{
+		Sequence:  binary.LittleEndian.Uint64(payload[0:8]),
+		Time:      time.Unix(0, int64(binary.LittleEndian.Uint64(payload[8:16]))),
+		Kind:      Kind(payload[16]),
+		Operation: types.Operation(payload[17]),
+		Request:   binary.LittleEndian.Uint64(payload[18:26]),
+	}
+	rest := payload[26:]
+	dataLen := int(binary.LittleEndian.Uint32(rest[0:4]))
+	if len(rest) < 4+dataLen+4 {
+		return Record{}, false
+	}
+	record.Data = rest[4 : 4+dataLen]
+	rest = rest[4+dataLen:]
+	errLen := int(binary.LittleEndian.Uint32(rest[0:4]))
+	if len(rest) != 4+errLen {
+		return Record{}, false
+	}
+	record.Err = string(rest[4:])
+	return record, true
+}
+
+// segmentNames returns the names of the segments in dir, in order.
+func segmentNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), segmentSuffix) {
+			names = append(names, entry.Name())
+		}
+	}
+	// The names are zero-padded, so they sort by sequence.
+	slices.Sort(names)
+	return names, nil
+}
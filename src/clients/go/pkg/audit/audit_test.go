@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"encoding/binary"
+	e "errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func readAll(dir string) ([]Record, error) {
+	var records []Record
+	for record, err := range Records(dir) {
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func TestLog(t *testing.T) {
+	dir := t.TempDir()
+	log, err := Open(dir, Config{SegmentSize: 1024})
+	assert.Equal(t, nil, err)
+
+	unavailable := e.New("unavailable")
+	transport := tigerbeetle_go.NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if op == types.OperationLookupAccounts {
+			return nil, unavailable
+		}
+		// Transfer 1 exists.
+		return binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, 1), uint32(types.TransferExists)), nil
+	})
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1,
+		tigerbeetle_go.WithTransport(transport), tigerbeetle_go.WithAuditLog(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	transfers := []types.Transfer{{ID: types.ToUint128(1)}, {ID: types.ToUint128(2)}}
+	for range 4 {
+		_, err = client.CreateTransfers(transfers)
+		assert.Equal(t, nil, err)
+	}
+	_, err = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, unavailable, err)
+	assert.Equal(t, nil, log.Close())
+	assert.Equal(t, ErrClosed, log.Err())
+
+	segments, err := segmentNames(dir)
+	assert.Equal(t, nil, err)
+	assert.True(t, len(segments) > 1)
+
+	records, err := readAll(dir)
+	assert.Equal(t, nil, err)
+	assert.Len(t, records, 10)
+	for i, record := range records {
+		assert.Equal(t, uint64(i), record.Sequence)
+		assert.Equal(t, uint64(i/2*2), record.Request)
+	}
+	assert.Equal(t, KindSubmitted, records[0].Kind)
+	decoded, err := types.DecodeTransfers(records[0].Data)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, transfers, decoded)
+	assert.Equal(t, KindReceived, records[1].Kind)
+	assert.Len(t, records[1].Data, 8)
+	assert.Equal(t, types.OperationLookupAccounts, records[9].Operation)
+	assert.Equal(t, "unavailable", records[9].Err)
+
+	t.Run("redacts user data", func(t *testing.T) {
+		dir := t.TempDir()
+		log, err := Open(dir, Config{Redaction: types.Redaction{UserData: types.RedactOmit}})
+		assert.Equal(t, nil, err)
+		events := types.EncodeTransfers([]types.Transfer{{ID: types.ToUint128(1), UserData64: 7}})
+		log.Received(log.Submitted(types.OperationCreateTransfers, events), types.OperationCreateTransfers, nil, nil)
+		assert.Equal(t, nil, log.Close())
+
+		records, err := readAll(dir)
+		assert.Equal(t, nil, err)
+		assert.Len(t, records, 2)
+		decoded, err := types.DecodeTransfers(records[0].Data)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []types.Transfer{{ID: types.ToUint128(1)}}, decoded)
+		// The events submitted are left as they are.
+		assert.Equal(t, uint64(7), binary.LittleEndian.Uint64(events[96:]))
+	})
+
+	t.Run("cuts off a torn record", func(t *testing.T) {
+		last := filepath.Join(dir, segments[len(segments)-1])
+		file, err := os.OpenFile(last, os.O_WRONLY|os.O_APPEND, 0)
+		assert.Equal(t, nil, err)
+		_, err = file.Write(encodeRecord(Record{Sequence: 10})[:20])
+		assert.Equal(t, nil, err)
+		file.Close()
+
+		records, err := readAll(dir)
+		assert.Equal(t, nil, err)
+		assert.Len(t, records, 10)
+
+		log, err := Open(dir, Config{SegmentSize: 1024})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(10), log.Submitted(types.OperationCreateAccounts, nil))
+		assert.Equal(t, nil, log.Close())
+		records, err = readAll(dir)
+		assert.Equal(t, nil, err)
+		assert.Len(t, records, 11)
+	})
+
+	t.Run("detects corruption", func(t *testing.T) {
+		first := filepath.Join(dir, segments[0])
+		data, err := os.ReadFile(first)
+		assert.Equal(t, nil, err)
+		data[len(segmentMagic)+frameSize] ^= 1
+		assert.Equal(t, nil, os.WriteFile(first, data, 0o644))
+
+		records, err := readAll(dir)
+		assert.Equal(t, ErrCorrupt{Segment: first, Offset: int64(len(segmentMagic))}, err)
+		assert.Empty(t, records)
+	})
+}
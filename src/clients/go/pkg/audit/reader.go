@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+)
+
+// Records reads the log in dir, oldest record first, checking the checksum of every record. It
+// stops at the first record that is corrupt, yielding ErrCorrupt, except for a record at the
+// end of the last segment that is only partly written, such as by a log being appended to,
+// which ends the records without an error.
+func Records(dir string) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		segments, err := segmentNames(dir)
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+		for i, name := range segments {
+			path := filepath.Join(dir, name)
+			file, err := os.Open(path)
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			stopped := false
+			_, err = scanSegment(file, path, i == len(segments)-1, func(record Record) bool {
+				stopped = !yield(record, nil)
+				return !stopped
+			})
+			file.Close()
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			if stopped {
+				return
+			}
+		}
+	}
+}
+
+// scanSegment reads the records of the segment in file, from its start, passing them to yield
+// until it returns false. It returns the offset past the last record read. A torn record, only
+// partly written at the end of the segment, ends the segment if it is the last one, and is
+// corrupt otherwise. A last segment too short for its magic has no records, at offset 0.
+func scanSegment(file *os.File, path string, last bool, yield func(Record) bool) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	reader := bufio.NewReader(file)
+
+	var magic [len(segmentMagic)]byte
+	if size < int64(len(magic)) {
+		if last {
+			return 0, nil
+		}
+		return 0, ErrCorrupt{Segment: path, Offset: 0}
+	}
+	if _, err := io.ReadFull(reader, magic[:]); err != nil {
+		return 0, err
+	}
+	if magic != segmentMagic {
+		return 0, ErrCorrupt{Segment: path, Offset: 0}
+	}
+
+	offset := int64(len(magic))
+	torn := func() (int64, error) {
+		if last {
+			return offset, nil
+		}
+		return 0, ErrCorrupt{Segment: path, Offset: offset}
+	}
+	var frame [frameSize]byte
+	for offset < size {
+		if offset+frameSize > size {
+			return torn()
+		}
+		if _, err := io.ReadFull(reader, frame[:]); err != nil {
+			return 0, err
+		}
+		length := int64(binary.LittleEndian.Uint32(frame[0:4]))
+		end := offset + frameSize + length
+		if end > size {
+			return torn()
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return 0, err
+		}
+		record, ok := decodeRecord(payload)
+		if !ok || crc32.Checksum(payload, castagnoli) != binary.LittleEndian.Uint32(frame[4:8]) {
+			if end == size {
+				return torn()
+			}
+			return 0, ErrCorrupt{Segment: path, Offset: offset}
+		}
+		if !yield(record) {
+			return end, nil
+		}
+		offset = end
+	}
+	return offset, nil
+}
@@ -0,0 +1,245 @@
+// Package bench load-tests a cluster with the workload of `tigerbeetle benchmark`: it creates
+// a set of accounts, then creates transfers between random pairs of them in full batches,
+// measuring the latency of every batch and the throughput of the whole run.
+//
+// On top of the upstream workload, a Zipfian skew concentrates transfers on a few hot
+// accounts, and a two-phase ratio creates some transfers as pending and posts them in the
+// following batch, as contended and two-phase payment flows do.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/pacing"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const (
+	accountCountDefault  = 10_000
+	transferCountDefault = 10_000_000
+)
+
+// Client is the part of the TigerBeetle client that a benchmark submits to.
+type Client interface {
+	CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error)
+	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+}
+
+// Config describes a workload. Zero fields take their defaults.
+type Config struct {
+	// AccountCount is the number of accounts created, at least 2. Defaults to 10,000.
+	AccountCount int
+	// TransferCount is the number of transfers created, including the posts of pending
+	// transfers. Defaults to 10,000,000.
+	TransferCount int
+	// BatchSize is the number of transfers per request. Defaults to the most that fit.
+	BatchSize int
+	// ZipfSkew, when greater than 1, picks the accounts of each transfer from a Zipf
+	// distribution with this exponent, so that low account indexes are hot. Otherwise accounts
+	// are picked uniformly.
+	ZipfSkew float64
+	// TwoPhaseRatio is the fraction of transfers, between 0 and 1, created as pending and posted
+	// in the next batch.
+	TwoPhaseRatio float64
+	// TransfersPerSecond caps the offered load. Zero submits as fast as the cluster replies.
+	TransfersPerSecond float64
+	// Ledger and Code of the accounts and transfers. Default to 1.
+	Ledger uint32
+	Code   uint16
+	// Seed makes the choice of accounts reproducible.
+	Seed int64
+}
+
+// Report is the outcome of a benchmark run.
+type Report struct {
+	Batches   int
+	Transfers int
+	// Rejected counts the transfers that the cluster did not create.
+	Rejected int
+	// Duration is how long creating the transfers took, excluding the accounts.
+	Duration time.Duration
+	// Latencies holds the latency of every batch of transfers, in ascending order.
+	Latencies []time.Duration
+}
+
+// Throughput returns the transfers created per second.
+func (r Report) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Transfers-r.Rejected) / r.Duration.Seconds()
+}
+
+// Percentile returns the batch latency at percentile p, between 0 and 100, by nearest rank.
+func (r Report) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(r.Latencies))))
+	return r.Latencies[min(max(rank, 1), len(r.Latencies))-1]
+}
+
+// Run creates the accounts and then the transfers of the workload, until done or ctx is done.
+func Run(ctx context.Context, client Client, config Config) (Report, error) {
+	config = withDefaults(config)
+	if config.AccountCount < 2 {
+		return Report{}, fmt.Errorf("bench: need at least 2 accounts, got %d", config.AccountCount)
+	}
+	if config.TwoPhaseRatio < 0 || config.TwoPhaseRatio > 1 {
+		return Report{}, fmt.Errorf("bench: two-phase ratio %v is not between 0 and 1", config.TwoPhaseRatio)
+	}
+
+	accounts, err := createAccounts(client, config)
+	if err != nil {
+		return Report{}, err
+	}
+
+	var pacer *pacing.Pacer
+	if config.TransfersPerSecond > 0 {
+		pacer = pacing.New(pacing.Config{Rate: config.TransfersPerSecond, Burst: config.BatchSize})
+	}
+
+	w := newWorkload(config, accounts)
+	report := Report{}
+	start := time.Now()
+	for report.Transfers < config.TransferCount {
+		batch := w.batch(config.TransferCount - report.Transfers)
+		if pacer != nil {
+			if err := pacer.Wait(ctx, len(batch)); err != nil {
+				return finish(report, start), err
+			}
+		} else if err := ctx.Err(); err != nil {
+			return finish(report, start), err
+		}
+
+		submitted := time.Now()
+		results, err := client.CreateTransfers(batch)
+		if err != nil {
+			return finish(report, start), err
+		}
+		report.Latencies = append(report.Latencies, time.Since(submitted))
+		report.Batches++
+		report.Transfers += len(batch)
+		report.Rejected += len(results)
+	}
+	return finish(report, start), nil
+}
+
+func withDefaults(config Config) Config {
+	if config.AccountCount == 0 {
+		config.AccountCount = accountCountDefault
+	}
+	if config.TransferCount == 0 {
+		config.TransferCount = transferCountDefault
+	}
+	if batchMax := types.MaxBatchSize(types.OperationCreateTransfers); config.BatchSize <= 0 ||
+		config.BatchSize > batchMax {
+		config.BatchSize = batchMax
+	}
+	if config.Ledger == 0 {
+		config.Ledger = 1
+	}
+	if config.Code == 0 {
+		config.Code = 1
+	}
+	return config
+}
+
+func finish(report Report, start time.Time) Report {
+	report.Duration = time.Since(start)
+	slices.Sort(report.Latencies)
+	return report
+}
+
+func createAccounts(client Client, config Config) ([]types.Uint128, error) {
+	ids := make([]types.Uint128, config.AccountCount)
+	batchMax := types.MaxBatchSize(types.OperationCreateAccounts)
+	for start := 0; start < len(ids); start += batchMax {
+		accounts := make([]types.Account, min(batchMax, len(ids)-start))
+		for i := range accounts {
+			ids[start+i] = types.ID()
+			accounts[i] = types.Account{ID: ids[start+i], Ledger: config.Ledger, Code: config.Code}
+		}
+
+		results, err := client.CreateAccounts(accounts)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) > 0 {
+			return nil, fmt.Errorf("bench: creating account %d failed: %s",
+				start+int(results[0].Index), results[0].Result)
+		}
+	}
+	return ids, nil
+}
+
+type workload struct {
+	config   Config
+	accounts []types.Uint128
+	random   *rand.Rand
+	zipf     *rand.Zipf
+	// pending holds the pending transfers of the previous batch, to post in the next one.
+	pending []types.Uint128
+}
+
+func newWorkload(config Config, accounts []types.Uint128) *workload {
+	w := &workload{
+		config:   config,
+		accounts: accounts,
+		random:   rand.New(rand.NewSource(config.Seed)),
+	}
+	if config.ZipfSkew > 1 {
+		w.zipf = rand.NewZipf(w.random, config.ZipfSkew, 1, uint64(len(accounts)-1))
+	}
+	return w
+}
+
+func (w *workload) account() int {
+	if w.zipf != nil {
+		return int(w.zipf.Uint64())
+	}
+	return w.random.Intn(len(w.accounts))
+}
+
+// batch returns the next batch of at most remaining transfers, starting with the posts of the
+// pending transfers of the previous batch.
+func (w *workload) batch(remaining int) []types.Transfer {
+	size := min(w.config.BatchSize, remaining)
+	transfers := make([]types.Transfer, 0, size)
+
+	posts := min(len(w.pending), size)
+	for _, pendingID := range w.pending[:posts] {
+		transfers = append(transfers, types.PostPendingTransfer(pendingID, types.ToUint128(0)))
+	}
+	w.pending = slices.Delete(w.pending, 0, posts)
+
+	// Leave room within the transfer count for the posts of every pending transfer.
+	free := func() int { return remaining - len(transfers) - len(w.pending) }
+	for len(transfers) < size && free() > 0 {
+		debit := w.account()
+		credit := w.account()
+		for credit == debit {
+			credit = w.random.Intn(len(w.accounts))
+		}
+
+		transfer := types.Transfer{
+			ID:              types.ID(),
+			DebitAccountID:  w.accounts[debit],
+			CreditAccountID: w.accounts[credit],
+			Amount:          types.ToUint128(uint64(w.random.Intn(100) + 1)),
+			Ledger:          w.config.Ledger,
+			Code:            w.config.Code,
+		}
+		if free() > 1 && w.random.Float64() < w.config.TwoPhaseRatio {
+			transfer.Flags = types.TransferFlags{Pending: true}.ToUint16()
+			w.pending = append(w.pending, transfer.ID)
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers
+}
@@ -0,0 +1,99 @@
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+type recordingClient struct {
+	accounts  []types.Account
+	transfers [][]types.Transfer
+}
+
+func (c *recordingClient) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	c.accounts = append(c.accounts, accounts...)
+	return nil, nil
+}
+
+func (c *recordingClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	c.transfers = append(c.transfers, transfers)
+	return nil, nil
+}
+
+func Test_Run(t *testing.T) {
+	client := &recordingClient{}
+	report, err := Run(context.Background(), client, Config{
+		AccountCount:  10,
+		TransferCount: 1000,
+		BatchSize:     64,
+		ZipfSkew:      1.5,
+		TwoPhaseRatio: 0.3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(client.accounts) != 10 {
+		t.Fatalf("Expected 10 accounts, got %d", len(client.accounts))
+	}
+	if report.Transfers != 1000 || report.Batches != len(client.transfers) {
+		t.Fatalf("Expected 1000 transfers in %d batches, got %+v", len(client.transfers), report)
+	}
+	if len(report.Latencies) != report.Batches {
+		t.Fatalf("Expected a latency per batch, got %d", len(report.Latencies))
+	}
+
+	// Every pending transfer is posted in the next batch, within the transfer count.
+	open := map[types.Uint128]int{}
+	total := 0
+	for batch, transfers := range client.transfers {
+		if len(transfers) > 64 {
+			t.Fatalf("Batch %d has %d transfers", batch, len(transfers))
+		}
+		for _, transfer := range transfers {
+			total++
+			flags := transfer.TransferFlags()
+			switch {
+			case flags.Pending:
+				open[transfer.ID] = batch
+			case flags.PostPendingTransfer:
+				if pending, ok := open[transfer.PendingID]; !ok || pending != batch-1 {
+					t.Fatalf("Post in batch %d of a transfer pending since batch %d", batch, pending)
+				}
+				delete(open, transfer.PendingID)
+			default:
+				if transfer.DebitAccountID == transfer.CreditAccountID {
+					t.Fatal("Expected different accounts")
+				}
+			}
+		}
+	}
+	if total != 1000 || len(open) != 0 {
+		t.Fatalf("Expected 1000 transfers with no pending left, got %d and %d", total, len(open))
+	}
+}
+
+func Test_ReportPercentile(t *testing.T) {
+	report := Report{Latencies: []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}}
+	tests := map[float64]time.Duration{0: 1, 10: 1, 50: 5, 95: 10, 99: 10, 100: 10}
+	for p, expected := range tests {
+		if got := report.Percentile(p); got != expected {
+			t.Fatalf("Expected p%v = %v, got %v", p, expected, got)
+		}
+	}
+	if got := (Report{}).Percentile(50); got != 0 {
+		t.Fatalf("Expected no latency, got %v", got)
+	}
+}
+
+func Test_RunInvalid(t *testing.T) {
+	if _, err := Run(context.Background(), &recordingClient{}, Config{AccountCount: 1}); err == nil {
+		t.Fatal("Expected an error for a single account")
+	}
+	if _, err := Run(context.Background(), &recordingClient{}, Config{TwoPhaseRatio: 2}); err == nil {
+		t.Fatal("Expected an error for a ratio above 1")
+	}
+}
@@ -0,0 +1,53 @@
+package cdc
+
+import (
+	"encoding/binary"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// avroWriter encodes the Avro binary encoding of a record, which is the concatenation of its
+// fields in the order of the schema.
+type avroWriter struct {
+	data []byte
+}
+
+// long writes an int or a long, as a zig-zag varint.
+func (w *avroWriter) long(value int64) {
+	w.data = binary.AppendUvarint(w.data, uint64(value<<1)^uint64(value>>63))
+}
+
+// string writes a string, prefixed with its length.
+func (w *avroWriter) string(value string) {
+	w.long(int64(len(value)))
+	w.data = append(w.data, value...)
+}
+
+func encodeTransferAvro(transfer types.Transfer) []byte {
+	w := avroWriter{}
+	w.string(transfer.ID.String())
+	w.string(transfer.DebitAccountID.String())
+	w.string(transfer.CreditAccountID.String())
+	w.string(transfer.Amount.String())
+	w.string(transfer.PendingID.String())
+	w.string(transfer.UserData128.String())
+	w.long(int64(transfer.UserData64))
+	w.long(int64(transfer.UserData32))
+	w.long(int64(transfer.Timeout))
+	w.long(int64(transfer.Ledger))
+	w.long(int64(transfer.Code))
+	w.long(int64(transfer.Flags))
+	w.long(int64(transfer.Timestamp))
+	return w.data
+}
+
+func encodeBalanceAvro(balance BalanceEvent) []byte {
+	w := avroWriter{}
+	w.string(balance.AccountID.String())
+	w.string(balance.DebitsPending.String())
+	w.string(balance.DebitsPosted.String())
+	w.string(balance.CreditsPending.String())
+	w.string(balance.CreditsPosted.String())
+	w.long(int64(balance.Timestamp))
+	return w.data
+}
@@ -0,0 +1,258 @@
+// Package cdc captures the changes of a set of accounts as records for a message broker such as
+// Kafka: a record per transfer, keyed by the transfer ID, and a record per balance of the accounts
+// with the history flag, keyed by the account ID.
+//
+// The cluster has no change feed, so the accounts are polled with GetAccountTransfers and
+// GetAccountHistory from a cursor per account. A Publisher writes each batch of records together
+// with the cursors after it, atomically, so that resuming from the last checkpoint publishes
+// every record exactly once. A transfer between two tailed accounts is published once, through
+// its debit account.
+//
+// Records are encoded as JSON, with the fields of types.Transfer and of types.AccountBalance plus
+// account_id, or as Avro binary, with the schemas TransferSchema and BalanceSchema.
+package cdc
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Avro schemas of the records of the transfers and balances topics.
+var (
+	//go:embed schemas/transfer.avsc
+	TransferSchema string
+	//go:embed schemas/balance.avsc
+	BalanceSchema string
+)
+
+// Client is the part of the TigerBeetle client that change capture polls.
+type Client interface {
+	GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error)
+	GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error)
+}
+
+// Format is the encoding of the records. It implements flag.Value.
+type Format uint8
+
+const (
+	FormatJSON Format = iota
+	FormatAvro
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatAvro:
+		return "avro"
+	default:
+		return "json"
+	}
+}
+
+// Set parses one of "json" or "avro".
+func (f *Format) Set(value string) error {
+	switch value {
+	case "json":
+		*f = FormatJSON
+	case "avro":
+		*f = FormatAvro
+	default:
+		return fmt.Errorf("unknown record format %q, expected json or avro", value)
+	}
+	return nil
+}
+
+// Record is a message to publish.
+type Record struct {
+	Topic string
+	Key   []byte
+	Value []byte
+	// ID identifies the record within its topic, for brokers that deduplicate messages by ID:
+	// the ID of the transfer, or the ID of the account and the timestamp of the balance, as
+	// "<account>/<timestamp>".
+	ID string
+}
+
+// Checkpoint holds, for every account by decimal ID, the timestamp of the last transfer and of the
+// last balance captured.
+type Checkpoint struct {
+	Transfers map[string]uint64 `json:"transfers"`
+	Balances  map[string]uint64 `json:"balances"`
+}
+
+func (c Checkpoint) clone() Checkpoint {
+	clone := Checkpoint{
+		Transfers: make(map[string]uint64, len(c.Transfers)),
+		Balances:  make(map[string]uint64, len(c.Balances)),
+	}
+	for account, timestamp := range c.Transfers {
+		clone.Transfers[account] = timestamp
+	}
+	for account, timestamp := range c.Balances {
+		clone.Balances[account] = timestamp
+	}
+	return clone
+}
+
+// Publisher writes records to the broker.
+type Publisher interface {
+	// Publish writes records, in order, and checkpoint, atomically.
+	Publish(ctx context.Context, records []Record, checkpoint Checkpoint) error
+}
+
+// BalanceEvent is the balance of an account, as published.
+type BalanceEvent struct {
+	AccountID types.Uint128 `json:"account_id"`
+	types.AccountBalance
+}
+
+// Config describes a change capture. Zero fields take their defaults.
+type Config struct {
+	Accounts []types.Uint128
+	// TransfersTopic and BalancesTopic default to "tigerbeetle.transfers" and
+	// "tigerbeetle.balances".
+	TransfersTopic string
+	BalancesTopic  string
+	Format         Format
+	// Resume is the checkpoint to resume from, as last published.
+	Resume Checkpoint
+	// PollInterval is how long to wait after catching up before polling again. Defaults to 1s.
+	PollInterval time.Duration
+}
+
+// Capture captures the changes of accounts.
+type Capture struct {
+	client     Client
+	config     Config
+	tailed     map[types.Uint128]bool
+	checkpoint Checkpoint
+}
+
+// New returns a capture that polls client as described by config.
+func New(client Client, config Config) *Capture {
+	if config.TransfersTopic == "" {
+		config.TransfersTopic = "tigerbeetle.transfers"
+	}
+	if config.BalancesTopic == "" {
+		config.BalancesTopic = "tigerbeetle.balances"
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	tailed := make(map[types.Uint128]bool, len(config.Accounts))
+	for _, account := range config.Accounts {
+		tailed[account] = true
+	}
+	return &Capture{client: client, config: config, tailed: tailed, checkpoint: config.Resume.clone()}
+}
+
+// Run publishes the changes as they happen, until ctx is done or polling or publishing fails.
+func (c *Capture) Run(ctx context.Context, publisher Publisher) error {
+	for {
+		records, checkpoint, caughtUp, err := c.Poll()
+		if err != nil {
+			return err
+		}
+		if len(records) > 0 {
+			if err := publisher.Publish(ctx, records, checkpoint); err != nil {
+				return fmt.Errorf("cdc: publishing: %w", err)
+			}
+		}
+		c.checkpoint = checkpoint
+
+		if caughtUp {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.config.PollInterval):
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// Poll reads a page of changes of every account, returning their records and the checkpoint
+// after them, without advancing the capture, and whether every account was caught up.
+func (c *Capture) Poll() ([]Record, Checkpoint, bool, error) {
+	checkpoint := c.checkpoint.clone()
+	caughtUp := true
+	var records []Record
+	for _, account := range c.config.Accounts {
+		key := account.String()
+
+		transfers, err := c.client.GetAccountTransfers(c.filter(account, checkpoint.Transfers[key]))
+		if err != nil {
+			return nil, Checkpoint{}, false, fmt.Errorf("cdc: account %s: %w", key, err)
+		}
+		for _, transfer := range transfers {
+			checkpoint.Transfers[key] = transfer.Timestamp
+			if transfer.DebitAccountID != account && c.tailed[transfer.DebitAccountID] {
+				continue
+			}
+			value, err := c.encodeTransfer(transfer)
+			if err != nil {
+				return nil, Checkpoint{}, false, err
+			}
+			records = append(records, Record{
+				Topic: c.config.TransfersTopic,
+				Key:   []byte(transfer.ID.String()),
+				Value: value,
+				ID:    transfer.ID.String(),
+			})
+		}
+
+		balances, err := c.client.GetAccountHistory(c.filter(account, checkpoint.Balances[key]))
+		if err != nil {
+			return nil, Checkpoint{}, false, fmt.Errorf("cdc: account %s: %w", key, err)
+		}
+		for _, balance := range balances {
+			checkpoint.Balances[key] = balance.Timestamp
+			value, err := c.encodeBalance(BalanceEvent{AccountID: account, AccountBalance: balance})
+			if err != nil {
+				return nil, Checkpoint{}, false, err
+			}
+			records = append(records, Record{
+				Topic: c.config.BalancesTopic,
+				Key:   []byte(key),
+				Value: value,
+				ID:    key + "/" + strconv.FormatUint(balance.Timestamp, 10),
+			})
+		}
+
+		limit := types.MaxBatchSize(types.OperationGetAccountTransfers)
+		if len(transfers) == limit || len(balances) == limit {
+			caughtUp = false
+		}
+	}
+	return records, checkpoint, caughtUp, nil
+}
+
+// filter reads the changes of account after timestamp.
+func (c *Capture) filter(account types.Uint128, timestamp uint64) types.AccountFilter {
+	return types.AccountFilter{
+		AccountID:    account,
+		TimestampMin: timestamp + 1,
+		Limit:        uint32(types.MaxBatchSize(types.OperationGetAccountTransfers)),
+		Flags:        types.AccountFilterFlags{Debits: true, Credits: true}.ToUint32(),
+	}
+}
+
+func (c *Capture) encodeTransfer(transfer types.Transfer) ([]byte, error) {
+	if c.config.Format == FormatAvro {
+		return encodeTransferAvro(transfer), nil
+	}
+	return json.Marshal(transfer)
+}
+
+func (c *Capture) encodeBalance(balance BalanceEvent) ([]byte, error) {
+	if c.config.Format == FormatAvro {
+		return encodeBalanceAvro(balance), nil
+	}
+	return json.Marshal(balance)
+}
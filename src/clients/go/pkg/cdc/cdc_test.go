@@ -0,0 +1,149 @@
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// ledgerClient serves the transfers and balances it holds, in order of timestamp.
+type ledgerClient struct {
+	transfers []types.Transfer
+	balances  map[types.Uint128][]types.AccountBalance
+}
+
+func (c *ledgerClient) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	var results []types.Transfer
+	for _, transfer := range c.transfers {
+		if transfer.Timestamp >= filter.TimestampMin &&
+			(transfer.DebitAccountID == filter.AccountID || transfer.CreditAccountID == filter.AccountID) {
+			results = append(results, transfer)
+		}
+	}
+	return results, nil
+}
+
+func (c *ledgerClient) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	var results []types.AccountBalance
+	for _, balance := range c.balances[filter.AccountID] {
+		if balance.Timestamp >= filter.TimestampMin {
+			results = append(results, balance)
+		}
+	}
+	return results, nil
+}
+
+type recordingPublisher struct {
+	records     []Record
+	checkpoints []Checkpoint
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, records []Record, checkpoint Checkpoint) error {
+	p.records = append(p.records, records...)
+	p.checkpoints = append(p.checkpoints, checkpoint)
+	return nil
+}
+
+func keys(records []Record) []string {
+	var keys []string
+	for _, record := range records {
+		keys = append(keys, record.Topic+"/"+string(record.Key))
+	}
+	return keys
+}
+
+func TestCapture(t *testing.T) {
+	client := &ledgerClient{
+		transfers: []types.Transfer{
+			// Between two tailed accounts, published once.
+			{ID: types.ToUint128(10), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2), Amount: types.ToUint128(5), Timestamp: 100},
+			// From an account that isn't tailed, published through its credit account.
+			{ID: types.ToUint128(11), DebitAccountID: types.ToUint128(3), CreditAccountID: types.ToUint128(2), Amount: types.ToUint128(7), Timestamp: 101},
+		},
+		balances: map[types.Uint128][]types.AccountBalance{
+			types.ToUint128(1): {{DebitsPosted: types.ToUint128(5), Timestamp: 100}},
+		},
+	}
+	accounts := []types.Uint128{types.ToUint128(1), types.ToUint128(2)}
+	capture := New(client, Config{Accounts: accounts})
+
+	records, checkpoint, caughtUp, err := capture.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, caughtUp)
+	assert.Equal(t, []string{
+		"tigerbeetle.transfers/10",
+		"tigerbeetle.balances/1",
+		"tigerbeetle.transfers/11",
+	}, keys(records))
+	assert.Equal(t, []string{"10", "1/100", "11"}, []string{records[0].ID, records[1].ID, records[2].ID})
+	assert.Equal(t, Checkpoint{
+		Transfers: map[string]uint64{"1": 100, "2": 101},
+		Balances:  map[string]uint64{"1": 100},
+	}, checkpoint)
+
+	var transfer types.Transfer
+	if err := json.Unmarshal(records[0].Value, &transfer); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, client.transfers[0], transfer)
+	var balance BalanceEvent
+	if err := json.Unmarshal(records[1].Value, &balance); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, BalanceEvent{AccountID: types.ToUint128(1), AccountBalance: client.balances[types.ToUint128(1)][0]}, balance)
+
+	// Resuming from the checkpoint publishes only what came after it.
+	client.transfers = append(client.transfers, types.Transfer{
+		ID: types.ToUint128(12), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1), Timestamp: 102,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	publisher := &recordingPublisher{}
+	capture = New(client, Config{Accounts: accounts, Resume: checkpoint})
+	records, next, _, err := capture.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"tigerbeetle.transfers/12"}, keys(records))
+	assert.Equal(t, uint64(102), next.Transfers["1"])
+	assert.Equal(t, uint64(100), checkpoint.Transfers["1"])
+
+	cancel()
+	assert.Equal(t, context.Canceled, capture.Run(ctx, publisher))
+	assert.Equal(t, []string{"tigerbeetle.transfers/12"}, keys(publisher.records))
+	assert.Equal(t, []Checkpoint{next}, publisher.checkpoints)
+}
+
+func TestCaptureAvro(t *testing.T) {
+	client := &ledgerClient{balances: map[types.Uint128][]types.AccountBalance{
+		types.ToUint128(1): {{CreditsPosted: types.ToUint128(42), Timestamp: 1}},
+	}}
+	records, _, _, err := New(client, Config{Accounts: []types.Uint128{types.ToUint128(1)}, Format: FormatAvro}).Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(records))
+	// Strings are prefixed with their zig-zag length, 2 per byte, and the timestamp is zig-zag.
+	assert.Equal(t, []byte{
+		2, '1',
+		2, '0',
+		2, '0',
+		2, '0',
+		4, '4', '2',
+		2,
+	}, records[0].Value)
+
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(TransferSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Transfer", schema["name"])
+	if err := json.Unmarshal([]byte(BalanceSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Balance", schema["name"])
+}
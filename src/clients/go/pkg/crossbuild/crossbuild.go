@@ -0,0 +1,103 @@
+// Package crossbuild cross-compiles programs that use the client for the other platforms that
+// the client ships a static library for.
+//
+// The client links libtb_client with cgo, which selects the static library of the target from
+// pkg/native with build constraints, but also needs a C compiler and linker for the target.
+// Without one, `GOOS=linux GOARCH=arm64 go build` fails on a macOS host. zig cc targets every
+// platform of the client out of the box, so a Target sets up the environment for go build to
+// use it. The static libraries can't be embedded with go:embed instead, since cgo links from
+// files on disk rather than from the compiled program.
+package crossbuild
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Target is a platform the client ships a static library for.
+type Target struct {
+	GOOS   string
+	GOARCH string
+	// Native is the directory of the static library under pkg/native.
+	Native string
+	// Library is the file name of the static library.
+	Library string
+	// Zig is the target of zig cc.
+	Zig string
+}
+
+// Targets are the platforms of the static libraries built by `zig build go_client`.
+var Targets = []Target{
+	{GOOS: "linux", GOARCH: "amd64", Native: "x86_64-linux", Library: "libtb_client.a", Zig: "x86_64-linux-gnu"},
+	{GOOS: "linux", GOARCH: "arm64", Native: "aarch64-linux", Library: "libtb_client.a", Zig: "aarch64-linux-gnu"},
+	{GOOS: "darwin", GOARCH: "amd64", Native: "x86_64-macos", Library: "libtb_client.a", Zig: "x86_64-macos"},
+	{GOOS: "darwin", GOARCH: "arm64", Native: "aarch64-macos", Library: "libtb_client.a", Zig: "aarch64-macos"},
+	{GOOS: "windows", GOARCH: "amd64", Native: "x86_64-windows", Library: "tb_client.lib", Zig: "x86_64-windows-gnu"},
+}
+
+// ErrMissingLibrary is returned when the static library of a target isn't in the module.
+type ErrMissingLibrary struct {
+	Target Target
+	Path   string
+}
+
+func (e ErrMissingLibrary) Error() string {
+	return fmt.Sprintf("crossbuild: no static library for %s/%s at %s, build it with "+
+		"`zig build go_client -Drelease -Dconfig=production` from the TigerBeetle repository",
+		e.Target.GOOS, e.Target.GOARCH, e.Path)
+}
+
+// Lookup returns the target of a GOOS and GOARCH.
+func Lookup(goos string, goarch string) (Target, error) {
+	for _, target := range Targets {
+		if target.GOOS == goos && target.GOARCH == goarch {
+			return target, nil
+		}
+	}
+	supported := make([]string, len(Targets))
+	for i, target := range Targets {
+		supported[i] = target.GOOS + "/" + target.GOARCH
+	}
+	return Target{}, fmt.Errorf("crossbuild: %s/%s is not supported, only %s",
+		goos, goarch, strings.Join(supported, ", "))
+}
+
+// LibraryPath returns the path of the static library of the target in the source of the client
+// module at moduleDir.
+func (t Target) LibraryPath(moduleDir string) string {
+	return filepath.Join(moduleDir, "pkg", "native", t.Native, t.Library)
+}
+
+// Check returns ErrMissingLibrary unless the module at moduleDir has the static library of the
+// target.
+func (t Target) Check(moduleDir string) error {
+	path := t.LibraryPath(moduleDir)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrMissingLibrary{Target: t, Path: path}
+		}
+		return err
+	}
+	return nil
+}
+
+// Env returns the environment variables for go build to compile for the target with zig cc,
+// where zig is the path of the zig executable.
+func (t Target) Env(zig string) []string {
+	// go build splits CC on spaces, except within quotes.
+	if strings.ContainsAny(zig, " \t") {
+		zig = "'" + zig + "'"
+	}
+	cc := zig + " cc -target " + t.Zig
+	cxx := zig + " c++ -target " + t.Zig
+	return []string{
+		"GOOS=" + t.GOOS,
+		"GOARCH=" + t.GOARCH,
+		"CGO_ENABLED=1",
+		"CC=" + cc,
+		"CXX=" + cxx,
+	}
+}
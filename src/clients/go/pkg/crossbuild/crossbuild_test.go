@@ -0,0 +1,61 @@
+package crossbuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func Test_Lookup(t *testing.T) {
+	target, err := Lookup("linux", "arm64")
+	if err != nil || target.Native != "aarch64-linux" || target.Zig != "aarch64-linux-gnu" {
+		t.Fatalf("Expected the aarch64-linux target, got %+v %v", target, err)
+	}
+	if _, err := Lookup("plan9", "386"); err == nil {
+		t.Fatalf("Expected plan9/386 to be unsupported")
+	}
+}
+
+func Test_Env(t *testing.T) {
+	target, _ := Lookup("darwin", "arm64")
+	env := target.Env("/opt/zig/zig")
+	for _, expected := range []string{
+		"GOOS=darwin",
+		"GOARCH=arm64",
+		"CGO_ENABLED=1",
+		"CC=/opt/zig/zig cc -target aarch64-macos",
+	} {
+		if !slices.Contains(env, expected) {
+			t.Fatalf("Expected %q in %v", expected, env)
+		}
+	}
+
+	env = target.Env("/Applications/Zig Tools/zig")
+	if !slices.Contains(env, "CC='/Applications/Zig Tools/zig' cc -target aarch64-macos") {
+		t.Fatalf("Expected a path with spaces to be quoted, got %v", env)
+	}
+}
+
+func Test_Check(t *testing.T) {
+	// The module in this repository has the static library of the host, once built.
+	moduleDir, _ := filepath.Abs(filepath.Join("..", ".."))
+	host, err := Lookup(runtime.GOOS, runtime.GOARCH)
+	if err == nil {
+		if _, statErr := os.Stat(host.LibraryPath(moduleDir)); statErr == nil {
+			if err := host.Check(moduleDir); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	empty := t.TempDir()
+	target, _ := Lookup("windows", "amd64")
+	var missing ErrMissingLibrary
+	if err := target.Check(empty); !errors.As(err, &missing) ||
+		missing.Path != filepath.Join(empty, "pkg", "native", "x86_64-windows", "tb_client.lib") {
+		t.Fatalf("Expected the library to be missing, got %v", err)
+	}
+}
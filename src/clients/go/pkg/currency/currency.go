@@ -0,0 +1,164 @@
+// Package currency maps ledgers to the currencies they hold, and converts between decimal money
+// and the integer amounts of TigerBeetle.
+//
+// A ledger holds a single currency, in units of 10^-Exponent of it: cents for USD, with an
+// exponent of 2, or yen for JPY, with an exponent of 0. Converting "12.34" USD by hand is where
+// off-by-exponent bugs creep in, so a Registry does it from the exponent registered for the
+// ledger, and rejects amounts with more decimal places than the ledger can hold instead of
+// rounding them away.
+package currency
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// exponentMax is the largest exponent of a currency: an amount is at most 39 digits, so a larger
+// exponent couldn't even represent a single unit.
+const exponentMax = 38
+
+// Currency is the currency of a ledger.
+type Currency struct {
+	// Code is the ISO 4217 code of the currency, such as "USD", or any other name for the
+	// assets that aren't currencies.
+	Code string
+	// Exponent is the number of decimal places of an amount: the amount 1 is 10^-Exponent of
+	// the currency.
+	Exponent uint8
+}
+
+// ErrUnknownLedger is returned for a ledger that has no currency registered.
+type ErrUnknownLedger struct {
+	Ledger uint32
+}
+
+func (e ErrUnknownLedger) Error() string {
+	return fmt.Sprintf("currency: ledger %d has no currency registered", e.Ledger)
+}
+
+// ErrInvalidAmount is returned when a decimal amount can't be converted exactly.
+type ErrInvalidAmount struct {
+	Amount string
+	Reason string
+}
+
+func (e ErrInvalidAmount) Error() string {
+	return fmt.Sprintf("currency: invalid amount %q: %s", e.Amount, e.Reason)
+}
+
+// Registry maps ledgers to their currency. It is safe for concurrent use.
+type Registry struct {
+	mutex   sync.RWMutex
+	ledgers map[uint32]Currency
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{ledgers: make(map[uint32]Currency)}
+}
+
+// Register sets the currency of a ledger. Changing the currency of a ledger with amounts already
+// in it would change what they mean, so a ledger may only be registered once.
+func (r *Registry) Register(ledger uint32, currency Currency) error {
+	if ledger == 0 {
+		return fmt.Errorf("currency: ledger must not be zero")
+	}
+	if currency.Exponent > exponentMax {
+		return fmt.Errorf("currency: exponent %d of %s is larger than %d",
+			currency.Exponent, currency.Code, exponentMax)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if registered, ok := r.ledgers[ledger]; ok {
+		return fmt.Errorf("currency: ledger %d is already registered as %s", ledger, registered.Code)
+	}
+	r.ledgers[ledger] = currency
+	return nil
+}
+
+// Lookup returns the currency of a ledger, if registered.
+func (r *Registry) Lookup(ledger uint32) (Currency, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	currency, ok := r.ledgers[ledger]
+	return currency, ok
+}
+
+// FormatAmount formats an amount of a ledger as a decimal, such as "12.34" for 1234 cents.
+func (r *Registry) FormatAmount(ledger uint32, amount types.Uint128) (string, error) {
+	currency, ok := r.Lookup(ledger)
+	if !ok {
+		return "", ErrUnknownLedger{Ledger: ledger}
+	}
+	return currency.Format(amount), nil
+}
+
+// ParseAmount converts a decimal, such as "12.34", to an amount of a ledger.
+func (r *Registry) ParseAmount(value string, ledger uint32) (types.Uint128, error) {
+	currency, ok := r.Lookup(ledger)
+	if !ok {
+		return types.Uint128{}, ErrUnknownLedger{Ledger: ledger}
+	}
+	return currency.Parse(value)
+}
+
+// Format formats an amount as a decimal with exactly Exponent decimal places.
+func (c Currency) Format(amount types.Uint128) string {
+	digits := amount.String()
+	exponent := int(c.Exponent)
+	if exponent == 0 {
+		return digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// Parse converts a decimal made of digits, with an optional decimal point, to an amount. It
+// returns ErrInvalidAmount for a decimal with more decimal places than Exponent, rather than
+// rounding, as well as for a negative or out of range one.
+func (c Currency) Parse(value string) (types.Uint128, error) {
+	whole, fraction, point := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return types.Uint128{}, ErrInvalidAmount{Amount: value, Reason: "no digits"}
+	}
+	if point && fraction == "" {
+		return types.Uint128{}, ErrInvalidAmount{
+			Amount: value,
+			Reason: "no digits after the decimal point",
+		}
+	}
+	for _, digit := range whole + fraction {
+		if digit < '0' || digit > '9' {
+			return types.Uint128{}, ErrInvalidAmount{
+				Amount: value,
+				Reason: "must only contain digits and a decimal point",
+			}
+		}
+	}
+
+	// Trailing zeros don't add precision: "12.340" is a valid amount of cents.
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > int(c.Exponent) {
+		return types.Uint128{}, ErrInvalidAmount{
+			Amount: value,
+			Reason: fmt.Sprintf("more than %d decimal places for %s", c.Exponent, c.Code),
+		}
+	}
+
+	digits := whole + fraction + strings.Repeat("0", int(c.Exponent)-len(fraction))
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return types.ToUint128(0), nil
+	}
+	amount, err := types.DecStringToUint128(digits)
+	if err != nil {
+		return types.Uint128{}, ErrInvalidAmount{Amount: value, Reason: "out of range"}
+	}
+	return amount, nil
+}
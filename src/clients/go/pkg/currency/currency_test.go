@@ -0,0 +1,84 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func Test_Registry(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(1, Currency{Code: "USD", Exponent: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(2, Currency{Code: "JPY", Exponent: 0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register(1, Currency{Code: "EUR", Exponent: 2}); err == nil {
+		t.Fatalf("Expected a ledger to be registered only once")
+	}
+
+	amount, err := registry.ParseAmount("12.34", 1)
+	if err != nil || amount != types.ToUint128(1234) {
+		t.Fatalf("Expected 12.34 USD to be 1234, got %s %v", amount, err)
+	}
+	amount, err = registry.ParseAmount("12.34", 2)
+	var invalid ErrInvalidAmount
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected 12.34 JPY to be rejected, got %s %v", amount, err)
+	}
+
+	formatted, err := registry.FormatAmount(1, types.ToUint128(1234))
+	if err != nil || formatted != "12.34" {
+		t.Fatalf("Expected 1234 USD to be 12.34, got %q %v", formatted, err)
+	}
+	var unknown ErrUnknownLedger
+	if _, err := registry.FormatAmount(3, types.ToUint128(1)); !errors.As(err, &unknown) || unknown.Ledger != 3 {
+		t.Fatalf("Expected ledger 3 to be unknown, got %v", err)
+	}
+}
+
+func Test_Currency(t *testing.T) {
+	usd := Currency{Code: "USD", Exponent: 2}
+	btc := Currency{Code: "BTC", Exponent: 8}
+	jpy := Currency{Code: "JPY", Exponent: 0}
+
+	tests := []struct {
+		currency Currency
+		value    string
+		amount   uint64
+		format   string
+	}{
+		{usd, "0", 0, "0.00"},
+		{usd, "0.05", 5, "0.05"},
+		{usd, ".5", 50, "0.50"},
+		{usd, "7", 700, "7.00"},
+		{usd, "12.340", 1234, "12.34"},
+		{usd, "00012.3", 1230, "12.30"},
+		{btc, "0.00000001", 1, "0.00000001"},
+		{btc, "21000000", 2100000000000000, "21000000.00000000"},
+		{jpy, "1500", 1500, "1500"},
+		{jpy, "1500.0", 1500, "1500"},
+	}
+	for _, test := range tests {
+		amount, err := test.currency.Parse(test.value)
+		if err != nil || amount != types.ToUint128(test.amount) {
+			t.Fatalf("Expected %s %s to be %d, got %s %v", test.value, test.currency.Code, test.amount, amount, err)
+		}
+		if format := test.currency.Format(amount); format != test.format {
+			t.Fatalf("Expected %d %s to format as %s, got %s", test.amount, test.currency.Code, test.format, format)
+		}
+	}
+
+	for _, value := range []string{"", ".", "1.", "12.345", "-1", "+1", "1e3", "1,000", " 1", "1.2.3",
+		"3402823669209384634633746074317682114.56"} {
+		if amount, err := usd.Parse(value); err == nil {
+			t.Fatalf("Expected %q to be rejected, got %s", value, amount)
+		}
+	}
+
+	if amount, err := usd.Parse(usd.Format(types.AmountMax)); err != nil || amount != types.AmountMax {
+		t.Fatalf("Expected the largest amount to round trip, got %s %v", amount, err)
+	}
+}
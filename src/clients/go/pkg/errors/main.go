@@ -1,5 +1,12 @@
 package errors
 
+import (
+	"strconv"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
 type ErrUnexpected struct{}
 
 func (s ErrUnexpected) Error() string { return "Unexpected internal error." }
@@ -40,6 +47,22 @@ func (s ErrConcurrencyExceeded) Error() string {
 	return "The maximum configured concurrency for the client has been exceeded."
 }
 
+// ErrCircuitOpen is returned for a request that the circuit breaker of WithCircuitBreaker failed
+// without submitting it, as the cluster has stopped answering in time.
+type ErrCircuitOpen struct{}
+
+func (s ErrCircuitOpen) Error() string {
+	return "The circuit breaker of the client is open after repeated timeouts."
+}
+
+// ErrRateLimited is returned by TryCreateAccounts and TryCreateTransfers when the rate limit of
+// WithRateLimit has no room for their events yet.
+type ErrRateLimited struct{}
+
+func (s ErrRateLimited) Error() string {
+	return "The rate limit configured for the client has been exceeded."
+}
+
 type ErrInvalidOperation struct{}
 
 func (s ErrInvalidOperation) Error() string { return "internal operation provided was invalid." }
@@ -51,3 +74,307 @@ func (s ErrEmptyBatch) Error() string { return "Empty batch." }
 type ErrMaximumBatchSizeExceeded struct{}
 
 func (s ErrMaximumBatchSizeExceeded) Error() string { return "Maximum batch size exceeded." }
+
+// ErrBatchTooLarge is returned, before submitting, for a batch with more events than fit in a
+// request. It matches ErrMaximumBatchSizeExceeded with errors.Is.
+type ErrBatchTooLarge struct {
+	Operation types.Operation
+	Count     int
+	Max       int
+}
+
+func (s ErrBatchTooLarge) Error() string {
+	return "Batch of " + strconv.Itoa(s.Count) + " events exceeds the maximum of " +
+		strconv.Itoa(s.Max) + " for " + s.Operation.String() + "."
+}
+
+func (s ErrBatchTooLarge) Is(target error) bool {
+	return target == ErrMaximumBatchSizeExceeded{}
+}
+
+// ErrBufferTooSmall is returned, before submitting, when the buffer given to decode the results
+// into has room for fewer than the request may reply with.
+type ErrBufferTooSmall struct {
+	Capacity int
+	Needed   int
+}
+
+func (s ErrBufferTooSmall) Error() string {
+	return "Buffer for " + strconv.Itoa(s.Capacity) + " results is too small for the " +
+		strconv.Itoa(s.Needed) + " that the request may reply with."
+}
+
+// ErrReplyTooLarge is returned when a transport replies with more bytes than the results of the
+// request may take up.
+type ErrReplyTooLarge struct {
+	Size int
+	Max  int
+}
+
+func (s ErrReplyTooLarge) Error() string {
+	return "Reply of " + strconv.Itoa(s.Size) + " bytes exceeds the maximum of " +
+		strconv.Itoa(s.Max) + " for the request."
+}
+
+// ErrSessionEvicted is returned for requests of a client whose session the cluster evicted. The
+// client must be recreated to register a new session. Reason is that of the cluster:
+// "no_session" when more clients connected than it has sessions for, or "release_too_low" and
+// "release_too_high" when the client's release is not one the cluster supports, followed by the
+// cluster's release.
+type ErrSessionEvicted struct {
+	Reason string
+}
+
+func (s ErrSessionEvicted) Error() string { return "Session evicted: " + s.Reason + "." }
+
+// ErrRequestTimeout is returned for a request without a reply within its timeout. The request
+// may still be executed by the cluster.
+type ErrRequestTimeout struct {
+	Operation types.Operation
+	Timeout   time.Duration
+}
+
+func (s ErrRequestTimeout) Error() string {
+	return "Request for " + s.Operation.String() + " timed out after " + s.Timeout.String() + "."
+}
+
+type ErrCreateAccount struct {
+	Result types.CreateAccountResult
+}
+
+func (s ErrCreateAccount) Error() string { return "Create account failed: " + s.Result.String() + "." }
+
+type ErrCreateTransfer struct {
+	Result types.CreateTransferResult
+}
+
+func (s ErrCreateTransfer) Error() string {
+	return "Create transfer failed: " + s.Result.String() + "."
+}
+
+type ErrTenantMissing struct{}
+
+func (s ErrTenantMissing) Error() string { return "No tenant in context." }
+
+type ErrTenantMismatch struct {
+	Index int
+}
+
+func (s ErrTenantMismatch) Error() string {
+	return "Event " + strconv.Itoa(s.Index) + " does not belong to the tenant."
+}
+
+// ErrLedgerNotAllowed is returned, before submitting, for a batch of which event Index is on a
+// ledger that the tenant of the request may not use.
+type ErrLedgerNotAllowed struct {
+	Index  int
+	Ledger uint32
+}
+
+func (s ErrLedgerNotAllowed) Error() string {
+	return "Event " + strconv.Itoa(s.Index) + " is on ledger " + strconv.FormatUint(uint64(s.Ledger), 10) +
+		", which the tenant may not use."
+}
+
+// ErrTenantOperation is returned for a request on behalf of a tenant whose operation can't be
+// confined to the tenant's ledgers.
+type ErrTenantOperation struct {
+	Operation types.Operation
+}
+
+func (s ErrTenantOperation) Error() string {
+	return "Operation " + s.Operation.String() + " is not allowed for tenants."
+}
+
+// ErrUnauthorized is returned, before submitting, for a request that an authorizer vetoed:
+// because of event Index, or of the whole request if Index is -1.
+type ErrUnauthorized struct {
+	Operation types.Operation
+	Index     int
+	Reason    string
+}
+
+func (s ErrUnauthorized) Error() string {
+	if s.Index < 0 {
+		return s.Operation.String() + " is not authorized: " + s.Reason + "."
+	}
+	return "Event " + strconv.Itoa(s.Index) + " of " + s.Operation.String() + " is not authorized: " +
+		s.Reason + "."
+}
+
+// ErrCrossCluster is returned, before submitting, for a batch of which event Index is a
+// transfer between accounts of different clusters, or links events of different clusters.
+type ErrCrossCluster struct {
+	Index int
+}
+
+func (s ErrCrossCluster) Error() string {
+	return "Event " + strconv.Itoa(s.Index) + " spans clusters."
+}
+
+// ErrInvalidRoute is returned, before submitting, when event Index is routed to a cluster that
+// the router does not have.
+type ErrInvalidRoute struct {
+	Index   int
+	Cluster int
+}
+
+func (s ErrInvalidRoute) Error() string {
+	return "Event " + strconv.Itoa(s.Index) + " is routed to unknown cluster " + strconv.Itoa(s.Cluster) + "."
+}
+
+// ErrClusterFailed is returned when the request to one of the clusters of a router failed,
+// while those to the others may have succeeded.
+type ErrClusterFailed struct {
+	Cluster int
+	Err     error
+}
+
+func (s ErrClusterFailed) Error() string {
+	return "Cluster " + strconv.Itoa(s.Cluster) + ": " + s.Err.Error()
+}
+
+func (s ErrClusterFailed) Unwrap() error { return s.Err }
+
+// ErrFailedOver is returned for a write submitted to a FailoverClient while it sends its reads
+// to the secondary cluster, unless writes are queued until the switchback, and for
+// TryCreateAccounts and TryCreateTransfers even then.
+type ErrFailedOver struct{}
+
+func (s ErrFailedOver) Error() string {
+	return "Writes are refused while failed over to the secondary cluster."
+}
+
+// ErrFailoverQueueFull is returned for a write submitted to a FailoverClient while failed over,
+// when as many writes as FailoverPolicy.QueueMax are queued already.
+type ErrFailoverQueueFull struct{}
+
+func (s ErrFailoverQueueFull) Error() string {
+	return "The queue of writes held until the switchback to the primary cluster is full."
+}
+
+// ErrAddressResolution is returned when a replica address could not be resolved with DNS.
+type ErrAddressResolution struct {
+	Address string
+	Err     error
+}
+
+func (s ErrAddressResolution) Error() string {
+	return "Address " + s.Address + " could not be resolved: " + s.Err.Error()
+}
+
+func (s ErrAddressResolution) Unwrap() error { return s.Err }
+
+type ErrInvalidRecording struct{}
+
+func (s ErrInvalidRecording) Error() string { return "Invalid or truncated recording." }
+
+type ErrReplayMismatch struct {
+	Operation types.Operation
+}
+
+func (s ErrReplayMismatch) Error() string {
+	return "No recorded reply left for " + s.Operation.String() + " with these events."
+}
+
+// ErrInvalidAccount is returned, before submitting, for a batch with an account that fails
+// its local validation.
+type ErrInvalidAccount struct {
+	Index  int
+	Result types.CreateAccountResult
+}
+
+func (s ErrInvalidAccount) Error() string {
+	return "Account " + strconv.Itoa(s.Index) + " is invalid: " + s.Result.String() + "."
+}
+
+// ErrInvalidTransfer is returned, before submitting, for a batch with a transfer that fails
+// its local validation.
+type ErrInvalidTransfer struct {
+	Index  int
+	Result types.CreateTransferResult
+}
+
+func (s ErrInvalidTransfer) Error() string {
+	return "Transfer " + strconv.Itoa(s.Index) + " is invalid: " + s.Result.String() + "."
+}
+
+// ErrDuplicateID is returned, before submitting, for a batch with two events of the same ID,
+// which the cluster would answer with an exists result for the second one.
+type ErrDuplicateID struct {
+	ID         types.Uint128
+	FirstIndex int
+	Index      int
+}
+
+func (s ErrDuplicateID) Error() string {
+	return "Event " + strconv.Itoa(s.Index) + " has the same ID as event " + strconv.Itoa(s.FirstIndex) +
+		": " + s.ID.String() + "."
+}
+
+type ErrMaintenance struct{}
+
+func (s ErrMaintenance) Error() string { return "Client is paused for maintenance." }
+
+type ErrUpdateAddressesUnsupported struct{}
+
+func (s ErrUpdateAddressesUnsupported) Error() string {
+	return "Addresses can only be updated on a client that connects through tb_client."
+}
+
+// ErrInvalidBodySize is returned, before submitting, for a raw request whose body is not made of
+// whole events of the operation.
+type ErrInvalidBodySize struct {
+	Operation types.Operation
+	Size      int
+	EventSize int
+}
+
+func (s ErrInvalidBodySize) Error() string {
+	return "Body of " + strconv.Itoa(s.Size) + " bytes is not a multiple of the event size " +
+		strconv.Itoa(s.EventSize) + " of " + s.Operation.String() + "."
+}
+
+type ErrSubmitRawUnsupported struct{}
+
+func (s ErrSubmitRawUnsupported) Error() string {
+	return "Raw requests are not supported by a client restricted to a tenant."
+}
+
+// ErrNativeUnavailable is returned by NewClient without WithTransport in a build without cgo,
+// such as for WebAssembly, which can't link tb_client.
+type ErrNativeUnavailable struct{}
+
+func (s ErrNativeUnavailable) Error() string {
+	return "tb_client is not available without cgo, submit requests through WithTransport."
+}
+
+// ErrHost is returned when the host of a WebAssembly client fails a request for a reason of its
+// own.
+type ErrHost struct {
+	Message string
+}
+
+func (s ErrHost) Error() string { return "Host failed the request: " + s.Message + "." }
+
+// ErrInterceptorResult is returned for a request whose interceptors replied with results of
+// another type than the operation's.
+type ErrInterceptorResult struct {
+	Operation types.Operation
+	Result    any
+}
+
+func (s ErrInterceptorResult) Error() string {
+	return "Interceptor of " + s.Operation.String() + " replied with results of the wrong type."
+}
+
+// ErrInterceptorEvents is returned for a request whose interceptors passed on events of another
+// type than the operation's.
+type ErrInterceptorEvents struct {
+	Operation types.Operation
+	Events    any
+}
+
+func (s ErrInterceptorEvents) Error() string {
+	return "Interceptor of " + s.Operation.String() + " passed on events of the wrong type."
+}
@@ -0,0 +1,209 @@
+// Package expiry tracks the pending transfers created with a timeout, to act on them before the
+// cluster expires them: to post them in time, or to void them early and release the amounts they
+// hold.
+//
+// The cluster expires a pending transfer Timeout seconds after its timestamp, which is only
+// known once the transfer was created. A Tracker estimates it from the time the transfer was
+// submitted, which is no later than its timestamp, so that the expiry estimated is never later
+// than the actual one.
+package expiry
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Client is the part of the TigerBeetle client that voids expiring transfers.
+type Client interface {
+	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+}
+
+// Pending is a pending transfer being tracked.
+type Pending struct {
+	Transfer  types.Transfer
+	ExpiresAt time.Time
+}
+
+// Tracker tracks pending transfers until they are resolved. It is safe for concurrent use.
+type Tracker struct {
+	mutex   sync.Mutex
+	pending map[types.Uint128]Pending
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{pending: make(map[types.Uint128]Pending)}
+}
+
+// Track tracks a pending transfer submitted at submitted, or created at its Timestamp if it was
+// looked up. Transfers that aren't pending or have no timeout never expire, and are ignored.
+func (t *Tracker) Track(transfer types.Transfer, submitted time.Time) {
+	if !transfer.TransferFlags().Pending || transfer.Timeout == 0 {
+		return
+	}
+	created := submitted
+	if transfer.Timestamp != 0 {
+		created = time.Unix(0, int64(transfer.Timestamp))
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.pending[transfer.ID] = Pending{
+		Transfer:  transfer,
+		ExpiresAt: created.Add(time.Duration(transfer.Timeout) * time.Second),
+	}
+}
+
+// TrackCreated tracks the pending transfers of a batch submitted at submitted that were created,
+// given the results of CreateTransfers. Transfers that already existed were created by an
+// earlier submission and are tracked too, with an estimated expiry that is only later than the
+// actual one if they are tracked for the first time.
+func (t *Tracker) TrackCreated(
+	transfers []types.Transfer,
+	results []types.TransferEventResult,
+	submitted time.Time,
+) {
+	failed := make(map[uint32]types.CreateTransferResult, len(results))
+	for _, result := range results {
+		failed[result.Index] = result.Result
+	}
+	for i, transfer := range transfers {
+		if result, ok := failed[uint32(i)]; !ok || result == types.TransferExists {
+			t.Track(transfer, submitted)
+		}
+	}
+}
+
+// Resolve stops tracking the pending transfers, once they have been posted or voided.
+func (t *Tracker) Resolve(ids ...types.Uint128) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, id := range ids {
+		delete(t.pending, id)
+	}
+}
+
+// ResolveCreated stops tracking the pending transfers posted or voided by a batch, given the
+// results of CreateTransfers. A pending transfer that was already posted, voided or expired is
+// resolved too.
+func (t *Tracker) ResolveCreated(transfers []types.Transfer, results []types.TransferEventResult) {
+	failed := make(map[uint32]types.CreateTransferResult, len(results))
+	for _, result := range results {
+		failed[result.Index] = result.Result
+	}
+	for i, transfer := range transfers {
+		flags := transfer.TransferFlags()
+		if !flags.PostPendingTransfer && !flags.VoidPendingTransfer {
+			continue
+		}
+		if result, ok := failed[uint32(i)]; !ok || resolved(result) {
+			t.Resolve(transfer.PendingID)
+		}
+	}
+}
+
+// Len returns the number of pending transfers being tracked.
+func (t *Tracker) Len() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return len(t.pending)
+}
+
+// ExpiringBefore returns the pending transfers that expire before deadline, soonest first.
+func (t *Tracker) ExpiringBefore(deadline time.Time) []Pending {
+	t.mutex.Lock()
+	var expiring []Pending
+	for _, pending := range t.pending {
+		if pending.ExpiresAt.Before(deadline) {
+			expiring = append(expiring, pending)
+		}
+	}
+	t.mutex.Unlock()
+
+	slices.SortFunc(expiring, func(a, b Pending) int { return a.ExpiresAt.Compare(b.ExpiresAt) })
+	return expiring
+}
+
+// resolved reports whether a post or void that failed with result found its pending transfer
+// resolved already.
+func resolved(result types.CreateTransferResult) bool {
+	switch result {
+	case types.TransferExists,
+		types.TransferPendingTransferAlreadyPosted,
+		types.TransferPendingTransferAlreadyVoided,
+		types.TransferPendingTransferExpired,
+		types.TransferPendingTransferNotFound:
+		return true
+	}
+	return false
+}
+
+// WatchConfig configures Watch.
+type WatchConfig struct {
+	// Interval is how often the tracker is checked. Defaults to a second.
+	Interval time.Duration
+	// Lead is how long before their expiry pending transfers are acted upon.
+	Lead time.Duration
+	// OnExpiring is called with the pending transfers expiring within Lead, soonest first, every
+	// time the tracker is checked until they are resolved. Optional.
+	OnExpiring func(expiring []Pending)
+	// Void voids the pending transfers expiring within Lead, after OnExpiring, with Client.
+	Void   bool
+	Client Client
+	// OnVoided is called with the results of voiding, or the error of the batch. Optional.
+	OnVoided func(voids []types.Transfer, results []types.TransferEventResult, err error)
+}
+
+// Watch checks the tracker every Interval until ctx is done, returning ctx.Err().
+func (t *Tracker) Watch(ctx context.Context, config WatchConfig) error {
+	if config.Void && config.Client == nil {
+		return fmt.Errorf("expiry: voiding needs a client")
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+	for {
+		t.check(time.Now(), config)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (t *Tracker) check(now time.Time, config WatchConfig) {
+	expiring := t.ExpiringBefore(now.Add(config.Lead))
+	if len(expiring) == 0 {
+		return
+	}
+	if config.OnExpiring != nil {
+		config.OnExpiring(expiring)
+	}
+	if !config.Void {
+		return
+	}
+
+	batchMax := types.MaxBatchSize(types.OperationCreateTransfers)
+	for chunk := range slices.Chunk(expiring, batchMax) {
+		voids := make([]types.Transfer, len(chunk))
+		for i, pending := range chunk {
+			voids[i] = types.VoidPendingTransfer(pending.Transfer.ID)
+		}
+		results, err := config.Client.CreateTransfers(voids)
+		if err == nil {
+			t.ResolveCreated(voids, results)
+		}
+		if config.OnVoided != nil {
+			config.OnVoided(voids, results, err)
+		}
+	}
+}
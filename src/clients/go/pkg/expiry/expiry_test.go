@@ -0,0 +1,112 @@
+package expiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+type voidingClient struct {
+	voided []types.Uint128
+}
+
+func (c *voidingClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	var results []types.TransferEventResult
+	for i, transfer := range transfers {
+		c.voided = append(c.voided, transfer.PendingID)
+		// Transfer 2 was posted in the meantime.
+		if transfer.PendingID == types.ToUint128(2) {
+			results = append(results, types.TransferEventResult{
+				Index:  uint32(i),
+				Result: types.TransferPendingTransferAlreadyPosted,
+			})
+		}
+	}
+	return results, nil
+}
+
+func pending(id uint64, timeout uint32) types.Transfer {
+	return types.Transfer{
+		ID:      types.ToUint128(id),
+		Timeout: timeout,
+		Flags:   types.TransferFlags{Pending: true}.ToUint16(),
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	submitted := time.Unix(1000, 0)
+
+	transfers := []types.Transfer{
+		pending(1, 60),
+		pending(2, 10),
+		pending(3, 30),
+		{ID: types.ToUint128(4)},
+		pending(5, 0),
+	}
+	// Transfer 3 failed.
+	tracker.TrackCreated(transfers, []types.TransferEventResult{
+		{Index: 2, Result: types.TransferExceedsCredits},
+	}, submitted)
+	assert.Equal(t, 2, tracker.Len())
+
+	// A transfer looked up expires from its timestamp.
+	looked := pending(6, 5)
+	looked.Timestamp = uint64(submitted.Add(time.Minute).UnixNano())
+	tracker.Track(looked, submitted)
+
+	expiring := tracker.ExpiringBefore(submitted.Add(time.Minute + 2*time.Second))
+	assert.Len(t, expiring, 2)
+	assert.Equal(t, types.ToUint128(2), expiring[0].Transfer.ID)
+	assert.Equal(t, submitted.Add(10*time.Second), expiring[0].ExpiresAt)
+	assert.Equal(t, types.ToUint128(1), expiring[1].Transfer.ID)
+
+	// Posting transfer 1 resolves it, but not a failed void of transfer 6.
+	tracker.ResolveCreated(
+		[]types.Transfer{
+			types.PostPendingTransfer(types.ToUint128(1), types.ToUint128(0)),
+			types.VoidPendingTransfer(types.ToUint128(6)),
+		},
+		[]types.TransferEventResult{{Index: 1, Result: types.TransferPendingIDMustBeDifferent}},
+	)
+	assert.Equal(t, 2, tracker.Len())
+	tracker.Resolve(types.ToUint128(6))
+	assert.Equal(t, 1, tracker.Len())
+}
+
+func TestWatch(t *testing.T) {
+	tracker := NewTracker()
+	now := time.Now()
+	tracker.Track(pending(1, 5), now)
+	tracker.Track(pending(2, 10), now)
+	tracker.Track(pending(3, 3600), now)
+
+	client := &voidingClient{}
+	var notified [][]Pending
+	var voidedCount int
+	config := WatchConfig{
+		Lead:       time.Minute,
+		OnExpiring: func(expiring []Pending) { notified = append(notified, expiring) },
+		Void:       true,
+		Client:     client,
+		OnVoided: func(voids []types.Transfer, results []types.TransferEventResult, err error) {
+			assert.Equal(t, nil, err)
+			voidedCount += len(voids)
+		},
+	}
+	tracker.check(now, config)
+	assert.Len(t, notified, 1)
+	assert.Len(t, notified[0], 2)
+	assert.Equal(t, []types.Uint128{types.ToUint128(1), types.ToUint128(2)}, client.voided)
+	assert.Equal(t, 2, voidedCount)
+	// Both were resolved, by the void or by the post in the meantime.
+	assert.Equal(t, 1, tracker.Len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, tracker.Watch(ctx, WatchConfig{}))
+	assert.True(t, tracker.Watch(ctx, WatchConfig{Void: true}) != nil)
+}
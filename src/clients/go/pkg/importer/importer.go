@@ -0,0 +1,335 @@
+// Package importer bulk-loads accounts and transfers from CSV or JSON lines into a cluster, as
+// when migrating a historical ledger.
+//
+// Rows are submitted in the largest batches that fit, without splitting a linked chain across
+// batches, and the result of every row is reported. After each batch, a checkpoint records how
+// many rows were imported, so that an interrupted import resumes where it stopped. Creating is
+// idempotent by ID, so the rows of a batch that was submitted but not checkpointed are reported
+// as already existing when resumed.
+//
+// CSV files start with a header naming the columns after the JSON fields of types.Account or
+// types.Transfer, such as id, debit_account_id or user_data_128. Empty cells are zero, and IDs
+// may be given in decimal or as "0x"-prefixed hex.
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Client is the part of the TigerBeetle client that an import submits to.
+type Client interface {
+	CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error)
+	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+}
+
+// Format is the format of the rows to import. It implements flag.Value.
+type Format uint8
+
+const (
+	FormatCSV Format = iota
+	FormatJSONL
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatJSONL:
+		return "jsonl"
+	default:
+		return "csv"
+	}
+}
+
+// Set parses one of "csv" or "jsonl".
+func (f *Format) Set(value string) error {
+	switch value {
+	case "csv":
+		*f = FormatCSV
+	case "jsonl":
+		*f = FormatJSONL
+	default:
+		return fmt.Errorf("unknown import format %q, expected csv or jsonl", value)
+	}
+	return nil
+}
+
+// Config describes an import. Zero fields take their defaults.
+type Config struct {
+	Format Format
+	// BatchSize is the number of rows per request. Defaults to the most that fit.
+	BatchSize int
+	// Resume is the number of rows already imported, as last passed to Checkpoint, which are
+	// read but not submitted again.
+	Resume int
+	// Checkpoint is called after every batch with the number of rows imported so far, including
+	// the resumed ones.
+	Checkpoint func(rows int) error
+	// Report is called with the result of every row submitted, in order.
+	Report func(result RowResult) error
+}
+
+// RowResult is the outcome of importing a row.
+type RowResult struct {
+	// Row counts the rows from 1, excluding the CSV header.
+	Row    int
+	ID     types.Uint128
+	Code   uint32
+	Result string
+	// Created is whether the row was created, rather than rejected or found to exist already.
+	Created bool
+}
+
+// Summary is the outcome of an import.
+type Summary struct {
+	// Rows counts the rows submitted, excluding the resumed ones.
+	Rows    int
+	Batches int
+	// Exists counts the rows that already existed as given, as when resuming.
+	Exists int
+	// Rejected counts the rows that were not created, other than those that already existed.
+	Rejected int
+}
+
+// ImportAccounts creates the accounts read from r, until done or ctx is done.
+func ImportAccounts(ctx context.Context, client Client, r io.Reader, config Config) (Summary, error) {
+	return run(ctx, r, config, operation[types.Account]{
+		op:     types.OperationCreateAccounts,
+		linked: func(account types.Account) bool { return account.AccountFlags().Linked },
+		id:     func(account types.Account) types.Uint128 { return account.ID },
+		submit: func(accounts []types.Account) ([]eventResult, error) {
+			results, err := client.CreateAccounts(accounts)
+			converted := make([]eventResult, len(results))
+			for i, result := range results {
+				converted[i] = eventResult{
+					index:  result.Index,
+					code:   uint32(result.Result),
+					result: result.Result.String(),
+					exists: result.Result == types.AccountExists,
+				}
+			}
+			return converted, err
+		},
+		ok: types.AccountOK.String(),
+	})
+}
+
+// ImportTransfers creates the transfers read from r, until done or ctx is done.
+func ImportTransfers(ctx context.Context, client Client, r io.Reader, config Config) (Summary, error) {
+	return run(ctx, r, config, operation[types.Transfer]{
+		op:     types.OperationCreateTransfers,
+		linked: func(transfer types.Transfer) bool { return transfer.TransferFlags().Linked },
+		id:     func(transfer types.Transfer) types.Uint128 { return transfer.ID },
+		submit: func(transfers []types.Transfer) ([]eventResult, error) {
+			results, err := client.CreateTransfers(transfers)
+			converted := make([]eventResult, len(results))
+			for i, result := range results {
+				converted[i] = eventResult{
+					index:  result.Index,
+					code:   uint32(result.Result),
+					result: result.Result.String(),
+					exists: result.Result == types.TransferExists,
+				}
+			}
+			return converted, err
+		},
+		ok: types.TransferOK.String(),
+	})
+}
+
+// operation adapts the import to accounts or transfers.
+type operation[E any] struct {
+	op     types.Operation
+	linked func(event E) bool
+	id     func(event E) types.Uint128
+	submit func(events []E) ([]eventResult, error)
+	// ok is the name of the result of a created event.
+	ok string
+}
+
+type eventResult struct {
+	index  uint32
+	code   uint32
+	result string
+	exists bool
+}
+
+func run[E any](ctx context.Context, r io.Reader, config Config, operation operation[E]) (Summary, error) {
+	if batchMax := types.MaxBatchSize(operation.op); config.BatchSize <= 0 || config.BatchSize > batchMax {
+		config.BatchSize = batchMax
+	}
+
+	rows, err := newRowReader[E](r, config.Format)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{}
+	imported := 0
+	var batch []E
+	eof := false
+	for !eof {
+		event, err := rows.next()
+		if err == io.EOF {
+			eof = true
+		} else if err != nil {
+			return summary, fmt.Errorf("importer: row %d: %w", imported+len(batch)+1, err)
+		} else if imported < config.Resume {
+			imported++
+			continue
+		} else {
+			batch = append(batch, event)
+		}
+
+		if len(batch) == 0 || (len(batch) < config.BatchSize && !eof) {
+			continue
+		}
+
+		// Cut the batch after the last complete linked chain, leaving the open one for the next
+		// batch. At the end of the rows, an open chain is submitted for the cluster to reject.
+		count := len(batch)
+		if !eof {
+			for count > 0 && operation.linked(batch[count-1]) {
+				count--
+			}
+			if count == 0 {
+				return summary, fmt.Errorf("importer: row %d: linked chain is longer than the batch size %d",
+					imported+1, config.BatchSize)
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		results, err := operation.submit(batch[:count])
+		if err != nil {
+			return summary, fmt.Errorf("importer: submitting rows %d to %d: %w", imported+1, imported+count, err)
+		}
+		summary.Batches++
+		summary.Rows += count
+
+		failed := make(map[uint32]eventResult, len(results))
+		for _, result := range results {
+			failed[result.index] = result
+			if result.exists {
+				summary.Exists++
+			} else {
+				summary.Rejected++
+			}
+		}
+		if config.Report != nil {
+			for i, event := range batch[:count] {
+				result, ok := failed[uint32(i)]
+				row := RowResult{Row: imported + i + 1, ID: operation.id(event), Result: operation.ok, Created: !ok}
+				if ok {
+					row.Code, row.Result = result.code, result.result
+				}
+				if err := config.Report(row); err != nil {
+					return summary, err
+				}
+			}
+		}
+
+		imported += count
+		batch = batch[count:]
+		if config.Checkpoint != nil {
+			if err := config.Checkpoint(imported); err != nil {
+				return summary, err
+			}
+		}
+	}
+	return summary, nil
+}
+
+// rowReader reads the rows to import as events.
+type rowReader[E any] struct {
+	json *json.Decoder
+	csv  *csv.Reader
+	// header names the CSV columns.
+	header []string
+}
+
+func newRowReader[E any](r io.Reader, format Format) (*rowReader[E], error) {
+	if format == FormatJSONL {
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		return &rowReader[E]{json: decoder}, nil
+	}
+
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &rowReader[E]{csv: reader}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("importer: reading header: %w", err)
+	}
+	return &rowReader[E]{csv: reader, header: append([]string(nil), header...)}, nil
+}
+
+// next returns the next event, or io.EOF after the last.
+func (r *rowReader[E]) next() (E, error) {
+	var event E
+	if r.json != nil {
+		err := r.json.Decode(&event)
+		return event, err
+	}
+	if r.header == nil {
+		return event, io.EOF
+	}
+
+	record, err := r.csv.Read()
+	if err != nil {
+		return event, err
+	}
+
+	// Decode the record as the JSON object with the same fields, so that CSV columns are named
+	// and parsed exactly like JSON fields. Integers are left unquoted, which Uint128 also accepts.
+	var object bytes.Buffer
+	object.WriteByte('{')
+	for i, value := range record {
+		if value == "" {
+			continue
+		}
+		if object.Len() > 1 {
+			object.WriteByte(',')
+		}
+		writeJSONString(&object, r.header[i])
+		object.WriteByte(':')
+		if isNumber(value) {
+			object.WriteString(value)
+		} else {
+			writeJSONString(&object, value)
+		}
+	}
+	object.WriteByte('}')
+
+	decoder := json.NewDecoder(&object)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&event)
+	return event, err
+}
+
+func writeJSONString(buffer *bytes.Buffer, value string) {
+	encoded, _ := json.Marshal(value)
+	buffer.Write(encoded)
+}
+
+// isNumber reports whether value is a JSON integer, which has no leading zeros.
+func isNumber(value string) bool {
+	if len(value) > 1 && value[0] == '0' {
+		return false
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// recordingClient creates every event except those whose ID is in exists or rejected.
+type recordingClient struct {
+	accounts  [][]types.Account
+	transfers [][]types.Transfer
+	exists    map[types.Uint128]bool
+	rejected  map[types.Uint128]bool
+}
+
+func (c *recordingClient) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	c.accounts = append(c.accounts, accounts)
+	var results []types.AccountEventResult
+	for i, account := range accounts {
+		if c.exists[account.ID] {
+			results = append(results, types.AccountEventResult{Index: uint32(i), Result: types.AccountExists})
+		}
+	}
+	return results, nil
+}
+
+func (c *recordingClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	c.transfers = append(c.transfers, transfers)
+	var results []types.TransferEventResult
+	for i, transfer := range transfers {
+		if c.rejected[transfer.ID] {
+			results = append(results, types.TransferEventResult{Index: uint32(i), Result: types.TransferExceedsCredits})
+		}
+	}
+	return results, nil
+}
+
+func TestImportAccountsCSV(t *testing.T) {
+	client := &recordingClient{exists: map[types.Uint128]bool{types.ToUint128(2): true}}
+	input := "id,ledger,code,user_data_128\n1,7,10,0xff\n2,7,10,\n"
+
+	var reported []RowResult
+	summary, err := ImportAccounts(context.Background(), client, strings.NewReader(input), Config{
+		Report: func(result RowResult) error {
+			reported = append(reported, result)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Summary{Rows: 2, Batches: 1, Exists: 1}, summary)
+	assert.Equal(t, []types.Account{
+		{ID: types.ToUint128(1), Ledger: 7, Code: 10, UserData128: types.ToUint128(255)},
+		{ID: types.ToUint128(2), Ledger: 7, Code: 10},
+	}, client.accounts[0])
+	assert.Equal(t, []RowResult{
+		{Row: 1, ID: types.ToUint128(1), Result: "AccountOK", Created: true},
+		{Row: 2, ID: types.ToUint128(2), Code: uint32(types.AccountExists), Result: "AccountExists"},
+	}, reported)
+}
+
+func TestImportCSVErrors(t *testing.T) {
+	for input, expected := range map[string]string{
+		"id,ledgr\n1,7\n":  `importer: row 1: json: unknown field "ledgr"`,
+		"id,ledger\n1,x\n": "importer: row 1: json: cannot unmarshal string into Go struct field Account.ledger of type uint32",
+		"id,ledger\n1\n":   "importer: row 1: record on line 2: wrong number of fields",
+	} {
+		_, err := ImportAccounts(context.Background(), &recordingClient{}, strings.NewReader(input), Config{})
+		if err == nil {
+			t.Fatalf("Expected %q to fail", input)
+		}
+		assert.Equal(t, expected, err.Error())
+	}
+}
+
+func transfersJSONL(count int, linked map[int]bool) string {
+	var lines strings.Builder
+	for i := 1; i <= count; i++ {
+		flags := uint16(0)
+		if linked[i] {
+			flags = types.TransferFlags{Linked: true}.ToUint16()
+		}
+		fmt.Fprintf(&lines, "{\"id\":\"%d\",\"amount\":%d,\"flags\":%d}\n", i, i, flags)
+	}
+	return lines.String()
+}
+
+func batchIDs(batches [][]types.Transfer) [][]uint64 {
+	ids := make([][]uint64, len(batches))
+	for i, batch := range batches {
+		for _, transfer := range batch {
+			id := transfer.ID.BigInt()
+			ids[i] = append(ids[i], id.Uint64())
+		}
+	}
+	return ids
+}
+
+func TestImportTransfersBatches(t *testing.T) {
+	// Transfers 3 to 5 are a linked chain, which must not be split across batches.
+	client := &recordingClient{rejected: map[types.Uint128]bool{types.ToUint128(7): true}}
+	input := transfersJSONL(8, map[int]bool{3: true, 4: true})
+
+	var checkpoints []int
+	summary, err := ImportTransfers(context.Background(), client, strings.NewReader(input), Config{
+		Format:    FormatJSONL,
+		BatchSize: 4,
+		Checkpoint: func(rows int) error {
+			checkpoints = append(checkpoints, rows)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Summary{Rows: 8, Batches: 3, Rejected: 1}, summary)
+	assert.Equal(t, [][]uint64{{1, 2}, {3, 4, 5, 6}, {7, 8}}, batchIDs(client.transfers))
+	assert.Equal(t, []int{2, 6, 8}, checkpoints)
+
+	// A chain that doesn't fit in a batch can't be imported.
+	_, err = ImportTransfers(context.Background(), &recordingClient{},
+		strings.NewReader(transfersJSONL(4, map[int]bool{1: true, 2: true, 3: true})),
+		Config{Format: FormatJSONL, BatchSize: 2})
+	assert.Equal(t, "importer: row 1: linked chain is longer than the batch size 2", err.Error())
+}
+
+func TestImportResume(t *testing.T) {
+	client := &recordingClient{}
+	var reported []int
+	summary, err := ImportTransfers(context.Background(), client, strings.NewReader(transfersJSONL(5, nil)), Config{
+		Format:    FormatJSONL,
+		BatchSize: 2,
+		Resume:    3,
+		Report: func(result RowResult) error {
+			reported = append(reported, result.Row)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Summary{Rows: 2, Batches: 1}, summary)
+	assert.Equal(t, [][]uint64{{4, 5}}, batchIDs(client.transfers))
+	assert.Equal(t, []int{4, 5}, reported)
+}
+
+func TestFormat(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatJSONL} {
+		var parsed Format
+		if err := parsed.Set(format.String()); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, format, parsed)
+	}
+
+	var parsed Format
+	assert.True(t, parsed.Set("xml") != nil)
+}
@@ -0,0 +1,295 @@
+// Package interest accrues daily interest on accounts, as a batch job to run once a day.
+//
+// The interest of every account for a day is computed by a Formula, rounded to a whole amount,
+// and credited by a linked chain of transfers whose IDs are derived from the account, the day
+// and the leg of the chain. Running the job for a day again, such as after it failed half way
+// through, accrues the accounts it missed and none twice.
+//
+// The cluster this client speaks to can't query accounts by their fields, so the job looks up
+// the accounts it is given and accrues those that match Config.Query.
+package interest
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Client is the part of the TigerBeetle client that the job submits to.
+type Client interface {
+	LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error)
+	LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error)
+	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+}
+
+// Formula returns the interest of account for day, in units of its ledger. The job rounds it to
+// a whole amount, and accrues nothing if it rounds to zero or less.
+type Formula func(account types.Account, day time.Time) *big.Rat
+
+// Daily returns the formula of a day's interest on balance at annualRate, such as
+// big.NewRat(35, 1000) for 3.5%, over daysPerYear days, such as 365 or 360.
+func Daily(annualRate *big.Rat, daysPerYear int64, balance func(account types.Account) *big.Int) Formula {
+	rate := new(big.Rat).Quo(annualRate, new(big.Rat).SetInt64(daysPerYear))
+	return func(account types.Account, _ time.Time) *big.Rat {
+		return new(big.Rat).Mul(rate, new(big.Rat).SetInt(balance(account)))
+	}
+}
+
+// CreditBalance returns the posted credits of account less its posted debits, the balance of a
+// deposit.
+func CreditBalance(account types.Account) *big.Int {
+	credits, debits := account.CreditsPosted.BigInt(), account.DebitsPosted.BigInt()
+	return new(big.Int).Sub(&credits, &debits)
+}
+
+// DebitBalance returns the posted debits of account less its posted credits, the balance of a
+// loan.
+func DebitBalance(account types.Account) *big.Int {
+	credits, debits := account.CreditsPosted.BigInt(), account.DebitsPosted.BigInt()
+	return new(big.Int).Sub(&debits, &credits)
+}
+
+// Query matches accounts by their fields, as the query filter of the cluster does: zero fields
+// match any value.
+type Query struct {
+	Ledger      uint32
+	Code        uint16
+	UserData128 types.Uint128
+	UserData64  uint64
+	UserData32  uint32
+}
+
+// Match reports whether account matches the query.
+func (q Query) Match(account types.Account) bool {
+	return (q.Ledger == 0 || q.Ledger == account.Ledger) &&
+		(q.Code == 0 || q.Code == account.Code) &&
+		(q.UserData128 == (types.Uint128{}) || q.UserData128 == account.UserData128) &&
+		(q.UserData64 == 0 || q.UserData64 == account.UserData64) &&
+		(q.UserData32 == 0 || q.UserData32 == account.UserData32)
+}
+
+// Config configures a Job.
+type Config struct {
+	// Query selects the accounts to accrue. The zero Query matches every account.
+	Query Query
+	// Formula computes the interest of an account for a day. Required.
+	Formula Formula
+	// Rounding rounds the interest to a whole amount. RoundUnnecessary, the zero value, is taken
+	// as RoundHalfEven, since interest is rarely a whole amount.
+	Rounding types.RoundingMode
+	// Legs returns the transfers that accrue amount to account, which are linked, so that all of
+	// them are created or none is, and whose IDs are replaced. Defaults to a single transfer of
+	// amount from SourceAccountID to account, with Code.
+	Legs func(account types.Account, amount types.Uint128) []types.Transfer
+	// SourceAccountID pays the interest, such as an interest expense account on the ledger of
+	// the accounts, unless Legs is set.
+	SourceAccountID types.Uint128
+	// Code is the code of the transfers, unless Legs is set.
+	Code uint16
+}
+
+// Job accrues the interest of a day. It is safe for concurrent use, though a day should be run
+// by one job at a time.
+type Job struct {
+	client Client
+	config Config
+}
+
+// New returns a job accruing interest with client.
+func New(client Client, config Config) *Job {
+	if config.Formula == nil {
+		panic("interest: Config.Formula is required")
+	}
+	if config.Rounding == types.RoundUnnecessary {
+		config.Rounding = types.RoundHalfEven
+	}
+	if config.Legs == nil {
+		config.Legs = func(account types.Account, amount types.Uint128) []types.Transfer {
+			return []types.Transfer{{
+				DebitAccountID:  config.SourceAccountID,
+				CreditAccountID: account.ID,
+				Amount:          amount,
+				Ledger:          account.Ledger,
+				Code:            config.Code,
+			}}
+		}
+	}
+	return &Job{client: client, config: config}
+}
+
+// Result is what a run of the job did.
+type Result struct {
+	// Accrued are the accounts whose interest the run accrued.
+	Accrued []types.Uint128
+	// Existing are the accounts whose interest an earlier run accrued.
+	Existing []types.Uint128
+	// Skipped are the accounts that don't match the query, weren't found, or whose interest
+	// rounds to zero or less.
+	Skipped []types.Uint128
+	// Rejected are the accounts whose accrual the cluster rejected, with the result of the first
+	// leg that failed other than for the chain.
+	Rejected map[types.Uint128]types.CreateTransferResult
+}
+
+// AccrualID returns the ID of leg of the accrual of accountID for day.
+func AccrualID(accountID types.Uint128, day time.Time, leg int) types.Uint128 {
+	id := accountID.Bytes()
+	return types.IDFromHash("interest", id[:], []byte(day.Format(time.DateOnly)), []byte{byte(leg)})
+}
+
+// Run accrues the interest of day, a date in the location of its time, on the accounts of
+// accountIDs that match the query and were not accrued for day yet. Interest is computed on the
+// accounts as they are during the run, so run the job for a day soon after the day ends.
+//
+// Run returns an error, with the result so far, if a request fails, and can then be run again.
+func (j *Job) Run(day time.Time, accountIDs []types.Uint128) (Result, error) {
+	result := Result{Rejected: make(map[types.Uint128]types.CreateTransferResult)}
+	batchMax := types.MaxBatchSize(types.OperationCreateTransfers)
+	var (
+		accounts []types.Account
+		chains   [][]types.Transfer
+		size     int
+	)
+	flush := func() error {
+		if size == 0 {
+			return nil
+		}
+		if err := j.submit(accounts, chains, &result); err != nil {
+			return err
+		}
+		accounts, chains, size = accounts[:0], chains[:0], 0
+		return nil
+	}
+
+	lookupMax := types.MaxBatchSize(types.OperationLookupAccounts)
+	for ids := range slices.Chunk(accountIDs, lookupMax) {
+		due, err := j.due(day, ids, &result)
+		if err != nil {
+			return result, err
+		}
+		for _, account := range due {
+			interest, err := types.FromDecimal(j.interest(account, day), 0, j.config.Rounding)
+			if err != nil {
+				return result, fmt.Errorf("interest: account %s: %w", account.ID, err)
+			}
+			if interest == (types.Uint128{}) {
+				result.Skipped = append(result.Skipped, account.ID)
+				continue
+			}
+
+			legs := j.config.Legs(account, interest)
+			for i := range legs {
+				legs[i].ID = AccrualID(account.ID, day, i)
+			}
+			chain, err := new(types.LinkedChain).Chain(legs...).Build()
+			if err != nil {
+				return result, err
+			}
+			if size+len(chain) > batchMax {
+				if err := flush(); err != nil {
+					return result, err
+				}
+			}
+			accounts = append(accounts, account)
+			chains = append(chains, chain)
+			size += len(chain)
+		}
+	}
+	return result, flush()
+}
+
+// interest returns the interest of account for day, with negative interest as zero.
+func (j *Job) interest(account types.Account, day time.Time) *big.Rat {
+	interest := j.config.Formula(account, day)
+	if interest == nil || interest.Sign() < 0 {
+		return new(big.Rat)
+	}
+	return interest
+}
+
+// due looks up the accounts of ids, and returns those that match the query and weren't accrued
+// for day, adding the others to result.
+func (j *Job) due(day time.Time, ids []types.Uint128, result *Result) ([]types.Account, error) {
+	accounts, err := j.client.LookupAccounts(ids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[types.Uint128]types.Account, len(accounts))
+	for _, account := range accounts {
+		found[account.ID] = account
+	}
+
+	var matched []types.Account
+	var accrualIDs []types.Uint128
+	for _, id := range ids {
+		account, ok := found[id]
+		if !ok || !j.config.Query.Match(account) {
+			result.Skipped = append(result.Skipped, id)
+			continue
+		}
+		matched = append(matched, account)
+		accrualIDs = append(accrualIDs, AccrualID(id, day, 0))
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+
+	// The first leg of an accrual exists if, and only if, the whole chain does.
+	existing, err := j.client.LookupTransfers(accrualIDs)
+	if err != nil {
+		return nil, err
+	}
+	accrued := make(map[types.Uint128]bool, len(existing))
+	for _, transfer := range existing {
+		accrued[transfer.ID] = true
+	}
+	due := matched[:0]
+	for i, account := range matched {
+		if accrued[accrualIDs[i]] {
+			result.Existing = append(result.Existing, account.ID)
+		} else {
+			due = append(due, account)
+		}
+	}
+	return due, nil
+}
+
+// submit creates the chains of accrual of accounts, adding the outcome for every account to
+// result.
+func (j *Job) submit(accounts []types.Account, chains [][]types.Transfer, result *Result) error {
+	var transfers []types.Transfer
+	for _, chain := range chains {
+		transfers = append(transfers, chain...)
+	}
+	results, err := j.client.CreateTransfers(transfers)
+	if err != nil {
+		return err
+	}
+	failed := make(map[uint32]types.CreateTransferResult, len(results))
+	for _, r := range results {
+		failed[r.Index] = r.Result
+	}
+
+	index := uint32(0)
+	for i, chain := range chains {
+		outcome := types.TransferOK
+		for range chain {
+			if r, ok := failed[index]; ok && r != types.TransferLinkedEventFailed && outcome == types.TransferOK {
+				outcome = r
+			}
+			index++
+		}
+		switch outcome {
+		case types.TransferOK:
+			result.Accrued = append(result.Accrued, accounts[i].ID)
+		case types.TransferExists:
+			result.Existing = append(result.Existing, accounts[i].ID)
+		default:
+			result.Rejected[accounts[i].ID] = outcome
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package interest
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbtest"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func TestJob(t *testing.T) {
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1,
+		tigerbeetle_go.WithTransport(tbtest.NewLedger(tbtest.NewClock(time.Time{}))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	source, a, b, c, d, missing := types.ToUint128(1), types.ToUint128(2), types.ToUint128(3),
+		types.ToUint128(4), types.ToUint128(5), types.ToUint128(6)
+	_, err = client.CreateAccounts([]types.Account{
+		{ID: source, Ledger: 1, Code: 1},
+		{ID: a, Ledger: 1, Code: 2},
+		{ID: b, Ledger: 2, Code: 2},
+		{ID: c, Ledger: 1, Code: 2},
+		{ID: d, Ledger: 1, Code: 2},
+	})
+	assert.Equal(t, nil, err)
+	_, err = client.CreateTransfers([]types.Transfer{
+		{ID: types.ID(), DebitAccountID: source, CreditAccountID: a, Amount: types.ToUint128(1_000_000), Ledger: 1, Code: 1},
+		{ID: types.ID(), DebitAccountID: source, CreditAccountID: d, Amount: types.ToUint128(123_457), Ledger: 1, Code: 1},
+	})
+	assert.Equal(t, nil, err)
+
+	balance := func(id types.Uint128) types.Uint128 {
+		account, _, err := client.LookupAccount(id)
+		assert.Equal(t, nil, err)
+		return account.CreditsPosted
+	}
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	job := New(client, Config{
+		Query:           Query{Ledger: 1, Code: 2},
+		Formula:         Daily(big.NewRat(365, 10_000), 365, CreditBalance),
+		SourceAccountID: source,
+		Code:            3,
+	})
+
+	t.Run("accrues", func(t *testing.T) {
+		result, err := job.Run(day, []types.Uint128{a})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []types.Uint128{a}, result.Accrued)
+		assert.Equal(t, types.ToUint128(1_000_100), balance(a))
+	})
+
+	t.Run("resumes without accruing twice", func(t *testing.T) {
+		result, err := job.Run(day, []types.Uint128{a, b, c, d, missing})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []types.Uint128{d}, result.Accrued)
+		assert.Equal(t, []types.Uint128{a}, result.Existing)
+		assert.Equal(t, []types.Uint128{b, missing, c}, result.Skipped)
+		assert.Empty(t, result.Rejected)
+		assert.Equal(t, types.ToUint128(1_000_100), balance(a))
+		assert.Equal(t, types.ToUint128(123_469), balance(d))
+	})
+
+	t.Run("accrues the next day", func(t *testing.T) {
+		result, err := job.Run(day.AddDate(0, 0, 1), []types.Uint128{a})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []types.Uint128{a}, result.Accrued)
+		// 0.01% of 1,000,100 is 100.01, rounded to 100.
+		assert.Equal(t, types.ToUint128(1_000_200), balance(a))
+	})
+
+	t.Run("links the legs", func(t *testing.T) {
+		withheld := New(client, Config{
+			Formula: Daily(big.NewRat(365, 10_000), 365, CreditBalance),
+			Legs: func(account types.Account, amount types.Uint128) []types.Transfer {
+				return []types.Transfer{
+					{DebitAccountID: source, CreditAccountID: account.ID, Amount: amount, Ledger: 1, Code: 3},
+					{DebitAccountID: account.ID, CreditAccountID: missing, Amount: types.ToUint128(1), Ledger: 1, Code: 4},
+				}
+			},
+		})
+		result, err := withheld.Run(day.AddDate(0, 0, 2), []types.Uint128{a})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, map[types.Uint128]types.CreateTransferResult{a: types.TransferCreditAccountNotFound}, result.Rejected)
+		assert.Equal(t, types.ToUint128(1_000_200), balance(a))
+	})
+}
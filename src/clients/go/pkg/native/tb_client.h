@@ -227,6 +227,13 @@ typedef enum TB_STATUS {
     TB_STATUS_NETWORK_SUBSYSTEM = 7,
 } TB_STATUS;
 
+typedef enum TB_LOG_LEVEL {
+    TB_LOG_ERR = 0,
+    TB_LOG_WARN = 1,
+    TB_LOG_INFO = 2,
+    TB_LOG_DEBUG = 3,
+} TB_LOG_LEVEL;
+
 TB_STATUS tb_client_init(
     tb_client_t* out_client,
     tb_uint128_t cluster_id,
@@ -266,6 +273,10 @@ void tb_client_deinit(
     tb_client_t client
 );
 
+void tb_client_register_log_callback(
+    void (*callback)(TB_LOG_LEVEL, const uint8_t*, uint32_t)
+);
+
 #ifdef __cplusplus
 } // extern "C"
 #endif
@@ -0,0 +1,190 @@
+// Package output writes the results of the command line tools as a human readable table, as
+// JSON lines for jq, or as CSV for spreadsheets.
+//
+// Every format writes the same columns in the same order, so scripts can rely on the schema of
+// a command's output whichever format they pick. Accounts and transfers are written with both
+// decimal and hex IDs.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Format selects how rows are written. It implements flag.Value.
+type Format uint8
+
+const (
+	FormatTable Format = iota
+	FormatJSON
+	FormatCSV
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatCSV:
+		return "csv"
+	default:
+		return "table"
+	}
+}
+
+// Set parses one of "table", "json" or "csv".
+func (f *Format) Set(value string) error {
+	switch value {
+	case "table":
+		*f = FormatTable
+	case "json":
+		*f = FormatJSON
+	case "csv":
+		*f = FormatCSV
+	default:
+		return fmt.Errorf("unknown output format %q, expected table, json or csv", value)
+	}
+	return nil
+}
+
+// Writer writes rows of values for a fixed list of columns.
+type Writer struct {
+	format  Format
+	columns []string
+
+	table *tabwriter.Writer
+	csv   *csv.Writer
+	json  *json.Encoder
+}
+
+// NewWriter returns a writer of rows with the given columns, which writes the header right
+// away for the table and CSV formats.
+func NewWriter(w io.Writer, format Format, columns ...string) (*Writer, error) {
+	writer := &Writer{format: format, columns: columns}
+	switch format {
+	case FormatJSON:
+		writer.json = json.NewEncoder(w)
+		return writer, nil
+	case FormatCSV:
+		writer.csv = csv.NewWriter(w)
+		return writer, writer.csv.Write(columns)
+	default:
+		writer.table = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		_, err := fmt.Fprintln(writer.table, strings.ToUpper(strings.Join(columns, "\t")))
+		return writer, err
+	}
+}
+
+// Row writes a row with one value per column.
+func (w *Writer) Row(values ...string) error {
+	if len(values) != len(w.columns) {
+		panic("output: row does not match the columns")
+	}
+
+	switch w.format {
+	case FormatJSON:
+		object := make(map[string]string, len(values))
+		for i, column := range w.columns {
+			object[column] = values[i]
+		}
+		return w.json.Encode(object)
+	case FormatCSV:
+		return w.csv.Write(values)
+	default:
+		_, err := fmt.Fprintln(w.table, strings.Join(values, "\t"))
+		return err
+	}
+}
+
+// Flush writes any buffered rows.
+func (w *Writer) Flush() error {
+	switch w.format {
+	case FormatCSV:
+		w.csv.Flush()
+		return w.csv.Error()
+	case FormatTable:
+		return w.table.Flush()
+	default:
+		return nil
+	}
+}
+
+// AccountColumns are the columns written for an account by AccountRow.
+var AccountColumns = []string{
+	"id",
+	"id_hex",
+	"debits_pending",
+	"debits_posted",
+	"credits_pending",
+	"credits_posted",
+	"user_data_128",
+	"user_data_64",
+	"user_data_32",
+	"ledger",
+	"code",
+	"flags",
+	"timestamp",
+}
+
+// AccountRow returns the values of account for AccountColumns.
+func AccountRow(account types.Account) []string {
+	return []string{
+		account.ID.String(),
+		account.ID.HexString(),
+		account.DebitsPending.String(),
+		account.DebitsPosted.String(),
+		account.CreditsPending.String(),
+		account.CreditsPosted.String(),
+		account.UserData128.String(),
+		strconv.FormatUint(account.UserData64, 10),
+		strconv.FormatUint(uint64(account.UserData32), 10),
+		strconv.FormatUint(uint64(account.Ledger), 10),
+		strconv.FormatUint(uint64(account.Code), 10),
+		strconv.FormatUint(uint64(account.Flags), 10),
+		strconv.FormatUint(account.Timestamp, 10),
+	}
+}
+
+// TransferColumns are the columns written for a transfer by TransferRow.
+var TransferColumns = []string{
+	"id",
+	"id_hex",
+	"debit_account_id",
+	"credit_account_id",
+	"amount",
+	"pending_id",
+	"user_data_128",
+	"user_data_64",
+	"user_data_32",
+	"timeout",
+	"ledger",
+	"code",
+	"flags",
+	"timestamp",
+}
+
+// TransferRow returns the values of transfer for TransferColumns.
+func TransferRow(transfer types.Transfer) []string {
+	return []string{
+		transfer.ID.String(),
+		transfer.ID.HexString(),
+		transfer.DebitAccountID.String(),
+		transfer.CreditAccountID.String(),
+		transfer.Amount.String(),
+		transfer.PendingID.String(),
+		transfer.UserData128.String(),
+		strconv.FormatUint(transfer.UserData64, 10),
+		strconv.FormatUint(uint64(transfer.UserData32), 10),
+		strconv.FormatUint(uint64(transfer.Timeout), 10),
+		strconv.FormatUint(uint64(transfer.Ledger), 10),
+		strconv.FormatUint(uint64(transfer.Code), 10),
+		strconv.FormatUint(uint64(transfer.Flags), 10),
+		strconv.FormatUint(transfer.Timestamp, 10),
+	}
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func write(t *testing.T, format Format) string {
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, format, "id", "id_hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := types.ToUint128(255)
+	if err := writer.Row(id.String(), id.HexString()); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.String()
+}
+
+func TestWriter(t *testing.T) {
+	assert.Equal(t, "ID   ID_HEX\n255  ff\n", write(t, FormatTable))
+	assert.Equal(t, "{\"id\":\"255\",\"id_hex\":\"ff\"}\n", write(t, FormatJSON))
+	assert.Equal(t, "id,id_hex\n255,ff\n", write(t, FormatCSV))
+}
+
+func TestFormat(t *testing.T) {
+	for _, format := range []Format{FormatTable, FormatJSON, FormatCSV} {
+		var parsed Format
+		if err := parsed.Set(format.String()); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, format, parsed)
+	}
+
+	var parsed Format
+	assert.True(t, parsed.Set("yaml") != nil)
+}
+
+func TestRows(t *testing.T) {
+	assert.Len(t, AccountRow(types.Account{}), len(AccountColumns))
+	assert.Len(t, TransferRow(types.Transfer{}), len(TransferColumns))
+
+	row := TransferRow(types.Transfer{ID: types.ToUint128(16), Amount: types.ToUint128(10)})
+	assert.Equal(t, "16", row[0])
+	assert.Equal(t, "10", row[4])
+	assert.Equal(t, "10", row[1])
+}
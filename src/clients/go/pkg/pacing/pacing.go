@@ -0,0 +1,168 @@
+// Package pacing spreads bulk writes smoothly over time.
+//
+// A Pacer is a token bucket over events: tokens accrue at the target rate up to a burst, and
+// callers wait until enough tokens are available for the events they are about to submit.
+// An optional ramp-up curve starts the rate low and raises it to the target, and jitter
+// randomizes each wait so that many writers started together don't submit in lockstep.
+// Scheduled, backfill and mirroring jobs should all pace through this package so they approach
+// their target throughput without bursts that trip cluster backpressure.
+package pacing
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Ramp returns the fraction of the target rate, in (0, 1], to pace at once elapsed has passed
+// since the pacer started.
+type Ramp func(elapsed time.Duration) float64
+
+// rampFloor keeps a ramp from starting at a rate of zero, which would never admit an event.
+const rampFloor = 0.01
+
+// NoRamp paces at the target rate from the start.
+func NoRamp(elapsed time.Duration) float64 { return 1 }
+
+// LinearRamp raises the rate linearly to the target over duration.
+func LinearRamp(duration time.Duration) Ramp {
+	return func(elapsed time.Duration) float64 {
+		if elapsed >= duration {
+			return 1
+		}
+		return math.Max(rampFloor, float64(elapsed)/float64(duration))
+	}
+}
+
+// ExponentialRamp raises the rate exponentially from 1% of the target to the target over
+// duration, staying gentle for longer than LinearRamp before reaching full speed.
+func ExponentialRamp(duration time.Duration) Ramp {
+	return func(elapsed time.Duration) float64 {
+		if elapsed >= duration {
+			return 1
+		}
+		return math.Pow(rampFloor, 1-float64(elapsed)/float64(duration))
+	}
+}
+
+// Config configures a Pacer.
+type Config struct {
+	// Rate is the target number of events per second.
+	Rate float64
+	// Burst is how many events may be admitted at once after the pacer has been idle.
+	// Defaults to one second worth of events at the target rate.
+	Burst int
+	// Ramp is the ramp-up curve, defaulting to NoRamp.
+	Ramp Ramp
+	// Jitter randomizes each wait by up to this fraction of it, between 0 and 1.
+	Jitter float64
+	// Seed seeds the jitter so that runs can be reproduced. Zero uses a time-based seed.
+	Seed int64
+}
+
+// Pacer admits events at a paced rate. It is safe for concurrent use.
+type Pacer struct {
+	config Config
+
+	mutex   sync.Mutex
+	random  *rand.Rand
+	started time.Time
+	updated time.Time
+	tokens  float64
+}
+
+// New returns a Pacer that starts pacing, and ramping up, from now.
+func New(config Config) *Pacer {
+	return newAt(config, time.Now())
+}
+
+func newAt(config Config, now time.Time) *Pacer {
+	if config.Rate <= 0 {
+		panic("pacing: rate must be positive")
+	}
+	if config.Burst <= 0 {
+		config.Burst = int(math.Max(1, config.Rate))
+	}
+	if config.Ramp == nil {
+		config.Ramp = NoRamp
+	}
+	config.Jitter = math.Min(math.Max(config.Jitter, 0), 1)
+
+	seed := config.Seed
+	if seed == 0 {
+		seed = now.UnixNano()
+	}
+
+	return &Pacer{
+		config:  config,
+		random:  rand.New(rand.NewSource(seed)),
+		started: now,
+		updated: now,
+		// Start empty so that a ramp-up isn't defeated by an initial burst.
+		tokens: 0,
+	}
+}
+
+// Rate returns the current target rate in events per second, after applying the ramp.
+func (p *Pacer) Rate() float64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.rateAt(time.Now())
+}
+
+func (p *Pacer) rateAt(now time.Time) float64 {
+	return p.config.Rate * p.config.Ramp(now.Sub(p.started))
+}
+
+// Reserve takes count events worth of tokens and returns how long the caller must wait before
+// submitting them. Reservations larger than the burst are allowed and simply wait longer.
+func (p *Pacer) Reserve(count int) time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.reserveAt(time.Now(), count)
+}
+
+func (p *Pacer) reserveAt(now time.Time, count int) time.Duration {
+	rate := p.rateAt(now)
+	if elapsed := now.Sub(p.updated); elapsed > 0 {
+		p.tokens = math.Min(float64(p.config.Burst), p.tokens+elapsed.Seconds()*rate)
+		p.updated = now
+	}
+
+	p.tokens -= float64(count)
+	if p.tokens >= 0 {
+		return 0
+	}
+
+	wait := -p.tokens / rate
+	if p.config.Jitter > 0 {
+		wait *= 1 + p.config.Jitter*(p.random.Float64()*2-1)
+	}
+	return time.Duration(wait * float64(time.Second))
+}
+
+// Wait blocks until count events may be submitted, or until ctx is done.
+func (p *Pacer) Wait(ctx context.Context, count int) error {
+	wait := p.Reserve(count)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give back the tokens that were never used.
+		p.mutex.Lock()
+		p.tokens += float64(count)
+		p.mutex.Unlock()
+		return ctx.Err()
+	}
+}
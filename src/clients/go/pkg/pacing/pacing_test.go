@@ -0,0 +1,60 @@
+package pacing
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Pacer(t *testing.T) {
+	start := time.Unix(0, 0)
+	pacer := newAt(Config{Rate: 100, Burst: 10}, start)
+
+	// The bucket starts empty, so 10 events at 100/s wait 100ms.
+	if wait := pacer.reserveAt(start, 10); wait != 100*time.Millisecond {
+		t.Fatalf("Expected to wait 100ms, got %s", wait)
+	}
+
+	// After a long idle period the bucket holds no more than the burst.
+	later := start.Add(10 * time.Second)
+	if wait := pacer.reserveAt(later, 10); wait != 0 {
+		t.Fatalf("Expected a full burst after idling, got a wait of %s", wait)
+	}
+	if wait := pacer.reserveAt(later, 1); wait != 10*time.Millisecond {
+		t.Fatalf("Expected to wait 10ms past the burst, got %s", wait)
+	}
+}
+
+func Test_Ramp(t *testing.T) {
+	linear := LinearRamp(10 * time.Second)
+	exponential := ExponentialRamp(10 * time.Second)
+
+	if linear(5*time.Second) != 0.5 || linear(20*time.Second) != 1 {
+		t.Fatalf("Expected linear ramp to be halfway at 5s and full at 20s")
+	}
+	if linear(0) != rampFloor || exponential(0) != rampFloor {
+		t.Fatalf("Expected ramps to start at the floor")
+	}
+	if exponential(5*time.Second) >= linear(5*time.Second) {
+		t.Fatalf("Expected exponential ramp to start slower than linear")
+	}
+
+	start := time.Unix(0, 0)
+	pacer := newAt(Config{Rate: 100, Ramp: linear}, start)
+	if rate := pacer.rateAt(start.Add(5 * time.Second)); rate != 50 {
+		t.Fatalf("Expected half the rate halfway through the ramp, got %f", rate)
+	}
+}
+
+func Test_Jitter(t *testing.T) {
+	start := time.Unix(0, 0)
+	pacer := newAt(Config{Rate: 100, Burst: 1, Jitter: 0.5, Seed: 1}, start)
+
+	for i := 0; i < 100; i++ {
+		// Without jitter, each event would owe exactly 10ms more than the last.
+		wait := pacer.reserveAt(start, 1)
+		expected := time.Duration(i+1) * 10 * time.Millisecond
+		if wait < expected/2 || wait > expected*3/2 {
+			t.Fatalf("Expected wait within 50%% of %s, got %s", expected, wait)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Rows is the part of *sql.Rows that ReadRows reads from.
+type Rows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}
+
+// ReadCSV yields the records of a CSV file that starts with a header naming the columns after
+// the JSON fields of Record, such as key or amount. Other columns are ignored, and empty cells
+// are zero. Keys and IDs may be given in decimal or as "0x"-prefixed hex. Iteration stops after
+// the first error is yielded.
+func ReadCSV(r io.Reader) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		reader := csv.NewReader(r)
+		reader.ReuseRecord = true
+		header, err := reader.Read()
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+		columns := make([]string, len(header))
+		for i, name := range header {
+			columns[i] = strings.ToLower(strings.TrimSpace(name))
+		}
+
+		for row := 1; ; row++ {
+			cells, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+
+			var record Record
+			for i, cell := range cells {
+				cell = strings.TrimSpace(cell)
+				if cell == "" {
+					continue
+				}
+				if err := record.set(columns[i], cell); err != nil {
+					yield(Record{}, fmt.Errorf("row %d, column %s: %w", row, columns[i], err))
+					return
+				}
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ReadRows yields the records of the rows of a query whose columns are named after the JSON
+// fields of Record, as in:
+//
+//	SELECT reference AS key, amount_minor AS amount FROM settlements WHERE day = $1
+//
+// Other columns are ignored, and NULL is zero. Rows is closed by the caller. Iteration stops
+// after the first error is yielded.
+func ReadRows(rows Rows) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		columns, err := rows.Columns()
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+		cells := make([]any, len(columns))
+		for i := range cells {
+			cells[i] = new(any)
+		}
+
+		for rows.Next() {
+			if err := rows.Scan(cells...); err != nil {
+				yield(Record{}, err)
+				return
+			}
+
+			var record Record
+			for i, cell := range cells {
+				var text string
+				switch value := (*cell.(*any)).(type) {
+				case nil:
+					continue
+				case []byte:
+					text = string(value)
+				case string:
+					text = value
+				case int64:
+					text = strconv.FormatInt(value, 10)
+				default:
+					text = fmt.Sprint(value)
+				}
+				column := strings.ToLower(columns[i])
+				if err := record.set(column, strings.TrimSpace(text)); err != nil {
+					yield(Record{}, fmt.Errorf("column %s: %w", column, err))
+					return
+				}
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(Record{}, err)
+		}
+	}
+}
+
+// set parses value into the field of the record named column, ignoring unknown columns.
+func (r *Record) set(column string, value string) error {
+	var err error
+	switch column {
+	case "key":
+		r.Key, err = parseUint128(value)
+	case "amount":
+		r.Amount, err = parseUint128(value)
+	case "debit_account_id":
+		r.DebitAccountID, err = parseUint128(value)
+	case "credit_account_id":
+		r.CreditAccountID, err = parseUint128(value)
+	case "ledger":
+		var ledger uint64
+		ledger, err = strconv.ParseUint(value, 10, 32)
+		r.Ledger = uint32(ledger)
+	case "code":
+		var code uint64
+		code, err = strconv.ParseUint(value, 10, 16)
+		r.Code = uint16(code)
+	}
+	return err
+}
+
+func parseUint128(value string) (types.Uint128, error) {
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		return types.HexStringToUint128(value[2:])
+	}
+	return types.DecStringToUint128(value)
+}
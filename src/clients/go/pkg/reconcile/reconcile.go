@@ -0,0 +1,323 @@
+// Package reconcile compares the transfers of a cluster against the records of an external
+// system, such as the daily settlement file of a payment provider, and reports every record as
+// matched, mismatched, missing from the cluster, or every transfer unexpected in the feed.
+//
+// Records are matched to transfers by a key, the transfer's ID or its user_data_128. Both sides
+// are read as streams: Compare holds on to a record or transfer only until its counterpart
+// shows up, so reading two feeds in roughly the same order, such as both by time, keeps memory
+// bounded by how far apart they drift rather than by their length. ByID looks the records up
+// in chunks instead, when the feed carries the IDs of the transfers.
+package reconcile
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Client is the part of the TigerBeetle client that a reconciliation reads from.
+type Client interface {
+	LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error)
+	GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error)
+}
+
+// Key selects the field of a transfer that the key of a record refers to.
+type Key uint8
+
+const (
+	KeyID Key = iota
+	KeyUserData128
+)
+
+func (k Key) of(transfer *types.Transfer) types.Uint128 {
+	if k == KeyUserData128 {
+		return transfer.UserData128
+	}
+	return transfer.ID
+}
+
+// Record is a transfer as known to the external system. Fields other than Key and Amount are
+// only compared when they are not zero.
+type Record struct {
+	Key             types.Uint128 `json:"key"`
+	Amount          types.Uint128 `json:"amount"`
+	DebitAccountID  types.Uint128 `json:"debit_account_id"`
+	CreditAccountID types.Uint128 `json:"credit_account_id"`
+	Ledger          uint32        `json:"ledger"`
+	Code            uint16        `json:"code"`
+}
+
+// Status is the outcome of reconciling a record or a transfer.
+type Status uint8
+
+const (
+	// StatusMatched is a record that agrees with its transfer.
+	StatusMatched Status = iota
+	// StatusMismatched is a record that disagrees with its transfer on the fields listed.
+	StatusMismatched
+	// StatusMissing is a record without a transfer.
+	StatusMissing
+	// StatusUnexpected is a transfer without a record.
+	StatusUnexpected
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusMatched:
+		return "matched"
+	case StatusMismatched:
+		return "mismatched"
+	case StatusMissing:
+		return "missing"
+	case StatusUnexpected:
+		return "unexpected"
+	default:
+		return fmt.Sprintf("Status(%d)", uint8(s))
+	}
+}
+
+// Result is the outcome for a record, or for a transfer without a record. Record is zero for
+// StatusUnexpected, and Transfer is zero for StatusMissing.
+type Result struct {
+	Status   Status
+	Record   Record
+	Transfer types.Transfer
+	// Fields names the fields of a mismatched record that differ, after the JSON fields of
+	// Record, such as "amount".
+	Fields []string
+}
+
+// Summary counts the results of a reconciliation.
+type Summary struct {
+	Matched    uint64
+	Mismatched uint64
+	Missing    uint64
+	Unexpected uint64
+}
+
+func (s *Summary) add(result Result) {
+	switch result.Status {
+	case StatusMatched:
+		s.Matched++
+	case StatusMismatched:
+		s.Mismatched++
+	case StatusMissing:
+		s.Missing++
+	case StatusUnexpected:
+		s.Unexpected++
+	}
+}
+
+// ErrDuplicateKey is returned when the key of a record, or of a transfer, is not unique within
+// its side of the reconciliation.
+type ErrDuplicateKey struct {
+	Key types.Uint128
+}
+
+func (e ErrDuplicateKey) Error() string {
+	return "Duplicate key " + e.Key.String() + "."
+}
+
+// Compare reconciles the records against the transfers, matching them by key, and calls report
+// with every result: a record as soon as its transfer is read, and the records and transfers
+// left without a counterpart, in order of key, once both sides are exhausted.
+//
+// Both sides are read in turn. A key must be unique within each side, so transfers that share a
+// user_data_128, such as the legs of a linked chain, should be filtered beforehand.
+func Compare(
+	transfers iter.Seq2[types.Transfer, error],
+	records iter.Seq2[Record, error],
+	key Key,
+	report func(Result) error,
+) (Summary, error) {
+	var summary Summary
+	emit := func(result Result) error {
+		summary.add(result)
+		return report(result)
+	}
+
+	nextTransfer, stopTransfers := iter.Pull2(transfers)
+	defer stopTransfers()
+	nextRecord, stopRecords := iter.Pull2(records)
+	defer stopRecords()
+
+	pendingTransfers := make(map[types.Uint128]types.Transfer)
+	pendingRecords := make(map[types.Uint128]Record)
+	transfersDone, recordsDone := false, false
+	for !transfersDone || !recordsDone {
+		if !transfersDone {
+			transfer, err, ok := nextTransfer()
+			if err != nil {
+				return summary, err
+			}
+			transfersDone = !ok
+			if ok {
+				transferKey := key.of(&transfer)
+				if record, found := pendingRecords[transferKey]; found {
+					delete(pendingRecords, transferKey)
+					if err := emit(match(record, transfer)); err != nil {
+						return summary, err
+					}
+				} else if _, found := pendingTransfers[transferKey]; found {
+					return summary, ErrDuplicateKey{Key: transferKey}
+				} else {
+					pendingTransfers[transferKey] = transfer
+				}
+			}
+		}
+
+		if !recordsDone {
+			record, err, ok := nextRecord()
+			if err != nil {
+				return summary, err
+			}
+			recordsDone = !ok
+			if ok {
+				if transfer, found := pendingTransfers[record.Key]; found {
+					delete(pendingTransfers, record.Key)
+					if err := emit(match(record, transfer)); err != nil {
+						return summary, err
+					}
+				} else if _, found := pendingRecords[record.Key]; found {
+					return summary, ErrDuplicateKey{Key: record.Key}
+				} else {
+					pendingRecords[record.Key] = record
+				}
+			}
+		}
+	}
+
+	for _, recordKey := range sortedKeys(pendingRecords) {
+		if err := emit(Result{Status: StatusMissing, Record: pendingRecords[recordKey]}); err != nil {
+			return summary, err
+		}
+	}
+	for _, transferKey := range sortedKeys(pendingTransfers) {
+		result := Result{Status: StatusUnexpected, Transfer: pendingTransfers[transferKey]}
+		if err := emit(result); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// ByID reconciles records whose keys are transfer IDs by looking them up in chunks, and calls
+// report with the result of every record, in order. Transfers without a record can't be found
+// this way, so none is reported unexpected.
+func ByID(client Client, records iter.Seq2[Record, error], report func(Result) error) (Summary, error) {
+	var summary Summary
+	chunkMax := types.MaxBatchSize(types.OperationLookupTransfers)
+	chunk := make([]Record, 0, chunkMax)
+	ids := make([]types.Uint128, 0, chunkMax)
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		ids = ids[:0]
+		for i := range chunk {
+			ids = append(ids, chunk[i].Key)
+		}
+		transfers, err := client.LookupTransfers(ids)
+		if err != nil {
+			return err
+		}
+		found := make(map[types.Uint128]types.Transfer, len(transfers))
+		for _, transfer := range transfers {
+			found[transfer.ID] = transfer
+		}
+		for _, record := range chunk {
+			result := Result{Status: StatusMissing, Record: record}
+			if transfer, ok := found[record.Key]; ok {
+				result = match(record, transfer)
+			}
+			summary.add(result)
+			if err := report(result); err != nil {
+				return err
+			}
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for record, err := range records {
+		if err != nil {
+			return summary, err
+		}
+		chunk = append(chunk, record)
+		if len(chunk) == chunkMax {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+	return summary, flush()
+}
+
+// AccountTransfers yields the transfers that match filter, requesting a page of up to
+// filter.Limit transfers at a time until the cluster has no more, for the transfers side of
+// Compare. Iteration stops after the first error is yielded.
+func AccountTransfers(client Client, filter types.AccountFilter) iter.Seq2[types.Transfer, error] {
+	return func(yield func(types.Transfer, error) bool) {
+		flags := filter.AccountFilterFlags()
+		for {
+			transfers, err := client.GetAccountTransfers(filter)
+			if err != nil {
+				yield(types.Transfer{}, err)
+				return
+			}
+			for _, transfer := range transfers {
+				if !yield(transfer, nil) {
+					return
+				}
+			}
+			if len(transfers) == 0 || len(transfers) < int(filter.Limit) {
+				return
+			}
+
+			last := transfers[len(transfers)-1].Timestamp
+			if flags.Reversed {
+				filter.TimestampMax = last - 1
+			} else {
+				filter.TimestampMin = last + 1
+			}
+		}
+	}
+}
+
+// match compares a record with the transfer of the same key.
+func match(record Record, transfer types.Transfer) Result {
+	var fields []string
+	if record.Amount != transfer.Amount {
+		fields = append(fields, "amount")
+	}
+	if record.DebitAccountID != (types.Uint128{}) && record.DebitAccountID != transfer.DebitAccountID {
+		fields = append(fields, "debit_account_id")
+	}
+	if record.CreditAccountID != (types.Uint128{}) &&
+		record.CreditAccountID != transfer.CreditAccountID {
+		fields = append(fields, "credit_account_id")
+	}
+	if record.Ledger != 0 && record.Ledger != transfer.Ledger {
+		fields = append(fields, "ledger")
+	}
+	if record.Code != 0 && record.Code != transfer.Code {
+		fields = append(fields, "code")
+	}
+
+	status := StatusMatched
+	if len(fields) > 0 {
+		status = StatusMismatched
+	}
+	return Result{Status: status, Record: record, Transfer: transfer, Fields: fields}
+}
+
+func sortedKeys[V any](pending map[types.Uint128]V) []types.Uint128 {
+	return slices.SortedFunc(maps.Keys(pending), func(a, b types.Uint128) int {
+		aBig, bBig := a.BigInt(), b.BigInt()
+		return aBig.Cmp(&bBig)
+	})
+}
@@ -0,0 +1,200 @@
+package reconcile
+
+import (
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// fakeClient holds transfers in order of timestamp.
+type fakeClient struct {
+	transfers []types.Transfer
+	lookups   int
+	pages     int
+}
+
+func (c *fakeClient) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	c.lookups++
+	var found []types.Transfer
+	for _, id := range transferIDs {
+		for _, transfer := range c.transfers {
+			if transfer.ID == id {
+				found = append(found, transfer)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (c *fakeClient) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	c.pages++
+	var page []types.Transfer
+	for _, transfer := range c.transfers {
+		if transfer.Timestamp >= filter.TimestampMin && len(page) < int(filter.Limit) {
+			page = append(page, transfer)
+		}
+	}
+	return page, nil
+}
+
+func transfer(id uint64, reference uint64, amount uint64) types.Transfer {
+	return types.Transfer{
+		ID:          types.ToUint128(id),
+		UserData128: types.ToUint128(reference),
+		Amount:      types.ToUint128(amount),
+		Ledger:      1,
+		Code:        1,
+		Timestamp:   id,
+	}
+}
+
+func records(list ...Record) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for _, record := range list {
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+func collect(results *[]Result) func(Result) error {
+	return func(result Result) error {
+		*results = append(*results, result)
+		return nil
+	}
+}
+
+func TestCompare(t *testing.T) {
+	client := &fakeClient{transfers: []types.Transfer{
+		transfer(1, 101, 10),
+		transfer(2, 102, 20),
+		transfer(3, 103, 30),
+		transfer(4, 104, 40),
+	}}
+	filter, err := types.NewAccountFilter(types.ToUint128(1)).Limit(3).Build()
+	assert.Equal(t, nil, err)
+
+	var results []Result
+	summary, err := Compare(
+		AccountTransfers(client, filter),
+		records(
+			Record{Key: types.ToUint128(102), Amount: types.ToUint128(20)},
+			Record{Key: types.ToUint128(101), Amount: types.ToUint128(10), Ledger: 1},
+			Record{Key: types.ToUint128(105), Amount: types.ToUint128(50)},
+			Record{Key: types.ToUint128(104), Amount: types.ToUint128(41), Code: 2},
+		),
+		KeyUserData128,
+		collect(&results),
+	)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Summary{Matched: 2, Mismatched: 1, Missing: 1, Unexpected: 1}, summary)
+	assert.Equal(t, 2, client.pages)
+
+	assert.Equal(t, 5, len(results))
+	assert.Equal(t, StatusMatched, results[0].Status)
+	assert.Equal(t, types.ToUint128(2), results[0].Transfer.ID)
+	assert.Equal(t, StatusMatched, results[1].Status)
+	assert.Equal(t, StatusMismatched, results[2].Status)
+	assert.Equal(t, []string{"amount", "code"}, results[2].Fields)
+	assert.Equal(t, StatusMissing, results[3].Status)
+	assert.Equal(t, types.ToUint128(105), results[3].Record.Key)
+	assert.Equal(t, StatusUnexpected, results[4].Status)
+	assert.Equal(t, types.ToUint128(3), results[4].Transfer.ID)
+	assert.Equal(t, "unexpected", results[4].Status.String())
+
+	_, err = Compare(
+		AccountTransfers(client, filter),
+		records(Record{Key: types.ToUint128(7)}, Record{Key: types.ToUint128(7)}),
+		KeyID,
+		collect(&results),
+	)
+	assert.Equal(t, ErrDuplicateKey{Key: types.ToUint128(7)}, err)
+
+	failure := errors.New("report failed")
+	_, err = Compare(
+		AccountTransfers(client, filter),
+		records(Record{Key: types.ToUint128(1), Amount: types.ToUint128(10)}),
+		KeyID,
+		func(Result) error { return failure },
+	)
+	assert.Equal(t, failure, err)
+}
+
+func TestByID(t *testing.T) {
+	client := &fakeClient{transfers: []types.Transfer{transfer(1, 0, 10), transfer(2, 0, 20)}}
+	feed := make([]Record, 0, types.MaxBatchSize(types.OperationLookupTransfers)+1)
+	for len(feed) < cap(feed) {
+		feed = append(feed, Record{Key: types.ToUint128(uint64(len(feed) + 1)), Amount: types.ToUint128(10)})
+	}
+
+	var results []Result
+	summary, err := ByID(client, records(feed...), collect(&results))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Summary{Matched: 1, Mismatched: 1, Missing: uint64(len(feed) - 2)}, summary)
+	assert.Equal(t, 2, client.lookups)
+	assert.Equal(t, len(feed), len(results))
+	assert.Equal(t, StatusMismatched, results[1].Status)
+	assert.Equal(t, feed[len(feed)-1], results[len(results)-1].Record)
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "Key,amount,memo,ledger\n" +
+		"0x10,100,rent,1\n" +
+		"17,,,\n"
+	var read []Record
+	for record, err := range ReadCSV(strings.NewReader(input)) {
+		assert.Equal(t, nil, err)
+		read = append(read, record)
+	}
+	assert.Equal(t, []Record{
+		{Key: types.ToUint128(16), Amount: types.ToUint128(100), Ledger: 1},
+		{Key: types.ToUint128(17)},
+	}, read)
+
+	for _, err := range ReadCSV(strings.NewReader("key,code\n1,70000\n")) {
+		assert.True(t, err != nil && strings.HasPrefix(err.Error(), "row 1, column code: "))
+	}
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]any
+}
+
+func (r *fakeRows) Columns() ([]string, error) { return r.columns, nil }
+
+func (r *fakeRows) Next() bool { return len(r.rows) > 0 }
+
+func (r *fakeRows) Scan(dest ...any) error {
+	for i, value := range r.rows[0] {
+		*dest[i].(*any) = value
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func (r *fakeRows) Err() error { return nil }
+
+func TestReadRows(t *testing.T) {
+	rows := &fakeRows{
+		columns: []string{"key", "amount", "code"},
+		rows: [][]any{
+			{[]byte("5"), int64(100), int64(3)},
+			{"6", nil, nil},
+		},
+	}
+	var read []Record
+	for record, err := range ReadRows(rows) {
+		assert.Equal(t, nil, err)
+		read = append(read, record)
+	}
+	assert.Equal(t, []Record{
+		{Key: types.ToUint128(5), Amount: types.ToUint128(100), Code: 3},
+		{Key: types.ToUint128(6)},
+	}, read)
+}
@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	e "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule decides when a schedule occurs.
+type Rule interface {
+	// Next returns the first occurrence after after, of a schedule that starts at start, or the
+	// zero time if there is none.
+	Next(start time.Time, after time.Time) time.Time
+}
+
+// ErrInvalidRule is returned, wrapped with the reason, when parsing a rule that is neither an
+// interval nor a cron expression.
+var ErrInvalidRule = e.New("scheduler: invalid rule")
+
+// ParseRule parses a rule, either an interval as "@every " and a time.Duration, such as
+// "@every 24h", which occurs at the start of the schedule then every interval, or a cron
+// expression of five fields, minute, hour, day of month, month and day of week, such as
+// "0 9 1 * *" for 9:00 on the first of every month. Cron fields take "*", numbers, ranges such as
+// "1-5", steps such as "*/15" and lists of those such as "1,15"; Sunday is 0 or 7. As in cron, a
+// day matches if either the day of month or the day of week matches when both are restricted.
+// Cron expressions are evaluated in UTC.
+func ParseRule(rule string) (Rule, error) {
+	if interval, ok := strings.CutPrefix(rule, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %s", ErrInvalidRule, rule, err)
+		}
+		if every < time.Second {
+			return nil, fmt.Errorf("%w %q: interval is shorter than a second", ErrInvalidRule, rule)
+		}
+		return intervalRule(every), nil
+	}
+
+	fields := strings.Fields(rule)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w %q: expected an interval or 5 cron fields", ErrInvalidRule, rule)
+	}
+	var cron cronRule
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	sets := [5]*uint64{&cron.minutes, &cron.hours, &cron.days, &cron.months, &cron.weekdays}
+	for i, field := range fields {
+		set, err := parseField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %s", ErrInvalidRule, rule, err)
+		}
+		*sets[i] = set
+	}
+	// Sunday is both 0 and 7.
+	if cron.weekdays&(1<<7) != 0 {
+		cron.weekdays |= 1
+	}
+	cron.anyDay = fields[2] == "*"
+	cron.anyWeekday = fields[4] == "*"
+	return cron, nil
+}
+
+// intervalRule occurs at the start, then every interval.
+type intervalRule time.Duration
+
+func (r intervalRule) Next(start time.Time, after time.Time) time.Time {
+	if after.Before(start) {
+		return start
+	}
+	every := time.Duration(r)
+	return start.Add((after.Sub(start)/every + 1) * every)
+}
+
+// cronRule holds the values that every field of a cron expression matches, as bit sets.
+type cronRule struct {
+	minutes, hours, days, months, weekdays uint64
+	anyDay, anyWeekday                     bool
+}
+
+func (r cronRule) Next(start time.Time, after time.Time) time.Time {
+	if after.Before(start) {
+		after = start.Add(-time.Nanosecond)
+	}
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	// A date that exists, the 29th of February included, matches within 5 years.
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		switch {
+		case r.months&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !r.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case r.hours&(1<<t.Hour()) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case r.minutes&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (r cronRule) matchesDay(t time.Time) bool {
+	day := r.days&(1<<t.Day()) != 0
+	weekday := r.weekdays&(1<<int(t.Weekday())) != 0
+	switch {
+	case r.anyDay && r.anyWeekday:
+		return true
+	case r.anyDay:
+		return weekday
+	case r.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
+
+// parseField returns the set of values from min to max that a cron field matches.
+func parseField(field string, min int, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		span, stepText, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+		}
+
+		low, high := min, max
+		if span != "*" {
+			lowText, highText, ranged := strings.Cut(span, "-")
+			var err error
+			if low, err = strconv.Atoi(lowText); err != nil {
+				return 0, fmt.Errorf("invalid value %q", lowText)
+			}
+			high = low
+			if ranged {
+				if high, err = strconv.Atoi(highText); err != nil {
+					return 0, fmt.Errorf("invalid value %q", highText)
+				}
+			} else if stepped {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q is not within %d-%d", part, min, max)
+		}
+		for value := low; value <= high; value += step {
+			set |= 1 << value
+		}
+	}
+	return set, nil
+}
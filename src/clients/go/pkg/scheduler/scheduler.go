@@ -0,0 +1,226 @@
+// Package scheduler submits recurring transfers, such as a monthly rent or a daily sweep, from
+// schedules kept in a Store.
+//
+// Every occurrence of a schedule is submitted with an ID derived from the schedule and the time
+// of the occurrence, so that submitting it again, after a crash between submitting it and
+// recording it in the store, creates nothing new. After downtime, the occurrences missed are
+// caught up according to Config.CatchUp.
+//
+//	store := scheduler.NewFileStore("schedules.json")
+//	s := scheduler.New(client, scheduler.Config{Store: store})
+//	err := s.Add(scheduler.Schedule{
+//		ID:    types.ID(),
+//		Rule:  "0 9 1 * *",
+//		Start: time.Now(),
+//		Transfer: types.Transfer{
+//			DebitAccountID:  tenant,
+//			CreditAccountID: landlord,
+//			Amount:          types.ToUint128(120000),
+//			Ledger:          1,
+//			Code:            1,
+//		},
+//	})
+//	go s.Run(ctx)
+package scheduler
+
+import (
+	"context"
+	"encoding/binary"
+	e "errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// pollIntervalDefault is how often Run checks for due occurrences by default.
+const pollIntervalDefault = time.Second
+
+// ErrScheduleID is returned when adding a schedule without an ID.
+var ErrScheduleID = e.New("scheduler: schedule ID must not be zero")
+
+// Client is the part of the TigerBeetle client that schedules submit to.
+type Client interface {
+	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+}
+
+// Schedule is a transfer to submit on every occurrence of a rule.
+type Schedule struct {
+	// ID identifies the schedule, and seeds the IDs of the transfers it submits.
+	ID types.Uint128
+	// Rule is when the schedule occurs, as parsed by ParseRule.
+	Rule string
+	// Start is when the schedule starts: its first occurrence is the first of Rule at or after
+	// Start, and occurs at Start for an interval.
+	Start time.Time
+	// End, unless zero, is when the schedule ends: no occurrence after End is submitted.
+	End time.Time
+	// Transfer is submitted on every occurrence, with its ID replaced by OccurrenceID.
+	Transfer types.Transfer
+	// Last is the last occurrence submitted, or zero before the first. The scheduler updates it.
+	Last time.Time
+}
+
+// Store persists schedules, and how far they have run.
+type Store interface {
+	// Schedules returns every schedule.
+	Schedules() ([]Schedule, error)
+	// Save adds schedule, or replaces the schedule of the same ID.
+	Save(schedule Schedule) error
+}
+
+// CatchUp decides which of the occurrences missed while the scheduler was not running are
+// submitted.
+type CatchUp uint8
+
+const (
+	// CatchUpAll submits every occurrence missed, oldest first.
+	CatchUpAll CatchUp = iota
+	// CatchUpLatest submits only the latest occurrence missed, and skips the earlier ones.
+	CatchUpLatest
+)
+
+// Config configures a Scheduler. Zero fields take their defaults.
+type Config struct {
+	// Store keeps the schedules. Required.
+	Store Store
+	// CatchUp decides which missed occurrences are submitted. Defaults to CatchUpAll.
+	CatchUp CatchUp
+	// PollInterval is how often Run checks for due occurrences. Defaults to a second.
+	PollInterval time.Duration
+	// OnRejected is called with the transfer of every occurrence that the cluster rejected, and
+	// the result. The occurrence is not submitted again. Optional.
+	OnRejected func(schedule Schedule, transfer types.Transfer, result types.CreateTransferResult)
+}
+
+// Scheduler submits the occurrences of the schedules in its store as they come due. It is safe
+// for concurrent use.
+type Scheduler struct {
+	client Client
+	config Config
+	// mutex serializes the runs, and the runs with adding schedules.
+	mutex sync.Mutex
+}
+
+// New returns a scheduler of the schedules in config.Store, submitting to client.
+func New(client Client, config Config) *Scheduler {
+	if config.Store == nil {
+		panic("scheduler: Config.Store is required")
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = pollIntervalDefault
+	}
+	return &Scheduler{client: client, config: config}
+}
+
+// Add saves schedule to the store, once its rule parses. Adding a schedule with the ID of one
+// already in the store replaces it, from Last on.
+func (s *Scheduler) Add(schedule Schedule) error {
+	if schedule.ID == (types.Uint128{}) {
+		return ErrScheduleID
+	}
+	if _, err := ParseRule(schedule.Rule); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.config.Store.Save(schedule)
+}
+
+// OccurrenceID returns the ID of the transfer that the schedule of ID scheduleID submits for
+// the occurrence at occurrence.
+func OccurrenceID(scheduleID types.Uint128, occurrence time.Time) types.Uint128 {
+	id := scheduleID.Bytes()
+	var nanoseconds [8]byte
+	binary.LittleEndian.PutUint64(nanoseconds[:], uint64(occurrence.UnixNano()))
+	return types.IDFromHash("scheduler", id[:], nanoseconds[:])
+}
+
+// Run submits the occurrences due every PollInterval, until ctx is done or a run fails, returning
+// ctx.Err() or the error of the run.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.RunDue(time.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// RunDue submits the occurrences of every schedule due at now that were not submitted yet, and
+// records them in the store. If submitting fails, the schedule is left at its last occurrence
+// recorded, for the next run to submit the same transfers again.
+func (s *Scheduler) RunDue(now time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	schedules, err := s.config.Store.Schedules()
+	if err != nil {
+		return fmt.Errorf("scheduler: loading schedules: %w", err)
+	}
+	for _, schedule := range schedules {
+		if err := s.runSchedule(schedule, now); err != nil {
+			return fmt.Errorf("scheduler: schedule %s: %w", schedule.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) runSchedule(schedule Schedule, now time.Time) error {
+	rule, err := ParseRule(schedule.Rule)
+	if err != nil {
+		return err
+	}
+
+	var occurrences []time.Time
+	after := schedule.Last
+	if after.IsZero() {
+		after = schedule.Start.Add(-time.Nanosecond)
+	}
+	for {
+		next := rule.Next(schedule.Start, after)
+		if next.IsZero() || next.After(now) || (!schedule.End.IsZero() && next.After(schedule.End)) {
+			break
+		}
+		if s.config.CatchUp == CatchUpLatest && len(occurrences) > 0 {
+			occurrences[0] = next
+		} else {
+			occurrences = append(occurrences, next)
+		}
+		after = next
+	}
+
+	batchMax := types.MaxBatchSize(types.OperationCreateTransfers)
+	for chunk := range slices.Chunk(occurrences, batchMax) {
+		transfers := make([]types.Transfer, len(chunk))
+		for i, occurrence := range chunk {
+			transfers[i] = schedule.Transfer
+			transfers[i].ID = OccurrenceID(schedule.ID, occurrence)
+		}
+		results, err := s.client.CreateTransfers(transfers)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			// An occurrence that exists was submitted before a crash.
+			if result.Result != types.TransferExists && s.config.OnRejected != nil {
+				s.config.OnRejected(schedule, transfers[result.Index], result.Result)
+			}
+		}
+
+		schedule.Last = chunk[len(chunk)-1]
+		if err := s.config.Store.Save(schedule); err != nil {
+			return fmt.Errorf("saving: %w", err)
+		}
+	}
+	return nil
+}
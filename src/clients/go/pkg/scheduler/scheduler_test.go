@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	e "errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbtest"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func TestRule(t *testing.T) {
+	start := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	next := func(spec string, after time.Time) time.Time {
+		rule, err := ParseRule(spec)
+		assert.Equal(t, nil, err)
+		return rule.Next(start, after)
+	}
+
+	assert.Equal(t, start, next("@every 1h", time.Time{}))
+	assert.Equal(t, start.Add(2*time.Hour), next("@every 1h", start.Add(90*time.Minute)))
+	assert.Equal(t, time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC), next("0 9 1 * *", start))
+	assert.Equal(t, start.Add(15*time.Minute), next("*/15 * * * *", start))
+	// The 3rd of February 2024 is a Saturday.
+	assert.Equal(t,
+		time.Date(2024, 2, 5, 9, 30, 0, 0, time.UTC),
+		next("30 9 * * 1-5", time.Date(2024, 2, 2, 10, 0, 0, 0, time.UTC)))
+	// Either the day of month or the day of week.
+	assert.Equal(t,
+		time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC),
+		next("0 0 15 * 0", time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), next("0 0 29 2 *", start))
+	assert.Equal(t, time.Time{}, next("0 0 30 2 *", start))
+
+	for _, spec := range []string{"", "@every 1ms", "@every soon", "* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *"} {
+		_, err := ParseRule(spec)
+		assert.True(t, e.Is(err, ErrInvalidRule))
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	clock := tbtest.NewClock(time.Time{})
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1,
+		tigerbeetle_go.WithTransport(tbtest.NewLedger(clock)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	_, err = client.CreateAccounts([]types.Account{
+		{ID: types.ToUint128(1), Ledger: 1, Code: 1},
+		{ID: types.ToUint128(2), Ledger: 1, Code: 1},
+	})
+	assert.Equal(t, nil, err)
+
+	credited := func() types.Uint128 {
+		accounts, err := client.LookupAccounts([]types.Uint128{types.ToUint128(2)})
+		assert.Equal(t, nil, err)
+		return accounts[0].CreditsPosted
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := Schedule{
+		ID:    types.ToUint128(7),
+		Rule:  "@every 1h",
+		Start: start,
+		End:   start.Add(10 * time.Hour),
+		Transfer: types.Transfer{
+			DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2),
+			Amount: types.ToUint128(1), Ledger: 1, Code: 1,
+		},
+	}
+	var rejected []types.CreateTransferResult
+	config := Config{
+		Store: NewFileStore(filepath.Join(t.TempDir(), "schedules.json")),
+		OnRejected: func(_ Schedule, _ types.Transfer, result types.CreateTransferResult) {
+			rejected = append(rejected, result)
+		},
+	}
+	scheduler := New(client, config)
+	assert.Equal(t, ErrScheduleID, scheduler.Add(Schedule{Rule: "@every 1h"}))
+	assert.True(t, e.Is(scheduler.Add(Schedule{ID: schedule.ID, Rule: "hourly"}), ErrInvalidRule))
+	assert.Equal(t, nil, scheduler.Add(schedule))
+
+	t.Run("catches up every occurrence", func(t *testing.T) {
+		assert.Equal(t, nil, scheduler.RunDue(start.Add(-time.Minute)))
+		assert.Equal(t, types.ToUint128(0), credited())
+
+		assert.Equal(t, nil, scheduler.RunDue(start.Add(150*time.Minute)))
+		assert.Equal(t, types.ToUint128(3), credited())
+		assert.Equal(t, nil, scheduler.RunDue(start.Add(150*time.Minute)))
+		assert.Equal(t, types.ToUint128(3), credited())
+
+		schedules, err := config.Store.Schedules()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, start.Add(2*time.Hour), schedules[0].Last.UTC())
+	})
+
+	t.Run("resubmits idempotently", func(t *testing.T) {
+		// A crash before recording the occurrences submitted leaves the schedule behind.
+		assert.Equal(t, nil, scheduler.Add(schedule))
+		assert.Equal(t, nil, New(client, config).RunDue(start.Add(3*time.Hour)))
+		assert.Equal(t, types.ToUint128(4), credited())
+		assert.Len(t, rejected, 0)
+	})
+
+	t.Run("catches up the latest occurrence", func(t *testing.T) {
+		latest := config
+		latest.CatchUp = CatchUpLatest
+		assert.Equal(t, nil, New(client, latest).RunDue(start.Add(6*time.Hour)))
+		assert.Equal(t, types.ToUint128(5), credited())
+	})
+
+	t.Run("stops at the end", func(t *testing.T) {
+		assert.Equal(t, nil, scheduler.RunDue(start.Add(24*time.Hour)))
+		assert.Equal(t, types.ToUint128(9), credited())
+	})
+
+	t.Run("reports rejected occurrences", func(t *testing.T) {
+		mismatched := schedule
+		mismatched.ID = types.ToUint128(8)
+		mismatched.Transfer.Ledger = 2
+		assert.Equal(t, nil, scheduler.Add(mismatched))
+		assert.Equal(t, nil, scheduler.RunDue(start))
+		assert.Equal(t, []types.CreateTransferResult{types.TransferTransferMustHaveTheSameLedgerAsAccounts}, rejected)
+	})
+}
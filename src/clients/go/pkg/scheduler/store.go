@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"encoding/json"
+	e "errors"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+)
+
+// FileStore keeps schedules in a JSON file, which every save replaces atomically, so that a
+// crash leaves either the schedules before the save or after it. It is safe for concurrent use
+// within a process.
+type FileStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileStore returns a store of schedules in the file at path, which is created on the first
+// save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Schedules returns the schedules in the file, in the order they were first saved.
+func (s *FileStore) Schedules() ([]Schedule, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.read()
+}
+
+// Save adds schedule to the file, or replaces the schedule of the same ID.
+func (s *FileStore) Save(schedule Schedule) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	schedules, err := s.read()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(schedules, func(saved Schedule) bool { return saved.ID == schedule.ID })
+	if i < 0 {
+		schedules = append(schedules, schedule)
+	} else {
+		schedules[i] = schedule
+	}
+
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp := s.path + ".tmp"
+	if err := os.WriteFile(temp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temp, s.path)
+}
+
+func (s *FileStore) read() ([]Schedule, error) {
+	data, err := os.ReadFile(s.path)
+	if e.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var schedules []Schedule
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
@@ -0,0 +1,279 @@
+// Package tbdebug serves the internals of the clients of a process as JSON, for triage in
+// production without a metrics stack: their stats, their connection state, the requests in
+// flight and how long they have been, and the last requests that failed.
+//
+// A Monitor watches a client through an interceptor, under a name:
+//
+//	monitor := tbdebug.New("payments")
+//	client, err := tigerbeetle_go.NewClient(clusterID, addresses, 32,
+//		tigerbeetle_go.WithInterceptor(monitor.Interceptor()))
+//	monitor.SetClient(client)
+//
+// Importing the package serves every monitor under /debug/tigerbeetle of
+// http.DefaultServeMux, as net/http/pprof does, and publishes them as the expvar "tigerbeetle",
+// under /debug/vars. Handler serves them on another mux.
+package tbdebug
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// recentErrorsMax is how many of the last failed requests a monitor keeps.
+const recentErrorsMax = 32
+
+func init() {
+	http.Handle("/debug/tigerbeetle", Handler())
+	expvar.Publish("tigerbeetle", expvar.Func(func() any { return snapshots() }))
+}
+
+// Client is the part of the TigerBeetle client that a monitor reads.
+type Client interface {
+	State() tigerbeetle_go.ConnectionState
+	Stats() tigerbeetle_go.Stats
+}
+
+var (
+	registryMutex sync.Mutex
+	registry      = make(map[string]*Monitor)
+)
+
+// Monitor watches the requests of a client. It is safe for concurrent use.
+type Monitor struct {
+	name string
+
+	mutex    sync.Mutex
+	client   Client
+	next     uint64
+	inFlight map[uint64]Request
+	// errors holds the last failed requests, as a ring starting at errorsStart once full.
+	errors      []RequestError
+	errorsStart int
+}
+
+// New registers a monitor under name, which must be unique among the monitors of the process
+// until the monitor is closed.
+func New(name string) *Monitor {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, ok := registry[name]; ok {
+		panic("tbdebug: " + name + " is registered already")
+	}
+	monitor := &Monitor{name: name, inFlight: make(map[uint64]Request)}
+	registry[name] = monitor
+	return monitor
+}
+
+// SetClient sets the client whose stats and state the monitor serves, once it is created with
+// the interceptor of the monitor.
+func (m *Monitor) SetClient(client Client) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.client = client
+}
+
+// Close unregisters the monitor, for its name to be reused, as by a client rebuilt after an
+// eviction.
+func (m *Monitor) Close() {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if registry[m.name] == m {
+		delete(registry, m.name)
+	}
+}
+
+// Interceptor returns the interceptor that tracks the requests of the client. Interceptors run
+// before requests are admitted, so the requests in flight include those held back by Pause or
+// waiting for a request slot. Add it first for them to include the time spent in the other
+// interceptors.
+func (m *Monitor) Interceptor() tigerbeetle_go.Interceptor {
+	return func(ctx context.Context, op types.Operation, events any, next tigerbeetle_go.Invoker) (any, error) {
+		request := Request{Operation: op.String(), Events: eventCount(events), Started: time.Now()}
+		m.mutex.Lock()
+		id := m.next
+		m.next++
+		m.inFlight[id] = request
+		m.mutex.Unlock()
+
+		results, err := next(ctx, op, events)
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		delete(m.inFlight, id)
+		if err != nil {
+			m.recordError(RequestError{
+				Request:  request,
+				Error:    err.Error(),
+				Duration: time.Since(request.Started).Seconds(),
+			})
+		}
+		return results, err
+	}
+}
+
+func (m *Monitor) recordError(failed RequestError) {
+	if len(m.errors) < recentErrorsMax {
+		m.errors = append(m.errors, failed)
+		return
+	}
+	m.errors[m.errorsStart] = failed
+	m.errorsStart = (m.errorsStart + 1) % recentErrorsMax
+}
+
+// eventCount returns the number of events of a request, one for a filter, and zero for the
+// encoded events of SubmitRaw, whose size depends on the operation.
+func eventCount(events any) int {
+	if _, ok := events.([]byte); ok {
+		return 0
+	}
+	if value := reflect.ValueOf(events); value.Kind() == reflect.Slice {
+		return value.Len()
+	}
+	return 1
+}
+
+// Request is a request of a client.
+type Request struct {
+	Operation string    `json:"operation"`
+	Events    int       `json:"events"`
+	Started   time.Time `json:"started"`
+	// Age is how long the request has been in flight, in seconds.
+	Age float64 `json:"age_seconds,omitempty"`
+}
+
+// RequestError is a request that failed with an error, rather than with the results of events
+// that failed.
+type RequestError struct {
+	Request
+	Error string `json:"error"`
+	// Duration is how long the request took to fail, in seconds.
+	Duration float64 `json:"duration_seconds"`
+}
+
+// Snapshot is what a monitor serves.
+type Snapshot struct {
+	Name string `json:"name"`
+	// State and Stats are left out until the client is set.
+	State string `json:"state,omitempty"`
+	Stats *Stats `json:"stats,omitempty"`
+	// InFlight holds the requests in flight, oldest first.
+	InFlight []Request `json:"in_flight"`
+	// RecentErrors holds the last requests that failed, newest first.
+	RecentErrors []RequestError `json:"recent_errors"`
+}
+
+// Stats is tigerbeetle_go.Stats in JSON.
+type Stats struct {
+	InFlight      int                       `json:"in_flight"`
+	Queued        int                       `json:"queued"`
+	Operations    map[string]OperationStats `json:"operations"`
+	BytesSent     uint64                    `json:"bytes_sent"`
+	BytesReceived uint64                    `json:"bytes_received"`
+	LastCompleted *time.Time                `json:"last_completed,omitempty"`
+	RateLimited   uint64                    `json:"rate_limited"`
+	// RateLimitWait is in seconds.
+	RateLimitWait float64 `json:"rate_limit_wait_seconds"`
+	Circuit       string  `json:"circuit"`
+}
+
+// OperationStats is tigerbeetle_go.OperationStats in JSON.
+type OperationStats struct {
+	Requests uint64 `json:"requests"`
+	Errors   uint64 `json:"errors"`
+}
+
+func newStats(stats tigerbeetle_go.Stats) *Stats {
+	converted := &Stats{
+		InFlight:      stats.InFlight,
+		Queued:        stats.Queued,
+		Operations:    make(map[string]OperationStats, len(stats.Operations)),
+		BytesSent:     stats.BytesSent,
+		BytesReceived: stats.BytesReceived,
+		RateLimited:   stats.RateLimited,
+		RateLimitWait: stats.RateLimitWait.Seconds(),
+		Circuit:       stats.Circuit.String(),
+	}
+	for op, operation := range stats.Operations {
+		converted.Operations[op.String()] = OperationStats{Requests: operation.Requests, Errors: operation.Errors}
+	}
+	if !stats.LastCompleted.IsZero() {
+		converted.LastCompleted = &stats.LastCompleted
+	}
+	return converted
+}
+
+// Snapshot returns what the monitor serves, as of now.
+func (m *Monitor) Snapshot() Snapshot {
+	m.mutex.Lock()
+	snapshot := Snapshot{
+		Name:         m.name,
+		InFlight:     make([]Request, 0, len(m.inFlight)),
+		RecentErrors: make([]RequestError, 0, len(m.errors)),
+	}
+	now := time.Now()
+	for _, request := range m.inFlight {
+		request.Age = now.Sub(request.Started).Seconds()
+		snapshot.InFlight = append(snapshot.InFlight, request)
+	}
+	for i := range m.errors {
+		snapshot.RecentErrors = append(snapshot.RecentErrors, m.errors[(m.errorsStart+len(m.errors)-1-i)%len(m.errors)])
+	}
+	client := m.client
+	m.mutex.Unlock()
+
+	slices.SortFunc(snapshot.InFlight, func(a, b Request) int { return a.Started.Compare(b.Started) })
+	if client != nil {
+		snapshot.State = client.State().String()
+		snapshot.Stats = newStats(client.Stats())
+	}
+	return snapshot
+}
+
+// snapshots returns the snapshots of every monitor, by name.
+func snapshots() []Snapshot {
+	registryMutex.Lock()
+	monitors := make([]*Monitor, 0, len(registry))
+	for _, monitor := range registry {
+		monitors = append(monitors, monitor)
+	}
+	registryMutex.Unlock()
+
+	slices.SortFunc(monitors, func(a, b *Monitor) int { return strings.Compare(a.name, b.name) })
+	snapshots := make([]Snapshot, len(monitors))
+	for i, monitor := range monitors {
+		snapshots[i] = monitor.Snapshot()
+	}
+	return snapshots
+}
+
+// Handler serves the snapshots of every monitor, by name, as a JSON array, or the snapshot of
+// the monitor named by the query parameter client.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any = snapshots()
+		if name := r.URL.Query().Get("client"); name != "" {
+			registryMutex.Lock()
+			monitor, ok := registry[name]
+			registryMutex.Unlock()
+			if !ok {
+				http.Error(w, "Unknown client "+name+".", http.StatusNotFound)
+				return
+			}
+			body = monitor.Snapshot()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(body)
+	})
+}
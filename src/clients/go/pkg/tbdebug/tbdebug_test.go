@@ -0,0 +1,98 @@
+package tbdebug
+
+import (
+	"context"
+	"encoding/json"
+	e "errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbtest"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func TestMonitor(t *testing.T) {
+	monitor := New("test")
+	defer monitor.Close()
+
+	// Lookups of transfers hang until released, and those of accounts fail.
+	release := make(chan struct{})
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 4,
+		tigerbeetle_go.WithTransport(tbtest.NewLedger(tbtest.NewClock(time.Time{}))),
+		tigerbeetle_go.WithInterceptor(monitor.Interceptor()),
+		tigerbeetle_go.WithInterceptor(func(ctx context.Context, op types.Operation, events any, next tigerbeetle_go.Invoker) (any, error) {
+			switch op {
+			case types.OperationLookupTransfers:
+				<-release
+			case types.OperationLookupAccounts:
+				return nil, e.New("lookup failed")
+			}
+			return next(ctx, op, events)
+		}),
+	)
+	assert.Equal(t, nil, err)
+	defer client.Close()
+
+	snapshot := monitor.Snapshot()
+	assert.Equal(t, "test", snapshot.Name)
+	assert.Equal(t, "", snapshot.State)
+
+	monitor.SetClient(client)
+	_, err = client.CreateAccounts([]types.Account{{ID: types.ToUint128(1), Ledger: 1, Code: 1}})
+	assert.Equal(t, nil, err)
+	for range recentErrorsMax + 1 {
+		_, err = client.LookupAccounts([]types.Uint128{types.ToUint128(1), types.ToUint128(2)})
+		assert.Equal(t, "lookup failed", err.Error())
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = client.LookupTransfers([]types.Uint128{types.ToUint128(1)})
+	}()
+	for len(monitor.Snapshot().InFlight) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	snapshot = monitor.Snapshot()
+	assert.Equal(t, "connected", snapshot.State)
+	assert.Equal(t, OperationStats{Requests: 1}, snapshot.Stats.Operations["CreateAccounts"])
+	assert.Len(t, snapshot.InFlight, 1)
+	assert.Equal(t, "LookupTransfers", snapshot.InFlight[0].Operation)
+	assert.Equal(t, 1, snapshot.InFlight[0].Events)
+	assert.True(t, snapshot.InFlight[0].Age >= 0)
+	assert.Len(t, snapshot.RecentErrors, recentErrorsMax)
+	assert.Equal(t, "LookupAccounts", snapshot.RecentErrors[0].Operation)
+	assert.Equal(t, 2, snapshot.RecentErrors[0].Events)
+	assert.Equal(t, "lookup failed", snapshot.RecentErrors[0].Error)
+	assert.True(t, !snapshot.RecentErrors[0].Started.Before(snapshot.RecentErrors[1].Started))
+
+	close(release)
+	<-done
+	assert.Len(t, monitor.Snapshot().InFlight, 0)
+
+	// The monitors are served under /debug/tigerbeetle, and as an expvar.
+	response := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(response, httptest.NewRequest("GET", "/debug/tigerbeetle", nil))
+	assert.Equal(t, http.StatusOK, response.Code)
+	var served []Snapshot
+	assert.Equal(t, nil, json.Unmarshal(response.Body.Bytes(), &served))
+	assert.Len(t, served, 1)
+	assert.Equal(t, "test", served[0].Name)
+
+	response = httptest.NewRecorder()
+	Handler().ServeHTTP(response, httptest.NewRequest("GET", "/?client=test", nil))
+	var one Snapshot
+	assert.Equal(t, nil, json.Unmarshal(response.Body.Bytes(), &one))
+	assert.Equal(t, "test", one.Name)
+	response = httptest.NewRecorder()
+	Handler().ServeHTTP(response, httptest.NewRequest("GET", "/?client=other", nil))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	assert.Equal(t, nil, json.Unmarshal([]byte(expvar.Get("tigerbeetle").String()), &served))
+	assert.Len(t, served, 1)
+}
@@ -0,0 +1,103 @@
+// Package tblogrus sends the logs of clients to a logrus logger, for applications that log with
+// logrus:
+//
+//	client, err := tigerbeetle_go.NewClient(clusterID, addresses, 32,
+//		tigerbeetle_go.WithLogger(tblogrus.NewLogger(logrus.StandardLogger())))
+//
+// The logs of tb_client keep their level, from its levels err, warn, info and debug to logrus'
+// error, warn, info and debug, and are tagged with a source field of tb_client.
+package tblogrus
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewLogger returns a logger for tigerbeetle_go.WithLogger that logs to logger.
+func NewLogger(logger *logrus.Logger) *slog.Logger {
+	return slog.New(NewHandler(logrus.NewEntry(logger)))
+}
+
+// NewHandler returns a slog.Handler that logs to entry, with its fields. Logrus fields are flat,
+// so the attributes of groups are fields with the names of their groups as a dotted prefix, as
+// "request.operation".
+func NewHandler(entry *logrus.Entry) slog.Handler {
+	return &handler{entry: entry}
+}
+
+type handler struct {
+	entry *logrus.Entry
+	// prefix is the prefix of the groups opened by WithGroup, ending with a dot.
+	prefix string
+}
+
+// Level returns the logrus level of a slog level. Levels between those of slog are rounded down,
+// and those below slog.LevelDebug are trace. Logrus' fatal and panic levels, which exit and
+// panic, are never returned.
+func Level(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	case level >= slog.LevelDebug:
+		return logrus.DebugLevel
+	}
+	return logrus.TraceLevel
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.entry.Logger.IsLevelEnabled(Level(level))
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(logrus.Fields, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		addField(fields, h.prefix, attr)
+		return true
+	})
+	entry := h.entry.WithContext(ctx).WithFields(fields)
+	if !record.Time.IsZero() {
+		entry = entry.WithTime(record.Time)
+	}
+	entry.Log(Level(record.Level), record.Message)
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(logrus.Fields, len(attrs))
+	for _, attr := range attrs {
+		addField(fields, h.prefix, attr)
+	}
+	return &handler{entry: h.entry.WithFields(fields), prefix: h.prefix}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &handler{entry: h.entry, prefix: h.prefix + name + "."}
+}
+
+// addField adds attr to fields under prefix, or the attributes of its group, leaving it out if
+// slog does: if it is empty.
+func addField(fields logrus.Fields, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range value.Group() {
+			addField(fields, prefix, member)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+	fields[prefix+attr.Key] = value.Any()
+}
@@ -0,0 +1,55 @@
+package tblogrus
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+)
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, logrus.TraceLevel, Level(slog.LevelDebug-4))
+	assert.Equal(t, logrus.DebugLevel, Level(slog.LevelDebug))
+	assert.Equal(t, logrus.InfoLevel, Level(slog.LevelInfo))
+	assert.Equal(t, logrus.InfoLevel, Level(slog.LevelInfo+2))
+	assert.Equal(t, logrus.WarnLevel, Level(slog.LevelWarn))
+	assert.Equal(t, logrus.ErrorLevel, Level(slog.LevelError))
+	assert.Equal(t, logrus.ErrorLevel, Level(slog.LevelError+4))
+}
+
+func TestLogger(t *testing.T) {
+	logrusLogger, hook := test.NewNullLogger()
+	logrusLogger.SetLevel(logrus.InfoLevel)
+	logger := NewLogger(logrusLogger)
+
+	timeout := errors.New("timeout")
+	logger.Debug("left out")
+	logger.Warn("request failed", "operation", "CreateTransfers", "attempt", 2, "error", timeout,
+		"backoff", time.Second)
+	logger.WithGroup("request").With("operation", "LookupAccounts").Error("failed",
+		slog.Group("batch", "size", uint64(8)), slog.Group("", "inline", true), slog.Attr{})
+
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 2)
+
+	assert.Equal(t, logrus.WarnLevel, entries[0].Level)
+	assert.Equal(t, "request failed", entries[0].Message)
+	assert.Equal(t, logrus.Fields{
+		"operation": "CreateTransfers",
+		"attempt":   int64(2),
+		"error":     timeout,
+		"backoff":   time.Second,
+	}, entries[0].Data)
+
+	assert.Equal(t, logrus.ErrorLevel, entries[1].Level)
+	assert.Equal(t, logrus.Fields{
+		"request.operation":  "LookupAccounts",
+		"request.batch.size": uint64(8),
+		"request.inline":     true,
+	}, entries[1].Data)
+}
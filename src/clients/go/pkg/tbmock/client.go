@@ -0,0 +1,1100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/tigerbeetle/tigerbeetle-go (interfaces: Client,ClientOperations,ClientLifecycle)
+//
+// Generated by this command:
+//
+//	mockgen -write_package_comment=false -destination client.go -package tbmock github.com/tigerbeetle/tigerbeetle-go Client,ClientOperations,ClientLifecycle
+//
+
+package tbmock
+
+import (
+	context "context"
+	iter "iter"
+	reflect "reflect"
+	time "time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	types "github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+	isgomock struct{}
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockClient) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockClientMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClient)(nil).Close))
+}
+
+// CloseContext mocks base method.
+func (m *MockClient) CloseContext(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseContext", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseContext indicates an expected call of CloseContext.
+func (mr *MockClientMockRecorder) CloseContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseContext", reflect.TypeOf((*MockClient)(nil).CloseContext), ctx)
+}
+
+// CreateAccount mocks base method.
+func (m *MockClient) CreateAccount(account types.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockClientMockRecorder) CreateAccount(account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockClient)(nil).CreateAccount), account)
+}
+
+// CreateAccounts mocks base method.
+func (m *MockClient) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccounts", accounts)
+	ret0, _ := ret[0].([]types.AccountEventResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccounts indicates an expected call of CreateAccounts.
+func (mr *MockClientMockRecorder) CreateAccounts(accounts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccounts", reflect.TypeOf((*MockClient)(nil).CreateAccounts), accounts)
+}
+
+// CreateAccountsAsync mocks base method.
+func (m *MockClient) CreateAccountsAsync(accounts []types.Account) (*tigerbeetle_go.Future[[]types.AccountEventResult], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountsAsync", accounts)
+	ret0, _ := ret[0].(*tigerbeetle_go.Future[[]types.AccountEventResult])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountsAsync indicates an expected call of CreateAccountsAsync.
+func (mr *MockClientMockRecorder) CreateAccountsAsync(accounts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountsAsync", reflect.TypeOf((*MockClient)(nil).CreateAccountsAsync), accounts)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockClient) CreateTransfer(transfer types.Transfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", transfer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockClientMockRecorder) CreateTransfer(transfer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockClient)(nil).CreateTransfer), transfer)
+}
+
+// CreateTransfers mocks base method.
+func (m *MockClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfers", transfers)
+	ret0, _ := ret[0].([]types.TransferEventResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfers indicates an expected call of CreateTransfers.
+func (mr *MockClientMockRecorder) CreateTransfers(transfers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfers", reflect.TypeOf((*MockClient)(nil).CreateTransfers), transfers)
+}
+
+// CreateTransfersAsync mocks base method.
+func (m *MockClient) CreateTransfersAsync(transfers []types.Transfer) (*tigerbeetle_go.Future[[]types.TransferEventResult], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfersAsync", transfers)
+	ret0, _ := ret[0].(*tigerbeetle_go.Future[[]types.TransferEventResult])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfersAsync indicates an expected call of CreateTransfersAsync.
+func (mr *MockClientMockRecorder) CreateTransfersAsync(transfers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfersAsync", reflect.TypeOf((*MockClient)(nil).CreateTransfersAsync), transfers)
+}
+
+// Done mocks base method.
+func (m *MockClient) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done.
+func (mr *MockClientMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockClient)(nil).Done))
+}
+
+// GetAccountHistory mocks base method.
+func (m *MockClient) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountHistory", filter)
+	ret0, _ := ret[0].([]types.AccountBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountHistory indicates an expected call of GetAccountHistory.
+func (mr *MockClientMockRecorder) GetAccountHistory(filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountHistory", reflect.TypeOf((*MockClient)(nil).GetAccountHistory), filter)
+}
+
+// GetAccountHistoryInto mocks base method.
+func (m *MockClient) GetAccountHistoryInto(filter types.AccountFilter, buf []types.AccountBalance) ([]types.AccountBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountHistoryInto", filter, buf)
+	ret0, _ := ret[0].([]types.AccountBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountHistoryInto indicates an expected call of GetAccountHistoryInto.
+func (mr *MockClientMockRecorder) GetAccountHistoryInto(filter, buf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountHistoryInto", reflect.TypeOf((*MockClient)(nil).GetAccountHistoryInto), filter, buf)
+}
+
+// GetAccountTransfers mocks base method.
+func (m *MockClient) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountTransfers", filter)
+	ret0, _ := ret[0].([]types.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountTransfers indicates an expected call of GetAccountTransfers.
+func (mr *MockClientMockRecorder) GetAccountTransfers(filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountTransfers", reflect.TypeOf((*MockClient)(nil).GetAccountTransfers), filter)
+}
+
+// GetAccountTransfersInto mocks base method.
+func (m *MockClient) GetAccountTransfersInto(filter types.AccountFilter, buf []types.Transfer) ([]types.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountTransfersInto", filter, buf)
+	ret0, _ := ret[0].([]types.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountTransfersInto indicates an expected call of GetAccountTransfersInto.
+func (mr *MockClientMockRecorder) GetAccountTransfersInto(filter, buf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountTransfersInto", reflect.TypeOf((*MockClient)(nil).GetAccountTransfersInto), filter, buf)
+}
+
+// LookupAccount mocks base method.
+func (m *MockClient) LookupAccount(accountID types.Uint128) (types.Account, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupAccount", accountID)
+	ret0, _ := ret[0].(types.Account)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LookupAccount indicates an expected call of LookupAccount.
+func (mr *MockClientMockRecorder) LookupAccount(accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupAccount", reflect.TypeOf((*MockClient)(nil).LookupAccount), accountID)
+}
+
+// LookupAccounts mocks base method.
+func (m *MockClient) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupAccounts", accountIDs)
+	ret0, _ := ret[0].([]types.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupAccounts indicates an expected call of LookupAccounts.
+func (mr *MockClientMockRecorder) LookupAccounts(accountIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupAccounts", reflect.TypeOf((*MockClient)(nil).LookupAccounts), accountIDs)
+}
+
+// LookupAccountsInto mocks base method.
+func (m *MockClient) LookupAccountsInto(accountIDs []types.Uint128, buf []types.Account) ([]types.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupAccountsInto", accountIDs, buf)
+	ret0, _ := ret[0].([]types.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupAccountsInto indicates an expected call of LookupAccountsInto.
+func (mr *MockClientMockRecorder) LookupAccountsInto(accountIDs, buf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupAccountsInto", reflect.TypeOf((*MockClient)(nil).LookupAccountsInto), accountIDs, buf)
+}
+
+// LookupAccountsStream mocks base method.
+func (m *MockClient) LookupAccountsStream(accountIDs iter.Seq[types.Uint128]) iter.Seq2[types.Account, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupAccountsStream", accountIDs)
+	ret0, _ := ret[0].(iter.Seq2[types.Account, error])
+	return ret0
+}
+
+// LookupAccountsStream indicates an expected call of LookupAccountsStream.
+func (mr *MockClientMockRecorder) LookupAccountsStream(accountIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupAccountsStream", reflect.TypeOf((*MockClient)(nil).LookupAccountsStream), accountIDs)
+}
+
+// LookupTransfers mocks base method.
+func (m *MockClient) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupTransfers", transferIDs)
+	ret0, _ := ret[0].([]types.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupTransfers indicates an expected call of LookupTransfers.
+func (mr *MockClientMockRecorder) LookupTransfers(transferIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupTransfers", reflect.TypeOf((*MockClient)(nil).LookupTransfers), transferIDs)
+}
+
+// LookupTransfersInto mocks base method.
+func (m *MockClient) LookupTransfersInto(transferIDs []types.Uint128, buf []types.Transfer) ([]types.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupTransfersInto", transferIDs, buf)
+	ret0, _ := ret[0].([]types.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupTransfersInto indicates an expected call of LookupTransfersInto.
+func (mr *MockClientMockRecorder) LookupTransfersInto(transferIDs, buf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupTransfersInto", reflect.TypeOf((*MockClient)(nil).LookupTransfersInto), transferIDs, buf)
+}
+
+// MessageSizeMax mocks base method.
+func (m *MockClient) MessageSizeMax() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MessageSizeMax")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MessageSizeMax indicates an expected call of MessageSizeMax.
+func (mr *MockClientMockRecorder) MessageSizeMax() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageSizeMax", reflect.TypeOf((*MockClient)(nil).MessageSizeMax))
+}
+
+// Nop mocks base method.
+func (m *MockClient) Nop() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Nop")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Nop indicates an expected call of Nop.
+func (mr *MockClientMockRecorder) Nop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nop", reflect.TypeOf((*MockClient)(nil).Nop))
+}
+
+// Pause mocks base method.
+func (m *MockClient) Pause(ctx context.Context, mode tigerbeetle_go.PauseMode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", ctx, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockClientMockRecorder) Pause(ctx, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockClient)(nil).Pause), ctx, mode)
+}
+
+// Ping mocks base method.
+func (m *MockClient) Ping(ctx context.Context) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockClientMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockClient)(nil).Ping), ctx)
+}
+
+// PostPending mocks base method.
+func (m *MockClient) PostPending(pendingID, amount types.Uint128) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostPending", pendingID, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostPending indicates an expected call of PostPending.
+func (mr *MockClientMockRecorder) PostPending(pendingID, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostPending", reflect.TypeOf((*MockClient)(nil).PostPending), pendingID, amount)
+}
+
+// Resume mocks base method.
+func (m *MockClient) Resume() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Resume")
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockClientMockRecorder) Resume() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockClient)(nil).Resume))
+}
+
+// State mocks base method.
+func (m *MockClient) State() tigerbeetle_go.ConnectionState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "State")
+	ret0, _ := ret[0].(tigerbeetle_go.ConnectionState)
+	return ret0
+}
+
+// State indicates an expected call of State.
+func (mr *MockClientMockRecorder) State() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockClient)(nil).State))
+}
+
+// Stats mocks base method.
+func (m *MockClient) Stats() tigerbeetle_go.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(tigerbeetle_go.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockClientMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockClient)(nil).Stats))
+}
+
+// StreamChanges mocks base method.
+func (m *MockClient) StreamChanges(ctx context.Context, fromTimestamp uint64, accountIDs []types.Uint128) <-chan tigerbeetle_go.ChangeEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamChanges", ctx, fromTimestamp, accountIDs)
+	ret0, _ := ret[0].(<-chan tigerbeetle_go.ChangeEvent)
+	return ret0
+}
+
+// StreamChanges indicates an expected call of StreamChanges.
+func (mr *MockClientMockRecorder) StreamChanges(ctx, fromTimestamp, accountIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamChanges", reflect.TypeOf((*MockClient)(nil).StreamChanges), ctx, fromTimestamp, accountIDs)
+}
+
+// SubmitRaw mocks base method.
+func (m *MockClient) SubmitRaw(op types.Operation, body []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitRaw", op, body)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitRaw indicates an expected call of SubmitRaw.
+func (mr *MockClientMockRecorder) SubmitRaw(op, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitRaw", reflect.TypeOf((*MockClient)(nil).SubmitRaw), op, body)
+}
+
+// TailTransfers mocks base method.
+func (m *MockClient) TailTransfers(ctx context.Context, accountID types.Uint128, sinceTimestamp uint64) <-chan tigerbeetle_go.TailEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TailTransfers", ctx, accountID, sinceTimestamp)
+	ret0, _ := ret[0].(<-chan tigerbeetle_go.TailEvent)
+	return ret0
+}
+
+// TailTransfers indicates an expected call of TailTransfers.
+func (mr *MockClientMockRecorder) TailTransfers(ctx, accountID, sinceTimestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TailTransfers", reflect.TypeOf((*MockClient)(nil).TailTransfers), ctx, accountID, sinceTimestamp)
+}
+
+// TryCreateAccounts mocks base method.
+func (m *MockClient) TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryCreateAccounts", accounts)
+	ret0, _ := ret[0].([]types.AccountEventResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TryCreateAccounts indicates an expected call of TryCreateAccounts.
+func (mr *MockClientMockRecorder) TryCreateAccounts(accounts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryCreateAccounts", reflect.TypeOf((*MockClient)(nil).TryCreateAccounts), accounts)
+}
+
+// TryCreateTransfers mocks base method.
+func (m *MockClient) TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryCreateTransfers", transfers)
+	ret0, _ := ret[0].([]types.TransferEventResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TryCreateTransfers indicates an expected call of TryCreateTransfers.
+func (mr *MockClientMockRecorder) TryCreateTransfers(transfers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryCreateTransfers", reflect.TypeOf((*MockClient)(nil).TryCreateTransfers), transfers)
+}
+
+// UpdateAddresses mocks base method.
+func (m *MockClient) UpdateAddresses(addresses []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAddresses", addresses)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAddresses indicates an expected call of UpdateAddresses.
+func (mr *MockClientMockRecorder) UpdateAddresses(addresses any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAddresses", reflect.TypeOf((*MockClient)(nil).UpdateAddresses), addresses)
+}
+
+// VoidPending mocks base method.
+func (m *MockClient) VoidPending(pendingID types.Uint128) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidPending", pendingID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VoidPending indicates an expected call of VoidPending.
+func (mr *MockClientMockRecorder) VoidPending(pendingID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidPending", reflect.TypeOf((*MockClient)(nil).VoidPending), pendingID)
+}
+
+// WatchBalance mocks base method.
+func (m *MockClient) WatchBalance(ctx context.Context, accountID types.Uint128, options tigerbeetle_go.WatchBalanceOptions) <-chan tigerbeetle_go.BalanceSnapshot {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchBalance", ctx, accountID, options)
+	ret0, _ := ret[0].(<-chan tigerbeetle_go.BalanceSnapshot)
+	return ret0
+}
+
+// WatchBalance indicates an expected call of WatchBalance.
+func (mr *MockClientMockRecorder) WatchBalance(ctx, accountID, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchBalance", reflect.TypeOf((*MockClient)(nil).WatchBalance), ctx, accountID, options)
+}
+
+// MockClientOperations is a mock of ClientOperations interface.
+type MockClientOperations struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientOperationsMockRecorder
+	isgomock struct{}
+}
+
+// MockClientOperationsMockRecorder is the mock recorder for MockClientOperations.
+type MockClientOperationsMockRecorder struct {
+	mock *MockClientOperations
+}
+
+// NewMockClientOperations creates a new mock instance.
+func NewMockClientOperations(ctrl *gomock.Controller) *MockClientOperations {
+	mock := &MockClientOperations{ctrl: ctrl}
+	mock.recorder = &MockClientOperationsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientOperations) EXPECT() *MockClientOperationsMockRecorder {
+	return m.recorder
+}
+
+// CreateAccount mocks base method.
+func (m *MockClientOperations) CreateAccount(account types.Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockClientOperationsMockRecorder) CreateAccount(account any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockClientOperations)(nil).CreateAccount), account)
+}
+
+// CreateAccounts mocks base method.
+func (m *MockClientOperations) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccounts", accounts)
+	ret0, _ := ret[0].([]types.AccountEventResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccounts indicates an expected call of CreateAccounts.
+func (mr *MockClientOperationsMockRecorder) CreateAccounts(accounts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccounts", reflect.TypeOf((*MockClientOperations)(nil).CreateAccounts), accounts)
+}
+
+// CreateAccountsAsync mocks base method.
+func (m *MockClientOperations) CreateAccountsAsync(accounts []types.Account) (*tigerbeetle_go.Future[[]types.AccountEventResult], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountsAsync", accounts)
+	ret0, _ := ret[0].(*tigerbeetle_go.Future[[]types.AccountEventResult])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountsAsync indicates an expected call of CreateAccountsAsync.
+func (mr *MockClientOperationsMockRecorder) CreateAccountsAsync(accounts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountsAsync", reflect.TypeOf((*MockClientOperations)(nil).CreateAccountsAsync), accounts)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockClientOperations) CreateTransfer(transfer types.Transfer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", transfer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockClientOperationsMockRecorder) CreateTransfer(transfer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockClientOperations)(nil).CreateTransfer), transfer)
+}
+
+// CreateTransfers mocks base method.
+func (m *MockClientOperations) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfers", transfers)
+	ret0, _ := ret[0].([]types.TransferEventResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfers indicates an expected call of CreateTransfers.
+func (mr *MockClientOperationsMockRecorder) CreateTransfers(transfers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfers", reflect.TypeOf((*MockClientOperations)(nil).CreateTransfers), transfers)
+}
+
+// CreateTransfersAsync mocks base method.
+func (m *MockClientOperations) CreateTransfersAsync(transfers []types.Transfer) (*tigerbeetle_go.Future[[]types.TransferEventResult], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfersAsync", transfers)
+	ret0, _ := ret[0].(*tigerbeetle_go.Future[[]types.TransferEventResult])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfersAsync indicates an expected call of CreateTransfersAsync.
+func (mr *MockClientOperationsMockRecorder) CreateTransfersAsync(transfers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfersAsync", reflect.TypeOf((*MockClientOperations)(nil).CreateTransfersAsync), transfers)
+}
+
+// GetAccountHistory mocks base method.
+func (m *MockClientOperations) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountHistory", filter)
+	ret0, _ := ret[0].([]types.AccountBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountHistory indicates an expected call of GetAccountHistory.
+func (mr *MockClientOperationsMockRecorder) GetAccountHistory(filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountHistory", reflect.TypeOf((*MockClientOperations)(nil).GetAccountHistory), filter)
+}
+
+// GetAccountHistoryInto mocks base method.
+func (m *MockClientOperations) GetAccountHistoryInto(filter types.AccountFilter, buf []types.AccountBalance) ([]types.AccountBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountHistoryInto", filter, buf)
+	ret0, _ := ret[0].([]types.AccountBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountHistoryInto indicates an expected call of GetAccountHistoryInto.
+func (mr *MockClientOperationsMockRecorder) GetAccountHistoryInto(filter, buf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountHistoryInto", reflect.TypeOf((*MockClientOperations)(nil).GetAccountHistoryInto), filter, buf)
+}
+
+// GetAccountTransfers mocks base method.
+func (m *MockClientOperations) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountTransfers", filter)
+	ret0, _ := ret[0].([]types.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountTransfers indicates an expected call of GetAccountTransfers.
+func (mr *MockClientOperationsMockRecorder) GetAccountTransfers(filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountTransfers", reflect.TypeOf((*MockClientOperations)(nil).GetAccountTransfers), filter)
+}
+
+// GetAccountTransfersInto mocks base method.
+func (m *MockClientOperations) GetAccountTransfersInto(filter types.AccountFilter, buf []types.Transfer) ([]types.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountTransfersInto", filter, buf)
+	ret0, _ := ret[0].([]types.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountTransfersInto indicates an expected call of GetAccountTransfersInto.
+func (mr *MockClientOperationsMockRecorder) GetAccountTransfersInto(filter, buf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountTransfersInto", reflect.TypeOf((*MockClientOperations)(nil).GetAccountTransfersInto), filter, buf)
+}
+
+// LookupAccount mocks base method.
+func (m *MockClientOperations) LookupAccount(accountID types.Uint128) (types.Account, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupAccount", accountID)
+	ret0, _ := ret[0].(types.Account)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LookupAccount indicates an expected call of LookupAccount.
+func (mr *MockClientOperationsMockRecorder) LookupAccount(accountID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupAccount", reflect.TypeOf((*MockClientOperations)(nil).LookupAccount), accountID)
+}
+
+// LookupAccounts mocks base method.
+func (m *MockClientOperations) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupAccounts", accountIDs)
+	ret0, _ := ret[0].([]types.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupAccounts indicates an expected call of LookupAccounts.
+func (mr *MockClientOperationsMockRecorder) LookupAccounts(accountIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupAccounts", reflect.TypeOf((*MockClientOperations)(nil).LookupAccounts), accountIDs)
+}
+
+// LookupAccountsInto mocks base method.
+func (m *MockClientOperations) LookupAccountsInto(accountIDs []types.Uint128, buf []types.Account) ([]types.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupAccountsInto", accountIDs, buf)
+	ret0, _ := ret[0].([]types.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupAccountsInto indicates an expected call of LookupAccountsInto.
+func (mr *MockClientOperationsMockRecorder) LookupAccountsInto(accountIDs, buf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupAccountsInto", reflect.TypeOf((*MockClientOperations)(nil).LookupAccountsInto), accountIDs, buf)
+}
+
+// LookupAccountsStream mocks base method.
+func (m *MockClientOperations) LookupAccountsStream(accountIDs iter.Seq[types.Uint128]) iter.Seq2[types.Account, error] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupAccountsStream", accountIDs)
+	ret0, _ := ret[0].(iter.Seq2[types.Account, error])
+	return ret0
+}
+
+// LookupAccountsStream indicates an expected call of LookupAccountsStream.
+func (mr *MockClientOperationsMockRecorder) LookupAccountsStream(accountIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupAccountsStream", reflect.TypeOf((*MockClientOperations)(nil).LookupAccountsStream), accountIDs)
+}
+
+// LookupTransfers mocks base method.
+func (m *MockClientOperations) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupTransfers", transferIDs)
+	ret0, _ := ret[0].([]types.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupTransfers indicates an expected call of LookupTransfers.
+func (mr *MockClientOperationsMockRecorder) LookupTransfers(transferIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupTransfers", reflect.TypeOf((*MockClientOperations)(nil).LookupTransfers), transferIDs)
+}
+
+// LookupTransfersInto mocks base method.
+func (m *MockClientOperations) LookupTransfersInto(transferIDs []types.Uint128, buf []types.Transfer) ([]types.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupTransfersInto", transferIDs, buf)
+	ret0, _ := ret[0].([]types.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupTransfersInto indicates an expected call of LookupTransfersInto.
+func (mr *MockClientOperationsMockRecorder) LookupTransfersInto(transferIDs, buf any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupTransfersInto", reflect.TypeOf((*MockClientOperations)(nil).LookupTransfersInto), transferIDs, buf)
+}
+
+// PostPending mocks base method.
+func (m *MockClientOperations) PostPending(pendingID, amount types.Uint128) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostPending", pendingID, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostPending indicates an expected call of PostPending.
+func (mr *MockClientOperationsMockRecorder) PostPending(pendingID, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostPending", reflect.TypeOf((*MockClientOperations)(nil).PostPending), pendingID, amount)
+}
+
+// StreamChanges mocks base method.
+func (m *MockClientOperations) StreamChanges(ctx context.Context, fromTimestamp uint64, accountIDs []types.Uint128) <-chan tigerbeetle_go.ChangeEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamChanges", ctx, fromTimestamp, accountIDs)
+	ret0, _ := ret[0].(<-chan tigerbeetle_go.ChangeEvent)
+	return ret0
+}
+
+// StreamChanges indicates an expected call of StreamChanges.
+func (mr *MockClientOperationsMockRecorder) StreamChanges(ctx, fromTimestamp, accountIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamChanges", reflect.TypeOf((*MockClientOperations)(nil).StreamChanges), ctx, fromTimestamp, accountIDs)
+}
+
+// SubmitRaw mocks base method.
+func (m *MockClientOperations) SubmitRaw(op types.Operation, body []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitRaw", op, body)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitRaw indicates an expected call of SubmitRaw.
+func (mr *MockClientOperationsMockRecorder) SubmitRaw(op, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitRaw", reflect.TypeOf((*MockClientOperations)(nil).SubmitRaw), op, body)
+}
+
+// TailTransfers mocks base method.
+func (m *MockClientOperations) TailTransfers(ctx context.Context, accountID types.Uint128, sinceTimestamp uint64) <-chan tigerbeetle_go.TailEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TailTransfers", ctx, accountID, sinceTimestamp)
+	ret0, _ := ret[0].(<-chan tigerbeetle_go.TailEvent)
+	return ret0
+}
+
+// TailTransfers indicates an expected call of TailTransfers.
+func (mr *MockClientOperationsMockRecorder) TailTransfers(ctx, accountID, sinceTimestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TailTransfers", reflect.TypeOf((*MockClientOperations)(nil).TailTransfers), ctx, accountID, sinceTimestamp)
+}
+
+// TryCreateAccounts mocks base method.
+func (m *MockClientOperations) TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryCreateAccounts", accounts)
+	ret0, _ := ret[0].([]types.AccountEventResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TryCreateAccounts indicates an expected call of TryCreateAccounts.
+func (mr *MockClientOperationsMockRecorder) TryCreateAccounts(accounts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryCreateAccounts", reflect.TypeOf((*MockClientOperations)(nil).TryCreateAccounts), accounts)
+}
+
+// TryCreateTransfers mocks base method.
+func (m *MockClientOperations) TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryCreateTransfers", transfers)
+	ret0, _ := ret[0].([]types.TransferEventResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TryCreateTransfers indicates an expected call of TryCreateTransfers.
+func (mr *MockClientOperationsMockRecorder) TryCreateTransfers(transfers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryCreateTransfers", reflect.TypeOf((*MockClientOperations)(nil).TryCreateTransfers), transfers)
+}
+
+// VoidPending mocks base method.
+func (m *MockClientOperations) VoidPending(pendingID types.Uint128) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidPending", pendingID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VoidPending indicates an expected call of VoidPending.
+func (mr *MockClientOperationsMockRecorder) VoidPending(pendingID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidPending", reflect.TypeOf((*MockClientOperations)(nil).VoidPending), pendingID)
+}
+
+// WatchBalance mocks base method.
+func (m *MockClientOperations) WatchBalance(ctx context.Context, accountID types.Uint128, options tigerbeetle_go.WatchBalanceOptions) <-chan tigerbeetle_go.BalanceSnapshot {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchBalance", ctx, accountID, options)
+	ret0, _ := ret[0].(<-chan tigerbeetle_go.BalanceSnapshot)
+	return ret0
+}
+
+// WatchBalance indicates an expected call of WatchBalance.
+func (mr *MockClientOperationsMockRecorder) WatchBalance(ctx, accountID, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchBalance", reflect.TypeOf((*MockClientOperations)(nil).WatchBalance), ctx, accountID, options)
+}
+
+// MockClientLifecycle is a mock of ClientLifecycle interface.
+type MockClientLifecycle struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientLifecycleMockRecorder
+	isgomock struct{}
+}
+
+// MockClientLifecycleMockRecorder is the mock recorder for MockClientLifecycle.
+type MockClientLifecycleMockRecorder struct {
+	mock *MockClientLifecycle
+}
+
+// NewMockClientLifecycle creates a new mock instance.
+func NewMockClientLifecycle(ctrl *gomock.Controller) *MockClientLifecycle {
+	mock := &MockClientLifecycle{ctrl: ctrl}
+	mock.recorder = &MockClientLifecycleMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientLifecycle) EXPECT() *MockClientLifecycleMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockClientLifecycle) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockClientLifecycleMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClientLifecycle)(nil).Close))
+}
+
+// CloseContext mocks base method.
+func (m *MockClientLifecycle) CloseContext(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseContext", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseContext indicates an expected call of CloseContext.
+func (mr *MockClientLifecycleMockRecorder) CloseContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseContext", reflect.TypeOf((*MockClientLifecycle)(nil).CloseContext), ctx)
+}
+
+// Done mocks base method.
+func (m *MockClientLifecycle) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done.
+func (mr *MockClientLifecycleMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockClientLifecycle)(nil).Done))
+}
+
+// MessageSizeMax mocks base method.
+func (m *MockClientLifecycle) MessageSizeMax() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MessageSizeMax")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MessageSizeMax indicates an expected call of MessageSizeMax.
+func (mr *MockClientLifecycleMockRecorder) MessageSizeMax() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageSizeMax", reflect.TypeOf((*MockClientLifecycle)(nil).MessageSizeMax))
+}
+
+// Nop mocks base method.
+func (m *MockClientLifecycle) Nop() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Nop")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Nop indicates an expected call of Nop.
+func (mr *MockClientLifecycleMockRecorder) Nop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nop", reflect.TypeOf((*MockClientLifecycle)(nil).Nop))
+}
+
+// Pause mocks base method.
+func (m *MockClientLifecycle) Pause(ctx context.Context, mode tigerbeetle_go.PauseMode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", ctx, mode)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockClientLifecycleMockRecorder) Pause(ctx, mode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockClientLifecycle)(nil).Pause), ctx, mode)
+}
+
+// Ping mocks base method.
+func (m *MockClientLifecycle) Ping(ctx context.Context) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockClientLifecycleMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockClientLifecycle)(nil).Ping), ctx)
+}
+
+// Resume mocks base method.
+func (m *MockClientLifecycle) Resume() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Resume")
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockClientLifecycleMockRecorder) Resume() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockClientLifecycle)(nil).Resume))
+}
+
+// State mocks base method.
+func (m *MockClientLifecycle) State() tigerbeetle_go.ConnectionState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "State")
+	ret0, _ := ret[0].(tigerbeetle_go.ConnectionState)
+	return ret0
+}
+
+// State indicates an expected call of State.
+func (mr *MockClientLifecycleMockRecorder) State() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockClientLifecycle)(nil).State))
+}
+
+// Stats mocks base method.
+func (m *MockClientLifecycle) Stats() tigerbeetle_go.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(tigerbeetle_go.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockClientLifecycleMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockClientLifecycle)(nil).Stats))
+}
+
+// UpdateAddresses mocks base method.
+func (m *MockClientLifecycle) UpdateAddresses(addresses []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAddresses", addresses)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAddresses indicates an expected call of UpdateAddresses.
+func (mr *MockClientLifecycleMockRecorder) UpdateAddresses(addresses any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAddresses", reflect.TypeOf((*MockClientLifecycle)(nil).UpdateAddresses), addresses)
+}
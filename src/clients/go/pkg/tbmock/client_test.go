@@ -0,0 +1,32 @@
+package tbmock
+
+import (
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+var (
+	_ tigerbeetle_go.Client           = (*MockClient)(nil)
+	_ tigerbeetle_go.ClientOperations = (*MockClientOperations)(nil)
+	_ tigerbeetle_go.ClientLifecycle  = (*MockClientLifecycle)(nil)
+)
+
+func TestMockClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := NewMockClient(ctrl)
+
+	exists := []types.TransferEventResult{{Index: 0, Result: types.TransferExists}}
+	client.EXPECT().CreateTransfers(gomock.Len(1)).Return(exists, nil)
+	client.EXPECT().Close()
+
+	var operations tigerbeetle_go.ClientOperations = client
+	results, err := operations.CreateTransfers([]types.Transfer{{ID: types.ToUint128(1)}})
+	if err != nil || len(results) != 1 || results[0].Result != types.TransferExists {
+		t.Fatalf("Expected the programmed results, got %v and %v", results, err)
+	}
+	client.Close()
+}
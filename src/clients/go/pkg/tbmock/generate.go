@@ -0,0 +1,11 @@
+// Package tbmock provides gomock mocks of the client interfaces, generated by mockgen, so that
+// code using a client can be tested without a cluster:
+//
+//	ctrl := gomock.NewController(t)
+//	client := tbmock.NewMockClient(ctrl)
+//	client.EXPECT().CreateTransfers(gomock.Len(1)).Return(nil, nil)
+//
+// It is a module of its own so that the client does not depend on gomock.
+package tbmock
+
+//go:generate mockgen -write_package_comment=false -destination client.go -package tbmock github.com/tigerbeetle/tigerbeetle-go Client,ClientOperations,ClientLifecycle
@@ -0,0 +1,10 @@
+module github.com/tigerbeetle/tigerbeetle-go/pkg/tbmock
+
+go 1.23.0
+
+require (
+	github.com/tigerbeetle/tigerbeetle-go v0.0.0
+	go.uber.org/mock v0.6.0
+)
+
+replace github.com/tigerbeetle/tigerbeetle-go => ../../
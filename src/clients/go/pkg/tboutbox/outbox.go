@@ -0,0 +1,280 @@
+// Package tboutbox solves the dual write of a service that records a payment in its own SQL
+// database and creates the transfer in TigerBeetle, with the outbox pattern:
+//
+//	outbox := tboutbox.New(tboutbox.Config{Dialect: tboutbox.Postgres})
+//
+//	tx, _ := db.BeginTx(ctx, nil)
+//	// ... update the service's own tables ...
+//	outbox.Enqueue(ctx, tx, transfer)
+//	tx.Commit()
+//
+//	go outbox.Relay(db, client).Run(ctx)
+//
+// Enqueue writes the transfers into the outbox table within the caller's transaction, so that they
+// are recorded if and only if the rest of it commits. A relay then drains the table into
+// CreateTransfers batches, deleting the rows once the cluster has replied. The ID of a transfer is
+// fixed when it is enqueued, so a batch that is relayed again after a crash reports the transfers
+// as already existing instead of creating them twice.
+//
+// Only one relay should drain a table at a time, which Config.IsLeader decides among several
+// instances of a service, for example by holding an advisory lock or a lease.
+package tboutbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Client is the part of the TigerBeetle client that a relay submits to.
+type Client interface {
+	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+}
+
+// Execer runs the inserts of Enqueue, as *sql.Tx does.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Dialect adapts the outbox to a database.
+type Dialect uint8
+
+const (
+	SQLite Dialect = iota
+	Postgres
+	MySQL
+)
+
+// placeholder returns the placeholder of parameter n, counting from 1.
+func (d Dialect) placeholder(n int) string {
+	if d == Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// Config describes an outbox. Zero fields take their defaults.
+type Config struct {
+	Dialect Dialect
+	// Table is the name of the outbox table. Defaults to "tb_outbox".
+	Table string
+	// BatchSize is the most transfers a relay submits per request. Defaults to the most that fit.
+	BatchSize int
+	// PollInterval is how long a relay waits after finding the outbox empty, or after finding it
+	// is not the leader. Defaults to 1s.
+	PollInterval time.Duration
+	// IsLeader is called by a relay before every batch, which it only relays if IsLeader returns
+	// true. Defaults to always relaying.
+	IsLeader func(ctx context.Context) (bool, error)
+	// OnRejected is called for every transfer that the cluster rejected, other than for already
+	// existing, before its row is deleted. If it fails, the batch is left in the outbox and the
+	// error is returned by the relay.
+	OnRejected func(ctx context.Context, transfer types.Transfer, result types.CreateTransferResult) error
+}
+
+// Outbox writes transfers to an outbox table and relays them to a cluster.
+type Outbox struct {
+	config Config
+}
+
+// New returns the outbox described by config.
+func New(config Config) *Outbox {
+	if config.Table == "" {
+		config.Table = "tb_outbox"
+	}
+	if batchMax := types.MaxBatchSize(types.OperationCreateTransfers); config.BatchSize <= 0 ||
+		config.BatchSize > batchMax {
+		config.BatchSize = batchMax
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	return &Outbox{config: config}
+}
+
+// CreateTable creates the outbox table if it doesn't exist yet. Rows are relayed in the order of
+// seq, and id is unique so that a transfer cannot be enqueued twice.
+func (o *Outbox) CreateTable(ctx context.Context, db Execer) error {
+	var columns string
+	switch o.config.Dialect {
+	case Postgres:
+		columns = "seq BIGSERIAL PRIMARY KEY, id NUMERIC(39, 0) NOT NULL UNIQUE, transfer BYTEA NOT NULL"
+	case MySQL:
+		columns = "seq BIGINT AUTO_INCREMENT PRIMARY KEY, id DECIMAL(39, 0) NOT NULL UNIQUE, " +
+			"transfer BINARY(128) NOT NULL"
+	default:
+		columns = "seq INTEGER PRIMARY KEY AUTOINCREMENT, id TEXT NOT NULL UNIQUE, transfer BLOB NOT NULL"
+	}
+	_, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+o.config.Table+" ("+columns+")")
+	return err
+}
+
+// Enqueue writes transfers to the outbox within tx, to be relayed once tx commits. Transfers
+// without an ID are given one with types.ID, which is then fixed. A transfer whose ID is already
+// in the outbox fails the insert.
+func (o *Outbox) Enqueue(ctx context.Context, tx Execer, transfers ...types.Transfer) error {
+	query := fmt.Sprintf("INSERT INTO %s (id, transfer) VALUES (%s, %s)",
+		o.config.Table, o.config.Dialect.placeholder(1), o.config.Dialect.placeholder(2))
+	for _, transfer := range transfers {
+		if transfer.ID == (types.Uint128{}) {
+			transfer.ID = types.ID()
+		}
+		encoded := types.EncodeTransfer(transfer)
+		if _, err := tx.ExecContext(ctx, query, transfer.ID, encoded[:]); err != nil {
+			return fmt.Errorf("tboutbox: enqueueing transfer %s: %w", transfer.ID, err)
+		}
+	}
+	return nil
+}
+
+// Relay returns a relay that drains the outbox in db into client.
+func (o *Outbox) Relay(db *sql.DB, client Client) *Relay {
+	return &Relay{outbox: o, db: db, client: client}
+}
+
+// Relay drains an outbox into a cluster.
+type Relay struct {
+	outbox *Outbox
+	db     *sql.DB
+	client Client
+}
+
+// Run relays transfers as they are enqueued, until ctx is done or relaying fails.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		relayed, err := r.relayBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if relayed == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(r.outbox.config.PollInterval):
+			}
+		}
+	}
+}
+
+// Drain relays the transfers in the outbox until it is empty, or until the relay is not the
+// leader, returning how many it relayed.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		relayed, err := r.relayBatch(ctx)
+		total += relayed
+		if err != nil || relayed == 0 {
+			return total, err
+		}
+	}
+}
+
+type row struct {
+	seq      int64
+	transfer types.Transfer
+}
+
+// relayBatch relays the oldest batch of the outbox, returning how many transfers it relayed.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	config := r.outbox.config
+	if config.IsLeader != nil {
+		leader, err := config.IsLeader(ctx)
+		if err != nil || !leader {
+			return 0, err
+		}
+	}
+
+	rows, err := r.read(ctx)
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	// Leave an open linked chain for the next batch, since the rest of it was not read yet.
+	count := len(rows)
+	if count == config.BatchSize {
+		for count > 0 && rows[count-1].transfer.TransferFlags().Linked {
+			count--
+		}
+		if count == 0 {
+			return 0, fmt.Errorf("tboutbox: linked chain is longer than the batch size %d", config.BatchSize)
+		}
+		rows = rows[:count]
+	}
+
+	transfers := make([]types.Transfer, len(rows))
+	for i, row := range rows {
+		transfers[i] = row.transfer
+	}
+	results, err := r.client.CreateTransfers(transfers)
+	if err != nil {
+		return 0, fmt.Errorf("tboutbox: relaying transfers: %w", err)
+	}
+	for _, result := range results {
+		if result.Result == types.TransferExists || config.OnRejected == nil {
+			continue
+		}
+		if err := config.OnRejected(ctx, transfers[result.Index], result.Result); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := r.delete(ctx, rows); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+func (r *Relay) read(ctx context.Context) ([]row, error) {
+	config := r.outbox.config
+	query := fmt.Sprintf("SELECT seq, transfer FROM %s ORDER BY seq LIMIT %d", config.Table, config.BatchSize)
+	result, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("tboutbox: reading the outbox: %w", err)
+	}
+	defer result.Close()
+
+	var rows []row
+	for result.Next() {
+		var seq int64
+		var encoded []byte
+		if err := result.Scan(&seq, &encoded); err != nil {
+			return nil, fmt.Errorf("tboutbox: reading the outbox: %w", err)
+		}
+		transfer, err := types.DecodeTransfer(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("tboutbox: row %d: %w", seq, err)
+		}
+		rows = append(rows, row{seq: seq, transfer: transfer})
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("tboutbox: reading the outbox: %w", err)
+	}
+	return rows, nil
+}
+
+// deleteChunk bounds the parameters of a delete statement, below the limits of every dialect.
+const deleteChunk = 500
+
+// delete removes the relayed rows by seq, rather than up to the last seq, since rows with a lower
+// seq may still commit after the read.
+func (r *Relay) delete(ctx context.Context, rows []row) error {
+	config := r.outbox.config
+	for start := 0; start < len(rows); start += deleteChunk {
+		chunk := rows[start:min(start+deleteChunk, len(rows))]
+		placeholders := make([]string, len(chunk))
+		args := make([]any, len(chunk))
+		for i, row := range chunk {
+			placeholders[i] = config.Dialect.placeholder(i + 1)
+			args[i] = row.seq
+		}
+		query := fmt.Sprintf("DELETE FROM %s WHERE seq IN (%s)", config.Table, strings.Join(placeholders, ", "))
+		if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("tboutbox: deleting relayed transfers: %w", err)
+		}
+	}
+	return nil
+}
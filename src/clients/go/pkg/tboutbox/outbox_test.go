@@ -0,0 +1,142 @@
+package tboutbox
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+	_ "modernc.org/sqlite"
+)
+
+// recordingClient creates every transfer, except those with an ID in rejected.
+type recordingClient struct {
+	batches  [][]types.Transfer
+	created  map[types.Uint128]bool
+	rejected map[types.Uint128]bool
+}
+
+func (c *recordingClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	c.batches = append(c.batches, transfers)
+	var results []types.TransferEventResult
+	for i, transfer := range transfers {
+		switch {
+		case c.rejected[transfer.ID]:
+			results = append(results, types.TransferEventResult{Index: uint32(i), Result: types.TransferExceedsCredits})
+		case c.created[transfer.ID]:
+			results = append(results, types.TransferEventResult{Index: uint32(i), Result: types.TransferExists})
+		default:
+			c.created[transfer.ID] = true
+		}
+	}
+	return results, nil
+}
+
+func openOutbox(t *testing.T, config Config) (*Outbox, *sql.DB) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: is a database of its own.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	outbox := New(config)
+	if err := outbox.CreateTable(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return outbox, db
+}
+
+func enqueue(t *testing.T, outbox *Outbox, db *sql.DB, commit bool, transfers ...types.Transfer) {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Enqueue(ctx, tx, transfers...); err != nil {
+		t.Fatal(err)
+	}
+	if commit {
+		err = tx.Commit()
+	} else {
+		err = tx.Rollback()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func transfer(id uint64) types.Transfer {
+	return types.Transfer{ID: types.ToUint128(id), Amount: types.ToUint128(id), Ledger: 1, Code: 1}
+}
+
+func TestRelay(t *testing.T) {
+	var rejected []types.Transfer
+	outbox, db := openOutbox(t, Config{
+		BatchSize: 2,
+		OnRejected: func(ctx context.Context, transfer types.Transfer, result types.CreateTransferResult) error {
+			assert.Equal(t, types.TransferExceedsCredits, result)
+			rejected = append(rejected, transfer)
+			return nil
+		},
+	})
+	client := &recordingClient{
+		created:  map[types.Uint128]bool{types.ToUint128(2): true},
+		rejected: map[types.Uint128]bool{types.ToUint128(3): true},
+	}
+
+	enqueue(t, outbox, db, true, transfer(1), transfer(2))
+	enqueue(t, outbox, db, false, transfer(9))
+	enqueue(t, outbox, db, true, transfer(3))
+
+	relayed, err := outbox.Relay(db, client).Drain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 3, relayed)
+	assert.Equal(t, [][]types.Transfer{{transfer(1), transfer(2)}, {transfer(3)}}, client.batches)
+	assert.Equal(t, []types.Transfer{transfer(3)}, rejected)
+
+	var remaining int
+	if err := db.QueryRow("SELECT COUNT(*) FROM tb_outbox").Scan(&remaining); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, remaining)
+}
+
+func TestRelayLinkedChain(t *testing.T) {
+	outbox, db := openOutbox(t, Config{BatchSize: 2})
+	client := &recordingClient{created: map[types.Uint128]bool{}}
+
+	linked := transfer(2)
+	linked.Flags = types.TransferFlags{Linked: true}.ToUint16()
+	enqueue(t, outbox, db, true, transfer(1), linked, transfer(3))
+
+	if _, err := outbox.Relay(db, client).Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, [][]types.Transfer{{transfer(1)}, {linked, transfer(3)}}, client.batches)
+}
+
+func TestRelayLeader(t *testing.T) {
+	leader := false
+	outbox, db := openOutbox(t, Config{
+		IsLeader: func(ctx context.Context) (bool, error) { return leader, nil },
+	})
+	client := &recordingClient{created: map[types.Uint128]bool{}}
+
+	// Transfers without an ID keep the one they are given when enqueued.
+	enqueue(t, outbox, db, true, types.Transfer{Ledger: 1})
+	relay := outbox.Relay(db, client)
+	relayed, err := relay.Drain(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, relayed)
+
+	leader = true
+	relayed, err = relay.Drain(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, relayed)
+	assert.True(t, client.batches[0][0].ID != types.Uint128{})
+}
@@ -0,0 +1,312 @@
+// Package tbpipeline submits a stream of transfers in pipelined batches: transfers are grouped
+// into the largest batches available, without splitting a linked chain, and several batches are
+// kept in flight at once, while the result of every transfer is delivered in the order of the
+// stream.
+//
+// A Pipeline replaces the goroutine and semaphore per request that bulk writers otherwise roll
+// by hand, and its Metrics show whether the cluster or the consumer of the results is holding
+// the stream back.
+package tbpipeline
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const (
+	// inFlightMaxDefault is how many batches a pipeline keeps in flight by default.
+	inFlightMaxDefault = 4
+)
+
+// ErrChainTooLong is returned when a linked chain has more transfers than fit in a batch.
+var ErrChainTooLong = errors.New("tbpipeline: linked chain is longer than the batch size")
+
+// Client is the part of the TigerBeetle client that a pipeline submits to.
+type Client interface {
+	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+}
+
+// Config configures a Pipeline. Zero fields take their defaults.
+type Config struct {
+	// InFlightMax is how many batches are submitted at once. It should not exceed the
+	// concurrencyMax of the client. Defaults to 4.
+	InFlightMax int
+	// BatchSizeMax caps the transfers per batch. Defaults to the most that fit in a request.
+	BatchSizeMax int
+}
+
+// Result is the outcome of submitting a transfer. Err is the error of the whole batch, in which
+// case the transfer may or may not have been created, and Result is then TransferOK.
+type Result struct {
+	Transfer types.Transfer
+	Result   types.CreateTransferResult
+	Err      error
+}
+
+// Metrics describes the throughput of a pipeline and what held it back.
+type Metrics struct {
+	// Transfers and Batches count those submitted and completed, including the failed ones.
+	Transfers uint64
+	Batches   uint64
+	// FailedBatches counts the batches that failed with an error.
+	FailedBatches uint64
+	// InFlight is how many batches are in flight at the moment.
+	InFlight int
+	// Backpressure is the total time that batches waited before being submitted, for a batch
+	// in flight to complete or for the results of earlier batches to be consumed.
+	Backpressure time.Duration
+	// Elapsed is the time since the pipeline first ran.
+	Elapsed time.Duration
+}
+
+// Throughput returns the transfers completed per second since the pipeline first ran.
+func (m Metrics) Throughput() float64 {
+	if m.Elapsed <= 0 {
+		return 0
+	}
+	return float64(m.Transfers) / m.Elapsed.Seconds()
+}
+
+// Pipeline submits streams of transfers to a client. Its Metrics add up over every run, and
+// concurrent runs share its batches in flight.
+type Pipeline struct {
+	client Client
+	config Config
+	slots  chan struct{}
+
+	// start is the time of the first run, in nanoseconds since the Unix epoch.
+	start         atomic.Int64
+	transfers     atomic.Uint64
+	batches       atomic.Uint64
+	failedBatches atomic.Uint64
+	inFlight      atomic.Int64
+	backpressure  atomic.Int64
+}
+
+// New returns a pipeline submitting to client.
+func New(client Client, config Config) *Pipeline {
+	if config.InFlightMax <= 0 {
+		config.InFlightMax = inFlightMaxDefault
+	}
+	if batchMax := types.MaxBatchSize(types.OperationCreateTransfers); config.BatchSizeMax <= 0 ||
+		config.BatchSizeMax > batchMax {
+		config.BatchSizeMax = batchMax
+	}
+	return &Pipeline{
+		client: client,
+		config: config,
+		slots:  make(chan struct{}, config.InFlightMax),
+	}
+}
+
+// Metrics returns a snapshot of the metrics of the pipeline.
+func (p *Pipeline) Metrics() Metrics {
+	metrics := Metrics{
+		Transfers:     p.transfers.Load(),
+		Batches:       p.batches.Load(),
+		FailedBatches: p.failedBatches.Load(),
+		InFlight:      int(p.inFlight.Load()),
+		Backpressure:  time.Duration(p.backpressure.Load()),
+	}
+	if start := p.start.Load(); start != 0 {
+		metrics.Elapsed = time.Since(time.Unix(0, start))
+	}
+	return metrics
+}
+
+// Run submits the transfers and sends the result of each to results, in order, closing results
+// once done. It stops early if ctx is done, returning ctx.Err() after the batches in flight have
+// completed, without delivering their results.
+func (p *Pipeline) Run(
+	ctx context.Context,
+	transfers iter.Seq[types.Transfer],
+	results chan<- Result,
+) error {
+	in := make(chan types.Transfer, p.config.BatchSizeMax)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		defer close(in)
+		for transfer := range transfers {
+			select {
+			case in <- transfer:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	// Don't return while transfers may still be pulled from.
+	defer func() {
+		close(stop)
+		<-stopped
+	}()
+
+	return p.RunChan(ctx, in, results)
+}
+
+// RunChan is Run for transfers received from in until it is closed. A batch is submitted with
+// the transfers available as soon as in has no more ready, rather than waiting for it to fill.
+func (p *Pipeline) RunChan(
+	ctx context.Context,
+	in <-chan types.Transfer,
+	results chan<- Result,
+) error {
+	defer close(results)
+	p.start.CompareAndSwap(0, time.Now().UnixNano())
+
+	var inFlight sync.WaitGroup
+	ordered := make(chan *batch, p.config.InFlightMax)
+	delivered := make(chan error, 1)
+	go func() { delivered <- p.deliver(ctx, ordered, results) }()
+
+	err := p.form(ctx, in, ordered, &inFlight)
+	close(ordered)
+	deliverErr := <-delivered
+	inFlight.Wait()
+	if err != nil {
+		return err
+	}
+	return deliverErr
+}
+
+type batch struct {
+	transfers []types.Transfer
+	results   []types.TransferEventResult
+	err       error
+	done      chan struct{}
+}
+
+// form groups the transfers received from in into batches and submits them.
+func (p *Pipeline) form(
+	ctx context.Context,
+	in <-chan types.Transfer,
+	ordered chan<- *batch,
+	inFlight *sync.WaitGroup,
+) error {
+	var transfers []types.Transfer
+	open := true
+	for open {
+		for len(transfers) < p.config.BatchSizeMax {
+			// Wait for the first transfer of a batch, and for the rest of an open chain.
+			wait := len(transfers) == 0 || linked(transfers[len(transfers)-1])
+			var transfer types.Transfer
+			received := false
+			if wait {
+				select {
+				case transfer, open = <-in:
+					received = open
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			} else {
+				select {
+				case transfer, open = <-in:
+					received = open
+				default:
+				}
+			}
+			if !received {
+				break
+			}
+			transfers = append(transfers, transfer)
+		}
+		if len(transfers) == 0 {
+			break
+		}
+
+		// Cut a full batch after its last complete linked chain, leaving the open one for the
+		// next batch. At the end of the stream, an open chain is submitted for the cluster to
+		// reject.
+		count := len(transfers)
+		if open {
+			for count > 0 && linked(transfers[count-1]) {
+				count--
+			}
+			if count == 0 {
+				return ErrChainTooLong
+			}
+		}
+
+		if err := p.submit(ctx, transfers[:count], ordered, inFlight); err != nil {
+			return err
+		}
+		transfers = append([]types.Transfer(nil), transfers[count:]...)
+	}
+	return nil
+}
+
+// submit submits the transfers once a batch in flight is available.
+func (p *Pipeline) submit(
+	ctx context.Context,
+	transfers []types.Transfer,
+	ordered chan<- *batch,
+	inFlight *sync.WaitGroup,
+) error {
+	waiting := time.Now()
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	b := &batch{transfers: transfers, done: make(chan struct{})}
+	select {
+	case ordered <- b:
+	case <-ctx.Done():
+		<-p.slots
+		return ctx.Err()
+	}
+	p.backpressure.Add(int64(time.Since(waiting)))
+
+	p.inFlight.Add(1)
+	inFlight.Add(1)
+	go func() {
+		defer inFlight.Done()
+		b.results, b.err = p.client.CreateTransfers(b.transfers)
+
+		p.inFlight.Add(-1)
+		p.transfers.Add(uint64(len(b.transfers)))
+		p.batches.Add(1)
+		if b.err != nil {
+			p.failedBatches.Add(1)
+		}
+		<-p.slots
+		close(b.done)
+	}()
+	return nil
+}
+
+// deliver sends the results of the batches in the order they were submitted.
+func (p *Pipeline) deliver(ctx context.Context, ordered <-chan *batch, results chan<- Result) error {
+	for b := range ordered {
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		failed := make(map[uint32]types.CreateTransferResult, len(b.results))
+		for _, result := range b.results {
+			failed[result.Index] = result.Result
+		}
+		for i, transfer := range b.transfers {
+			result := Result{Transfer: transfer, Result: failed[uint32(i)], Err: b.err}
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+func linked(transfer types.Transfer) bool {
+	return transfer.TransferFlags().Linked
+}
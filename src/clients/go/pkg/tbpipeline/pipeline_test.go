@@ -0,0 +1,194 @@
+package tbpipeline
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbtest"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// slowClient rejects the transfers with an odd ID, taking longer for the earlier batches so
+// that they complete out of order.
+type slowClient struct {
+	mutex       sync.Mutex
+	batches     [][]uint64
+	inFlight    atomic.Int32
+	inFlightMax atomic.Int32
+}
+
+func (c *slowClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	inFlight := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		latest := c.inFlightMax.Load()
+		if inFlight <= latest || c.inFlightMax.CompareAndSwap(latest, inFlight) {
+			break
+		}
+	}
+
+	c.mutex.Lock()
+	var ids []uint64
+	for _, transfer := range transfers {
+		ids = append(ids, id(transfer))
+	}
+	c.batches = append(c.batches, ids)
+	delay := time.Duration(max(0, 8-len(c.batches))) * time.Millisecond
+	c.mutex.Unlock()
+	time.Sleep(delay)
+
+	var results []types.TransferEventResult
+	for i, transfer := range transfers {
+		if id(transfer)%2 == 1 {
+			results = append(results, types.TransferEventResult{
+				Index:  uint32(i),
+				Result: types.TransferExceedsCredits,
+			})
+		}
+	}
+	return results, nil
+}
+
+func id(transfer types.Transfer) uint64 {
+	bytes := transfer.ID.Bytes()
+	return uint64(bytes[0]) | uint64(bytes[1])<<8
+}
+
+func transfers(count int, linked ...int) func(yield func(types.Transfer) bool) {
+	return func(yield func(types.Transfer) bool) {
+		for i := 1; i <= count; i++ {
+			transfer := types.Transfer{ID: types.ToUint128(uint64(i))}
+			if slices.Contains(linked, i) {
+				transfer.Flags = types.TransferFlags{Linked: true}.ToUint16()
+			}
+			if !yield(transfer) {
+				return
+			}
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	client := &slowClient{}
+	pipeline := New(client, Config{InFlightMax: 3, BatchSizeMax: 4})
+
+	results := make(chan Result)
+	done := make(chan error, 1)
+	// Transfers 3 to 5 and 11 to 12 are linked chains, which batches don't split.
+	go func() { done <- pipeline.Run(context.Background(), transfers(30, 3, 4, 11), results) }()
+
+	var ids []uint64
+	for result := range results {
+		ids = append(ids, id(result.Transfer))
+		assert.Equal(t, nil, result.Err)
+		if id(result.Transfer)%2 == 1 {
+			assert.Equal(t, types.TransferExceedsCredits, result.Result)
+		} else {
+			assert.Equal(t, types.TransferOK, result.Result)
+		}
+	}
+	assert.Equal(t, nil, <-done)
+	assert.Equal(t, 30, len(ids))
+	assert.True(t, slices.IsSorted(ids))
+
+	for _, batch := range client.batches {
+		assert.True(t, len(batch) <= 4)
+		assert.True(t, !slices.Contains(batch, 4) || slices.Contains(batch, 3) && slices.Contains(batch, 5))
+		assert.True(t, !slices.Contains(batch, 11) || slices.Contains(batch, 12))
+	}
+	assert.True(t, client.inFlightMax.Load() > 1)
+	assert.True(t, client.inFlightMax.Load() <= 3)
+
+	metrics := pipeline.Metrics()
+	assert.Equal(t, uint64(30), metrics.Transfers)
+	assert.Equal(t, uint64(len(client.batches)), metrics.Batches)
+	assert.Equal(t, 0, metrics.InFlight)
+	assert.True(t, metrics.Throughput() > 0)
+}
+
+func TestPipelineChan(t *testing.T) {
+	client := &slowClient{}
+	pipeline := New(client, Config{})
+
+	// A batch is submitted with the transfers ready, without waiting for it to fill.
+	in := make(chan types.Transfer, 2)
+	in <- types.Transfer{ID: types.ToUint128(2)}
+	in <- types.Transfer{ID: types.ToUint128(4)}
+	results := make(chan Result, 2)
+	done := make(chan error, 1)
+	go func() { done <- pipeline.RunChan(context.Background(), in, results) }()
+	assert.Equal(t, types.ToUint128(2), (<-results).Transfer.ID)
+	assert.Equal(t, types.ToUint128(4), (<-results).Transfer.ID)
+	close(in)
+	assert.Equal(t, nil, <-done)
+	assert.Equal(t, [][]uint64{{2, 4}}, client.batches)
+
+	// A linked chain longer than a batch.
+	pipeline = New(client, Config{BatchSizeMax: 2})
+	results = make(chan Result, 8)
+	err := pipeline.Run(context.Background(), transfers(4, 1, 2, 3), results)
+	assert.Equal(t, ErrChainTooLong, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = make(chan Result)
+	err = New(client, Config{}).RunChan(ctx, make(chan types.Transfer), results)
+	assert.Equal(t, context.Canceled, err)
+	_, open := <-results
+	assert.True(t, !open)
+}
+
+func TestPipelineClient(t *testing.T) {
+	const transfersMax = 20_000
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 4,
+		tigerbeetle_go.WithTransport(tbtest.NewLedger(tbtest.NewClock(time.Time{}))),
+	)
+	assert.Equal(t, nil, err)
+	defer client.Close()
+	accountA := types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1}
+	accountB := types.Account{ID: types.ToUint128(2), Ledger: 1, Code: 1}
+	_, err = client.CreateAccounts([]types.Account{accountA, accountB})
+	assert.Equal(t, nil, err)
+
+	// The stream spans several full batches, kept in flight at once.
+	stream := func(yield func(types.Transfer) bool) {
+		for i := range transfersMax {
+			transfer := types.Transfer{
+				ID:              types.ToUint128(uint64(i + 1)),
+				CreditAccountID: accountA.ID,
+				DebitAccountID:  accountB.ID,
+				Amount:          types.ToUint128(1),
+				Ledger:          1,
+				Code:            1,
+			}
+			if !yield(transfer) {
+				return
+			}
+		}
+	}
+	pipeline := New(client, Config{InFlightMax: 4})
+	results := make(chan Result, 1024)
+	done := make(chan error, 1)
+	go func() { done <- pipeline.Run(context.Background(), stream, results) }()
+	count := 0
+	for result := range results {
+		assert.Equal(t, nil, result.Err)
+		assert.Equal(t, types.TransferOK, result.Result)
+		count++
+	}
+	assert.Equal(t, nil, <-done)
+	assert.Equal(t, transfersMax, count)
+	assert.True(t, pipeline.Metrics().Batches > 2)
+
+	accounts, err := client.LookupAccounts([]types.Uint128{accountA.ID, accountB.ID})
+	assert.Equal(t, nil, err)
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, types.ToUint128(transfersMax), accounts[0].CreditsPosted)
+	assert.Equal(t, types.ToUint128(transfersMax), accounts[1].DebitsPosted)
+}
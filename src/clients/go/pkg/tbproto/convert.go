@@ -0,0 +1,177 @@
+package tbproto
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// ErrOverflow is returned when converting a message with a field too large for the field of its
+// type, such as a code or flags beyond 16 bits.
+type ErrOverflow struct {
+	Message string
+	Field   string
+	Value   uint32
+}
+
+func (e ErrOverflow) Error() string {
+	return fmt.Sprintf("tbproto: %s.%s %d does not fit in 16 bits", e.Message, e.Field, e.Value)
+}
+
+// FromUint128 returns the message of value.
+func FromUint128(value types.Uint128) *Uint128 {
+	bytes := value.Bytes()
+	return &Uint128{
+		Low:  binary.LittleEndian.Uint64(bytes[:8]),
+		High: binary.LittleEndian.Uint64(bytes[8:]),
+	}
+}
+
+// ToUint128 returns the value of the message, which is zero when nil.
+func ToUint128(value *Uint128) types.Uint128 {
+	var bytes [16]byte
+	binary.LittleEndian.PutUint64(bytes[:8], value.GetLow())
+	binary.LittleEndian.PutUint64(bytes[8:], value.GetHigh())
+	return types.BytesToUint128(bytes)
+}
+
+// FromAccount returns the message of account.
+func FromAccount(account types.Account) *Account {
+	return &Account{
+		Id:             FromUint128(account.ID),
+		DebitsPending:  FromUint128(account.DebitsPending),
+		DebitsPosted:   FromUint128(account.DebitsPosted),
+		CreditsPending: FromUint128(account.CreditsPending),
+		CreditsPosted:  FromUint128(account.CreditsPosted),
+		UserData_128:   FromUint128(account.UserData128),
+		UserData_64:    account.UserData64,
+		UserData_32:    account.UserData32,
+		Ledger:         account.Ledger,
+		Code:           uint32(account.Code),
+		Flags:          uint32(account.Flags),
+		Timestamp:      account.Timestamp,
+	}
+}
+
+// ToAccount returns the account of the message, or ErrOverflow if its code or flags exceed 16
+// bits. Fields left out of the message are zero.
+func ToAccount(account *Account) (types.Account, error) {
+	if err := check16("Account", "code", account.GetCode()); err != nil {
+		return types.Account{}, err
+	}
+	if err := check16("Account", "flags", account.GetFlags()); err != nil {
+		return types.Account{}, err
+	}
+	return types.Account{
+		ID:             ToUint128(account.GetId()),
+		DebitsPending:  ToUint128(account.GetDebitsPending()),
+		DebitsPosted:   ToUint128(account.GetDebitsPosted()),
+		CreditsPending: ToUint128(account.GetCreditsPending()),
+		CreditsPosted:  ToUint128(account.GetCreditsPosted()),
+		UserData128:    ToUint128(account.GetUserData_128()),
+		UserData64:     account.GetUserData_64(),
+		UserData32:     account.GetUserData_32(),
+		Ledger:         account.GetLedger(),
+		Code:           uint16(account.GetCode()),
+		Flags:          uint16(account.GetFlags()),
+		Timestamp:      account.GetTimestamp(),
+	}, nil
+}
+
+// FromTransfer returns the message of transfer.
+func FromTransfer(transfer types.Transfer) *Transfer {
+	return &Transfer{
+		Id:              FromUint128(transfer.ID),
+		DebitAccountId:  FromUint128(transfer.DebitAccountID),
+		CreditAccountId: FromUint128(transfer.CreditAccountID),
+		Amount:          FromUint128(transfer.Amount),
+		PendingId:       FromUint128(transfer.PendingID),
+		UserData_128:    FromUint128(transfer.UserData128),
+		UserData_64:     transfer.UserData64,
+		UserData_32:     transfer.UserData32,
+		Timeout:         transfer.Timeout,
+		Ledger:          transfer.Ledger,
+		Code:            uint32(transfer.Code),
+		Flags:           uint32(transfer.Flags),
+		Timestamp:       transfer.Timestamp,
+	}
+}
+
+// ToTransfer returns the transfer of the message, or ErrOverflow if its code or flags exceed 16
+// bits. Fields left out of the message are zero.
+func ToTransfer(transfer *Transfer) (types.Transfer, error) {
+	if err := check16("Transfer", "code", transfer.GetCode()); err != nil {
+		return types.Transfer{}, err
+	}
+	if err := check16("Transfer", "flags", transfer.GetFlags()); err != nil {
+		return types.Transfer{}, err
+	}
+	return types.Transfer{
+		ID:              ToUint128(transfer.GetId()),
+		DebitAccountID:  ToUint128(transfer.GetDebitAccountId()),
+		CreditAccountID: ToUint128(transfer.GetCreditAccountId()),
+		Amount:          ToUint128(transfer.GetAmount()),
+		PendingID:       ToUint128(transfer.GetPendingId()),
+		UserData128:     ToUint128(transfer.GetUserData_128()),
+		UserData64:      transfer.GetUserData_64(),
+		UserData32:      transfer.GetUserData_32(),
+		Timeout:         transfer.GetTimeout(),
+		Ledger:          transfer.GetLedger(),
+		Code:            uint16(transfer.GetCode()),
+		Flags:           uint16(transfer.GetFlags()),
+		Timestamp:       transfer.GetTimestamp(),
+	}, nil
+}
+
+// FromAccountFilter returns the message of filter.
+func FromAccountFilter(filter types.AccountFilter) *AccountFilter {
+	return &AccountFilter{
+		AccountId:    FromUint128(filter.AccountID),
+		TimestampMin: filter.TimestampMin,
+		TimestampMax: filter.TimestampMax,
+		Limit:        filter.Limit,
+		Flags:        filter.Flags,
+	}
+}
+
+// ToAccountFilter returns the filter of the message.
+func ToAccountFilter(filter *AccountFilter) types.AccountFilter {
+	return types.AccountFilter{
+		AccountID:    ToUint128(filter.GetAccountId()),
+		TimestampMin: filter.GetTimestampMin(),
+		TimestampMax: filter.GetTimestampMax(),
+		Limit:        filter.GetLimit(),
+		Flags:        filter.GetFlags(),
+	}
+}
+
+// FromAccountBalance returns the message of balance.
+func FromAccountBalance(balance types.AccountBalance) *AccountBalance {
+	return &AccountBalance{
+		DebitsPending:  FromUint128(balance.DebitsPending),
+		DebitsPosted:   FromUint128(balance.DebitsPosted),
+		CreditsPending: FromUint128(balance.CreditsPending),
+		CreditsPosted:  FromUint128(balance.CreditsPosted),
+		Timestamp:      balance.Timestamp,
+	}
+}
+
+// ToAccountBalance returns the balance of the message.
+func ToAccountBalance(balance *AccountBalance) types.AccountBalance {
+	return types.AccountBalance{
+		DebitsPending:  ToUint128(balance.GetDebitsPending()),
+		DebitsPosted:   ToUint128(balance.GetDebitsPosted()),
+		CreditsPending: ToUint128(balance.GetCreditsPending()),
+		CreditsPosted:  ToUint128(balance.GetCreditsPosted()),
+		Timestamp:      balance.GetTimestamp(),
+	}
+}
+
+func check16(message, field string, value uint32) error {
+	if value > math.MaxUint16 {
+		return ErrOverflow{Message: message, Field: field, Value: value}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package tbproto
+
+import (
+	"crypto/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// fieldNames returns the names of the fields of T that are encoded, as named in JSON, which are
+// the names of the fields of their message.
+func fieldNames[T any]() []string {
+	t := reflect.TypeFor[T]()
+	var names []string
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "-" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func messageFieldNames(message proto.Message) []string {
+	fields := message.ProtoReflect().Descriptor().Fields()
+	names := make([]string, fields.Len())
+	for i := range names {
+		names[i] = string(fields.Get(i).Name())
+	}
+	slices.Sort(names)
+	return names
+}
+
+// randomize sets the encoded fields of value at random, by way of their binary layout.
+func randomize[T any](value *T, decode func([]byte) (T, error), encode func(T) []byte) {
+	data := make([]byte, len(encode(*value)))
+	rand.Read(data)
+	*value, _ = decode(data)
+	// Reserved fields aren't carried, so they round-trip as zero.
+	if reserved := reflect.ValueOf(value).Elem().FieldByName("Reserved"); reserved.IsValid() {
+		reserved.SetZero()
+	}
+}
+
+// roundTrip converts value to its message and back, through the protobuf wire format.
+func roundTrip[T any, M proto.Message](t *testing.T, value T, from func(T) M, to func(M) (T, error)) T {
+	data, err := proto.Marshal(from(value))
+	assert.Equal(t, nil, err)
+	message := from(*new(T))
+	proto.Reset(message)
+	assert.Equal(t, nil, proto.Unmarshal(data, message))
+	converted, err := to(message)
+	assert.Equal(t, nil, err)
+	return converted
+}
+
+func withoutError[M, T any](convert func(M) T) func(M) (T, error) {
+	return func(message M) (T, error) { return convert(message), nil }
+}
+
+func TestConvert(t *testing.T) {
+	// Every field of the binary layout has a field in its message, so none is lost in between.
+	assert.Equal(t, fieldNames[types.Account](), messageFieldNames(&Account{}))
+	assert.Equal(t, fieldNames[types.Transfer](), messageFieldNames(&Transfer{}))
+	assert.Equal(t, fieldNames[types.AccountFilter](), messageFieldNames(&AccountFilter{}))
+	assert.Equal(t, fieldNames[types.AccountBalance](), messageFieldNames(&AccountBalance{}))
+	assert.Equal(t, protoreflect.FullName("tigerbeetle.v1.Account"), (&Account{}).ProtoReflect().Descriptor().FullName())
+
+	for range 100 {
+		var account types.Account
+		randomize(&account, types.DecodeAccount, func(account types.Account) []byte {
+			encoded := types.EncodeAccount(account)
+			return encoded[:]
+		})
+		converted := roundTrip(t, account, FromAccount, ToAccount)
+		assert.Equal(t, types.EncodeAccount(account), types.EncodeAccount(converted))
+
+		var transfer types.Transfer
+		randomize(&transfer, types.DecodeTransfer, func(transfer types.Transfer) []byte {
+			encoded := types.EncodeTransfer(transfer)
+			return encoded[:]
+		})
+		assert.Equal(t, transfer, roundTrip(t, transfer, FromTransfer, ToTransfer))
+	}
+
+	filter := types.AccountFilter{AccountID: types.ToUint128(7), TimestampMin: 1, TimestampMax: 2, Limit: 10, Flags: 5}
+	assert.Equal(t, filter, roundTrip(t, filter, FromAccountFilter, withoutError(ToAccountFilter)))
+	balance := types.AccountBalance{DebitsPosted: types.ToUint128(3), CreditsPending: types.ToUint128(4), Timestamp: 9}
+	assert.Equal(t, balance, roundTrip(t, balance, FromAccountBalance, withoutError(ToAccountBalance)))
+
+	// Messages left out are zero.
+	account, err := ToAccount(&Account{Ledger: 1})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, types.Account{Ledger: 1}, account)
+
+	_, err = ToAccount(&Account{Code: 1 << 16})
+	assert.Equal(t, ErrOverflow{Message: "Account", Field: "code", Value: 1 << 16}, err)
+	_, err = ToTransfer(&Transfer{Flags: 1 << 20})
+	assert.Equal(t, ErrOverflow{Message: "Transfer", Field: "flags", Value: 1 << 20}, err)
+}
@@ -0,0 +1,18 @@
+// Package tbproto is the protobuf definition of the TigerBeetle types, for gRPC services and
+// Kafka schemas to share one canonical definition, with converters to and from the types of the
+// client:
+//
+//	message := tbproto.FromTransfer(transfer)
+//	...
+//	transfer, err := tbproto.ToTransfer(message)
+//
+// The messages are defined in proto/tigerbeetle/v1/types.proto, which other .proto files import
+// as "tigerbeetle/v1/types.proto". After changing it, regenerate types.pb.go with protoc and
+// protoc-gen-go on the PATH:
+//
+//	go generate
+//
+// It is a module of its own so that the client does not depend on protobuf.
+package tbproto
+
+//go:generate protoc -I proto --go_out=. --go_opt=module=github.com/tigerbeetle/tigerbeetle-go/pkg/tbproto tigerbeetle/v1/types.proto
@@ -0,0 +1,10 @@
+module github.com/tigerbeetle/tigerbeetle-go/pkg/tbproto
+
+go 1.23.0
+
+require (
+	github.com/tigerbeetle/tigerbeetle-go v0.0.0
+	google.golang.org/protobuf v1.36.12
+)
+
+replace github.com/tigerbeetle/tigerbeetle-go => ../../
@@ -0,0 +1,631 @@
+// The TigerBeetle types, as the one definition that gRPC services and Kafka schemas share.
+//
+// The messages mirror the types of the Go client field for field, without their reserved
+// fields. 128-bit integers are split into their low and high 64 bits, and the 16-bit code and
+// flags are carried in 32-bit fields, which must not exceed 16 bits.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: tigerbeetle/v1/types.proto
+
+package tbproto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Uint128 struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Low           uint64                 `protobuf:"varint,1,opt,name=low,proto3" json:"low,omitempty"`
+	High          uint64                 `protobuf:"varint,2,opt,name=high,proto3" json:"high,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Uint128) Reset() {
+	*x = Uint128{}
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Uint128) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Uint128) ProtoMessage() {}
+
+func (x *Uint128) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Uint128.ProtoReflect.Descriptor instead.
+func (*Uint128) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_v1_types_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Uint128) GetLow() uint64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *Uint128) GetHigh() uint64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+type Account struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             *Uint128               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DebitsPending  *Uint128               `protobuf:"bytes,2,opt,name=debits_pending,json=debitsPending,proto3" json:"debits_pending,omitempty"`
+	DebitsPosted   *Uint128               `protobuf:"bytes,3,opt,name=debits_posted,json=debitsPosted,proto3" json:"debits_posted,omitempty"`
+	CreditsPending *Uint128               `protobuf:"bytes,4,opt,name=credits_pending,json=creditsPending,proto3" json:"credits_pending,omitempty"`
+	CreditsPosted  *Uint128               `protobuf:"bytes,5,opt,name=credits_posted,json=creditsPosted,proto3" json:"credits_posted,omitempty"`
+	UserData_128   *Uint128               `protobuf:"bytes,6,opt,name=user_data_128,json=userData128,proto3" json:"user_data_128,omitempty"`
+	UserData_64    uint64                 `protobuf:"varint,7,opt,name=user_data_64,json=userData64,proto3" json:"user_data_64,omitempty"`
+	UserData_32    uint32                 `protobuf:"varint,8,opt,name=user_data_32,json=userData32,proto3" json:"user_data_32,omitempty"`
+	Ledger         uint32                 `protobuf:"varint,9,opt,name=ledger,proto3" json:"ledger,omitempty"`
+	// Code and flags are 16-bit.
+	Code          uint32 `protobuf:"varint,10,opt,name=code,proto3" json:"code,omitempty"`
+	Flags         uint32 `protobuf:"varint,11,opt,name=flags,proto3" json:"flags,omitempty"`
+	Timestamp     uint64 `protobuf:"varint,12,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_v1_types_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Account) GetId() *Uint128 {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *Account) GetDebitsPending() *Uint128 {
+	if x != nil {
+		return x.DebitsPending
+	}
+	return nil
+}
+
+func (x *Account) GetDebitsPosted() *Uint128 {
+	if x != nil {
+		return x.DebitsPosted
+	}
+	return nil
+}
+
+func (x *Account) GetCreditsPending() *Uint128 {
+	if x != nil {
+		return x.CreditsPending
+	}
+	return nil
+}
+
+func (x *Account) GetCreditsPosted() *Uint128 {
+	if x != nil {
+		return x.CreditsPosted
+	}
+	return nil
+}
+
+func (x *Account) GetUserData_128() *Uint128 {
+	if x != nil {
+		return x.UserData_128
+	}
+	return nil
+}
+
+func (x *Account) GetUserData_64() uint64 {
+	if x != nil {
+		return x.UserData_64
+	}
+	return 0
+}
+
+func (x *Account) GetUserData_32() uint32 {
+	if x != nil {
+		return x.UserData_32
+	}
+	return 0
+}
+
+func (x *Account) GetLedger() uint32 {
+	if x != nil {
+		return x.Ledger
+	}
+	return 0
+}
+
+func (x *Account) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Account) GetFlags() uint32 {
+	if x != nil {
+		return x.Flags
+	}
+	return 0
+}
+
+func (x *Account) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type Transfer struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              *Uint128               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DebitAccountId  *Uint128               `protobuf:"bytes,2,opt,name=debit_account_id,json=debitAccountId,proto3" json:"debit_account_id,omitempty"`
+	CreditAccountId *Uint128               `protobuf:"bytes,3,opt,name=credit_account_id,json=creditAccountId,proto3" json:"credit_account_id,omitempty"`
+	Amount          *Uint128               `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	PendingId       *Uint128               `protobuf:"bytes,5,opt,name=pending_id,json=pendingId,proto3" json:"pending_id,omitempty"`
+	UserData_128    *Uint128               `protobuf:"bytes,6,opt,name=user_data_128,json=userData128,proto3" json:"user_data_128,omitempty"`
+	UserData_64     uint64                 `protobuf:"varint,7,opt,name=user_data_64,json=userData64,proto3" json:"user_data_64,omitempty"`
+	UserData_32     uint32                 `protobuf:"varint,8,opt,name=user_data_32,json=userData32,proto3" json:"user_data_32,omitempty"`
+	Timeout         uint32                 `protobuf:"varint,9,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Ledger          uint32                 `protobuf:"varint,10,opt,name=ledger,proto3" json:"ledger,omitempty"`
+	// Code and flags are 16-bit.
+	Code          uint32 `protobuf:"varint,11,opt,name=code,proto3" json:"code,omitempty"`
+	Flags         uint32 `protobuf:"varint,12,opt,name=flags,proto3" json:"flags,omitempty"`
+	Timestamp     uint64 `protobuf:"varint,13,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transfer) Reset() {
+	*x = Transfer{}
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transfer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transfer) ProtoMessage() {}
+
+func (x *Transfer) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transfer.ProtoReflect.Descriptor instead.
+func (*Transfer) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_v1_types_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Transfer) GetId() *Uint128 {
+	if x != nil {
+		return x.Id
+	}
+	return nil
+}
+
+func (x *Transfer) GetDebitAccountId() *Uint128 {
+	if x != nil {
+		return x.DebitAccountId
+	}
+	return nil
+}
+
+func (x *Transfer) GetCreditAccountId() *Uint128 {
+	if x != nil {
+		return x.CreditAccountId
+	}
+	return nil
+}
+
+func (x *Transfer) GetAmount() *Uint128 {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *Transfer) GetPendingId() *Uint128 {
+	if x != nil {
+		return x.PendingId
+	}
+	return nil
+}
+
+func (x *Transfer) GetUserData_128() *Uint128 {
+	if x != nil {
+		return x.UserData_128
+	}
+	return nil
+}
+
+func (x *Transfer) GetUserData_64() uint64 {
+	if x != nil {
+		return x.UserData_64
+	}
+	return 0
+}
+
+func (x *Transfer) GetUserData_32() uint32 {
+	if x != nil {
+		return x.UserData_32
+	}
+	return 0
+}
+
+func (x *Transfer) GetTimeout() uint32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *Transfer) GetLedger() uint32 {
+	if x != nil {
+		return x.Ledger
+	}
+	return 0
+}
+
+func (x *Transfer) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Transfer) GetFlags() uint32 {
+	if x != nil {
+		return x.Flags
+	}
+	return 0
+}
+
+func (x *Transfer) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type AccountFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     *Uint128               `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	TimestampMin  uint64                 `protobuf:"varint,2,opt,name=timestamp_min,json=timestampMin,proto3" json:"timestamp_min,omitempty"`
+	TimestampMax  uint64                 `protobuf:"varint,3,opt,name=timestamp_max,json=timestampMax,proto3" json:"timestamp_max,omitempty"`
+	Limit         uint32                 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Flags         uint32                 `protobuf:"varint,5,opt,name=flags,proto3" json:"flags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountFilter) Reset() {
+	*x = AccountFilter{}
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountFilter) ProtoMessage() {}
+
+func (x *AccountFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountFilter.ProtoReflect.Descriptor instead.
+func (*AccountFilter) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_v1_types_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AccountFilter) GetAccountId() *Uint128 {
+	if x != nil {
+		return x.AccountId
+	}
+	return nil
+}
+
+func (x *AccountFilter) GetTimestampMin() uint64 {
+	if x != nil {
+		return x.TimestampMin
+	}
+	return 0
+}
+
+func (x *AccountFilter) GetTimestampMax() uint64 {
+	if x != nil {
+		return x.TimestampMax
+	}
+	return 0
+}
+
+func (x *AccountFilter) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *AccountFilter) GetFlags() uint32 {
+	if x != nil {
+		return x.Flags
+	}
+	return 0
+}
+
+type AccountBalance struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	DebitsPending  *Uint128               `protobuf:"bytes,1,opt,name=debits_pending,json=debitsPending,proto3" json:"debits_pending,omitempty"`
+	DebitsPosted   *Uint128               `protobuf:"bytes,2,opt,name=debits_posted,json=debitsPosted,proto3" json:"debits_posted,omitempty"`
+	CreditsPending *Uint128               `protobuf:"bytes,3,opt,name=credits_pending,json=creditsPending,proto3" json:"credits_pending,omitempty"`
+	CreditsPosted  *Uint128               `protobuf:"bytes,4,opt,name=credits_posted,json=creditsPosted,proto3" json:"credits_posted,omitempty"`
+	Timestamp      uint64                 `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AccountBalance) Reset() {
+	*x = AccountBalance{}
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountBalance) ProtoMessage() {}
+
+func (x *AccountBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_tigerbeetle_v1_types_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountBalance.ProtoReflect.Descriptor instead.
+func (*AccountBalance) Descriptor() ([]byte, []int) {
+	return file_tigerbeetle_v1_types_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AccountBalance) GetDebitsPending() *Uint128 {
+	if x != nil {
+		return x.DebitsPending
+	}
+	return nil
+}
+
+func (x *AccountBalance) GetDebitsPosted() *Uint128 {
+	if x != nil {
+		return x.DebitsPosted
+	}
+	return nil
+}
+
+func (x *AccountBalance) GetCreditsPending() *Uint128 {
+	if x != nil {
+		return x.CreditsPending
+	}
+	return nil
+}
+
+func (x *AccountBalance) GetCreditsPosted() *Uint128 {
+	if x != nil {
+		return x.CreditsPosted
+	}
+	return nil
+}
+
+func (x *AccountBalance) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+var File_tigerbeetle_v1_types_proto protoreflect.FileDescriptor
+
+const file_tigerbeetle_v1_types_proto_rawDesc = "" +
+	"\n" +
+	"\x1atigerbeetle/v1/types.proto\x12\x0etigerbeetle.v1\"/\n" +
+	"\aUint128\x12\x10\n" +
+	"\x03low\x18\x01 \x01(\x04R\x03low\x12\x12\n" +
+	"\x04high\x18\x02 \x01(\x04R\x04high\"\x93\x04\n" +
+	"\aAccount\x12'\n" +
+	"\x02id\x18\x01 \x01(\v2\x17.tigerbeetle.v1.Uint128R\x02id\x12>\n" +
+	"\x0edebits_pending\x18\x02 \x01(\v2\x17.tigerbeetle.v1.Uint128R\rdebitsPending\x12<\n" +
+	"\rdebits_posted\x18\x03 \x01(\v2\x17.tigerbeetle.v1.Uint128R\fdebitsPosted\x12@\n" +
+	"\x0fcredits_pending\x18\x04 \x01(\v2\x17.tigerbeetle.v1.Uint128R\x0ecreditsPending\x12>\n" +
+	"\x0ecredits_posted\x18\x05 \x01(\v2\x17.tigerbeetle.v1.Uint128R\rcreditsPosted\x12;\n" +
+	"\ruser_data_128\x18\x06 \x01(\v2\x17.tigerbeetle.v1.Uint128R\vuserData128\x12 \n" +
+	"\fuser_data_64\x18\a \x01(\x04R\n" +
+	"userData64\x12 \n" +
+	"\fuser_data_32\x18\b \x01(\rR\n" +
+	"userData32\x12\x16\n" +
+	"\x06ledger\x18\t \x01(\rR\x06ledger\x12\x12\n" +
+	"\x04code\x18\n" +
+	" \x01(\rR\x04code\x12\x14\n" +
+	"\x05flags\x18\v \x01(\rR\x05flags\x12\x1c\n" +
+	"\ttimestamp\x18\f \x01(\x04R\ttimestamp\"\x9f\x04\n" +
+	"\bTransfer\x12'\n" +
+	"\x02id\x18\x01 \x01(\v2\x17.tigerbeetle.v1.Uint128R\x02id\x12A\n" +
+	"\x10debit_account_id\x18\x02 \x01(\v2\x17.tigerbeetle.v1.Uint128R\x0edebitAccountId\x12C\n" +
+	"\x11credit_account_id\x18\x03 \x01(\v2\x17.tigerbeetle.v1.Uint128R\x0fcreditAccountId\x12/\n" +
+	"\x06amount\x18\x04 \x01(\v2\x17.tigerbeetle.v1.Uint128R\x06amount\x126\n" +
+	"\n" +
+	"pending_id\x18\x05 \x01(\v2\x17.tigerbeetle.v1.Uint128R\tpendingId\x12;\n" +
+	"\ruser_data_128\x18\x06 \x01(\v2\x17.tigerbeetle.v1.Uint128R\vuserData128\x12 \n" +
+	"\fuser_data_64\x18\a \x01(\x04R\n" +
+	"userData64\x12 \n" +
+	"\fuser_data_32\x18\b \x01(\rR\n" +
+	"userData32\x12\x18\n" +
+	"\atimeout\x18\t \x01(\rR\atimeout\x12\x16\n" +
+	"\x06ledger\x18\n" +
+	" \x01(\rR\x06ledger\x12\x12\n" +
+	"\x04code\x18\v \x01(\rR\x04code\x12\x14\n" +
+	"\x05flags\x18\f \x01(\rR\x05flags\x12\x1c\n" +
+	"\ttimestamp\x18\r \x01(\x04R\ttimestamp\"\xbd\x01\n" +
+	"\rAccountFilter\x126\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\v2\x17.tigerbeetle.v1.Uint128R\taccountId\x12#\n" +
+	"\rtimestamp_min\x18\x02 \x01(\x04R\ftimestampMin\x12#\n" +
+	"\rtimestamp_max\x18\x03 \x01(\x04R\ftimestampMax\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\rR\x05limit\x12\x14\n" +
+	"\x05flags\x18\x05 \x01(\rR\x05flags\"\xae\x02\n" +
+	"\x0eAccountBalance\x12>\n" +
+	"\x0edebits_pending\x18\x01 \x01(\v2\x17.tigerbeetle.v1.Uint128R\rdebitsPending\x12<\n" +
+	"\rdebits_posted\x18\x02 \x01(\v2\x17.tigerbeetle.v1.Uint128R\fdebitsPosted\x12@\n" +
+	"\x0fcredits_pending\x18\x03 \x01(\v2\x17.tigerbeetle.v1.Uint128R\x0ecreditsPending\x12>\n" +
+	"\x0ecredits_posted\x18\x04 \x01(\v2\x17.tigerbeetle.v1.Uint128R\rcreditsPosted\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x04R\ttimestampB3Z1github.com/tigerbeetle/tigerbeetle-go/pkg/tbprotob\x06proto3"
+
+var (
+	file_tigerbeetle_v1_types_proto_rawDescOnce sync.Once
+	file_tigerbeetle_v1_types_proto_rawDescData []byte
+)
+
+func file_tigerbeetle_v1_types_proto_rawDescGZIP() []byte {
+	file_tigerbeetle_v1_types_proto_rawDescOnce.Do(func() {
+		file_tigerbeetle_v1_types_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tigerbeetle_v1_types_proto_rawDesc), len(file_tigerbeetle_v1_types_proto_rawDesc)))
+	})
+	return file_tigerbeetle_v1_types_proto_rawDescData
+}
+
+var file_tigerbeetle_v1_types_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_tigerbeetle_v1_types_proto_goTypes = []any{
+	(*Uint128)(nil),        // 0: tigerbeetle.v1.Uint128
+	(*Account)(nil),        // 1: tigerbeetle.v1.Account
+	(*Transfer)(nil),       // 2: tigerbeetle.v1.Transfer
+	(*AccountFilter)(nil),  // 3: tigerbeetle.v1.AccountFilter
+	(*AccountBalance)(nil), // 4: tigerbeetle.v1.AccountBalance
+}
+var file_tigerbeetle_v1_types_proto_depIdxs = []int32{
+	0,  // 0: tigerbeetle.v1.Account.id:type_name -> tigerbeetle.v1.Uint128
+	0,  // 1: tigerbeetle.v1.Account.debits_pending:type_name -> tigerbeetle.v1.Uint128
+	0,  // 2: tigerbeetle.v1.Account.debits_posted:type_name -> tigerbeetle.v1.Uint128
+	0,  // 3: tigerbeetle.v1.Account.credits_pending:type_name -> tigerbeetle.v1.Uint128
+	0,  // 4: tigerbeetle.v1.Account.credits_posted:type_name -> tigerbeetle.v1.Uint128
+	0,  // 5: tigerbeetle.v1.Account.user_data_128:type_name -> tigerbeetle.v1.Uint128
+	0,  // 6: tigerbeetle.v1.Transfer.id:type_name -> tigerbeetle.v1.Uint128
+	0,  // 7: tigerbeetle.v1.Transfer.debit_account_id:type_name -> tigerbeetle.v1.Uint128
+	0,  // 8: tigerbeetle.v1.Transfer.credit_account_id:type_name -> tigerbeetle.v1.Uint128
+	0,  // 9: tigerbeetle.v1.Transfer.amount:type_name -> tigerbeetle.v1.Uint128
+	0,  // 10: tigerbeetle.v1.Transfer.pending_id:type_name -> tigerbeetle.v1.Uint128
+	0,  // 11: tigerbeetle.v1.Transfer.user_data_128:type_name -> tigerbeetle.v1.Uint128
+	0,  // 12: tigerbeetle.v1.AccountFilter.account_id:type_name -> tigerbeetle.v1.Uint128
+	0,  // 13: tigerbeetle.v1.AccountBalance.debits_pending:type_name -> tigerbeetle.v1.Uint128
+	0,  // 14: tigerbeetle.v1.AccountBalance.debits_posted:type_name -> tigerbeetle.v1.Uint128
+	0,  // 15: tigerbeetle.v1.AccountBalance.credits_pending:type_name -> tigerbeetle.v1.Uint128
+	0,  // 16: tigerbeetle.v1.AccountBalance.credits_posted:type_name -> tigerbeetle.v1.Uint128
+	17, // [17:17] is the sub-list for method output_type
+	17, // [17:17] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_tigerbeetle_v1_types_proto_init() }
+func file_tigerbeetle_v1_types_proto_init() {
+	if File_tigerbeetle_v1_types_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tigerbeetle_v1_types_proto_rawDesc), len(file_tigerbeetle_v1_types_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_tigerbeetle_v1_types_proto_goTypes,
+		DependencyIndexes: file_tigerbeetle_v1_types_proto_depIdxs,
+		MessageInfos:      file_tigerbeetle_v1_types_proto_msgTypes,
+	}.Build()
+	File_tigerbeetle_v1_types_proto = out.File
+	file_tigerbeetle_v1_types_proto_goTypes = nil
+	file_tigerbeetle_v1_types_proto_depIdxs = nil
+}
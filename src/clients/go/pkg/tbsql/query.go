@@ -0,0 +1,562 @@
+package tbsql
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// ErrSyntax is returned for a query outside the SQL that the driver supports.
+type ErrSyntax struct {
+	// Offset is the byte offset in the query where it goes astray.
+	Offset int
+	Reason string
+}
+
+func (e ErrSyntax) Error() string {
+	return fmt.Sprintf("tbsql: %s at offset %d", e.Reason, e.Offset)
+}
+
+// ErrUnsupportedQuery is returned for a query that the cluster can't answer without scanning a
+// whole table.
+type ErrUnsupportedQuery struct {
+	Reason string
+}
+
+func (e ErrUnsupportedQuery) Error() string { return "tbsql: " + e.Reason }
+
+// columnKind is how a column is stored, and so returned.
+type columnKind uint8
+
+const (
+	// numeric columns hold the 128-bit integers and user_data_64, as decimal strings, since a
+	// driver.Value holds no integers above int64.
+	numeric columnKind = iota
+	// bigint columns hold the other integers, as int64.
+	bigint
+)
+
+type column struct {
+	name  string
+	kind  columnKind
+	field int
+}
+
+func (c *column) get(row reflect.Value) types.Uint128 {
+	switch value := row.Field(c.field).Interface().(type) {
+	case types.Uint128:
+		return value
+	case uint64:
+		return types.ToUint128(value)
+	case uint32:
+		return types.ToUint128(uint64(value))
+	case uint16:
+		return types.ToUint128(uint64(value))
+	}
+	panic("unreachable")
+}
+
+type table struct {
+	name    string
+	columns []*column
+}
+
+// columnsOf returns the columns of the fields of T, named as in JSON.
+func columnsOf[T any]() []*column {
+	t := reflect.TypeFor[T]()
+	var columns []*column
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		kind := bigint
+		// Timestamps are nanoseconds since the epoch, which fit in an int64 until 2262.
+		if t.Field(i).Type == reflect.TypeFor[types.Uint128]() ||
+			(t.Field(i).Type.Kind() == reflect.Uint64 && name != "timestamp") {
+			kind = numeric
+		}
+		columns = append(columns, &column{name: name, kind: kind, field: i})
+	}
+	return columns
+}
+
+var (
+	accountsTable  = &table{name: "accounts", columns: columnsOf[types.Account]()}
+	transfersTable = &table{name: "transfers", columns: columnsOf[types.Transfer]()}
+	tables         = []*table{accountsTable, transfersTable}
+)
+
+func (t *table) column(name string) *column {
+	for _, column := range t.columns {
+		if column.name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+// operand is an integer literal, or a placeholder when arg is its ordinal, from 1.
+type operand struct {
+	value types.Uint128
+	arg   int
+}
+
+type condition struct {
+	// columns are those that the condition compares, any of which may match, since account_id
+	// stands for both accounts of a transfer.
+	columns  []*column
+	operator string
+	operands []operand
+}
+
+// query is a parsed SELECT.
+type query struct {
+	table      *table
+	columns    []*column
+	conditions []condition
+	ordered    bool
+	descending bool
+	limit      uint64
+	hasLimit   bool
+	// inputs is the number of placeholders.
+	inputs int
+}
+
+type tokenKind uint8
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdentifier
+	tokenNumber
+	tokenString
+	tokenPlaceholder
+	tokenSymbol
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '_' || 'a' <= c|0x20 && c|0x20 <= 'z':
+			for i < len(sql) && (sql[i] == '_' || 'a' <= sql[i]|0x20 && sql[i]|0x20 <= 'z' ||
+				'0' <= sql[i] && sql[i] <= '9') {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdentifier, strings.ToLower(sql[start:i]), start})
+		case c == '"':
+			end := strings.IndexByte(sql[i+1:], '"')
+			if end < 0 {
+				return nil, ErrSyntax{Offset: start, Reason: "unterminated identifier"}
+			}
+			i += end + 2
+			tokens = append(tokens, token{tokenIdentifier, sql[start+1 : i-1], start})
+		case '0' <= c && c <= '9':
+			for i < len(sql) && '0' <= sql[i] && sql[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, sql[start:i], start})
+		case c == '\'':
+			end := strings.IndexByte(sql[i+1:], '\'')
+			if end < 0 {
+				return nil, ErrSyntax{Offset: start, Reason: "unterminated string"}
+			}
+			i += end + 2
+			tokens = append(tokens, token{tokenString, sql[start+1 : i-1], start})
+		case c == '?':
+			i++
+			tokens = append(tokens, token{tokenPlaceholder, "?", start})
+		case c == '$':
+			i++
+			for i < len(sql) && '0' <= sql[i] && sql[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, token{tokenPlaceholder, sql[start:i], start})
+		case strings.HasPrefix(sql[i:], "<=") || strings.HasPrefix(sql[i:], ">=") ||
+			strings.HasPrefix(sql[i:], "<>") || strings.HasPrefix(sql[i:], "!="):
+			i += 2
+			tokens = append(tokens, token{tokenSymbol, sql[start:i], start})
+		case strings.IndexByte("*,()=<>;", c) >= 0:
+			i++
+			tokens = append(tokens, token{tokenSymbol, sql[start:i], start})
+		default:
+			return nil, ErrSyntax{Offset: start, Reason: fmt.Sprintf("unexpected %q", c)}
+		}
+	}
+	return append(tokens, token{kind: tokenEnd, offset: len(sql)}), nil
+}
+
+// statements are the keywords that begin the statements that write.
+var statements = []string{"insert", "update", "delete", "merge", "create", "alter", "drop", "truncate"}
+
+type parser struct {
+	tokens []token
+	// placeholders is "?" or "$" once the query uses either, as the two don't mix.
+	placeholders string
+	inputs       int
+}
+
+func (p *parser) peek() token { return p.tokens[0] }
+
+func (p *parser) next() token {
+	token := p.tokens[0]
+	if token.kind != tokenEnd {
+		p.tokens = p.tokens[1:]
+	}
+	return token
+}
+
+// accept consumes the next token if it is the keyword or symbol text.
+func (p *parser) accept(text string) bool {
+	token := p.peek()
+	if (token.kind == tokenIdentifier || token.kind == tokenSymbol) && token.text == text {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected("expected " + strings.ToUpper(text))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(reason string) error {
+	return ErrSyntax{Offset: p.peek().offset, Reason: reason}
+}
+
+func (p *parser) identifier() (token, error) {
+	token := p.next()
+	if token.kind != tokenIdentifier {
+		return token, ErrSyntax{Offset: token.offset, Reason: "expected a name"}
+	}
+	return token, nil
+}
+
+// parse parses a query of the form:
+//
+//	SELECT * | column, ... FROM accounts | transfers
+//	    [WHERE condition AND ...] [ORDER BY timestamp [ASC | DESC]] [LIMIT n]
+func parse(sql string) (*query, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if !p.accept("select") {
+		if first := p.peek(); first.kind == tokenIdentifier && slices.Contains(statements, first.text) {
+			return nil, ErrReadOnly{}
+		}
+		return nil, p.unexpected("expected SELECT")
+	}
+
+	var names []token
+	if !p.accept("*") {
+		for {
+			name, err := p.identifier()
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if err := p.expect("from"); err != nil {
+		return nil, err
+	}
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	q := &query{}
+	for _, table := range tables {
+		if table.name == name.text {
+			q.table = table
+		}
+	}
+	if q.table == nil {
+		return nil, ErrSyntax{Offset: name.offset, Reason: fmt.Sprintf("unknown table %q", name.text)}
+	}
+	q.columns = q.table.columns
+	if names != nil {
+		q.columns = nil
+		for _, name := range names {
+			column := q.table.column(name.text)
+			if column == nil {
+				return nil, ErrSyntax{Offset: name.offset, Reason: fmt.Sprintf("unknown column %q", name.text)}
+			}
+			q.columns = append(q.columns, column)
+		}
+	}
+
+	if p.accept("where") {
+		for {
+			condition, err := p.condition(q.table)
+			if err != nil {
+				return nil, err
+			}
+			q.conditions = append(q.conditions, condition)
+			if !p.accept("and") {
+				break
+			}
+		}
+	}
+	if p.accept("order") {
+		if err := p.expect("by"); err != nil {
+			return nil, err
+		}
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		if name.text != "timestamp" {
+			return nil, ErrSyntax{Offset: name.offset, Reason: "only ORDER BY timestamp is supported"}
+		}
+		q.ordered = true
+		q.descending = p.accept("desc")
+		if !q.descending {
+			p.accept("asc")
+		}
+	}
+	if p.accept("limit") {
+		token := p.next()
+		if token.kind != tokenNumber {
+			return nil, ErrSyntax{Offset: token.offset, Reason: "expected an integer"}
+		}
+		if q.limit, err = strconv.ParseUint(token.text, 10, 64); err != nil {
+			return nil, ErrSyntax{Offset: token.offset, Reason: "LIMIT out of range"}
+		}
+		q.hasLimit = true
+	}
+	p.accept(";")
+	if p.peek().kind != tokenEnd {
+		return nil, p.unexpected("unexpected " + p.peek().text)
+	}
+	q.inputs = p.inputs
+	return q, nil
+}
+
+func (p *parser) condition(table *table) (condition, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return condition{}, err
+	}
+	var c condition
+	if named := table.column(name.text); named != nil {
+		c.columns = []*column{named}
+	} else if table == transfersTable && name.text == "account_id" {
+		c.columns = []*column{table.column("debit_account_id"), table.column("credit_account_id")}
+	} else {
+		return condition{}, ErrSyntax{Offset: name.offset, Reason: fmt.Sprintf("unknown column %q", name.text)}
+	}
+
+	operator := p.next()
+	switch {
+	case operator.kind == tokenSymbol && slices.Contains([]string{"=", "<>", "!=", "<", "<=", ">", ">="}, operator.text):
+		c.operator = operator.text
+		if c.operator == "!=" {
+			c.operator = "<>"
+		}
+		value, err := p.operand()
+		if err != nil {
+			return condition{}, err
+		}
+		c.operands = append(c.operands, value)
+	case operator.kind == tokenIdentifier && operator.text == "in":
+		c.operator = "in"
+		if err := p.expect("("); err != nil {
+			return condition{}, err
+		}
+		for {
+			value, err := p.operand()
+			if err != nil {
+				return condition{}, err
+			}
+			c.operands = append(c.operands, value)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return condition{}, err
+		}
+	default:
+		return condition{}, ErrSyntax{Offset: operator.offset, Reason: "expected a comparison"}
+	}
+	return c, nil
+}
+
+func (p *parser) operand() (operand, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenNumber, tokenString:
+		value, err := types.DecStringToUint128(token.text)
+		if err != nil {
+			return operand{}, ErrSyntax{Offset: token.offset, Reason: "expected an unsigned 128-bit integer"}
+		}
+		return operand{value: value}, nil
+	case tokenPlaceholder:
+		style := token.text[:1]
+		if p.placeholders != "" && p.placeholders != style {
+			return operand{}, ErrSyntax{Offset: token.offset, Reason: "? and $n placeholders can't be mixed"}
+		}
+		p.placeholders = style
+		if style == "?" {
+			p.inputs++
+			return operand{arg: p.inputs}, nil
+		}
+		arg, err := strconv.Atoi(token.text[1:])
+		if err != nil || arg == 0 {
+			return operand{}, ErrSyntax{Offset: token.offset, Reason: "expected $1 or above"}
+		}
+		p.inputs = max(p.inputs, arg)
+		return operand{arg: arg}, nil
+	}
+	return operand{}, ErrSyntax{Offset: token.offset, Reason: "expected an integer or a placeholder"}
+}
+
+// resolved is a condition with the values of its placeholders.
+type resolved struct {
+	columns  []*column
+	operator string
+	values   []types.Uint128
+}
+
+func (q *query) resolve(args []types.Uint128) []resolved {
+	conditions := make([]resolved, len(q.conditions))
+	for i, c := range q.conditions {
+		values := make([]types.Uint128, len(c.operands))
+		for j, operand := range c.operands {
+			values[j] = operand.value
+			if operand.arg != 0 {
+				values[j] = args[operand.arg-1]
+			}
+		}
+		conditions[i] = resolved{columns: c.columns, operator: c.operator, values: values}
+	}
+	return conditions
+}
+
+// matches returns whether the row meets every condition.
+func matches(conditions []resolved, row reflect.Value) bool {
+	for _, c := range conditions {
+		if !slices.ContainsFunc(c.columns, func(column *column) bool {
+			return c.compare(column.get(row))
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *resolved) compare(value types.Uint128) bool {
+	if c.operator == "in" {
+		return slices.Contains(c.values, value)
+	}
+	order := compare(value, c.values[0])
+	switch c.operator {
+	case "=":
+		return order == 0
+	case "<>":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	}
+	panic("unreachable")
+}
+
+func compare(a, b types.Uint128) int {
+	x, y := a.Bytes(), b.Bytes()
+	for i := len(x) - 1; i >= 0; i-- {
+		if order := cmp.Compare(x[i], y[i]); order != 0 {
+			return order
+		}
+	}
+	return 0
+}
+
+// keys returns the values that the column must equal for a row to match, if a condition
+// restricts it to a set, whether alone or as the only column of the condition.
+func keys(conditions []resolved, column *column) ([]types.Uint128, bool) {
+	for _, c := range conditions {
+		if len(c.columns) == 1 && c.columns[0] == column && (c.operator == "=" || c.operator == "in") {
+			return c.values, true
+		}
+	}
+	return nil, false
+}
+
+// timestamps returns the timestamps from and to, both inclusive, narrowed by the conditions on
+// them, returning false if none can match.
+func timestamps(conditions []resolved) (from uint64, to uint64, ok bool) {
+	to = math.MaxUint64
+	for _, c := range conditions {
+		if len(c.columns) != 1 || c.columns[0].name != "timestamp" {
+			continue
+		}
+		value, fits := toUint64(c.values[0])
+		switch c.operator {
+		case "=":
+			if !fits {
+				return 0, 0, false
+			}
+			from, to = max(from, value), min(to, value)
+		case ">", ">=":
+			if !fits || (c.operator == ">" && value == math.MaxUint64) {
+				return 0, 0, false
+			}
+			if c.operator == ">" {
+				value++
+			}
+			from = max(from, value)
+		case "<", "<=":
+			if !fits {
+				continue
+			}
+			if c.operator == "<" {
+				if value == 0 {
+					return 0, 0, false
+				}
+				value--
+			}
+			to = min(to, value)
+		}
+	}
+	return from, to, from <= to
+}
+
+func toUint64(value types.Uint128) (uint64, bool) {
+	bytes := value.Bytes()
+	var low uint64
+	for i := 7; i >= 0; i-- {
+		low = low<<8 | uint64(bytes[i])
+	}
+	return low, value == types.ToUint128(low)
+}
@@ -0,0 +1,418 @@
+// Package tbsql is a read-only database/sql driver, registered as "tigerbeetle", so that BI
+// tools and code written against database/sql or sqlx can read accounts and transfers with SQL:
+//
+//	db, err := sql.Open("tigerbeetle", "addresses=3000,3001&cluster=0")
+//	rows, err := db.QueryContext(ctx, `SELECT id, amount, timestamp FROM transfers
+//		WHERE debit_account_id = ? AND timestamp >= ? ORDER BY timestamp DESC LIMIT 100`,
+//		accountID, since)
+//
+// or, over a client of the application, sql.OpenDB(tbsql.NewConnector(client)).
+//
+// The driver supports a small subset of SELECT:
+//
+//	SELECT * | column, ... FROM accounts | transfers
+//	    [WHERE condition AND ...] [ORDER BY timestamp [ASC | DESC]] [LIMIT n]
+//
+// where a condition compares a column with =, <>, <, <=, >, >= or IN (...) to an integer, a
+// quoted string of digits, or a placeholder, ? or $n. The columns are named as the fields of
+// types.Account and types.Transfer are in JSON, and account_id stands for either account of a
+// transfer in conditions.
+//
+// Since the cluster has no table scans, a query must select the accounts by id, and the
+// transfers by id, or by debit_account_id, credit_account_id or account_id equal to one
+// account. The transfers of an account are paged with GetAccountTransfers as the rows are read,
+// with the conditions on timestamp narrowing its filter, and ORDER BY timestamp DESC reversing
+// it. The other conditions filter the rows returned; a query that can't be answered fails with
+// ErrUnsupportedQuery.
+//
+// The 128-bit integers and user_data_64 are returned as decimal strings, which scan into a
+// types.Uint128, a string, or an integer type that holds them, and the other columns as int64.
+package tbsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/reconcile"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func init() {
+	sql.Register("tigerbeetle", Driver{})
+}
+
+// ErrReadOnly is returned for statements other than queries.
+type ErrReadOnly struct{}
+
+func (ErrReadOnly) Error() string { return "tbsql: the driver is read-only" }
+
+// Client is the part of the TigerBeetle client that queries read from.
+type Client interface {
+	LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error)
+	LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error)
+	GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error)
+}
+
+// Driver is the driver registered as "tigerbeetle". Its data source names are URL queries of
+// the addresses of the replicas, comma-separated, and the cluster ID, zero if left out:
+//
+//	addresses=3000,3001,3002&cluster=0
+type Driver struct{}
+
+// Open returns a connection with a client of its own. database/sql uses OpenConnector instead,
+// whose connections share one client.
+func (d Driver) Open(name string) (driver.Conn, error) {
+	connector, err := d.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	client := connector.(*clientConnector).client
+	return &conn{client: client, owned: client}, nil
+}
+
+// OpenConnector returns a connector whose connections share a client of the cluster named, which
+// the connector closes as the sql.DB is closed.
+func (Driver) OpenConnector(name string) (driver.Connector, error) {
+	values, err := url.ParseQuery(name)
+	if err != nil {
+		return nil, fmt.Errorf("tbsql: data source name: %w", err)
+	}
+	var addresses []string
+	var clusterID uint64
+	for key, value := range values {
+		switch key {
+		case "addresses":
+			addresses = strings.Split(value[0], ",")
+		case "cluster":
+			if clusterID, err = strconv.ParseUint(value[0], 10, 64); err != nil {
+				return nil, fmt.Errorf("tbsql: cluster %q: %w", value[0], err)
+			}
+		default:
+			return nil, fmt.Errorf("tbsql: unknown data source parameter %q", key)
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("tbsql: data source name has no addresses")
+	}
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(clusterID), addresses, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &clientConnector{connector: connector{client: client}, client: client}, nil
+}
+
+// NewConnector returns a connector whose connections query through client, for sql.OpenDB.
+// The client is left open as the sql.DB is closed.
+func NewConnector(client Client) driver.Connector {
+	return connector{client: client}
+}
+
+type connector struct {
+	client Client
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{client: c.client}, nil
+}
+
+func (connector) Driver() driver.Driver { return Driver{} }
+
+// clientConnector is a connector of a client of its own.
+type clientConnector struct {
+	connector
+	client tigerbeetle_go.Client
+}
+
+// Close implements io.Closer, which sql.DB.Close calls.
+func (c *clientConnector) Close() error {
+	c.client.Close()
+	return nil
+}
+
+type conn struct {
+	client Client
+	// owned is the client, if the connection closes it.
+	owned tigerbeetle_go.Client
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	parsed, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{client: c.client, query: parsed}, nil
+}
+
+func (c *conn) Close() error {
+	if c.owned != nil {
+		c.owned.Close()
+	}
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx returns a transaction that does nothing, as there is nothing to write, for tools that
+// wrap their queries in one. Queries within it don't read from a snapshot.
+func (c *conn) BeginTx(_ context.Context, options driver.TxOptions) (driver.Tx, error) {
+	if options.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, fmt.Errorf("tbsql: isolation level %s is not supported",
+			sql.IsolationLevel(options.Isolation))
+	}
+	return tx{}, nil
+}
+
+// CheckNamedValue accepts uint64 and types.Uint128 arguments as they are, since the default
+// conversion rejects integers above int64.
+func (c *conn) CheckNamedValue(value *driver.NamedValue) error {
+	switch value.Value.(type) {
+	case uint64, types.Uint128:
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type stmt struct {
+	client Client
+	query  *query
+}
+
+func (s *stmt) Close() error { return nil }
+
+func (s *stmt) NumInput() int { return s.query.inputs }
+
+func (s *stmt) Exec([]driver.Value) (driver.Result, error) { return nil, ErrReadOnly{} }
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return s.QueryContext(context.Background(), named)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	values := make([]types.Uint128, s.query.inputs)
+	for _, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("tbsql: named argument %s is not supported", arg.Name)
+		}
+		switch value := arg.Value.(type) {
+		case uint64:
+			values[arg.Ordinal-1] = types.ToUint128(value)
+		case types.Uint128:
+			values[arg.Ordinal-1] = value
+		default:
+			if err := values[arg.Ordinal-1].Scan(value); err != nil {
+				return nil, fmt.Errorf("tbsql: argument %d: %w", arg.Ordinal, err)
+			}
+		}
+	}
+	conditions := s.query.resolve(values)
+	source, err := s.source(conditions)
+	if err != nil {
+		return nil, err
+	}
+	next, stop := iter.Pull2(source)
+	return &rows{ctx: ctx, query: s.query, conditions: conditions, next: next, stop: stop}, nil
+}
+
+// source returns the rows of the table that the conditions may match, by the request that the
+// conditions allow.
+func (s *stmt) source(conditions []resolved) (iter.Seq2[reflect.Value, error], error) {
+	table := s.query.table
+	if ids, ok := keys(conditions, table.column("id")); ok {
+		if table == accountsTable {
+			return s.lookup(lookup(s.client.LookupAccounts, types.OperationLookupAccounts, ids))
+		}
+		return s.lookup(lookup(s.client.LookupTransfers, types.OperationLookupTransfers, ids))
+	}
+	if table == accountsTable {
+		return nil, ErrUnsupportedQuery{Reason: "accounts must be selected by id"}
+	}
+
+	filter := types.AccountFilter{Limit: uint32(types.MaxBatchSize(types.OperationGetAccountTransfers))}
+	var flags types.AccountFilterFlags
+	for _, c := range conditions {
+		if c.operator != "=" && (c.operator != "in" || len(c.values) != 1) {
+			continue
+		}
+		if len(c.columns) == 2 {
+			flags.Debits, flags.Credits = true, true
+		} else if c.columns[0].name == "debit_account_id" {
+			flags.Debits = true
+		} else if c.columns[0].name == "credit_account_id" {
+			flags.Credits = true
+		} else {
+			continue
+		}
+		filter.AccountID = c.values[0]
+		break
+	}
+	if !flags.Debits && !flags.Credits {
+		return nil, ErrUnsupportedQuery{
+			Reason: "transfers must be selected by id, or by debit_account_id, credit_account_id or account_id",
+		}
+	}
+	flags.Reversed = s.query.descending
+	filter.Flags = flags.ToUint32()
+
+	from, to, ok := timestamps(conditions)
+	if !ok {
+		return none, nil
+	}
+	filter.TimestampMin = from
+	if to < math.MaxUint64 {
+		filter.TimestampMax = to
+	}
+	if s.query.hasLimit && s.query.limit < uint64(filter.Limit) {
+		filter.Limit = uint32(s.query.limit)
+	}
+	// The cluster matches no transfers of the zero or maximum account ID, nor any with a zero
+	// limit.
+	if filter.Validate() != nil {
+		return none, nil
+	}
+	return func(yield func(reflect.Value, error) bool) {
+		for transfer, err := range reconcile.AccountTransfers(s.client, filter) {
+			if !yield(reflect.ValueOf(transfer), err) {
+				return
+			}
+		}
+	}, nil
+}
+
+// lookup returns the rows of the IDs that exist, looked up in batches.
+func lookup[T any](
+	request func([]types.Uint128) ([]T, error),
+	op types.Operation,
+	ids []types.Uint128,
+) ([]reflect.Value, error) {
+	ids = slices.Clone(ids)
+	slices.SortFunc(ids, compare)
+	ids = slices.Compact(ids)
+	var found []reflect.Value
+	for chunk := range slices.Chunk(ids, types.MaxBatchSize(op)) {
+		rows, err := request(chunk)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			found = append(found, reflect.ValueOf(row))
+		}
+	}
+	return found, nil
+}
+
+func (s *stmt) lookup(found []reflect.Value, err error) (iter.Seq2[reflect.Value, error], error) {
+	if err != nil {
+		return nil, err
+	}
+	if s.query.ordered {
+		timestamp := s.query.table.column("timestamp")
+		slices.SortFunc(found, func(a, b reflect.Value) int {
+			order := compare(timestamp.get(a), timestamp.get(b))
+			if s.query.descending {
+				return -order
+			}
+			return order
+		})
+	}
+	return func(yield func(reflect.Value, error) bool) {
+		for _, row := range found {
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+func none(func(reflect.Value, error) bool) {}
+
+type rows struct {
+	ctx        context.Context
+	query      *query
+	conditions []resolved
+	next       func() (reflect.Value, error, bool)
+	stop       func()
+	count      uint64
+}
+
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.query.columns))
+	for i, column := range r.query.columns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName.
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	if r.query.columns[index].kind == numeric {
+		return "NUMERIC"
+	}
+	return "BIGINT"
+}
+
+// ColumnTypeScanType implements driver.RowsColumnTypeScanType.
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	if r.query.columns[index].kind == numeric {
+		return reflect.TypeFor[string]()
+	}
+	return reflect.TypeFor[int64]()
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	for {
+		if r.query.hasLimit && r.count == r.query.limit {
+			return io.EOF
+		}
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		row, err, ok := r.next()
+		if !ok {
+			return io.EOF
+		}
+		if err != nil {
+			return err
+		}
+		if !matches(r.conditions, row) {
+			continue
+		}
+		for i, column := range r.query.columns {
+			value := column.get(row)
+			if column.kind == numeric {
+				dest[i] = value.String()
+			} else {
+				low, _ := toUint64(value)
+				dest[i] = int64(low)
+			}
+		}
+		r.count++
+		return nil
+	}
+}
+
+func (r *rows) Close() error {
+	r.stop()
+	return nil
+}
@@ -0,0 +1,151 @@
+package tbsql
+
+import (
+	"context"
+	"database/sql"
+	e "errors"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbtest"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// queryIDs returns the first column of the rows of query, which must be an ID.
+func queryIDs(t *testing.T, db *sql.DB, query string, args ...any) []uint64 {
+	t.Helper()
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	ids := []uint64{}
+	for rows.Next() {
+		var id types.Uint128
+		assert.Equal(t, nil, rows.Scan(&id))
+		low, _ := toUint64(id)
+		ids = append(ids, low)
+	}
+	assert.Equal(t, nil, rows.Err())
+	return ids
+}
+
+func TestDriver(t *testing.T) {
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1,
+		tigerbeetle_go.WithTransport(tbtest.NewLedger(tbtest.NewClock(time.Time{}))))
+	assert.Equal(t, nil, err)
+	defer client.Close()
+
+	a, b, c := types.ToUint128(1), types.ToUint128(2), types.ToUint128(3)
+	_, err = client.CreateAccounts([]types.Account{
+		{ID: a, Ledger: 1, Code: 1},
+		{ID: b, Ledger: 1, Code: 1, UserData64: 1 << 63},
+		{ID: c, Ledger: 1, Code: 2},
+	})
+	assert.Equal(t, nil, err)
+	var transfers []types.Transfer
+	for i := range uint64(6) {
+		debit, credit := a, b
+		if i%2 == 1 {
+			debit, credit = b, c
+		}
+		transfers = append(transfers, types.Transfer{
+			ID: types.ToUint128(10 + i), DebitAccountID: debit, CreditAccountID: credit,
+			Amount: types.ToUint128(100 * (i + 1)), Ledger: 1, Code: uint16(i + 1),
+		})
+	}
+	_, err = client.CreateTransfers(transfers)
+	assert.Equal(t, nil, err)
+	created, err := client.LookupTransfers([]types.Uint128{types.ToUint128(12)})
+	assert.Equal(t, nil, err)
+	middle := created[0].Timestamp
+
+	db := sql.OpenDB(NewConnector(client))
+	defer db.Close()
+
+	t.Run("accounts", func(t *testing.T) {
+		assert.Equal(t, []uint64{1, 2}, queryIDs(t, db, "SELECT id FROM accounts WHERE id IN (2, ?, ?)", 1, 2))
+		assert.Equal(t, []uint64{3}, queryIDs(t, db, "select ID from Accounts where id in (1, '3') and code = 2"))
+		assert.Equal(t, []uint64{}, queryIDs(t, db, "SELECT id FROM accounts WHERE id = 4"))
+
+		var id types.Uint128
+		var userData64 uint64
+		var ledger int
+		var debitsPosted string
+		err := db.QueryRow("SELECT id, user_data_64, ledger, debits_posted FROM accounts WHERE id = ?", b).
+			Scan(&id, &userData64, &ledger, &debitsPosted)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, b, id)
+		assert.Equal(t, uint64(1<<63), userData64)
+		assert.Equal(t, 1, ledger)
+		assert.Equal(t, "1200", debitsPosted)
+
+		rows, err := db.Query("SELECT * FROM accounts WHERE id = 1")
+		assert.Equal(t, nil, err)
+		columns, err := rows.ColumnTypes()
+		assert.Equal(t, nil, err)
+		assert.Len(t, columns, 12)
+		assert.Equal(t, "debits_pending", columns[1].Name())
+		assert.Equal(t, "NUMERIC", columns[1].DatabaseTypeName())
+		assert.Equal(t, "BIGINT", columns[11].DatabaseTypeName())
+		assert.Equal(t, nil, rows.Close())
+	})
+
+	t.Run("transfers", func(t *testing.T) {
+		assert.Equal(t, []uint64{10, 12, 14},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE debit_account_id = ?", uint64(1)))
+		assert.Equal(t, []uint64{15, 14, 13, 12, 11, 10},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE account_id = 2 ORDER BY timestamp DESC"))
+		assert.Equal(t, []uint64{11, 13},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE credit_account_id = 3 AND amount < 500;"))
+		assert.Equal(t, []uint64{12, 13},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE account_id = 2 AND timestamp >= ? LIMIT 2", middle))
+		assert.Equal(t, []uint64{11, 10},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE account_id = 2 AND timestamp < ? ORDER BY timestamp DESC", middle))
+		assert.Equal(t, []uint64{12},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE account_id = 2 AND timestamp = ?", middle))
+		assert.Equal(t, []uint64{13, 10},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE id IN (13, 10, 99) ORDER BY timestamp DESC"))
+		assert.Equal(t, []uint64{},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE account_id = 2 AND timestamp < 0"))
+		assert.Equal(t, []uint64{},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE debit_account_id = 0"))
+
+		// Placeholders may be scanned from the other types that database/sql passes.
+		assert.Equal(t, []uint64{11},
+			queryIDs(t, db, "SELECT id FROM transfers WHERE id = ?", types.Uint128Bytes(types.ToUint128(11))))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := db.Query("SELECT id FROM accounts WHERE ledger = 1")
+		assert.Equal(t, ErrUnsupportedQuery{Reason: "accounts must be selected by id"}, err)
+		_, err = db.Query("SELECT id FROM transfers WHERE account_id IN (1, 2)")
+		assert.True(t, e.As(err, new(ErrUnsupportedQuery)))
+		_, err = db.Query("SELECT id FROM ledgers")
+		assert.Equal(t, ErrSyntax{Offset: 15, Reason: `unknown table "ledgers"`}, err)
+		_, err = db.Query("SELECT id FROM transfers WHERE id = ? AND code = $2", 1, 2)
+		assert.Equal(t, ErrSyntax{Offset: 49, Reason: "? and $n placeholders can't be mixed"}, err)
+		_, err = db.Query("SELECT id FROM transfers ORDER BY amount")
+		assert.Equal(t, ErrSyntax{Offset: 34, Reason: "only ORDER BY timestamp is supported"}, err)
+		_, err = db.Exec("DELETE FROM transfers")
+		assert.Equal(t, ErrReadOnly{}, err)
+		_, err = db.Exec("SELECT id FROM transfers WHERE id = 1")
+		assert.Equal(t, ErrReadOnly{}, err)
+		_, err = db.Query("SELECT id FROM transfers WHERE id = ?", -1)
+		assert.True(t, err != nil)
+
+		tx, err := db.Begin()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []uint64{10}, queryIDs(t, db, "SELECT id FROM transfers WHERE id = 10"))
+		assert.Equal(t, nil, tx.Commit())
+		_, err = db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+		assert.True(t, err != nil)
+
+		_, err = Driver{}.OpenConnector("cluster=0")
+		assert.True(t, err != nil)
+		_, err = Driver{}.OpenConnector("addresses=3000&replica=1")
+		assert.True(t, err != nil)
+	})
+}
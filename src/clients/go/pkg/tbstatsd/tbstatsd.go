@@ -0,0 +1,251 @@
+// Package tbstatsd sends the telemetry of clients to statsd, in the DogStatsD dialect with tags,
+// for teams on Datadog:
+//
+//	sink, err := tbstatsd.Dial("localhost:8125", tbstatsd.Options{Tags: []string{"service:payments"}})
+//	client, err := tigerbeetle_go.NewClient(clusterID, addresses, 32,
+//		tigerbeetle_go.WithInterceptor(sink.Interceptor()),
+//		tigerbeetle_go.WithConnectionMonitor(tigerbeetle_go.ConnectionMonitor{
+//			OnStateChange: sink.OnStateChange,
+//		}))
+//	go sink.Report(ctx, client, 10*time.Second)
+//
+// The interceptor times every request, Report turns the Stats of the client into counters and
+// gauges, and OnStateChange counts the changes of its connection state. The metrics are
+// buffered into packets that Report sends every interval, and that are sent as soon as they are
+// full.
+//
+// Metrics are sent over UDP, so they are lost rather than block the client when the agent is
+// down, and errors of the writer are ignored.
+package tbstatsd
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// packetSizeMax is the size of the largest packet sent, the payload of a UDP datagram that fits
+// the MTU of Ethernet without fragmenting.
+const packetSizeMax = 1432
+
+// Client is the part of the TigerBeetle client that Report reads.
+type Client interface {
+	State() tigerbeetle_go.ConnectionState
+	Stats() tigerbeetle_go.Stats
+}
+
+// Options configures a Sink.
+type Options struct {
+	// Prefix is prepended to the name of every metric. Defaults to "tigerbeetle.".
+	Prefix string
+	// Tags are added to every metric, as "key:value" or "key".
+	Tags []string
+}
+
+// Sink buffers metrics and writes them to statsd. It is safe for concurrent use.
+type Sink struct {
+	writer io.Writer
+	prefix string
+	tags   []string
+
+	mutex  sync.Mutex
+	buffer []byte
+}
+
+// Dial returns a sink sending to the statsd agent at address, a UDP host:port.
+func Dial(address string, options Options) (*Sink, error) {
+	connection, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return New(connection, options), nil
+}
+
+// New returns a sink writing to writer a packet per write, as to a UDP connection. Close closes
+// writer if it is an io.Closer.
+func New(writer io.Writer, options Options) *Sink {
+	if options.Prefix == "" {
+		options.Prefix = "tigerbeetle."
+	}
+	return &Sink{
+		writer: writer,
+		prefix: options.Prefix,
+		tags:   options.Tags,
+		buffer: make([]byte, 0, packetSizeMax),
+	}
+}
+
+// Interceptor returns the interceptor that times the requests of the client as
+// request.duration, tagged with the operation and a status of ok or error. Requests that fail
+// with the results of events that failed have a status of ok.
+func (s *Sink) Interceptor() tigerbeetle_go.Interceptor {
+	return func(ctx context.Context, op types.Operation, events any, next tigerbeetle_go.Invoker) (any, error) {
+		started := time.Now()
+		results, err := next(ctx, op, events)
+		status := "status:ok"
+		if err != nil {
+			status = "status:error"
+		}
+		s.Timing("request.duration", time.Since(started), "operation:"+op.String(), status)
+		return results, err
+	}
+}
+
+// OnStateChange counts the changes of the connection state of a client as
+// connection.state_change, tagged with the states from and to. It is meant for
+// ConnectionMonitor.OnStateChange.
+func (s *Sink) OnStateChange(change tigerbeetle_go.ConnectionStateChange) {
+	s.Count("connection.state_change", 1, "from:"+change.From.String(), "to:"+change.To.String())
+}
+
+// connectionStates and circuitStates are the states that Report sends a gauge for.
+var (
+	connectionStates = []tigerbeetle_go.ConnectionState{
+		tigerbeetle_go.ConnectionConnecting,
+		tigerbeetle_go.ConnectionConnected,
+		tigerbeetle_go.ConnectionReconnecting,
+		tigerbeetle_go.ConnectionUnreachable,
+		tigerbeetle_go.ConnectionEvicted,
+		tigerbeetle_go.ConnectionClosed,
+	}
+	circuitStates = []tigerbeetle_go.CircuitState{
+		tigerbeetle_go.CircuitClosed,
+		tigerbeetle_go.CircuitOpen,
+		tigerbeetle_go.CircuitHalfOpen,
+	}
+)
+
+// Report sends the stats of client every interval, and flushes the sink, until ctx is done,
+// returning ctx.Err():
+//
+//   - requests and errors, counted by operation;
+//   - bytes.sent, bytes.received, rate_limited and rate_limit.wait, counted;
+//   - in_flight and queued as gauges;
+//   - connection.state and circuit.state as a gauge per state, tagged with the state, one for the
+//     state the client is in and zero for the others.
+//
+// The counters are sent as they increase from one report to the next, from the first report.
+func (s *Sink) Report(ctx context.Context, client Client, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := client.Stats()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			_ = s.Flush()
+			return ctx.Err()
+		}
+		stats := client.Stats()
+		s.report(last, stats, client.State())
+		_ = s.Flush()
+		last = stats
+	}
+}
+
+func (s *Sink) report(last tigerbeetle_go.Stats, stats tigerbeetle_go.Stats, state tigerbeetle_go.ConnectionState) {
+	for op, operation := range stats.Operations {
+		tag := "operation:" + op.String()
+		if requests := operation.Requests - last.Operations[op].Requests; requests > 0 {
+			s.Count("requests", int64(requests), tag)
+		}
+		if errors := operation.Errors - last.Operations[op].Errors; errors > 0 {
+			s.Count("errors", int64(errors), tag)
+		}
+	}
+	s.Count("bytes.sent", int64(stats.BytesSent-last.BytesSent))
+	s.Count("bytes.received", int64(stats.BytesReceived-last.BytesReceived))
+	s.Count("rate_limited", int64(stats.RateLimited-last.RateLimited))
+	s.Count("rate_limit.wait", int64((stats.RateLimitWait - last.RateLimitWait).Milliseconds()))
+	s.Gauge("in_flight", float64(stats.InFlight))
+	s.Gauge("queued", float64(stats.Queued))
+	for _, each := range connectionStates {
+		s.Gauge("connection.state", gaugeOf(each == state), "state:"+each.String())
+	}
+	for _, each := range circuitStates {
+		s.Gauge("circuit.state", gaugeOf(each == stats.Circuit), "state:"+each.String())
+	}
+}
+
+func gaugeOf(current bool) float64 {
+	if current {
+		return 1
+	}
+	return 0
+}
+
+// Count adds value to the counter name.
+func (s *Sink) Count(name string, value int64, tags ...string) {
+	s.add(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Gauge sets the gauge name to value.
+func (s *Sink) Gauge(name string, value float64, tags ...string) {
+	s.add(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags)
+}
+
+// Timing records a duration under name, in milliseconds.
+func (s *Sink) Timing(name string, duration time.Duration, tags ...string) {
+	milliseconds := float64(duration) / float64(time.Millisecond)
+	s.add(name, strconv.FormatFloat(milliseconds, 'f', -1, 64), "ms", tags)
+}
+
+// add buffers the metric as a line "<prefix><name>:<value>|<kind>|#<tags>", flushing the
+// buffer first if the line does not fit in its packet.
+func (s *Sink) add(name string, value string, kind string, tags []string) {
+	var line strings.Builder
+	line.WriteString(s.prefix)
+	line.WriteString(name)
+	line.WriteByte(':')
+	line.WriteString(value)
+	line.WriteByte('|')
+	line.WriteString(kind)
+	if len(s.tags)+len(tags) > 0 {
+		line.WriteString("|#")
+		line.WriteString(strings.Join(append(s.tags[:len(s.tags):len(s.tags)], tags...), ","))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.buffer) > 0 && len(s.buffer)+1+line.Len() > packetSizeMax {
+		_ = s.flush()
+	}
+	if len(s.buffer) > 0 {
+		s.buffer = append(s.buffer, '\n')
+	}
+	s.buffer = append(s.buffer, line.String()...)
+}
+
+// Flush writes the metrics buffered, if any.
+func (s *Sink) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.flush()
+}
+
+func (s *Sink) flush() error {
+	if len(s.buffer) == 0 {
+		return nil
+	}
+	_, err := s.writer.Write(s.buffer)
+	s.buffer = s.buffer[:0]
+	return err
+}
+
+// Close flushes the sink and closes its writer.
+func (s *Sink) Close() error {
+	err := s.Flush()
+	if closer, ok := s.writer.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
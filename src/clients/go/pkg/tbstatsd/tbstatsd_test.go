@@ -0,0 +1,142 @@
+package tbstatsd
+
+import (
+	"context"
+	e "errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbtest"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// packets records every write as a packet.
+type packets struct {
+	written []string
+}
+
+func (p *packets) Write(packet []byte) (int, error) {
+	p.written = append(p.written, string(packet))
+	return len(packet), nil
+}
+
+// lines returns the lines of every packet written, without the values of timings.
+func (p *packets) lines() []string {
+	var lines []string
+	for _, packet := range p.written {
+		for _, line := range strings.Split(packet, "\n") {
+			if name, rest, ok := strings.Cut(line, ":"); ok && strings.Contains(rest, "|ms") {
+				_, rest, _ = strings.Cut(rest, "|")
+				line = name + ":_|" + rest
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestSink(t *testing.T) {
+	writer := &packets{}
+	sink := New(writer, Options{Tags: []string{"service:test"}})
+
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1,
+		tigerbeetle_go.WithTransport(tbtest.NewLedger(tbtest.NewClock(time.Time{}))),
+		tigerbeetle_go.WithInterceptor(sink.Interceptor()),
+		tigerbeetle_go.WithInterceptor(func(ctx context.Context, op types.Operation, events any, next tigerbeetle_go.Invoker) (any, error) {
+			if op == types.OperationLookupTransfers {
+				return nil, e.New("lookup failed")
+			}
+			return next(ctx, op, events)
+		}),
+	)
+	assert.Equal(t, nil, err)
+	defer client.Close()
+
+	last := client.Stats()
+	_, err = client.CreateAccounts([]types.Account{{ID: types.ToUint128(1), Ledger: 1, Code: 1}})
+	assert.Equal(t, nil, err)
+	_, err = client.LookupTransfers([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, "lookup failed", err.Error())
+	sink.OnStateChange(tigerbeetle_go.ConnectionStateChange{
+		From: tigerbeetle_go.ConnectionConnected,
+		To:   tigerbeetle_go.ConnectionReconnecting,
+	})
+	assert.Len(t, writer.written, 0)
+
+	stats := client.Stats()
+	stats.Operations = map[types.Operation]tigerbeetle_go.OperationStats{
+		types.OperationCreateAccounts: stats.Operations[types.OperationCreateAccounts],
+	}
+	sink.report(last, stats, client.State())
+	assert.Equal(t, nil, sink.Close())
+	assert.Len(t, writer.written, 1)
+	assert.Equal(t, []string{
+		"tigerbeetle.request.duration:_|ms|#service:test,operation:CreateAccounts,status:ok",
+		"tigerbeetle.request.duration:_|ms|#service:test,operation:LookupTransfers,status:error",
+		"tigerbeetle.connection.state_change:1|c|#service:test,from:connected,to:reconnecting",
+		"tigerbeetle.requests:1|c|#service:test,operation:CreateAccounts",
+		"tigerbeetle.bytes.sent:" + itoa(stats.BytesSent) + "|c|#service:test",
+		"tigerbeetle.bytes.received:" + itoa(stats.BytesReceived) + "|c|#service:test",
+		"tigerbeetle.rate_limited:0|c|#service:test",
+		"tigerbeetle.rate_limit.wait:0|c|#service:test",
+		"tigerbeetle.in_flight:0|g|#service:test",
+		"tigerbeetle.queued:0|g|#service:test",
+		"tigerbeetle.connection.state:0|g|#service:test,state:connecting",
+		"tigerbeetle.connection.state:1|g|#service:test,state:connected",
+		"tigerbeetle.connection.state:0|g|#service:test,state:reconnecting",
+		"tigerbeetle.connection.state:0|g|#service:test,state:cluster-unreachable",
+		"tigerbeetle.connection.state:0|g|#service:test,state:session-evicted",
+		"tigerbeetle.connection.state:0|g|#service:test,state:closed",
+		"tigerbeetle.circuit.state:1|g|#service:test,state:closed",
+		"tigerbeetle.circuit.state:0|g|#service:test,state:open",
+		"tigerbeetle.circuit.state:0|g|#service:test,state:half-open",
+	}, writer.lines())
+}
+
+func TestSinkPackets(t *testing.T) {
+	writer := &packets{}
+	sink := New(writer, Options{Prefix: "tb."})
+	for range 200 {
+		sink.Count("requests", 1)
+	}
+	assert.Equal(t, nil, sink.Flush())
+	assert.Equal(t, nil, sink.Flush())
+
+	// Packets are filled up to their size, with whole lines.
+	lines := 0
+	for i, packet := range writer.written {
+		assert.True(t, len(packet) <= packetSizeMax)
+		if i < len(writer.written)-1 {
+			assert.True(t, len(packet)+len("\ntb.requests:1|c") > packetSizeMax)
+		}
+		lines += len(strings.Split(packet, "\n"))
+	}
+	assert.True(t, len(writer.written) > 1)
+	assert.Equal(t, 200, lines)
+	assert.Equal(t, "tb.requests:1|c", strings.Split(writer.written[0], "\n")[0])
+}
+
+func TestSinkReport(t *testing.T) {
+	writer := &packets{}
+	sink := New(writer, Options{})
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1,
+		tigerbeetle_go.WithTransport(tbtest.NewLedger(tbtest.NewClock(time.Time{}))),
+	)
+	assert.Equal(t, nil, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = sink.Report(ctx, client, 10*time.Millisecond)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, len(writer.written) > 0)
+	assert.Equal(t, "tigerbeetle.bytes.sent:0|c", strings.Split(writer.written[0], "\n")[0])
+}
+
+func itoa(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
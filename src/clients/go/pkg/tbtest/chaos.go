@@ -0,0 +1,269 @@
+package tbtest
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// ChaosConfig sets how often a ChaosClient injects each fault. Rates are fractions of requests,
+// between 0 and 1, and a request suffers at most one of evicted, dropped or duplicated.
+type ChaosConfig struct {
+	// Seed makes the faults reproducible. Zero seeds them from the time instead.
+	Seed int64
+
+	// LatencyRate is the fraction of requests delayed by up to Latency, on top of any other fault.
+	LatencyRate float64
+	Latency     time.Duration
+	// EvictRate is the fraction of requests that fail with errors.ErrSessionEvicted, without
+	// being submitted.
+	EvictRate float64
+	// DropRate is the fraction of requests that fail with ErrDropped. Half of them are submitted
+	// but lose the reply, since a client cannot tell whether a lost request reached the cluster.
+	DropRate float64
+	// DuplicateRate is the fraction of requests submitted twice, as a retry would, returning the
+	// reply of the second submission.
+	DuplicateRate float64
+}
+
+// ErrDropped is returned for a request that a ChaosClient dropped. Submitted is whether it was
+// dropped after reaching the cluster, with only the reply lost.
+type ErrDropped struct {
+	Operation types.Operation
+	Submitted bool
+}
+
+func (e ErrDropped) Error() string {
+	if e.Submitted {
+		return "Dropped the reply to " + e.Operation.String() + "."
+	}
+	return "Dropped the request for " + e.Operation.String() + "."
+}
+
+// ChaosClient wraps a client and injects faults into its requests, to test how code copes with
+// them, such as whether its retries are idempotent:
+//
+//	client := tbtest.NewChaosClient(client, tbtest.ChaosConfig{Seed: 1, DropRate: 0.1})
+//
+// Only the operations that submit a single request are subject to faults; the streams of
+// LookupAccountsStream and StreamChanges, and the lifecycle, go straight to the wrapped client.
+type ChaosClient struct {
+	tigerbeetle_go.Client
+	config ChaosConfig
+
+	mutex  sync.Mutex
+	random *rand.Rand
+}
+
+// NewChaosClient returns a client that submits through client, injecting faults as configured.
+func NewChaosClient(client tigerbeetle_go.Client, config ChaosConfig) *ChaosClient {
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &ChaosClient{Client: client, config: config, random: rand.New(rand.NewSource(seed))}
+}
+
+// Fault is a fault injected into a request by a ChaosClient or a Simulator.
+type Fault uint8
+
+const (
+	FaultNone Fault = iota
+	// FaultEvict fails the request with errors.ErrSessionEvicted, without submitting it.
+	FaultEvict
+	// FaultDropRequest fails the request with ErrDropped, without submitting it.
+	FaultDropRequest
+	// FaultDropReply submits the request, but fails it with ErrDropped.
+	FaultDropReply
+	// FaultDuplicate submits the request twice, and returns the reply of the second submission.
+	FaultDuplicate
+)
+
+func (f Fault) String() string {
+	switch f {
+	case FaultNone:
+		return "None"
+	case FaultEvict:
+		return "Evict"
+	case FaultDropRequest:
+		return "DropRequest"
+	case FaultDropReply:
+		return "DropReply"
+	case FaultDuplicate:
+		return "Duplicate"
+	}
+	return "Fault(" + strconv.Itoa(int(f)) + ")"
+}
+
+// next draws the fault of a request, and its delay.
+func (c *ChaosClient) next() (Fault, time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var delay time.Duration
+	if c.random.Float64() < c.config.LatencyRate && c.config.Latency > 0 {
+		delay = time.Duration(c.random.Int63n(int64(c.config.Latency)))
+	}
+
+	draw := c.random.Float64()
+	switch {
+	case draw < c.config.EvictRate:
+		return FaultEvict, delay
+	case draw < c.config.EvictRate+c.config.DropRate/2:
+		return FaultDropRequest, delay
+	case draw < c.config.EvictRate+c.config.DropRate:
+		return FaultDropReply, delay
+	case draw < c.config.EvictRate+c.config.DropRate+c.config.DuplicateRate:
+		return FaultDuplicate, delay
+	}
+	return FaultNone, delay
+}
+
+// inject submits a request of op through submit, with the next fault.
+func inject[R any](c *ChaosClient, op types.Operation, submit func() (R, error)) (R, error) {
+	fault, delay := c.next()
+	time.Sleep(delay)
+
+	var zero R
+	switch fault {
+	case FaultEvict:
+		return zero, errors.ErrSessionEvicted{Reason: "injected by tbtest.ChaosClient"}
+	case FaultDropRequest:
+		return zero, ErrDropped{Operation: op}
+	case FaultDropReply:
+		_, _ = submit()
+		return zero, ErrDropped{Operation: op, Submitted: true}
+	case FaultDuplicate:
+		if _, err := submit(); err != nil {
+			return zero, err
+		}
+	}
+	return submit()
+}
+
+func (c *ChaosClient) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return inject(c, types.OperationCreateAccounts, func() ([]types.AccountEventResult, error) {
+		return c.Client.CreateAccounts(accounts)
+	})
+}
+
+func (c *ChaosClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return inject(c, types.OperationCreateTransfers, func() ([]types.TransferEventResult, error) {
+		return c.Client.CreateTransfers(transfers)
+	})
+}
+
+func (c *ChaosClient) TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return inject(c, types.OperationCreateAccounts, func() ([]types.AccountEventResult, error) {
+		return c.Client.TryCreateAccounts(accounts)
+	})
+}
+
+func (c *ChaosClient) TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return inject(c, types.OperationCreateTransfers, func() ([]types.TransferEventResult, error) {
+		return c.Client.TryCreateTransfers(transfers)
+	})
+}
+
+func (c *ChaosClient) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	return inject(c, types.OperationLookupAccounts, func() ([]types.Account, error) {
+		return c.Client.LookupAccounts(accountIDs)
+	})
+}
+
+func (c *ChaosClient) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	return inject(c, types.OperationLookupTransfers, func() ([]types.Transfer, error) {
+		return c.Client.LookupTransfers(transferIDs)
+	})
+}
+
+func (c *ChaosClient) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	return inject(c, types.OperationGetAccountTransfers, func() ([]types.Transfer, error) {
+		return c.Client.GetAccountTransfers(filter)
+	})
+}
+
+func (c *ChaosClient) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	return inject(c, types.OperationGetAccountHistory, func() ([]types.AccountBalance, error) {
+		return c.Client.GetAccountHistory(filter)
+	})
+}
+
+func (c *ChaosClient) LookupAccountsInto(
+	accountIDs []types.Uint128,
+	buf []types.Account,
+) ([]types.Account, error) {
+	return inject(c, types.OperationLookupAccounts, func() ([]types.Account, error) {
+		return c.Client.LookupAccountsInto(accountIDs, buf)
+	})
+}
+
+func (c *ChaosClient) LookupTransfersInto(
+	transferIDs []types.Uint128,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return inject(c, types.OperationLookupTransfers, func() ([]types.Transfer, error) {
+		return c.Client.LookupTransfersInto(transferIDs, buf)
+	})
+}
+
+func (c *ChaosClient) GetAccountTransfersInto(
+	filter types.AccountFilter,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return inject(c, types.OperationGetAccountTransfers, func() ([]types.Transfer, error) {
+		return c.Client.GetAccountTransfersInto(filter, buf)
+	})
+}
+
+func (c *ChaosClient) GetAccountHistoryInto(
+	filter types.AccountFilter,
+	buf []types.AccountBalance,
+) ([]types.AccountBalance, error) {
+	return inject(c, types.OperationGetAccountHistory, func() ([]types.AccountBalance, error) {
+		return c.Client.GetAccountHistoryInto(filter, buf)
+	})
+}
+
+func (c *ChaosClient) CreateAccount(account types.Account) error {
+	results, err := c.CreateAccounts([]types.Account{account})
+	if err != nil {
+		return err
+	}
+	if len(results) > 0 {
+		return errors.ErrCreateAccount{Result: results[0].Result}
+	}
+	return nil
+}
+
+func (c *ChaosClient) CreateTransfer(transfer types.Transfer) error {
+	results, err := c.CreateTransfers([]types.Transfer{transfer})
+	if err != nil {
+		return err
+	}
+	if len(results) > 0 {
+		return errors.ErrCreateTransfer{Result: results[0].Result}
+	}
+	return nil
+}
+
+func (c *ChaosClient) LookupAccount(accountID types.Uint128) (types.Account, bool, error) {
+	accounts, err := c.LookupAccounts([]types.Uint128{accountID})
+	if err != nil || len(accounts) == 0 {
+		return types.Account{}, false, err
+	}
+	return accounts[0], true, nil
+}
+
+func (c *ChaosClient) PostPending(pendingID types.Uint128, amount types.Uint128) error {
+	return c.CreateTransfer(types.PostPendingTransfer(pendingID, amount))
+}
+
+func (c *ChaosClient) VoidPending(pendingID types.Uint128) error {
+	return c.CreateTransfer(types.VoidPendingTransfer(pendingID))
+}
@@ -0,0 +1,287 @@
+package tbtest
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const (
+	// embeddedStartTimeout bounds how long a replica takes to listen.
+	embeddedStartTimeout = 30 * time.Second
+	// embeddedConcurrencyMaxDefault is the concurrencyMax of the client of an embedded cluster.
+	embeddedConcurrencyMaxDefault = 32
+)
+
+// EmbeddedConfig configures StartEmbeddedConfig. Zero fields take their defaults.
+type EmbeddedConfig struct {
+	// Binary is the path of the tigerbeetle executable. Defaults to $TIGERBEETLE_BINARY, or to
+	// tigerbeetle on the PATH, or else to the release of Version, downloaded once into CacheDir.
+	Binary string
+	// Version is the release to download, such as "0.15.3". Defaults to the latest release,
+	// which a client older than it may not support.
+	Version string
+	// CacheDir keeps the releases downloaded across runs. Defaults to tigerbeetle-go in
+	// os.UserCacheDir().
+	CacheDir string
+	// CacheGrid is the --cache-grid of the replica. Defaults to 256MiB.
+	CacheGrid string
+	// ConcurrencyMax is passed to NewClient. Defaults to 32.
+	ConcurrencyMax uint
+	// Options are passed to NewClient.
+	Options []tigerbeetle_go.ClientOption
+}
+
+// Embedded is a cluster of a single replica, started by StartEmbeddedConfig for a test.
+type Embedded struct {
+	Client tigerbeetle_go.Client
+	// Address is the address the replica listens on, for tools that connect on their own.
+	Address string
+	// DataFile is the path of the data file of the replica, in a directory of the test.
+	DataFile string
+}
+
+// StartEmbedded starts a cluster of a single replica for the test, as StartEmbeddedConfig does
+// with the defaults, and returns a client connected to it.
+func StartEmbedded(t testing.TB) tigerbeetle_go.Client {
+	return StartEmbeddedConfig(t, EmbeddedConfig{}).Client
+}
+
+// StartEmbeddedConfig formats a data file in a temporary directory of the test, starts a replica
+// on it on a port picked by the OS, and connects a client to it. The client is closed, and the
+// replica stopped, once the test completes. It fails the test if the tigerbeetle executable
+// can't be found or downloaded, or the replica doesn't start.
+func StartEmbeddedConfig(t testing.TB, config EmbeddedConfig) *Embedded {
+	t.Helper()
+	if config.CacheGrid == "" {
+		config.CacheGrid = "256MiB"
+	}
+	if config.ConcurrencyMax == 0 {
+		config.ConcurrencyMax = embeddedConcurrencyMaxDefault
+	}
+
+	binary, err := locateBinary(config)
+	if err != nil {
+		t.Fatalf("tbtest: locating tigerbeetle: %s", err)
+	}
+
+	dataFile := filepath.Join(t.TempDir(), "0_0.tigerbeetle")
+	format := exec.Command(binary, "format", "--cluster=0", "--replica=0", "--replica-count=1", dataFile)
+	if output, err := format.CombinedOutput(); err != nil {
+		t.Fatalf("tbtest: formatting %s: %s: %s", dataFile, err, output)
+	}
+
+	// With --addresses=0, the replica listens on a port picked by the OS, and prints it.
+	start := exec.Command(binary, "start", "--cache-grid="+config.CacheGrid, "--addresses=0", dataFile)
+	var stderr bytes.Buffer
+	start.Stderr = &stderr
+	// Keep the stdin of the replica open until it is stopped.
+	stdin, err := start.StdinPipe()
+	if err != nil {
+		t.Fatalf("tbtest: starting replica: %s", err)
+	}
+	stdout, err := start.StdoutPipe()
+	if err != nil {
+		t.Fatalf("tbtest: starting replica: %s", err)
+	}
+	if err := start.Start(); err != nil {
+		t.Fatalf("tbtest: starting replica: %s", err)
+	}
+	t.Cleanup(func() {
+		_ = start.Process.Kill()
+		_ = start.Wait()
+		stdin.Close()
+	})
+
+	port, err := readPort(stdout, embeddedStartTimeout)
+	if err != nil {
+		t.Fatalf("tbtest: starting replica: %s: %s", err, stderr.String())
+	}
+	// Keep draining stdout for the replica not to block on writing it.
+	go func() { _, _ = io.Copy(io.Discard, stdout) }()
+
+	address := "127.0.0.1:" + strconv.Itoa(port)
+	client, err := tigerbeetle_go.NewClient(
+		types.ToUint128(0),
+		[]string{address},
+		config.ConcurrencyMax,
+		config.Options...,
+	)
+	if err != nil {
+		t.Fatalf("tbtest: connecting to replica: %s", err)
+	}
+	// Registered last, the client is closed before the replica is stopped.
+	t.Cleanup(client.Close)
+
+	return &Embedded{Client: client, Address: address, DataFile: dataFile}
+}
+
+// readPort reads the port that a replica started with --addresses=0 prints.
+func readPort(stdout io.Reader, timeout time.Duration) (int, error) {
+	type read struct {
+		port int
+		err  error
+	}
+	done := make(chan read, 1)
+	go func() {
+		line, err := bufio.NewReader(stdout).ReadString('\n')
+		if err != nil {
+			done <- read{err: fmt.Errorf("replica exited before listening: %w", err)}
+			return
+		}
+		port, err := strconv.Atoi(strings.TrimSpace(line))
+		done <- read{port: port, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.port, result.err
+	case <-time.After(timeout):
+		return 0, fmt.Errorf("replica isn't listening after %s", timeout)
+	}
+}
+
+// downloads serializes the downloads of the tests of a process.
+var downloads sync.Mutex
+
+func locateBinary(config EmbeddedConfig) (string, error) {
+	if config.Binary != "" {
+		return config.Binary, nil
+	}
+	if binary := os.Getenv("TIGERBEETLE_BINARY"); binary != "" {
+		return binary, nil
+	}
+	if binary, err := exec.LookPath("tigerbeetle"); err == nil {
+		return binary, nil
+	}
+
+	asset, err := releaseAsset(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+	version := config.Version
+	if version == "" {
+		version = "latest"
+	}
+	cacheDir := config.CacheDir
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		cacheDir = filepath.Join(userCacheDir, "tigerbeetle-go")
+	}
+
+	binary := filepath.Join(cacheDir, version, executableName(runtime.GOOS))
+	downloads.Lock()
+	defer downloads.Unlock()
+	// The latest release is downloaded again by every run, for it not to go stale.
+	if _, err := os.Stat(binary); err == nil && version != "latest" {
+		return binary, nil
+	}
+
+	url := "https://github.com/tigerbeetle/tigerbeetle/releases/download/" + version + "/" + asset
+	if version == "latest" {
+		url = "https://github.com/tigerbeetle/tigerbeetle/releases/latest/download/" + asset
+	}
+	if err := download(url, binary); err != nil {
+		return "", fmt.Errorf("downloading %s: %w", url, err)
+	}
+	return binary, nil
+}
+
+// releaseAsset returns the name of the release archive of the platform.
+func releaseAsset(goos string, goarch string) (string, error) {
+	switch {
+	case goos == "linux" && goarch == "amd64":
+		return "tigerbeetle-x86_64-linux.zip", nil
+	case goos == "linux" && goarch == "arm64":
+		return "tigerbeetle-aarch64-linux.zip", nil
+	case goos == "darwin":
+		return "tigerbeetle-universal-macos.zip", nil
+	case goos == "windows" && goarch == "amd64":
+		return "tigerbeetle-x86_64-windows.zip", nil
+	}
+	return "", fmt.Errorf("no release of tigerbeetle for %s/%s", goos, goarch)
+}
+
+func executableName(goos string) string {
+	if goos == "windows" {
+		return "tigerbeetle.exe"
+	}
+	return "tigerbeetle"
+}
+
+// download extracts the executable from the release archive at url to path, atomically, for
+// concurrent test processes not to run a partial one.
+func download(url string, path string) error {
+	response, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", response.Status)
+	}
+	archive, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	return extract(archive, filepath.Base(path), path)
+}
+
+// extract writes the file name of the zip archive to path.
+func extract(archive []byte, name string, path string) error {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return err
+	}
+	for _, file := range reader.File {
+		if file.Name != name {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		temp, err := os.CreateTemp(filepath.Dir(path), name+".*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(temp.Name())
+
+		contents, err := file.Open()
+		if err != nil {
+			temp.Close()
+			return err
+		}
+		_, err = io.Copy(temp, contents)
+		contents.Close()
+		if closeErr := temp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(temp.Name(), 0o755); err != nil {
+			return err
+		}
+		return os.Rename(temp.Name(), path)
+	}
+	return fmt.Errorf("no %s in the archive", name)
+}
@@ -0,0 +1,94 @@
+package tbtest
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func TestReleaseAsset(t *testing.T) {
+	for _, test := range []struct {
+		goos, goarch, asset string
+	}{
+		{"linux", "amd64", "tigerbeetle-x86_64-linux.zip"},
+		{"linux", "arm64", "tigerbeetle-aarch64-linux.zip"},
+		{"darwin", "arm64", "tigerbeetle-universal-macos.zip"},
+		{"windows", "amd64", "tigerbeetle-x86_64-windows.zip"},
+	} {
+		if asset, err := releaseAsset(test.goos, test.goarch); err != nil || asset != test.asset {
+			t.Fatalf("Expected %s for %s/%s, got %s %v", test.asset, test.goos, test.goarch, asset, err)
+		}
+	}
+	if _, err := releaseAsset("freebsd", "amd64"); err == nil {
+		t.Fatalf("Expected no release for freebsd")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	file, _ := writer.Create("tigerbeetle")
+	file.Write([]byte("#!/bin/sh\n"))
+	writer.Close()
+
+	path := filepath.Join(t.TempDir(), "latest", "tigerbeetle")
+	if err := extract(archive.Bytes(), "tigerbeetle", path); err != nil {
+		t.Fatal(err)
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil || string(contents) != "#!/bin/sh\n" {
+		t.Fatalf("Expected the executable to be extracted, got %q %v", contents, err)
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode()&0o100 == 0 {
+		t.Fatalf("Expected the executable to be executable, got %s", info.Mode())
+	}
+
+	if err := extract(archive.Bytes(), "tigerbeetle.exe", path); err == nil {
+		t.Fatalf("Expected tigerbeetle.exe to be missing")
+	}
+}
+
+func TestReadPort(t *testing.T) {
+	port, err := readPort(strings.NewReader("40123\n"), time.Second)
+	if err != nil || port != 40123 {
+		t.Fatalf("Expected port 40123, got %d %v", port, err)
+	}
+	if _, err := readPort(strings.NewReader(""), time.Second); err == nil {
+		t.Fatalf("Expected an error once the replica exits")
+	}
+	reader, _ := io.Pipe()
+	if _, err := readPort(reader, time.Millisecond); err == nil {
+		t.Fatalf("Expected a timeout")
+	}
+}
+
+func TestStartEmbedded(t *testing.T) {
+	// Only run against a binary at hand, rather than download one.
+	binary := os.Getenv("TIGERBEETLE_BINARY")
+	if binary == "" {
+		binary, _ = exec.LookPath("tigerbeetle")
+	}
+	if binary == "" {
+		if _, err := os.Stat("../../../../../tigerbeetle"); err == nil {
+			binary = "../../../../../tigerbeetle"
+		}
+	}
+	if binary == "" {
+		t.Skip("no tigerbeetle executable")
+	}
+
+	embedded := StartEmbeddedConfig(t, EmbeddedConfig{Binary: binary})
+	results, err := embedded.Client.CreateAccounts([]types.Account{{ID: types.ID(), Ledger: 1, Code: 1}})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("Expected the account to be created, got %v %v", results, err)
+	}
+}
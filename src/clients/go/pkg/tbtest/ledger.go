@@ -0,0 +1,625 @@
+package tbtest
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// simulationEpoch is where the clock of a simulation starts, for its timestamps not to depend
+// on when it runs.
+var simulationEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is the virtual clock of a simulation. It only moves when advanced, by the test or by
+// the latency of simulated requests, so that timeouts expire at the same point of every run.
+type Clock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+// NewClock returns a clock that reads start, or a fixed date if start is zero.
+func NewClock(start time.Time) *Clock {
+	if start.IsZero() {
+		start = simulationEpoch
+	}
+	return &Clock{now: start}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type pendingStatus uint8
+
+const (
+	pendingOpen pendingStatus = iota
+	pendingPosted
+	pendingVoided
+	pendingExpired
+)
+
+type historyEntry struct {
+	balance types.AccountBalance
+	debit   bool
+}
+
+// Ledger is a transport that executes requests in memory, as a cluster of a single replica
+// would, with the timestamps of a Clock. It implements the accounts, transfers and their
+// flags: linked chains, two-phase transfers and their timeouts, balancing transfers, balance
+// limits and account history. It doesn't persist anything, and answers every request at once.
+type Ledger struct {
+	clock *Clock
+
+	mutex     sync.Mutex
+	timestamp uint64
+	accounts  map[types.Uint128]*types.Account
+	// transfers are in the order of their timestamps, indexed by ID in transferIndex.
+	transfers     []types.Transfer
+	transferIndex map[types.Uint128]int
+	pending       map[types.Uint128]pendingStatus
+	history       map[types.Uint128][]historyEntry
+}
+
+// NewLedger returns an empty ledger that timestamps with clock.
+func NewLedger(clock *Clock) *Ledger {
+	return &Ledger{
+		clock:         clock,
+		accounts:      make(map[types.Uint128]*types.Account),
+		transferIndex: make(map[types.Uint128]int),
+		pending:       make(map[types.Uint128]pendingStatus),
+		history:       make(map[types.Uint128][]historyEntry),
+	}
+}
+
+func (l *Ledger) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.expire()
+
+	var results []byte
+	switch op {
+	case types.OperationCreateAccounts:
+		results = encodeResults(l.createAccounts(decode[types.Account](events)))
+	case types.OperationCreateTransfers:
+		results = encodeResults(l.createTransfers(decode[types.Transfer](events)))
+	case types.OperationLookupAccounts:
+		results = encodeResults(l.lookupAccounts(decode[types.Uint128](events)))
+	case types.OperationLookupTransfers:
+		results = encodeResults(l.lookupTransfers(decode[types.Uint128](events)))
+	case types.OperationGetAccountTransfers:
+		results = encodeResults(l.getAccountTransfers(decode[types.AccountFilter](events)))
+	case types.OperationGetAccountHistory:
+		results = encodeResults(l.getAccountHistory(decode[types.AccountFilter](events)))
+	default:
+		return 0, errors.ErrInvalidOperation{}
+	}
+
+	if len(results) > len(reply) {
+		panic("invalid reply: more results than the request allows")
+	}
+	return copy(reply, results), nil
+}
+
+func (l *Ledger) Close() {}
+
+// Accounts returns the accounts, in the order they were created.
+func (l *Ledger) Accounts() []types.Account {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	accounts := make([]types.Account, 0, len(l.accounts))
+	for _, account := range l.accounts {
+		accounts = append(accounts, *account)
+	}
+	slices.SortFunc(accounts, func(a, b types.Account) int { return cmp.Compare(a.Timestamp, b.Timestamp) })
+	return accounts
+}
+
+// Transfers returns the transfers, in the order they were created.
+func (l *Ledger) Transfers() []types.Transfer {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return slices.Clone(l.transfers)
+}
+
+// Verify checks the invariants of double-entry bookkeeping: that the debits of every ledger
+// equal its credits, that the pending balances of every account add up to its open pending
+// transfers, and that no account exceeds the limits of its flags.
+func (l *Ledger) Verify() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	type totals struct {
+		debitsPending, debitsPosted, creditsPending, creditsPosted types.Uint128
+	}
+	ledgers := make(map[uint32]*totals)
+	pending := make(map[types.Uint128]*totals)
+	for _, account := range l.accounts {
+		t := ledgers[account.Ledger]
+		if t == nil {
+			t = &totals{}
+			ledgers[account.Ledger] = t
+		}
+		t.debitsPending = t.debitsPending.AddSaturating(account.DebitsPending)
+		t.debitsPosted = t.debitsPosted.AddSaturating(account.DebitsPosted)
+		t.creditsPending = t.creditsPending.AddSaturating(account.CreditsPending)
+		t.creditsPosted = t.creditsPosted.AddSaturating(account.CreditsPosted)
+		pending[account.ID] = &totals{}
+
+		flags := account.AccountFlags()
+		if flags.DebitsMustNotExceedCredits &&
+			less(account.CreditsPosted, account.DebitsPending.AddSaturating(account.DebitsPosted)) {
+			return fmt.Errorf("tbtest: the debits of account %s exceed its credits", account.ID)
+		}
+		if flags.CreditsMustNotExceedDebits &&
+			less(account.DebitsPosted, account.CreditsPending.AddSaturating(account.CreditsPosted)) {
+			return fmt.Errorf("tbtest: the credits of account %s exceed its debits", account.ID)
+		}
+	}
+	for ledger, t := range ledgers {
+		if t.debitsPending != t.creditsPending {
+			return fmt.Errorf("tbtest: the pending debits of ledger %d don't equal its pending credits", ledger)
+		}
+		if t.debitsPosted != t.creditsPosted {
+			return fmt.Errorf("tbtest: the posted debits of ledger %d don't equal its posted credits", ledger)
+		}
+	}
+
+	for id, status := range l.pending {
+		if status != pendingOpen {
+			continue
+		}
+		transfer := l.transfers[l.transferIndex[id]]
+		debit := pending[transfer.DebitAccountID]
+		debit.debitsPending = debit.debitsPending.AddSaturating(transfer.Amount)
+		credit := pending[transfer.CreditAccountID]
+		credit.creditsPending = credit.creditsPending.AddSaturating(transfer.Amount)
+	}
+	for id, t := range pending {
+		account := l.accounts[id]
+		if account.DebitsPending != t.debitsPending || account.CreditsPending != t.creditsPending {
+			return fmt.Errorf("tbtest: the pending balances of account %s don't add up to its pending transfers", id)
+		}
+	}
+	return nil
+}
+
+// less returns whether a < b.
+func less(a types.Uint128, b types.Uint128) bool {
+	_, ok := a.SubChecked(b)
+	return !ok
+}
+
+// tick returns the timestamp of the next event, which follows both the last one and the clock.
+func (l *Ledger) tick() uint64 {
+	l.timestamp = max(l.timestamp+1, uint64(l.clock.Now().UnixNano()))
+	return l.timestamp
+}
+
+// expire releases the pending transfers whose timeout has passed on the clock.
+func (l *Ledger) expire() {
+	now := uint64(l.clock.Now().UnixNano())
+	for id, status := range l.pending {
+		if status != pendingOpen {
+			continue
+		}
+		transfer := l.transfers[l.transferIndex[id]]
+		if expiresAt := transfer.ExpiresAt(); expiresAt != 0 && expiresAt <= now {
+			debit := l.accounts[transfer.DebitAccountID]
+			debit.DebitsPending = debit.DebitsPending.SubSaturating(transfer.Amount)
+			credit := l.accounts[transfer.CreditAccountID]
+			credit.CreditsPending = credit.CreditsPending.SubSaturating(transfer.Amount)
+			l.pending[id] = pendingExpired
+		}
+	}
+}
+
+// chains runs the events of a batch through create, event by event, rolling back the linked
+// chains that fail, and returns the results of the events that failed.
+func chains[R any](
+	count int,
+	linked func(i int) bool,
+	create func(i int, undo *[]func()) uint32,
+	result func(i int, code uint32) R,
+) []R {
+	var results []R
+	for start := 0; start < count; {
+		end := start
+		for end < count-1 && linked(end) {
+			end++
+		}
+
+		var undo []func()
+		failed, code := -1, uint32(0)
+		if linked(end) {
+			// The last event of the batch leaves the chain open.
+			failed, code = end, 2
+		} else {
+			for i := start; i <= end; i++ {
+				if code = create(i, &undo); code != 0 {
+					failed = i
+					break
+				}
+			}
+		}
+
+		if failed >= 0 {
+			for i := len(undo) - 1; i >= 0; i-- {
+				undo[i]()
+			}
+			for i := start; i <= end; i++ {
+				switch {
+				case i == failed:
+					results = append(results, result(i, code))
+				case start != end:
+					// Every other event of a linked chain fails along with it.
+					results = append(results, result(i, 1))
+				}
+			}
+		}
+		start = end + 1
+	}
+	return results
+}
+
+func (l *Ledger) createAccounts(accounts []types.Account) []types.AccountEventResult {
+	return chains(
+		len(accounts),
+		func(i int) bool { return accounts[i].AccountFlags().Linked },
+		func(i int, undo *[]func()) uint32 { return uint32(l.createAccount(accounts[i], undo)) },
+		func(i int, code uint32) types.AccountEventResult {
+			return types.AccountEventResult{Index: uint32(i), Result: types.CreateAccountResult(code)}
+		},
+	)
+}
+
+func (l *Ledger) createAccount(account types.Account, undo *[]func()) types.CreateAccountResult {
+	if result := account.Validate(); result != types.AccountOK {
+		return result
+	}
+
+	if existing, ok := l.accounts[account.ID]; ok {
+		return account.Exists(*existing)
+	}
+
+	account.Timestamp = l.tick()
+	l.accounts[account.ID] = &account
+	*undo = append(*undo, func() { delete(l.accounts, account.ID) })
+	return types.AccountOK
+}
+
+func (l *Ledger) createTransfers(transfers []types.Transfer) []types.TransferEventResult {
+	return chains(
+		len(transfers),
+		func(i int) bool { return transfers[i].TransferFlags().Linked },
+		func(i int, undo *[]func()) uint32 { return uint32(l.createTransfer(transfers[i], undo)) },
+		func(i int, code uint32) types.TransferEventResult {
+			return types.TransferEventResult{Index: uint32(i), Result: types.CreateTransferResult(code)}
+		},
+	)
+}
+
+func (l *Ledger) createTransfer(transfer types.Transfer, undo *[]func()) types.CreateTransferResult {
+	if result := transfer.Validate(); result != types.TransferOK {
+		return result
+	}
+	flags := transfer.TransferFlags()
+	if flags.PostPendingTransfer || flags.VoidPendingTransfer {
+		return l.resolvePending(transfer, undo)
+	}
+
+	debit, ok := l.accounts[transfer.DebitAccountID]
+	if !ok {
+		return types.TransferDebitAccountNotFound
+	}
+	credit, ok := l.accounts[transfer.CreditAccountID]
+	if !ok {
+		return types.TransferCreditAccountNotFound
+	}
+	if debit.Ledger != credit.Ledger {
+		return types.TransferAccountsMustHaveTheSameLedger
+	}
+	if transfer.Ledger != debit.Ledger {
+		return types.TransferTransferMustHaveTheSameLedgerAsAccounts
+	}
+	if index, ok := l.transferIndex[transfer.ID]; ok {
+		return transfer.Exists(l.transfers[index])
+	}
+
+	amount := transfer.Amount
+	if flags.BalancingDebit || flags.BalancingCredit {
+		if amount == (types.Uint128{}) {
+			amount = types.ToUint128(1<<64 - 1)
+		}
+		if flags.BalancingDebit {
+			available := debit.CreditsPosted.SubSaturating(debit.DebitsPending.AddSaturating(debit.DebitsPosted))
+			amount = minUint128(amount, available)
+			if amount == (types.Uint128{}) {
+				return types.TransferExceedsCredits
+			}
+		}
+		if flags.BalancingCredit {
+			available := credit.DebitsPosted.SubSaturating(credit.CreditsPending.AddSaturating(credit.CreditsPosted))
+			amount = minUint128(amount, available)
+			if amount == (types.Uint128{}) {
+				return types.TransferExceedsDebits
+			}
+		}
+	}
+
+	if flags.Pending {
+		if _, ok := debit.DebitsPending.AddChecked(amount); !ok {
+			return types.TransferOverflowsDebitsPending
+		}
+		if _, ok := credit.CreditsPending.AddChecked(amount); !ok {
+			return types.TransferOverflowsCreditsPending
+		}
+	} else {
+		if _, ok := debit.DebitsPosted.AddChecked(amount); !ok {
+			return types.TransferOverflowsDebitsPosted
+		}
+		if _, ok := credit.CreditsPosted.AddChecked(amount); !ok {
+			return types.TransferOverflowsCreditsPosted
+		}
+	}
+	debits, ok := debit.DebitsPending.AddChecked(debit.DebitsPosted)
+	if debits, ok = debits.AddChecked(amount); !ok {
+		return types.TransferOverflowsDebits
+	}
+	credits, ok := credit.CreditsPending.AddChecked(credit.CreditsPosted)
+	if credits, ok = credits.AddChecked(amount); !ok {
+		return types.TransferOverflowsCredits
+	}
+	timestamp := l.tick()
+	if timestamp > 1<<64-1-uint64(transfer.Timeout)*uint64(time.Second) {
+		return types.TransferOverflowsTimeout
+	}
+	if debit.AccountFlags().DebitsMustNotExceedCredits && less(debit.CreditsPosted, debits) {
+		return types.TransferExceedsCredits
+	}
+	if credit.AccountFlags().CreditsMustNotExceedDebits && less(credit.DebitsPosted, credits) {
+		return types.TransferExceedsDebits
+	}
+
+	transfer.Amount = amount
+	transfer.Timestamp = timestamp
+	l.apply(transfer, debit, credit, undo, func() {
+		if flags.Pending {
+			debit.DebitsPending, _ = debit.DebitsPending.AddChecked(amount)
+			credit.CreditsPending, _ = credit.CreditsPending.AddChecked(amount)
+			l.pending[transfer.ID] = pendingOpen
+		} else {
+			debit.DebitsPosted, _ = debit.DebitsPosted.AddChecked(amount)
+			credit.CreditsPosted, _ = credit.CreditsPosted.AddChecked(amount)
+		}
+	})
+	return types.TransferOK
+}
+
+func (l *Ledger) resolvePending(transfer types.Transfer, undo *[]func()) types.CreateTransferResult {
+	flags := transfer.TransferFlags()
+	index, ok := l.transferIndex[transfer.PendingID]
+	if !ok {
+		return types.TransferPendingTransferNotFound
+	}
+	pending := l.transfers[index]
+	if !pending.TransferFlags().Pending {
+		return types.TransferPendingTransferNotPending
+	}
+
+	zero := types.Uint128{}
+	if transfer.DebitAccountID != zero && transfer.DebitAccountID != pending.DebitAccountID {
+		return types.TransferPendingTransferHasDifferentDebitAccountID
+	}
+	if transfer.CreditAccountID != zero && transfer.CreditAccountID != pending.CreditAccountID {
+		return types.TransferPendingTransferHasDifferentCreditAccountID
+	}
+	if transfer.Ledger != 0 && transfer.Ledger != pending.Ledger {
+		return types.TransferPendingTransferHasDifferentLedger
+	}
+	if transfer.Code != 0 && transfer.Code != pending.Code {
+		return types.TransferPendingTransferHasDifferentCode
+	}
+
+	amount := pending.Amount
+	if flags.PostPendingTransfer && transfer.Amount != zero {
+		if less(pending.Amount, transfer.Amount) {
+			return types.TransferExceedsPendingTransferAmount
+		}
+		amount = transfer.Amount
+	}
+	if flags.VoidPendingTransfer && transfer.Amount != zero && transfer.Amount != pending.Amount {
+		return types.TransferPendingTransferHasDifferentAmount
+	}
+
+	if index, ok := l.transferIndex[transfer.ID]; ok {
+		return transfer.Exists(l.transfers[index])
+	}
+
+	switch l.pending[pending.ID] {
+	case pendingPosted:
+		return types.TransferPendingTransferAlreadyPosted
+	case pendingVoided:
+		return types.TransferPendingTransferAlreadyVoided
+	case pendingExpired:
+		return types.TransferPendingTransferExpired
+	}
+
+	transfer.DebitAccountID = pending.DebitAccountID
+	transfer.CreditAccountID = pending.CreditAccountID
+	transfer.Ledger = pending.Ledger
+	transfer.Code = pending.Code
+	transfer.Amount = amount
+	transfer.Timestamp = l.tick()
+	debit := l.accounts[pending.DebitAccountID]
+	credit := l.accounts[pending.CreditAccountID]
+	l.apply(transfer, debit, credit, undo, func() {
+		debit.DebitsPending = debit.DebitsPending.SubSaturating(pending.Amount)
+		credit.CreditsPending = credit.CreditsPending.SubSaturating(pending.Amount)
+		if flags.PostPendingTransfer {
+			debit.DebitsPosted, _ = debit.DebitsPosted.AddChecked(amount)
+			credit.CreditsPosted, _ = credit.CreditsPosted.AddChecked(amount)
+			l.pending[pending.ID] = pendingPosted
+		} else {
+			l.pending[pending.ID] = pendingVoided
+		}
+	})
+	return types.TransferOK
+}
+
+// apply records transfer, updating the balances of its accounts with update, along with how
+// to undo it.
+func (l *Ledger) apply(
+	transfer types.Transfer,
+	debit *types.Account,
+	credit *types.Account,
+	undo *[]func(),
+	update func(),
+) {
+	debitBefore, creditBefore := *debit, *credit
+	pendingBefore, pendingExisted := l.pending[transfer.PendingID]
+	historyBefore := map[types.Uint128]int{
+		debit.ID:  len(l.history[debit.ID]),
+		credit.ID: len(l.history[credit.ID]),
+	}
+	*undo = append(*undo, func() {
+		*debit, *credit = debitBefore, creditBefore
+		delete(l.pending, transfer.ID)
+		if pendingExisted {
+			l.pending[transfer.PendingID] = pendingBefore
+		}
+		for id, length := range historyBefore {
+			l.history[id] = l.history[id][:length]
+		}
+		delete(l.transferIndex, transfer.ID)
+		l.transfers = l.transfers[:len(l.transfers)-1]
+	})
+
+	update()
+	l.transferIndex[transfer.ID] = len(l.transfers)
+	l.transfers = append(l.transfers, transfer)
+	for _, account := range []*types.Account{debit, credit} {
+		if account.AccountFlags().History {
+			l.history[account.ID] = append(l.history[account.ID], historyEntry{
+				balance: types.AccountBalance{
+					DebitsPending:  account.DebitsPending,
+					DebitsPosted:   account.DebitsPosted,
+					CreditsPending: account.CreditsPending,
+					CreditsPosted:  account.CreditsPosted,
+					Timestamp:      transfer.Timestamp,
+				},
+				debit: account == debit,
+			})
+		}
+	}
+}
+
+func minUint128(a types.Uint128, b types.Uint128) types.Uint128 {
+	if less(b, a) {
+		return b
+	}
+	return a
+}
+
+func (l *Ledger) lookupAccounts(ids []types.Uint128) []types.Account {
+	var accounts []types.Account
+	for _, id := range ids {
+		if account, ok := l.accounts[id]; ok {
+			accounts = append(accounts, *account)
+		}
+	}
+	return accounts
+}
+
+func (l *Ledger) lookupTransfers(ids []types.Uint128) []types.Transfer {
+	var transfers []types.Transfer
+	for _, id := range ids {
+		if index, ok := l.transferIndex[id]; ok {
+			transfers = append(transfers, l.transfers[index])
+		}
+	}
+	return transfers
+}
+
+// query walks the timestamps of count entries in the order of filter, calling visit with the
+// index of each one within the filter's range, until visit returns false.
+func query(filter types.AccountFilter, count int, timestamp func(i int) uint64, visit func(i int) bool) {
+	reversed := filter.AccountFilterFlags().Reversed
+	for n := 0; n < count; n++ {
+		i := n
+		if reversed {
+			i = count - 1 - n
+		}
+		ts := timestamp(i)
+		if ts < filter.TimestampMin || (filter.TimestampMax != 0 && ts > filter.TimestampMax) {
+			continue
+		}
+		if !visit(i) {
+			return
+		}
+	}
+}
+
+func (l *Ledger) getAccountTransfers(filters []types.AccountFilter) []types.Transfer {
+	if len(filters) != 1 || filters[0].Validate() != nil {
+		return nil
+	}
+	filter := filters[0]
+	flags := filter.AccountFilterFlags()
+
+	var transfers []types.Transfer
+	query(filter, len(l.transfers), func(i int) uint64 { return l.transfers[i].Timestamp }, func(i int) bool {
+		transfer := l.transfers[i]
+		if (flags.Debits && transfer.DebitAccountID == filter.AccountID) ||
+			(flags.Credits && transfer.CreditAccountID == filter.AccountID) {
+			transfers = append(transfers, transfer)
+		}
+		return len(transfers) < int(filter.Limit)
+	})
+	return transfers
+}
+
+func (l *Ledger) getAccountHistory(filters []types.AccountFilter) []types.AccountBalance {
+	if len(filters) != 1 || filters[0].Validate() != nil {
+		return nil
+	}
+	filter := filters[0]
+	flags := filter.AccountFilterFlags()
+	history := l.history[filter.AccountID]
+
+	var balances []types.AccountBalance
+	query(filter, len(history), func(i int) uint64 { return history[i].balance.Timestamp }, func(i int) bool {
+		if (flags.Debits && history[i].debit) || (flags.Credits && !history[i].debit) {
+			balances = append(balances, history[i].balance)
+		}
+		return len(balances) < int(filter.Limit)
+	})
+	return balances
+}
+
+// encodeResults copies results into their wire layout.
+func encodeResults[R any](results []R) []byte {
+	if len(results) == 0 {
+		return nil
+	}
+	return slices.Clone(unsafe.Slice(
+		(*byte)(unsafe.Pointer(&results[0])),
+		len(results)*int(unsafe.Sizeof(results[0])),
+	))
+}
@@ -0,0 +1,309 @@
+// Package tbtest provides test doubles for code using the TigerBeetle client.
+//
+// A Registry is a transport that tests program with the operations they expect and the results
+// to reply with, in the spirit of an http.RoundTripper stub:
+//
+//	registry := tbtest.NewRegistry(t, tbtest.Strict)
+//	registry.ExpectCreateTransfers(tbtest.Any[types.Transfer]()).Return()
+//	client, _ := tigerbeetle_go.NewClient(clusterID, nil, 1, tigerbeetle_go.WithTransport(registry))
+//
+// Expectations that were not met fail the test when it completes.
+//
+// Tests that need a real cluster start one with StartEmbedded, which runs a single replica of
+// the tigerbeetle executable for the duration of the test.
+//
+// A Simulator runs a Ledger in memory, on a virtual clock and behind faults drawn from a seed, for
+// property tests of application logic that replay exactly from their seed.
+package tbtest
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Mode decides how a registry answers requests that match no expectation.
+type Mode uint8
+
+const (
+	// Strict fails the test on any request that matches no expectation.
+	Strict Mode = iota
+	// Lenient answers requests that match no expectation with empty results.
+	Lenient
+)
+
+// ErrUnexpectedCall is returned for a request that matches no expectation in Strict mode.
+type ErrUnexpectedCall struct {
+	Operation types.Operation
+}
+
+func (e ErrUnexpectedCall) Error() string {
+	return "Unexpected call to " + e.Operation.String() + "."
+}
+
+// Call is a request received by a registry. Events holds the decoded events, such as a
+// []types.Transfer for OperationCreateTransfers.
+type Call struct {
+	Operation types.Operation
+	Events    any
+}
+
+type expectation interface {
+	operation() types.Operation
+	matches(events []byte) bool
+	exhausted() bool
+	satisfied() bool
+	respond(events []byte, reply []byte) (int, error)
+	String() string
+}
+
+// Registry is a transport that answers requests from programmed expectations.
+type Registry struct {
+	t    testing.TB
+	mode Mode
+
+	mutex        sync.Mutex
+	expectations []expectation
+	calls        []Call
+}
+
+// NewRegistry returns a registry that reports to t, and verifies its expectations once the
+// test completes.
+func NewRegistry(t testing.TB, mode Mode) *Registry {
+	r := &Registry{t: t, mode: mode}
+	t.Cleanup(r.Verify)
+	return r
+}
+
+// Any matches any events.
+func Any[E any]() func(events []E) bool {
+	return func(events []E) bool { return true }
+}
+
+func (r *Registry) ExpectCreateAccounts(
+	match func(accounts []types.Account) bool,
+) *Expectation[types.Account, types.AccountEventResult] {
+	return expect[types.Account, types.AccountEventResult](r, types.OperationCreateAccounts, match)
+}
+
+func (r *Registry) ExpectCreateTransfers(
+	match func(transfers []types.Transfer) bool,
+) *Expectation[types.Transfer, types.TransferEventResult] {
+	return expect[types.Transfer, types.TransferEventResult](r, types.OperationCreateTransfers, match)
+}
+
+func (r *Registry) ExpectLookupAccounts(
+	match func(accountIDs []types.Uint128) bool,
+) *Expectation[types.Uint128, types.Account] {
+	return expect[types.Uint128, types.Account](r, types.OperationLookupAccounts, match)
+}
+
+func (r *Registry) ExpectLookupTransfers(
+	match func(transferIDs []types.Uint128) bool,
+) *Expectation[types.Uint128, types.Transfer] {
+	return expect[types.Uint128, types.Transfer](r, types.OperationLookupTransfers, match)
+}
+
+func (r *Registry) ExpectGetAccountTransfers(
+	match func(filters []types.AccountFilter) bool,
+) *Expectation[types.AccountFilter, types.Transfer] {
+	return expect[types.AccountFilter, types.Transfer](r, types.OperationGetAccountTransfers, match)
+}
+
+func (r *Registry) ExpectGetAccountHistory(
+	match func(filters []types.AccountFilter) bool,
+) *Expectation[types.AccountFilter, types.AccountBalance] {
+	return expect[types.AccountFilter, types.AccountBalance](r, types.OperationGetAccountHistory, match)
+}
+
+func expect[E, R any](
+	r *Registry,
+	op types.Operation,
+	match func(events []E) bool,
+) *Expectation[E, R] {
+	if match == nil {
+		match = Any[E]()
+	}
+
+	expectation := &Expectation[E, R]{registry: r, op: op, match: match, times: 1}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.expectations = append(r.expectations, expectation)
+	return expectation
+}
+
+// Calls returns the requests received so far, in order.
+func (r *Registry) Calls() []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return slices.Clone(r.calls)
+}
+
+// Verify fails the test for every expectation that was called fewer times than expected.
+// It is called automatically once the test completes.
+func (r *Registry) Verify() {
+	r.t.Helper()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, expectation := range r.expectations {
+		if !expectation.satisfied() {
+			r.t.Errorf("tbtest: unmet expectation: %s", expectation)
+		}
+	}
+}
+
+func (r *Registry) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	decoded, ok := decodeEvents(op, events)
+	if !ok {
+		return 0, errors.ErrInvalidOperation{}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.calls = append(r.calls, Call{Operation: op, Events: decoded})
+
+	for _, expectation := range r.expectations {
+		if expectation.operation() == op && !expectation.exhausted() && expectation.matches(events) {
+			return expectation.respond(events, reply)
+		}
+	}
+
+	if r.mode == Lenient {
+		return 0, nil
+	}
+	r.t.Errorf("tbtest: unexpected call to %s with %v", op, decoded)
+	return 0, ErrUnexpectedCall{Operation: op}
+}
+
+func (r *Registry) Close() {}
+
+// Expectation is an expected request with events of type E, answered with results of type R.
+type Expectation[E, R any] struct {
+	registry *Registry
+	op       types.Operation
+	match    func(events []E) bool
+	results  func(events []E) []R
+	err      error
+	times    int
+	anyTimes bool
+	calls    int
+}
+
+// Return answers the request with results.
+func (e *Expectation[E, R]) Return(results ...R) *Expectation[E, R] {
+	return e.ReturnFunc(func(events []E) []R { return results })
+}
+
+// ReturnFunc answers the request with the results computed from its events.
+func (e *Expectation[E, R]) ReturnFunc(results func(events []E) []R) *Expectation[E, R] {
+	e.registry.mutex.Lock()
+	defer e.registry.mutex.Unlock()
+	e.results = results
+	e.err = nil
+	return e
+}
+
+// ReturnError fails the request with err.
+func (e *Expectation[E, R]) ReturnError(err error) *Expectation[E, R] {
+	e.registry.mutex.Lock()
+	defer e.registry.mutex.Unlock()
+	e.results = nil
+	e.err = err
+	return e
+}
+
+// Times expects the request exactly n times, instead of once.
+func (e *Expectation[E, R]) Times(n int) *Expectation[E, R] {
+	e.registry.mutex.Lock()
+	defer e.registry.mutex.Unlock()
+	e.times = n
+	e.anyTimes = false
+	return e
+}
+
+// AnyTimes allows the request any number of times, including never.
+func (e *Expectation[E, R]) AnyTimes() *Expectation[E, R] {
+	e.registry.mutex.Lock()
+	defer e.registry.mutex.Unlock()
+	e.anyTimes = true
+	return e
+}
+
+func (e *Expectation[E, R]) operation() types.Operation { return e.op }
+
+func (e *Expectation[E, R]) matches(events []byte) bool {
+	return e.match(decode[E](events))
+}
+
+func (e *Expectation[E, R]) exhausted() bool {
+	return !e.anyTimes && e.calls >= e.times
+}
+
+func (e *Expectation[E, R]) satisfied() bool {
+	return e.anyTimes || e.calls >= e.times
+}
+
+func (e *Expectation[E, R]) respond(events []byte, reply []byte) (int, error) {
+	e.calls++
+	if e.err != nil {
+		return 0, e.err
+	}
+	if e.results == nil {
+		return 0, nil
+	}
+
+	results := e.results(decode[E](events))
+	if len(results) == 0 {
+		return 0, nil
+	}
+
+	encoded := unsafe.Slice(
+		(*byte)(unsafe.Pointer(&results[0])),
+		len(results)*int(unsafe.Sizeof(results[0])),
+	)
+	if len(encoded) > len(reply) {
+		panic("invalid reply: more results than the request allows")
+	}
+	return copy(reply, encoded), nil
+}
+
+func (e *Expectation[E, R]) String() string {
+	if e.anyTimes {
+		return fmt.Sprintf("%s any times, called %d times", e.op, e.calls)
+	}
+	return fmt.Sprintf("%s %d times, called %d times", e.op, e.times, e.calls)
+}
+
+func decodeEvents(op types.Operation, events []byte) (any, bool) {
+	switch op {
+	case types.OperationCreateAccounts:
+		return decode[types.Account](events), true
+	case types.OperationCreateTransfers:
+		return decode[types.Transfer](events), true
+	case types.OperationLookupAccounts, types.OperationLookupTransfers:
+		return decode[types.Uint128](events), true
+	case types.OperationGetAccountTransfers, types.OperationGetAccountHistory:
+		return decode[types.AccountFilter](events), true
+	default:
+		return nil, false
+	}
+}
+
+// decode copies the events out of their wire layout.
+func decode[E any](events []byte) []E {
+	var event E
+	size := int(unsafe.Sizeof(event))
+	if len(events) < size {
+		return nil
+	}
+	return slices.Clone(unsafe.Slice((*E)(unsafe.Pointer(&events[0])), len(events)/size))
+}
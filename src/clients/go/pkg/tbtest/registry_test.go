@@ -0,0 +1,151 @@
+package tbtest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+	"unsafe"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// recorder captures the failures a registry reports instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper()          {}
+func (r *recorder) Cleanup(f func()) {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func encode[E any](events []E) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(&events[0])), len(events)*int(unsafe.Sizeof(events[0])))
+}
+
+func submitTransfers(registry *Registry, transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	reply := make([]types.TransferEventResult, len(transfers))
+	written, err := registry.Submit(types.OperationCreateTransfers, encode(transfers), encode(reply))
+	return reply[:written/int(unsafe.Sizeof(reply[0]))], err
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("returns programmed results", func(t *testing.T) {
+		r := &recorder{}
+		registry := NewRegistry(r, Strict)
+		registry.ExpectCreateTransfers(func(transfers []types.Transfer) bool {
+			return len(transfers) == 2
+		}).Return(types.TransferEventResult{Index: 1, Result: types.TransferExists})
+
+		results, err := submitTransfers(registry, make([]types.Transfer, 2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, results, 1)
+		assert.Equal(t, uint32(1), results[0].Index)
+		assert.Equal(t, types.TransferExists, results[0].Result)
+
+		registry.Verify()
+		assert.Empty(t, r.failures)
+		assert.Len(t, registry.Calls(), 1)
+	})
+
+	t.Run("strict fails unexpected calls", func(t *testing.T) {
+		r := &recorder{}
+		registry := NewRegistry(r, Strict)
+		registry.ExpectCreateTransfers(nil).Return()
+
+		_, err := submitTransfers(registry, make([]types.Transfer, 1))
+		assert.Equal(t, nil, err)
+		_, err = submitTransfers(registry, make([]types.Transfer, 1))
+		assert.Equal(t, ErrUnexpectedCall{Operation: types.OperationCreateTransfers}, err)
+		assert.Len(t, r.failures, 1)
+	})
+
+	t.Run("lenient ignores unexpected calls", func(t *testing.T) {
+		r := &recorder{}
+		registry := NewRegistry(r, Lenient)
+
+		results, err := submitTransfers(registry, make([]types.Transfer, 1))
+		assert.Equal(t, nil, err)
+		assert.Empty(t, results)
+		assert.Empty(t, r.failures)
+	})
+
+	t.Run("verifies call counts", func(t *testing.T) {
+		r := &recorder{}
+		registry := NewRegistry(r, Strict)
+		registry.ExpectCreateTransfers(nil).Times(2)
+		registry.ExpectLookupAccounts(nil).AnyTimes()
+
+		_, err := submitTransfers(registry, make([]types.Transfer, 1))
+		assert.Equal(t, nil, err)
+
+		registry.Verify()
+		assert.Len(t, r.failures, 1)
+	})
+
+	t.Run("returns errors", func(t *testing.T) {
+		r := &recorder{}
+		registry := NewRegistry(r, Strict)
+		registry.ExpectCreateTransfers(nil).ReturnError(ErrUnexpectedCall{})
+
+		_, err := submitTransfers(registry, make([]types.Transfer, 1))
+		assert.Equal(t, ErrUnexpectedCall{}, err)
+	})
+}
+
+func TestChaosClient(t *testing.T) {
+	newClient := func(config ChaosConfig) (*ChaosClient, *Registry) {
+		registry := NewRegistry(t, Lenient)
+		client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1,
+			tigerbeetle_go.WithTransport(registry))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(client.Close)
+		return NewChaosClient(client, config), registry
+	}
+	transfers := make([]types.Transfer, 1)
+
+	client, registry := newClient(ChaosConfig{EvictRate: 1})
+	_, err := client.CreateTransfers(transfers)
+	assert.Equal(t, errors.ErrSessionEvicted{Reason: "injected by tbtest.ChaosClient"}, err)
+	assert.Len(t, registry.Calls(), 0)
+
+	client, registry = newClient(ChaosConfig{DuplicateRate: 1})
+	if err := client.CreateTransfer(transfers[0]); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, registry.Calls(), 2)
+
+	// Half of the drops reach the cluster, and the same seed drops the same way.
+	dropped := func(seed int64) []bool {
+		client, registry := newClient(ChaosConfig{Seed: seed, DropRate: 1})
+		var submitted []bool
+		for range 20 {
+			calls := len(registry.Calls())
+			_, err := client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+			drop, ok := err.(ErrDropped)
+			assert.True(t, ok)
+			assert.Equal(t, drop.Submitted, len(registry.Calls()) > calls)
+			submitted = append(submitted, drop.Submitted)
+		}
+		return submitted
+	}
+	first := dropped(7)
+	assert.Equal(t, first, dropped(7))
+	assert.True(t, slices.Contains(first, true) && slices.Contains(first, false))
+
+	client, registry = newClient(ChaosConfig{Seed: 1, LatencyRate: 1, Latency: time.Millisecond})
+	if _, err := client.GetAccountTransfers(types.AccountFilter{Limit: 1}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, registry.Calls(), 1)
+}
@@ -0,0 +1,204 @@
+package tbtest
+
+import (
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// SimulatorConfig sets the faults of a Simulator. Rates are fractions of requests, between 0 and
+// 1, and a request suffers at most one fault, as with ChaosConfig.
+type SimulatorConfig struct {
+	// Seed decides the faults, the latencies and the values of Simulator.Rand. Zero seeds them
+	// from $TBTEST_SEED, or else from the time.
+	Seed int64
+
+	// Latency is the most the clock advances while a request is in flight.
+	Latency time.Duration
+	// EvictRate, DropRate and DuplicateRate are as for ChaosConfig.
+	EvictRate     float64
+	DropRate      float64
+	DuplicateRate float64
+}
+
+// FaultEvent is a fault that a Simulator injected.
+type FaultEvent struct {
+	// Request is the position of the faulty request among those submitted, from zero.
+	Request   int
+	Operation types.Operation
+	Fault     Fault
+	At        time.Time
+}
+
+// Simulator is a deterministic cluster for property-testing application logic in the spirit of
+// the VOPR: a Ledger on a virtual Clock, behind a schedule of faults drawn from a seed. A run
+// that submits its requests from a single goroutine replays exactly from its seed, with the
+// same faults, timestamps and results:
+//
+//	tbtest.Simulate(t, 100, tbtest.SimulatorConfig{DropRate: 0.1}, func(t *testing.T, sim *tbtest.Simulator) {
+//		client := sim.Client()
+//		// Drive the workflow under test with client, sim.Clock and sim.Rand().
+//	})
+//
+// The simulator is a transport, so that the client, and any middleware, runs as in production.
+type Simulator struct {
+	Clock  *Clock
+	Ledger *Ledger
+	Seed   int64
+
+	t      testing.TB
+	config SimulatorConfig
+
+	mutex    sync.Mutex
+	faults   *rand.Rand
+	workload *rand.Rand
+	requests int
+	events   []FaultEvent
+}
+
+// NewSimulator returns a simulator with an empty ledger. If the test fails, the seed is logged
+// for the run to be replayed with $TBTEST_SEED.
+func NewSimulator(t testing.TB, config SimulatorConfig) *Simulator {
+	seed := config.Seed
+	if seed == 0 {
+		seed = seedFromEnv()
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	clock := NewClock(time.Time{})
+	s := &Simulator{
+		Clock:  clock,
+		Ledger: NewLedger(clock),
+		Seed:   seed,
+		t:      t,
+		config: config,
+		faults: rand.New(rand.NewSource(seed)),
+		// The workload draws from a source of its own, for changing it not to move the faults.
+		workload: rand.New(rand.NewSource(seed ^ 0x5eed)),
+	}
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("tbtest: replay the simulation with TBTEST_SEED=%d", seed)
+		}
+	})
+	return s
+}
+
+func seedFromEnv() int64 {
+	seed, _ := strconv.ParseInt(os.Getenv("TBTEST_SEED"), 10, 64)
+	return seed
+}
+
+// Simulate runs run as a subtest for each of seeds seeds, each with a simulator of its own, and
+// verifies the invariants of its ledger once run returns. With $TBTEST_SEED set, it only runs
+// that seed, to replay a failure. config.Seed, if set, is the first seed.
+func Simulate(t *testing.T, seeds int, config SimulatorConfig, run func(t *testing.T, sim *Simulator)) {
+	t.Helper()
+
+	first := config.Seed
+	if first == 0 {
+		first = 1
+	}
+	if seed := seedFromEnv(); seed != 0 {
+		first, seeds = seed, 1
+	}
+
+	for seed := first; seed < first+int64(seeds); seed++ {
+		t.Run("seed="+strconv.FormatInt(seed, 10), func(t *testing.T) {
+			config := config
+			config.Seed = seed
+			sim := NewSimulator(t, config)
+			run(t, sim)
+			if err := sim.Ledger.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Client returns a client that submits to the simulator, closed once the test completes.
+func (s *Simulator) Client(options ...tigerbeetle_go.ClientOption) tigerbeetle_go.Client {
+	s.t.Helper()
+
+	options = append([]tigerbeetle_go.ClientOption{tigerbeetle_go.WithTransport(s)}, options...)
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1, options...)
+	if err != nil {
+		s.t.Fatalf("tbtest: creating client: %s", err)
+	}
+	s.t.Cleanup(client.Close)
+	return client
+}
+
+// Rand returns the random source of the workload, seeded from the seed of the simulator. It
+// is not safe for concurrent use.
+func (s *Simulator) Rand() *rand.Rand {
+	return s.workload
+}
+
+// Faults returns the faults injected so far, in order.
+func (s *Simulator) Faults() []FaultEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]FaultEvent(nil), s.events...)
+}
+
+// next draws the fault of a request of op, and advances the clock by its latency.
+func (s *Simulator) next(op types.Operation) Fault {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.config.Latency > 0 {
+		s.Clock.Advance(time.Duration(s.faults.Int63n(int64(s.config.Latency))))
+	}
+
+	fault := FaultNone
+	draw := s.faults.Float64()
+	switch {
+	case draw < s.config.EvictRate:
+		fault = FaultEvict
+	case draw < s.config.EvictRate+s.config.DropRate/2:
+		fault = FaultDropRequest
+	case draw < s.config.EvictRate+s.config.DropRate:
+		fault = FaultDropReply
+	case draw < s.config.EvictRate+s.config.DropRate+s.config.DuplicateRate:
+		fault = FaultDuplicate
+	}
+	if fault != FaultNone {
+		s.events = append(s.events, FaultEvent{
+			Request:   s.requests,
+			Operation: op,
+			Fault:     fault,
+			At:        s.Clock.Now(),
+		})
+	}
+	s.requests++
+	return fault
+}
+
+func (s *Simulator) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	switch s.next(op) {
+	case FaultEvict:
+		return 0, errors.ErrSessionEvicted{Reason: "injected by tbtest.Simulator"}
+	case FaultDropRequest:
+		return 0, ErrDropped{Operation: op}
+	case FaultDropReply:
+		_, _ = s.Ledger.Submit(op, events, reply)
+		return 0, ErrDropped{Operation: op, Submitted: true}
+	case FaultDuplicate:
+		if _, err := s.Ledger.Submit(op, events, reply); err != nil {
+			return 0, err
+		}
+	}
+	return s.Ledger.Submit(op, events, reply)
+}
+
+func (s *Simulator) Close() {}
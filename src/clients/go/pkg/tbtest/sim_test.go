@@ -0,0 +1,219 @@
+package tbtest
+
+import (
+	e "errors"
+	"slices"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func ledgerClient(t *testing.T, clock *Clock) (tigerbeetle_go.Client, *Ledger) {
+	ledger := NewLedger(clock)
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1, tigerbeetle_go.WithTransport(ledger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client, ledger
+}
+
+func TestLedger(t *testing.T) {
+	limited := types.AccountFlags{DebitsMustNotExceedCredits: true, History: true}.ToUint16()
+	accounts := []types.Account{
+		{ID: types.ToUint128(1), Ledger: 1, Code: 1, Flags: limited},
+		{ID: types.ToUint128(2), Ledger: 1, Code: 1},
+		{ID: types.ToUint128(3), Ledger: 2, Code: 1},
+	}
+
+	t.Run("creates accounts and transfers", func(t *testing.T) {
+		client, ledger := ledgerClient(t, NewClock(time.Time{}))
+		results, err := client.CreateAccounts(accounts)
+		assert.Equal(t, err, nil)
+		assert.Empty(t, results)
+
+		results, _ = client.CreateAccounts([]types.Account{accounts[0], {ID: types.ToUint128(2), Ledger: 1, Code: 2}})
+		assert.Equal(t, results, []types.AccountEventResult{
+			{Index: 0, Result: types.AccountExists},
+			{Index: 1, Result: types.AccountExistsWithDifferentCode},
+		})
+
+		transfers, _ := client.CreateTransfers([]types.Transfer{
+			{ID: types.ToUint128(10), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1), Amount: types.ToUint128(100), Ledger: 1, Code: 1},
+			{ID: types.ToUint128(11), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2), Amount: types.ToUint128(101), Ledger: 1, Code: 1},
+			{ID: types.ToUint128(12), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(3), Amount: types.ToUint128(1), Ledger: 1, Code: 1},
+			{ID: types.ToUint128(10), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1), Amount: types.ToUint128(100), Ledger: 1, Code: 1},
+		})
+		assert.Equal(t, transfers, []types.TransferEventResult{
+			{Index: 1, Result: types.TransferExceedsCredits},
+			{Index: 2, Result: types.TransferAccountsMustHaveTheSameLedger},
+			{Index: 3, Result: types.TransferExists},
+		})
+
+		found, _ := client.LookupAccounts([]types.Uint128{types.ToUint128(1), types.ToUint128(9)})
+		assert.Len(t, found, 1)
+		assert.Equal(t, found[0].CreditsPosted, types.ToUint128(100))
+		assert.Equal(t, ledger.Verify(), nil)
+	})
+
+	t.Run("rolls back failed linked chains", func(t *testing.T) {
+		client, ledger := ledgerClient(t, NewClock(time.Time{}))
+		client.CreateAccounts(accounts)
+
+		linked := types.TransferFlags{Linked: true}.ToUint16()
+		results, _ := client.CreateTransfers([]types.Transfer{
+			{ID: types.ToUint128(10), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1), Amount: types.ToUint128(5), Ledger: 1, Code: 1, Flags: linked},
+			{ID: types.ToUint128(11), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2), Amount: types.ToUint128(6), Ledger: 1, Code: 1},
+			{ID: types.ToUint128(12), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1), Amount: types.ToUint128(7), Ledger: 1, Code: 1, Flags: linked},
+		})
+		assert.Equal(t, results, []types.TransferEventResult{
+			{Index: 0, Result: types.TransferLinkedEventFailed},
+			{Index: 1, Result: types.TransferExceedsCredits},
+			{Index: 2, Result: types.TransferLinkedEventChainOpen},
+		})
+		assert.Empty(t, ledger.Transfers())
+		assert.Equal(t, ledger.Accounts()[0].CreditsPosted, types.ToUint128(0))
+	})
+
+	t.Run("posts, voids and expires pending transfers", func(t *testing.T) {
+		clock := NewClock(time.Time{})
+		client, ledger := ledgerClient(t, clock)
+		client.CreateAccounts(accounts)
+
+		pending := types.TransferFlags{Pending: true}.ToUint16()
+		results, _ := client.CreateTransfers([]types.Transfer{
+			{ID: types.ToUint128(10), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1), Amount: types.ToUint128(50), Ledger: 1, Code: 1, Flags: pending},
+			{ID: types.ToUint128(11), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1), Amount: types.ToUint128(50), Ledger: 1, Code: 1, Flags: pending},
+			{ID: types.ToUint128(12), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1), Amount: types.ToUint128(50), Ledger: 1, Code: 1, Flags: pending, Timeout: 60},
+		})
+		assert.Empty(t, results)
+		assert.Equal(t, ledger.Verify(), nil)
+
+		assert.Equal(t, client.PostPending(types.ToUint128(10), types.ToUint128(20)), nil)
+		assert.Equal(t, client.VoidPending(types.ToUint128(11)), nil)
+		assert.Equal(
+			t,
+			client.VoidPending(types.ToUint128(10)),
+			error(errors.ErrCreateTransfer{Result: types.TransferPendingTransferAlreadyPosted}),
+		)
+
+		clock.Advance(61 * time.Second)
+		assert.Equal(
+			t,
+			client.PostPending(types.ToUint128(12), types.Uint128{}),
+			error(errors.ErrCreateTransfer{Result: types.TransferPendingTransferExpired}),
+		)
+
+		account, _, _ := client.LookupAccount(types.ToUint128(1))
+		assert.Equal(t, account.CreditsPending, types.ToUint128(0))
+		assert.Equal(t, account.CreditsPosted, types.ToUint128(20))
+		assert.Equal(t, ledger.Verify(), nil)
+
+		filter, _ := types.NewAccountFilter(types.ToUint128(1)).Credits().Reversed().Build()
+		transfers, _ := client.GetAccountTransfers(filter)
+		ids := make([]types.Uint128, len(transfers))
+		for i, transfer := range transfers {
+			ids[i] = transfer.ID
+		}
+		assert.Equal(t, ids[len(ids)-3:], []types.Uint128{types.ToUint128(12), types.ToUint128(11), types.ToUint128(10)})
+
+		history, _ := client.GetAccountHistory(filter)
+		assert.Len(t, history, 5)
+		assert.Equal(t, history[0].CreditsPosted, types.ToUint128(20))
+	})
+
+	t.Run("balances transfers", func(t *testing.T) {
+		client, _ := ledgerClient(t, NewClock(time.Time{}))
+		client.CreateAccounts(accounts)
+		client.CreateTransfer(types.Transfer{
+			ID: types.ToUint128(10), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1),
+			Amount: types.ToUint128(30), Ledger: 1, Code: 1,
+		})
+
+		sweep := types.NewBalancingTransfer(
+			types.BalancingDebit, types.ToUint128(1), types.ToUint128(2), types.AmountMax, 1, 1,
+		)
+		assert.Equal(t, client.CreateTransfer(sweep), nil)
+		transfers, _ := client.LookupTransfers([]types.Uint128{sweep.ID})
+		assert.Equal(t, transfers[0].Amount, types.ToUint128(30))
+	})
+}
+
+// transferWithRetries creates a transfer as an application would, retrying the requests that
+// failed without an answer, and tells whether the cluster created it.
+func transferWithRetries(client tigerbeetle_go.Client, transfer types.Transfer) bool {
+	for {
+		err := client.CreateTransfer(transfer)
+		var dropped ErrDropped
+		var evicted errors.ErrSessionEvicted
+		if e.As(err, &dropped) || e.As(err, &evicted) {
+			continue
+		}
+		var failed errors.ErrCreateTransfer
+		if e.As(err, &failed) && failed.Result == types.TransferExists {
+			return true
+		}
+		return err == nil
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	config := SimulatorConfig{Latency: time.Second, EvictRate: 0.05, DropRate: 0.2, DuplicateRate: 0.1}
+
+	Simulate(t, 20, config, func(t *testing.T, sim *Simulator) {
+		client := sim.Client()
+		for {
+			results, err := client.CreateAccounts([]types.Account{
+				{ID: types.ToUint128(1), Ledger: 1, Code: 1, Flags: types.AccountFlags{DebitsMustNotExceedCredits: true}.ToUint16()},
+				{ID: types.ToUint128(2), Ledger: 1, Code: 1},
+			})
+			if err == nil && (len(results) == 0 || results[0].Result == types.AccountExists) {
+				break
+			}
+		}
+
+		var created uint64
+		for i := range 50 {
+			amount := uint64(sim.Rand().Intn(10) + 1)
+			transfer := types.Transfer{
+				ID: types.ToUint128(uint64(100 + i)), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(1),
+				Amount: types.ToUint128(amount), Ledger: 1, Code: 1,
+			}
+			if transferWithRetries(client, transfer) {
+				created += amount
+			}
+		}
+
+		accounts := sim.Ledger.Accounts()
+		assert.Equal(t, accounts[0].CreditsPosted, types.ToUint128(created))
+		assert.Len(t, sim.Ledger.Transfers(), 50)
+	})
+}
+
+func TestSimulatorDeterminism(t *testing.T) {
+	run := func() ([]FaultEvent, []types.Transfer) {
+		sim := NewSimulator(t, SimulatorConfig{Seed: 42, Latency: time.Second, DropRate: 0.3, DuplicateRate: 0.2})
+		client := sim.Client()
+		client.CreateAccounts([]types.Account{
+			{ID: types.ToUint128(1), Ledger: 1, Code: 1},
+			{ID: types.ToUint128(2), Ledger: 1, Code: 1},
+		})
+		for i := range 20 {
+			transferWithRetries(client, types.Transfer{
+				ID: types.ToUint128(uint64(100 + i)), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2),
+				Amount: types.ToUint128(uint64(sim.Rand().Intn(100) + 1)), Ledger: 1, Code: 1,
+			})
+		}
+		return sim.Faults(), sim.Ledger.Transfers()
+	}
+
+	faults, transfers := run()
+	replayedFaults, replayedTransfers := run()
+	assert.True(t, len(faults) > 0)
+	assert.True(t, slices.Equal(faults, replayedFaults))
+	assert.True(t, slices.Equal(transfers, replayedTransfers))
+}
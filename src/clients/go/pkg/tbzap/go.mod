@@ -0,0 +1,12 @@
+module github.com/tigerbeetle/tigerbeetle-go/pkg/tbzap
+
+go 1.23.0
+
+require (
+	github.com/tigerbeetle/tigerbeetle-go v0.0.0
+	go.uber.org/zap v1.27.0
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
+
+replace github.com/tigerbeetle/tigerbeetle-go => ../../
@@ -0,0 +1,150 @@
+// Package tbzap sends the logs of clients to a zap logger, for applications that log with zap:
+//
+//	client, err := tigerbeetle_go.NewClient(clusterID, addresses, 32,
+//		tigerbeetle_go.WithLogger(tbzap.NewLogger(logger)))
+//
+// The logs of tb_client keep their level, from its levels err, warn, info and debug to
+// zap's error, warn, info and debug, and are tagged with a source field of tb_client.
+package tbzap
+
+import (
+	"context"
+	"log/slog"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewLogger returns a logger for tigerbeetle_go.WithLogger that logs to logger.
+func NewLogger(logger *zap.Logger) *slog.Logger {
+	return slog.New(NewHandler(logger.Core()))
+}
+
+// NewHandler returns a slog.Handler that writes to core. Groups of attributes are nested
+// objects.
+func NewHandler(core zapcore.Core) slog.Handler {
+	return &handler{core: core}
+}
+
+type handler struct {
+	core zapcore.Core
+	// groups are the groups opened by WithGroup that have yet to be given attributes, as slog
+	// leaves out groups without any.
+	groups []string
+}
+
+// Level returns the zap level of a slog level. Levels between those of slog are rounded down,
+// and those below slog.LevelDebug are debug, as zap has no lower level.
+func Level(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	}
+	return zapcore.DebugLevel
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.core.Enabled(Level(level))
+}
+
+func (h *handler) Handle(_ context.Context, record slog.Record) error {
+	entry := zapcore.Entry{Level: Level(record.Level), Time: record.Time, Message: record.Message}
+	checked := h.core.Check(entry, nil)
+	if checked == nil {
+		return nil
+	}
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	checked.Write(h.fields(attrs)...)
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := h.fields(attrs)
+	if len(fields) == 0 {
+		return h
+	}
+	return &handler{core: h.core.With(fields)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &handler{core: h.core, groups: append(h.groups[:len(h.groups):len(h.groups)], name)}
+}
+
+// fields returns the fields of attrs, after a namespace for each group opened by WithGroup if
+// there are any, which nests them and the fields added after them.
+func (h *handler) fields(attrs []slog.Attr) []zapcore.Field {
+	fields := make([]zapcore.Field, 0, len(h.groups)+len(attrs))
+	for _, name := range h.groups {
+		fields = append(fields, zap.Namespace(name))
+	}
+	for _, attr := range attrs {
+		if field, ok := fieldOf(attr); ok {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == len(h.groups) {
+		return nil
+	}
+	return fields
+}
+
+// fieldOf returns the field of attr, or false if slog leaves it out: if it is empty, or an
+// empty group.
+func fieldOf(attr slog.Attr) (zapcore.Field, bool) {
+	value := attr.Value.Resolve()
+	if attr.Key == "" && value.Kind() != slog.KindGroup {
+		return zapcore.Field{}, false
+	}
+	switch value.Kind() {
+	case slog.KindBool:
+		return zap.Bool(attr.Key, value.Bool()), true
+	case slog.KindDuration:
+		return zap.Duration(attr.Key, value.Duration()), true
+	case slog.KindFloat64:
+		return zap.Float64(attr.Key, value.Float64()), true
+	case slog.KindInt64:
+		return zap.Int64(attr.Key, value.Int64()), true
+	case slog.KindString:
+		return zap.String(attr.Key, value.String()), true
+	case slog.KindTime:
+		return zap.Time(attr.Key, value.Time()), true
+	case slog.KindUint64:
+		return zap.Uint64(attr.Key, value.Uint64()), true
+	case slog.KindGroup:
+		attrs := value.Group()
+		if len(attrs) == 0 {
+			return zapcore.Field{}, false
+		}
+		if attr.Key == "" {
+			return zap.Inline(group(attrs)), true
+		}
+		return zap.Object(attr.Key, group(attrs)), true
+	}
+	if err, ok := value.Any().(error); ok {
+		return zap.NamedError(attr.Key, err), true
+	}
+	return zap.Any(attr.Key, value.Any()), true
+}
+
+// group marshals a group of attributes as an object.
+type group []slog.Attr
+
+func (g group) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	for _, attr := range g {
+		if field, ok := fieldOf(attr); ok {
+			field.AddTo(encoder)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package tbzap
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+)
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, zapcore.DebugLevel, Level(slog.LevelDebug-4))
+	assert.Equal(t, zapcore.DebugLevel, Level(slog.LevelDebug))
+	assert.Equal(t, zapcore.InfoLevel, Level(slog.LevelInfo))
+	assert.Equal(t, zapcore.InfoLevel, Level(slog.LevelInfo+2))
+	assert.Equal(t, zapcore.WarnLevel, Level(slog.LevelWarn))
+	assert.Equal(t, zapcore.ErrorLevel, Level(slog.LevelError))
+	assert.Equal(t, zapcore.ErrorLevel, Level(slog.LevelError+4))
+}
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := NewLogger(zap.New(core))
+
+	logger.Debug("left out")
+	logger.Warn("request failed", "operation", "CreateTransfers", "attempt", 2, "error", errors.New("timeout"))
+	logger.With("client", 1).WithGroup("request").WithGroup("empty").Info("grouped")
+	logger.WithGroup("request").With("operation", "LookupAccounts").Error("failed",
+		slog.Group("batch", "size", uint64(8)), slog.Group("none"))
+
+	entries := logs.All()
+	assert.Len(t, entries, 3)
+
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Equal(t, "request failed", entries[0].Message)
+	assert.Equal(t, map[string]any{
+		"operation": "CreateTransfers",
+		"attempt":   int64(2),
+		"error":     "timeout",
+	}, entries[0].ContextMap())
+
+	// Groups without attributes are left out.
+	assert.Equal(t, "grouped", entries[1].Message)
+	assert.Equal(t, map[string]any{"client": int64(1)}, entries[1].ContextMap())
+
+	assert.Equal(t, zapcore.ErrorLevel, entries[2].Level)
+	assert.Equal(t, map[string]any{
+		"request": map[string]any{
+			"operation": "LookupAccounts",
+			"batch":     map[string]any{"size": uint64(8)},
+		},
+	}, entries[2].ContextMap())
+}
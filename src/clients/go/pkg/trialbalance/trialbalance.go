@@ -0,0 +1,132 @@
+// Package trialbalance checks the integrity of a ledger from the balances of its accounts.
+//
+// Every transfer debits one account and credits another by the same amount on the same ledger,
+// so across all the accounts of a ledger the debits must equal the credits, both posted and
+// pending. An account whose flags limit its balance must also stay within that limit.
+//
+// The checks only hold over every account of the ledger: an account left out of the scan shows
+// up as a ledger that doesn't balance.
+package trialbalance
+
+import (
+	"iter"
+	"math/big"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Check names a check that the accounts of a ledger must pass.
+type Check string
+
+const (
+	// CheckPostedBalance is the total of the posted debits against that of the posted credits.
+	CheckPostedBalance Check = "posted_balance"
+	// CheckPendingBalance is the total of the pending debits against that of the pending
+	// credits.
+	CheckPendingBalance Check = "pending_balance"
+	// CheckDebitsMustNotExceedCredits is an account with the flag whose posted and pending
+	// debits exceed its posted credits.
+	CheckDebitsMustNotExceedCredits Check = "debits_must_not_exceed_credits"
+	// CheckCreditsMustNotExceedDebits is an account with the flag whose posted and pending
+	// credits exceed its posted debits.
+	CheckCreditsMustNotExceedDebits Check = "credits_must_not_exceed_debits"
+)
+
+// Violation is an account whose balance exceeds the limit set by its flags.
+type Violation struct {
+	Check     Check         `json:"check"`
+	AccountID types.Uint128 `json:"account_id"`
+	// Debits and Credits are the sides of the balance that were compared, with the pending
+	// amounts included on the side that is limited.
+	Debits  *big.Int `json:"debits"`
+	Credits *big.Int `json:"credits"`
+}
+
+// Report is the outcome of checking the accounts of a ledger.
+type Report struct {
+	Ledger uint32 `json:"ledger"`
+	// Accounts is how many accounts of the ledger were checked, and Skipped how many accounts
+	// of other ledgers were ignored.
+	Accounts uint64 `json:"accounts"`
+	Skipped  uint64 `json:"skipped"`
+
+	DebitsPosted   *big.Int `json:"debits_posted"`
+	CreditsPosted  *big.Int `json:"credits_posted"`
+	DebitsPending  *big.Int `json:"debits_pending"`
+	CreditsPending *big.Int `json:"credits_pending"`
+
+	Violations []Violation `json:"violations"`
+}
+
+// PostedBalanced reports whether the posted debits equal the posted credits.
+func (r *Report) PostedBalanced() bool {
+	return r.DebitsPosted.Cmp(r.CreditsPosted) == 0
+}
+
+// PendingBalanced reports whether the pending debits equal the pending credits.
+func (r *Report) PendingBalanced() bool {
+	return r.DebitsPending.Cmp(r.CreditsPending) == 0
+}
+
+// OK reports whether the ledger passed every check.
+func (r *Report) OK() bool {
+	return r.PostedBalanced() && r.PendingBalanced() && len(r.Violations) == 0
+}
+
+// Verify checks the accounts of ledger, ignoring those of other ledgers. It stops at the first
+// error from accounts, returning the report so far. Totals are kept as big integers, since the
+// balances of a ledger may add up past 128 bits.
+func Verify(ledger uint32, accounts iter.Seq2[types.Account, error]) (Report, error) {
+	report := Report{
+		Ledger:         ledger,
+		DebitsPosted:   new(big.Int),
+		CreditsPosted:  new(big.Int),
+		DebitsPending:  new(big.Int),
+		CreditsPending: new(big.Int),
+		Violations:     []Violation{},
+	}
+	for account, err := range accounts {
+		if err != nil {
+			return report, err
+		}
+		if account.Ledger != ledger {
+			report.Skipped++
+			continue
+		}
+		report.Accounts++
+
+		debitsPosted := account.DebitsPosted.BigInt()
+		creditsPosted := account.CreditsPosted.BigInt()
+		debitsPending := account.DebitsPending.BigInt()
+		creditsPending := account.CreditsPending.BigInt()
+		report.DebitsPosted.Add(report.DebitsPosted, &debitsPosted)
+		report.CreditsPosted.Add(report.CreditsPosted, &creditsPosted)
+		report.DebitsPending.Add(report.DebitsPending, &debitsPending)
+		report.CreditsPending.Add(report.CreditsPending, &creditsPending)
+
+		flags := account.AccountFlags()
+		if flags.DebitsMustNotExceedCredits {
+			debits := new(big.Int).Add(&debitsPosted, &debitsPending)
+			if debits.Cmp(&creditsPosted) > 0 {
+				report.Violations = append(report.Violations, Violation{
+					Check:     CheckDebitsMustNotExceedCredits,
+					AccountID: account.ID,
+					Debits:    debits,
+					Credits:   &creditsPosted,
+				})
+			}
+		}
+		if flags.CreditsMustNotExceedDebits {
+			credits := new(big.Int).Add(&creditsPosted, &creditsPending)
+			if credits.Cmp(&debitsPosted) > 0 {
+				report.Violations = append(report.Violations, Violation{
+					Check:     CheckCreditsMustNotExceedDebits,
+					AccountID: account.ID,
+					Debits:    &debitsPosted,
+					Credits:   credits,
+				})
+			}
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,104 @@
+package trialbalance
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func accounts(list ...types.Account) func(yield func(types.Account, error) bool) {
+	return func(yield func(types.Account, error) bool) {
+		for _, account := range list {
+			if !yield(account, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	debitsLimited := types.AccountFlags{DebitsMustNotExceedCredits: true}.ToUint16()
+	creditsLimited := types.AccountFlags{CreditsMustNotExceedDebits: true}.ToUint16()
+	report, err := Verify(1, accounts(
+		types.Account{
+			ID:            types.ToUint128(1),
+			Ledger:        1,
+			DebitsPosted:  types.ToUint128(100),
+			DebitsPending: types.ToUint128(10),
+			Flags:         creditsLimited,
+		},
+		types.Account{
+			ID:             types.ToUint128(2),
+			Ledger:         1,
+			CreditsPosted:  types.ToUint128(100),
+			CreditsPending: types.ToUint128(10),
+			Flags:          debitsLimited,
+		},
+		types.Account{ID: types.ToUint128(3), Ledger: 2, DebitsPosted: types.ToUint128(5)},
+	))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(2), report.Accounts)
+	assert.Equal(t, uint64(1), report.Skipped)
+	assert.Equal(t, "100", report.DebitsPosted.String())
+	assert.Equal(t, "10", report.CreditsPending.String())
+	assert.True(t, report.OK())
+
+	// Balances that exceed their limit, and a ledger that doesn't balance.
+	report, err = Verify(1, accounts(
+		types.Account{
+			ID:            types.ToUint128(1),
+			Ledger:        1,
+			DebitsPosted:  types.ToUint128(100),
+			DebitsPending: types.ToUint128(10),
+			CreditsPosted: types.ToUint128(100),
+			Flags:         debitsLimited,
+		},
+		types.Account{
+			ID:            types.ToUint128(2),
+			Ledger:        1,
+			CreditsPosted: types.ToUint128(100),
+			Flags:         creditsLimited,
+		},
+	))
+	assert.Equal(t, nil, err)
+	assert.True(t, !report.PostedBalanced())
+	assert.True(t, !report.PendingBalanced())
+	assert.Equal(t, 2, len(report.Violations))
+	assert.Equal(t, CheckDebitsMustNotExceedCredits, report.Violations[0].Check)
+	assert.Equal(t, types.ToUint128(1), report.Violations[0].AccountID)
+	assert.Equal(t, "110", report.Violations[0].Debits.String())
+	assert.Equal(t, CheckCreditsMustNotExceedDebits, report.Violations[1].Check)
+	assert.Equal(t, types.ToUint128(2), report.Violations[1].AccountID)
+	assert.Equal(t, "100", report.Violations[1].Credits.String())
+
+	encoded, err := json.Marshal(report.Violations[0])
+	assert.Equal(t, nil, err)
+	assert.Equal(t,
+		`{"check":"debits_must_not_exceed_credits","account_id":"1","debits":110,"credits":100}`,
+		string(encoded))
+
+	// Totals past 128 bits.
+	max := types.BigIntToUint128(*new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1)))
+	report, err = Verify(1, accounts(
+		types.Account{ID: types.ToUint128(1), Ledger: 1, DebitsPosted: max},
+		types.Account{ID: types.ToUint128(2), Ledger: 1, DebitsPosted: max},
+		types.Account{ID: types.ToUint128(3), Ledger: 1, CreditsPosted: max},
+		types.Account{ID: types.ToUint128(4), Ledger: 1, CreditsPosted: max},
+	))
+	assert.Equal(t, nil, err)
+	assert.True(t, report.PostedBalanced())
+	assert.Equal(t, 129, report.DebitsPosted.BitLen())
+
+	failure := errors.New("lookup failed")
+	report, err = Verify(1, func(yield func(types.Account, error) bool) {
+		if yield(types.Account{Ledger: 1}, nil) {
+			yield(types.Account{}, failure)
+		}
+	})
+	assert.Equal(t, failure, err)
+	assert.Equal(t, uint64(1), report.Accounts)
+}
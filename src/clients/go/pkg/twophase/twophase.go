@@ -0,0 +1,223 @@
+// Package twophase runs reserve, then capture or void sagas on two-phase transfers: a pending
+// transfer reserves an amount, which is then captured by posting it, or released by voiding it.
+//
+// Every saga is recorded before its pending transfer is created, with the IDs of the transfers
+// that may capture or void it fixed up front, so that any step can be retried, or resumed from
+// the record after a crash, without ever capturing or voiding twice.
+package twophase
+
+import (
+	e "errors"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const (
+	// attemptsDefault is how many times a request is submitted by default.
+	attemptsDefault = 5
+	// backoffDefault is the default wait before the second attempt of a request.
+	backoffDefault = 100 * time.Millisecond
+)
+
+var (
+	// ErrExpired is returned when capturing a reservation that the cluster expired.
+	ErrExpired = e.New("twophase: pending transfer expired")
+	// ErrVoided is returned when capturing a reservation that was voided.
+	ErrVoided = e.New("twophase: pending transfer was voided")
+	// ErrPosted is returned when voiding a reservation that was posted, or when capturing one
+	// that was posted by another transfer than the saga's.
+	ErrPosted = e.New("twophase: pending transfer was posted")
+	// ErrNotReserved is returned for the status of a saga whose pending transfer was never
+	// created.
+	ErrNotReserved = e.New("twophase: pending transfer does not exist")
+)
+
+// Client is the part of the TigerBeetle client that sagas submit to.
+type Client interface {
+	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
+	LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error)
+}
+
+// Config configures a Coordinator. Zero fields take their defaults.
+type Config struct {
+	// Persist saves the record of a saga before its pending transfer is created, such as in the
+	// database of the application, for Resume to pick the saga up after a crash. A saga whose
+	// record fails to persist is not started. Required.
+	Persist func(record Record) error
+	// Attempts is how many times a request that fails without results is submitted before
+	// giving up. Defaults to 5.
+	Attempts int
+	// Backoff is the wait before the second attempt, doubled before every later one. Defaults
+	// to 100ms.
+	Backoff time.Duration
+}
+
+// Record is what a saga needs to be resumed.
+type Record struct {
+	// Pending is the pending transfer, as submitted.
+	Pending types.Transfer
+	// CaptureID and VoidID are the IDs of the transfers that post or void Pending.
+	CaptureID types.Uint128
+	VoidID    types.Uint128
+	// ExpiresAt estimates when the cluster expires Pending, no later than it does, or is zero if
+	// Pending has no timeout.
+	ExpiresAt time.Time
+}
+
+// Coordinator starts and resumes sagas on a client. It is safe for concurrent use.
+type Coordinator struct {
+	client Client
+	config Config
+}
+
+// New returns a coordinator of sagas submitting to client.
+func New(client Client, config Config) *Coordinator {
+	if config.Persist == nil {
+		panic("twophase: Config.Persist is required")
+	}
+	if config.Attempts <= 0 {
+		config.Attempts = attemptsDefault
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = backoffDefault
+	}
+	return &Coordinator{client: client, config: config}
+}
+
+// Saga is a reservation to capture or void. Its steps are idempotent: a step that failed with
+// an error other than one of this package may be retried, and repeating a step that succeeded
+// succeeds again.
+type Saga struct {
+	coordinator *Coordinator
+	record      Record
+}
+
+// Reserve records the saga of transfer, then creates transfer as a pending transfer. A zero ID
+// is replaced with a fresh one. If creating transfer fails, the record is left for Resume to
+// void, which releases nothing if transfer was never created.
+func (c *Coordinator) Reserve(transfer types.Transfer) (*Saga, error) {
+	if transfer.ID == (types.Uint128{}) {
+		transfer.ID = types.ID()
+	}
+	flags := transfer.TransferFlags()
+	flags.Pending = true
+	transfer.Flags = flags.ToUint16()
+
+	record := Record{Pending: transfer, CaptureID: types.ID(), VoidID: types.ID()}
+	if transfer.Timeout > 0 {
+		// The transfer is timestamped once created, after now, so its expiry is no earlier.
+		record.ExpiresAt = time.Now().Add(time.Duration(transfer.Timeout) * time.Second)
+	}
+	if err := c.config.Persist(record); err != nil {
+		return nil, err
+	}
+
+	result, err := c.submit(transfer)
+	if err != nil {
+		return nil, err
+	}
+	if result != types.TransferOK && result != types.TransferExists {
+		return nil, errors.ErrCreateTransfer{Result: result}
+	}
+	return &Saga{coordinator: c, record: record}, nil
+}
+
+// Resume returns the saga of record, as persisted by Reserve, to capture or void it. It must
+// not be used while the saga may still be reserving.
+func (c *Coordinator) Resume(record Record) *Saga {
+	return &Saga{coordinator: c, record: record}
+}
+
+// Record returns the record of the saga.
+func (s *Saga) Record() Record {
+	return s.record
+}
+
+// Capture posts amount of the reservation, as PostPendingTransfer does. It fails with
+// ErrExpired if the reservation expired, with ErrVoided if it was voided, and with ErrPosted if
+// another transfer posted it.
+func (s *Saga) Capture(amount types.Uint128) error {
+	post := types.PostPendingTransfer(s.record.Pending.ID, amount)
+	post.ID = s.record.CaptureID
+
+	result, err := s.coordinator.submit(post)
+	if err != nil {
+		return err
+	}
+	switch result {
+	case types.TransferOK, types.TransferExists:
+		return nil
+	case types.TransferPendingTransferExpired:
+		return ErrExpired
+	case types.TransferPendingTransferAlreadyVoided:
+		return ErrVoided
+	case types.TransferPendingTransferAlreadyPosted:
+		return ErrPosted
+	}
+	return errors.ErrCreateTransfer{Result: result}
+}
+
+// Void releases the reservation. It succeeds if the reservation was already released, whether
+// voided, expired or never created, and fails with ErrPosted if it was captured.
+func (s *Saga) Void() error {
+	void := types.VoidPendingTransfer(s.record.Pending.ID)
+	void.ID = s.record.VoidID
+
+	result, err := s.coordinator.submit(void)
+	if err != nil {
+		return err
+	}
+	switch result {
+	case types.TransferOK,
+		types.TransferExists,
+		types.TransferPendingTransferAlreadyVoided,
+		types.TransferPendingTransferExpired,
+		types.TransferPendingTransferNotFound:
+		return nil
+	case types.TransferPendingTransferAlreadyPosted:
+		return ErrPosted
+	}
+	return errors.ErrCreateTransfer{Result: result}
+}
+
+// Status looks up where the reservation is in its lifecycle, as types.TransferState tells it
+// from the transfers of the saga. It fails with ErrNotReserved if the pending transfer was never
+// created.
+func (s *Saga) Status() (types.TransferStatus, error) {
+	transfers, err := s.coordinator.client.LookupTransfers([]types.Uint128{
+		s.record.Pending.ID,
+		s.record.CaptureID,
+		s.record.VoidID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for i, transfer := range transfers {
+		if transfer.ID == s.record.Pending.ID {
+			related := append(transfers[:i:i], transfers[i+1:]...)
+			return types.TransferState(transfer, related), nil
+		}
+	}
+	return 0, ErrNotReserved
+}
+
+// submit creates transfer, retrying with backoff while the request fails without a result.
+func (c *Coordinator) submit(transfer types.Transfer) (types.CreateTransferResult, error) {
+	backoff := c.config.Backoff
+	for attempt := 1; ; attempt++ {
+		results, err := c.client.CreateTransfers([]types.Transfer{transfer})
+		if err == nil {
+			if len(results) == 0 {
+				return types.TransferOK, nil
+			}
+			return results[0].Result, nil
+		}
+		if attempt == c.config.Attempts {
+			return 0, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
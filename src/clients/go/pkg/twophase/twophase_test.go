@@ -0,0 +1,96 @@
+package twophase
+
+import (
+	e "errors"
+	"testing"
+	"time"
+
+	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/tbtest"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// flakyClient fails every other request without submitting it.
+type flakyClient struct {
+	Client
+	calls int
+}
+
+func (c *flakyClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	c.calls++
+	if c.calls%2 == 1 {
+		return nil, e.New("unavailable")
+	}
+	return c.Client.CreateTransfers(transfers)
+}
+
+func TestSaga(t *testing.T) {
+	clock := tbtest.NewClock(time.Time{})
+	client, err := tigerbeetle_go.NewClient(types.ToUint128(0), nil, 1,
+		tigerbeetle_go.WithTransport(tbtest.NewLedger(clock)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	_, err = client.CreateAccounts([]types.Account{
+		{ID: types.ToUint128(1), Ledger: 1, Code: 1},
+		{ID: types.ToUint128(2), Ledger: 1, Code: 1},
+	})
+	assert.Equal(t, nil, err)
+
+	var records []Record
+	coordinator := New(&flakyClient{Client: client}, Config{
+		Persist: func(record Record) error {
+			records = append(records, record)
+			return nil
+		},
+		Backoff: time.Millisecond,
+	})
+	reserve := func(timeout uint32) *Saga {
+		saga, err := coordinator.Reserve(types.Transfer{
+			DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2),
+			Amount: types.ToUint128(100), Ledger: 1, Code: 1, Timeout: timeout,
+		})
+		assert.Equal(t, nil, err)
+		return saga
+	}
+
+	t.Run("captures once", func(t *testing.T) {
+		saga := reserve(0)
+		assert.Equal(t, saga.Record(), records[len(records)-1])
+		assert.True(t, saga.Record().Pending.TransferFlags().Pending)
+
+		assert.Equal(t, nil, saga.Capture(types.ToUint128(60)))
+		assert.Equal(t, nil, saga.Capture(types.ToUint128(60)))
+		assert.Equal(t, ErrPosted, saga.Void())
+		status, err := saga.Status()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, types.TransferStatusPendingPosted, status)
+
+		accounts, _ := client.LookupAccounts([]types.Uint128{types.ToUint128(2)})
+		assert.Equal(t, types.ToUint128(60), accounts[0].CreditsPosted)
+	})
+
+	t.Run("voids a resumed saga", func(t *testing.T) {
+		saga := coordinator.Resume(reserve(0).Record())
+		assert.Equal(t, nil, saga.Void())
+		assert.Equal(t, nil, saga.Void())
+		assert.Equal(t, ErrVoided, saga.Capture(types.ToUint128(1)))
+	})
+
+	t.Run("handles expiry", func(t *testing.T) {
+		saga := reserve(10)
+		assert.True(t, !saga.Record().ExpiresAt.IsZero())
+		clock.Advance(11 * time.Second)
+		assert.Equal(t, ErrExpired, saga.Capture(types.ToUint128(100)))
+		assert.Equal(t, nil, saga.Void())
+	})
+
+	t.Run("voids a reservation that was never made", func(t *testing.T) {
+		saga := coordinator.Resume(Record{Pending: types.Transfer{ID: types.ID()}, CaptureID: types.ID(), VoidID: types.ID()})
+		assert.Equal(t, nil, saga.Void())
+		_, err := saga.Status()
+		assert.Equal(t, ErrNotReserved, err)
+	})
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// halves returns the low and high 64 bits of value.
+func (value Uint128) halves() (uint64, uint64) {
+	bytes := value.Bytes()
+	return binary.LittleEndian.Uint64(bytes[:8]), binary.LittleEndian.Uint64(bytes[8:])
+}
+
+func uint128FromHalves(low uint64, high uint64) Uint128 {
+	var bytes [16]byte
+	binary.LittleEndian.PutUint64(bytes[:8], low)
+	binary.LittleEndian.PutUint64(bytes[8:], high)
+	return BytesToUint128(bytes)
+}
+
+// AddChecked returns value + other, and false instead if the sum overflows 128 bits.
+func (value Uint128) AddChecked(other Uint128) (Uint128, bool) {
+	aLow, aHigh := value.halves()
+	bLow, bHigh := other.halves()
+	low, carry := bits.Add64(aLow, bLow, 0)
+	high, carry := bits.Add64(aHigh, bHigh, carry)
+	if carry != 0 {
+		return Uint128{}, false
+	}
+	return uint128FromHalves(low, high), true
+}
+
+// SubChecked returns value - other, and false instead if other is greater than value.
+func (value Uint128) SubChecked(other Uint128) (Uint128, bool) {
+	aLow, aHigh := value.halves()
+	bLow, bHigh := other.halves()
+	low, borrow := bits.Sub64(aLow, bLow, 0)
+	high, borrow := bits.Sub64(aHigh, bHigh, borrow)
+	if borrow != 0 {
+		return Uint128{}, false
+	}
+	return uint128FromHalves(low, high), true
+}
+
+// AddSaturating returns value + other, or the largest Uint128 if the sum overflows.
+func (value Uint128) AddSaturating(other Uint128) Uint128 {
+	sum, ok := value.AddChecked(other)
+	if !ok {
+		return uint128IntMax
+	}
+	return sum
+}
+
+// SubSaturating returns value - other, or zero if other is greater than value.
+func (value Uint128) SubSaturating(other Uint128) Uint128 {
+	difference, _ := value.SubChecked(other)
+	return difference
+}
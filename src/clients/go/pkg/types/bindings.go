@@ -5,10 +5,6 @@
 
 package types
 
-/*
-#include "../native/tb_client.h"
-*/
-import "C"
 import "strconv"
 
 type AccountFlags struct {
@@ -104,19 +100,19 @@ func (f AccountFilterFlags) ToUint32() uint32 {
 }
 
 type Account struct {
-	ID             Uint128
-	DebitsPending  Uint128
-	DebitsPosted   Uint128
-	CreditsPending Uint128
-	CreditsPosted  Uint128
-	UserData128    Uint128
-	UserData64     uint64
-	UserData32     uint32
-	Reserved       uint32
-	Ledger         uint32
-	Code           uint16
-	Flags          uint16
-	Timestamp      uint64
+	ID             Uint128 `json:"id"`
+	DebitsPending  Uint128 `json:"debits_pending"`
+	DebitsPosted   Uint128 `json:"debits_posted"`
+	CreditsPending Uint128 `json:"credits_pending"`
+	CreditsPosted  Uint128 `json:"credits_posted"`
+	UserData128    Uint128 `json:"user_data_128"`
+	UserData64     uint64  `json:"user_data_64"`
+	UserData32     uint32  `json:"user_data_32"`
+	Reserved       uint32  `json:"-"`
+	Ledger         uint32  `json:"ledger"`
+	Code           uint16  `json:"code"`
+	Flags          uint16  `json:"flags"`
+	Timestamp      uint64  `json:"timestamp"`
 }
 
 func (o Account) AccountFlags() AccountFlags {
@@ -129,19 +125,19 @@ func (o Account) AccountFlags() AccountFlags {
 }
 
 type Transfer struct {
-	ID              Uint128
-	DebitAccountID  Uint128
-	CreditAccountID Uint128
-	Amount          Uint128
-	PendingID       Uint128
-	UserData128     Uint128
-	UserData64      uint64
-	UserData32      uint32
-	Timeout         uint32
-	Ledger          uint32
-	Code            uint16
-	Flags           uint16
-	Timestamp       uint64
+	ID              Uint128 `json:"id"`
+	DebitAccountID  Uint128 `json:"debit_account_id"`
+	CreditAccountID Uint128 `json:"credit_account_id"`
+	Amount          Uint128 `json:"amount"`
+	PendingID       Uint128 `json:"pending_id"`
+	UserData128     Uint128 `json:"user_data_128"`
+	UserData64      uint64  `json:"user_data_64"`
+	UserData32      uint32  `json:"user_data_32"`
+	Timeout         uint32  `json:"timeout"`
+	Ledger          uint32  `json:"ledger"`
+	Code            uint16  `json:"code"`
+	Flags           uint16  `json:"flags"`
+	Timestamp       uint64  `json:"timestamp"`
 }
 
 func (o Transfer) TransferFlags() TransferFlags {
@@ -412,22 +408,22 @@ func (i CreateTransferResult) String() string {
 }
 
 type AccountEventResult struct {
-	Index  uint32
-	Result CreateAccountResult
+	Index  uint32              `json:"index"`
+	Result CreateAccountResult `json:"result"`
 }
 
 type TransferEventResult struct {
-	Index  uint32
-	Result CreateTransferResult
+	Index  uint32               `json:"index"`
+	Result CreateTransferResult `json:"result"`
 }
 
 type AccountFilter struct {
-	AccountID    Uint128
-	TimestampMin uint64
-	TimestampMax uint64
-	Limit        uint32
-	Flags        uint32
-	Reserved     [24]uint8
+	AccountID    Uint128   `json:"account_id"`
+	TimestampMin uint64    `json:"timestamp_min"`
+	TimestampMax uint64    `json:"timestamp_max"`
+	Limit        uint32    `json:"limit"`
+	Flags        uint32    `json:"flags"`
+	Reserved     [24]uint8 `json:"-"`
 }
 
 func (o AccountFilter) AccountFilterFlags() AccountFilterFlags {
@@ -439,11 +435,11 @@ func (o AccountFilter) AccountFilterFlags() AccountFilterFlags {
 }
 
 type AccountBalance struct {
-	DebitsPending  Uint128
-	DebitsPosted   Uint128
-	CreditsPending Uint128
-	CreditsPosted  Uint128
-	Timestamp      uint64
-	Reserved       [56]uint8
+	DebitsPending  Uint128   `json:"debits_pending"`
+	DebitsPosted   Uint128   `json:"debits_posted"`
+	CreditsPending Uint128   `json:"credits_pending"`
+	CreditsPosted  Uint128   `json:"credits_posted"`
+	Timestamp      uint64    `json:"timestamp"`
+	Reserved       [56]uint8 `json:"-"`
 }
 
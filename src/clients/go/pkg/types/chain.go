@@ -0,0 +1,131 @@
+package types
+
+import "fmt"
+
+type ErrLinkedChainOpen struct {
+	// Index of the first event of the chain that is never terminated.
+	Index int
+}
+
+func (s ErrLinkedChainOpen) Error() string {
+	return fmt.Sprintf("Linked chain starting at index %d is not terminated.", s.Index)
+}
+
+// LinkedChain builds a batch of transfers out of linked chains.
+// Every chain added succeeds or fails as a unit when the batch is submitted.
+type LinkedChain struct {
+	transfers []Transfer
+	err       error
+}
+
+// Chain appends the transfers as a single linked chain, setting the linked flag on all but the
+// last transfer and clearing it on the last.
+//
+// A chain left open by Append would silently join the new one, so Chain then adds nothing and
+// Build returns ErrLinkedChainOpen for the open chain instead.
+func (c *LinkedChain) Chain(transfers ...Transfer) *LinkedChain {
+	if c.err != nil {
+		return c
+	}
+	if index, open := openChain(len(c.transfers), func(i int) bool {
+		return c.transfers[i].TransferFlags().Linked
+	}); open {
+		c.err = ErrLinkedChainOpen{Index: index}
+		return c
+	}
+	for i, transfer := range transfers {
+		if i < len(transfers)-1 {
+			transfer.Flags |= TransferFlags{Linked: true}.ToUint16()
+		} else {
+			transfer.Flags &^= TransferFlags{Linked: true}.ToUint16()
+		}
+		c.transfers = append(c.transfers, transfer)
+	}
+	return c
+}
+
+// Append appends the transfers with their flags untouched, for chains built by the caller.
+func (c *LinkedChain) Append(transfers ...Transfer) *LinkedChain {
+	c.transfers = append(c.transfers, transfers...)
+	return c
+}
+
+// Build returns the batch, or ErrLinkedChainOpen if an appended chain is left unterminated or
+// was followed by Chain.
+func (c *LinkedChain) Build() ([]Transfer, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if index, open := openChain(len(c.transfers), func(i int) bool {
+		return c.transfers[i].TransferFlags().Linked
+	}); open {
+		return nil, ErrLinkedChainOpen{Index: index}
+	}
+	return c.transfers, nil
+}
+
+// LinkedAccountChain builds a batch of accounts out of linked chains.
+// Every chain added succeeds or fails as a unit when the batch is submitted.
+type LinkedAccountChain struct {
+	accounts []Account
+	err      error
+}
+
+// Chain appends the accounts as a single linked chain, setting the linked flag on all but the
+// last account and clearing it on the last.
+//
+// A chain left open by Append would silently join the new one, so Chain then adds nothing and
+// Build returns ErrLinkedChainOpen for the open chain instead.
+func (c *LinkedAccountChain) Chain(accounts ...Account) *LinkedAccountChain {
+	if c.err != nil {
+		return c
+	}
+	if index, open := openChain(len(c.accounts), func(i int) bool {
+		return c.accounts[i].AccountFlags().Linked
+	}); open {
+		c.err = ErrLinkedChainOpen{Index: index}
+		return c
+	}
+	for i, account := range accounts {
+		if i < len(accounts)-1 {
+			account.Flags |= AccountFlags{Linked: true}.ToUint16()
+		} else {
+			account.Flags &^= AccountFlags{Linked: true}.ToUint16()
+		}
+		c.accounts = append(c.accounts, account)
+	}
+	return c
+}
+
+// Append appends the accounts with their flags untouched, for chains built by the caller.
+func (c *LinkedAccountChain) Append(accounts ...Account) *LinkedAccountChain {
+	c.accounts = append(c.accounts, accounts...)
+	return c
+}
+
+// Build returns the batch, or ErrLinkedChainOpen if an appended chain is left unterminated or
+// was followed by Chain.
+func (c *LinkedAccountChain) Build() ([]Account, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if index, open := openChain(len(c.accounts), func(i int) bool {
+		return c.accounts[i].AccountFlags().Linked
+	}); open {
+		return nil, ErrLinkedChainOpen{Index: index}
+	}
+	return c.accounts, nil
+}
+
+// openChain returns the index where the trailing chain starts if the last event is linked.
+func openChain(count int, linked func(i int) bool) (int, bool) {
+	if count == 0 || !linked(count-1) {
+		return 0, false
+	}
+
+	start := count - 1
+	for start > 0 && linked(start-1) {
+		start--
+	}
+	return start, true
+}
@@ -0,0 +1,196 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// AccountType is the kind of an account in double-entry bookkeeping, which decides the side its
+// balance is normally on, and so the flag that keeps it there.
+type AccountType uint8
+
+const (
+	// AccountTypeAsset and AccountTypeExpense have debit balances: their credits must not
+	// exceed their debits.
+	AccountTypeAsset AccountType = iota + 1
+	AccountTypeExpense
+	// AccountTypeLiability, AccountTypeEquity and AccountTypeIncome have credit balances: their
+	// debits must not exceed their credits.
+	AccountTypeLiability
+	AccountTypeEquity
+	AccountTypeIncome
+	// AccountTypeControl has a balance on either side, such as the operator account that funds
+	// others, and no limit.
+	AccountTypeControl
+)
+
+func (t AccountType) String() string {
+	switch t {
+	case AccountTypeAsset:
+		return "Asset"
+	case AccountTypeExpense:
+		return "Expense"
+	case AccountTypeLiability:
+		return "Liability"
+	case AccountTypeEquity:
+		return "Equity"
+	case AccountTypeIncome:
+		return "Income"
+	case AccountTypeControl:
+		return "Control"
+	}
+	return "AccountType(" + strconv.FormatInt(int64(t), 10) + ")"
+}
+
+// Flags returns the balance limit of an account of this type.
+func (t AccountType) Flags() AccountFlags {
+	switch t {
+	case AccountTypeAsset, AccountTypeExpense:
+		return AccountFlags{CreditsMustNotExceedDebits: true}
+	case AccountTypeLiability, AccountTypeEquity, AccountTypeIncome:
+		return AccountFlags{DebitsMustNotExceedCredits: true}
+	}
+	return AccountFlags{}
+}
+
+// NewAccount returns an account of type t with a fresh ID(), limited to its normal balance.
+func NewAccount(t AccountType, ledger uint32, code uint16) Account {
+	return Account{
+		ID:     ID(),
+		Ledger: ledger,
+		Code:   code,
+		Flags:  t.Flags().ToUint16(),
+	}
+}
+
+// NewAssetAccount returns an asset account, whose credits must not exceed its debits.
+func NewAssetAccount(ledger uint32, code uint16) Account {
+	return NewAccount(AccountTypeAsset, ledger, code)
+}
+
+// NewLiabilityAccount returns a liability account, whose debits must not exceed its credits.
+func NewLiabilityAccount(ledger uint32, code uint16) Account {
+	return NewAccount(AccountTypeLiability, ledger, code)
+}
+
+// NewControlAccount returns a control account, whose balance may be on either side.
+func NewControlAccount(ledger uint32, code uint16) Account {
+	return NewAccount(AccountTypeControl, ledger, code)
+}
+
+type ErrChartOfAccounts struct {
+	// Index is the position of the event in the batch validated.
+	Index  int
+	Code   uint16
+	Reason string
+}
+
+func (s ErrChartOfAccounts) Error() string {
+	return fmt.Sprintf("Event %d with code %d: %s.", s.Index, s.Code, s.Reason)
+}
+
+// ChartOfAccounts names the codes of a ledger's accounts and transfers, and checks that events
+// only use the codes registered, with the flags of their account type. Register the codes before
+// validating concurrently.
+type ChartOfAccounts struct {
+	accounts  map[uint16]chartAccount
+	transfers map[uint16]string
+}
+
+type chartAccount struct {
+	name        string
+	accountType AccountType
+}
+
+// NewChartOfAccounts returns an empty chart.
+func NewChartOfAccounts() *ChartOfAccounts {
+	return &ChartOfAccounts{
+		accounts:  make(map[uint16]chartAccount),
+		transfers: make(map[uint16]string),
+	}
+}
+
+// RegisterAccount names an account code, for accounts of type t. A code may only be registered
+// once.
+func (c *ChartOfAccounts) RegisterAccount(code uint16, name string, t AccountType) error {
+	if code == 0 {
+		return ErrChartOfAccounts{Code: code, Reason: "code must not be zero"}
+	}
+	if _, ok := c.accounts[code]; ok {
+		return ErrChartOfAccounts{Code: code, Reason: "account code is already registered"}
+	}
+	c.accounts[code] = chartAccount{name: name, accountType: t}
+	return nil
+}
+
+// RegisterTransfer names a transfer code. A code may only be registered once.
+func (c *ChartOfAccounts) RegisterTransfer(code uint16, name string) error {
+	if code == 0 {
+		return ErrChartOfAccounts{Code: code, Reason: "code must not be zero"}
+	}
+	if _, ok := c.transfers[code]; ok {
+		return ErrChartOfAccounts{Code: code, Reason: "transfer code is already registered"}
+	}
+	c.transfers[code] = name
+	return nil
+}
+
+// AccountName returns the name and type of an account code, if registered.
+func (c *ChartOfAccounts) AccountName(code uint16) (string, AccountType, bool) {
+	account, ok := c.accounts[code]
+	return account.name, account.accountType, ok
+}
+
+// TransferName returns the name of a transfer code, if registered.
+func (c *ChartOfAccounts) TransferName(code uint16) (string, bool) {
+	name, ok := c.transfers[code]
+	return name, ok
+}
+
+// NewAccount returns an account with the code, which must be registered, and the flags of its
+// type.
+func (c *ChartOfAccounts) NewAccount(ledger uint32, code uint16) (Account, error) {
+	account, ok := c.accounts[code]
+	if !ok {
+		return Account{}, ErrChartOfAccounts{Code: code, Reason: "account code is not registered"}
+	}
+	return NewAccount(account.accountType, ledger, code), nil
+}
+
+// ValidateAccounts checks that every account has a registered code and the balance limit of its
+// type, returning ErrChartOfAccounts for the first that doesn't.
+func (c *ChartOfAccounts) ValidateAccounts(accounts []Account) error {
+	for i, account := range accounts {
+		registered, ok := c.accounts[account.Code]
+		if !ok {
+			return ErrChartOfAccounts{Index: i, Code: account.Code,
+				Reason: "account code is not registered"}
+		}
+
+		limits := registered.accountType.Flags()
+		flags := account.AccountFlags()
+		if flags.DebitsMustNotExceedCredits != limits.DebitsMustNotExceedCredits ||
+			flags.CreditsMustNotExceedDebits != limits.CreditsMustNotExceedDebits {
+			return ErrChartOfAccounts{Index: i, Code: account.Code,
+				Reason: "flags do not match the " + registered.accountType.String() + " account type"}
+		}
+	}
+	return nil
+}
+
+// ValidateTransfers checks that every transfer has a registered code, returning
+// ErrChartOfAccounts for the first that doesn't. Posting and voiding transfers may leave the code
+// zero to inherit it from the pending transfer.
+func (c *ChartOfAccounts) ValidateTransfers(transfers []Transfer) error {
+	for i, transfer := range transfers {
+		flags := transfer.TransferFlags()
+		if transfer.Code == 0 && (flags.PostPendingTransfer || flags.VoidPendingTransfer) {
+			continue
+		}
+		if _, ok := c.transfers[transfer.Code]; !ok {
+			return ErrChartOfAccounts{Index: i, Code: transfer.Code,
+				Reason: "transfer code is not registered"}
+		}
+	}
+	return nil
+}
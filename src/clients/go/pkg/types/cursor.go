@@ -0,0 +1,107 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"hash/fnv"
+)
+
+type ErrInvalidCursor struct {
+	Reason string
+}
+
+func (s ErrInvalidCursor) Error() string { return "Invalid cursor: " + s.Reason + "." }
+
+// cursorVersion is the first byte of an encoded cursor, for its layout to change later.
+const cursorVersion = 1
+
+// cursorSize is the size of an encoded cursor: its version, timestamp, direction and filter
+// hash.
+const cursorSize = 1 + 8 + 1 + 8
+
+// Cursor is where a page of GetAccountTransfers or GetAccountHistory ended, to be handed out as
+// an opaque pagination token instead of a raw timestamp. It remembers the filter it was made
+// for, so that a token can't continue the pages of another account or direction.
+//
+// The token is not signed: a client may forge one for a filter it is allowed to query anyway,
+// but not reuse one across filters by mistake.
+type Cursor struct {
+	// Timestamp is the timestamp of the last result of the page.
+	Timestamp uint64
+	// Reversed is set when the pages go from the latest results to the earliest.
+	Reversed bool
+	// FilterHash identifies the filter of the pages.
+	FilterHash uint64
+}
+
+// NewCursor returns the cursor past the result at timestamp, for the pages of filter.
+func NewCursor(filter AccountFilter, timestamp uint64) Cursor {
+	return Cursor{
+		Timestamp:  timestamp,
+		Reversed:   filter.AccountFilterFlags().Reversed,
+		FilterHash: filterHash(filter),
+	}
+}
+
+// Encode returns the cursor as a URL-safe base64 token.
+func (c Cursor) Encode() string {
+	var data [cursorSize]byte
+	data[0] = cursorVersion
+	binary.LittleEndian.PutUint64(data[1:], c.Timestamp)
+	if c.Reversed {
+		data[9] = 1
+	}
+	binary.LittleEndian.PutUint64(data[10:], c.FilterHash)
+	return base64.RawURLEncoding.EncodeToString(data[:])
+}
+
+// DecodeCursor reads a token returned by Cursor.Encode.
+func DecodeCursor(token string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) != cursorSize {
+		return Cursor{}, ErrInvalidCursor{Reason: "token is malformed"}
+	}
+	if data[0] != cursorVersion {
+		return Cursor{}, ErrInvalidCursor{Reason: "token is of an unknown version"}
+	}
+	if data[9] > 1 {
+		return Cursor{}, ErrInvalidCursor{Reason: "token is malformed"}
+	}
+	return Cursor{
+		Timestamp:  binary.LittleEndian.Uint64(data[1:]),
+		Reversed:   data[9] == 1,
+		FilterHash: binary.LittleEndian.Uint64(data[10:]),
+	}, nil
+}
+
+// Apply returns filter narrowed down to the results past the cursor, in its direction. filter
+// must be the filter that the cursor was made for, as given for the first page: the cursor is
+// rejected otherwise.
+func (c Cursor) Apply(filter AccountFilter) (AccountFilter, error) {
+	if c.FilterHash != filterHash(filter) || c.Reversed != filter.AccountFilterFlags().Reversed {
+		return AccountFilter{}, ErrInvalidCursor{Reason: "token was issued for another filter"}
+	}
+	if c.Reversed {
+		if c.Timestamp <= 1 {
+			return AccountFilter{}, ErrInvalidCursor{Reason: "token is past the earliest result"}
+		}
+		filter.TimestampMax = c.Timestamp - 1
+	} else {
+		filter.TimestampMin = c.Timestamp + 1
+	}
+	return filter, nil
+}
+
+// filterHash hashes the fields that choose the results of a filter, but not its limit, which
+// may change from one page to the next.
+func filterHash(filter AccountFilter) uint64 {
+	var data [Uint128Size + 8 + 8 + 4]byte
+	id := filter.AccountID.Bytes()
+	copy(data[:], id[:])
+	binary.LittleEndian.PutUint64(data[16:], filter.TimestampMin)
+	binary.LittleEndian.PutUint64(data[24:], filter.TimestampMax)
+	binary.LittleEndian.PutUint32(data[32:], filter.Flags)
+	hash := fnv.New64a()
+	hash.Write(data[:])
+	return hash.Sum64()
+}
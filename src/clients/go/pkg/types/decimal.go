@@ -0,0 +1,109 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode decides how FromDecimal rounds a decimal with more decimal places than the
+// exponent of the amount.
+type RoundingMode uint8
+
+const (
+	// RoundUnnecessary rejects a decimal that isn't exactly representable, rather than rounding.
+	RoundUnnecessary RoundingMode = iota
+	// RoundDown truncates, towards zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundHalfUp rounds to the nearest amount, with ties away from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest amount, with ties to the even one, as banks do.
+	RoundHalfEven
+)
+
+func (mode RoundingMode) String() string {
+	switch mode {
+	case RoundUnnecessary:
+		return "RoundUnnecessary"
+	case RoundDown:
+		return "RoundDown"
+	case RoundUp:
+		return "RoundUp"
+	case RoundHalfUp:
+		return "RoundHalfUp"
+	case RoundHalfEven:
+		return "RoundHalfEven"
+	}
+	return fmt.Sprintf("RoundingMode(%d)", uint8(mode))
+}
+
+type ErrDecimalConversion struct {
+	Decimal string
+	Reason  string
+}
+
+func (s ErrDecimalConversion) Error() string {
+	return fmt.Sprintf("Decimal %s can't be converted to a Uint128: %s.", s.Decimal, s.Reason)
+}
+
+func pow10(exponent uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+// ToDecimal returns the amount in units of 10^exponent, exactly: the amount 1234 with an exponent
+// of 2 is 12.34.
+//
+// A github.com/shopspring/decimal.Decimal is decimal.NewFromBigRat(value.ToDecimal(exponent),
+// int32(exponent)), or decimal.NewFromBigInt(&bigint, -int32(exponent)) from value.BigInt().
+func (value Uint128) ToDecimal(exponent uint8) *big.Rat {
+	bigint := value.BigInt()
+	return new(big.Rat).SetFrac(&bigint, pow10(exponent))
+}
+
+// FromDecimal converts a decimal in units of 10^exponent to an amount, rounded with mode: 12.345
+// with an exponent of 2 is 1234 with RoundDown, or 1235 with RoundUp. It returns
+// ErrDecimalConversion for a negative decimal, one that overflows 128 bits once rounded, or one
+// that needs rounding with RoundUnnecessary.
+//
+// A github.com/shopspring/decimal.Decimal d converts with FromDecimal(d.Rat(), exponent, mode).
+func FromDecimal(decimal *big.Rat, exponent uint8, mode RoundingMode) (Uint128, error) {
+	if decimal.Sign() < 0 {
+		return Uint128{}, ErrDecimalConversion{Decimal: decimal.RatString(), Reason: "negative"}
+	}
+
+	scaled := new(big.Rat).Mul(decimal, new(big.Rat).SetInt(pow10(exponent)))
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if remainder.Sign() != 0 {
+		// Compare twice the remainder with the denominator to find which half it is in.
+		half := new(big.Int).Lsh(remainder, 1).Cmp(scaled.Denom())
+		roundUp := false
+		switch mode {
+		case RoundUnnecessary:
+			return Uint128{}, ErrDecimalConversion{
+				Decimal: decimal.RatString(),
+				Reason:  fmt.Sprintf("more than %d decimal places", exponent),
+			}
+		case RoundDown:
+		case RoundUp:
+			roundUp = true
+		case RoundHalfUp:
+			roundUp = half >= 0
+		case RoundHalfEven:
+			roundUp = half > 0 || half == 0 && quotient.Bit(0) == 1
+		default:
+			return Uint128{}, ErrDecimalConversion{
+				Decimal: decimal.RatString(),
+				Reason:  "unknown " + mode.String(),
+			}
+		}
+		if roundUp {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	if quotient.Cmp(uint128Max) > 0 {
+		return Uint128{}, ErrDecimalConversion{Decimal: decimal.RatString(), Reason: "out of range"}
+	}
+	return BigIntToUint128(*quotient), nil
+}
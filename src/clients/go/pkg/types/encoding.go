@@ -0,0 +1,200 @@
+package types
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// ErrInvalidEncoding is returned when decoding bytes that are not a whole number of the encoded
+// events.
+type ErrInvalidEncoding struct {
+	Type string
+	Size int
+}
+
+func (s ErrInvalidEncoding) Error() string {
+	return "Invalid encoding: " + strconv.Itoa(s.Size) + " bytes are not a whole number of " + s.Type + "s."
+}
+
+// wire reads and writes the fields of an event in order, in the layout of the cluster: packed,
+// little-endian, with Uint128s as their low 64 bits then their high 64 bits.
+type wire struct {
+	data   []byte
+	offset int
+}
+
+func (w *wire) putUint128(value Uint128) {
+	bytes := value.Bytes()
+	w.offset += copy(w.data[w.offset:], bytes[:])
+}
+
+func (w *wire) putUint64(value uint64) {
+	binary.LittleEndian.PutUint64(w.data[w.offset:], value)
+	w.offset += 8
+}
+
+func (w *wire) putUint32(value uint32) {
+	binary.LittleEndian.PutUint32(w.data[w.offset:], value)
+	w.offset += 4
+}
+
+func (w *wire) putUint16(value uint16) {
+	binary.LittleEndian.PutUint16(w.data[w.offset:], value)
+	w.offset += 2
+}
+
+func (w *wire) uint128() Uint128 {
+	value := BytesToUint128([16]byte(w.data[w.offset:]))
+	w.offset += 16
+	return value
+}
+
+func (w *wire) uint64() uint64 {
+	value := binary.LittleEndian.Uint64(w.data[w.offset:])
+	w.offset += 8
+	return value
+}
+
+func (w *wire) uint32() uint32 {
+	value := binary.LittleEndian.Uint32(w.data[w.offset:])
+	w.offset += 4
+	return value
+}
+
+func (w *wire) uint16() uint16 {
+	value := binary.LittleEndian.Uint16(w.data[w.offset:])
+	w.offset += 2
+	return value
+}
+
+// EncodeAccount returns the account as the cluster stores it, byte for byte, whatever the
+// endianness of the host.
+func EncodeAccount(account Account) [AccountSize]byte {
+	var data [AccountSize]byte
+	w := wire{data: data[:]}
+	w.putUint128(account.ID)
+	w.putUint128(account.DebitsPending)
+	w.putUint128(account.DebitsPosted)
+	w.putUint128(account.CreditsPending)
+	w.putUint128(account.CreditsPosted)
+	w.putUint128(account.UserData128)
+	w.putUint64(account.UserData64)
+	w.putUint32(account.UserData32)
+	w.putUint32(account.Reserved)
+	w.putUint32(account.Ledger)
+	w.putUint16(account.Code)
+	w.putUint16(account.Flags)
+	w.putUint64(account.Timestamp)
+	return data
+}
+
+// DecodeAccount reads an account encoded by EncodeAccount.
+func DecodeAccount(data []byte) (Account, error) {
+	if len(data) != AccountSize {
+		return Account{}, ErrInvalidEncoding{Type: "Account", Size: len(data)}
+	}
+	w := wire{data: data}
+	return Account{
+		ID:             w.uint128(),
+		DebitsPending:  w.uint128(),
+		DebitsPosted:   w.uint128(),
+		CreditsPending: w.uint128(),
+		CreditsPosted:  w.uint128(),
+		UserData128:    w.uint128(),
+		UserData64:     w.uint64(),
+		UserData32:     w.uint32(),
+		Reserved:       w.uint32(),
+		Ledger:         w.uint32(),
+		Code:           w.uint16(),
+		Flags:          w.uint16(),
+		Timestamp:      w.uint64(),
+	}, nil
+}
+
+// EncodeTransfer returns the transfer as the cluster stores it, byte for byte, whatever the
+// endianness of the host.
+func EncodeTransfer(transfer Transfer) [TransferSize]byte {
+	var data [TransferSize]byte
+	w := wire{data: data[:]}
+	w.putUint128(transfer.ID)
+	w.putUint128(transfer.DebitAccountID)
+	w.putUint128(transfer.CreditAccountID)
+	w.putUint128(transfer.Amount)
+	w.putUint128(transfer.PendingID)
+	w.putUint128(transfer.UserData128)
+	w.putUint64(transfer.UserData64)
+	w.putUint32(transfer.UserData32)
+	w.putUint32(transfer.Timeout)
+	w.putUint32(transfer.Ledger)
+	w.putUint16(transfer.Code)
+	w.putUint16(transfer.Flags)
+	w.putUint64(transfer.Timestamp)
+	return data
+}
+
+// DecodeTransfer reads a transfer encoded by EncodeTransfer.
+func DecodeTransfer(data []byte) (Transfer, error) {
+	if len(data) != TransferSize {
+		return Transfer{}, ErrInvalidEncoding{Type: "Transfer", Size: len(data)}
+	}
+	w := wire{data: data}
+	return Transfer{
+		ID:              w.uint128(),
+		DebitAccountID:  w.uint128(),
+		CreditAccountID: w.uint128(),
+		Amount:          w.uint128(),
+		PendingID:       w.uint128(),
+		UserData128:     w.uint128(),
+		UserData64:      w.uint64(),
+		UserData32:      w.uint32(),
+		Timeout:         w.uint32(),
+		Ledger:          w.uint32(),
+		Code:            w.uint16(),
+		Flags:           w.uint16(),
+		Timestamp:       w.uint64(),
+	}, nil
+}
+
+// EncodeAccounts returns a batch of accounts as the body of a create_accounts request.
+func EncodeAccounts(accounts []Account) []byte {
+	data := make([]byte, 0, len(accounts)*AccountSize)
+	for _, account := range accounts {
+		encoded := EncodeAccount(account)
+		data = append(data, encoded[:]...)
+	}
+	return data
+}
+
+// DecodeAccounts reads a batch of accounts encoded by EncodeAccounts.
+func DecodeAccounts(data []byte) ([]Account, error) {
+	if len(data)%AccountSize != 0 {
+		return nil, ErrInvalidEncoding{Type: "Account", Size: len(data)}
+	}
+	accounts := make([]Account, len(data)/AccountSize)
+	for i := range accounts {
+		accounts[i], _ = DecodeAccount(data[i*AccountSize : (i+1)*AccountSize])
+	}
+	return accounts, nil
+}
+
+// EncodeTransfers returns a batch of transfers as the body of a create_transfers request.
+func EncodeTransfers(transfers []Transfer) []byte {
+	data := make([]byte, 0, len(transfers)*TransferSize)
+	for _, transfer := range transfers {
+		encoded := EncodeTransfer(transfer)
+		data = append(data, encoded[:]...)
+	}
+	return data
+}
+
+// DecodeTransfers reads a batch of transfers encoded by EncodeTransfers.
+func DecodeTransfers(data []byte) ([]Transfer, error) {
+	if len(data)%TransferSize != 0 {
+		return nil, ErrInvalidEncoding{Type: "Transfer", Size: len(data)}
+	}
+	transfers := make([]Transfer, len(data)/TransferSize)
+	for i := range transfers {
+		transfers[i], _ = DecodeTransfer(data[i*TransferSize : (i+1)*TransferSize])
+	}
+	return transfers, nil
+}
@@ -0,0 +1,81 @@
+package types
+
+import "math/big"
+
+type ErrInvalidExchange struct {
+	Reason string
+}
+
+func (s ErrInvalidExchange) Error() string { return "Invalid exchange: " + s.Reason + "." }
+
+// Exchange describes a currency exchange between the accounts of two ledgers, through a
+// liquidity account on each: the source account pays Amount to the source liquidity account,
+// and the destination liquidity account pays the amount exchanged at Rate to the destination
+// account.
+type Exchange struct {
+	SourceAccountID        Uint128
+	SourceLiquidityID      Uint128
+	SourceLedger           uint32
+	DestinationLiquidityID Uint128
+	DestinationAccountID   Uint128
+	DestinationLedger      uint32
+	Code                   uint16
+	// UserData128 is given to both transfers, to tie them together.
+	UserData128 Uint128
+
+	// Amount is what the source account pays, in the units of the source ledger.
+	Amount Uint128
+	// Rate is how many units of the destination ledger a unit of the source ledger is worth,
+	// with the scales of both ledgers taken into account: from a ledger of cents to a ledger of
+	// yen at 150 yen to the dollar, it is 150/100.
+	Rate *big.Rat
+	// Rounding rounds the amount exchanged, which RoundUnnecessary requires to be exact.
+	Rounding RoundingMode
+}
+
+// Build returns the exchange as a linked chain of two transfers, one per ledger, each a debit
+// and a credit, so that both legs are created or neither is. The transfers are assigned fresh
+// IDs, so build an exchange once, and resubmit the same transfers to retry it. The amount
+// exchanged is the Amount of the second transfer.
+//
+// Build returns ErrInvalidExchange if the rate is missing or not positive, or if the amount
+// exchanged rounds to zero, and ErrDecimalConversion if it can't be rounded with Rounding.
+func (o Exchange) Build() ([]Transfer, error) {
+	if o.Rate == nil || o.Rate.Sign() <= 0 {
+		return nil, ErrInvalidExchange{Reason: "rate must be positive"}
+	}
+	if o.SourceLedger == o.DestinationLedger {
+		return nil, ErrInvalidExchange{Reason: "ledgers must be different"}
+	}
+
+	amount := o.Amount.BigInt()
+	exact := new(big.Rat).Mul(new(big.Rat).SetInt(&amount), o.Rate)
+	exchanged, err := FromDecimal(exact, 0, o.Rounding)
+	if err != nil {
+		return nil, err
+	}
+	if exchanged == (Uint128{}) {
+		return nil, ErrInvalidExchange{Reason: "amount " + o.Amount.String() + " exchanges to zero"}
+	}
+
+	return new(LinkedChain).Chain(
+		Transfer{
+			ID:              ID(),
+			DebitAccountID:  o.SourceAccountID,
+			CreditAccountID: o.SourceLiquidityID,
+			Amount:          o.Amount,
+			Ledger:          o.SourceLedger,
+			Code:            o.Code,
+			UserData128:     o.UserData128,
+		},
+		Transfer{
+			ID:              ID(),
+			DebitAccountID:  o.DestinationLiquidityID,
+			CreditAccountID: o.DestinationAccountID,
+			Amount:          exchanged,
+			Ledger:          o.DestinationLedger,
+			Code:            o.Code,
+			UserData128:     o.UserData128,
+		},
+	).Build()
+}
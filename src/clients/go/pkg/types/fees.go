@@ -0,0 +1,202 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+type ErrInvalidFeeSplit struct {
+	Reason string
+}
+
+func (s ErrInvalidFeeSplit) Error() string { return "Invalid fee split: " + s.Reason + "." }
+
+// RemainderPolicy decides who absorbs what rounding the fees of a FeeSplitBuilder to whole
+// amounts takes off or adds.
+type RemainderPolicy uint8
+
+const (
+	// RemainderToPayee rounds every fee on its own, leaving the payee with the rest of the
+	// principal, rounding differences included.
+	RemainderToPayee RemainderPolicy = iota
+	// RemainderToFirstFee rounds the total of the fees once, rounds the other fees down, and
+	// assigns what is left of the total to the first fee.
+	RemainderToFirstFee
+	// RemainderLargestFraction rounds the total of the fees once, rounds every fee down, and
+	// hands what is left of the total out one unit at a time to the fees that rounding down cut
+	// the most, the earlier fee first among equals.
+	RemainderLargestFraction
+)
+
+func (policy RemainderPolicy) String() string {
+	switch policy {
+	case RemainderToPayee:
+		return "RemainderToPayee"
+	case RemainderToFirstFee:
+		return "RemainderToFirstFee"
+	case RemainderLargestFraction:
+		return "RemainderLargestFraction"
+	}
+	return fmt.Sprintf("RemainderPolicy(%d)", uint8(policy))
+}
+
+// feeRule is a fee of a FeeSplitBuilder: rate of the principal, plus fixed.
+type feeRule struct {
+	creditAccountID Uint128
+	rate            *big.Rat
+	fixed           Uint128
+}
+
+// FeeSplitBuilder builds the linked chain of transfers that pays a principal from a payer to a
+// payee, less fees paid to fee accounts out of the principal.
+type FeeSplitBuilder struct {
+	principal Transfer
+	fees      []feeRule
+	rounding  RoundingMode
+	remainder RemainderPolicy
+}
+
+// NewFeeSplit returns a builder of the split of principal, a transfer of its Amount from its
+// debit account, the payer, to its credit account, the payee. The ledger, code, user data and
+// flags of principal are given to every transfer of the split, and its ID, if not zero, to the
+// transfer to the payee. Unless set, the fees are rounded with RoundHalfEven and the remainder
+// goes to the payee.
+func NewFeeSplit(principal Transfer) *FeeSplitBuilder {
+	return &FeeSplitBuilder{principal: principal, rounding: RoundHalfEven}
+}
+
+// Fee charges rate of the principal, such as big.NewRat(25, 1000) for 2.5%, plus fixed, to be
+// paid to creditAccountID. A nil rate charges only fixed.
+func (b *FeeSplitBuilder) Fee(creditAccountID Uint128, rate *big.Rat, fixed Uint128) *FeeSplitBuilder {
+	if rate == nil {
+		rate = new(big.Rat)
+	}
+	b.fees = append(b.fees, feeRule{creditAccountID: creditAccountID, rate: new(big.Rat).Set(rate), fixed: fixed})
+	return b
+}
+
+// Rounding rounds the fees with mode.
+func (b *FeeSplitBuilder) Rounding(mode RoundingMode) *FeeSplitBuilder {
+	b.rounding = mode
+	return b
+}
+
+// Remainder assigns the rounding remainder according to policy.
+func (b *FeeSplitBuilder) Remainder(policy RemainderPolicy) *FeeSplitBuilder {
+	b.remainder = policy
+	return b
+}
+
+// Build returns the transfers of the split as a linked chain: the transfer to the payee, then a
+// transfer per fee, in the order they were added. Transfers of a zero amount, which the cluster
+// would reject, are left out. Every transfer but the one to the payee is assigned a fresh ID(),
+// so build a split once, and resubmit the same transfers to retry it.
+//
+// Build returns ErrInvalidFeeSplit if the fees add up to more than the principal, or if a rate
+// is negative, and ErrDecimalConversion if a fee can't be rounded with RoundUnnecessary.
+func (b *FeeSplitBuilder) Build() ([]Transfer, error) {
+	amounts, err := b.feeAmounts()
+	if err != nil {
+		return nil, err
+	}
+
+	principal := b.principal.Amount.BigInt()
+	rest := new(big.Int).Set(&principal)
+	for _, amount := range amounts {
+		rest.Sub(rest, amount)
+	}
+	if rest.Sign() < 0 {
+		return nil, ErrInvalidFeeSplit{Reason: "fees exceed the principal " + b.principal.Amount.String()}
+	}
+
+	payee := b.principal
+	if payee.ID == (Uint128{}) {
+		payee.ID = ID()
+	}
+	payee.Amount = BigIntToUint128(*rest)
+
+	var transfers []Transfer
+	if rest.Sign() > 0 {
+		transfers = append(transfers, payee)
+	}
+	for i, fee := range b.fees {
+		if amounts[i].Sign() == 0 {
+			continue
+		}
+		transfer := b.principal
+		transfer.ID = ID()
+		transfer.CreditAccountID = fee.creditAccountID
+		transfer.Amount = BigIntToUint128(*amounts[i])
+		transfers = append(transfers, transfer)
+	}
+	return new(LinkedChain).Chain(transfers...).Build()
+}
+
+// feeAmounts returns the amount of every fee, rounded according to the policy.
+func (b *FeeSplitBuilder) feeAmounts() ([]*big.Int, error) {
+	principal := b.principal.Amount.BigInt()
+	exact := make([]*big.Rat, len(b.fees))
+	total := new(big.Rat)
+	for i, fee := range b.fees {
+		if fee.rate.Sign() < 0 {
+			return nil, ErrInvalidFeeSplit{Reason: "rate " + fee.rate.RatString() + " is negative"}
+		}
+		exact[i] = new(big.Rat).Mul(fee.rate, new(big.Rat).SetInt(&principal))
+		total.Add(total, exact[i])
+	}
+
+	amounts := make([]*big.Int, len(b.fees))
+	switch b.remainder {
+	case RemainderToPayee:
+		for i := range b.fees {
+			rounded, err := FromDecimal(exact[i], 0, b.rounding)
+			if err != nil {
+				return nil, err
+			}
+			bigint := rounded.BigInt()
+			amounts[i] = &bigint
+		}
+	case RemainderToFirstFee, RemainderLargestFraction:
+		rounded, err := FromDecimal(total, 0, b.rounding)
+		if err != nil {
+			return nil, err
+		}
+		left := rounded.BigInt()
+		for i := range b.fees {
+			amounts[i] = new(big.Int).Quo(exact[i].Num(), exact[i].Denom())
+			left.Sub(&left, amounts[i])
+		}
+		switch {
+		case len(b.fees) == 0:
+		case b.remainder == RemainderToFirstFee:
+			amounts[0].Add(amounts[0], &left)
+		default:
+			// The units left, fewer than the fees, go to the fees with the largest fractional
+			// parts.
+			order := make([]int, len(b.fees))
+			fractions := make([]*big.Rat, len(b.fees))
+			for i := range b.fees {
+				order[i] = i
+				fractions[i] = new(big.Rat).Sub(exact[i], new(big.Rat).SetInt(amounts[i]))
+			}
+			slices.SortStableFunc(order, func(x, y int) int {
+				return -fractions[x].Cmp(fractions[y])
+			})
+			for _, i := range order[:left.Int64()] {
+				amounts[i].Add(amounts[i], big.NewInt(1))
+			}
+		}
+	default:
+		return nil, ErrInvalidFeeSplit{Reason: "unknown " + b.remainder.String()}
+	}
+
+	for i, fee := range b.fees {
+		fixed := fee.fixed.BigInt()
+		amounts[i].Add(amounts[i], &fixed)
+		if amounts[i].Cmp(uint128Max) > 0 {
+			return nil, ErrInvalidFeeSplit{Reason: "fee " + amounts[i].String() + " is out of range"}
+		}
+	}
+	return amounts, nil
+}
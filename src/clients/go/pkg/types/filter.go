@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"math"
+)
+
+type ErrInvalidAccountFilter struct {
+	Reason string
+}
+
+func (s ErrInvalidAccountFilter) Error() string { return "Invalid account filter: " + s.Reason + "." }
+
+// AccountFilterBuilder builds an AccountFilter for GetAccountTransfers or GetAccountHistory.
+// The cluster replies to an invalid filter with no results, so Build rejects it instead.
+//
+// The cluster only filters by account, debits or credits, and timestamps. The AccountFilter of
+// its protocol has no fields for the code or the user data, and its reserved bytes must be
+// zero. To match those, filter the transfers once they are returned.
+type AccountFilterBuilder struct {
+	filter AccountFilter
+	flags  AccountFilterFlags
+}
+
+// NewAccountFilter returns a builder of a filter for the transfers of accountID. Unless narrowed
+// down, the filter matches both debits and credits at any timestamp, in chronological order, up
+// to the most results a request can return.
+func NewAccountFilter(accountID Uint128) *AccountFilterBuilder {
+	return &AccountFilterBuilder{
+		filter: AccountFilter{
+			AccountID: accountID,
+			Limit:     uint32(MaxBatchSize(OperationGetAccountTransfers)),
+		},
+	}
+}
+
+// Debits matches the transfers that debit the account. Together with Credits, it matches both.
+func (b *AccountFilterBuilder) Debits() *AccountFilterBuilder {
+	b.flags.Debits = true
+	return b
+}
+
+// Credits matches the transfers that credit the account. Together with Debits, it matches both.
+func (b *AccountFilterBuilder) Credits() *AccountFilterBuilder {
+	b.flags.Credits = true
+	return b
+}
+
+// Reversed returns the results in reverse-chronological order.
+func (b *AccountFilterBuilder) Reversed() *AccountFilterBuilder {
+	b.flags.Reversed = true
+	return b
+}
+
+// Limit caps the number of results.
+func (b *AccountFilterBuilder) Limit(limit uint32) *AccountFilterBuilder {
+	b.filter.Limit = limit
+	return b
+}
+
+// Between matches the timestamps from min to max, both inclusive. Zero leaves either end
+// unbounded.
+func (b *AccountFilterBuilder) Between(min uint64, max uint64) *AccountFilterBuilder {
+	b.filter.TimestampMin = min
+	b.filter.TimestampMax = max
+	return b
+}
+
+// Build returns the filter, or ErrInvalidAccountFilter if the cluster would not accept it.
+func (b *AccountFilterBuilder) Build() (AccountFilter, error) {
+	filter := b.filter
+	flags := b.flags
+	if !flags.Debits && !flags.Credits {
+		flags.Debits, flags.Credits = true, true
+	}
+	filter.Flags = flags.ToUint32()
+
+	if err := filter.Validate(); err != nil {
+		return AccountFilter{}, err
+	}
+	return filter, nil
+}
+
+// Validate checks the filter as the cluster does before scanning, returning
+// ErrInvalidAccountFilter for a filter that would match nothing.
+func (o AccountFilter) Validate() error {
+	if o.AccountID == ToUint128(0) {
+		return ErrInvalidAccountFilter{Reason: "account ID must not be zero"}
+	}
+	if o.AccountID == uint128IntMax {
+		return ErrInvalidAccountFilter{Reason: "account ID must not be the maximum"}
+	}
+	if o.TimestampMin == math.MaxUint64 || o.TimestampMax == math.MaxUint64 {
+		return ErrInvalidAccountFilter{Reason: "timestamps must be below the maximum"}
+	}
+	if o.TimestampMax != 0 && o.TimestampMin > o.TimestampMax {
+		return ErrInvalidAccountFilter{
+			Reason: fmt.Sprintf("timestamp min %d is after timestamp max %d", o.TimestampMin, o.TimestampMax),
+		}
+	}
+	if limitMax := MaxBatchSize(OperationGetAccountTransfers); o.Limit == 0 || int(o.Limit) > limitMax {
+		return ErrInvalidAccountFilter{Reason: fmt.Sprintf("limit %d is not between 1 and %d", o.Limit, limitMax)}
+	}
+	flags := o.AccountFilterFlags()
+	if !flags.Debits && !flags.Credits {
+		return ErrInvalidAccountFilter{Reason: "neither debits nor credits are matched"}
+	}
+	if o.Flags&^(AccountFilterFlags{Debits: true, Credits: true, Reversed: true}).ToUint32() != 0 {
+		return ErrInvalidAccountFilter{Reason: "reserved flags must be zero"}
+	}
+	if o.Reserved != [24]uint8{} {
+		return ErrInvalidAccountFilter{Reason: "reserved field must be zero"}
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package types
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The names of the flags, by bit.
+var (
+	accountFlagNames = []string{
+		"linked",
+		"debits_must_not_exceed_credits",
+		"credits_must_not_exceed_debits",
+		"history",
+	}
+	transferFlagNames = []string{
+		"linked",
+		"pending",
+		"post_pending_transfer",
+		"void_pending_transfer",
+		"balancing_debit",
+		"balancing_credit",
+	}
+	accountFilterFlagNames = []string{"debits", "credits", "reversed"}
+)
+
+// ErrInvalidName is returned when parsing a name that none of the values of Type have.
+type ErrInvalidName struct {
+	Type string
+	Name string
+}
+
+func (s ErrInvalidName) Error() string {
+	return "Invalid " + s.Type + " name " + strconv.Quote(s.Name) + "."
+}
+
+// String returns the set flags, separated by "|", such as "linked|history", or "none".
+func (f AccountFlags) String() string {
+	return formatFlags(uint64(f.ToUint16()), accountFlagNames)
+}
+
+// String returns the set flags, separated by "|", such as "linked|pending", or "none".
+func (f TransferFlags) String() string {
+	return formatFlags(uint64(f.ToUint16()), transferFlagNames)
+}
+
+// String returns the set flags, separated by "|", such as "debits|credits", or "none".
+func (f AccountFilterFlags) String() string {
+	return formatFlags(uint64(f.ToUint32()), accountFilterFlagNames)
+}
+
+// AccountFlagsFromUint16 decodes the flags of Account.Flags, as Account.AccountFlags does.
+func AccountFlagsFromUint16(flags uint16) AccountFlags {
+	return Account{Flags: flags}.AccountFlags()
+}
+
+// TransferFlagsFromUint16 decodes the flags of Transfer.Flags, as Transfer.TransferFlags does.
+func TransferFlagsFromUint16(flags uint16) TransferFlags {
+	return Transfer{Flags: flags}.TransferFlags()
+}
+
+// AccountFilterFlagsFromUint32 decodes the flags of AccountFilter.Flags, as
+// AccountFilter.AccountFilterFlags does.
+func AccountFilterFlagsFromUint32(flags uint32) AccountFilterFlags {
+	return AccountFilter{Flags: flags}.AccountFilterFlags()
+}
+
+// ParseAccountFlags parses flags as rendered by AccountFlags.String, such as "history|linked",
+// in any order. "none" and "" parse to no flags.
+func ParseAccountFlags(flags string) (AccountFlags, error) {
+	bits, err := parseFlags(flags, "AccountFlags", accountFlagNames)
+	return AccountFlagsFromUint16(uint16(bits)), err
+}
+
+// ParseTransferFlags parses flags as rendered by TransferFlags.String, such as "pending|linked",
+// in any order. "none" and "" parse to no flags.
+func ParseTransferFlags(flags string) (TransferFlags, error) {
+	bits, err := parseFlags(flags, "TransferFlags", transferFlagNames)
+	return TransferFlagsFromUint16(uint16(bits)), err
+}
+
+// ParseAccountFilterFlags parses flags as rendered by AccountFilterFlags.String, such as
+// "credits|debits", in any order. "none" and "" parse to no flags.
+func ParseAccountFilterFlags(flags string) (AccountFilterFlags, error) {
+	bits, err := parseFlags(flags, "AccountFilterFlags", accountFilterFlagNames)
+	return AccountFilterFlagsFromUint32(uint32(bits)), err
+}
+
+func formatFlags(bits uint64, names []string) string {
+	var set []string
+	for bit, name := range names {
+		if bits&(1<<bit) != 0 {
+			set = append(set, name)
+		}
+	}
+	if len(set) == 0 {
+		return "none"
+	}
+	return strings.Join(set, "|")
+}
+
+func parseFlags(flags string, typeName string, names []string) (uint64, error) {
+	flags = strings.TrimSpace(flags)
+	if flags == "" || flags == "none" {
+		return 0, nil
+	}
+
+	var bits uint64
+	for _, name := range strings.Split(flags, "|") {
+		name = strings.TrimSpace(name)
+		bit := slices.Index(names, name)
+		if bit < 0 {
+			return 0, ErrInvalidName{Type: typeName, Name: name}
+		}
+		bits |= 1 << bit
+	}
+	return bits, nil
+}
+
+// ParseCreateAccountResult returns the result named by CreateAccountResult.String, such as
+// "AccountExists".
+func ParseCreateAccountResult(name string) (CreateAccountResult, error) {
+	result, ok := createAccountResults()[name]
+	if !ok {
+		return 0, ErrInvalidName{Type: "CreateAccountResult", Name: name}
+	}
+	return result, nil
+}
+
+// ParseCreateTransferResult returns the result named by CreateTransferResult.String, such as
+// "TransferExceedsCredits".
+func ParseCreateTransferResult(name string) (CreateTransferResult, error) {
+	result, ok := createTransferResults()[name]
+	if !ok {
+		return 0, ErrInvalidName{Type: "CreateTransferResult", Name: name}
+	}
+	return result, nil
+}
+
+var (
+	createAccountResults  = sync.OnceValue(resultNames[CreateAccountResult])
+	createTransferResults = sync.OnceValue(resultNames[CreateTransferResult])
+)
+
+// resultNames indexes the results by the names of the generated String methods, which name
+// the results they don't know by their number instead.
+func resultNames[R interface {
+	~uint32
+	String() string
+}]() map[string]R {
+	names := make(map[string]R)
+	for code := range 256 {
+		result := R(code)
+		if name := result.String(); !strings.Contains(name, "(") {
+			names[name] = result
+		}
+	}
+	return names
+}
+
+// fields formats the fields of a struct for its String method, as "Name{key: value, ...}".
+type fields struct {
+	builder strings.Builder
+	empty   bool
+}
+
+func newFields(name string) *fields {
+	f := &fields{empty: true}
+	f.builder.WriteString(name)
+	f.builder.WriteByte('{')
+	return f
+}
+
+func (f *fields) add(key string, value string) *fields {
+	if !f.empty {
+		f.builder.WriteString(", ")
+	}
+	f.empty = false
+	f.builder.WriteString(key)
+	f.builder.WriteString(": ")
+	f.builder.WriteString(value)
+	return f
+}
+
+// id adds value in hex, as IDs are mostly random.
+func (f *fields) id(key string, value Uint128) *fields {
+	return f.add(key, "0x"+value.HexString())
+}
+
+// optional adds value unless it is zero, for fields that are mostly unused.
+func (f *fields) optional(key string, value string) *fields {
+	if value != "0" && value != "0x0" {
+		f.add(key, value)
+	}
+	return f
+}
+
+func (f *fields) String() string {
+	return f.builder.String() + "}"
+}
+
+// String renders the account for logs and test failures, with its ID in hex, its balances in
+// decimal and its flags by name. The user data and the timestamp are left out while zero, and
+// the user data is redacted as set with SetLogRedaction.
+func (o Account) String() string {
+	o = LogRedaction().Account(o)
+	return newFields("Account").
+		id("id", o.ID).
+		add("ledger", strconv.FormatUint(uint64(o.Ledger), 10)).
+		add("code", strconv.FormatUint(uint64(o.Code), 10)).
+		add("flags", o.AccountFlags().String()).
+		add("debits_pending", o.DebitsPending.String()).
+		add("debits_posted", o.DebitsPosted.String()).
+		add("credits_pending", o.CreditsPending.String()).
+		add("credits_posted", o.CreditsPosted.String()).
+		optional("user_data_128", "0x"+o.UserData128.HexString()).
+		optional("user_data_64", strconv.FormatUint(o.UserData64, 10)).
+		optional("user_data_32", strconv.FormatUint(uint64(o.UserData32), 10)).
+		optional("timestamp", strconv.FormatUint(o.Timestamp, 10)).
+		String()
+}
+
+// String renders the transfer for logs and test failures, with its IDs in hex, its amount in
+// decimal and its flags by name. The pending ID, the timeout, the user data and the timestamp
+// are left out while zero, and the user data is redacted as set with SetLogRedaction.
+func (o Transfer) String() string {
+	o = LogRedaction().Transfer(o)
+	return newFields("Transfer").
+		id("id", o.ID).
+		id("debit_account_id", o.DebitAccountID).
+		id("credit_account_id", o.CreditAccountID).
+		add("amount", o.Amount.String()).
+		add("ledger", strconv.FormatUint(uint64(o.Ledger), 10)).
+		add("code", strconv.FormatUint(uint64(o.Code), 10)).
+		add("flags", o.TransferFlags().String()).
+		optional("pending_id", "0x"+o.PendingID.HexString()).
+		optional("timeout", strconv.FormatUint(uint64(o.Timeout), 10)).
+		optional("user_data_128", "0x"+o.UserData128.HexString()).
+		optional("user_data_64", strconv.FormatUint(o.UserData64, 10)).
+		optional("user_data_32", strconv.FormatUint(uint64(o.UserData32), 10)).
+		optional("timestamp", strconv.FormatUint(o.Timestamp, 10)).
+		String()
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// IDFromHash derives a stable ID from a business key, such as an invoice number, so that
+// resubmitting the transfer it creates is idempotent without a table of the IDs already used.
+//
+// The ID is the first 16 bytes of the SHA-256 of namespace and parts, each prefixed with its
+// length so that different splits of the same bytes give different IDs. Use a namespace per
+// kind of key, such as "invoice", so that equal keys of different kinds don't collide. Unlike
+// those of ID, the IDs are not sortable by time.
+func IDFromHash(namespace string, parts ...[]byte) Uint128 {
+	hash := sha256.New()
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(namespace)))
+	hash.Write(length[:])
+	hash.Write([]byte(namespace))
+	for _, part := range parts {
+		binary.LittleEndian.PutUint64(length[:], uint64(len(part)))
+		hash.Write(length[:])
+		hash.Write(part)
+	}
+
+	var id [16]byte
+	copy(id[:], hash.Sum(nil))
+	return BytesToUint128(id)
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+)
+
+var uint128JSONHex atomic.Bool
+
+// SetUint128JSONHex selects how Uint128 values are marshaled to JSON: as decimal strings
+// (the default) or, when hex is true, as "0x"-prefixed hex strings.
+// Unmarshaling always accepts both, as well as plain JSON numbers.
+func SetUint128JSONHex(hex bool) {
+	uint128JSONHex.Store(hex)
+}
+
+// MarshalJSON encodes the value as a JSON string, since 128-bit integers don't survive the
+// float64 numbers most JSON decoders use.
+func (value Uint128) MarshalJSON() ([]byte, error) {
+	if uint128JSONHex.Load() {
+		return json.Marshal("0x" + value.HexString())
+	}
+
+	return json.Marshal(value.String())
+}
+
+func (value *Uint128) UnmarshalJSON(data []byte) error {
+	var text string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	} else {
+		// Accept plain JSON integers too, but not floats or exponents.
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return err
+		}
+		text = number.String()
+	}
+
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		parsed, err := HexStringToUint128(text[2:])
+		if err != nil {
+			return err
+		}
+		*value = parsed
+		return nil
+	}
+
+	parsed, err := DecStringToUint128(text)
+	if err != nil {
+		return err
+	}
+	*value = parsed
+	return nil
+}
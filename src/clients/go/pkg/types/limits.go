@@ -0,0 +1,116 @@
+package types
+
+const (
+	// MessageSizeMax is the size of the largest message tb_client exchanges with the cluster,
+	// as configured when the client library was built. It is hard-coded to the default message
+	// size of the cluster, 1 MiB, and is too large for a cluster configured with a smaller one.
+	MessageSizeMax = 1024 * 1024
+
+	// messageHeaderSize is the size of the header that precedes the body of every message.
+	messageHeaderSize = 256
+
+	// MessageBodySizeMax is how much of a message is left for the events or results.
+	MessageBodySizeMax = MessageSizeMax - messageHeaderSize
+)
+
+// The sizes of events and results on the wire.
+const (
+	AccountSize             = 128
+	TransferSize            = 128
+	Uint128Size             = 16
+	AccountFilterSize       = 64
+	AccountBalanceSize      = 128
+	AccountEventResultSize  = 8
+	TransferEventResultSize = 8
+)
+
+// EventSize returns the size of a single event of op on the wire, or 0 for an unknown op.
+func EventSize(op Operation) int {
+	switch op {
+	case OperationCreateAccounts:
+		return AccountSize
+	case OperationCreateTransfers:
+		return TransferSize
+	case OperationLookupAccounts, OperationLookupTransfers:
+		return Uint128Size
+	case OperationGetAccountTransfers, OperationGetAccountHistory:
+		return AccountFilterSize
+	default:
+		return 0
+	}
+}
+
+// ResultSize returns the size of a single result of op on the wire, or 0 for an unknown op.
+func ResultSize(op Operation) int {
+	switch op {
+	case OperationCreateAccounts:
+		return AccountEventResultSize
+	case OperationCreateTransfers:
+		return TransferEventResultSize
+	case OperationLookupAccounts:
+		return AccountSize
+	case OperationLookupTransfers, OperationGetAccountTransfers:
+		return TransferSize
+	case OperationGetAccountHistory:
+		return AccountBalanceSize
+	default:
+		return 0
+	}
+}
+
+// MaxBatchSize returns how many events of op fit in a single request, such that both the
+// events and their results fit in a message, or 0 for an unknown op.
+//
+// For GetAccountTransfers and GetAccountHistory, which take a single filter, it is instead the
+// maximum number of results a request can return.
+func MaxBatchSize(op Operation) int {
+	size := max(EventSize(op), ResultSize(op))
+	if size == 0 {
+		return 0
+	}
+	return MessageBodySizeMax / size
+}
+
+// BatchBudget tracks how much of a message the events of a batch being built, and the results
+// they will produce, take up.
+type BatchBudget struct {
+	op          Operation
+	eventBytes  int
+	resultBytes int
+}
+
+// NewBatchBudget returns an empty budget for a batch of op.
+func NewBatchBudget(op Operation) *BatchBudget {
+	return &BatchBudget{op: op}
+}
+
+// Fits reports whether count more events fit in the batch.
+func (b *BatchBudget) Fits(count int) bool {
+	eventSize, resultSize := EventSize(b.op), ResultSize(b.op)
+	if eventSize == 0 {
+		return false
+	}
+	return b.eventBytes+count*eventSize <= MessageBodySizeMax &&
+		b.resultBytes+count*resultSize <= MessageBodySizeMax
+}
+
+// Add accounts for count more events if they fit in the batch, reporting whether they did.
+func (b *BatchBudget) Add(count int) bool {
+	if !b.Fits(count) {
+		return false
+	}
+	b.eventBytes += count * EventSize(b.op)
+	b.resultBytes += count * ResultSize(b.op)
+	return true
+}
+
+// Bytes returns the size of the events added so far.
+func (b *BatchBudget) Bytes() int {
+	return b.eventBytes
+}
+
+// Reset empties the budget for the next batch.
+func (b *BatchBudget) Reset() {
+	b.eventBytes = 0
+	b.resultBytes = 0
+}
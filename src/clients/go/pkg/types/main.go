@@ -1,21 +1,20 @@
 package types
 
-/*
-#include "../native/tb_client.h"
-*/
-import "C"
 import (
 	"crypto/rand"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"strconv"
 	"sync"
 	"time"
 	"unsafe"
 )
 
-type Uint128 C.tb_uint128_t
+// Uint128 is an unsigned 128-bit integer, in the little-endian layout of tb_uint128_t, without
+// depending on cgo.
+type Uint128 [16]byte
 
 func (value Uint128) Bytes() [16]byte {
 	return *(*[16]byte)(unsafe.Pointer(&value))
@@ -27,8 +26,20 @@ func swapEndian(bytes []byte) {
 	}
 }
 
+// String returns the value as a decimal integer.
 func (value Uint128) String() string {
 	bytes := value.Bytes()
+	if high := binary.LittleEndian.Uint64(bytes[8:]); high == 0 {
+		return strconv.FormatUint(binary.LittleEndian.Uint64(bytes[:8]), 10)
+	}
+
+	bigint := value.BigInt()
+	return bigint.String()
+}
+
+// HexString returns the value as a hex integer, without leading zeros.
+func (value Uint128) HexString() string {
+	bytes := value.Bytes()
 
 	// Convert little-endian Uint128 number to big-endian string.
 	swapEndian(bytes[:])
@@ -81,6 +92,26 @@ func HexStringToUint128(value string) (Uint128, error) {
 	return BytesToUint128(bytes), nil
 }
 
+var uint128Max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+// DecStringToUint128 converts a decimal integer, made only of digits, to a Uint128.
+func DecStringToUint128(value string) (Uint128, error) {
+	if len(value) == 0 {
+		return Uint128{}, fmt.Errorf("Uint128 decimal string must not be empty.")
+	}
+	for _, digit := range value {
+		if digit < '0' || digit > '9' {
+			return Uint128{}, fmt.Errorf("Uint128 decimal string %q must only contain digits.", value)
+		}
+	}
+
+	bigint, ok := new(big.Int).SetString(value, 10)
+	if !ok || bigint.Cmp(uint128Max) > 0 {
+		return Uint128{}, fmt.Errorf("Uint128 decimal string %q is out of range.", value)
+	}
+	return BigIntToUint128(*bigint), nil
+}
+
 // BigIntToUint128 converts a [math/big.Int] to a Uint128.
 func BigIntToUint128(value big.Int) Uint128 {
 	// big.Int bytes are big-endian so convert them to little-endian for Uint128 bytes.
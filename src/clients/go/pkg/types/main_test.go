@@ -1,9 +1,15 @@
 package types
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+	"unsafe"
 )
 
 func Test_HexStringToUint128(t *testing.T) {
@@ -21,7 +27,7 @@ func Test_HexStringToUint128(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Expected %s to be a valid hex string, got: %s", test, err)
 		}
-		thereAndBack := res.String()
+		thereAndBack := res.HexString()
 		if thereAndBack != test {
 			t.Fatalf("Expected %s to be %s, got %s", test, test, thereAndBack)
 		}
@@ -64,7 +70,7 @@ func Test_BigIntToUint128(t *testing.T) {
 
 		bigint := uint128.BigInt()
 		uint128_back := BigIntToUint128(bigint)
-		string_back := uint128_back.String()
+		string_back := uint128_back.HexString()
 
 		if string_back != test {
 			t.Fatalf("Expected %s to be %s, got %s", test, test, string_back)
@@ -112,4 +118,1106 @@ func Test_ID(t *testing.T) {
 	}
 
 	finish.Wait()
-}
\ No newline at end of file
+}
+func Test_PostVoidPendingTransfer(t *testing.T) {
+	pendingID := ToUint128(42)
+
+	post := PostPendingTransfer(pendingID, ToUint128(10))
+	if post.PendingID != pendingID || post.Amount != ToUint128(10) {
+		t.Fatalf("Expected post to reference pending %s with amount 10, got %+v", pendingID, post)
+	}
+	if !post.TransferFlags().PostPendingTransfer || post.TransferFlags().VoidPendingTransfer {
+		t.Fatalf("Expected only the post_pending_transfer flag, got %d", post.Flags)
+	}
+
+	void := VoidPendingTransfer(pendingID)
+	if void.PendingID != pendingID || void.Amount != ToUint128(0) {
+		t.Fatalf("Expected void to reference pending %s with amount 0, got %+v", pendingID, void)
+	}
+	if !void.TransferFlags().VoidPendingTransfer || void.TransferFlags().PostPendingTransfer {
+		t.Fatalf("Expected only the void_pending_transfer flag, got %d", void.Flags)
+	}
+
+	if post.ID == void.ID || post.ID == ToUint128(0) {
+		t.Fatalf("Expected distinct non-zero IDs, got %s and %s", post.ID, void.ID)
+	}
+}
+
+func Test_NewBalancingTransfer(t *testing.T) {
+	debit, credit := ToUint128(1), ToUint128(2)
+
+	transfer := NewBalancingTransfer(BalancingDebit, debit, credit, AmountMax, 7, 10)
+	if transfer.DebitAccountID != debit || transfer.CreditAccountID != credit || transfer.Amount != AmountMax ||
+		transfer.Ledger != 7 || transfer.Code != 10 {
+		t.Fatalf("Expected a transfer of up to AmountMax from 1 to 2 on ledger 7, got %+v", transfer)
+	}
+	if flags := transfer.TransferFlags(); !flags.BalancingDebit || flags.BalancingCredit {
+		t.Fatalf("Expected only the balancing_debit flag, got %d", transfer.Flags)
+	}
+	if result := transfer.Validate(); result != TransferOK {
+		t.Fatalf("Expected a valid transfer, got %s", result)
+	}
+
+	both := NewBalancingTransfer(BalancingDebit|BalancingCredit, debit, credit, ToUint128(5), 7, 10)
+	if flags := both.TransferFlags(); !flags.BalancingDebit || !flags.BalancingCredit {
+		t.Fatalf("Expected both balancing flags, got %d", both.Flags)
+	}
+	if both.ID == transfer.ID || both.ID == ToUint128(0) {
+		t.Fatalf("Expected distinct non-zero IDs, got %s and %s", both.ID, transfer.ID)
+	}
+}
+
+func Test_LinkedChain(t *testing.T) {
+	transfer := func(id uint64, flags uint16) Transfer {
+		return Transfer{ID: ToUint128(id), Flags: flags}
+	}
+	linked := TransferFlags{Linked: true}.ToUint16()
+
+	var chain LinkedChain
+	batch, err := chain.
+		Chain(transfer(1, 0), transfer(2, 0), transfer(3, linked)).
+		Append(transfer(4, 0)).
+		Chain(transfer(5, 0)).
+		Build()
+	if err != nil {
+		t.Fatalf("Expected chain to build, got: %s", err)
+	}
+
+	expected := []bool{true, true, false, false, false}
+	for i, transfer := range batch {
+		if transfer.TransferFlags().Linked != expected[i] {
+			t.Fatalf("Expected transfer %d linked=%v, got flags %d", i, expected[i], transfer.Flags)
+		}
+	}
+
+	var open LinkedChain
+	_, err = open.Chain(transfer(1, 0)).Append(transfer(2, linked), transfer(3, linked)).Build()
+	if err != (ErrLinkedChainOpen{Index: 1}) {
+		t.Fatalf("Expected open chain at index 1, got: %v", err)
+	}
+
+	// Chain does not join a chain left open by Append.
+	var joined LinkedChain
+	_, err = joined.
+		Chain(transfer(1, 0)).
+		Append(transfer(2, linked), transfer(3, linked)).
+		Chain(transfer(4, 0), transfer(5, 0)).
+		Build()
+	if err != (ErrLinkedChainOpen{Index: 1}) {
+		t.Fatalf("Expected open chain at index 1, got: %v", err)
+	}
+
+	var joinedAccounts LinkedAccountChain
+	_, err = joinedAccounts.
+		Append(Account{ID: ToUint128(1), Flags: AccountFlags{Linked: true}.ToUint16()}).
+		Chain(Account{ID: ToUint128(2)}).
+		Build()
+	if err != (ErrLinkedChainOpen{Index: 0}) {
+		t.Fatalf("Expected open account chain at index 0, got: %v", err)
+	}
+
+	var accounts LinkedAccountChain
+	_, err = accounts.Append(Account{ID: ToUint128(1), Flags: AccountFlags{Linked: true}.ToUint16()}).Build()
+	if err != (ErrLinkedChainOpen{Index: 0}) {
+		t.Fatalf("Expected open account chain at index 0, got: %v", err)
+	}
+}
+
+func Test_Uint128JSON(t *testing.T) {
+	max, err := HexStringToUint128("ffffffffffffffffffffffffffffffff")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transfer := Transfer{ID: max, Amount: ToUint128(100), Ledger: 1, Code: 2}
+	data, err := json.Marshal(transfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"id":"340282366920938463463374607431768211455"`) {
+		t.Fatalf("Expected decimal ID in %s", data)
+	}
+	if !strings.Contains(string(data), `"amount":"100"`) {
+		t.Fatalf("Expected decimal amount in %s", data)
+	}
+
+	var decoded Transfer
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != transfer {
+		t.Fatalf("Expected %+v to round-trip, got %+v", transfer, decoded)
+	}
+
+	SetUint128JSONHex(true)
+	data, err = json.Marshal(ToUint128(255))
+	SetUint128JSONHex(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"0xff"` {
+		t.Fatalf("Expected hex encoding, got %s", data)
+	}
+
+	var value Uint128
+	for input, expected := range map[string]Uint128{`"0xff"`: ToUint128(255), `42`: ToUint128(42), `"7"`: ToUint128(7)} {
+		if err := json.Unmarshal([]byte(input), &value); err != nil {
+			t.Fatalf("Expected %s to be valid, got: %s", input, err)
+		}
+		if value != expected {
+			t.Fatalf("Expected %s to decode to %s, got %s", input, expected, value)
+		}
+	}
+
+	for _, input := range []string{`"340282366920938463463374607431768211456"`, `"-1"`, `"1.5"`, `1.5`, `""`} {
+		if err := json.Unmarshal([]byte(input), &value); err == nil {
+			t.Fatalf("Expected %s to be rejected", input)
+		}
+	}
+}
+
+func Test_MaxBatchSize(t *testing.T) {
+	for _, op := range []Operation{
+		OperationCreateAccounts,
+		OperationCreateTransfers,
+		OperationLookupAccounts,
+		OperationLookupTransfers,
+		OperationGetAccountTransfers,
+		OperationGetAccountHistory,
+	} {
+		if got := MaxBatchSize(op); got != 8190 {
+			t.Fatalf("Expected %s to allow 8190 events, got %d", op, got)
+		}
+	}
+
+	if got := MaxBatchSize(Operation(0)); got != 0 {
+		t.Fatalf("Expected unknown operation to allow no events, got %d", got)
+	}
+}
+
+func Test_BatchBudget(t *testing.T) {
+	sizes := map[string][2]uintptr{
+		"Account":             {unsafe.Sizeof(Account{}), AccountSize},
+		"Transfer":            {unsafe.Sizeof(Transfer{}), TransferSize},
+		"Uint128":             {unsafe.Sizeof(Uint128{}), Uint128Size},
+		"AccountFilter":       {unsafe.Sizeof(AccountFilter{}), AccountFilterSize},
+		"AccountBalance":      {unsafe.Sizeof(AccountBalance{}), AccountBalanceSize},
+		"AccountEventResult":  {unsafe.Sizeof(AccountEventResult{}), AccountEventResultSize},
+		"TransferEventResult": {unsafe.Sizeof(TransferEventResult{}), TransferEventResultSize},
+	}
+	for name, size := range sizes {
+		if size[0] != size[1] {
+			t.Fatalf("Expected %s to be %d bytes, got %d", name, size[1], size[0])
+		}
+	}
+
+	// Lookups are bounded by the size of their results rather than of their events.
+	budget := NewBatchBudget(OperationLookupAccounts)
+	if !budget.Add(8000) || budget.Add(191) || !budget.Add(190) {
+		t.Fatalf("Expected lookups to fit in 8190 results")
+	}
+	if budget.Bytes() != 8190*Uint128Size {
+		t.Fatalf("Expected %d bytes of events, got %d", 8190*Uint128Size, budget.Bytes())
+	}
+
+	budget.Reset()
+	if !budget.Fits(8190) {
+		t.Fatalf("Expected an empty budget to fit a full batch")
+	}
+}
+
+func Test_DecStringToUint128(t *testing.T) {
+	tests := []string{
+		"0",
+		"1",
+		"18446744073709551615",
+		"18446744073709551616",
+		"340282366920938463463374607431768211455",
+	}
+
+	for _, test := range tests {
+		res, err := DecStringToUint128(test)
+		if err != nil {
+			t.Fatalf("Expected %s to be a valid decimal string, got: %s", test, err)
+		}
+		thereAndBack := res.String()
+		if thereAndBack != test {
+			t.Fatalf("Expected %s to be %s, got %s", test, test, thereAndBack)
+		}
+	}
+
+	max, err := DecStringToUint128("340282366920938463463374607431768211455")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if max.HexString() != "ffffffffffffffffffffffffffffffff" {
+		t.Fatalf("Expected 2^128-1 to be all ones, got %s", max.HexString())
+	}
+
+	for _, test := range []string{"", "-1", "+1", " 1", "1.0", "0x10", "340282366920938463463374607431768211456"} {
+		if _, err := DecStringToUint128(test); err == nil {
+			t.Fatalf("Expected %q to be rejected", test)
+		}
+	}
+}
+
+func Test_TransferState(t *testing.T) {
+	pending := Transfer{
+		ID:        ToUint128(1),
+		Timeout:   1,
+		Flags:     TransferFlags{Pending: true}.ToUint16(),
+		Timestamp: uint64(time.Now().UnixNano()),
+	}
+	post := PostPendingTransfer(pending.ID, ToUint128(0))
+	void := VoidPendingTransfer(pending.ID)
+	later := Transfer{ID: ToUint128(2), Timestamp: pending.ExpiresAt()}
+
+	tests := []struct {
+		transfer Transfer
+		related  []Transfer
+		expected TransferStatus
+	}{
+		{Transfer{ID: ToUint128(3)}, nil, TransferStatusPosted},
+		{post, nil, TransferStatusPosted},
+		{pending, nil, TransferStatusPendingOpen},
+		{pending, []Transfer{later, post}, TransferStatusPendingPosted},
+		{pending, []Transfer{void}, TransferStatusPendingVoided},
+		{pending, []Transfer{later}, TransferStatusPendingExpired},
+	}
+	for _, test := range tests {
+		if got := TransferState(test.transfer, test.related); got != test.expected {
+			t.Fatalf("Expected %s, got %s", test.expected, got)
+		}
+	}
+
+	// Without a timeout, a pending transfer never expires.
+	pending.Timeout = 0
+	if got := TransferState(pending, []Transfer{later}); got != TransferStatusPendingOpen {
+		t.Fatalf("Expected %s, got %s", TransferStatusPendingOpen, got)
+	}
+}
+
+func Test_AccountValidate(t *testing.T) {
+	valid := Account{ID: ToUint128(1), Ledger: 1, Code: 1}
+	if got := valid.Validate(); got != AccountOK {
+		t.Fatalf("Expected %s, got %s", AccountOK, got)
+	}
+
+	tests := []struct {
+		change   func(*Account)
+		expected CreateAccountResult
+	}{
+		{func(a *Account) { a.Timestamp = 1 }, AccountTimestampMustBeZero},
+		{func(a *Account) { a.Reserved = 1 }, AccountReservedField},
+		{func(a *Account) { a.Flags = 1 << 15 }, AccountReservedFlag},
+		{func(a *Account) { a.ID = ToUint128(0) }, AccountIDMustNotBeZero},
+		{func(a *Account) { a.ID = uint128IntMax }, AccountIDMustNotBeIntMax},
+		{func(a *Account) {
+			a.Flags = AccountFlags{DebitsMustNotExceedCredits: true, CreditsMustNotExceedDebits: true}.ToUint16()
+		}, AccountFlagsAreMutuallyExclusive},
+		{func(a *Account) { a.DebitsPending = ToUint128(1) }, AccountDebitsPendingMustBeZero},
+		{func(a *Account) { a.CreditsPosted = ToUint128(1) }, AccountCreditsPostedMustBeZero},
+		{func(a *Account) { a.Ledger = 0 }, AccountLedgerMustNotBeZero},
+		{func(a *Account) { a.Code = 0 }, AccountCodeMustNotBeZero},
+	}
+	for _, test := range tests {
+		account := valid
+		test.change(&account)
+		if got := account.Validate(); got != test.expected {
+			t.Fatalf("Expected %s, got %s", test.expected, got)
+		}
+	}
+}
+
+func Test_TransferValidate(t *testing.T) {
+	valid := Transfer{
+		ID:              ToUint128(1),
+		DebitAccountID:  ToUint128(2),
+		CreditAccountID: ToUint128(3),
+		Amount:          ToUint128(10),
+		Ledger:          1,
+		Code:            1,
+	}
+	if got := valid.Validate(); got != TransferOK {
+		t.Fatalf("Expected %s, got %s", TransferOK, got)
+	}
+	if got := PostPendingTransfer(ToUint128(1), ToUint128(0)).Validate(); got != TransferOK {
+		t.Fatalf("Expected %s, got %s", TransferOK, got)
+	}
+
+	tests := []struct {
+		change   func(*Transfer)
+		expected CreateTransferResult
+	}{
+		{func(t *Transfer) { t.Timestamp = 1 }, TransferTimestampMustBeZero},
+		{func(t *Transfer) { t.Flags = 1 << 15 }, TransferReservedFlag},
+		{func(t *Transfer) { t.ID = ToUint128(0) }, TransferIDMustNotBeZero},
+		{func(t *Transfer) { t.ID = uint128IntMax }, TransferIDMustNotBeIntMax},
+		{func(t *Transfer) {
+			t.Flags = TransferFlags{PostPendingTransfer: true, VoidPendingTransfer: true}.ToUint16()
+		}, TransferFlagsAreMutuallyExclusive},
+		{func(t *Transfer) {
+			t.Flags = TransferFlags{Pending: true, VoidPendingTransfer: true}.ToUint16()
+		}, TransferFlagsAreMutuallyExclusive},
+		{func(t *Transfer) {
+			t.Flags = TransferFlags{VoidPendingTransfer: true}.ToUint16()
+		}, TransferPendingIDMustNotBeZero},
+		{func(t *Transfer) {
+			t.Flags = TransferFlags{PostPendingTransfer: true}.ToUint16()
+			t.PendingID = t.ID
+		}, TransferPendingIDMustBeDifferent},
+		{func(t *Transfer) { t.DebitAccountID = ToUint128(0) }, TransferDebitAccountIDMustNotBeZero},
+		{func(t *Transfer) { t.CreditAccountID = uint128IntMax }, TransferCreditAccountIDMustNotBeIntMax},
+		{func(t *Transfer) { t.CreditAccountID = t.DebitAccountID }, TransferAccountsMustBeDifferent},
+		{func(t *Transfer) { t.PendingID = ToUint128(4) }, TransferPendingIDMustBeZero},
+		{func(t *Transfer) { t.Timeout = 1 }, TransferTimeoutReservedForPendingTransfer},
+		{func(t *Transfer) { t.Amount = ToUint128(0) }, TransferAmountMustNotBeZero},
+		{func(t *Transfer) { t.Ledger = 0 }, TransferLedgerMustNotBeZero},
+		{func(t *Transfer) { t.Code = 0 }, TransferCodeMustNotBeZero},
+	}
+	for _, test := range tests {
+		transfer := valid
+		test.change(&transfer)
+		if got := transfer.Validate(); got != test.expected {
+			t.Fatalf("Expected %s, got %s", test.expected, got)
+		}
+	}
+
+	// Balancing transfers may leave the amount zero.
+	balancing := valid
+	balancing.Amount = ToUint128(0)
+	balancing.Flags = TransferFlags{BalancingDebit: true}.ToUint16()
+	if got := balancing.Validate(); got != TransferOK {
+		t.Fatalf("Expected %s, got %s", TransferOK, got)
+	}
+}
+
+func Test_Uint128SQL(t *testing.T) {
+	value, err := DecStringToUint128("340282366920938463463374607431768211455")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := value.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != "340282366920938463463374607431768211455" {
+		t.Fatalf("Expected a decimal string, got %v", stored)
+	}
+
+	bytes, err := Uint128Bytes(ToUint128(0x0102)).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBytes := make([]byte, 16)
+	expectedBytes[14], expectedBytes[15] = 0x01, 0x02
+	if string(bytes.([]byte)) != string(expectedBytes) {
+		t.Fatalf("Expected big-endian bytes, got %x", bytes)
+	}
+
+	tests := []struct {
+		src      any
+		expected Uint128
+	}{
+		{int64(42), ToUint128(42)},
+		{"42", ToUint128(42)},
+		{[]byte("42"), ToUint128(42)},
+		{stored, value},
+		{[]byte("1234567890123456"), ToUint128(1234567890123456)},
+		{bytes, ToUint128(0x0102)},
+	}
+	for _, test := range tests {
+		var scanned Uint128
+		if err := scanned.Scan(test.src); err != nil {
+			t.Fatal(err)
+		}
+		if scanned != test.expected {
+			t.Fatalf("Expected %s, got %s scanning %v", test.expected, scanned, test.src)
+		}
+	}
+
+	for _, invalid := range []any{nil, int64(-1), "-1", "1.5", []byte{1, 2}, 1.5} {
+		var scanned Uint128
+		if err := scanned.Scan(invalid); err == nil {
+			t.Fatalf("Expected an error scanning %v", invalid)
+		}
+	}
+
+	var scanned Uint128Bytes
+	if err := scanned.Scan([]byte("42")); err == nil {
+		t.Fatal("Expected an error scanning fewer than 16 bytes")
+	}
+}
+
+func Test_AccountFilterBuilder(t *testing.T) {
+	id := ToUint128(1)
+
+	filter, err := NewAccountFilter(id).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := AccountFilter{
+		AccountID: id,
+		Limit:     uint32(MaxBatchSize(OperationGetAccountTransfers)),
+		Flags:     AccountFilterFlags{Debits: true, Credits: true}.ToUint32(),
+	}
+	if filter != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, filter)
+	}
+
+	filter, err = NewAccountFilter(id).Credits().Reversed().Limit(100).Between(10, 20).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = AccountFilter{
+		AccountID:    id,
+		TimestampMin: 10,
+		TimestampMax: 20,
+		Limit:        100,
+		Flags:        AccountFilterFlags{Credits: true, Reversed: true}.ToUint32(),
+	}
+	if filter != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, filter)
+	}
+
+	for _, builder := range []*AccountFilterBuilder{
+		NewAccountFilter(ToUint128(0)),
+		NewAccountFilter(uint128IntMax),
+		NewAccountFilter(id).Between(20, 10),
+		NewAccountFilter(id).Between(math.MaxUint64, 0),
+		NewAccountFilter(id).Limit(0),
+		NewAccountFilter(id).Limit(uint32(MaxBatchSize(OperationGetAccountTransfers) + 1)),
+	} {
+		if _, err := builder.Build(); !errors.As(err, new(ErrInvalidAccountFilter)) {
+			t.Fatalf("Expected ErrInvalidAccountFilter for %+v, got %v", builder.filter, err)
+		}
+	}
+
+	// A filter built by hand is checked the same way.
+	for _, invalid := range []AccountFilter{
+		{AccountID: id, Limit: 1},
+		{AccountID: id, Limit: 1, Flags: 1<<3 | 1},
+		{AccountID: id, Limit: 1, Flags: 1, Reserved: [24]uint8{1}},
+	} {
+		if invalid.Validate() == nil {
+			t.Fatalf("Expected %+v to be invalid", invalid)
+		}
+	}
+	if err := (AccountFilter{AccountID: id, Limit: 1, Flags: 1}).Validate(); err != nil {
+		t.Fatalf("Expected a valid filter, got %s", err)
+	}
+}
+
+func Test_PartitionResults(t *testing.T) {
+	type payment struct{ reference string }
+	payments := []payment{{"a"}, {"b"}, {"c"}, {"d"}}
+
+	succeeded, failed := PartitionResults(payments, []TransferEventResult{
+		{Index: 1, Result: TransferExceedsCredits},
+		{Index: 3, Result: TransferExists},
+	})
+	if len(succeeded) != 2 || succeeded[0] != payments[0] || succeeded[1] != payments[2] {
+		t.Fatalf("Expected payments a and c to succeed, got %+v", succeeded)
+	}
+	if len(failed) != 2 || failed[0].Input != payments[1] || failed[0].Result.Result != TransferExceedsCredits ||
+		failed[1].Input != payments[3] || failed[1].Result.Result != TransferExists {
+		t.Fatalf("Expected payments b and d to fail, got %+v", failed)
+	}
+
+	accounts, failedAccounts := PartitionResults([]string{"x"}, []AccountEventResult(nil))
+	if len(accounts) != 1 || len(failedAccounts) != 0 {
+		t.Fatalf("Expected every account to succeed, got %v and %+v", accounts, failedAccounts)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected a result beyond the inputs to panic")
+		}
+	}()
+	PartitionResults([]string{"x"}, []AccountEventResult{{Index: 1, Result: AccountExists}})
+}
+
+func Test_Uint128Arithmetic(t *testing.T) {
+	lowMax := ToUint128(^uint64(0))
+	highOne := BytesToUint128([16]byte{8: 1})
+
+	// The carry and the borrow cross the halves.
+	if sum, ok := lowMax.AddChecked(ToUint128(1)); !ok || sum != highOne {
+		t.Fatalf("Expected %s, got %s and %v", highOne, sum, ok)
+	}
+	if difference, ok := highOne.SubChecked(ToUint128(1)); !ok || difference != lowMax {
+		t.Fatalf("Expected %s, got %s and %v", lowMax, difference, ok)
+	}
+
+	if _, ok := uint128IntMax.AddChecked(ToUint128(1)); ok {
+		t.Fatal("Expected the sum to overflow")
+	}
+	if _, ok := ToUint128(1).SubChecked(ToUint128(2)); ok {
+		t.Fatal("Expected the difference to overflow")
+	}
+	if sum := uint128IntMax.AddSaturating(highOne); sum != uint128IntMax {
+		t.Fatalf("Expected the sum to saturate, got %s", sum)
+	}
+	if difference := ToUint128(1).SubSaturating(highOne); difference != ToUint128(0) {
+		t.Fatalf("Expected the difference to saturate, got %s", difference)
+	}
+	if sum := ToUint128(2).AddSaturating(ToUint128(3)); sum != ToUint128(5) {
+		t.Fatalf("Expected 5, got %s", sum)
+	}
+}
+
+func Test_IDFromHash(t *testing.T) {
+	id := IDFromHash("invoice", []byte("INV-1001"))
+	if id != IDFromHash("invoice", []byte("INV-1001")) {
+		t.Fatal("Expected the same key to give the same ID")
+	}
+	if id == ToUint128(0) {
+		t.Fatal("Expected a non-zero ID")
+	}
+
+	for _, other := range []Uint128{
+		IDFromHash("refund", []byte("INV-1001")),
+		IDFromHash("invoice", []byte("INV-1002")),
+		IDFromHash("invoice", []byte("INV-"), []byte("1001")),
+		IDFromHash("invoiceINV-1001"),
+	} {
+		if other == id {
+			t.Fatalf("Expected a different key to give a different ID than %s", id)
+		}
+	}
+
+	// The derivation must never change, since the IDs are persisted.
+	if expected := "26fd19b7f198188b70e56c976b30baeb"; id.HexString() != expected {
+		t.Fatalf("Expected %s, got %s", expected, id.HexString())
+	}
+}
+
+func Test_EncodeAccount(t *testing.T) {
+	account := Account{
+		ID:          BytesToUint128([16]byte{0: 1, 15: 2}),
+		UserData128: ToUint128(3),
+		UserData64:  4,
+		UserData32:  5,
+		Ledger:      6,
+		Code:        7,
+		Flags:       AccountFlags{History: true}.ToUint16(),
+		Timestamp:   8,
+	}
+
+	// The encoding is the in-memory layout the cluster shares.
+	encoded := EncodeAccount(account)
+	if native := *(*[AccountSize]byte)(unsafe.Pointer(&account)); encoded != native {
+		t.Fatalf("Expected the layout of the account, got %x", encoded)
+	}
+	if encoded[0] != 1 || encoded[15] != 2 || encoded[112] != 6 || encoded[116] != 7 {
+		t.Fatalf("Expected the fields at their offsets, got %x", encoded)
+	}
+
+	decoded, err := DecodeAccount(encoded[:])
+	if err != nil || decoded != account {
+		t.Fatalf("Expected %+v, got %+v and %v", account, decoded, err)
+	}
+
+	batch, err := DecodeAccounts(EncodeAccounts([]Account{account, {ID: ToUint128(9)}}))
+	if err != nil || len(batch) != 2 || batch[0] != account || batch[1].ID != ToUint128(9) {
+		t.Fatalf("Expected the batch back, got %+v and %v", batch, err)
+	}
+	if _, err := DecodeAccounts(make([]byte, 130)); err == nil {
+		t.Fatal("Expected a partial account to fail")
+	}
+}
+
+func Test_EncodeTransfer(t *testing.T) {
+	transfer := Transfer{
+		ID:              ToUint128(1),
+		DebitAccountID:  ToUint128(2),
+		CreditAccountID: ToUint128(3),
+		Amount:          BytesToUint128([16]byte{8: 1}),
+		PendingID:       ToUint128(4),
+		Timeout:         5,
+		Ledger:          6,
+		Code:            7,
+		Flags:           TransferFlags{Pending: true}.ToUint16(),
+	}
+
+	encoded := EncodeTransfer(transfer)
+	if native := *(*[TransferSize]byte)(unsafe.Pointer(&transfer)); encoded != native {
+		t.Fatalf("Expected the layout of the transfer, got %x", encoded)
+	}
+
+	decoded, err := DecodeTransfer(encoded[:])
+	if err != nil || decoded != transfer {
+		t.Fatalf("Expected %+v, got %+v and %v", transfer, decoded, err)
+	}
+
+	batch, err := DecodeTransfers(EncodeTransfers([]Transfer{transfer}))
+	if err != nil || len(batch) != 1 || batch[0] != transfer {
+		t.Fatalf("Expected the batch back, got %+v and %v", batch, err)
+	}
+	if _, err := DecodeTransfer(encoded[:64]); err == nil {
+		t.Fatal("Expected a partial transfer to fail")
+	}
+}
+
+func Test_AccountTemplates(t *testing.T) {
+	asset := NewAssetAccount(1, 10)
+	if flags := asset.AccountFlags(); !flags.CreditsMustNotExceedDebits || flags.DebitsMustNotExceedCredits {
+		t.Fatalf("Expected only the credits_must_not_exceed_debits flag, got %d", asset.Flags)
+	}
+	liability := NewLiabilityAccount(1, 20)
+	if flags := liability.AccountFlags(); !flags.DebitsMustNotExceedCredits || flags.CreditsMustNotExceedDebits {
+		t.Fatalf("Expected only the debits_must_not_exceed_credits flag, got %d", liability.Flags)
+	}
+	control := NewControlAccount(1, 30)
+	if control.Flags != 0 {
+		t.Fatalf("Expected no flags, got %d", control.Flags)
+	}
+	if asset.ID == liability.ID || asset.ID == ToUint128(0) || control.Ledger != 1 || control.Code != 30 {
+		t.Fatalf("Expected distinct non-zero IDs on ledger 1, got %+v and %+v", asset, control)
+	}
+}
+
+func Test_ChartOfAccounts(t *testing.T) {
+	chart := NewChartOfAccounts()
+	if err := chart.RegisterAccount(10, "Cash", AccountTypeAsset); err != nil {
+		t.Fatal(err)
+	}
+	if err := chart.RegisterAccount(20, "Deposits", AccountTypeLiability); err != nil {
+		t.Fatal(err)
+	}
+	if err := chart.RegisterTransfer(1, "Deposit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := chart.RegisterAccount(10, "Cash", AccountTypeAsset); err == nil {
+		t.Fatalf("Expected a duplicate code to be rejected")
+	}
+	if err := chart.RegisterTransfer(0, "None"); err == nil {
+		t.Fatalf("Expected code 0 to be rejected")
+	}
+
+	if name, accountType, ok := chart.AccountName(20); !ok || name != "Deposits" ||
+		accountType != AccountTypeLiability {
+		t.Fatalf("Expected Deposits to be a liability, got %q %s", name, accountType)
+	}
+	if name, ok := chart.TransferName(1); !ok || name != "Deposit" {
+		t.Fatalf("Expected transfer code 1 to be Deposit, got %q", name)
+	}
+
+	cash, err := chart.NewAccount(7, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts := []Account{cash, NewLiabilityAccount(7, 20)}
+	if err := chart.ValidateAccounts(accounts); err != nil {
+		t.Fatal(err)
+	}
+
+	accounts = append(accounts, NewAssetAccount(7, 20))
+	var chartErr ErrChartOfAccounts
+	if err := chart.ValidateAccounts(accounts); !errors.As(err, &chartErr) ||
+		chartErr.Index != 2 || chartErr.Code != 20 {
+		t.Fatalf("Expected the asset flags on a liability code to be rejected, got %v", err)
+	}
+	if err := chart.ValidateAccounts([]Account{NewControlAccount(7, 30)}); err == nil {
+		t.Fatalf("Expected an unregistered code to be rejected")
+	}
+
+	transfers := []Transfer{
+		{Code: 1},
+		PostPendingTransfer(ToUint128(1), ToUint128(5)),
+	}
+	if err := chart.ValidateTransfers(transfers); err != nil {
+		t.Fatal(err)
+	}
+	transfers = append(transfers, Transfer{Code: 2})
+	if err := chart.ValidateTransfers(transfers); !errors.As(err, &chartErr) || chartErr.Index != 2 {
+		t.Fatalf("Expected transfer 2 to be rejected, got %v", err)
+	}
+}
+
+func Test_Decimal(t *testing.T) {
+	if decimal := ToUint128(1234).ToDecimal(2); decimal.Cmp(big.NewRat(1234, 100)) != 0 {
+		t.Fatalf("Expected 1234 with exponent 2 to be 12.34, got %s", decimal.RatString())
+	}
+	if decimal := AmountMax.ToDecimal(38); decimal.FloatString(38) != "3.40282366920938463463374607431768211455" {
+		t.Fatalf("Expected AmountMax to convert exactly, got %s", decimal.FloatString(38))
+	}
+
+	tests := []struct {
+		decimal string
+		mode    RoundingMode
+		amount  uint64
+	}{
+		{"12.34", RoundUnnecessary, 1234},
+		{"12.345", RoundDown, 1234},
+		{"12.341", RoundUp, 1235},
+		{"12.345", RoundHalfUp, 1235},
+		{"12.3449", RoundHalfUp, 1234},
+		{"12.345", RoundHalfEven, 1234},
+		{"12.355", RoundHalfEven, 1236},
+		{"12.3451", RoundHalfEven, 1235},
+		{"1/3", RoundHalfEven, 33},
+		{"0", RoundUnnecessary, 0},
+	}
+	for _, test := range tests {
+		decimal, _ := new(big.Rat).SetString(test.decimal)
+		amount, err := FromDecimal(decimal, 2, test.mode)
+		if err != nil || amount != ToUint128(test.amount) {
+			t.Fatalf("Expected %s with %s to be %d, got %s %v", test.decimal, test.mode, test.amount, amount, err)
+		}
+	}
+
+	for _, test := range []struct {
+		decimal string
+		mode    RoundingMode
+	}{
+		{"12.345", RoundUnnecessary},
+		{"-0.01", RoundDown},
+		{"3402823669209384634633746074317682114.56", RoundDown},
+		{"3402823669209384634633746074317682114.551", RoundUp},
+	} {
+		decimal, _ := new(big.Rat).SetString(test.decimal)
+		var conversionErr ErrDecimalConversion
+		if amount, err := FromDecimal(decimal, 2, test.mode); !errors.As(err, &conversionErr) {
+			t.Fatalf("Expected %s with %s to be rejected, got %s %v", test.decimal, test.mode, amount, err)
+		}
+	}
+
+	decimal, _ := new(big.Rat).SetString("3402823669209384634633746074317682114.55")
+	if amount, err := FromDecimal(decimal, 2, RoundUnnecessary); err != nil || amount != AmountMax {
+		t.Fatalf("Expected the largest decimal to be AmountMax, got %s %v", amount, err)
+	}
+}
+
+func Test_Format(t *testing.T) {
+	for _, test := range []struct {
+		got, expected string
+	}{
+		{AccountFlags{}.String(), "none"},
+		{TransferFlags{Pending: true, Linked: true}.String(), "linked|pending"},
+		{AccountFilterFlags{Debits: true, Reversed: true}.String(), "debits|reversed"},
+		{
+			Account{
+				ID:            ToUint128(255),
+				CreditsPosted: ToUint128(1000),
+				Ledger:        1,
+				Code:          10,
+				Flags:         AccountFlags{Linked: true, History: true}.ToUint16(),
+			}.String(),
+			"Account{id: 0xff, ledger: 1, code: 10, flags: linked|history, debits_pending: 0, " +
+				"debits_posted: 0, credits_pending: 0, credits_posted: 1000}",
+		},
+		{
+			Transfer{
+				ID:              ToUint128(16),
+				DebitAccountID:  ToUint128(1),
+				CreditAccountID: ToUint128(2),
+				Amount:          ToUint128(12345),
+				Ledger:          1,
+				Code:            1,
+				Flags:           TransferFlags{Pending: true}.ToUint16(),
+				Timeout:         60,
+				UserData64:      7,
+			}.String(),
+			"Transfer{id: 0x10, debit_account_id: 0x1, credit_account_id: 0x2, amount: 12345, " +
+				"ledger: 1, code: 1, flags: pending, timeout: 60, user_data_64: 7}",
+		},
+	} {
+		if test.got != test.expected {
+			t.Fatalf("Expected %s, got %s", test.expected, test.got)
+		}
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	flags, err := ParseAccountFlags("history | linked")
+	if err != nil || flags != (AccountFlags{Linked: true, History: true}) {
+		t.Fatalf("Expected linked|history, got %s %v", flags, err)
+	}
+	for bits := range uint16(1 << 6) {
+		flags := TransferFlagsFromUint16(bits)
+		parsed, err := ParseTransferFlags(flags.String())
+		if err != nil || parsed.ToUint16() != bits {
+			t.Fatalf("Expected %s to round-trip, got %s %v", flags, parsed, err)
+		}
+	}
+	if filter, _ := ParseAccountFilterFlags("none"); filter != (AccountFilterFlags{}) {
+		t.Fatalf("Expected no flags, got %s", filter)
+	}
+	if _, err := ParseAccountFlags("linked|pending"); err != (ErrInvalidName{Type: "AccountFlags", Name: "pending"}) {
+		t.Fatalf("Expected pending to be invalid, got %v", err)
+	}
+
+	for result := range AccountExists + 1 {
+		if parsed, err := ParseCreateAccountResult(result.String()); err != nil || parsed != result {
+			t.Fatalf("Expected %s to round-trip, got %d %v", result, parsed, err)
+		}
+	}
+	if result, err := ParseCreateTransferResult("TransferExceedsCredits"); err != nil || result != TransferExceedsCredits {
+		t.Fatalf("Expected TransferExceedsCredits, got %s %v", result, err)
+	}
+	if _, err := ParseCreateTransferResult("CreateTransferResult(100)"); err == nil {
+		t.Fatalf("Expected unknown results not to parse")
+	}
+}
+
+func Test_Cursor(t *testing.T) {
+	filter, _ := NewAccountFilter(ToUint128(1)).Limit(10).Build()
+	token := NewCursor(filter, 100).Encode()
+	cursor, err := DecodeCursor(token)
+	if err != nil || cursor != NewCursor(filter, 100) {
+		t.Fatalf("Expected the cursor to round-trip, got %+v %v", cursor, err)
+	}
+
+	// The limit may change between pages, but not what the filter matches.
+	next, err := cursor.Apply(AccountFilter{AccountID: filter.AccountID, Limit: 20, Flags: filter.Flags})
+	if err != nil || next.TimestampMin != 101 || next.Limit != 20 {
+		t.Fatalf("Expected the page after 100, got %+v %v", next, err)
+	}
+	other, _ := NewAccountFilter(ToUint128(2)).Build()
+	if _, err := cursor.Apply(other); err != (ErrInvalidCursor{Reason: "token was issued for another filter"}) {
+		t.Fatalf("Expected the cursor of another filter to be rejected, got %v", err)
+	}
+
+	reversed, _ := NewAccountFilter(ToUint128(1)).Reversed().Build()
+	next, err = NewCursor(reversed, 100).Apply(reversed)
+	if err != nil || next.TimestampMax != 99 || next.TimestampMin != 0 {
+		t.Fatalf("Expected the page before 100, got %+v %v", next, err)
+	}
+
+	for _, token := range []string{"", "not a token", token[:len(token)-1], "AgAAAAAAAAAAAAAAAAAAAAAA"} {
+		if _, err := DecodeCursor(token); err == nil {
+			t.Fatalf("Expected %q to be rejected", token)
+		}
+	}
+}
+
+func lowBits(value Uint128) uint64 {
+	bigint := value.BigInt()
+	return bigint.Uint64()
+}
+
+func Test_FeeSplit(t *testing.T) {
+	payer, payee := ToUint128(1), ToUint128(2)
+	feeA, feeB, feeC := ToUint128(10), ToUint128(11), ToUint128(12)
+	principal := Transfer{DebitAccountID: payer, CreditAccountID: payee, Ledger: 1, Code: 7, UserData64: 42}
+
+	amounts := func(transfers []Transfer) map[Uint128]uint64 {
+		split := make(map[Uint128]uint64)
+		for _, transfer := range transfers {
+			split[transfer.CreditAccountID] = lowBits(transfer.Amount)
+		}
+		return split
+	}
+
+	t.Run("links the legs", func(t *testing.T) {
+		principal := principal
+		principal.ID = ToUint128(99)
+		principal.Amount = ToUint128(1000)
+		transfers, err := NewFeeSplit(principal).
+			Fee(feeA, big.NewRat(25, 1000), ToUint128(30)).
+			Fee(feeB, nil, ToUint128(5)).
+			Build()
+		if err != nil || len(transfers) != 3 {
+			t.Fatalf("Expected 3 transfers, got %v %v", transfers, err)
+		}
+		if transfers[0].ID != principal.ID || transfers[0].Amount != ToUint128(940) {
+			t.Fatalf("Expected 940 to the payee with the ID of the principal, got %s", transfers[0])
+		}
+		for i, transfer := range transfers {
+			if transfer.TransferFlags().Linked != (i < 2) || transfer.DebitAccountID != payer ||
+				transfer.Ledger != 1 || transfer.Code != 7 || transfer.UserData64 != 42 {
+				t.Fatalf("Expected transfer %d to be a leg of the chain, got %s", i, transfer)
+			}
+		}
+		if split := amounts(transfers); split[feeA] != 55 || split[feeB] != 5 {
+			t.Fatalf("Expected fees of 55 and 5, got %v", split)
+		}
+	})
+
+	t.Run("rounds the fees", func(t *testing.T) {
+		// Three fees of a third of 1% of 250 are 0.8333 each, 2.5 in total.
+		third := big.NewRat(1, 300)
+		build := func(mode RoundingMode, policy RemainderPolicy) (map[Uint128]uint64, error) {
+			principal := principal
+			principal.Amount = ToUint128(250)
+			transfers, err := NewFeeSplit(principal).
+				Fee(feeA, third, Uint128{}).
+				Fee(feeB, third, Uint128{}).
+				Fee(feeC, third, Uint128{}).
+				Rounding(mode).
+				Remainder(policy).
+				Build()
+			return amounts(transfers), err
+		}
+		cases := []struct {
+			mode     RoundingMode
+			policy   RemainderPolicy
+			expected [4]uint64
+		}{
+			{RoundHalfEven, RemainderToPayee, [4]uint64{247, 1, 1, 1}},
+			{RoundDown, RemainderToPayee, [4]uint64{250, 0, 0, 0}},
+			{RoundUp, RemainderToPayee, [4]uint64{247, 1, 1, 1}},
+			{RoundHalfEven, RemainderToFirstFee, [4]uint64{248, 2, 0, 0}},
+			{RoundHalfUp, RemainderToFirstFee, [4]uint64{247, 3, 0, 0}},
+			{RoundDown, RemainderLargestFraction, [4]uint64{248, 1, 1, 0}},
+			{RoundUp, RemainderLargestFraction, [4]uint64{247, 1, 1, 1}},
+		}
+		for _, c := range cases {
+			split, err := build(c.mode, c.policy)
+			actual := [4]uint64{split[payee], split[feeA], split[feeB], split[feeC]}
+			if err != nil || actual != c.expected {
+				t.Fatalf("Expected %v with %s and %s, got %v %v", c.expected, c.mode, c.policy, actual, err)
+			}
+		}
+		if _, err := build(RoundUnnecessary, RemainderToPayee); err == nil {
+			t.Fatalf("Expected inexact fees to be rejected with RoundUnnecessary")
+		}
+	})
+
+	t.Run("conserves the principal", func(t *testing.T) {
+		rates := []*big.Rat{big.NewRat(1, 3), big.NewRat(7, 1000), big.NewRat(1, 7), big.NewRat(0, 1)}
+		modes := []RoundingMode{RoundDown, RoundUp, RoundHalfUp, RoundHalfEven}
+		policies := []RemainderPolicy{RemainderToPayee, RemainderToFirstFee, RemainderLargestFraction}
+		for amount := uint64(1); amount <= 300; amount++ {
+			for _, mode := range modes {
+				for _, policy := range policies {
+					principal := principal
+					principal.Amount = ToUint128(amount)
+					builder := NewFeeSplit(principal).Rounding(mode).Remainder(policy)
+					for i, rate := range rates {
+						builder.Fee(ToUint128(uint64(10+i)), rate, Uint128{})
+					}
+					transfers, err := builder.Build()
+					var invalid ErrInvalidFeeSplit
+					if errors.As(err, &invalid) && mode == RoundUp {
+						// Rounding every fee up may take more than a small principal.
+						continue
+					}
+					if err != nil {
+						t.Fatalf("Expected %d to split with %s and %s, got %v", amount, mode, policy, err)
+					}
+					var total uint64
+					for _, transfer := range transfers {
+						if transfer.Amount == (Uint128{}) {
+							t.Fatalf("Expected no transfer of zero, got %s", transfer)
+						}
+						total += lowBits(transfer.Amount)
+					}
+					if total != amount {
+						t.Fatalf("Expected the legs of %d to add up to it with %s and %s, got %d", amount, mode, policy, total)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("rejects invalid splits", func(t *testing.T) {
+		principal := principal
+		principal.Amount = ToUint128(100)
+		if _, err := NewFeeSplit(principal).Fee(feeA, big.NewRat(1, 2), ToUint128(51)).Build(); err == nil {
+			t.Fatalf("Expected fees above the principal to be rejected")
+		}
+		if _, err := NewFeeSplit(principal).Fee(feeA, big.NewRat(-1, 2), Uint128{}).Build(); err == nil {
+			t.Fatalf("Expected a negative rate to be rejected")
+		}
+		transfers, err := NewFeeSplit(principal).Fee(feeA, big.NewRat(1, 1), Uint128{}).Build()
+		if err != nil || len(transfers) != 1 || transfers[0].CreditAccountID != feeA ||
+			transfers[0].TransferFlags().Linked {
+			t.Fatalf("Expected the whole principal as a fee, got %v %v", transfers, err)
+		}
+	})
+}
+
+func Test_Exchange(t *testing.T) {
+	exchange := Exchange{
+		SourceAccountID:        ToUint128(1),
+		SourceLiquidityID:      ToUint128(2),
+		SourceLedger:           840,
+		DestinationLiquidityID: ToUint128(3),
+		DestinationAccountID:   ToUint128(4),
+		DestinationLedger:      392,
+		Code:                   1,
+		UserData128:            ToUint128(77),
+		Amount:                 ToUint128(1001),
+		Rate:                   big.NewRat(15, 10),
+		Rounding:               RoundHalfEven,
+	}
+	transfers, err := exchange.Build()
+	if err != nil || len(transfers) != 2 {
+		t.Fatalf("Expected 2 transfers, got %v %v", transfers, err)
+	}
+	source, destination := transfers[0], transfers[1]
+	if !source.TransferFlags().Linked || destination.TransferFlags().Linked {
+		t.Fatalf("Expected the legs to be linked, got %s %s", source, destination)
+	}
+	if source.DebitAccountID != ToUint128(1) || source.CreditAccountID != ToUint128(2) ||
+		source.Ledger != 840 || source.Amount != ToUint128(1001) {
+		t.Fatalf("Expected the source leg on ledger 840, got %s", source)
+	}
+	// 1001 * 1.5 = 1501.5, rounded to the even 1502.
+	if destination.DebitAccountID != ToUint128(3) || destination.CreditAccountID != ToUint128(4) ||
+		destination.Ledger != 392 || destination.Amount != ToUint128(1502) || destination.UserData128 != ToUint128(77) {
+		t.Fatalf("Expected 1502 on ledger 392, got %s", destination)
+	}
+
+	exchange.Rounding = RoundDown
+	if transfers, _ := exchange.Build(); transfers[1].Amount != ToUint128(1501) {
+		t.Fatalf("Expected 1501 rounded down, got %s", transfers[1])
+	}
+	exchange.Rounding = RoundUnnecessary
+	if _, err := exchange.Build(); err == nil {
+		t.Fatalf("Expected an inexact exchange to be rejected with RoundUnnecessary")
+	}
+
+	exchange.Rounding = RoundDown
+	exchange.Amount = ToUint128(1)
+	exchange.Rate = big.NewRat(1, 100)
+	if _, err := exchange.Build(); err != (ErrInvalidExchange{Reason: "amount 1 exchanges to zero"}) {
+		t.Fatalf("Expected an exchange to zero to be rejected, got %v", err)
+	}
+	exchange.Rate = nil
+	if _, err := exchange.Build(); err == nil {
+		t.Fatalf("Expected an exchange without a rate to be rejected")
+	}
+}
+
+func Test_Redaction(t *testing.T) {
+	transfer := Transfer{ID: ToUint128(1), UserData128: ToUint128(42), UserData64: 7, Ledger: 1}
+
+	if redacted := (Redaction{}).Transfer(transfer); redacted != transfer {
+		t.Fatalf("Expected %s to be left as it is, got %s", transfer, redacted)
+	}
+	if redacted := (Redaction{UserData: RedactOmit}).Transfer(transfer); redacted != (Transfer{ID: ToUint128(1), Ledger: 1}) {
+		t.Fatalf("Expected the user data of %s to be omitted", redacted)
+	}
+
+	hash := Redaction{UserData: RedactHash, Key: []byte("secret")}
+	redacted := hash.Transfer(transfer)
+	if redacted.UserData128 == transfer.UserData128 || redacted.UserData64 == transfer.UserData64 {
+		t.Fatalf("Expected the user data of %s to be hashed", redacted)
+	}
+	if redacted.UserData32 != 0 || redacted.ID != transfer.ID {
+		t.Fatalf("Expected only the user data of %s that isn't zero to be hashed", redacted)
+	}
+	account := hash.Account(Account{UserData128: ToUint128(42), UserData64: 7})
+	if account.UserData128 != redacted.UserData128 || account.UserData64 != redacted.UserData64 {
+		t.Fatalf("Expected equal user data to hash alike, got %s and %s", account, redacted)
+	}
+	other := Redaction{UserData: RedactHash, Key: []byte("other")}.Transfer(transfer)
+	if other.UserData128 == redacted.UserData128 {
+		t.Fatalf("Expected the hash to depend on the key")
+	}
+
+	SetLogRedaction(Redaction{UserData: RedactOmit})
+	formatted := transfer.String()
+	logged := transfer.LogValue().String()
+	SetLogRedaction(Redaction{})
+	if strings.Contains(formatted, "user_data") || strings.Contains(logged, "user_data") {
+		t.Fatalf("Expected the user data to be left out of %s and %s", formatted, logged)
+	}
+	if !strings.Contains(transfer.String(), "user_data_128: 0x2a") {
+		t.Fatalf("Expected the user data in %s", transfer)
+	}
+}
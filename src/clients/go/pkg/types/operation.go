@@ -0,0 +1,34 @@
+package types
+
+import "strconv"
+
+// Operation identifies a request that the cluster executes.
+type Operation uint8
+
+// The operations, as in TB_OPERATION of tb_client.h.
+const (
+	OperationCreateAccounts      Operation = 129
+	OperationCreateTransfers     Operation = 130
+	OperationLookupAccounts      Operation = 131
+	OperationLookupTransfers     Operation = 132
+	OperationGetAccountTransfers Operation = 133
+	OperationGetAccountHistory   Operation = 134
+)
+
+func (op Operation) String() string {
+	switch op {
+	case OperationCreateAccounts:
+		return "CreateAccounts"
+	case OperationCreateTransfers:
+		return "CreateTransfers"
+	case OperationLookupAccounts:
+		return "LookupAccounts"
+	case OperationLookupTransfers:
+		return "LookupTransfers"
+	case OperationGetAccountTransfers:
+		return "GetAccountTransfers"
+	case OperationGetAccountHistory:
+		return "GetAccountHistory"
+	}
+	return "Operation(" + strconv.FormatInt(int64(op), 10) + ")"
+}
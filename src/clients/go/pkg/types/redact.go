@@ -0,0 +1,169 @@
+package types
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+)
+
+// RedactMode decides how a Redaction renders the user data fields.
+type RedactMode uint8
+
+const (
+	// RedactNone leaves the user data as it is.
+	RedactNone RedactMode = iota
+	// RedactHash replaces every user data field that isn't zero with a keyed hash of it, of the
+	// same width, so that equal values still correlate within the output without revealing the
+	// references they hold.
+	RedactHash
+	// RedactOmit zeroes the user data, which is then left out wherever zero fields are.
+	RedactOmit
+)
+
+func (mode RedactMode) String() string {
+	switch mode {
+	case RedactNone:
+		return "RedactNone"
+	case RedactHash:
+		return "RedactHash"
+	case RedactOmit:
+		return "RedactOmit"
+	}
+	return fmt.Sprintf("RedactMode(%d)", uint8(mode))
+}
+
+// Redaction is a policy for the user data of accounts and transfers in logs and other output,
+// where it may hold references that correlate with personal data.
+type Redaction struct {
+	UserData RedactMode
+	// Key keys the hash of RedactHash. Without a secret key, user data of few bits, such as
+	// user_data_32, is recovered by hashing every possible value, so keep it secret, and the
+	// same wherever hashes should correlate.
+	Key []byte
+}
+
+// Account returns account with its user data redacted.
+func (r Redaction) Account(account Account) Account {
+	account.UserData128, account.UserData64, account.UserData32 =
+		r.userData(account.UserData128, account.UserData64, account.UserData32)
+	return account
+}
+
+// Transfer returns transfer with its user data redacted.
+func (r Redaction) Transfer(transfer Transfer) Transfer {
+	transfer.UserData128, transfer.UserData64, transfer.UserData32 =
+		r.userData(transfer.UserData128, transfer.UserData64, transfer.UserData32)
+	return transfer
+}
+
+func (r Redaction) userData(data128 Uint128, data64 uint64, data32 uint32) (Uint128, uint64, uint32) {
+	switch r.UserData {
+	case RedactHash:
+		if data128 != (Uint128{}) {
+			bytes := data128.Bytes()
+			var hashed [16]byte
+			copy(hashed[:], r.hash("user_data_128", bytes[:]))
+			data128 = BytesToUint128(hashed)
+		}
+		if data64 != 0 {
+			data64 = binary.LittleEndian.Uint64(r.hash("user_data_64", binary.LittleEndian.AppendUint64(nil, data64)))
+		}
+		if data32 != 0 {
+			data32 = binary.LittleEndian.Uint32(r.hash("user_data_32", binary.LittleEndian.AppendUint32(nil, data32)))
+		}
+	case RedactOmit:
+		return Uint128{}, 0, 0
+	}
+	return data128, data64, data32
+}
+
+// hash returns the HMAC-SHA256 of value, under the name of its field so that equal values of
+// different fields don't correlate.
+func (r Redaction) hash(field string, value []byte) []byte {
+	mac := hmac.New(sha256.New, r.Key)
+	mac.Write([]byte(field))
+	mac.Write(value)
+	return mac.Sum(nil)
+}
+
+var logRedaction atomic.Pointer[Redaction]
+
+// SetLogRedaction sets the redaction of the user data of accounts and transfers rendered for
+// logs, by their String and LogValue methods, for the whole process. It is RedactNone unless
+// set.
+func SetLogRedaction(redaction Redaction) {
+	logRedaction.Store(&redaction)
+}
+
+// LogRedaction returns the redaction set with SetLogRedaction.
+func LogRedaction() Redaction {
+	if redaction := logRedaction.Load(); redaction != nil {
+		return *redaction
+	}
+	return Redaction{}
+}
+
+// LogValue renders the account for log/slog as a group of its fields, with its user data
+// redacted as set with SetLogRedaction, and left out while zero.
+func (o Account) LogValue() slog.Value {
+	o = LogRedaction().Account(o)
+	attrs := []slog.Attr{
+		slog.String("id", "0x"+o.ID.HexString()),
+		slog.Uint64("ledger", uint64(o.Ledger)),
+		slog.Uint64("code", uint64(o.Code)),
+		slog.String("flags", o.AccountFlags().String()),
+		slog.String("debits_pending", o.DebitsPending.String()),
+		slog.String("debits_posted", o.DebitsPosted.String()),
+		slog.String("credits_pending", o.CreditsPending.String()),
+		slog.String("credits_posted", o.CreditsPosted.String()),
+	}
+	attrs = appendUserData(attrs, o.UserData128, o.UserData64, o.UserData32)
+	if o.Timestamp != 0 {
+		attrs = append(attrs, slog.Uint64("timestamp", o.Timestamp))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue renders the transfer for log/slog as a group of its fields, with its user data
+// redacted as set with SetLogRedaction, and left out while zero, as are the pending ID, the
+// timeout and the timestamp.
+func (o Transfer) LogValue() slog.Value {
+	o = LogRedaction().Transfer(o)
+	attrs := []slog.Attr{
+		slog.String("id", "0x"+o.ID.HexString()),
+		slog.String("debit_account_id", "0x"+o.DebitAccountID.HexString()),
+		slog.String("credit_account_id", "0x"+o.CreditAccountID.HexString()),
+		slog.String("amount", o.Amount.String()),
+		slog.Uint64("ledger", uint64(o.Ledger)),
+		slog.Uint64("code", uint64(o.Code)),
+		slog.String("flags", o.TransferFlags().String()),
+	}
+	if o.PendingID != (Uint128{}) {
+		attrs = append(attrs, slog.String("pending_id", "0x"+o.PendingID.HexString()))
+	}
+	if o.Timeout != 0 {
+		attrs = append(attrs, slog.Uint64("timeout", uint64(o.Timeout)))
+	}
+	attrs = appendUserData(attrs, o.UserData128, o.UserData64, o.UserData32)
+	if o.Timestamp != 0 {
+		attrs = append(attrs, slog.Uint64("timestamp", o.Timestamp))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func appendUserData(attrs []slog.Attr, data128 Uint128, data64 uint64, data32 uint32) []slog.Attr {
+	if data128 != (Uint128{}) {
+		attrs = append(attrs, slog.String("user_data_128", "0x"+data128.HexString()))
+	}
+	if data64 != 0 {
+		attrs = append(attrs, slog.String("user_data_64", strconv.FormatUint(data64, 10)))
+	}
+	if data32 != 0 {
+		attrs = append(attrs, slog.Uint64("user_data_32", uint64(data32)))
+	}
+	return attrs
+}
@@ -0,0 +1,43 @@
+package types
+
+// EventResult is the result of an event of a create batch that was not created.
+type EventResult interface {
+	AccountEventResult | TransferEventResult
+	eventIndex() uint32
+}
+
+func (r AccountEventResult) eventIndex() uint32 { return r.Index }
+
+func (r TransferEventResult) eventIndex() uint32 { return r.Index }
+
+// FailedEvent is an input whose event was not created, with the result the cluster replied with.
+type FailedEvent[T any, R EventResult] struct {
+	Input  T
+	Result R
+}
+
+// PartitionResults splits the inputs from which the events of a create batch were built, one per
+// event and in the same order, into those whose event was created and those whose event failed,
+// both in the order of inputs. It panics if a result is for an event beyond the inputs.
+func PartitionResults[T any, R EventResult](inputs []T, results []R) (succeeded []T, failed []FailedEvent[T, R]) {
+	// resultOf holds, for the input at each index, the position of its result plus one.
+	resultOf := make([]int, len(inputs))
+	for position, result := range results {
+		index := result.eventIndex()
+		if int(index) >= len(inputs) {
+			panic("types: result index out of range of the inputs")
+		}
+		resultOf[index] = position + 1
+	}
+
+	succeeded = make([]T, 0, max(len(inputs)-len(results), 0))
+	failed = make([]FailedEvent[T, R], 0, len(results))
+	for i, input := range inputs {
+		if resultOf[i] == 0 {
+			succeeded = append(succeeded, input)
+		} else {
+			failed = append(failed, FailedEvent[T, R]{Input: input, Result: results[resultOf[i]-1]})
+		}
+	}
+	return succeeded, failed
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Value implements driver.Valuer, storing the value as a decimal string, which databases
+// convert to NUMERIC(39, 0) and text columns alike.
+func (value Uint128) Value() (driver.Value, error) {
+	return value.String(), nil
+}
+
+// Scan implements sql.Scanner, reading a value stored as a decimal integer, from a NUMERIC,
+// text or integer column, or as the 16 big-endian bytes written by Uint128Bytes.
+// A NULL column is an error; scan into a sql.Null[Uint128] instead to allow it.
+func (value *Uint128) Scan(src any) error {
+	switch src := src.(type) {
+	case int64:
+		if src < 0 {
+			return fmt.Errorf("Uint128 cannot scan negative integer %d.", src)
+		}
+		*value = ToUint128(uint64(src))
+		return nil
+	case string:
+		parsed, err := DecStringToUint128(src)
+		if err != nil {
+			return err
+		}
+		*value = parsed
+		return nil
+	case []byte:
+		if len(src) == 16 && !isDecimal(src) {
+			return (*Uint128Bytes)(value).Scan(src)
+		}
+		parsed, err := DecStringToUint128(string(src))
+		if err != nil {
+			return err
+		}
+		*value = parsed
+		return nil
+	case nil:
+		return fmt.Errorf("Uint128 cannot scan NULL.")
+	default:
+		return fmt.Errorf("Uint128 cannot scan %T.", src)
+	}
+}
+
+func isDecimal(bytes []byte) bool {
+	for _, digit := range bytes {
+		if digit < '0' || digit > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Uint128Bytes stores a Uint128 as 16 big-endian bytes, for BYTEA and BLOB columns, where
+// big-endian keeps the byte order of the column the same as the numeric order:
+//
+//	db.Exec("INSERT INTO transfers (id) VALUES ($1)", types.Uint128Bytes(transfer.ID))
+//	db.QueryRow("SELECT id FROM transfers").Scan((*types.Uint128Bytes)(&id))
+type Uint128Bytes Uint128
+
+// Value implements driver.Valuer.
+func (value Uint128Bytes) Value() (driver.Value, error) {
+	bytes := Uint128(value).Bytes()
+	swapEndian(bytes[:])
+	return bytes[:], nil
+}
+
+// Scan implements sql.Scanner.
+func (value *Uint128Bytes) Scan(src any) error {
+	raw, ok := src.([]byte)
+	if !ok || len(raw) != 16 {
+		return fmt.Errorf("Uint128Bytes can only scan 16 bytes, not %T.", src)
+	}
+
+	var bytes [16]byte
+	copy(bytes[:], raw)
+	swapEndian(bytes[:])
+	*value = Uint128Bytes(BytesToUint128(bytes))
+	return nil
+}
@@ -0,0 +1,147 @@
+package types
+
+import (
+	"strconv"
+	"time"
+)
+
+// PostPendingTransfer returns a transfer that posts the pending transfer pendingID.
+// The transfer is assigned a fresh ID(); an amount of zero posts the full pending amount.
+// The accounts, ledger and code are left zero so that they are inherited from the pending transfer.
+func PostPendingTransfer(pendingID Uint128, amount Uint128) Transfer {
+	return Transfer{
+		ID:        ID(),
+		PendingID: pendingID,
+		Amount:    amount,
+		Flags:     TransferFlags{PostPendingTransfer: true}.ToUint16(),
+	}
+}
+
+// VoidPendingTransfer returns a transfer that voids the pending transfer pendingID.
+// The transfer is assigned a fresh ID(); the amount is left zero to void the full pending amount.
+func VoidPendingTransfer(pendingID Uint128) Transfer {
+	return Transfer{
+		ID:        ID(),
+		PendingID: pendingID,
+		Flags:     TransferFlags{VoidPendingTransfer: true}.ToUint16(),
+	}
+}
+
+// AmountMax is the largest amount. As the amount of a balancing transfer, it transfers as much as
+// the balanced accounts allow.
+var AmountMax = uint128IntMax
+
+// Balancing selects which accounts of a balancing transfer limit its amount.
+type Balancing uint8
+
+const (
+	// BalancingDebit limits the amount to what keeps the debits of the debit account within its
+	// credits.
+	BalancingDebit Balancing = 1 << iota
+	// BalancingCredit limits the amount to what keeps the credits of the credit account within its
+	// debits.
+	BalancingCredit
+)
+
+// NewBalancingTransfer returns a transfer of up to limit from debitAccountID to creditAccountID,
+// which the cluster reduces to what the balanced accounts allow instead of rejecting it, as when
+// sweeping a balance. It fails with TransferExceedsCredits or TransferExceedsDebits only if
+// nothing can be transferred. The transfer is assigned a fresh ID(); look it up once created to
+// learn the amount transferred.
+//
+// Pass AmountMax to transfer as much as possible: a zero amount is taken by the cluster as the
+// largest 64-bit amount instead.
+func NewBalancingTransfer(
+	balancing Balancing,
+	debitAccountID Uint128,
+	creditAccountID Uint128,
+	limit Uint128,
+	ledger uint32,
+	code uint16,
+) Transfer {
+	return Transfer{
+		ID:              ID(),
+		DebitAccountID:  debitAccountID,
+		CreditAccountID: creditAccountID,
+		Amount:          limit,
+		Ledger:          ledger,
+		Code:            code,
+		Flags: TransferFlags{
+			BalancingDebit:  balancing&BalancingDebit != 0,
+			BalancingCredit: balancing&BalancingCredit != 0,
+		}.ToUint16(),
+	}
+}
+
+// ExpiresAt returns the cluster timestamp at which a pending transfer with a timeout expires,
+// or zero if the transfer never expires.
+func (o Transfer) ExpiresAt() uint64 {
+	if !o.TransferFlags().Pending || o.Timeout == 0 {
+		return 0
+	}
+	return o.Timestamp + uint64(o.Timeout)*uint64(time.Second)
+}
+
+// expiryClockGrace is how far the local clock must be past a pending transfer's timeout before
+// it is taken as evidence of expiry, to tolerate clock skew with the cluster.
+const expiryClockGrace = time.Second
+
+// TransferStatus is where a transfer is in its lifecycle.
+type TransferStatus uint8
+
+const (
+	// TransferStatusPosted is a transfer that is not pending, including posts and voids.
+	TransferStatusPosted TransferStatus = iota + 1
+	TransferStatusPendingOpen
+	TransferStatusPendingPosted
+	TransferStatusPendingVoided
+	TransferStatusPendingExpired
+)
+
+func (s TransferStatus) String() string {
+	switch s {
+	case TransferStatusPosted:
+		return "Posted"
+	case TransferStatusPendingOpen:
+		return "PendingOpen"
+	case TransferStatusPendingPosted:
+		return "PendingPosted"
+	case TransferStatusPendingVoided:
+		return "PendingVoided"
+	case TransferStatusPendingExpired:
+		return "PendingExpired"
+	}
+	return "TransferStatus(" + strconv.FormatInt(int64(s), 10) + ")"
+}
+
+// TransferState returns the status of transfer given the transfers related to it, such as the
+// transfers of its accounts created after it.
+//
+// A pending transfer is posted or voided by the related transfer that resolved it. Expiry is not
+// recorded as a transfer, so an unresolved pending transfer is expired once either a related
+// transfer or the local clock shows that its timeout has passed.
+func TransferState(transfer Transfer, related []Transfer) TransferStatus {
+	if !transfer.TransferFlags().Pending {
+		return TransferStatusPosted
+	}
+
+	expiresAt := transfer.ExpiresAt()
+	now := uint64(time.Now().Add(-expiryClockGrace).UnixNano())
+	for _, other := range related {
+		if other.PendingID == transfer.ID {
+			flags := other.TransferFlags()
+			if flags.PostPendingTransfer {
+				return TransferStatusPendingPosted
+			}
+			if flags.VoidPendingTransfer {
+				return TransferStatusPendingVoided
+			}
+		}
+		now = max(now, other.Timestamp)
+	}
+
+	if expiresAt != 0 && now >= expiresAt {
+		return TransferStatusPendingExpired
+	}
+	return TransferStatusPendingOpen
+}
@@ -0,0 +1,189 @@
+package types
+
+const (
+	accountFlagsMask  = 1<<4 - 1
+	transferFlagsMask = 1<<6 - 1
+)
+
+var uint128IntMax = BytesToUint128([16]byte{
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+})
+
+// Validate checks the invariants of a new account that the cluster checks before looking at
+// any state, in the same order, and returns the result the cluster would reply with, or
+// AccountOK if the account may still be created.
+func (o Account) Validate() CreateAccountResult {
+	if o.Timestamp != 0 {
+		return AccountTimestampMustBeZero
+	}
+	if o.Reserved != 0 {
+		return AccountReservedField
+	}
+	if o.Flags&^accountFlagsMask != 0 {
+		return AccountReservedFlag
+	}
+
+	if o.ID == (Uint128{}) {
+		return AccountIDMustNotBeZero
+	}
+	if o.ID == uint128IntMax {
+		return AccountIDMustNotBeIntMax
+	}
+
+	flags := o.AccountFlags()
+	if flags.DebitsMustNotExceedCredits && flags.CreditsMustNotExceedDebits {
+		return AccountFlagsAreMutuallyExclusive
+	}
+
+	if o.DebitsPending != (Uint128{}) {
+		return AccountDebitsPendingMustBeZero
+	}
+	if o.DebitsPosted != (Uint128{}) {
+		return AccountDebitsPostedMustBeZero
+	}
+	if o.CreditsPending != (Uint128{}) {
+		return AccountCreditsPendingMustBeZero
+	}
+	if o.CreditsPosted != (Uint128{}) {
+		return AccountCreditsPostedMustBeZero
+	}
+	if o.Ledger == 0 {
+		return AccountLedgerMustNotBeZero
+	}
+	if o.Code == 0 {
+		return AccountCodeMustNotBeZero
+	}
+	return AccountOK
+}
+
+// Validate checks the invariants of a new transfer that the cluster checks before looking at
+// any state, in the same order, and returns the result the cluster would reply with, or
+// TransferOK if the transfer may still be created.
+//
+// Posting or voiding transfers inherit their accounts, ledger and code from the pending
+// transfer, so only their pending ID and flags are checked.
+func (o Transfer) Validate() CreateTransferResult {
+	if o.Timestamp != 0 {
+		return TransferTimestampMustBeZero
+	}
+	if o.Flags&^transferFlagsMask != 0 {
+		return TransferReservedFlag
+	}
+
+	if o.ID == (Uint128{}) {
+		return TransferIDMustNotBeZero
+	}
+	if o.ID == uint128IntMax {
+		return TransferIDMustNotBeIntMax
+	}
+
+	flags := o.TransferFlags()
+	if flags.PostPendingTransfer || flags.VoidPendingTransfer {
+		if flags.PostPendingTransfer && flags.VoidPendingTransfer {
+			return TransferFlagsAreMutuallyExclusive
+		}
+		if flags.Pending || flags.BalancingDebit || flags.BalancingCredit {
+			return TransferFlagsAreMutuallyExclusive
+		}
+
+		if o.PendingID == (Uint128{}) {
+			return TransferPendingIDMustNotBeZero
+		}
+		if o.PendingID == uint128IntMax {
+			return TransferPendingIDMustNotBeIntMax
+		}
+		if o.PendingID == o.ID {
+			return TransferPendingIDMustBeDifferent
+		}
+		if o.Timeout != 0 {
+			return TransferTimeoutReservedForPendingTransfer
+		}
+		return TransferOK
+	}
+
+	if o.DebitAccountID == (Uint128{}) {
+		return TransferDebitAccountIDMustNotBeZero
+	}
+	if o.DebitAccountID == uint128IntMax {
+		return TransferDebitAccountIDMustNotBeIntMax
+	}
+	if o.CreditAccountID == (Uint128{}) {
+		return TransferCreditAccountIDMustNotBeZero
+	}
+	if o.CreditAccountID == uint128IntMax {
+		return TransferCreditAccountIDMustNotBeIntMax
+	}
+	if o.CreditAccountID == o.DebitAccountID {
+		return TransferAccountsMustBeDifferent
+	}
+
+	if o.PendingID != (Uint128{}) {
+		return TransferPendingIDMustBeZero
+	}
+	if !flags.Pending && o.Timeout != 0 {
+		return TransferTimeoutReservedForPendingTransfer
+	}
+	if !flags.BalancingDebit && !flags.BalancingCredit && o.Amount == (Uint128{}) {
+		return TransferAmountMustNotBeZero
+	}
+
+	if o.Ledger == 0 {
+		return TransferLedgerMustNotBeZero
+	}
+	if o.Code == 0 {
+		return TransferCodeMustNotBeZero
+	}
+	return TransferOK
+}
+
+// Exists compares a new account with the existing account of the same ID, in the same order as
+// the cluster, and returns the result the cluster would reply with.
+func (o Account) Exists(existing Account) CreateAccountResult {
+	switch {
+	case existing.Flags != o.Flags:
+		return AccountExistsWithDifferentFlags
+	case existing.UserData128 != o.UserData128:
+		return AccountExistsWithDifferentUserData128
+	case existing.UserData64 != o.UserData64:
+		return AccountExistsWithDifferentUserData64
+	case existing.UserData32 != o.UserData32:
+		return AccountExistsWithDifferentUserData32
+	case existing.Ledger != o.Ledger:
+		return AccountExistsWithDifferentLedger
+	case existing.Code != o.Code:
+		return AccountExistsWithDifferentCode
+	}
+	return AccountExists
+}
+
+// Exists compares a new transfer with the existing transfer of the same ID, in the same order
+// as the cluster, and returns the result the cluster would reply with. The fields that the new
+// transfer leaves to be inherited or computed are not compared.
+func (o Transfer) Exists(existing Transfer) CreateTransferResult {
+	flags := o.TransferFlags()
+	switch {
+	case existing.Flags != o.Flags:
+		return TransferExistsWithDifferentFlags
+	case o.DebitAccountID != (Uint128{}) && existing.DebitAccountID != o.DebitAccountID:
+		return TransferExistsWithDifferentDebitAccountID
+	case o.CreditAccountID != (Uint128{}) && existing.CreditAccountID != o.CreditAccountID:
+		return TransferExistsWithDifferentCreditAccountID
+	case !flags.BalancingDebit && !flags.BalancingCredit && o.Amount != (Uint128{}) &&
+		existing.Amount != o.Amount:
+		return TransferExistsWithDifferentAmount
+	case existing.PendingID != o.PendingID:
+		return TransferExistsWithDifferentPendingID
+	case existing.UserData128 != o.UserData128:
+		return TransferExistsWithDifferentUserData128
+	case existing.UserData64 != o.UserData64:
+		return TransferExistsWithDifferentUserData64
+	case existing.UserData32 != o.UserData32:
+		return TransferExistsWithDifferentUserData32
+	case existing.Timeout != o.Timeout:
+		return TransferExistsWithDifferentTimeout
+	case o.Code != 0 && existing.Code != o.Code:
+		return TransferExistsWithDifferentCode
+	}
+	return TransferExists
+}
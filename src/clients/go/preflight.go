@@ -0,0 +1,86 @@
+package tigerbeetle_go
+
+import (
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// WithPreflight makes the client validate accounts and transfers locally before submitting
+// them, failing the whole batch with ErrInvalidAccount or ErrInvalidTransfer for the first
+// event that the cluster would reject regardless of its state.
+//
+// Without preflight, the same events are rejected one by one in the results, after a round
+// trip, while the valid events of the batch are created.
+func WithPreflight() ClientOption {
+	return func(options *clientOptions) {
+		options.preflight = true
+	}
+}
+
+// WithDuplicateDetection makes the client check that the events of a batch have distinct IDs
+// before submitting it, failing the whole batch with ErrDuplicateID for the first event that
+// repeats the ID of an earlier one.
+//
+// Without it, the cluster creates the first event and answers the others with AccountExists or
+// TransferExists, or with one of the exists with different results if they differ, which are
+// hard to tell from a retry of an earlier batch.
+func WithDuplicateDetection() ClientOption {
+	return func(options *clientOptions) {
+		options.duplicates = true
+	}
+}
+
+// checkAccounts runs the checks that the options enable on a batch of accounts.
+func (c *c_client) checkAccounts(accounts []types.Account) error {
+	if c.preflight {
+		if err := preflightAccounts(accounts); err != nil {
+			return err
+		}
+	}
+	if c.duplicates {
+		return detectDuplicates(accounts, func(account types.Account) types.Uint128 { return account.ID })
+	}
+	return nil
+}
+
+// checkTransfers runs the checks that the options enable on a batch of transfers.
+func (c *c_client) checkTransfers(transfers []types.Transfer) error {
+	if c.preflight {
+		if err := preflightTransfers(transfers); err != nil {
+			return err
+		}
+	}
+	if c.duplicates {
+		return detectDuplicates(transfers, func(transfer types.Transfer) types.Uint128 { return transfer.ID })
+	}
+	return nil
+}
+
+func preflightAccounts(accounts []types.Account) error {
+	for i, account := range accounts {
+		if result := account.Validate(); result != types.AccountOK {
+			return errors.ErrInvalidAccount{Index: i, Result: result}
+		}
+	}
+	return nil
+}
+
+func preflightTransfers(transfers []types.Transfer) error {
+	for i, transfer := range transfers {
+		if result := transfer.Validate(); result != types.TransferOK {
+			return errors.ErrInvalidTransfer{Index: i, Result: result}
+		}
+	}
+	return nil
+}
+
+func detectDuplicates[E any](events []E, id func(event E) types.Uint128) error {
+	seen := make(map[types.Uint128]int, len(events))
+	for i, event := range events {
+		if first, ok := seen[id(event)]; ok {
+			return errors.ErrDuplicateID{ID: id(event), FirstIndex: first, Index: i}
+		}
+		seen[id(event)] = i
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package tigerbeetle_go
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+)
+
+// WithRateLimit throttles the client to eventsPerSecond events, so that a single runaway
+// service can't swamp a cluster it shares with others. The limit is a token bucket over the
+// events of the requests rather than over the requests: a batch of 8000 transfers weighs 8000
+// times a single lookup. Up to burst events go through at once after the client was idle, and
+// a batch larger than burst waits for as long as its events take at the rate.
+//
+// Requests wait for the limit once admitted, before taking a request slot, and count as queued
+// meanwhile. TryCreateAccounts and TryCreateTransfers fail with ErrRateLimited instead of
+// waiting. Stats reports how many requests waited, and for how long.
+//
+// An eventsPerSecond of zero or less leaves the client unlimited.
+func WithRateLimit(eventsPerSecond float64, burst int) ClientOption {
+	return func(options *clientOptions) {
+		options.rateLimit = nil
+		if eventsPerSecond > 0 {
+			options.rateLimit = newRateLimiter(eventsPerSecond, burst, time.Now())
+		}
+	}
+}
+
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mutex   sync.Mutex
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter returns a full bucket, for the first requests not to wait. The rate must be
+// positive.
+func newRateLimiter(rate float64, burst int, now time.Time) *rateLimiter {
+	if burst <= 0 {
+		burst = int(math.Max(1, rate))
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), updated: now}
+}
+
+// reserve takes count tokens and returns how long to wait before they are available. Unless
+// wait is set, it takes nothing and returns false if the tokens aren't available right away.
+func (l *rateLimiter) reserve(count int, now time.Time, wait bool) (time.Duration, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if elapsed := now.Sub(l.updated); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.updated = now
+	}
+	// A batch larger than the burst only needs a full bucket, and leaves it in debt.
+	needed := math.Min(float64(count), l.burst)
+	var delay time.Duration
+	if l.tokens < needed {
+		if !wait {
+			return 0, false
+		}
+		delay = time.Duration((needed - l.tokens) / l.rate * float64(time.Second))
+	}
+	l.tokens -= float64(count)
+	return delay, true
+}
+
+// throttle waits for the rate limit to admit count events.
+func (c *c_client) throttle(count int, wait bool) error {
+	delay, ok := c.rateLimit.reserve(count, time.Now(), wait)
+	if !ok {
+		return errors.ErrRateLimited{}
+	}
+	if delay > 0 {
+		c.stats.rateLimited.Add(1)
+		c.stats.rateLimitWait.Add(int64(delay))
+		time.Sleep(delay)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package tigerbeetle_go
+
+import (
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// SubmitRaw submits body, the events of op encoded in their wire layout, and returns the results
+// encoded likewise. It reaches the operations of newer clusters before this client has a typed
+// method for them, and serves tools that relay encoded requests.
+//
+// The request is admitted like any other: it takes a request slot, waits while the client is
+// paused and fails once it is closed. For the operations this client knows, body must be made of
+// whole events, within the batch size of the operation. Other operations take any body that
+// fits in a message, as a single event.
+func (c *c_client) SubmitRaw(op types.Operation, body []byte) ([]byte, error) {
+	if len(body) > types.MessageBodySizeMax {
+		return nil, errors.ErrMaximumBatchSizeExceeded{}
+	}
+	count := 1
+	if size := types.EventSize(op); size > 0 {
+		if len(body)%size != 0 {
+			return nil, errors.ErrInvalidBodySize{Operation: op, Size: len(body), EventSize: size}
+		}
+		count = len(body) / size
+	}
+
+	release, err := c.begin(op, count, true)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	reply := make([]byte, rawReplySize(op, count))
+	wrote, err := c.send(op, body, reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply[:wrote], nil
+}
+
+// rawReplySize returns the size of the largest reply to a raw request of count events.
+func rawReplySize(op types.Operation, count int) int {
+	switch op {
+	case types.OperationCreateAccounts,
+		types.OperationCreateTransfers,
+		types.OperationLookupAccounts,
+		types.OperationLookupTransfers:
+		return count * types.ResultSize(op)
+	}
+	// Queries and unknown operations may reply with as much as a message holds.
+	return types.MessageBodySizeMax
+}
@@ -0,0 +1,203 @@
+package tigerbeetle_go
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	e "errors"
+	"io"
+	"sync"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// A recording starts with recordingMagic, followed by one entry per request:
+//
+//	operation   u8
+//	events_len  u32, then the events as submitted
+//	reply_len   u32, then the reply as received
+//	error_len   u32, then the error message, empty if the request succeeded
+//
+// Integers are little-endian, and events and replies are in their wire layout.
+var recordingMagic = [8]byte{'T', 'B', 'R', 'E', 'C', 'V', '0', '1'}
+
+// WithRecording records every request the client submits, and the reply or error it gets,
+// to w. The recording can be served without a cluster by NewReplayTransport.
+func WithRecording(w io.Writer) ClientOption {
+	return func(options *clientOptions) {
+		options.recording = w
+	}
+}
+
+type recordingTransport struct {
+	Transport
+
+	mutex  sync.Mutex
+	w      io.Writer
+	header bool
+	err    error
+}
+
+func newRecordingTransport(inner Transport, w io.Writer) *recordingTransport {
+	return &recordingTransport{Transport: inner, w: w}
+}
+
+func (t *recordingTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	wrote, err := t.Transport.Submit(op, events, reply)
+
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// A recording that failed to be written is incomplete, so stop recording but keep serving.
+	if t.err != nil {
+		return wrote, err
+	}
+	if !t.header {
+		t.header = true
+		if _, t.err = t.w.Write(recordingMagic[:]); t.err != nil {
+			return wrote, err
+		}
+	}
+
+	var entry bytes.Buffer
+	entry.WriteByte(byte(op))
+	writeRecordingBytes(&entry, events)
+	writeRecordingBytes(&entry, reply[:wrote])
+	writeRecordingBytes(&entry, []byte(message))
+	_, t.err = t.w.Write(entry.Bytes())
+
+	return wrote, err
+}
+
+func writeRecordingBytes(w *bytes.Buffer, data []byte) {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(data)))
+	w.Write(length[:])
+	w.Write(data)
+}
+
+type recordingEntry struct {
+	op     types.Operation
+	events []byte
+	reply  []byte
+	err    error
+	used   bool
+}
+
+type replayTransport struct {
+	mutex   sync.Mutex
+	entries []recordingEntry
+}
+
+// NewReplayTransport returns a Transport that serves the replies recorded with WithRecording,
+// without any network, for use with WithTransport in hermetic tests.
+//
+// Each request is answered by the first recorded request, not yet replayed, with the same
+// operation and events, so concurrent requests may replay in a different order than recorded.
+// A request that wasn't recorded fails with ErrReplayMismatch.
+func NewReplayTransport(r io.Reader) (Transport, error) {
+	reader := bufio.NewReader(r)
+
+	var magic [8]byte
+	if _, err := io.ReadFull(reader, magic[:]); err != nil {
+		if err == io.EOF {
+			return &replayTransport{}, nil
+		}
+		return nil, err
+	}
+	if magic != recordingMagic {
+		return nil, errors.ErrInvalidRecording{}
+	}
+
+	var entries []recordingEntry
+	for {
+		op, err := reader.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := recordingEntry{op: types.Operation(op)}
+		var message []byte
+		for _, field := range []*[]byte{&entry.events, &entry.reply, &message} {
+			if *field, err = readRecordingBytes(reader); err != nil {
+				if err == io.EOF {
+					err = errors.ErrInvalidRecording{}
+				}
+				return nil, err
+			}
+		}
+		if len(message) > 0 {
+			entry.err = recordedError(string(message))
+		}
+		entries = append(entries, entry)
+	}
+
+	return &replayTransport{entries: entries}, nil
+}
+
+func readRecordingBytes(r *bufio.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	data := make([]byte, binary.LittleEndian.Uint32(length[:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// recordedErrors are the errors a replay returns as themselves rather than by message only, so
+// that code checking for them, such as retry policies, behaves as it did when recording.
+var recordedErrors = []error{
+	errors.ErrClientClosed{},
+	errors.ErrConcurrencyExceeded{},
+	errors.ErrInvalidOperation{},
+	errors.ErrMaximumBatchSizeExceeded{},
+	errors.ErrUnexpected{},
+}
+
+func recordedError(message string) error {
+	for _, err := range recordedErrors {
+		if err.Error() == message {
+			return err
+		}
+	}
+	return e.New(message)
+}
+
+func (t *replayTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for i := range t.entries {
+		entry := &t.entries[i]
+		if entry.used || entry.op != op || !bytes.Equal(entry.events, events) {
+			continue
+		}
+
+		entry.used = true
+		if entry.err != nil {
+			return 0, entry.err
+		}
+		if len(entry.reply) > len(reply) {
+			panic("invalid reply: more results than the request allows")
+		}
+		return copy(reply, entry.reply), nil
+	}
+	return 0, errors.ErrReplayMismatch{Operation: op}
+}
+
+func (t *replayTransport) Close() {}
@@ -0,0 +1,183 @@
+package tigerbeetle_go
+
+import (
+	e "errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// RetryPolicy configures how a client retries submissions that failed for transient reasons.
+//
+// Retrying is safe because creating accounts and transfers is idempotent by ID: a retry of a
+// submission that did reach the cluster will report the events as already existing.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per submission, including the first.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. Each retry waits Multiplier times longer,
+	// up to MaxBackoff. A MaxBackoff or Multiplier left at zero is taken from DefaultRetryPolicy.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomizes each backoff by up to this fraction of it, between 0 and 1.
+	Jitter float64
+	// BudgetRatio caps retries to this fraction of submissions, so that a struggling cluster is
+	// not flooded with retries. Zero disables the budget.
+	BudgetRatio float64
+	// Retryable decides whether an error is transient. Defaults to IsRetryable.
+	Retryable func(err error) bool
+	// OnAttempt is called after every failed attempt, whether or not it will be retried.
+	OnAttempt func(attempt RetryAttempt)
+}
+
+// RetryAttempt describes a failed attempt, as passed to RetryPolicy.OnAttempt.
+type RetryAttempt struct {
+	Operation types.Operation
+	// Attempt counts from 1 for the first submission.
+	Attempt int
+	Err     error
+	// Backoff is how long until the next attempt, or zero if the error is not retried.
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy retries up to five times, backing off from 10ms to 1s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		BudgetRatio:    0.1,
+	}
+}
+
+// IsRetryable reports whether err is a transient failure that a later attempt may not hit.
+func IsRetryable(err error) bool {
+	return e.Is(err, errors.ErrConcurrencyExceeded{})
+}
+
+// WithRetryPolicy makes the client retry transient failures according to policy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(options *clientOptions) {
+		options.retryPolicy = &policy
+	}
+}
+
+// retryBudgetMax bounds how many retries can be saved up while submissions succeed.
+const retryBudgetMax = 100
+
+type retryTransport struct {
+	Transport
+	policy RetryPolicy
+
+	mutex  sync.Mutex
+	random *rand.Rand
+	budget float64
+}
+
+func newRetryTransport(inner Transport, policy RetryPolicy) *retryTransport {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	defaults := DefaultRetryPolicy()
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = max(defaults.MaxBackoff, policy.InitialBackoff)
+	}
+	if policy.Multiplier == 0 {
+		policy.Multiplier = defaults.Multiplier
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 1
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsRetryable
+	}
+
+	return &retryTransport{
+		Transport: inner,
+		policy:    policy,
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		budget:    retryBudgetMax,
+	}
+}
+
+func (t *retryTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	t.deposit()
+
+	backoff := t.policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		wrote, err := t.Transport.Submit(op, events, reply)
+		if err == nil {
+			return wrote, nil
+		}
+
+		retry := attempt < t.policy.MaxAttempts && t.policy.Retryable(err) && t.withdraw()
+		wait := time.Duration(0)
+		if retry {
+			wait = t.jitter(backoff)
+		}
+
+		if t.policy.OnAttempt != nil {
+			t.policy.OnAttempt(RetryAttempt{
+				Operation: op,
+				Attempt:   attempt,
+				Err:       err,
+				Backoff:   wait,
+			})
+		}
+
+		if !retry {
+			return 0, err
+		}
+
+		time.Sleep(wait)
+		backoff = time.Duration(math.Min(
+			float64(backoff)*t.policy.Multiplier,
+			float64(t.policy.MaxBackoff),
+		))
+	}
+}
+
+// deposit earns a fraction of a retry for every submission.
+func (t *retryTransport) deposit() {
+	if t.policy.BudgetRatio <= 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.budget = math.Min(retryBudgetMax, t.budget+t.policy.BudgetRatio)
+}
+
+// withdraw spends one retry from the budget, returning false if none are left.
+func (t *retryTransport) withdraw() bool {
+	if t.policy.BudgetRatio <= 0 {
+		return true
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.budget < 1 {
+		return false
+	}
+	t.budget--
+	return true
+}
+
+func (t *retryTransport) jitter(backoff time.Duration) time.Duration {
+	if t.policy.Jitter <= 0 {
+		return backoff
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return time.Duration(float64(backoff) * (1 + t.policy.Jitter*(t.random.Float64()*2-1)))
+}
@@ -0,0 +1,226 @@
+package tigerbeetle_go
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// RouteFunc returns the index of the cluster that holds the accounts of ledger with the given
+// ID, among the clients of a Router.
+type RouteFunc func(ledger uint32, accountID types.Uint128) int
+
+// Router submits to several clusters, as when tenants are sharded across them, routing each
+// event to its cluster with a RouteFunc:
+//   - Accounts are routed by their ledger and ID.
+//   - Transfers are routed by their ledger and debit account, and both accounts must be on the
+//     same cluster, since a transfer cannot span clusters. Post and void transfers must
+//     therefore name their accounts.
+//   - Lookups and queries, which know nothing but the IDs, go to every cluster.
+//
+// A batch is split into a request per cluster, submitted concurrently, and the results are
+// merged to index into the batch as if it had been submitted whole. A linked chain must not
+// span clusters, since the clusters cannot create it atomically.
+type Router struct {
+	clients []Client
+	route   RouteFunc
+}
+
+// NewRouter returns a router over clients, routing with route. The router owns the clients,
+// and closes them on Close.
+func NewRouter(clients []Client, route RouteFunc) *Router {
+	return &Router{clients: clients, route: route}
+}
+
+// Clients returns the clients of the router, by cluster index.
+func (r *Router) Clients() []Client {
+	return r.clients
+}
+
+func (r *Router) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	clusters, err := r.routeEvents(len(accounts), func(i int) (int, int, bool) {
+		cluster := r.route(accounts[i].Ledger, accounts[i].ID)
+		return cluster, cluster, accounts[i].AccountFlags().Linked
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routeBatch(r, accounts, clusters,
+		func(client Client, batch []types.Account) ([]types.AccountEventResult, error) {
+			return client.CreateAccounts(batch)
+		},
+		func(result *types.AccountEventResult) *uint32 { return &result.Index },
+	)
+}
+
+func (r *Router) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	clusters, err := r.routeEvents(len(transfers), func(i int) (int, int, bool) {
+		transfer := transfers[i]
+		debit := r.route(transfer.Ledger, transfer.DebitAccountID)
+		credit := r.route(transfer.Ledger, transfer.CreditAccountID)
+		return debit, credit, transfer.TransferFlags().Linked
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routeBatch(r, transfers, clusters,
+		func(client Client, batch []types.Transfer) ([]types.TransferEventResult, error) {
+			return client.CreateTransfers(batch)
+		},
+		func(result *types.TransferEventResult) *uint32 { return &result.Index },
+	)
+}
+
+// routeEvents returns the cluster of each of count events, where event i is routed to the
+// clusters of both its accounts, which must agree, and is linked to the next one if linked.
+func (r *Router) routeEvents(count int, route func(i int) (int, int, bool)) ([]int, error) {
+	clusters := make([]int, count)
+	linked := false
+	for i := range clusters {
+		cluster, other, link := route(i)
+		if cluster < 0 || cluster >= len(r.clients) {
+			return nil, errors.ErrInvalidRoute{Index: i, Cluster: cluster}
+		}
+		if other != cluster || (linked && clusters[i-1] != cluster) {
+			return nil, errors.ErrCrossCluster{Index: i}
+		}
+		clusters[i] = cluster
+		linked = link
+	}
+	return clusters, nil
+}
+
+// routeBatch submits each event to its cluster and merges the results, of which index returns
+// the index into the events.
+func routeBatch[E any, R any](
+	r *Router,
+	events []E,
+	clusters []int,
+	submit func(client Client, batch []E) ([]R, error),
+	index func(result *R) *uint32,
+) ([]R, error) {
+	batches := make([][]E, len(r.clients))
+	// indexes maps the index of an event in the batch of its cluster to its index in events.
+	indexes := make([][]uint32, len(r.clients))
+	for i, event := range events {
+		cluster := clusters[i]
+		batches[cluster] = append(batches[cluster], event)
+		indexes[cluster] = append(indexes[cluster], uint32(i))
+	}
+
+	var wait sync.WaitGroup
+	replies := make([][]R, len(r.clients))
+	failures := make([]error, len(r.clients))
+	for cluster, batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			replies[cluster], failures[cluster] = submit(r.clients[cluster], batch)
+		}()
+	}
+	wait.Wait()
+
+	var results []R
+	var err error
+	for cluster, reply := range replies {
+		if failures[cluster] != nil && err == nil {
+			err = errors.ErrClusterFailed{Cluster: cluster, Err: failures[cluster]}
+		}
+		for _, result := range reply {
+			*index(&result) = indexes[cluster][*index(&result)]
+			results = append(results, result)
+		}
+	}
+	slices.SortFunc(results, func(a, b R) int {
+		return int(*index(&a)) - int(*index(&b))
+	})
+	return results, err
+}
+
+// LookupAccounts looks up the accounts on every cluster, returning those found in the order of
+// accountIDs.
+func (r *Router) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	found, err := broadcast(r, func(client Client) ([]types.Account, error) {
+		return client.LookupAccounts(accountIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inOrder(accountIDs, found, func(account types.Account) types.Uint128 { return account.ID }), nil
+}
+
+// LookupTransfers looks up the transfers on every cluster, returning those found in the order
+// of transferIDs.
+func (r *Router) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	found, err := broadcast(r, func(client Client) ([]types.Transfer, error) {
+		return client.LookupTransfers(transferIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inOrder(transferIDs, found, func(transfer types.Transfer) types.Uint128 { return transfer.ID }), nil
+}
+
+// GetAccountTransfers queries every cluster, of which only that of the account has results.
+func (r *Router) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	return broadcast(r, func(client Client) ([]types.Transfer, error) {
+		return client.GetAccountTransfers(filter)
+	})
+}
+
+// GetAccountHistory queries every cluster, of which only that of the account has results.
+func (r *Router) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	return broadcast(r, func(client Client) ([]types.AccountBalance, error) {
+		return client.GetAccountHistory(filter)
+	})
+}
+
+// broadcast submits to every cluster concurrently, returning the results of all of them by
+// cluster index, or the first failure.
+func broadcast[R any](r *Router, submit func(client Client) ([]R, error)) ([]R, error) {
+	var wait sync.WaitGroup
+	replies := make([][]R, len(r.clients))
+	failures := make([]error, len(r.clients))
+	for cluster, client := range r.clients {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			replies[cluster], failures[cluster] = submit(client)
+		}()
+	}
+	wait.Wait()
+
+	for cluster, err := range failures {
+		if err != nil {
+			return nil, errors.ErrClusterFailed{Cluster: cluster, Err: err}
+		}
+	}
+	return slices.Concat(replies...), nil
+}
+
+// inOrder returns the objects found for ids, in the order of ids.
+func inOrder[T any](ids []types.Uint128, found []T, id func(object T) types.Uint128) []T {
+	byID := make(map[types.Uint128]T, len(found))
+	for _, object := range found {
+		byID[id(object)] = object
+	}
+	ordered := make([]T, 0, len(found))
+	for _, objectID := range ids {
+		if object, ok := byID[objectID]; ok {
+			ordered = append(ordered, object)
+		}
+	}
+	return ordered
+}
+
+// Close closes the clients of every cluster.
+func (r *Router) Close() {
+	for _, client := range r.clients {
+		client.Close()
+	}
+}
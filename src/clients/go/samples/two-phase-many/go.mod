@@ -1,6 +1,6 @@
 module two-phase-many
 
-go 1.17
+go 1.23
 
 require github.com/tigerbeetle/tigerbeetle-go v0.0.0
 
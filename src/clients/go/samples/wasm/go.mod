@@ -0,0 +1,7 @@
+module wasm
+
+go 1.23
+
+require github.com/tigerbeetle/tigerbeetle-go v0.0.0
+
+replace github.com/tigerbeetle/tigerbeetle-go => ../../
@@ -0,0 +1,60 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"log"
+	"syscall/js"
+
+	. "github.com/tigerbeetle/tigerbeetle-go"
+	. "github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// The plugin submits its requests through tigerbeetleSubmit, which the host defines, as in
+// run.mjs or index.html.
+func main() {
+	client, err := NewClient(ToUint128(0), nil, 1,
+		WithTransport(NewJSTransport(js.Global().Get("tigerbeetleSubmit"))),
+	)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	accounts := []Account{
+		{ID: ID(), Ledger: 1, Code: 1},
+		{ID: ID(), Ledger: 1, Code: 1},
+	}
+	results, err := client.CreateAccounts(accounts)
+	if err != nil {
+		log.Fatalf("Error creating accounts: %s", err)
+	}
+	for _, result := range results {
+		log.Fatalf("Error creating account %d: %s", result.Index, result.Result)
+	}
+
+	transferResults, err := client.CreateTransfers([]Transfer{{
+		ID:              ID(),
+		DebitAccountID:  accounts[0].ID,
+		CreditAccountID: accounts[1].ID,
+		Amount:          ToUint128(10),
+		Ledger:          1,
+		Code:            1,
+	}})
+	if err != nil {
+		log.Fatalf("Error creating transfer: %s", err)
+	}
+	for _, result := range transferResults {
+		log.Fatalf("Error creating transfer: %s", result.Result)
+	}
+
+	found, err := client.LookupAccounts([]Uint128{accounts[0].ID, accounts[1].ID})
+	if err != nil {
+		log.Fatalf("Error looking up accounts: %s", err)
+	}
+	for _, account := range found {
+		log.Printf("Account %s: debits %s, credits %s",
+			account.ID, account.DebitsPosted, account.CreditsPosted)
+	}
+	log.Printf("ok")
+}
@@ -0,0 +1,73 @@
+// Command relay submits the requests that the WebAssembly plugin posts to it to the cluster,
+// with SubmitRaw, for hosts that have no native client of their own.
+//
+//	POST /submit/{operation} with the events in their wire layout replies with the results.
+package main
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	. "github.com/tigerbeetle/tigerbeetle-go"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	. "github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+func main() {
+	port := os.Getenv("TB_ADDRESS")
+	if port == "" {
+		port = "3000"
+	}
+	listen := os.Getenv("RELAY_ADDRESS")
+	if listen == "" {
+		listen = "localhost:8080"
+	}
+
+	client, err := NewClient(ToUint128(0), []string{port}, 32)
+	if err != nil {
+		log.Fatalf("Error creating client: %s", err)
+	}
+	defer client.Close()
+
+	http.HandleFunc("POST /submit/{operation}", func(w http.ResponseWriter, r *http.Request) {
+		// The plugin may be served from another origin, as from index.html.
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		op, err := strconv.ParseUint(r.PathValue("operation"), 10, 8)
+		if err != nil {
+			http.Error(w, "invalid operation", http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MessageBodySizeMax))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		results, err := client.SubmitRaw(Operation(op), body)
+		if err != nil {
+			// The plugin maps the status back to the error of the request.
+			status := 0
+			switch err.(type) {
+			case errors.ErrMaximumBatchSizeExceeded, errors.ErrBatchTooLarge:
+				status = 1
+			case errors.ErrInvalidOperation:
+				status = 2
+			case errors.ErrConcurrencyExceeded:
+				status = 3
+			case errors.ErrClientClosed:
+				status = 4
+			}
+			w.Header().Set("X-TigerBeetle-Status", strconv.Itoa(status))
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(results)
+	})
+	log.Printf("Relaying to the cluster at %s on %s", port, listen)
+	log.Fatal(http.ListenAndServe(listen, nil))
+}
@@ -0,0 +1,26 @@
+// Runs the plugin in Node, submitting its requests through the relay:
+//
+//   GOOS=js GOARCH=wasm go build -o main.wasm . && node run.mjs
+import fs from "node:fs";
+import path from "node:path";
+import { execFileSync } from "node:child_process";
+
+const goroot = execFileSync("go", ["env", "GOROOT"]).toString().trim();
+await import(path.join(goroot, "lib", "wasm", "wasm_exec.js"));
+
+const relay = process.env.RELAY_URL ?? "http://localhost:8080";
+
+globalThis.tigerbeetleSubmit = async (operation, events, replySizeMax) => {
+  const response = await fetch(`${relay}/submit/${operation}`, { method: "POST", body: events });
+  if (!response.ok) {
+    const status = Number(response.headers.get("X-TigerBeetle-Status"));
+    throw status > 0 ? { status } : new Error(await response.text());
+  }
+  return new Uint8Array(await response.arrayBuffer());
+};
+
+const go = new Go();
+const { instance } = await WebAssembly.instantiate(fs.readFileSync("main.wasm"), go.importObject);
+await go.run(instance);
+// Don't wait for the timeouts the Go runtime may have left scheduled once the program exited.
+process.exit(go.exitCode);
@@ -0,0 +1,100 @@
+package tigerbeetle_go
+
+import (
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// CreateAccountsSplit creates any number of accounts in as few requests as fit in a message
+// each, never splitting a linked chain across requests. The results index into accounts.
+func CreateAccountsSplit(
+	client Client,
+	accounts []types.Account,
+) ([]types.AccountEventResult, error) {
+	batches, err := splitBatches(types.OperationCreateAccounts, len(accounts), func(i int) bool {
+		return accounts[i].AccountFlags().Linked
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []types.AccountEventResult
+	for _, batch := range batches {
+		batchResults, err := client.CreateAccounts(accounts[batch.start:batch.end])
+		if err != nil {
+			return results, err
+		}
+		for _, result := range batchResults {
+			result.Index += uint32(batch.start)
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// CreateTransfersSplit creates any number of transfers in as few requests as fit in a message
+// each, never splitting a linked chain across requests. The results index into transfers.
+func CreateTransfersSplit(
+	client Client,
+	transfers []types.Transfer,
+) ([]types.TransferEventResult, error) {
+	batches, err := splitBatches(types.OperationCreateTransfers, len(transfers), func(i int) bool {
+		return transfers[i].TransferFlags().Linked
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []types.TransferEventResult
+	for _, batch := range batches {
+		batchResults, err := client.CreateTransfers(transfers[batch.start:batch.end])
+		if err != nil {
+			return results, err
+		}
+		for _, result := range batchResults {
+			result.Index += uint32(batch.start)
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+type batchRange struct {
+	start int
+	end   int
+}
+
+// splitBatches cuts count events of op into batches that each fit in a message, cutting only
+// between linked chains, where linked reports whether event i is linked to the next one.
+func splitBatches(op types.Operation, count int, linked func(i int) bool) ([]batchRange, error) {
+	var batches []batchRange
+	budget := types.NewBatchBudget(op)
+	start := 0
+	for chainStart := 0; chainStart < count; {
+		chainEnd := chainStart + 1
+		for chainEnd < count && linked(chainEnd-1) {
+			chainEnd++
+		}
+
+		chain := chainEnd - chainStart
+		if !budget.Add(chain) {
+			if chainStart == start {
+				return nil, errors.ErrBatchTooLarge{
+					Operation: op,
+					Count:     chain,
+					Max:       types.MaxBatchSize(op),
+				}
+			}
+
+			batches = append(batches, batchRange{start: start, end: chainStart})
+			start = chainStart
+			budget.Reset()
+			continue
+		}
+		chainStart = chainEnd
+	}
+	if start < count {
+		batches = append(batches, batchRange{start: start, end: count})
+	}
+	return batches, nil
+}
@@ -0,0 +1,101 @@
+package tigerbeetle_go
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Stats is a snapshot of the internals of a client, for dashboards and debug endpoints. The
+// counters add up from the creation of the client.
+type Stats struct {
+	// InFlight counts the requests submitted to the cluster that have yet to complete.
+	InFlight int
+	// Queued counts the requests waiting to be submitted, for the client to be resumed or for a
+	// free request slot with ConcurrencyBlock.
+	Queued int
+	// Operations counts the requests completed by operation, for the operations submitted at
+	// least once.
+	Operations map[types.Operation]OperationStats
+	// BytesSent and BytesReceived count the bytes of the events submitted and of the results
+	// replied, without the headers of the messages.
+	BytesSent     uint64
+	BytesReceived uint64
+	// LastCompleted is when a request last completed, or zero if none has.
+	LastCompleted time.Time
+	// RateLimited counts the requests that waited for the rate limit of WithRateLimit, and
+	// RateLimitWait adds up how long they waited.
+	RateLimited   uint64
+	RateLimitWait time.Duration
+	// Circuit is the state of the circuit breaker of WithCircuitBreaker, or CircuitClosed
+	// without one.
+	Circuit CircuitState
+}
+
+// OperationStats counts the requests completed for an operation.
+type OperationStats struct {
+	Requests uint64
+	// Errors counts the requests that failed with an error, rather than with the results of
+	// events that failed.
+	Errors uint64
+}
+
+type clientStats struct {
+	inFlight      atomic.Int64
+	queued        atomic.Int64
+	requests      [256]atomic.Uint64
+	errors        [256]atomic.Uint64
+	bytesSent     atomic.Uint64
+	bytesReceived atomic.Uint64
+	// lastCompleted is in nanoseconds since the Unix epoch.
+	lastCompleted atomic.Int64
+	rateLimited   atomic.Uint64
+	rateLimitWait atomic.Int64
+}
+
+// Stats returns a snapshot of the internals of the client. Requests that complete while the
+// snapshot is taken may be partly included.
+func (c *c_client) Stats() Stats {
+	stats := Stats{
+		InFlight:      int(c.stats.inFlight.Load()),
+		Queued:        int(c.stats.queued.Load()),
+		Operations:    make(map[types.Operation]OperationStats),
+		BytesSent:     c.stats.bytesSent.Load(),
+		BytesReceived: c.stats.bytesReceived.Load(),
+		RateLimited:   c.stats.rateLimited.Load(),
+		RateLimitWait: time.Duration(c.stats.rateLimitWait.Load()),
+	}
+	for op := range c.stats.requests {
+		if requests := c.stats.requests[op].Load(); requests > 0 {
+			stats.Operations[types.Operation(op)] = OperationStats{
+				Requests: requests,
+				Errors:   c.stats.errors[op].Load(),
+			}
+		}
+	}
+	if c.breaker != nil {
+		stats.Circuit = c.breaker.State()
+	}
+	if lastCompleted := c.stats.lastCompleted.Load(); lastCompleted != 0 {
+		stats.LastCompleted = time.Unix(0, lastCompleted)
+	}
+	return stats
+}
+
+func (s *clientStats) submitted(events []byte) {
+	s.inFlight.Add(1)
+	s.bytesSent.Add(uint64(len(events)))
+}
+
+func (s *clientStats) completed(op types.Operation, wrote int, err error) {
+	s.inFlight.Add(-1)
+	if err != nil {
+		s.errors[op].Add(1)
+	} else {
+		s.bytesReceived.Add(uint64(wrote))
+	}
+	// Count the request last, for a snapshot not to see more errors than requests.
+	s.requests[op].Add(1)
+	s.lastCompleted.Store(time.Now().UnixNano())
+}
@@ -0,0 +1,111 @@
+package tigerbeetle_go
+
+import (
+	"iter"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+const (
+	// lookupStreamInFlightMax is how many chunks a stream keeps in flight ahead of its consumer.
+	lookupStreamInFlightMax = 4
+)
+
+type lookupAccountsResult struct {
+	accounts []types.Account
+	err      error
+}
+
+// LookupAccountsStream looks up the accounts in chunks as accountIDs are produced, keeping a
+// few chunks in flight, and yields the accounts found in the order of their IDs.
+// IDs that don't exist are skipped. Iteration stops after the first error is yielded.
+func (c *c_client) LookupAccountsStream(
+	accountIDs iter.Seq[types.Uint128],
+) iter.Seq2[types.Account, error] {
+	return lookupAccountsStream(c, accountIDs)
+}
+
+func lookupAccountsStream(
+	client Client,
+	accountIDs iter.Seq[types.Uint128],
+) iter.Seq2[types.Account, error] {
+	return func(yield func(types.Account, error) bool) {
+		pending := make(chan chan lookupAccountsResult, lookupStreamInFlightMax)
+		stop := make(chan struct{})
+		stopped := make(chan struct{})
+
+		// Produce chunks on a separate goroutine so that lookups are in flight while the
+		// consumer is processing the results of earlier chunks.
+		go func() {
+			defer close(stopped)
+			defer close(pending)
+
+			// stopping reports, without blocking, whether the consumer has stopped, as a send on
+			// pending may still be chosen over stop while both are ready.
+			stopping := func() bool {
+				select {
+				case <-stop:
+					return true
+				default:
+					return false
+				}
+			}
+
+			submit := func(chunk []types.Uint128) bool {
+				result := make(chan lookupAccountsResult, 1)
+				select {
+				case pending <- result:
+				case <-stop:
+					return false
+				}
+				if stopping() {
+					return false
+				}
+
+				go func() {
+					accounts, err := client.LookupAccounts(chunk)
+					result <- lookupAccountsResult{accounts: accounts, err: err}
+				}()
+				return true
+			}
+
+			chunkMax := types.MaxBatchSize(types.OperationLookupAccounts)
+			chunk := make([]types.Uint128, 0, chunkMax)
+			for id := range accountIDs {
+				if stopping() {
+					return
+				}
+				chunk = append(chunk, id)
+				if len(chunk) == chunkMax {
+					if !submit(chunk) {
+						return
+					}
+					chunk = make([]types.Uint128, 0, chunkMax)
+				}
+			}
+			if len(chunk) > 0 {
+				submit(chunk)
+			}
+		}()
+
+		// Don't return while the producer may still be pulling from accountIDs.
+		defer func() {
+			close(stop)
+			<-stopped
+		}()
+
+		for result := range pending {
+			response := <-result
+			if response.err != nil {
+				yield(types.Account{}, response.err)
+				return
+			}
+
+			for _, account := range response.accounts {
+				if !yield(account, nil) {
+					return
+				}
+			}
+		}
+	}
+}
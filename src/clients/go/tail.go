@@ -0,0 +1,91 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// TailEvent is a transfer reported by TailTransfers. A tail ends with an event holding a non-nil
+// Err if paging fails.
+type TailEvent struct {
+	Transfer types.Transfer
+	// Checkpoint is the sinceTimestamp to resume the tail from once Transfer is handled.
+	Checkpoint uint64
+	Err        error
+}
+
+// TailTransfers reports on the returned channel the transfers of accountID with a timestamp
+// after sinceTimestamp, in timestamp order, then the new ones as they are created, until ctx is
+// done. It pages GetAccountTransfers until caught up, and polls it from then on.
+//
+// Delivery is at least once: saving the Checkpoint of every event once it is handled, and
+// passing the last one saved as sinceTimestamp to resume, reports again at most the transfers
+// that were handled but not checkpointed. Unlike StreamChanges, the transfers are reported as
+// they are, without telling the expiry of pending transfers.
+func (c *c_client) TailTransfers(
+	ctx context.Context,
+	accountID types.Uint128,
+	sinceTimestamp uint64,
+) <-chan TailEvent {
+	return tailTransfers(ctx, c, accountID, sinceTimestamp)
+}
+
+func tailTransfers(
+	ctx context.Context,
+	client Client,
+	accountID types.Uint128,
+	sinceTimestamp uint64,
+) <-chan TailEvent {
+	events := make(chan TailEvent)
+	go func() {
+		defer close(events)
+
+		emit := func(event TailEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		limit := types.MaxBatchSize(types.OperationGetAccountTransfers)
+		cursor := sinceTimestamp
+		for {
+			page, err := client.GetAccountTransfers(types.AccountFilter{
+				AccountID:    accountID,
+				TimestampMin: cursor + 1,
+				Limit:        uint32(limit),
+				Flags: types.AccountFilterFlags{
+					Debits:  true,
+					Credits: true,
+				}.ToUint32(),
+			})
+			if err != nil {
+				emit(TailEvent{Err: err})
+				return
+			}
+
+			for _, transfer := range page {
+				cursor = transfer.Timestamp
+				if !emit(TailEvent{Transfer: transfer, Checkpoint: cursor}) {
+					return
+				}
+			}
+
+			// A full page may be followed by more transfers already, so only wait once caught up.
+			if len(page) == limit {
+				continue
+			}
+			select {
+			case <-time.After(changesPollInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events
+}
@@ -1,35 +1,10 @@
 package tigerbeetle_go
 
-/*
-#cgo CFLAGS: -g -Wall
-#cgo darwin,arm64 LDFLAGS: ${SRCDIR}/pkg/native/aarch64-macos/libtb_client.a -ldl -lm
-#cgo darwin,amd64 LDFLAGS: ${SRCDIR}/pkg/native/x86_64-macos/libtb_client.a -ldl -lm
-#cgo linux,arm64 LDFLAGS: ${SRCDIR}/pkg/native/aarch64-linux/libtb_client.a -ldl -lm
-#cgo linux,amd64 LDFLAGS: ${SRCDIR}/pkg/native/x86_64-linux/libtb_client.a -ldl -lm
-#cgo windows,amd64 LDFLAGS: -L${SRCDIR}/pkg/native/x86_64-windows -ltb_client -lws2_32 -lntdll
-
-#include <stdlib.h>
-#include <string.h>
-#include "./pkg/native/tb_client.h"
-
-#ifndef __declspec
-	#define __declspec(x)
-#endif
-
-typedef const uint8_t* tb_result_bytes_t;
-
-extern __declspec(dllexport) void onGoPacketCompletion(
-	uintptr_t ctx,
-	tb_client_t client,
-	tb_packet_t* packet,
-	tb_result_bytes_t result_ptr,
-	uint32_t result_len
-);
-*/
-import "C"
 import (
+	"context"
 	e "errors"
-	"strings"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
@@ -38,247 +13,343 @@ import (
 
 ///////////////////////////////////////////////////////////////
 
-type Client interface {
-	CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error)
-	CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error)
-	LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error)
-	LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error)
-	GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error)
-	GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error)
-
-	Nop() error
-	Close()
-}
-
-type request struct {
-	packet *C.tb_packet_t
-	result unsafe.Pointer
-	ready  chan struct{}
-}
-
 type c_client struct {
-	tb_client C.tb_client_t
+	transport Transport
+	// async is transport, if it completes requests itself rather than on a goroutine waiting for
+	// them, as tb_client does when no other transport is wrapped around it.
+	async asyncTransport
+	// native is the tb_client transport at the bottom of transport, if any.
+	native *nativeTransport
+	// hedgeNative is the second tb_client session that lookups are hedged on, if any.
+	hedgeNative *nativeTransport
+	connection  *connectionTransport
+	preflight   bool
+	rateLimit   *rateLimiter
+	breaker     *circuitBreaker
+	duplicates  bool
+
+	// slots limits the requests in flight for ConcurrencyBlock, until slotsCtx is done.
+	slots    chan struct{}
+	slotsCtx context.Context
+
+	// closing is set once the client stops accepting requests; inflight counts the requests
+	// that were accepted before that and have yet to complete.
+	mutex    sync.RWMutex
+	closing  bool
+	inflight sync.WaitGroup
+	done     chan struct{}
+
+	// paused is set from Pause until Resume closes resumed, which wakes up the queued requests.
+	// drained is closed once the requests in flight at Pause have completed.
+	paused    bool
+	pauseMode PauseMode
+	resumed   chan struct{}
+	drained   chan struct{}
+
+	stats clientStats
 }
 
 func NewClient(
 	clusterID types.Uint128,
 	addresses []string,
 	concurrencyMax uint,
+	opts ...ClientOption,
 ) (Client, error) {
-	// Allocate a cstring of the addresses joined with ",".
-	addresses_raw := strings.Join(addresses[:], ",")
-	c_addresses := C.CString(addresses_raw)
-	defer C.free(unsafe.Pointer(c_addresses))
-
-	var tb_client C.tb_client_t
-
-	// Create the tb_client.
-	status := C.tb_client_init(
-		&tb_client,
-		C.tb_uint128_t(clusterID),
-		c_addresses,
-		C.uint32_t(len(addresses_raw)),
-		C.uint32_t(concurrencyMax),
-		C.uintptr_t(0), // on_completion_ctx
-		(*[0]byte)(C.onGoPacketCompletion),
-	)
+	options := newClientOptions(opts)
+
+	var native *nativeTransport
+	// discoveryAddresses are the addresses resolved by DNSDiscovery, if any.
+	var discoveryAddresses []string
+	transport := options.transport
+	if transport == nil {
+		if options.logger != nil {
+			nativeLogger.Store(options.logger)
+		}
+		if options.logger != nil || options.connectionMonitor != nil {
+			registerNativeLog()
+		}
+
+		if options.dnsDiscovery != nil {
+			discovery := options.dnsDiscovery.withDefaults()
+			options.dnsDiscovery = &discovery
+			resolved, err := discovery.resolve(context.Background(), addresses)
+			if err != nil {
+				return nil, err
+			}
+			discoveryAddresses, addresses = addresses, resolved
+		}
 
-	if status != C.TB_STATUS_SUCCESS {
-		switch status {
-		case C.TB_STATUS_UNEXPECTED:
-			return nil, errors.ErrUnexpected{}
-		case C.TB_STATUS_OUT_OF_MEMORY:
-			return nil, errors.ErrOutOfMemory{}
-		case C.TB_STATUS_ADDRESS_INVALID:
-			return nil, errors.ErrInvalidAddress{}
-		case C.TB_STATUS_ADDRESS_LIMIT_EXCEEDED:
-			return nil, errors.ErrAddressLimitExceeded{}
-		case C.TB_STATUS_CONCURRENCY_MAX_INVALID:
-			return nil, errors.ErrInvalidConcurrencyMax{}
-		case C.TB_STATUS_SYSTEM_RESOURCES:
-			return nil, errors.ErrSystemResources{}
-		case C.TB_STATUS_NETWORK_SUBSYSTEM:
-			return nil, errors.ErrNetworkSubsystem{}
-		default:
-			panic("tb_client_init(): invalid error code")
+		var err error
+		native, err = newNativeTransport(clusterID, addresses, concurrencyMax)
+		if err != nil {
+			return nil, err
 		}
+		transport = native
+	}
+
+	monitor := ConnectionMonitor{}
+	if options.connectionMonitor != nil {
+		monitor = *options.connectionMonitor
+	}
+	connection := newConnectionTransport(transport, monitor)
+	if native != nil {
+		nativeConnections.Store(connection, struct{}{})
+	}
+	transport = connection
+
+	var hedgeNative *nativeTransport
+	if options.hedgePolicy != nil {
+		hedge := options.hedgePolicy.Transport
+		if hedge == nil && native != nil {
+			var err error
+			hedgeNative, err = newNativeTransport(clusterID, addresses, concurrencyMax)
+			if err != nil {
+				connection.Close()
+				return nil, err
+			}
+			hedge = hedgeNative
+		} else if hedge == nil {
+			hedge = transport
+		}
+		transport = newHedgeTransport(transport, hedge, *options.hedgePolicy)
+	}
+
+	if options.recording != nil {
+		transport = newRecordingTransport(transport, options.recording)
+	}
+	if options.audit != nil {
+		transport = &auditTransport{Transport: transport, log: options.audit}
+	}
+
+	if options.retryPolicy != nil {
+		policy := *options.retryPolicy
+		if options.logger != nil {
+			policy = logRetries(policy, options.logger)
+		}
+		transport = newRetryTransport(transport, policy)
+	}
+
+	if options.latencyMonitor != nil {
+		transport = newLatencyTransport(transport, options.latencyMonitor, options.logger)
 	}
 
 	c := &c_client{
-		tb_client: tb_client,
+		transport:   transport,
+		native:      native,
+		hedgeNative: hedgeNative,
+		connection:  connection,
+		preflight:   options.preflight,
+		duplicates:  options.duplicates,
+		rateLimit:   options.rateLimit,
+		done:        make(chan struct{}),
+	}
+	if _, ok := connection.Transport.(asyncTransport); ok && transport == Transport(connection) {
+		c.async = connection
+	}
+	if options.circuitBreaker != nil {
+		c.breaker = newCircuitBreaker(*options.circuitBreaker)
+	}
+	// A transport of its own leaves concurrencyMax unused, so zero does not limit requests.
+	if options.concurrencyMode == ConcurrencyBlock && concurrencyMax > 0 {
+		c.slots = make(chan struct{}, concurrencyMax)
+		c.slotsCtx = options.concurrencyCtx
+	}
+	if native != nil && options.dnsDiscovery != nil {
+		go options.dnsDiscovery.watch(discoveryAddresses, addresses, c.UpdateAddresses, c.done)
 	}
 
-	return c, nil
+	if len(options.interceptors) > 0 {
+		return RequestTimeout(newInterceptClient(c, options.interceptors), options.requestTimeout), nil
+	}
+	return RequestTimeout(c, options.requestTimeout), nil
 }
 
+// Close stops accepting requests and waits for the in-flight ones to complete before shutting
+// down the client.
 func (c *c_client) Close() {
-	if c.tb_client != nil {
-		C.tb_client_deinit(c.tb_client)
-		c.tb_client = nil
-	}
-}
-
-func getEventSize(op C.TB_OPERATION) uintptr {
-	switch op {
-	case C.TB_OPERATION_CREATE_ACCOUNTS:
-		return unsafe.Sizeof(types.Account{})
-	case C.TB_OPERATION_CREATE_TRANSFERS:
-		return unsafe.Sizeof(types.Transfer{})
-	case C.TB_OPERATION_LOOKUP_ACCOUNTS:
-		fallthrough
-	case C.TB_OPERATION_LOOKUP_TRANSFERS:
-		return unsafe.Sizeof(types.Uint128{})
-	case C.TB_OPERATION_GET_ACCOUNT_TRANSFERS:
-		return unsafe.Sizeof(types.AccountFilter{})
-	case C.TB_OPERATION_GET_ACCOUNT_HISTORY:
-		return unsafe.Sizeof(types.AccountFilter{})
-	default:
-		return 0
-	}
-}
-
-func getResultSize(op C.TB_OPERATION) uintptr {
-	switch op {
-	case C.TB_OPERATION_CREATE_ACCOUNTS:
-		return unsafe.Sizeof(types.AccountEventResult{})
-	case C.TB_OPERATION_CREATE_TRANSFERS:
-		return unsafe.Sizeof(types.TransferEventResult{})
-	case C.TB_OPERATION_LOOKUP_ACCOUNTS:
-		return unsafe.Sizeof(types.Account{})
-	case C.TB_OPERATION_LOOKUP_TRANSFERS:
-		return unsafe.Sizeof(types.Transfer{})
-	case C.TB_OPERATION_GET_ACCOUNT_TRANSFERS:
-		return unsafe.Sizeof(types.Transfer{})
-	case C.TB_OPERATION_GET_ACCOUNT_HISTORY:
-		return unsafe.Sizeof(types.AccountBalance{})
-	default:
-		return 0
+	_ = c.CloseContext(context.Background())
+}
+
+// CloseContext stops accepting requests and waits for the in-flight ones to complete, or for
+// ctx to be done, in which case the client keeps shutting down in the background once they
+// complete. Either way, Done is closed when the shutdown has finished.
+func (c *c_client) CloseContext(ctx context.Context) error {
+	c.mutex.Lock()
+	first := !c.closing
+	c.closing = true
+	if c.paused {
+		c.paused = false
+		close(c.resumed)
+	}
+	c.mutex.Unlock()
+
+	if first {
+		go func() {
+			c.inflight.Wait()
+			c.transport.Close()
+			close(c.done)
+		}()
+	}
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
+// State returns the state of the client's session with the cluster.
+func (c *c_client) State() ConnectionState {
+	return c.connection.State()
+}
+
+// Done returns a channel that is closed once the client has fully shut down, with no requests
+// in flight and the underlying transport closed.
+func (c *c_client) Done() <-chan struct{} {
+	return c.done
+}
+
 func (c *c_client) doRequest(
-	op C.TB_OPERATION,
+	op types.Operation,
 	count int,
 	data unsafe.Pointer,
 	result unsafe.Pointer,
+	resultCount int,
 ) (int, error) {
-	if count == 0 {
-		return 0, errors.ErrEmptyBatch{}
-	}
+	return c.request(op, count, data, result, resultCount, true)
+}
 
-	if c.tb_client == nil {
-		return 0, errors.ErrClientClosed{}
+// request submits the events, waiting for a free request slot if wait is set and the client
+// was created with ConcurrencyBlock.
+func (c *c_client) request(
+	op types.Operation,
+	count int,
+	data unsafe.Pointer,
+	result unsafe.Pointer,
+	resultCount int,
+	wait bool,
+) (int, error) {
+	release, err := c.begin(op, count, wait)
+	if err != nil {
+		return 0, err
 	}
+	defer release()
+
+	return c.submit(op, count, data, result, resultCount)
+}
 
-	req := request{
-		packet: nil,
-		ready:  make(chan struct{}),
+// begin admits a request of count events, taking a request slot if the client was created with
+// ConcurrencyBlock, and returns the function that releases them once the request completes.
+func (c *c_client) begin(op types.Operation, count int, wait bool) (func(), error) {
+	if count == 0 {
+		return nil, errors.ErrEmptyBatch{}
 	}
+	if batchMax := types.MaxBatchSize(op); batchMax > 0 && count > batchMax {
+		return nil, errors.ErrBatchTooLarge{Operation: op, Count: count, Max: batchMax}
+	}
+
+	c.stats.queued.Add(1)
+	defer c.stats.queued.Add(-1)
 
-	switch acquire_status := C.tb_client_acquire_packet(c.tb_client, &req.packet); acquire_status {
-	case C.TB_PACKET_ACQUIRE_CONCURRENCY_MAX_EXCEEDED:
-		return 0, errors.ErrConcurrencyExceeded{}
-	case C.TB_PACKET_ACQUIRE_SHUTDOWN:
-		return 0, errors.ErrClientClosed{}
-	default:
-		if req.packet == nil {
-			panic("tb_client_acquire_packet(): returned null packet")
+	if err := c.admit(); err != nil {
+		return nil, err
+	}
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			c.inflight.Done()
+			return nil, err
+		}
+	}
+	if c.rateLimit != nil {
+		if err := c.throttle(count, wait); err != nil {
+			c.inflight.Done()
+			return nil, err
 		}
 	}
 
-	// Release the packet for other goroutines to use.
-	defer C.tb_client_release_packet(c.tb_client, req.packet)
-
-	req.packet.user_data = unsafe.Pointer(&req)
-	req.packet.operation = C.uint8_t(op)
-	req.packet.status = C.TB_PACKET_OK
-	req.packet.data_size = C.uint32_t(count * int(getEventSize(op)))
-	req.packet.data = data
-
-	// Set where to write the result bytes.
-	req.result = result
-
-	// Submit the request.
-	C.tb_client_submit(c.tb_client, req.packet)
-
-	// Wait for the request to complete.
-	<-req.ready
-	status := C.TB_PACKET_STATUS(req.packet.status)
-	wrote := int(req.packet.data_size)
-
-	// Handle packet error
-	if status != C.TB_PACKET_OK {
-		switch status {
-		case C.TB_PACKET_TOO_MUCH_DATA:
-			return 0, errors.ErrMaximumBatchSizeExceeded{}
-		case C.TB_PACKET_INVALID_OPERATION:
-			// we control what C.TB_OPERATION is given
-			// but allow an invalid opcode to be passed to emulate a client nop
-			return 0, errors.ErrInvalidOperation{}
-		case C.TB_PACKET_INVALID_DATA_SIZE:
-			panic("unreachable") // we control what type of data is given
-		default:
-			panic("tb_client_submit(): returned packet with invalid status")
+	if c.slots != nil {
+		if err := c.acquireSlot(wait); err != nil {
+			c.inflight.Done()
+			return nil, err
 		}
+		return func() {
+			c.releaseSlot()
+			c.inflight.Done()
+		}, nil
 	}
+	return c.inflight.Done, nil
+}
 
-	// Return the amount of bytes written into result
-	return wrote, nil
+// submit submits a request admitted by begin.
+func (c *c_client) submit(
+	op types.Operation,
+	count int,
+	data unsafe.Pointer,
+	result unsafe.Pointer,
+	resultCount int,
+) (int, error) {
+	events := unsafe.Slice((*byte)(data), count*types.EventSize(op))
+	reply := unsafe.Slice((*byte)(result), resultCount*types.ResultSize(op))
+	return c.send(op, events, reply)
 }
 
-//export onGoPacketCompletion
-func onGoPacketCompletion(
-	_context C.uintptr_t,
-	client C.tb_client_t,
-	packet *C.tb_packet_t,
-	result_ptr C.tb_result_bytes_t,
-	result_len C.uint32_t,
-) {
-	// Get the request from the packet user data.
-	req := (*request)(unsafe.Pointer(packet.user_data))
-	if req.packet != packet {
-		panic("invalid packet: request packet mismatch")
+// send submits the encoded events of a request admitted by begin to the transport.
+func (c *c_client) send(op types.Operation, events []byte, reply []byte) (int, error) {
+	c.stats.submitted(events)
+	if c.breaker != nil {
+		defer c.breaker.watch()()
 	}
+	wrote, err := c.transport.Submit(op, events, reply)
+	c.stats.completed(op, wrote, err)
+	return wrote, err
+}
 
-	var wrote C.uint32_t
-	if result_len > 0 && result_ptr != nil {
-		op := C.TB_OPERATION(packet.operation)
-
-		// Make sure the completion handler is giving us valid data.
-		resultSize := C.uint32_t(getResultSize(op))
-		if result_len%resultSize != 0 {
-			panic("invalid result_len:  misaligned for the event")
-		}
-
-		//TODO(batiati): Refine the way we handle events with asymmetric results.
-		if op != C.TB_OPERATION_GET_ACCOUNT_TRANSFERS && op != C.TB_OPERATION_GET_ACCOUNT_HISTORY {
-			// Make sure the amount of results at least matches the amount of requests.
-			count := packet.data_size / C.uint32_t(getEventSize(op))
-			if count*resultSize < result_len {
-				panic("invalid result_len: implied multiple results per event")
-			}
+// sendAsync is send through a transport that completes requests itself, calling done from its
+// completion.
+func (c *c_client) sendAsync(
+	transport asyncTransport,
+	op types.Operation,
+	events []byte,
+	reply []byte,
+	done func(wrote int, err error),
+) {
+	c.stats.submitted(events)
+	var answered func()
+	if c.breaker != nil {
+		answered = c.breaker.watch()
+	}
+	transport.SubmitAsync(op, events, reply, func(wrote int, err error) {
+		if answered != nil {
+			answered()
 		}
+		c.stats.completed(op, wrote, err)
+		done(wrote, err)
+	})
+}
 
-		// Write the result data into the request's result.
-		if req.result != nil {
-			wrote = result_len
-			C.memcpy(req.result, unsafe.Pointer(result_ptr), C.size_t(result_len))
-		}
-	}
+func (c *c_client) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return c.createAccounts(accounts, true)
+}
 
-	// Signal to the goroutine which owns this request that it's ready.
-	req.packet.data_size = wrote
-	req.ready <- struct{}{}
+func (c *c_client) TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	return c.createAccounts(accounts, false)
 }
 
-func (c *c_client) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+func (c *c_client) createAccounts(accounts []types.Account, wait bool) ([]types.AccountEventResult, error) {
+	if err := c.checkAccounts(accounts); err != nil {
+		return nil, err
+	}
+
 	count := len(accounts)
 	results := make([]types.AccountEventResult, count)
-	wrote, err := c.doRequest(
-		C.TB_OPERATION_CREATE_ACCOUNTS,
+	wrote, err := c.request(
+		types.OperationCreateAccounts,
 		count,
 		unsafe.Pointer(&accounts[0]),
 		unsafe.Pointer(&results[0]),
+		len(results),
+		wait,
 	)
 
 	if err != nil {
@@ -290,13 +361,27 @@ func (c *c_client) CreateAccounts(accounts []types.Account) ([]types.AccountEven
 }
 
 func (c *c_client) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return c.createTransfers(transfers, true)
+}
+
+func (c *c_client) TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	return c.createTransfers(transfers, false)
+}
+
+func (c *c_client) createTransfers(transfers []types.Transfer, wait bool) ([]types.TransferEventResult, error) {
+	if err := c.checkTransfers(transfers); err != nil {
+		return nil, err
+	}
+
 	count := len(transfers)
 	results := make([]types.TransferEventResult, count)
-	wrote, err := c.doRequest(
-		C.TB_OPERATION_CREATE_TRANSFERS,
+	wrote, err := c.request(
+		types.OperationCreateTransfers,
 		count,
 		unsafe.Pointer(&transfers[0]),
 		unsafe.Pointer(&results[0]),
+		len(results),
+		wait,
 	)
 
 	if err != nil {
@@ -308,90 +393,130 @@ func (c *c_client) CreateTransfers(transfers []types.Transfer) ([]types.Transfer
 }
 
 func (c *c_client) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
-	count := len(accountIDs)
-	results := make([]types.Account, count)
-	wrote, err := c.doRequest(
-		C.TB_OPERATION_LOOKUP_ACCOUNTS,
-		count,
-		unsafe.Pointer(&accountIDs[0]),
-		unsafe.Pointer(&results[0]),
-	)
+	return c.LookupAccountsInto(accountIDs, make([]types.Account, len(accountIDs)))
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (c *c_client) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	return c.LookupTransfersInto(transferIDs, make([]types.Transfer, len(transferIDs)))
+}
 
-	resultCount := wrote / int(unsafe.Sizeof(types.Account{}))
-	return results[0:resultCount], nil
+func (c *c_client) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	// Queries have asymmetric events and results, so the results are allocated for as many as
+	// fit in a message of types.MessageSizeMax rather than for the number of events.
+	results := make([]types.Transfer, types.MaxBatchSize(types.OperationGetAccountTransfers))
+	return c.GetAccountTransfersInto(filter, results)
 }
 
-func (c *c_client) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
-	count := len(transferIDs)
-	results := make([]types.Transfer, count)
-	wrote, err := c.doRequest(
-		C.TB_OPERATION_LOOKUP_TRANSFERS,
-		count,
-		unsafe.Pointer(&transferIDs[0]),
-		unsafe.Pointer(&results[0]),
-	)
+func (c *c_client) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	// Queries have asymmetric events and results, so the results are allocated for as many as
+	// fit in a message of types.MessageSizeMax rather than for the number of events.
+	results := make([]types.AccountBalance, types.MaxBatchSize(types.OperationGetAccountHistory))
+	return c.GetAccountHistoryInto(filter, results)
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (c *c_client) LookupAccountsInto(
+	accountIDs []types.Uint128,
+	buf []types.Account,
+) ([]types.Account, error) {
+	return requestInto(c, types.OperationLookupAccounts, accountIDs, buf, len(accountIDs))
+}
 
-	resultCount := wrote / int(unsafe.Sizeof(types.Transfer{}))
-	return results[0:resultCount], nil
+func (c *c_client) LookupTransfersInto(
+	transferIDs []types.Uint128,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return requestInto(c, types.OperationLookupTransfers, transferIDs, buf, len(transferIDs))
 }
 
-func (c *c_client) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
-	//TODO(batiati): we need to expose the max message size to the client.
-	//since queries have asymmetric events and results, we can't allocate
-	//the results array based on the number of events.
-	results := make([]types.Transfer, 8190)
+func (c *c_client) GetAccountTransfersInto(
+	filter types.AccountFilter,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	return requestInto(c, types.OperationGetAccountTransfers, []types.AccountFilter{filter}, buf,
+		queryResultsMax(types.OperationGetAccountTransfers, filter))
+}
+
+func (c *c_client) GetAccountHistoryInto(
+	filter types.AccountFilter,
+	buf []types.AccountBalance,
+) ([]types.AccountBalance, error) {
+	return requestInto(c, types.OperationGetAccountHistory, []types.AccountFilter{filter}, buf,
+		queryResultsMax(types.OperationGetAccountHistory, filter))
+}
 
+// requestInto submits events and writes the results into buf, which must have room for
+// resultsMax of them since the reply is copied in whole. Only those resultsMax results of buf are
+// handed to the transport, rather than the whole of its capacity.
+func requestInto[E any, R any](
+	c *c_client,
+	op types.Operation,
+	events []E,
+	buf []R,
+	resultsMax int,
+) ([]R, error) {
+	if cap(buf) < resultsMax {
+		return nil, errors.ErrBufferTooSmall{Capacity: cap(buf), Needed: resultsMax}
+	}
+	buf = buf[:resultsMax]
 	wrote, err := c.doRequest(
-		C.TB_OPERATION_GET_ACCOUNT_TRANSFERS,
-		1,
-		unsafe.Pointer(&filter),
-		unsafe.Pointer(&results[0]),
+		op,
+		len(events),
+		unsafe.Pointer(unsafe.SliceData(events)),
+		unsafe.Pointer(unsafe.SliceData(buf)),
+		len(buf),
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
-	resultCount := wrote / int(unsafe.Sizeof(types.Transfer{}))
-	return results[0:resultCount], nil
+	var result R
+	resultCount := wrote / int(unsafe.Sizeof(result))
+	return buf[0:resultCount], nil
 }
 
-func (c *c_client) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
-	//TODO(batiati): we need to expose the max message size to the client.
-	//since queries have asymmetric events and results, we can't allocate
-	//the results array based on the number of events.
-	results := make([]types.AccountBalance, 8190)
+// queryResultsMax returns the most results that a query with filter may reply with.
+func queryResultsMax(op types.Operation, filter types.AccountFilter) int {
+	return min(int(filter.Limit), types.MaxBatchSize(op))
+}
 
-	wrote, err := c.doRequest(
-		C.TB_OPERATION_GET_ACCOUNT_HISTORY,
-		1,
-		unsafe.Pointer(&filter),
-		unsafe.Pointer(&results[0]),
-	)
+func (c *c_client) CreateAccount(account types.Account) error {
+	return createAccount(c, account)
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (c *c_client) CreateTransfer(transfer types.Transfer) error {
+	return createTransfer(c, transfer)
+}
 
-	resultCount := wrote / int(unsafe.Sizeof(types.AccountBalance{}))
-	return results[0:resultCount], nil
+func (c *c_client) LookupAccount(accountID types.Uint128) (types.Account, bool, error) {
+	return lookupAccount(c, accountID)
+}
+
+func (c *c_client) PostPending(pendingID types.Uint128, amount types.Uint128) error {
+	return createTransfer(c, types.PostPendingTransfer(pendingID, amount))
+}
+
+func (c *c_client) VoidPending(pendingID types.Uint128) error {
+	return createTransfer(c, types.VoidPendingTransfer(pendingID))
+}
+
+// MessageSizeMax returns types.MessageSizeMax, which assumes the default message size of the
+// cluster, as tb_client doesn't expose the one the cluster was configured with.
+func (c *c_client) MessageSizeMax() int {
+	return types.MessageSizeMax
+}
+
+func (c *c_client) Ping(ctx context.Context) (time.Duration, error) {
+	return ping(ctx, c)
 }
 
 func (c *c_client) Nop() error {
 	const dataSize = 256
-	var dummyData [dataSize]C.uint8_t
+	var dummyData [dataSize]byte
 	ptr := unsafe.Pointer(&dummyData)
 
-	reservedOp := C.TB_OPERATION(0)
-	wrote, err := c.doRequest(reservedOp, 1, ptr, ptr)
+	reservedOp := types.Operation(0)
+	wrote, err := c.doRequest(reservedOp, 1, ptr, ptr, 0)
 
 	if !e.Is(err, errors.ErrInvalidOperation{}) {
 		return err
@@ -2,16 +2,26 @@ package tigerbeetle_go
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	e "errors"
 	"fmt"
+	"log/slog"
 	"math/big"
+	"net"
 	"os"
 	"os/exec"
 	"runtime"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 	"unsafe"
 
 	"github.com/tigerbeetle/tigerbeetle-go/assert"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
 	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
 )
 
@@ -767,6 +777,1887 @@ func doTestClient(s *testing.T, client Client) {
 		assert.Len(t, account_history, len(transfers_retrieved))
 	})
 
+	s.Run("can create and lookup a single account", func(t *testing.T) {
+		accountE := types.Account{
+			ID:     HexStringToUint128("e"),
+			Ledger: 1,
+			Code:   1,
+		}
+		if err := client.CreateAccount(accountE); err != nil {
+			t.Fatal(err)
+		}
+
+		err := client.CreateAccount(accountE)
+		assert.Equal(t, errors.ErrCreateAccount{Result: types.AccountExists}, err)
+
+		account, found, err := client.LookupAccount(accountE.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, found)
+		assert.Equal(t, accountE.ID, account.ID)
+
+		_, found, err = client.LookupAccount(HexStringToUint128("ffff"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, !found)
+	})
+
+	s.Run("can create a single transfer", func(t *testing.T) {
+		transfer := types.Transfer{
+			ID:              HexStringToUint128("e"),
+			CreditAccountID: accountA.ID,
+			DebitAccountID:  accountB.ID,
+			Amount:          types.ToUint128(1),
+			Ledger:          1,
+			Code:            1,
+		}
+		if err := client.CreateTransfer(transfer); err != nil {
+			t.Fatal(err)
+		}
+
+		transfer.Amount = types.ToUint128(2)
+		err := client.CreateTransfer(transfer)
+		assert.Equal(t, errors.ErrCreateTransfer{Result: types.TransferExistsWithDifferentAmount}, err)
+	})
+
+	s.Run("can ensure accounts", func(t *testing.T) {
+		accountG := types.Account{ID: HexStringToUint128("e0"), Ledger: 1, Code: 1}
+		accountH := types.Account{ID: accountA.ID, Ledger: 1, Code: 7}
+
+		receipts, err := EnsureAccounts(client, []types.Account{accountG, accountH})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, receipts, 2)
+		assert.Equal(t, AccountCreated, receipts[0].Status)
+		assert.Equal(t, AccountMismatch, receipts[1].Status)
+		assert.Equal(t, []string{"code"}, receipts[1].Drift)
+
+		receipts, err = EnsureAccounts(client, []types.Account{accountG})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, AccountExisted, receipts[0].Status)
+		assert.Len(t, receipts[0].Drift, 0)
+	})
+
+	s.Run("can isolate tenants", func(t *testing.T) {
+		isolation := NewTenantIsolation(client, TenantUserData64)
+		tenant1, err := isolation.Client(ContextWithTenant(context.Background(), types.ToUint128(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tenant2, err := isolation.Client(ContextWithTenant(context.Background(), types.ToUint128(2)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		account1 := types.Account{ID: HexStringToUint128("d1"), UserData64: 1, Ledger: 1, Code: 1}
+		account2 := types.Account{ID: HexStringToUint128("d2"), UserData64: 2, Ledger: 1, Code: 1}
+		_, err = tenant1.CreateAccounts([]types.Account{account2})
+		assert.Equal(t, errors.ErrTenantMismatch{Index: 0}, err)
+		if err := tenant1.CreateAccount(account1); err != nil {
+			t.Fatal(err)
+		}
+		if err := tenant2.CreateAccount(account2); err != nil {
+			t.Fatal(err)
+		}
+
+		_, found, err := tenant2.LookupAccount(account1.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, !found)
+
+		err = tenant1.CreateTransfer(types.Transfer{
+			ID:              HexStringToUint128("d1"),
+			DebitAccountID:  account1.ID,
+			CreditAccountID: account2.ID,
+			Amount:          types.ToUint128(1),
+			UserData64:      1,
+			Ledger:          1,
+			Code:            1,
+		})
+		assert.Equal(t, errors.ErrTenantMismatch{Index: 0}, err)
+
+		_, err = isolation.Client(context.Background())
+		assert.Equal(t, errors.ErrTenantMissing{}, err)
+	})
+
+	s.Run("can post and void pending transfers", func(t *testing.T) {
+		pendingA := types.Transfer{
+			ID:              HexStringToUint128("f0"),
+			CreditAccountID: accountA.ID,
+			DebitAccountID:  accountB.ID,
+			Amount:          types.ToUint128(10),
+			Ledger:          1,
+			Code:            1,
+			Flags:           types.TransferFlags{Pending: true}.ToUint16(),
+		}
+		pendingB := pendingA
+		pendingB.ID = HexStringToUint128("f1")
+		results, err := client.CreateTransfers([]types.Transfer{pendingA, pendingB})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, results)
+
+		if err := client.PostPending(pendingA.ID, types.ToUint128(0)); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.VoidPending(pendingB.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		err = client.VoidPending(pendingA.ID)
+		assert.Equal(t, errors.ErrCreateTransfer{Result: types.TransferPendingTransferAlreadyPosted}, err)
+
+		for id, expected := range map[types.Uint128]types.TransferStatus{
+			pendingA.ID: types.TransferStatusPendingPosted,
+			pendingB.ID: types.TransferStatusPendingVoided,
+		} {
+			status, found, err := LookupTransferState(context.Background(), client, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, found)
+			assert.Equal(t, expected, status)
+		}
+	})
+}
+
+func TestInMemoryTransport(t *testing.T) {
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		assert.Equal(t, types.OperationLookupAccounts, op)
+
+		// Reply with one account per requested ID.
+		ids := unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16)
+		accounts := make([]types.Account, len(ids))
+		for i, id := range ids {
+			accounts[i] = types.Account{ID: id, Ledger: 1, Code: 1}
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&accounts[0])), len(accounts)*128), nil
+	})
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := client.LookupAccounts([]types.Uint128{types.ToUint128(1), types.ToUint128(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, types.ToUint128(2), accounts[1].ID)
+
+	// A reply that doesn't fit the results of the request is an error.
+	id := types.ToUint128(1).Bytes()
+	_, err = transport.Submit(types.OperationLookupAccounts, id[:], make([]byte, 64))
+	assert.Equal(t, errors.ErrReplyTooLarge{Size: 128, Max: 64}, err)
+
+	client.Close()
+	_, err = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, errors.ErrClientClosed{}, err)
+}
+
+func TestBatchTooLarge(t *testing.T) {
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		t.Fatal("oversized batch must not be submitted")
+		return nil, nil
+	})
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	batchMax := types.MaxBatchSize(types.OperationCreateTransfers)
+	_, err = client.CreateTransfers(make([]types.Transfer, batchMax+1))
+	assert.Equal(t, errors.ErrBatchTooLarge{
+		Operation: types.OperationCreateTransfers,
+		Count:     batchMax + 1,
+		Max:       batchMax,
+	}, err)
+	assert.True(t, e.Is(err, errors.ErrMaximumBatchSizeExceeded{}))
+	assert.Equal(t, types.MessageSizeMax, client.MessageSizeMax())
+}
+
+func TestCreateTransfersSplit(t *testing.T) {
+	var batches []int
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		batches = append(batches, len(events)/types.TransferSize)
+
+		// Fail the first transfer of every batch.
+		results := []types.TransferEventResult{{Index: 0, Result: types.TransferExists}}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&results[0])), types.TransferEventResultSize), nil
+	})
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// A linked chain straddling the end of the first batch moves to the second one whole.
+	batchMax := types.MaxBatchSize(types.OperationCreateTransfers)
+	transfers := make([]types.Transfer, batchMax+10)
+	linked := types.TransferFlags{Linked: true}.ToUint16()
+	for i := batchMax - 5; i < batchMax+5; i++ {
+		transfers[i].Flags = linked
+	}
+
+	results, err := CreateTransfersSplit(client, transfers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []int{batchMax - 5, 15}, batches)
+	assert.Len(t, results, 2)
+	assert.Equal(t, uint32(0), results[0].Index)
+	assert.Equal(t, uint32(batchMax-5), results[1].Index)
+
+	// A chain that doesn't fit in any request is rejected before submitting.
+	for i := range transfers {
+		transfers[i].Flags = linked
+	}
+	_, err = CreateTransfersSplit(client, transfers)
+	assert.True(t, e.Is(err, errors.ErrMaximumBatchSizeExceeded{}))
+	assert.Len(t, batches, 2)
+}
+
+func TestWithLogger(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	failed := false
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if !failed {
+			failed = true
+			return nil, errors.ErrConcurrencyExceeded{}
+		}
+		return nil, nil
+	})
+
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	client, err := NewClient(
+		types.ToUint128(0),
+		nil,
+		1,
+		WithTransport(transport),
+		WithRetryPolicy(policy),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	err = client.CreateAccount(types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1})
+	assert.Equal(t, nil, err)
+	assert.True(t, strings.Contains(logs.String(), "level=WARN msg=\"request failed\" operation=CreateAccounts"))
+
+	nativeLogger.Store(logger)
+	defer nativeLogger.Store(nil)
+	logNative(nativeLogErr, "(vsr) evicted")
+	assert.True(t, strings.Contains(logs.String(), "level=ERROR msg=\"(vsr) evicted\" source=tb_client"))
+}
+
+func TestLatencyMonitor(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if op == types.OperationLookupAccounts {
+			time.Sleep(5 * time.Millisecond)
+		}
+		return nil, nil
+	})
+	monitor := NewLatencyMonitor(time.Millisecond)
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(transport),
+		WithLatencyMonitor(monitor),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	err = client.CreateAccount(types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1})
+	assert.Equal(t, nil, err)
+	_, err = client.LookupAccounts([]types.Uint128{types.ToUint128(1), types.ToUint128(2)})
+	assert.Equal(t, nil, err)
+
+	histogram := monitor.Histogram(types.OperationLookupAccounts)
+	assert.Equal(t, uint64(1), histogram.Count)
+	assert.True(t, histogram.Max >= 5*time.Millisecond)
+	assert.Equal(t, histogram.Max, histogram.Mean())
+	assert.Equal(t, uint64(1), histogram.Buckets[latencyBucket(histogram.Max)])
+	assert.Equal(t, histogram.Max, histogram.Quantile(0.99))
+	assert.Equal(t, uint64(1), monitor.Histogram(types.OperationCreateAccounts).Count)
+	assert.True(t, strings.Contains(logs.String(),
+		"level=WARN msg=\"slow request\" operation=LookupAccounts"))
+	assert.True(t, strings.Contains(logs.String(), "batch_size=2 results=0"))
+	assert.True(t, !strings.Contains(logs.String(), "operation=CreateAccounts"))
+
+	// Neither measured nor logged while disabled.
+	logs.Reset()
+	monitor.SetEnabled(false)
+	_, err = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(1), monitor.Histogram(types.OperationLookupAccounts).Count)
+	assert.Equal(t, 0, logs.Len())
+
+	monitor.SetEnabled(true)
+	monitor.SetSlowThreshold(0)
+	_, err = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(2), monitor.Histogram(types.OperationLookupAccounts).Count)
+	assert.Equal(t, 0, logs.Len())
+
+	monitor.Reset()
+	assert.Equal(t, LatencyHistogram{Operation: types.OperationLookupAccounts},
+		monitor.Histogram(types.OperationLookupAccounts))
+	assert.Equal(t, 2*time.Microsecond, LatencyBucketBound(1))
+}
+
+func TestRecordReplay(t *testing.T) {
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if op == types.OperationCreateTransfers {
+			return nil, errors.ErrConcurrencyExceeded{}
+		}
+		ids := unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16)
+		accounts := []types.Account{{ID: ids[0], Ledger: 1, Code: 1}}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&accounts[0])), 128), nil
+	})
+
+	var recording bytes.Buffer
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport), WithRecording(&recording))
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, found, err := client.LookupAccount(types.ToUint128(7))
+	assert.True(t, found && err == nil)
+	err = client.CreateTransfer(types.Transfer{ID: types.ToUint128(1)})
+	assert.Equal(t, errors.ErrConcurrencyExceeded{}, err)
+	client.Close()
+
+	replay, err := NewReplayTransport(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err = NewClient(types.ToUint128(0), nil, 1, WithTransport(replay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	err = client.CreateTransfer(types.Transfer{ID: types.ToUint128(1)})
+	assert.Equal(t, errors.ErrConcurrencyExceeded{}, err)
+	replayed, found, err := client.LookupAccount(types.ToUint128(7))
+	assert.True(t, found && err == nil)
+	assert.Equal(t, account, replayed)
+
+	// Every recorded request is replayed once.
+	_, _, err = client.LookupAccount(types.ToUint128(7))
+	assert.Equal(t, errors.ErrReplayMismatch{Operation: types.OperationLookupAccounts}, err)
+}
+
+func TestPreflight(t *testing.T) {
+	var submitted int
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		submitted++
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport), WithPreflight())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	_, err = client.CreateAccounts([]types.Account{
+		{ID: types.ToUint128(1), Ledger: 1, Code: 1},
+		{ID: types.ToUint128(2), Code: 1},
+	})
+	assert.Equal(t, errors.ErrInvalidAccount{Index: 1, Result: types.AccountLedgerMustNotBeZero}, err)
+
+	_, err = client.CreateTransfers([]types.Transfer{{
+		ID:              types.ToUint128(3),
+		DebitAccountID:  types.ToUint128(1),
+		CreditAccountID: types.ToUint128(1),
+		Amount:          types.ToUint128(10),
+		Ledger:          1,
+		Code:            1,
+	}})
+	assert.Equal(t, errors.ErrInvalidTransfer{Index: 0, Result: types.TransferAccountsMustBeDifferent}, err)
+	assert.Equal(t, 0, submitted)
+
+	_, err = client.CreateAccounts([]types.Account{{ID: types.ToUint128(1), Ledger: 1, Code: 1}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, submitted)
+}
+
+func TestPause(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transfer := types.Transfer{ID: types.ToUint128(1)}
+	inflight := make(chan error)
+	go func() { inflight <- client.CreateTransfer(transfer) }()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The in-flight request keeps the pause from completing.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, client.Pause(ctx, PauseReject))
+	assert.Equal(t, errors.ErrMaintenance{}, client.Nop())
+
+	close(release)
+	assert.Equal(t, nil, <-inflight)
+	assert.Equal(t, nil, client.Pause(context.Background(), PauseQueue))
+
+	queued := make(chan error)
+	go func() { queued <- client.CreateTransfer(transfer) }()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+
+	client.Resume()
+	assert.Equal(t, nil, <-queued)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Closing fails the queued requests.
+	assert.Equal(t, nil, client.Pause(context.Background(), PauseQueue))
+	go func() { queued <- client.CreateTransfer(transfer) }()
+	time.Sleep(10 * time.Millisecond)
+	client.Close()
+	assert.Equal(t, errors.ErrClientClosed{}, <-queued)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestUpdateAddresses(t *testing.T) {
+	normalized, err := normalizeAddresses([]string{" 3001", "127.0.0.1:3002", "3001", "3003 "})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"3001", "127.0.0.1:3002", "3003"}, normalized)
+
+	for _, invalid := range [][]string{nil, {""}, {"3001", " "}, {"3001,3002"}} {
+		_, err := normalizeAddresses(invalid)
+		assert.Equal(t, errors.ErrInvalidAddress{}, err)
+	}
+
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	assert.Equal(t, errors.ErrUpdateAddressesUnsupported{}, client.UpdateAddresses([]string{"3001"}))
+}
+
+type testResolver struct {
+	hosts map[string][]string
+	srv   []*net.SRV
+}
+
+func (r testResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if ips, ok := r.hosts[host]; ok {
+		return ips, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r testResolver) LookupSRV(
+	ctx context.Context,
+	service, proto, name string,
+) (string, []*net.SRV, error) {
+	return name, slices.Clone(r.srv), nil
+}
+
+func TestDNSDiscovery(t *testing.T) {
+	resolver := testResolver{
+		hosts: map[string][]string{
+			"tb-0.tb": {"10.0.0.1"},
+			"tb-1.tb": {"10.0.0.2", "10.0.0.3"},
+			"tb-2.tb": {"10.0.0.4"},
+		},
+		srv: []*net.SRV{
+			{Target: "tb-2.tb.", Port: 3002, Priority: 0},
+			{Target: "tb-0.tb.", Port: 3000, Priority: 0},
+			{Target: "tb-1.tb.", Port: 3001, Priority: 0},
+		},
+	}
+	discovery := DNSDiscovery{Resolver: resolver}.withDefaults()
+	assert.Equal(t, dnsDiscoveryIntervalDefault, discovery.Interval)
+
+	// IP addresses and ports alone are kept as is, and host names take their first IP address.
+	resolved, err := discovery.resolve(context.Background(),
+		[]string{"3000", "127.0.0.1:3001", "tb-1.tb:3002", "tb-2.tb"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"3000", "127.0.0.1:3001", "10.0.0.2:3002", "10.0.0.4"}, resolved)
+
+	_, err = discovery.resolve(context.Background(), []string{"tb-3.tb:3003"})
+	var resolution errors.ErrAddressResolution
+	assert.True(t, e.As(err, &resolution))
+	assert.Equal(t, "tb-3.tb:3003", resolution.Address)
+
+	// SRV targets are taken in order of name, and replace the addresses.
+	discovery.SRV = "_tigerbeetle._tcp.tb"
+	resolved, err = discovery.resolve(context.Background(), []string{"3000"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"10.0.0.1:3000", "10.0.0.2:3001", "10.0.0.4:3002"}, resolved)
+
+	// The client switches to the new addresses whenever they change.
+	discovery.Interval = time.Millisecond
+	changes := make(chan []string, 1)
+	discovery.OnChange = func(addresses []string, err error) {
+		assert.Equal(t, nil, err)
+		changes <- addresses
+	}
+	updates := make(chan []string, 1)
+	done := make(chan struct{})
+	go discovery.watch(nil, []string{"10.0.0.1:3000", "10.0.0.9:3001", "10.0.0.4:3002"},
+		func(addresses []string) error {
+			updates <- addresses
+			return nil
+		}, done)
+	assert.Equal(t, resolved, <-updates)
+	assert.Equal(t, resolved, <-changes)
+	close(done)
+}
+
+func TestTransferBatcher(t *testing.T) {
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	var batches [][]uint64
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		transfers := unsafe.Slice((*types.Transfer)(unsafe.Pointer(&events[0])), len(events)/128)
+		var ids []uint64
+		var results []types.TransferEventResult
+		for i, transfer := range transfers {
+			id := transfer.ID.BigInt()
+			ids = append(ids, id.Uint64())
+			if transfer.Code == 0 {
+				results = append(results, types.TransferEventResult{
+					Index:  uint32(i),
+					Result: types.TransferCodeMustNotBeZero,
+				})
+			}
+		}
+
+		mutex.Lock()
+		batches = append(batches, ids)
+		first := len(batches) == 1
+		mutex.Unlock()
+		if first {
+			<-release
+		}
+
+		if len(results) == 0 {
+			return nil, nil
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&results[0])), len(results)*8), nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	batcher := NewTransferBatcher(client, BatcherOptions{Delay: 5 * time.Millisecond, BatchSizeMax: 2})
+	submit := func(ctx context.Context, id uint64, code uint16) chan error {
+		result := make(chan error, 1)
+		go func() {
+			result <- batcher.Submit(ctx, types.Transfer{ID: types.ToUint128(id), Code: code})
+		}()
+		return result
+	}
+
+	// The first batch blocks the flusher while the others queue up behind it.
+	first := submit(context.Background(), 1, 1)
+	for {
+		mutex.Lock()
+		started := len(batches) == 1
+		mutex.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	urgent, cancelUrgent := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelUrgent()
+	expiring, cancelExpiring := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelExpiring()
+
+	relaxed := submit(context.Background(), 2, 0)
+	time.Sleep(time.Millisecond)
+	soon := submit(urgent, 3, 1)
+	expired := submit(expiring, 4, 1)
+	time.Sleep(30 * time.Millisecond)
+	close(release)
+
+	assert.Equal(t, nil, <-first)
+	assert.Equal(t, context.DeadlineExceeded, <-expired)
+	assert.Equal(t, nil, <-soon)
+	assert.Equal(t, errors.ErrCreateTransfer{Result: types.TransferCodeMustNotBeZero}, <-relaxed)
+	batcher.Close()
+
+	// The transfer with a deadline went ahead of the one without, and the expired one was
+	// never submitted.
+	assert.Equal(t, [][]uint64{{1}, {3, 2}}, batches)
+
+	err = batcher.Submit(context.Background(), types.Transfer{ID: types.ToUint128(5)})
+	assert.Equal(t, errors.ErrClientClosed{}, err)
+	err = NewTransferBatcher(client, BatcherOptions{}).Submit(context.Background(), types.Transfer{
+		ID:    types.ToUint128(6),
+		Flags: types.TransferFlags{Linked: true}.ToUint16(),
+	})
+	assert.Equal(t, errors.ErrCreateTransfer{Result: types.TransferLinkedEventChainOpen}, err)
+}
+
+func TestConcurrencyMode(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		calls.Add(1)
+		if events[0] == 1 {
+			<-release
+		}
+		return nil, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(transport),
+		WithConcurrencyMode(ConcurrencyBlock),
+		WithConcurrencyContext(ctx),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	transfers := []types.Transfer{{ID: types.ToUint128(1)}}
+	first := make(chan error)
+	go func() {
+		_, err := client.CreateTransfers(transfers)
+		first <- err
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The only slot is taken, so TryCreateTransfers fails fast while CreateTransfers waits.
+	_, err = client.TryCreateTransfers(transfers)
+	assert.Equal(t, errors.ErrConcurrencyExceeded{}, err)
+
+	second := make(chan error)
+	go func() {
+		_, err := client.CreateTransfers(transfers)
+		second <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+
+	release <- struct{}{}
+	assert.Equal(t, nil, <-first)
+	for calls.Load() == 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Once the concurrency context is done, waiting for a slot fails.
+	cancel()
+	_, err = client.CreateTransfers(transfers)
+	assert.Equal(t, context.Canceled, err)
+
+	close(release)
+	assert.Equal(t, nil, <-second)
+
+	// A transport of its own with a concurrencyMax of zero does not limit requests.
+	unlimited, err := NewClient(types.ToUint128(0), nil, 0,
+		WithTransport(NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+			return nil, nil
+		})),
+		WithConcurrencyMode(ConcurrencyBlock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlimited.Close()
+
+	_, err = unlimited.CreateTransfers(transfers)
+	assert.Equal(t, nil, err)
+	_, err = unlimited.TryCreateTransfers(transfers)
+	assert.Equal(t, nil, err)
+}
+
+func TestHedging(t *testing.T) {
+	// Both transports reply with the account, on ledger 1 for the primary, which stalls until
+	// released, and on ledger 2 for the hedge.
+	answer := func(ledger uint32, op types.Operation, events []byte) []byte {
+		if op != types.OperationLookupAccounts {
+			return nil
+		}
+		account := types.Account{ID: *(*types.Uint128)(unsafe.Pointer(&events[0])), Ledger: ledger}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&account)), 128)
+	}
+	var stalled atomic.Bool
+	stalled.Store(true)
+	stall := make(chan struct{})
+	primary := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if stalled.Load() {
+			<-stall
+		}
+		return answer(1, op, events), nil
+	})
+	hedge := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		return answer(2, op, events), nil
+	})
+
+	stats := &HedgeStats{}
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(primary),
+		WithHedging(HedgePolicy{Delay: time.Millisecond, Transport: hedge, OnResult: stats.Record}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// A stalled lookup takes the reply of the hedge.
+	account, ok, err := client.LookupAccount(types.ToUint128(7))
+	if err != nil || !ok {
+		t.Fatalf("Expected the account, got %v and %v", ok, err)
+	}
+	assert.Equal(t, types.ToUint128(7), account.ID)
+	assert.Equal(t, uint32(2), account.Ledger)
+	assert.Equal(t, uint64(1), stats.Hedged())
+	assert.Equal(t, uint64(1), stats.Won())
+	close(stall)
+
+	// A prompt lookup is not hedged, and creating is never hedged.
+	stalled.Store(false)
+	account, _, err = client.LookupAccount(types.ToUint128(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint32(1), account.Ledger)
+	assert.Equal(t, uint64(2), stats.Lookups())
+	assert.Equal(t, 0.5, stats.HedgeRate())
+
+	if _, err := client.CreateAccounts([]types.Account{{ID: types.ToUint128(9)}}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(2), stats.Lookups())
+
+	// A buffer larger than a message is not copied into the pooled reply buffers.
+	accounts, err := client.LookupAccountsInto(
+		[]types.Uint128{types.ToUint128(10)},
+		make([]types.Account, 0, 10000),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, accounts, 1)
+	assert.Equal(t, types.ToUint128(10), accounts[0].ID)
+
+	// Nor is a reply longer than a message, handed to the transport by a caller of its own.
+	id := types.ToUint128(11)
+	reply := make([]byte, 10000*128)
+	size, err := newHedgeTransport(primary, hedge, HedgePolicy{Delay: time.Millisecond}).Submit(
+		types.OperationLookupAccounts,
+		unsafe.Slice((*byte)(unsafe.Pointer(&id)), 16),
+		reply,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 128, size)
+	assert.Equal(t, id, (*types.Account)(unsafe.Pointer(&reply[0])).ID)
+}
+
+func TestConnectionState(t *testing.T) {
+	stall := make(chan struct{})
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if op == types.OperationLookupAccounts {
+			<-stall
+		}
+		return nil, nil
+	})
+
+	changes := make(chan ConnectionStateChange, 16)
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(transport),
+		WithConnectionMonitor(ConnectionMonitor{
+			ReconnectingAfter: 10 * time.Millisecond,
+			UnreachableAfter:  50 * time.Millisecond,
+			OnStateChange:     func(change ConnectionStateChange) { changes <- change },
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := func(from ConnectionState, to ConnectionState) {
+		t.Helper()
+		change := <-changes
+		assert.Equal(t, from, change.From)
+		assert.Equal(t, to, change.To)
+	}
+
+	assert.Equal(t, ConnectionConnecting, client.State())
+	if err := client.Nop(); err != nil {
+		t.Fatal(err)
+	}
+	expect(ConnectionConnecting, ConnectionConnected)
+	assert.Equal(t, ConnectionConnected, client.State())
+
+	// A request without a reply makes the client reconnecting, then the cluster unreachable,
+	// until the reply arrives.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	}()
+	expect(ConnectionConnected, ConnectionReconnecting)
+	expect(ConnectionReconnecting, ConnectionUnreachable)
+	assert.Equal(t, "cluster-unreachable", client.State().String())
+	close(stall)
+	<-done
+	expect(ConnectionUnreachable, ConnectionConnected)
+
+	client.Close()
+	expect(ConnectionConnected, ConnectionClosed)
+	assert.Equal(t, 0, len(changes))
+}
+
+func TestSessionEviction(t *testing.T) {
+	changes := make(chan ConnectionStateChange, 16)
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+			return nil, nil
+		})),
+		WithConnectionMonitor(ConnectionMonitor{
+			OnStateChange: func(change ConnectionStateChange) { changes <- change },
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.Nop(); err != nil {
+		t.Fatal(err)
+	}
+	<-changes
+
+	// Clients with a transport don't hear of tb_client's evictions, so register this one.
+	connection := client.(*c_client).connection
+	nativeConnections.Store(connection, struct{}{})
+	defer nativeConnections.Delete(connection)
+
+	noteNativeLog(nativeLogWarn, "42: on_eviction: ignoring (wrong client=43)")
+	noteNativeLog(nativeLogErr, "42: session evicted: reason=release_too_low (cluster_release=0.16.1)")
+	evicted := errors.ErrSessionEvicted{Reason: "release_too_low, cluster release 0.16.1"}
+	change := <-changes
+	assert.Equal(t, ConnectionConnected, change.From)
+	assert.Equal(t, ConnectionEvicted, change.To)
+	assert.Equal(t, evicted, change.Err)
+	assert.Equal(t, ConnectionEvicted, client.State())
+
+	_, err = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, evicted, err)
+	assert.Equal(t, "Session evicted: release_too_low, cluster release 0.16.1.", err.Error())
+	assert.Equal(t, 0, len(changes))
+}
+
+func TestCreateTransfersAsync(t *testing.T) {
+	// The transport rejects every transfer with an odd ID, once released.
+	release := make(chan struct{})
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		<-release
+		var results []types.TransferEventResult
+		transfers := unsafe.Slice((*types.Transfer)(unsafe.Pointer(&events[0])), len(events)/128)
+		for i, transfer := range transfers {
+			if transfer.ID.Bytes()[0]%2 == 1 {
+				results = append(results, types.TransferEventResult{
+					Index:  uint32(i),
+					Result: types.TransferExceedsCredits,
+				})
+			}
+		}
+		if len(results) == 0 {
+			return nil, nil
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&results[0])), len(results)*8), nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 4, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var futures []*Future[[]types.TransferEventResult]
+	for i := 1; i <= 4; i++ {
+		future, err := client.CreateTransfersAsync([]types.Transfer{{ID: types.ToUint128(uint64(i))}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, future)
+	}
+	failed := Then(futures[0], func(results []types.TransferEventResult) (int, error) {
+		return len(results), nil
+	})
+	select {
+	case <-futures[0].Done():
+		t.Fatal("future done before the reply")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = futures[0].Wait(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	close(release)
+	for i, future := range futures {
+		results, err := future.Wait(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, (i+1)%2, len(results))
+	}
+	count, err := failed.Wait(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, count)
+
+	_, err = client.CreateTransfersAsync(nil)
+	assert.Equal(t, errors.ErrEmptyBatch{}, err)
+
+	// A view with a timeout fails the future, and chained futures with it.
+	stalled := make(chan struct{})
+	stalling, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+			<-stalled
+			return nil, nil
+		})),
+		WithDefaultRequestTimeout(5*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stalling.Close()
+	defer close(stalled)
+	future, err := stalling.CreateAccountsAsync([]types.Account{{ID: types.ToUint128(1)}})
+	assert.Equal(t, nil, err)
+	_, err = Then(future, func(results []types.AccountEventResult) (int, error) {
+		return len(results), nil
+	}).Wait(context.Background())
+	assert.Equal(t, errors.ErrRequestTimeout{
+		Operation: types.OperationCreateAccounts,
+		Timeout:   5 * time.Millisecond,
+	}, err)
+
+	resolved := ResolvedFuture[[]types.TransferEventResult](nil, errors.ErrClientClosed{})
+	<-resolved.Done()
+	_, err = resolved.Wait(context.Background())
+	assert.Equal(t, errors.ErrClientClosed{}, err)
+}
+
+// completingTransport completes requests itself, as tb_client does, once complete is called.
+type completingTransport struct {
+	Transport
+	mutex   sync.Mutex
+	pending []func()
+}
+
+func (t *completingTransport) SubmitAsync(
+	op types.Operation,
+	events []byte,
+	reply []byte,
+	done func(wrote int, err error),
+) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.pending = append(t.pending, func() {
+		done(t.Transport.Submit(op, events, reply))
+	})
+}
+
+func (t *completingTransport) complete() {
+	t.mutex.Lock()
+	pending := t.pending
+	t.pending = nil
+	t.mutex.Unlock()
+	for _, complete := range pending {
+		complete()
+	}
+}
+
+func TestCreateTransfersAsyncCompletion(t *testing.T) {
+	transport := &completingTransport{
+		Transport: NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+			result := types.TransferEventResult{Result: types.TransferExists}
+			return unsafe.Slice((*byte)(unsafe.Pointer(&result)), 8), nil
+		}),
+	}
+	client, err := NewClient(types.ToUint128(0), nil, 1024, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Requests in flight hold no goroutine, and are completed by the transport.
+	goroutines := runtime.NumGoroutine()
+	var futures []*Future[[]types.TransferEventResult]
+	for i := range 1000 {
+		future, err := client.CreateTransfersAsync([]types.Transfer{{ID: types.ToUint128(uint64(i + 1))}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, future)
+	}
+	assert.True(t, runtime.NumGoroutine() < goroutines+10)
+	select {
+	case <-futures[0].Done():
+		t.Fatal("future done before the reply")
+	default:
+	}
+
+	transport.complete()
+	for _, future := range futures {
+		results, err := future.Wait(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []types.TransferEventResult{{Result: types.TransferExists}}, results)
+	}
+	assert.Equal(t, 0, client.Stats().InFlight)
+}
+
+// replyTransport records the length of the reply buffer of every request.
+type replyTransport struct {
+	Transport
+	replies []int
+}
+
+func (t *replyTransport) Submit(op types.Operation, events []byte, reply []byte) (int, error) {
+	t.replies = append(t.replies, len(reply))
+	return t.Transport.Submit(op, events, reply)
+}
+
+func TestLookupInto(t *testing.T) {
+	// The transport finds the accounts with odd IDs, and two transfers for any filter.
+	transport := &replyTransport{Transport: NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		var reply []byte
+		switch op {
+		case types.OperationLookupAccounts:
+			for _, id := range unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16) {
+				if id.Bytes()[0]%2 == 1 {
+					account := types.Account{ID: id, Ledger: 1}
+					reply = append(reply, unsafe.Slice((*byte)(unsafe.Pointer(&account)), 128)...)
+				}
+			}
+		case types.OperationGetAccountTransfers:
+			transfers := []types.Transfer{{ID: types.ToUint128(1)}, {ID: types.ToUint128(2)}}
+			reply = unsafe.Slice((*byte)(unsafe.Pointer(&transfers[0])), 2*128)
+		}
+		return reply, nil
+	})}
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	buf := make([]types.Account, 0, 4)
+	ids := []types.Uint128{types.ToUint128(1), types.ToUint128(2), types.ToUint128(3)}
+	accounts, err := client.LookupAccountsInto(ids, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(accounts))
+	assert.Equal(t, types.ToUint128(3), accounts[1].ID)
+	assert.True(t, &accounts[0] == &buf[:1][0])
+
+	_, err = client.LookupAccountsInto(ids, make([]types.Account, 2))
+	assert.Equal(t, errors.ErrBufferTooSmall{Capacity: 2, Needed: 3}, err)
+
+	transfers := make([]types.Transfer, 10)
+	filter := types.AccountFilter{AccountID: types.ToUint128(1), Limit: 10}
+	page, err := client.GetAccountTransfersInto(filter, transfers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(page))
+	assert.True(t, &page[0] == &transfers[0])
+
+	// The transport is handed room for the results of the request alone, not the whole buffer.
+	filter.Limit = 2
+	_, err = client.GetAccountTransfersInto(filter, transfers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []int{3 * 128, 10 * 128, 2 * 128}, transport.replies)
+
+	filter.Limit = 11
+	_, err = client.GetAccountTransfersInto(filter, transfers)
+	assert.Equal(t, errors.ErrBufferTooSmall{Capacity: 10, Needed: 11}, err)
+
+	_, err = client.LookupAccountsInto(nil, buf)
+	assert.Equal(t, errors.ErrEmptyBatch{}, err)
+}
+
+func TestPing(t *testing.T) {
+	var stalled atomic.Bool
+	stall := make(chan struct{})
+	var lookups atomic.Int32
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		assert.Equal(t, types.OperationLookupAccounts, op)
+		lookups.Add(1)
+		if stalled.Load() {
+			<-stall
+		}
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	latency, err := client.Ping(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, latency > 0)
+	assert.Equal(t, int32(1), lookups.Load())
+	assert.Equal(t, ConnectionConnected, client.State())
+
+	// An unreachable cluster fails the ping once ctx is done.
+	stalled.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.Ping(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	close(stall)
+}
+
+func TestLookupTransferState(t *testing.T) {
+	now := uint64(time.Now().UnixNano())
+	pending := types.Transfer{
+		ID:             types.ToUint128(1),
+		DebitAccountID: types.ToUint128(1),
+		Flags:          types.TransferFlags{Pending: true}.ToUint16(),
+		Timeout:        3600,
+		Timestamp:      now,
+	}
+	unbounded := pending
+	unbounded.ID = types.ToUint128(2)
+	unbounded.Timeout = 0
+	debits := []types.Transfer{
+		{ID: types.ToUint128(3), DebitAccountID: types.ToUint128(1), Timestamp: now + 1},
+		{ID: types.ToUint128(4), DebitAccountID: types.ToUint128(1), Timestamp: pending.ExpiresAt()},
+	}
+
+	var filters []types.AccountFilter
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		var results []types.Transfer
+		switch op {
+		case types.OperationLookupTransfers:
+			for _, id := range unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16) {
+				for _, transfer := range []types.Transfer{pending, unbounded} {
+					if transfer.ID == id {
+						results = append(results, transfer)
+					}
+				}
+			}
+		case types.OperationGetAccountTransfers:
+			filter := *(*types.AccountFilter)(unsafe.Pointer(&events[0]))
+			filters = append(filters, filter)
+			for _, debit := range debits {
+				if debit.Timestamp >= filter.TimestampMin &&
+					(filter.TimestampMax == 0 || debit.Timestamp <= filter.TimestampMax) &&
+					len(results) < int(filter.Limit) {
+					results = append(results, debit)
+				}
+			}
+		}
+		if len(results) == 0 {
+			return nil, nil
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&results[0])), len(results)*128), nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// The search stops at the timeout, and a later debit tells that the transfer expired.
+	status, found, err := LookupTransferState(context.Background(), client, pending.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, found)
+	assert.Equal(t, types.TransferStatusPendingExpired, status)
+	assert.Len(t, filters, 2)
+	assert.Equal(t, pending.ExpiresAt()-1, filters[0].TimestampMax)
+	assert.Equal(t, pending.ExpiresAt(), filters[1].TimestampMin)
+
+	// Without a timeout, the search is bounded by ctx.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = LookupTransferState(ctx, client, unbounded.ID)
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, filters, 2)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	var stalled atomic.Bool
+	stall := make(chan struct{})
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if stalled.Load() {
+			<-stall
+		}
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 2,
+		WithTransport(transport),
+		WithDefaultRequestTimeout(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err := client.CreateTransfers([]types.Transfer{{ID: types.ToUint128(1)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	stalled.Store(true)
+	err = client.CreateTransfer(types.Transfer{ID: types.ToUint128(2)})
+	assert.Equal(t, errors.ErrRequestTimeout{
+		Operation: types.OperationCreateTransfers,
+		Timeout:   10 * time.Millisecond,
+	}, err)
+
+	// The override applies to the calls through the view.
+	buf := make([]types.Account, 0, 1)
+	_, err = RequestTimeout(client, time.Millisecond).LookupAccountsInto([]types.Uint128{types.ToUint128(1)}, buf)
+	assert.Equal(t, errors.ErrRequestTimeout{Operation: types.OperationLookupAccounts, Timeout: time.Millisecond}, err)
+
+	replied := make(chan error, 1)
+	go func() {
+		_, err := RequestTimeout(client, 0).LookupAccounts([]types.Uint128{types.ToUint128(1)})
+		replied <- err
+	}()
+	close(stall)
+	assert.Equal(t, nil, <-replied)
+}
+
+func TestRouter(t *testing.T) {
+	// Each cluster stores the accounts created on it, and rejects those with an even ID as
+	// existing already.
+	newCluster := func() (Client, *[]types.Account) {
+		var stored []types.Account
+		transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+			var reply []byte
+			switch op {
+			case types.OperationCreateAccounts:
+				for i, account := range unsafe.Slice((*types.Account)(unsafe.Pointer(&events[0])), len(events)/128) {
+					stored = append(stored, account)
+					if account.ID.Bytes()[0]%2 == 0 {
+						result := types.AccountEventResult{Index: uint32(i), Result: types.AccountExists}
+						reply = append(reply, unsafe.Slice((*byte)(unsafe.Pointer(&result)), 8)...)
+					}
+				}
+			case types.OperationLookupAccounts:
+				for _, id := range unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16) {
+					for _, account := range stored {
+						if account.ID == id {
+							reply = append(reply, unsafe.Slice((*byte)(unsafe.Pointer(&account)), 128)...)
+						}
+					}
+				}
+			}
+			return reply, nil
+		})
+		client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client, &stored
+	}
+	first, firstStored := newCluster()
+	second, secondStored := newCluster()
+	// Ledgers map to clusters, except for the accounts from 100, which are all on the second.
+	router := NewRouter([]Client{first, second}, func(ledger uint32, accountID types.Uint128) int {
+		if accountID.Bytes()[0] >= 100 {
+			return 1
+		}
+		return int(ledger) - 1
+	})
+	defer router.Close()
+
+	account := func(id uint64, ledger uint32) types.Account {
+		return types.Account{ID: types.ToUint128(id), Ledger: ledger}
+	}
+	results, err := router.CreateAccounts([]types.Account{
+		account(1, 1), account(2, 2), account(3, 2), account(4, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []types.AccountEventResult{
+		{Index: 1, Result: types.AccountExists},
+		{Index: 3, Result: types.AccountExists},
+	}, results)
+	assert.Equal(t, []types.Account{account(1, 1), account(4, 1)}, *firstStored)
+	assert.Equal(t, []types.Account{account(2, 2), account(3, 2)}, *secondStored)
+
+	accounts, err := router.LookupAccounts([]types.Uint128{
+		types.ToUint128(3), types.ToUint128(5), types.ToUint128(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []types.Account{account(3, 2), account(1, 1)}, accounts)
+
+	// Nothing is submitted for batches that can't be routed.
+	linked := account(5, 1)
+	linked.Flags = types.AccountFlags{Linked: true}.ToUint16()
+	_, err = router.CreateAccounts([]types.Account{linked, account(6, 2)})
+	assert.Equal(t, errors.ErrCrossCluster{Index: 1}, err)
+	_, err = router.CreateTransfers([]types.Transfer{{
+		Ledger:          1,
+		DebitAccountID:  types.ToUint128(1),
+		CreditAccountID: types.ToUint128(100),
+	}})
+	assert.Equal(t, errors.ErrCrossCluster{Index: 0}, err)
+	_, err = router.CreateAccounts([]types.Account{account(7, 3)})
+	assert.Equal(t, errors.ErrInvalidRoute{Index: 0, Cluster: 2}, err)
+	assert.Equal(t, 2, len(*firstStored))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	failures := 2
+	var failure error = errors.ErrConcurrencyExceeded{}
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if failures > 0 {
+			failures--
+			return nil, failure
+		}
+		return nil, nil
+	})
+
+	var attempts []RetryAttempt
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.OnAttempt = func(attempt RetryAttempt) {
+		attempts = append(attempts, attempt)
+	}
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport), WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.CreateAccount(types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, attempts, 2)
+	assert.Equal(t, 2, attempts[1].Attempt)
+	assert.Equal(t, types.OperationCreateAccounts, attempts[1].Operation)
+
+	// Errors that aren't transient are returned without retrying.
+	failures = 1
+	failure = errors.ErrUnexpected{}
+	attempts = nil
+	err = client.CreateAccount(types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1})
+	assert.Equal(t, errors.ErrUnexpected{}, err)
+	assert.Len(t, attempts, 1)
+	assert.Equal(t, time.Duration(0), attempts[0].Backoff)
+
+	// A MaxBackoff and Multiplier left at zero keep backing off as by default.
+	failures = 3
+	failure = errors.ErrConcurrencyExceeded{}
+	attempts = nil
+	client, err = NewClient(types.ToUint128(0), nil, 1, WithTransport(transport), WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Millisecond,
+		OnAttempt:      policy.OnAttempt,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.CreateAccount(types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, attempts, 3)
+	assert.Equal(t, time.Millisecond, attempts[0].Backoff)
+	assert.Equal(t, 2*time.Millisecond, attempts[1].Backoff)
+	assert.Equal(t, 4*time.Millisecond, attempts[2].Backoff)
+}
+
+func TestCloseContext(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inflight := make(chan error)
+	go func() {
+		inflight <- client.CreateAccount(types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1})
+	}()
+	<-started
+
+	// The in-flight request keeps the client from shutting down.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, client.CloseContext(ctx))
+
+	// New requests are rejected while closing.
+	err = client.CreateAccount(types.Account{ID: types.ToUint128(2), Ledger: 1, Code: 1})
+	assert.Equal(t, errors.ErrClientClosed{}, err)
+
+	close(release)
+	assert.Equal(t, nil, <-inflight)
+	<-client.Done()
+	assert.Equal(t, nil, client.CloseContext(context.Background()))
+}
+
+func TestHandoffClient(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var served [2]atomic.Int32
+	newClient := func(index int) Client {
+		transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+			if op == types.OperationCreateAccounts && served[index].Add(1) == 1 && index == 0 {
+				close(started)
+				<-release
+			}
+			return nil, nil
+		})
+		client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	active := newClient(0)
+	client := NewHandoffClient(active)
+	defer client.Close()
+
+	inflight := make(chan error)
+	go func() {
+		inflight <- client.CreateAccount(types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1})
+	}()
+	<-started
+
+	standby := newClient(1)
+	handoff := make(chan error)
+	go func() {
+		handoff <- client.Handoff(context.Background(), standby)
+	}()
+	for client.Active() != standby {
+		time.Sleep(time.Millisecond)
+	}
+
+	// New requests go to the standby while the previous client drains.
+	err := client.CreateAccount(types.Account{ID: types.ToUint128(2), Ledger: 1, Code: 1})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int32(1), served[1].Load())
+
+	close(release)
+	assert.Equal(t, nil, <-inflight)
+	assert.Equal(t, nil, <-handoff)
+	<-active.Done()
+	assert.Equal(t, int32(1), served[0].Load())
+
+	// A standby that can't reach its cluster is not handed off to.
+	stalled := make(chan struct{})
+	var unreachables []Client
+	for _, test := range []struct {
+		handler InMemoryHandler
+		err     error
+	}{
+		{func(op types.Operation, events []byte) ([]byte, error) {
+			return nil, errors.ErrUnexpected{}
+		}, errors.ErrUnexpected{}},
+		{func(op types.Operation, events []byte) ([]byte, error) {
+			<-stalled
+			return nil, nil
+		}, context.DeadlineExceeded},
+	} {
+		unreachable, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(NewInMemoryTransport(test.handler)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		assert.Equal(t, test.err, client.Handoff(ctx, unreachable))
+		cancel()
+		assert.Equal(t, standby, client.Active())
+		unreachables = append(unreachables, unreachable)
+	}
+	close(stalled)
+	for _, unreachable := range unreachables {
+		unreachable.Close()
+	}
+}
+
+func TestFailoverClient(t *testing.T) {
+	stall := make(chan struct{})
+	var created [2]atomic.Int32
+	newClient := func(index int, options ...ClientOption) Client {
+		transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+			switch op {
+			case types.OperationCreateAccounts:
+				created[index].Add(1)
+			case types.OperationLookupTransfers:
+				<-stall
+			case types.OperationLookupAccounts:
+				// Each cluster has an account of the ID of its index.
+				account := types.Account{ID: types.ToUint128(uint64(index)), Ledger: 1, Code: 1}
+				return unsafe.Slice((*byte)(unsafe.Pointer(&account)), 128), nil
+			}
+			return nil, nil
+		})
+		client, err := NewClient(types.ToUint128(0), nil, 4, append(options, WithTransport(transport))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	lookup := func(client Client) types.Uint128 {
+		t.Helper()
+		accounts, err := client.LookupAccounts([]types.Uint128{{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return accounts[0].ID
+	}
+
+	events := make(chan FailoverEvent, 16)
+	primary := newClient(0, WithConnectionMonitor(ConnectionMonitor{
+		ReconnectingAfter: 10 * time.Millisecond,
+		UnreachableAfter:  20 * time.Millisecond,
+	}))
+	var client Client = NewFailoverClient(primary, newClient(1), FailoverPolicy{
+		After:         20 * time.Millisecond,
+		CheckInterval: time.Millisecond,
+		QueueWrites:   true,
+		QueueMax:      1,
+		OnEvent:       func(event FailoverEvent) { events <- event },
+	})
+	failover := client.(*FailoverClient)
+	defer client.Close()
+	assert.Equal(t, types.ToUint128(0), lookup(client))
+
+	// A request without a reply makes the primary unreachable, and the reads fail over.
+	stalled := make(chan struct{})
+	go func() {
+		defer close(stalled)
+		_, _ = client.LookupTransfers([]types.Uint128{types.ToUint128(1)})
+	}()
+	event := <-events
+	assert.Equal(t, FailoverPrimary, event.From)
+	assert.Equal(t, FailoverSecondary, event.To)
+	assert.True(t, event.Automatic)
+	assert.Equal(t, ConnectionUnreachable, event.State)
+	assert.Equal(t, FailoverSecondary, failover.Active())
+	assert.Equal(t, types.ToUint128(1), lookup(client))
+
+	// Try writes fail right away rather than wait for the switchback.
+	_, err := client.TryCreateAccounts([]types.Account{{ID: types.ToUint128(2), Ledger: 1, Code: 1}})
+	assert.Equal(t, errors.ErrFailedOver{}, err)
+	_, err = client.TryCreateTransfers([]types.Transfer{{ID: types.ToUint128(2), Ledger: 1, Code: 1}})
+	assert.Equal(t, errors.ErrFailedOver{}, err)
+	assert.Equal(t, 0, failover.queued)
+
+	// Writes are held until the switchback, up to QueueMax.
+	held := make(chan error)
+	go func() {
+		held <- client.CreateAccount(types.Account{ID: types.ToUint128(2), Ledger: 1, Code: 1})
+	}()
+	for {
+		failover.mutex.Lock()
+		queued := failover.queued
+		failover.mutex.Unlock()
+		if queued == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_, err = client.CreateAccounts([]types.Account{{ID: types.ToUint128(3), Ledger: 1, Code: 1}})
+	assert.Equal(t, errors.ErrFailoverQueueFull{}, err)
+
+	close(stall)
+	<-stalled
+	if err := failover.Switchback(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	event = <-events
+	assert.Equal(t, FailoverSecondary, event.From)
+	assert.Equal(t, FailoverPrimary, event.To)
+	assert.True(t, !event.Automatic)
+	assert.Equal(t, 1, event.Queued)
+	assert.Equal(t, nil, <-held)
+	assert.Equal(t, int32(1), created[0].Load())
+	assert.Equal(t, int32(0), created[1].Load())
+	assert.Equal(t, types.ToUint128(0), lookup(client))
+
+	// Without QueueWrites, writes fail while failed over.
+	manual := NewFailoverClient(newClient(0), newClient(1), FailoverPolicy{Manual: true})
+	defer manual.Close()
+	if err := manual.Switchover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, FailoverSecondary, manual.Active())
+	err = manual.CreateAccount(types.Account{ID: types.ToUint128(4), Ledger: 1, Code: 1})
+	assert.Equal(t, errors.ErrFailedOver{}, err)
+	assert.Equal(t, types.ToUint128(1), lookup(manual))
+
+	manual.Close()
+	<-manual.Done()
+}
+
+func TestLookupAccountsStream(t *testing.T) {
+	// Every ID except multiples of ten exists.
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		ids := unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16)
+		accounts := make([]types.Account, 0, len(ids))
+		for _, id := range ids {
+			bytes := id.Bytes()
+			if binary.LittleEndian.Uint64(bytes[:8])%10 != 0 {
+				accounts = append(accounts, types.Account{ID: id})
+			}
+		}
+		if len(accounts) == 0 {
+			return nil, nil
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&accounts[0])), len(accounts)*128), nil
+	})
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ids := func(yield func(types.Uint128) bool) {
+		for i := uint64(1); i <= 20_000; i++ {
+			if !yield(types.ToUint128(i)) {
+				return
+			}
+		}
+	}
+
+	count := 0
+	for account, err := range client.LookupAccountsStream(ids) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		bytes := account.ID.Bytes()
+		assert.True(t, binary.LittleEndian.Uint64(bytes[:8])%10 != 0)
+		count++
+	}
+	assert.Equal(t, 18_000, count)
+
+	// Stopping early must not leak the producer.
+	for range client.LookupAccountsStream(ids) {
+		break
+	}
+}
+
+func TestLookupAccountsStreamStop(t *testing.T) {
+	var lookups atomic.Int32
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		lookups.Add(1)
+		return nil, errors.ErrUnexpected{}
+	})
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Once the first chunk has failed, the producer must stop pulling IDs and submitting chunks.
+	chunkMax := types.MaxBatchSize(types.OperationLookupAccounts)
+	failed := make(chan struct{})
+	var pulled atomic.Int32
+	ids := func(yield func(types.Uint128) bool) {
+		for i := 1; i <= chunkMax*(lookupStreamInFlightMax+2); i++ {
+			if i == chunkMax+1 {
+				<-failed
+				time.Sleep(10 * time.Millisecond)
+			}
+			if !yield(types.ToUint128(uint64(i))) {
+				return
+			}
+			pulled.Add(1)
+		}
+	}
+
+	for _, err := range client.LookupAccountsStream(ids) {
+		assert.Equal(t, error(errors.ErrUnexpected{}), err)
+		close(failed)
+	}
+	assert.Equal(t, int32(1), lookups.Load())
+	assert.Equal(t, int32(chunkMax), pulled.Load())
+}
+
+func TestStreamChanges(t *testing.T) {
+	pending := types.TransferFlags{Pending: true}.ToUint16()
+	transfers := []types.Transfer{
+		{ID: types.ToUint128(1), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2), Timestamp: 10},
+		{ID: types.ToUint128(2), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2), Timeout: 1, Flags: pending, Timestamp: 20},
+		{ID: types.ToUint128(3), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2), PendingID: types.ToUint128(2), Flags: types.TransferFlags{PostPendingTransfer: true}.ToUint16(), Timestamp: 30},
+		{ID: types.ToUint128(4), DebitAccountID: types.ToUint128(2), CreditAccountID: types.ToUint128(3), Timeout: 1, Flags: pending, Timestamp: 40},
+	}
+
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		filter := *(*types.AccountFilter)(unsafe.Pointer(&events[0]))
+		results := []types.Transfer{}
+		for _, transfer := range transfers {
+			if transfer.Timestamp >= filter.TimestampMin &&
+				(transfer.DebitAccountID == filter.AccountID || transfer.CreditAccountID == filter.AccountID) {
+				results = append(results, transfer)
+			}
+		}
+		if len(results) == 0 {
+			return nil, nil
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&results[0])), len(results)*128), nil
+	})
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := client.StreamChanges(ctx, 0, []types.Uint128{types.ToUint128(1), types.ToUint128(2)})
+	expected := []struct {
+		changeType ChangeType
+		id         uint64
+	}{
+		{ChangeTransferCreated, 1},
+		{ChangeTransferCreated, 2},
+		{ChangePendingPosted, 3},
+		{ChangeTransferCreated, 4},
+		{ChangePendingExpired, 4},
+	}
+	for _, e := range expected {
+		change := <-changes
+		if change.Err != nil {
+			t.Fatal(change.Err)
+		}
+		assert.Equal(t, e.changeType, change.Type)
+		assert.Equal(t, types.ToUint128(e.id), change.Transfer.ID)
+	}
+
+	cancel()
+	for range changes {
+		t.Fatal("unexpected change")
+	}
+}
+
+func TestStreamChangesOrder(t *testing.T) {
+	// A backlog of account 1 spanning several pages, with a transfer of account 2 now and then.
+	limit := types.MaxBatchSize(types.OperationGetAccountTransfers)
+	transfers := make([]types.Transfer, 2*limit+10)
+	for i := range transfers {
+		transfers[i] = types.Transfer{
+			ID:              types.ToUint128(uint64(i + 1)),
+			DebitAccountID:  types.ToUint128(9),
+			CreditAccountID: types.ToUint128(1),
+			Timestamp:       uint64(i + 1),
+		}
+		if i%1000 == 0 {
+			transfers[i].CreditAccountID = types.ToUint128(2)
+		}
+	}
+
+	// The transfers are created right after account 1 is first read, and before account 2 is:
+	// those of account 2 must still wait for those of account 1 before them.
+	var reads atomic.Int32
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		filter := *(*types.AccountFilter)(unsafe.Pointer(&events[0]))
+		if reads.Add(1) == 1 {
+			return nil, nil
+		}
+		assert.True(t, int(filter.Limit) <= limit)
+		results := []types.Transfer{}
+		for _, transfer := range transfers {
+			if len(results) == int(filter.Limit) {
+				break
+			}
+			if transfer.Timestamp >= filter.TimestampMin && transfer.CreditAccountID == filter.AccountID {
+				results = append(results, transfer)
+			}
+		}
+		if len(results) == 0 {
+			return nil, nil
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&results[0])), len(results)*128), nil
+	})
+
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := client.StreamChanges(ctx, 0, []types.Uint128{types.ToUint128(1), types.ToUint128(2)})
+	for _, transfer := range transfers {
+		change := <-changes
+		if change.Err != nil {
+			t.Fatal(change.Err)
+		}
+		assert.Equal(t, ChangeTransferCreated, change.Type)
+		assert.Equal(t, transfer.Timestamp, change.Timestamp)
+	}
+}
+
+func BenchmarkPing(b *testing.B) {
+	WithClient(b, func(client Client) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := client.Ping(context.Background()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 }
 
 func BenchmarkNop(b *testing.B) {
@@ -779,3 +2670,756 @@ func BenchmarkNop(b *testing.B) {
 		}
 	})
 }
+
+func TestStats(t *testing.T) {
+	block := make(chan struct{})
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		switch op {
+		case types.OperationLookupAccounts:
+			<-block
+			return make([]byte, types.ResultSize(op)), nil
+		case types.OperationLookupTransfers:
+			return nil, errors.ErrMaximumBatchSizeExceeded{}
+		}
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(transport),
+		WithConcurrencyMode(ConcurrencyBlock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	stats := client.Stats()
+	assert.Equal(t, 0, stats.InFlight)
+	assert.Empty(t, stats.Operations)
+	assert.True(t, stats.LastCompleted.IsZero())
+
+	err = client.CreateAccount(types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1})
+	assert.Equal(t, nil, err)
+	_, err = client.LookupTransfers([]types.Uint128{types.ToUint128(1)})
+	assert.NotEqual(t, nil, err)
+
+	// The first lookup takes the only request slot, and the second waits for it.
+	var lookups sync.WaitGroup
+	for range 2 {
+		lookups.Add(1)
+		go func() {
+			defer lookups.Done()
+			_, _ = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+		}()
+	}
+	for client.Stats().Queued != 1 || client.Stats().InFlight != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(block)
+	lookups.Wait()
+
+	stats = client.Stats()
+	assert.Equal(t, 0, stats.InFlight)
+	assert.Equal(t, 0, stats.Queued)
+	assert.Equal(t, map[types.Operation]OperationStats{
+		types.OperationCreateAccounts:  {Requests: 1},
+		types.OperationLookupTransfers: {Requests: 1, Errors: 1},
+		types.OperationLookupAccounts:  {Requests: 2},
+	}, stats.Operations)
+	assert.Equal(t, uint64(types.EventSize(types.OperationCreateAccounts)+
+		types.EventSize(types.OperationLookupTransfers)+
+		2*types.EventSize(types.OperationLookupAccounts)), stats.BytesSent)
+	assert.Equal(t, uint64(2*types.ResultSize(types.OperationLookupAccounts)), stats.BytesReceived)
+	assert.True(t, !stats.LastCompleted.IsZero())
+}
+
+func TestSubmitRaw(t *testing.T) {
+	account := types.Account{ID: types.ToUint128(1), Ledger: 1, Code: 1}
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		switch op {
+		case types.OperationLookupAccounts:
+			return types.EncodeAccounts([]types.Account{account}), nil
+		case types.Operation(200):
+			// An operation this client doesn't know echoes its body.
+			return events, nil
+		}
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	id := types.ToUint128(1).Bytes()
+	reply, err := client.SubmitRaw(types.OperationLookupAccounts, id[:])
+	assert.Equal(t, nil, err)
+	accounts, err := types.DecodeAccounts(reply)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []types.Account{account}, accounts)
+
+	reply, err = client.SubmitRaw(types.Operation(200), []byte("opaque"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "opaque", string(reply))
+
+	_, err = client.SubmitRaw(types.OperationLookupAccounts, id[:15])
+	assert.Equal(t, errors.ErrInvalidBodySize{
+		Operation: types.OperationLookupAccounts,
+		Size:      15,
+		EventSize: types.Uint128Size,
+	}, err)
+	_, err = client.SubmitRaw(types.OperationCreateTransfers, nil)
+	assert.Equal(t, errors.ErrEmptyBatch{}, err)
+	_, err = client.SubmitRaw(types.Operation(200), make([]byte, types.MessageBodySizeMax+1))
+	assert.Equal(t, errors.ErrMaximumBatchSizeExceeded{}, err)
+
+	client.Close()
+	_, err = client.SubmitRaw(types.Operation(200), []byte("opaque"))
+	assert.Equal(t, errors.ErrClientClosed{}, err)
+}
+
+func TestRateLimit(t *testing.T) {
+	start := time.Now()
+	limiter := newRateLimiter(100, 10, start)
+
+	delay, ok := limiter.reserve(10, start, false)
+	assert.Equal(t, time.Duration(0), delay)
+	assert.True(t, ok)
+	_, ok = limiter.reserve(1, start, false)
+	assert.True(t, !ok)
+	delay, _ = limiter.reserve(5, start, true)
+	assert.Equal(t, 50*time.Millisecond, delay)
+
+	// A batch larger than the burst waits for a full bucket, and leaves it in debt.
+	later := start.Add(time.Second)
+	delay, _ = limiter.reserve(30, later, true)
+	assert.Equal(t, time.Duration(0), delay)
+	delay, _ = limiter.reserve(1, later, true)
+	assert.Equal(t, 210*time.Millisecond, delay)
+
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport), WithRateLimit(100, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	transfers := make([]types.Transfer, 10)
+	_, err = client.TryCreateTransfers(transfers)
+	assert.Equal(t, nil, err)
+	_, err = client.TryCreateTransfers(transfers[:1])
+	assert.Equal(t, error(errors.ErrRateLimited{}), err)
+
+	_, err = client.CreateTransfers(transfers[:5])
+	assert.Equal(t, nil, err)
+	stats := client.Stats()
+	assert.Equal(t, uint64(1), stats.RateLimited)
+	assert.True(t, stats.RateLimitWait > 0)
+
+	// A rate of zero or less is unlimited.
+	for _, rate := range []float64{0, -1} {
+		transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+			return nil, nil
+		})
+		unlimited, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport), WithRateLimit(rate, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range 3 {
+			_, err = unlimited.TryCreateTransfers(transfers)
+			assert.Equal(t, nil, err)
+		}
+		assert.Equal(t, uint64(0), unlimited.Stats().RateLimited)
+		unlimited.Close()
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var down atomic.Bool
+	release := make(chan struct{})
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if down.Load() {
+			<-release
+		}
+		return nil, nil
+	})
+
+	var mutex sync.Mutex
+	var changes []CircuitState
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(transport),
+		WithCircuitBreaker(CircuitBreaker{
+			Threshold:     2,
+			Timeout:       10 * time.Millisecond,
+			ProbeInterval: 20 * time.Millisecond,
+			OnStateChange: func(change CircuitStateChange) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				changes = append(changes, change.To)
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// Two requests in a row without a reply open the circuit.
+	down.Store(true)
+	var stuck sync.WaitGroup
+	for range 2 {
+		stuck.Add(1)
+		go func() {
+			defer stuck.Done()
+			_, _ = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+		}()
+	}
+	for client.Stats().Circuit != CircuitOpen {
+		time.Sleep(time.Millisecond)
+	}
+	_, err = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, error(errors.ErrCircuitOpen{}), err)
+
+	// Once the probe interval has passed, a probe answered in time closes the circuit.
+	down.Store(false)
+	time.Sleep(20 * time.Millisecond)
+	_, err = client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, CircuitClosed, client.Stats().Circuit)
+
+	close(release)
+	stuck.Wait()
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitClosed}, changes)
+}
+
+func TestInterceptors(t *testing.T) {
+	var submitted []types.Transfer
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if op == types.OperationCreateTransfers {
+			submitted = append(submitted, unsafe.Slice((*types.Transfer)(unsafe.Pointer(&events[0])), len(events)/128)...)
+		}
+		return nil, nil
+	})
+
+	type caller struct{}
+	var calls []string
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(transport),
+		WithInterceptor(func(ctx context.Context, op types.Operation, events any, next Invoker) (any, error) {
+			calls = append(calls, "outer "+op.String())
+			result, err := next(ctx, op, events)
+			calls = append(calls, "outer done")
+			return result, err
+		}),
+		WithInterceptor(func(ctx context.Context, op types.Operation, events any, next Invoker) (any, error) {
+			calls = append(calls, "inner")
+			if ctx.Value(caller{}) == "dry-run" {
+				return []types.TransferEventResult{}, nil
+			}
+			if transfers, ok := events.([]types.Transfer); ok {
+				stamped := slices.Clone(transfers)
+				for i := range stamped {
+					stamped[i].UserData64 = 42
+				}
+				events = stamped
+			}
+			return next(ctx, op, events)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	transfers := []types.Transfer{{ID: types.ToUint128(1)}}
+	_, err = client.CreateTransfers(transfers)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"outer CreateTransfers", "inner", "outer done"}, calls)
+	assert.Len(t, submitted, 1)
+	assert.Equal(t, uint64(42), submitted[0].UserData64)
+	assert.Equal(t, uint64(0), transfers[0].UserData64)
+
+	// The context of the view reaches the interceptors, which answer without submitting.
+	results, err := ClientWithContext(client, context.WithValue(context.Background(), caller{}, "dry-run")).
+		CreateTransfers(transfers)
+	assert.Equal(t, nil, err)
+	assert.Empty(t, results)
+	assert.Len(t, submitted, 1)
+
+	future, err := client.CreateTransfersAsync(transfers)
+	assert.Equal(t, nil, err)
+	_, err = future.Wait(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Len(t, submitted, 2)
+
+	// Interceptors that pass on events, or reply with results, of the wrong type fail the request.
+	mistyped, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(transport),
+		WithInterceptor(func(ctx context.Context, op types.Operation, events any, next Invoker) (any, error) {
+			if op == types.OperationLookupAccounts {
+				return "accounts", nil
+			}
+			return next(ctx, op, "transfers")
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mistyped.Close()
+
+	_, err = mistyped.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, error(errors.ErrInterceptorResult{Operation: types.OperationLookupAccounts, Result: "accounts"}), err)
+	_, err = mistyped.CreateTransfers(transfers)
+	assert.Equal(t, error(errors.ErrInterceptorEvents{Operation: types.OperationCreateTransfers, Events: "transfers"}), err)
+	assert.Len(t, submitted, 2)
+}
+
+func TestDryRun(t *testing.T) {
+	client, err := NewClient(types.ToUint128(0), []string{"3000"}, 1, WithDryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	accounts, err := client.CreateAccounts([]types.Account{
+		{ID: types.ToUint128(1), Ledger: 1, Code: 1},
+		{ID: types.ToUint128(1), Ledger: 1, Code: 2},
+		{ID: types.ToUint128(2), Ledger: 1, Code: 1, Flags: types.AccountFlags{Linked: true}.ToUint16()},
+		{ID: types.ToUint128(3), Ledger: 0, Code: 1},
+		{ID: types.ToUint128(2), Ledger: 1, Code: 1},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []types.AccountEventResult{
+		{Index: 1, Result: types.AccountExistsWithDifferentCode},
+		{Index: 2, Result: types.AccountLinkedEventFailed},
+		{Index: 3, Result: types.AccountLedgerMustNotBeZero},
+	}, accounts)
+
+	linked := types.TransferFlags{Linked: true}.ToUint16()
+	transfers, err := client.CreateTransfers([]types.Transfer{
+		{ID: types.ToUint128(10), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2), Amount: types.ToUint128(1), Ledger: 1, Code: 1},
+		{ID: types.ToUint128(10), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2), Amount: types.ToUint128(1), Ledger: 1, Code: 1},
+		{ID: types.ToUint128(11), DebitAccountID: types.ToUint128(1), CreditAccountID: types.ToUint128(2), Amount: types.ToUint128(1), Ledger: 1, Code: 1, Flags: linked},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []types.TransferEventResult{
+		{Index: 1, Result: types.TransferExists},
+		{Index: 2, Result: types.TransferLinkedEventChainOpen},
+	}, transfers)
+
+	found, err := client.LookupAccounts([]types.Uint128{types.ToUint128(1)})
+	assert.Equal(t, nil, err)
+	assert.Empty(t, found)
+}
+
+func TestDuplicateDetection(t *testing.T) {
+	var submitted int
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		submitted++
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport), WithDuplicateDetection())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	_, err = client.CreateTransfers([]types.Transfer{
+		{ID: types.ToUint128(1)},
+		{ID: types.ToUint128(2)},
+		{ID: types.ToUint128(1)},
+	})
+	assert.Equal(t, errors.ErrDuplicateID{ID: types.ToUint128(1), FirstIndex: 0, Index: 2}, err)
+	_, err = client.CreateAccountsAsync([]types.Account{{ID: types.ToUint128(3)}, {ID: types.ToUint128(3)}})
+	assert.Equal(t, errors.ErrDuplicateID{ID: types.ToUint128(3), FirstIndex: 0, Index: 1}, err)
+	assert.Equal(t, 0, submitted)
+
+	_, err = client.CreateTransfers([]types.Transfer{{ID: types.ToUint128(1)}, {ID: types.ToUint128(2)}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, submitted)
+}
+
+func TestWatchBalance(t *testing.T) {
+	var balance atomic.Uint64
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		account := types.Account{ID: types.ToUint128(1), CreditsPosted: types.ToUint128(balance.Load())}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&account)), 128), nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	balance.Store(50)
+	snapshots := client.WatchBalance(ctx, types.ToUint128(1), WatchBalanceOptions{
+		Thresholds: []BalanceThreshold{
+			CreditBalanceBelow("low", types.ToUint128(100)),
+			CreditBalanceBelow("empty", types.ToUint128(1)),
+		},
+		PollInterval: time.Millisecond,
+	})
+
+	// The thresholds already reached are reported first.
+	snapshot := <-snapshots
+	assert.Equal(t, "low", snapshot.Threshold)
+	assert.True(t, snapshot.Reached)
+	assert.Equal(t, types.ToUint128(50), snapshot.Account.CreditsPosted)
+
+	balance.Store(0)
+	snapshot = <-snapshots
+	assert.Equal(t, "empty", snapshot.Threshold)
+	assert.True(t, snapshot.Reached)
+
+	balance.Store(200)
+	crossed := map[string]bool{}
+	for range 2 {
+		snapshot = <-snapshots
+		crossed[snapshot.Threshold] = snapshot.Reached
+	}
+	assert.Equal(t, map[string]bool{"low": false, "empty": false}, crossed)
+
+	cancel()
+	for range snapshots {
+	}
+}
+
+func TestTailTransfers(t *testing.T) {
+	var mutex sync.Mutex
+	var transfers []types.Transfer
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		filter := *(*types.AccountFilter)(unsafe.Pointer(&events[0]))
+		mutex.Lock()
+		defer mutex.Unlock()
+		results := []types.Transfer{}
+		for _, transfer := range transfers {
+			if transfer.Timestamp >= filter.TimestampMin && transfer.DebitAccountID == filter.AccountID {
+				results = append(results, transfer)
+			}
+		}
+		if len(results) == 0 {
+			return nil, nil
+		}
+		return unsafe.Slice((*byte)(unsafe.Pointer(&results[0])), len(results)*128), nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	create := func(id uint64) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		transfers = append(transfers, types.Transfer{ID: types.ToUint128(id), DebitAccountID: types.ToUint128(1), Timestamp: id * 10})
+	}
+	create(1)
+	create(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := client.TailTransfers(ctx, types.ToUint128(1), 0)
+	event := <-events
+	assert.Equal(t, types.ToUint128(1), event.Transfer.ID)
+	assert.Equal(t, uint64(10), event.Checkpoint)
+	assert.Equal(t, types.ToUint128(2), (<-events).Transfer.ID)
+
+	// New transfers are picked up by polling.
+	create(3)
+	event = <-events
+	assert.Equal(t, types.ToUint128(3), event.Transfer.ID)
+	cancel()
+	for range events {
+	}
+
+	// Resuming from a checkpoint reports the transfers after it.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events = client.TailTransfers(ctx, types.ToUint128(1), 20)
+	assert.Equal(t, types.ToUint128(3), (<-events).Transfer.ID)
+}
+
+func TestAccountCache(t *testing.T) {
+	// The cluster has accounts 1 to 3, whose credits count the transfers created.
+	existing := []types.Uint128{types.ToUint128(1), types.ToUint128(2), types.ToUint128(3)}
+	var lookedUp []types.Uint128
+	var created uint64
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		switch op {
+		case types.OperationCreateTransfers:
+			created++
+		case types.OperationLookupAccounts:
+			var accounts []types.Account
+			for _, id := range unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16) {
+				lookedUp = append(lookedUp, id)
+				if slices.Contains(existing, id) {
+					accounts = append(accounts, types.Account{ID: id, CreditsPosted: types.ToUint128(created)})
+				}
+			}
+			if len(accounts) == 0 {
+				return nil, nil
+			}
+			return unsafe.Slice((*byte)(unsafe.Pointer(&accounts[0])), len(accounts)*128), nil
+		}
+		return nil, nil
+	})
+
+	now := time.Unix(0, 0)
+	cache := NewAccountCache(time.Minute, 2)
+	cache.now = func() time.Time { return now }
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport), WithAccountCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	one, two, four := types.ToUint128(1), types.ToUint128(2), types.ToUint128(4)
+	lookup := func(ids ...types.Uint128) []types.Account {
+		accounts, err := client.LookupAccounts(ids)
+		assert.Equal(t, nil, err)
+		return accounts
+	}
+
+	accounts := lookup(one, four, one)
+	assert.Equal(t, []types.Account{{ID: one}, {ID: one}}, accounts)
+	assert.Equal(t, []types.Uint128{one, four}, lookedUp)
+
+	// Hits are answered from the cache, and accounts not found are looked up again.
+	accounts = lookup(two, one, four)
+	assert.Len(t, accounts, 2)
+	assert.Equal(t, []types.Uint128{one, four, two, four}, lookedUp)
+	assert.Equal(t, AccountCacheStats{Hits: 1, Misses: 4, Len: 2}, cache.Stats())
+
+	// Transfers created through the client invalidate the accounts they touch.
+	_, err = client.CreateTransfers([]types.Transfer{{ID: types.ID(), DebitAccountID: four, CreditAccountID: one}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, types.ToUint128(1), lookup(one)[0].CreditsPosted)
+	assert.Equal(t, types.ToUint128(0), lookup(two)[0].CreditsPosted)
+
+	// Post and void transfers invalidate every account.
+	assert.Equal(t, nil, client.VoidPending(types.ID()))
+	assert.Equal(t, 0, cache.Stats().Len)
+	assert.Equal(t, types.ToUint128(2), lookup(two)[0].CreditsPosted)
+
+	// Accounts expire after the TTL.
+	lookedUp = nil
+	lookup(two)
+	now = now.Add(time.Minute)
+	lookup(two)
+	assert.Equal(t, []types.Uint128{two}, lookedUp)
+
+	// Ping still reaches the cluster.
+	_, err = client.Ping(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []types.Uint128{two, {}}, lookedUp)
+}
+
+func TestTenantIsolationBatch(t *testing.T) {
+	// Every account and transfer belongs to tenant 1, except the account other.
+	other := types.ToUint128(1 << 40)
+	looked := map[types.Operation][]int{}
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		switch op {
+		case types.OperationLookupAccounts:
+			ids := unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16)
+			looked[op] = append(looked[op], len(ids))
+			accounts := make([]types.Account, len(ids))
+			for i, id := range ids {
+				accounts[i] = types.Account{ID: id, UserData64: 1}
+				if id == other {
+					accounts[i].UserData64 = 2
+				}
+			}
+			return unsafe.Slice((*byte)(unsafe.Pointer(&accounts[0])), len(accounts)*128), nil
+		case types.OperationLookupTransfers:
+			ids := unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16)
+			looked[op] = append(looked[op], len(ids))
+			transfers := make([]types.Transfer, len(ids))
+			for i, id := range ids {
+				transfers[i] = types.Transfer{ID: id, UserData64: 1}
+			}
+			return unsafe.Slice((*byte)(unsafe.Pointer(&transfers[0])), len(transfers)*128), nil
+		}
+		return nil, nil
+	})
+	client, err := NewClient(types.ToUint128(0), nil, 1, WithTransport(transport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	tenant, err := NewTenantIsolation(client, TenantUserData64).Client(
+		ContextWithTenant(context.Background(), types.ToUint128(1)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A full batch references more accounts than a lookup takes, and the same pending transfer
+	// throughout.
+	count := types.MaxBatchSize(types.OperationCreateTransfers)
+	transfers := make([]types.Transfer, count)
+	for i := range transfers {
+		transfers[i] = types.Transfer{
+			ID:              types.ToUint128(uint64(i + 1)),
+			DebitAccountID:  types.ToUint128(uint64(i + 1)),
+			CreditAccountID: types.ToUint128(uint64(count + i + 1)),
+			PendingID:       types.ToUint128(1),
+			UserData64:      1,
+			Ledger:          1,
+			Code:            1,
+		}
+	}
+	_, err = tenant.CreateTransfers(transfers)
+	assert.Equal(t, nil, err)
+	lookupMax := types.MaxBatchSize(types.OperationLookupAccounts)
+	total := 0
+	for _, size := range looked[types.OperationLookupAccounts] {
+		assert.True(t, size <= lookupMax)
+		total += size
+	}
+	assert.Equal(t, 2*count, total)
+	assert.Equal(t, []int{1}, looked[types.OperationLookupTransfers])
+
+	transfers[count-1].CreditAccountID = other
+	_, err = tenant.CreateTransfers(transfers)
+	assert.Equal(t, errors.ErrTenantMismatch{Index: count - 1}, err)
+}
+
+func TestTenantLedgers(t *testing.T) {
+	// Account n is on ledger n.
+	var created int
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		switch op {
+		case types.OperationCreateAccounts, types.OperationCreateTransfers:
+			created++
+		case types.OperationLookupAccounts:
+			var accounts []types.Account
+			for _, id := range unsafe.Slice((*types.Uint128)(unsafe.Pointer(&events[0])), len(events)/16) {
+				if id != (types.Uint128{}) {
+					accounts = append(accounts, types.Account{ID: id, Ledger: uint32(id.Bytes()[0])})
+				}
+			}
+			if len(accounts) == 0 {
+				return nil, nil
+			}
+			return unsafe.Slice((*byte)(unsafe.Pointer(&accounts[0])), len(accounts)*128), nil
+		}
+		return nil, nil
+	})
+
+	alice, bob := types.ToUint128(100), types.ToUint128(200)
+	ledgers := map[types.Uint128][]uint32{alice: {1, 2}, bob: {3}}
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(transport),
+		WithInterceptor(TenantLedgers(func(tenant types.Uint128) []uint32 { return ledgers[tenant] })),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	asAlice := ClientWithContext(client, ContextWithTenant(context.Background(), alice))
+	asBob := ClientWithContext(client, ContextWithTenant(context.Background(), bob))
+
+	_, err = asAlice.CreateAccounts([]types.Account{{ID: types.ID(), Ledger: 1}, {ID: types.ID(), Ledger: 2}})
+	assert.Equal(t, nil, err)
+	_, err = asAlice.CreateTransfers([]types.Transfer{{ID: types.ID(), Ledger: 2}, {ID: types.ID(), Ledger: 3}})
+	assert.Equal(t, errors.ErrLedgerNotAllowed{Index: 1, Ledger: 3}, err)
+	assert.Equal(t, errors.ErrLedgerNotAllowed{Index: 0, Ledger: 0}, asBob.VoidPending(types.ID()))
+	_, err = client.CreateTransfers([]types.Transfer{{ID: types.ID(), Ledger: 1}})
+	assert.Equal(t, errors.ErrTenantMissing{}, err)
+	assert.Equal(t, 1, created)
+
+	ids := []types.Uint128{types.ToUint128(1), types.ToUint128(2), types.ToUint128(3)}
+	accounts, err := asAlice.LookupAccounts(ids)
+	assert.Equal(t, nil, err)
+	assert.Len(t, accounts, 2)
+	accounts, err = asBob.LookupAccounts(ids)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []types.Account{{ID: ids[2], Ledger: 3}}, accounts)
+	accounts, err = client.LookupAccounts(ids)
+	assert.Equal(t, nil, err)
+	assert.Empty(t, accounts)
+	_, err = client.Ping(context.Background())
+	assert.Equal(t, nil, err)
+
+	_, err = asAlice.GetAccountHistory(types.AccountFilter{AccountID: ids[0], Limit: 1})
+	assert.Equal(t, errors.ErrTenantOperation{Operation: types.OperationGetAccountHistory}, err)
+}
+
+func TestAuthorizer(t *testing.T) {
+	var created int
+	transport := NewInMemoryTransport(func(op types.Operation, events []byte) ([]byte, error) {
+		if op == types.OperationCreateTransfers {
+			created += len(events) / 128
+		}
+		return nil, nil
+	})
+
+	treasury, suspended := types.ToUint128(1), types.ToUint128(2)
+	unavailable := e.New("policy service unavailable")
+	client, err := NewClient(types.ToUint128(0), nil, 1,
+		WithTransport(transport),
+		WithAuthorizer(AuthorizeTransfers(func(ctx context.Context, transfer types.Transfer) string {
+			amount := transfer.Amount.BigInt()
+			if transfer.Code == 10 && amount.Cmp(big.NewInt(1000)) > 0 {
+				return "amount exceeds the cap of code 10"
+			}
+			if transfer.DebitAccountID == treasury && transfer.CreditAccountID == suspended {
+				return "the treasury may not pay a suspended account"
+			}
+			return ""
+		})),
+		WithAuthorizer(func(ctx context.Context, op types.Operation, events any) error {
+			if op == types.OperationGetAccountHistory {
+				return unavailable
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	_, err = client.CreateTransfers([]types.Transfer{
+		{ID: types.ID(), Code: 10, Amount: types.ToUint128(1000)},
+		{ID: types.ID(), DebitAccountID: suspended, CreditAccountID: treasury},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, created)
+
+	_, err = client.CreateTransfers([]types.Transfer{
+		{ID: types.ID(), Code: 10, Amount: types.ToUint128(10)},
+		{ID: types.ID(), DebitAccountID: treasury, CreditAccountID: suspended},
+	})
+	assert.Equal(t, errors.ErrUnauthorized{
+		Operation: types.OperationCreateTransfers,
+		Index:     1,
+		Reason:    "the treasury may not pay a suspended account",
+	}, err)
+	assert.Equal(t, "Event 1 of CreateTransfers is not authorized: the treasury may not pay a suspended account.",
+		err.Error())
+
+	future, err := client.CreateTransfersAsync([]types.Transfer{{ID: types.ID(), Code: 10, Amount: types.ToUint128(1001)}})
+	assert.Equal(t, nil, err)
+	_, err = future.Wait(context.Background())
+	var unauthorized errors.ErrUnauthorized
+	assert.True(t, e.As(err, &unauthorized))
+	assert.Equal(t, 0, unauthorized.Index)
+	assert.Equal(t, 2, created)
+
+	_, err = client.SubmitRaw(types.OperationCreateTransfers, make([]byte, 128))
+	assert.Equal(t, errors.ErrUnauthorized{
+		Operation: types.OperationCreateTransfers,
+		Index:     -1,
+		Reason:    "raw transfers can't be checked",
+	}, err)
+
+	_, err = client.GetAccountHistory(types.AccountFilter{AccountID: treasury, Limit: 1})
+	assert.Equal(t, unavailable, err)
+}
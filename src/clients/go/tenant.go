@@ -0,0 +1,454 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"encoding/binary"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// TenantField selects the user data field that holds the tenant of accounts and transfers.
+type TenantField uint8
+
+const (
+	TenantUserData128 TenantField = iota
+	TenantUserData64
+	TenantUserData32
+)
+
+func (f TenantField) account(account types.Account) types.Uint128 {
+	switch f {
+	case TenantUserData128:
+		return account.UserData128
+	case TenantUserData64:
+		return types.ToUint128(account.UserData64)
+	default:
+		return types.ToUint128(uint64(account.UserData32))
+	}
+}
+
+func (f TenantField) transfer(transfer types.Transfer) types.Uint128 {
+	switch f {
+	case TenantUserData128:
+		return transfer.UserData128
+	case TenantUserData64:
+		return types.ToUint128(transfer.UserData64)
+	default:
+		return types.ToUint128(uint64(transfer.UserData32))
+	}
+}
+
+type tenantContextKey struct{}
+
+// ContextWithTenant returns a context carrying the tenant that requests are made on behalf of.
+func ContextWithTenant(ctx context.Context, tenant types.Uint128) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set with ContextWithTenant.
+func TenantFromContext(ctx context.Context) (types.Uint128, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(types.Uint128)
+	return tenant, ok
+}
+
+// TenantIsolation enforces that tenants sharing a cluster only see and touch their own
+// accounts and transfers, identified by the tenant stored in a user data field.
+type TenantIsolation struct {
+	client Client
+	field  TenantField
+}
+
+func NewTenantIsolation(client Client, field TenantField) *TenantIsolation {
+	return &TenantIsolation{client: client, field: field}
+}
+
+// Client returns a view of the client restricted to the tenant in ctx:
+//   - Created accounts and transfers must carry the tenant, and transfers may only reference
+//     the tenant's accounts and pending transfers, otherwise ErrTenantMismatch is returned
+//     before anything is submitted.
+//   - Lookups leave out objects of other tenants, as if they didn't exist.
+//   - Queries return nothing unless the filtered account belongs to the tenant, since the
+//     account filter has no user data predicate of its own.
+//
+// Closing or pausing the view does not close or pause the underlying client.
+func (i *TenantIsolation) Client(ctx context.Context) (Client, error) {
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, errors.ErrTenantMissing{}
+	}
+	return &tenantClient{client: i.client, field: i.field, tenant: tenant}, nil
+}
+
+type tenantClient struct {
+	client Client
+	field  TenantField
+	tenant types.Uint128
+}
+
+func (c *tenantClient) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	if err := c.checkAccounts(accounts); err != nil {
+		return nil, err
+	}
+	return c.client.CreateAccounts(accounts)
+}
+
+func (c *tenantClient) TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	if err := c.checkAccounts(accounts); err != nil {
+		return nil, err
+	}
+	return c.client.TryCreateAccounts(accounts)
+}
+
+func (c *tenantClient) checkAccounts(accounts []types.Account) error {
+	for i, account := range accounts {
+		if c.field.account(account) != c.tenant {
+			return errors.ErrTenantMismatch{Index: i}
+		}
+	}
+	return nil
+}
+
+func (c *tenantClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	if err := c.checkTransfers(transfers); err != nil {
+		return nil, err
+	}
+	return c.client.CreateTransfers(transfers)
+}
+
+// TryCreateTransfers may still wait for the lookups that check the accounts and pending
+// transfers belong to the tenant.
+func (c *tenantClient) TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	if err := c.checkTransfers(transfers); err != nil {
+		return nil, err
+	}
+	return c.client.TryCreateTransfers(transfers)
+}
+
+func (c *tenantClient) CreateAccountsAsync(
+	accounts []types.Account,
+) (*Future[[]types.AccountEventResult], error) {
+	if err := c.checkAccounts(accounts); err != nil {
+		return nil, err
+	}
+	return c.client.CreateAccountsAsync(accounts)
+}
+
+// CreateTransfersAsync waits for the lookups that check the accounts and pending transfers
+// belong to the tenant before returning.
+func (c *tenantClient) CreateTransfersAsync(
+	transfers []types.Transfer,
+) (*Future[[]types.TransferEventResult], error) {
+	if err := c.checkTransfers(transfers); err != nil {
+		return nil, err
+	}
+	return c.client.CreateTransfersAsync(transfers)
+}
+
+func (c *tenantClient) checkTransfers(transfers []types.Transfer) error {
+	var accountIDs, pendingIDs []types.Uint128
+	for i, transfer := range transfers {
+		if c.field.transfer(transfer) != c.tenant {
+			return errors.ErrTenantMismatch{Index: i}
+		}
+
+		// Post and void transfers may leave the accounts zero to inherit them from the pending
+		// transfer, whose accounts were checked when it was created.
+		zero := types.Uint128{}
+		if transfer.DebitAccountID != zero {
+			accountIDs = append(accountIDs, transfer.DebitAccountID)
+		}
+		if transfer.CreditAccountID != zero {
+			accountIDs = append(accountIDs, transfer.CreditAccountID)
+		}
+		if transfer.PendingID != zero {
+			pendingIDs = append(pendingIDs, transfer.PendingID)
+		}
+	}
+
+	owned, err := c.ownedAccounts(accountIDs)
+	if err != nil {
+		return err
+	}
+	ownedPending, err := c.ownedTransfers(pendingIDs)
+	if err != nil {
+		return err
+	}
+
+	// Objects that don't exist are left for the cluster to report as not found.
+	for i, transfer := range transfers {
+		if owned[transfer.DebitAccountID] == ownedOther ||
+			owned[transfer.CreditAccountID] == ownedOther ||
+			ownedPending[transfer.PendingID] == ownedOther {
+			return errors.ErrTenantMismatch{Index: i}
+		}
+	}
+	return nil
+}
+
+type ownership uint8
+
+const (
+	ownedUnknown ownership = iota
+	ownedTenant
+	ownedOther
+)
+
+func (c *tenantClient) ownedAccounts(accountIDs []types.Uint128) (map[types.Uint128]ownership, error) {
+	return lookupOwnership(accountIDs, types.OperationLookupAccounts, c.client.LookupAccounts,
+		func(account types.Account) (types.Uint128, bool) {
+			return account.ID, c.field.account(account) == c.tenant
+		},
+	)
+}
+
+func (c *tenantClient) ownedTransfers(transferIDs []types.Uint128) (map[types.Uint128]ownership, error) {
+	return lookupOwnership(transferIDs, types.OperationLookupTransfers, c.client.LookupTransfers,
+		func(transfer types.Transfer) (types.Uint128, bool) {
+			return transfer.ID, c.field.transfer(transfer) == c.tenant
+		},
+	)
+}
+
+// lookupOwnership looks up the objects of ids, once each and in batches that op accepts, and
+// returns whether those found belong to the tenant, as reported by owns.
+func lookupOwnership[T any](
+	ids []types.Uint128,
+	op types.Operation,
+	lookup func(ids []types.Uint128) ([]T, error),
+	owns func(object T) (types.Uint128, bool),
+) (map[types.Uint128]ownership, error) {
+	owned := make(map[types.Uint128]ownership, len(ids))
+	unique := make([]types.Uint128, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := owned[id]; !ok {
+			owned[id] = ownedUnknown
+			unique = append(unique, id)
+		}
+	}
+
+	for chunk := range slices.Chunk(unique, types.MaxBatchSize(op)) {
+		objects, err := lookup(chunk)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range objects {
+			id, tenant := owns(object)
+			owned[id] = ownedOther
+			if tenant {
+				owned[id] = ownedTenant
+			}
+		}
+	}
+	return owned, nil
+}
+
+func (c *tenantClient) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	accounts, err := c.client.LookupAccounts(accountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := accounts[:0]
+	for _, account := range accounts {
+		if c.field.account(account) == c.tenant {
+			visible = append(visible, account)
+		}
+	}
+	return visible, nil
+}
+
+func (c *tenantClient) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	transfers, err := c.client.LookupTransfers(transferIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := transfers[:0]
+	for _, transfer := range transfers {
+		if c.field.transfer(transfer) == c.tenant {
+			visible = append(visible, transfer)
+		}
+	}
+	return visible, nil
+}
+
+func (c *tenantClient) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	if _, found, err := lookupAccount(c, filter.AccountID); err != nil || !found {
+		return nil, err
+	}
+	return c.client.GetAccountTransfers(filter)
+}
+
+func (c *tenantClient) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	if _, found, err := lookupAccount(c, filter.AccountID); err != nil || !found {
+		return nil, err
+	}
+	return c.client.GetAccountHistory(filter)
+}
+
+func (c *tenantClient) LookupAccountsInto(
+	accountIDs []types.Uint128,
+	buf []types.Account,
+) ([]types.Account, error) {
+	accounts, err := c.client.LookupAccountsInto(accountIDs, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := accounts[:0]
+	for _, account := range accounts {
+		if c.field.account(account) == c.tenant {
+			visible = append(visible, account)
+		}
+	}
+	return visible, nil
+}
+
+func (c *tenantClient) LookupTransfersInto(
+	transferIDs []types.Uint128,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	transfers, err := c.client.LookupTransfersInto(transferIDs, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := transfers[:0]
+	for _, transfer := range transfers {
+		if c.field.transfer(transfer) == c.tenant {
+			visible = append(visible, transfer)
+		}
+	}
+	return visible, nil
+}
+
+func (c *tenantClient) GetAccountTransfersInto(
+	filter types.AccountFilter,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	if _, found, err := lookupAccount(c, filter.AccountID); err != nil || !found {
+		return nil, err
+	}
+	return c.client.GetAccountTransfersInto(filter, buf)
+}
+
+func (c *tenantClient) GetAccountHistoryInto(
+	filter types.AccountFilter,
+	buf []types.AccountBalance,
+) ([]types.AccountBalance, error) {
+	if _, found, err := lookupAccount(c, filter.AccountID); err != nil || !found {
+		return nil, err
+	}
+	return c.client.GetAccountHistoryInto(filter, buf)
+}
+
+func (c *tenantClient) CreateAccount(account types.Account) error {
+	return createAccount(c, account)
+}
+
+func (c *tenantClient) CreateTransfer(transfer types.Transfer) error {
+	return createTransfer(c, transfer)
+}
+
+func (c *tenantClient) LookupAccount(accountID types.Uint128) (types.Account, bool, error) {
+	return lookupAccount(c, accountID)
+}
+
+func (c *tenantClient) LookupAccountsStream(
+	accountIDs iter.Seq[types.Uint128],
+) iter.Seq2[types.Account, error] {
+	return lookupAccountsStream(c, accountIDs)
+}
+
+func (c *tenantClient) StreamChanges(
+	ctx context.Context,
+	fromTimestamp uint64,
+	accountIDs []types.Uint128,
+) <-chan ChangeEvent {
+	return streamChanges(ctx, c, fromTimestamp, accountIDs)
+}
+
+func (c *tenantClient) TailTransfers(
+	ctx context.Context,
+	accountID types.Uint128,
+	sinceTimestamp uint64,
+) <-chan TailEvent {
+	return tailTransfers(ctx, c, accountID, sinceTimestamp)
+}
+
+func (c *tenantClient) WatchBalance(
+	ctx context.Context,
+	accountID types.Uint128,
+	options WatchBalanceOptions,
+) <-chan BalanceSnapshot {
+	return watchBalance(ctx, c, accountID, options)
+}
+
+func (c *tenantClient) PostPending(pendingID types.Uint128, amount types.Uint128) error {
+	return createTransfer(c, c.stamp(types.PostPendingTransfer(pendingID, amount)))
+}
+
+func (c *tenantClient) VoidPending(pendingID types.Uint128) error {
+	return createTransfer(c, c.stamp(types.VoidPendingTransfer(pendingID)))
+}
+
+// SubmitRaw fails with ErrSubmitRawUnsupported, since the tenant of encoded events can't be
+// checked.
+func (c *tenantClient) SubmitRaw(op types.Operation, body []byte) ([]byte, error) {
+	return nil, errors.ErrSubmitRawUnsupported{}
+}
+
+// stamp sets the tenant on a transfer built by the client itself.
+func (c *tenantClient) stamp(transfer types.Transfer) types.Transfer {
+	switch c.field {
+	case TenantUserData128:
+		transfer.UserData128 = c.tenant
+	case TenantUserData64:
+		bytes := c.tenant.Bytes()
+		transfer.UserData64 = binary.LittleEndian.Uint64(bytes[:8])
+	default:
+		bytes := c.tenant.Bytes()
+		transfer.UserData32 = binary.LittleEndian.Uint32(bytes[:4])
+	}
+	return transfer
+}
+
+func (c *tenantClient) MessageSizeMax() int {
+	return c.client.MessageSizeMax()
+}
+
+func (c *tenantClient) UpdateAddresses(addresses []string) error {
+	return errors.ErrUpdateAddressesUnsupported{}
+}
+
+func (c *tenantClient) Pause(ctx context.Context, mode PauseMode) error { return nil }
+
+func (c *tenantClient) Resume() {}
+
+func (c *tenantClient) Ping(ctx context.Context) (time.Duration, error) {
+	return c.client.Ping(ctx)
+}
+
+func (c *tenantClient) Nop() error {
+	return c.client.Nop()
+}
+
+func (c *tenantClient) State() ConnectionState {
+	return c.client.State()
+}
+
+func (c *tenantClient) Stats() Stats {
+	return c.client.Stats()
+}
+
+func (c *tenantClient) Close() {}
+
+func (c *tenantClient) CloseContext(ctx context.Context) error { return nil }
+
+func (c *tenantClient) Done() <-chan struct{} {
+	return c.client.Done()
+}
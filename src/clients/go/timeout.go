@@ -0,0 +1,314 @@
+package tigerbeetle_go
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// WithDefaultRequestTimeout makes every request of the client fail with ErrRequestTimeout if it
+// has no reply within timeout, including its retries. Use RequestTimeout to override it for
+// some calls.
+func WithDefaultRequestTimeout(timeout time.Duration) ClientOption {
+	return func(options *clientOptions) {
+		options.requestTimeout = timeout
+	}
+}
+
+// RequestTimeout returns a view of the client whose requests fail with ErrRequestTimeout if
+// they have no reply within timeout, overriding WithDefaultRequestTimeout, for callers that
+// have no context to cancel:
+//
+//	results, err := tigerbeetle_go.RequestTimeout(client, time.Second).CreateTransfers(transfers)
+//
+// tb_client cannot cancel a request once submitted, so a request that timed out stays in flight
+// and holds its request slot until the cluster replies, and may still be executed. The events
+// are copied so that the caller may reuse them, and the Into variants decode into a buffer of
+// their own that is copied to buf. A timeout of zero removes the timeout.
+//
+// The view shares the client, and closing it closes the client.
+func RequestTimeout(client Client, timeout time.Duration) Client {
+	if view, ok := client.(*timeoutClient); ok {
+		client = view.client
+	}
+	if timeout <= 0 {
+		return client
+	}
+	return &timeoutClient{client: client, timeout: timeout}
+}
+
+type timeoutClient struct {
+	client  Client
+	timeout time.Duration
+}
+
+// withTimeout calls request, returning early if it takes longer than the timeout.
+func withTimeout[R any](c *timeoutClient, op types.Operation, request func() (R, error)) (R, error) {
+	type reply struct {
+		result R
+		err    error
+	}
+	replied := make(chan reply, 1)
+	go func() {
+		result, err := request()
+		replied <- reply{result: result, err: err}
+	}()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-replied:
+		return reply.result, reply.err
+	case <-timer.C:
+		var zero R
+		return zero, errors.ErrRequestTimeout{Operation: op, Timeout: c.timeout}
+	}
+}
+
+func (c *timeoutClient) CreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	accounts = slices.Clone(accounts)
+	return withTimeout(c, types.OperationCreateAccounts, func() ([]types.AccountEventResult, error) {
+		return c.client.CreateAccounts(accounts)
+	})
+}
+
+func (c *timeoutClient) CreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	transfers = slices.Clone(transfers)
+	return withTimeout(c, types.OperationCreateTransfers, func() ([]types.TransferEventResult, error) {
+		return c.client.CreateTransfers(transfers)
+	})
+}
+
+func (c *timeoutClient) TryCreateAccounts(accounts []types.Account) ([]types.AccountEventResult, error) {
+	accounts = slices.Clone(accounts)
+	return withTimeout(c, types.OperationCreateAccounts, func() ([]types.AccountEventResult, error) {
+		return c.client.TryCreateAccounts(accounts)
+	})
+}
+
+func (c *timeoutClient) TryCreateTransfers(transfers []types.Transfer) ([]types.TransferEventResult, error) {
+	transfers = slices.Clone(transfers)
+	return withTimeout(c, types.OperationCreateTransfers, func() ([]types.TransferEventResult, error) {
+		return c.client.TryCreateTransfers(transfers)
+	})
+}
+
+func (c *timeoutClient) CreateAccountsAsync(
+	accounts []types.Account,
+) (*Future[[]types.AccountEventResult], error) {
+	future, err := c.client.CreateAccountsAsync(accounts)
+	if err != nil {
+		return nil, err
+	}
+	return futureWithTimeout(c, types.OperationCreateAccounts, future), nil
+}
+
+func (c *timeoutClient) CreateTransfersAsync(
+	transfers []types.Transfer,
+) (*Future[[]types.TransferEventResult], error) {
+	future, err := c.client.CreateTransfersAsync(transfers)
+	if err != nil {
+		return nil, err
+	}
+	return futureWithTimeout(c, types.OperationCreateTransfers, future), nil
+}
+
+// futureWithTimeout returns a future of the reply of future, which fails with
+// ErrRequestTimeout if the reply takes longer than the timeout.
+func futureWithTimeout[R any](c *timeoutClient, op types.Operation, future *Future[R]) *Future[R] {
+	timed := newFuture[R]()
+	timer := time.AfterFunc(c.timeout, func() {
+		var zero R
+		timed.resolve(zero, errors.ErrRequestTimeout{Operation: op, Timeout: c.timeout})
+	})
+	future.onDone(func() {
+		timer.Stop()
+		timed.resolve(future.value, future.err)
+	})
+	return timed
+}
+
+func (c *timeoutClient) LookupAccounts(accountIDs []types.Uint128) ([]types.Account, error) {
+	accountIDs = slices.Clone(accountIDs)
+	return withTimeout(c, types.OperationLookupAccounts, func() ([]types.Account, error) {
+		return c.client.LookupAccounts(accountIDs)
+	})
+}
+
+func (c *timeoutClient) LookupTransfers(transferIDs []types.Uint128) ([]types.Transfer, error) {
+	transferIDs = slices.Clone(transferIDs)
+	return withTimeout(c, types.OperationLookupTransfers, func() ([]types.Transfer, error) {
+		return c.client.LookupTransfers(transferIDs)
+	})
+}
+
+func (c *timeoutClient) GetAccountTransfers(filter types.AccountFilter) ([]types.Transfer, error) {
+	return withTimeout(c, types.OperationGetAccountTransfers, func() ([]types.Transfer, error) {
+		return c.client.GetAccountTransfers(filter)
+	})
+}
+
+func (c *timeoutClient) GetAccountHistory(filter types.AccountFilter) ([]types.AccountBalance, error) {
+	return withTimeout(c, types.OperationGetAccountHistory, func() ([]types.AccountBalance, error) {
+		return c.client.GetAccountHistory(filter)
+	})
+}
+
+func (c *timeoutClient) LookupAccountsInto(
+	accountIDs []types.Uint128,
+	buf []types.Account,
+) ([]types.Account, error) {
+	accountIDs = slices.Clone(accountIDs)
+	accounts, err := withTimeout(c, types.OperationLookupAccounts, func() ([]types.Account, error) {
+		return c.client.LookupAccountsInto(accountIDs, make([]types.Account, 0, cap(buf)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(buf[:0], accounts...), nil
+}
+
+func (c *timeoutClient) LookupTransfersInto(
+	transferIDs []types.Uint128,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	transferIDs = slices.Clone(transferIDs)
+	transfers, err := withTimeout(c, types.OperationLookupTransfers, func() ([]types.Transfer, error) {
+		return c.client.LookupTransfersInto(transferIDs, make([]types.Transfer, 0, cap(buf)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(buf[:0], transfers...), nil
+}
+
+func (c *timeoutClient) GetAccountTransfersInto(
+	filter types.AccountFilter,
+	buf []types.Transfer,
+) ([]types.Transfer, error) {
+	transfers, err := withTimeout(c, types.OperationGetAccountTransfers, func() ([]types.Transfer, error) {
+		return c.client.GetAccountTransfersInto(filter, make([]types.Transfer, 0, cap(buf)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(buf[:0], transfers...), nil
+}
+
+func (c *timeoutClient) GetAccountHistoryInto(
+	filter types.AccountFilter,
+	buf []types.AccountBalance,
+) ([]types.AccountBalance, error) {
+	balances, err := withTimeout(c, types.OperationGetAccountHistory, func() ([]types.AccountBalance, error) {
+		return c.client.GetAccountHistoryInto(filter, make([]types.AccountBalance, 0, cap(buf)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(buf[:0], balances...), nil
+}
+
+func (c *timeoutClient) CreateAccount(account types.Account) error {
+	return createAccount(c, account)
+}
+
+func (c *timeoutClient) CreateTransfer(transfer types.Transfer) error {
+	return createTransfer(c, transfer)
+}
+
+func (c *timeoutClient) LookupAccount(accountID types.Uint128) (types.Account, bool, error) {
+	return lookupAccount(c, accountID)
+}
+
+func (c *timeoutClient) LookupAccountsStream(
+	accountIDs iter.Seq[types.Uint128],
+) iter.Seq2[types.Account, error] {
+	return lookupAccountsStream(c, accountIDs)
+}
+
+func (c *timeoutClient) StreamChanges(
+	ctx context.Context,
+	fromTimestamp uint64,
+	accountIDs []types.Uint128,
+) <-chan ChangeEvent {
+	return streamChanges(ctx, c, fromTimestamp, accountIDs)
+}
+
+func (c *timeoutClient) TailTransfers(
+	ctx context.Context,
+	accountID types.Uint128,
+	sinceTimestamp uint64,
+) <-chan TailEvent {
+	return tailTransfers(ctx, c, accountID, sinceTimestamp)
+}
+
+func (c *timeoutClient) WatchBalance(
+	ctx context.Context,
+	accountID types.Uint128,
+	options WatchBalanceOptions,
+) <-chan BalanceSnapshot {
+	return watchBalance(ctx, c, accountID, options)
+}
+
+func (c *timeoutClient) PostPending(pendingID types.Uint128, amount types.Uint128) error {
+	return createTransfer(c, types.PostPendingTransfer(pendingID, amount))
+}
+
+func (c *timeoutClient) VoidPending(pendingID types.Uint128) error {
+	return createTransfer(c, types.VoidPendingTransfer(pendingID))
+}
+
+func (c *timeoutClient) SubmitRaw(op types.Operation, body []byte) ([]byte, error) {
+	body = slices.Clone(body)
+	return withTimeout(c, op, func() ([]byte, error) {
+		return c.client.SubmitRaw(op, body)
+	})
+}
+
+func (c *timeoutClient) MessageSizeMax() int {
+	return c.client.MessageSizeMax()
+}
+
+func (c *timeoutClient) UpdateAddresses(addresses []string) error {
+	return c.client.UpdateAddresses(addresses)
+}
+
+func (c *timeoutClient) Pause(ctx context.Context, mode PauseMode) error {
+	return c.client.Pause(ctx, mode)
+}
+
+func (c *timeoutClient) Resume() {
+	c.client.Resume()
+}
+
+func (c *timeoutClient) State() ConnectionState {
+	return c.client.State()
+}
+
+func (c *timeoutClient) Stats() Stats {
+	return c.client.Stats()
+}
+
+func (c *timeoutClient) Ping(ctx context.Context) (time.Duration, error) {
+	return ping(ctx, c)
+}
+
+func (c *timeoutClient) Nop() error {
+	return c.client.Nop()
+}
+
+func (c *timeoutClient) Close() {
+	c.client.Close()
+}
+
+func (c *timeoutClient) CloseContext(ctx context.Context) error {
+	return c.client.CloseContext(ctx)
+}
+
+func (c *timeoutClient) Done() <-chan struct{} {
+	return c.client.Done()
+}
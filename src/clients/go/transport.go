@@ -0,0 +1,74 @@
+package tigerbeetle_go
+
+import (
+	"sync"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/errors"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// Transport carries requests from a Client to the cluster.
+//
+// Submit sends the events for op, encoded in their wire layout, and writes the encoded results
+// into reply, returning the number of bytes written. Submit is called concurrently from every
+// goroutine using the Client, and must not be called after Close.
+//
+// By default the client submits through tb_client, which connects to the replicas over TCP.
+// There is no unix domain socket transport, as replicas only listen on TCP: colocated
+// deployments should use a loopback address instead.
+type Transport interface {
+	Submit(op types.Operation, events []byte, reply []byte) (int, error)
+	Close()
+}
+
+// asyncTransport is a Transport that can also complete a request from its own completion path,
+// calling done once the results are in reply, so that no goroutine waits on the request.
+type asyncTransport interface {
+	Transport
+	SubmitAsync(op types.Operation, events []byte, reply []byte, done func(wrote int, err error))
+}
+
+// InMemoryHandler executes a request for an in-memory transport, returning the encoded results.
+type InMemoryHandler func(op types.Operation, events []byte) ([]byte, error)
+
+type inMemoryTransport struct {
+	handler InMemoryHandler
+	mutex   sync.RWMutex
+	closed  bool
+}
+
+// NewInMemoryTransport returns a Transport that serves every request by calling handler on the
+// submitting goroutine, without any network, for use with WithTransport in tests.
+func NewInMemoryTransport(handler InMemoryHandler) Transport {
+	return &inMemoryTransport{handler: handler}
+}
+
+func (t *inMemoryTransport) Submit(
+	op types.Operation,
+	events []byte,
+	reply []byte,
+) (int, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if t.closed {
+		return 0, errors.ErrClientClosed{}
+	}
+
+	results, err := t.handler(op, events)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(results) > len(reply) {
+		return 0, errors.ErrReplyTooLarge{Size: len(results), Max: len(reply)}
+	}
+	return copy(reply, results), nil
+}
+
+func (t *inMemoryTransport) Close() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.closed = true
+}